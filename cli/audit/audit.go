@@ -0,0 +1,57 @@
+// Package main provides a cli to audit the database of a stopped node, for
+// instance after an incident.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/ucli"
+	"go.dedis.ch/dela/contracts/beacon"
+	"go.dedis.ch/dela/contracts/secret"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution/native"
+	audit "go.dedis.ch/dela/core/ordering/cosipbft/audit/command"
+)
+
+var builder cli.Builder = ucli.NewBuilder("audit", nil)
+var printer io.Writer = os.Stderr
+
+// contracts are the optional contracts of the chains of memcoin, which are
+// registered in addition to the value and the access ones.
+var contracts = []audit.Contract{
+	func(exec *native.Service, srvc access.Service) {
+		beacon.RegisterContract(exec, beacon.NewContract(srvc))
+	},
+	func(exec *native.Service, srvc access.Service) {
+		secret.RegisterContract(exec, secret.NewContract(srvc))
+	},
+}
+
+// exit is called with a non-zero code when the audit fails, so that it can be
+// detected by a script.
+var exit = os.Exit
+
+func main() {
+	err := run(os.Args, audit.Initializer{Contracts: contracts})
+	if err != nil {
+		fmt.Fprintf(printer, "%+v\n", err)
+		exit(1)
+	}
+}
+
+func run(args []string, inits ...cli.Initializer) error {
+	for _, init := range inits {
+		init.SetCommands(builder)
+	}
+
+	app := builder.Build()
+	err := app.Run(args)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
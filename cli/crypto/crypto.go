@@ -9,6 +9,7 @@ import (
 
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/ucli"
+	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	bls "go.dedis.ch/dela/crypto/bls/command"
 )
 
@@ -16,7 +17,8 @@ var builder cli.Builder = ucli.NewBuilder("crypto", nil)
 var printer io.Writer = os.Stderr
 
 func main() {
-	err := run(os.Args, bls.Initializer{})
+	// The signer daemon protects the messages of the cosipbft consensus.
+	err := run(os.Args, bls.Initializer{Verifier: pbft.NewScopeVerifier()})
 	if err != nil {
 		fmt.Fprintf(printer, "%+v\n", err)
 	}
@@ -33,14 +33,9 @@ func newJstore(path string) (accessStore, error) {
 	}
 
 	if fileExist(path) {
-		buf, err := os.ReadFile(path)
+		err := jstore.readFile()
 		if err != nil {
-			return nil, xerrors.Errorf("failed to read file '%s': %v", path, err)
-		}
-
-		err = ctx.Unmarshal(buf, &data)
-		if err != nil {
-			return nil, xerrors.Errorf("failed to read json: %v", err)
+			return nil, err
 		}
 	} else {
 		err := jstore.saveFile()
@@ -52,6 +47,26 @@ func newJstore(path string) (accessStore, error) {
 	return jstore, nil
 }
 
+// NewReadOnlyStore returns the store of the access contract saved in the file,
+// without creating the file if it does not exist. It allows a tool to read the
+// accesses of a node that is stopped.
+func NewReadOnlyStore(path string) (store.Readable, error) {
+	jstore := &jstore{
+		ctx:  json.NewContext(),
+		path: path,
+		data: map[string][]byte{},
+	}
+
+	if fileExist(path) {
+		err := jstore.readFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return jstore, nil
+}
+
 // jstore implements a simple store to store accesses on the access contract. It
 // keeps the data in memory AND in a json file.
 //
@@ -93,6 +108,20 @@ func (s *jstore) Get(key []byte) ([]byte, error) {
 	return s.data[string(key)], nil
 }
 
+func (s *jstore) readFile() error {
+	buf, err := os.ReadFile(s.path)
+	if err != nil {
+		return xerrors.Errorf("failed to read file '%s': %v", s.path, err)
+	}
+
+	err = s.ctx.Unmarshal(buf, &s.data)
+	if err != nil {
+		return xerrors.Errorf("failed to read json: %v", err)
+	}
+
+	return nil
+}
+
 func (s *jstore) saveFile() error {
 	buf, err := s.ctx.Marshal(s.data)
 	if err != nil {
@@ -38,6 +38,43 @@ func TestJstore_New(t *testing.T) {
 	require.Regexp(t, "^failed to save empty file:", err.Error())
 }
 
+func TestNewReadOnlyStore(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), delaTestDir)
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "store.json")
+
+	store, err := NewReadOnlyStore(path)
+	require.NoError(t, err)
+	require.NoFileExists(t, path)
+
+	value, err := store.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	jstore, err := newJstore(path)
+	require.NoError(t, err)
+	require.NoError(t, jstore.Set([]byte("key"), []byte("value")))
+
+	store, err = NewReadOnlyStore(path)
+	require.NoError(t, err)
+
+	value, err = store.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+
+	_, err = NewReadOnlyStore(dir)
+	require.Regexp(t, "^failed to read file", err.Error())
+
+	err = os.WriteFile(path, []byte(""), os.ModePerm)
+	require.NoError(t, err)
+
+	_, err = NewReadOnlyStore(path)
+	require.EqualError(t, err, "failed to read json: unexpected end of JSON input")
+}
+
 func TestJstore_Set_Get_Delete(t *testing.T) {
 	dir, err := os.MkdirTemp(os.TempDir(), delaTestDir)
 	require.NoError(t, err)
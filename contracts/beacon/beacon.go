@@ -0,0 +1,285 @@
+// Package beacon implements a native contract that records the rounds of the
+// random beacon of a DKG. Each round is verified against the public key of the
+// DKG before being stored, so that other contracts can read an unbiasable
+// randomness through the snapshot.
+package beacon
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/prefixed"
+	"go.dedis.ch/dela/dkg/pedersen"
+	"go.dedis.ch/kyber/v3/suites"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractUID is the unique (4-bytes) identifier of the contract, it is
+	// used to prefix keys in the K/V store and by DARCs for access control.
+	ContractUID = "BEAC"
+
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Beacon"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract. Should be one of the Command type.
+	CmdArg = "beacon:command"
+
+	// PublicKeyArg is the argument's name in the transaction that contains the
+	// hexadecimal public key of the DKG.
+	PublicKeyArg = "beacon:public_key"
+
+	// RoundArg is the argument's name in the transaction that contains the
+	// index of the round.
+	RoundArg = "beacon:round"
+
+	// SharesArg is the argument's name in the transaction that contains the
+	// shares of the round, as encoded by pedersen.EncodeBeaconShares.
+	SharesArg = "beacon:shares"
+
+	// CredentialAllCommand defines the credential command that is allowed to
+	// perform all commands.
+	CredentialAllCommand = "all"
+)
+
+// Command defines a type of command for the beacon contract
+type Command string
+
+const (
+	// CmdSetup defines the command to set the public key of the DKG. It can
+	// only be done once.
+	CmdSetup Command = "SETUP"
+
+	// CmdRound defines the command to store the next round of the beacon.
+	CmdRound Command = "ROUND"
+)
+
+var (
+	suite = suites.MustFind("Ed25519")
+
+	keyPublicKey = []byte("pubkey")
+	keyLatest    = []byte("latest")
+	keyRound     = []byte("round")
+)
+
+// NewCreds creates new credentials for a beacon contract execution.
+func NewCreds() access.Credential {
+	return access.NewContractCreds([]byte(ContractUID), ContractName, CredentialAllCommand)
+}
+
+// RegisterContract registers the beacon contract to the given execution
+// service.
+func RegisterContract(exec *native.Service, c Contract) {
+	exec.Set(ContractName, c)
+}
+
+// Latest returns the index and the randomness of the latest round stored in
+// the snapshot. The randomness is nil if no round has been stored yet.
+func Latest(snap store.Readable) (uint64, []byte, error) {
+	return readLatest(prefixed.NewReadable(ContractUID, snap))
+}
+
+// Randomness returns the randomness of the round stored in the snapshot.
+func Randomness(snap store.Readable, round uint64) ([]byte, error) {
+	value, err := prefixed.NewReadable(ContractUID, snap).Get(roundKey(round))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read round: %v", err)
+	}
+
+	if value == nil {
+		return nil, xerrors.Errorf("round %d not found", round)
+	}
+
+	return value, nil
+}
+
+// Contract is a smart contract that stores the verified rounds of a random
+// beacon.
+//
+// - implements native.Contract
+type Contract struct {
+	// access is the access control service managing this smart contract
+	access access.Service
+}
+
+// NewContract creates a new beacon contract
+func NewContract(srvc access.Service) Contract {
+	return Contract{
+		access: srvc,
+	}
+}
+
+// Execute implements native.Contract. It runs the appropriate command.
+func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	creds := NewCreds()
+
+	err := c.access.Match(snap, creds, step.Current.GetIdentity())
+	if err != nil {
+		return xerrors.Errorf("identity not authorized: %v (%v)",
+			step.Current.GetIdentity(), err)
+	}
+
+	cmd := step.Current.GetArg(CmdArg)
+	if len(cmd) == 0 {
+		return xerrors.Errorf("'%s' not found in tx arg", CmdArg)
+	}
+
+	snap = prefixed.NewSnapshot(ContractUID, snap)
+
+	switch Command(cmd) {
+	case CmdSetup:
+		err := c.setup(snap, step)
+		if err != nil {
+			return xerrors.Errorf("failed to SETUP: %v", err)
+		}
+	case CmdRound:
+		err := c.round(snap, step)
+		if err != nil {
+			return xerrors.Errorf("failed to ROUND: %v", err)
+		}
+	default:
+		return xerrors.Errorf("unknown command: %s", cmd)
+	}
+
+	return nil
+}
+
+// UID returns the unique 4-bytes contract identifier.
+//
+// - implements native.Contract
+func (c Contract) UID() string {
+	return ContractUID
+}
+
+// setup performs the SETUP command
+func (c Contract) setup(snap store.Snapshot, step execution.Step) error {
+	current, err := snap.Get(keyPublicKey)
+	if err != nil {
+		return xerrors.Errorf("failed to read public key: %v", err)
+	}
+
+	if current != nil {
+		return xerrors.New("public key already set")
+	}
+
+	data, err := hex.DecodeString(string(step.Current.GetArg(PublicKeyArg)))
+	if err != nil {
+		return xerrors.Errorf("malformed public key: %v", err)
+	}
+
+	err = suite.Point().UnmarshalBinary(data)
+	if err != nil {
+		return xerrors.Errorf("malformed public key: %v", err)
+	}
+
+	err = snap.Set(keyPublicKey, data)
+	if err != nil {
+		return xerrors.Errorf("failed to set public key: %v", err)
+	}
+
+	dela.Logger.Info().
+		Str("contract", ContractName).
+		Msgf("setting public key %x", data)
+
+	return nil
+}
+
+// round performs the ROUND command. The round must be the one following the
+// latest one, and its shares must be valid for the public key.
+func (c Contract) round(snap store.Snapshot, step execution.Step) error {
+	data, err := snap.Get(keyPublicKey)
+	if err != nil {
+		return xerrors.Errorf("failed to read public key: %v", err)
+	}
+
+	if data == nil {
+		return xerrors.New("public key not set")
+	}
+
+	pubkey := suite.Point()
+
+	err = pubkey.UnmarshalBinary(data)
+	if err != nil {
+		return xerrors.Errorf("malformed public key: %v", err)
+	}
+
+	index, err := strconv.ParseUint(string(step.Current.GetArg(RoundArg)), 10, 64)
+	if err != nil {
+		return xerrors.Errorf("malformed round: %v", err)
+	}
+
+	latest, previous, err := readLatest(snap)
+	if err != nil {
+		return xerrors.Errorf("failed to read latest round: %v", err)
+	}
+
+	expected := uint64(0)
+	if previous != nil {
+		expected = latest + 1
+	}
+
+	if index != expected {
+		return xerrors.Errorf("unexpected round %d, expected %d", index, expected)
+	}
+
+	shares, err := pedersen.DecodeBeaconShares(string(step.Current.GetArg(SharesArg)))
+	if err != nil {
+		return xerrors.Errorf("failed to decode shares: %v", err)
+	}
+
+	value, err := pedersen.VerifyBeacon(pubkey, pedersen.BeaconInput(index, previous), shares)
+	if err != nil {
+		return xerrors.Errorf("invalid round: %v", err)
+	}
+
+	err = snap.Set(roundKey(index), value)
+	if err != nil {
+		return xerrors.Errorf("failed to set round: %v", err)
+	}
+
+	err = snap.Set(keyLatest, append(roundIndex(index), value...))
+	if err != nil {
+		return xerrors.Errorf("failed to set latest round: %v", err)
+	}
+
+	dela.Logger.Info().
+		Str("contract", ContractName).
+		Msgf("setting round %d=%x", index, value)
+
+	return nil
+}
+
+func readLatest(snap store.Readable) (uint64, []byte, error) {
+	data, err := snap.Get(keyLatest)
+	if err != nil {
+		return 0, nil, xerrors.Errorf("failed to read latest round: %v", err)
+	}
+
+	if data == nil {
+		return 0, nil, nil
+	}
+
+	if len(data) <= 8 {
+		return 0, nil, xerrors.Errorf("malformed latest round: %x", data)
+	}
+
+	return binary.BigEndian.Uint64(data[:8]), data[8:], nil
+}
+
+func roundIndex(index uint64) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, index)
+
+	return buffer
+}
+
+func roundKey(index uint64) []byte {
+	return append(append([]byte{}, keyRound...), roundIndex(index)...)
+}
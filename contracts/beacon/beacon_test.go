@@ -0,0 +1,262 @@
+package beacon
+
+import (
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/prefixed"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/dkg/pedersen"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+func TestExecute(t *testing.T) {
+	contract := NewContract(fakeAccess{err: fake.GetError()})
+
+	err := contract.Execute(fake.NewSnapshot(), makeStep(t))
+	require.EqualError(t, err,
+		"identity not authorized: fake.PublicKey ("+fake.GetError().Error()+")")
+
+	contract = NewContract(fakeAccess{})
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t))
+	require.EqualError(t, err, "'beacon:command' not found in tx arg")
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, CmdArg, "SETUP"))
+	require.EqualError(t, err, fake.Err("failed to SETUP: failed to read public key"))
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, CmdArg, "ROUND"))
+	require.EqualError(t, err, fake.Err("failed to ROUND: failed to read public key"))
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, CmdArg, "fake"))
+	require.EqualError(t, err, "unknown command: fake")
+}
+
+func TestScenario(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubkey, err := priPoly.Commit(nil).Commit().MarshalBinary()
+	require.NoError(t, err)
+
+	contract := NewContract(fakeAccess{})
+	snap := fake.NewSnapshot()
+
+	err = contract.Execute(snap, makeStep(t, CmdArg, "SETUP",
+		PublicKeyArg, hex.EncodeToString(pubkey)))
+	require.NoError(t, err)
+
+	_, value, err := Latest(snap)
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	var previous []byte
+
+	for round := uint64(0); round < 3; round++ {
+		input := pedersen.BeaconInput(round, previous)
+
+		err = contract.Execute(snap, makeStep(t, CmdArg, "ROUND",
+			RoundArg, strconv.FormatUint(round, 10),
+			SharesArg, makeShares(t, input, priPoly.Shares(3)[1:])))
+		require.NoError(t, err)
+
+		index, value, err := Latest(snap)
+		require.NoError(t, err)
+		require.Equal(t, round, index)
+		require.Len(t, value, 32)
+		require.NotEqual(t, previous, value)
+
+		randomness, err := Randomness(snap, round)
+		require.NoError(t, err)
+		require.Equal(t, value, randomness)
+
+		previous = value
+	}
+
+	_, err = Randomness(snap, 3)
+	require.EqualError(t, err, "round 3 not found")
+
+	_, err = Randomness(fake.NewBadSnapshot(), 0)
+	require.EqualError(t, err, fake.Err("failed to read round"))
+
+	_, _, err = Latest(fake.NewBadSnapshot())
+	require.EqualError(t, err, fake.Err("failed to read latest round"))
+}
+
+func TestContract_Setup(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	pubkey, err := suite.Point().Pick(suite.RandomStream()).MarshalBinary()
+	require.NoError(t, err)
+
+	snap := prefixed.NewSnapshot(ContractUID, fake.NewSnapshot())
+
+	err = contract.setup(snap, makeStep(t, PublicKeyArg, "zz"))
+	require.Error(t, err)
+	require.Regexp(t, "^malformed public key: encoding/hex", err.Error())
+
+	err = contract.setup(snap, makeStep(t, PublicKeyArg, "aa"))
+	require.Error(t, err)
+	require.Regexp(t, "^malformed public key: ", err.Error())
+
+	err = contract.setup(snap, makeStep(t, PublicKeyArg, hex.EncodeToString(pubkey)))
+	require.NoError(t, err)
+
+	err = contract.setup(snap, makeStep(t, PublicKeyArg, hex.EncodeToString(pubkey)))
+	require.EqualError(t, err, "public key already set")
+
+	badSnap := fake.NewSnapshot()
+	badSnap.ErrWrite = fake.GetError()
+
+	err = contract.setup(badSnap, makeStep(t, PublicKeyArg, hex.EncodeToString(pubkey)))
+	require.EqualError(t, err, fake.Err("failed to set public key"))
+}
+
+func TestContract_Round(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubkey, err := priPoly.Commit(nil).Commit().MarshalBinary()
+	require.NoError(t, err)
+
+	shares := makeShares(t, pedersen.BeaconInput(0, nil), priPoly.Shares(2))
+
+	contract := NewContract(fakeAccess{})
+
+	snap := fake.NewSnapshot()
+
+	err = contract.round(snap, makeStep(t))
+	require.EqualError(t, err, "public key not set")
+
+	snap.Set(keyPublicKey, []byte{0xaa})
+
+	err = contract.round(snap, makeStep(t))
+	require.Error(t, err)
+	require.Regexp(t, "^malformed public key: ", err.Error())
+
+	snap.Set(keyPublicKey, pubkey)
+
+	err = contract.round(snap, makeStep(t, RoundArg, "abc"))
+	require.Error(t, err)
+	require.Regexp(t, "^malformed round: ", err.Error())
+
+	err = contract.round(snap, makeStep(t, RoundArg, "1"))
+	require.EqualError(t, err, "unexpected round 1, expected 0")
+
+	err = contract.round(snap, makeStep(t, RoundArg, "0", SharesArg, "abc"))
+	require.EqualError(t, err, "failed to decode shares: malformed share 'abc'")
+
+	// The shares of the input of another round are rejected.
+	err = contract.round(snap, makeStep(t, RoundArg, "0",
+		SharesArg, makeShares(t, pedersen.BeaconInput(1, nil), priPoly.Shares(2))))
+	require.Error(t, err)
+	require.Regexp(t, "^invalid round: ", err.Error())
+
+	snap.Set(keyLatest, []byte{1})
+
+	err = contract.round(snap, makeStep(t, RoundArg, "0", SharesArg, shares))
+	require.EqualError(t, err,
+		"failed to read latest round: malformed latest round: 01")
+
+	snap.Delete(keyLatest)
+	snap.ErrWrite = fake.GetError()
+
+	err = contract.round(snap, makeStep(t, RoundArg, "0", SharesArg, shares))
+	require.EqualError(t, err, fake.Err("failed to set round"))
+
+	snap.ErrWrite = nil
+
+	err = contract.round(snap, makeStep(t, RoundArg, "0", SharesArg, shares))
+	require.NoError(t, err)
+
+	err = contract.round(snap, makeStep(t, RoundArg, "0", SharesArg, shares))
+	require.EqualError(t, err, "unexpected round 0, expected 1")
+}
+
+func TestContract_RoundSetLatestFail(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubkey, err := priPoly.Commit(nil).Commit().MarshalBinary()
+	require.NoError(t, err)
+
+	shares := makeShares(t, pedersen.BeaconInput(0, nil), priPoly.Shares(2))
+
+	contract := NewContract(fakeAccess{})
+
+	snap := fakeSnapshot{
+		InMemorySnapshot: fake.NewSnapshot(),
+		errKey:           string(keyLatest),
+	}
+
+	snap.Set(keyPublicKey, pubkey)
+
+	err = contract.round(snap, makeStep(t, RoundArg, "0", SharesArg, shares))
+	require.EqualError(t, err, fake.Err("failed to set latest round"))
+}
+
+func TestContract_UID(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	require.Equal(t, ContractUID, contract.UID())
+}
+
+func TestRegisterContract(t *testing.T) {
+	RegisterContract(native.NewExecution(), Contract{})
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeShares(t *testing.T, input []byte, priShares []*share.PriShare) string {
+	shares := make([]types.BeaconShare, len(priShares))
+	for i, priShare := range priShares {
+		shares[i] = pedersen.NewBeaconShare(input, priShare)
+	}
+
+	str, err := pedersen.EncodeBeaconShares(shares)
+	require.NoError(t, err)
+
+	return str
+}
+
+func makeStep(t *testing.T, args ...string) execution.Step {
+	return execution.Step{Current: makeTx(t, args...)}
+}
+
+func makeTx(t *testing.T, args ...string) txn.Transaction {
+	options := []signed.TransactionOption{}
+	for i := 0; i < len(args)-1; i += 2 {
+		options = append(options, signed.WithArg(args[i], []byte(args[i+1])))
+	}
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{}, options...)
+	require.NoError(t, err)
+
+	return tx
+}
+
+type fakeAccess struct {
+	access.Service
+
+	err error
+}
+
+func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
+	return srvc.err
+}
+
+type fakeSnapshot struct {
+	*fake.InMemorySnapshot
+
+	errKey string
+}
+
+func (snap fakeSnapshot) Set(key, value []byte) error {
+	if string(key) == snap.errKey {
+		return fake.GetError()
+	}
+
+	return snap.InMemorySnapshot.Set(key, value)
+}
@@ -0,0 +1,130 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/xerrors"
+)
+
+// verifiableProof is a proof of the ordering service that can be verified from
+// the genesis block, like the proofs of cosipbft.
+type verifiableProof interface {
+	ordering.Proof
+
+	Verify(genesis types.Genesis, fac crypto.VerifierFactory) error
+}
+
+// Authorizer verifies that the proof of a decryption request shows that a read
+// request for the ciphertext has been committed, which means that the reader
+// is allowed to read the secret.
+//
+// - implements pedersen.Authorizer
+type Authorizer struct {
+	genesis     blockstore.GenesisStore
+	proofFac    serde.Factory
+	verifierFac crypto.VerifierFactory
+	context     serde.Context
+}
+
+// NewAuthorizer creates a new authorizer that verifies the proofs of the chain
+// starting at the genesis block.
+func NewAuthorizer(genesis blockstore.GenesisStore, proofFac serde.Factory,
+	verifierFac crypto.VerifierFactory) Authorizer {
+
+	return Authorizer{
+		genesis:     genesis,
+		proofFac:    proofFac,
+		verifierFac: verifierFac,
+		context:     sjson.NewContext(),
+	}
+}
+
+// Authorize implements pedersen.Authorizer. It returns nil if the proof is the
+// proof of a read request for the ciphertext with K and the public key. The
+// proof can be rebuilt by anyone from the chain, therefore the shares are only
+// released when they are reencrypted to the key of the reader.
+func (a Authorizer) Authorize(data []byte, K, pubk kyber.Point) error {
+	if pubk == nil {
+		return xerrors.New("missing public key of the reencryption")
+	}
+
+	msg, err := a.proofFac.Deserialize(a.context, data)
+	if err != nil {
+		return xerrors.Errorf("failed to deserialize proof: %v", err)
+	}
+
+	proof, ok := msg.(verifiableProof)
+	if !ok {
+		return xerrors.Errorf("invalid proof '%T'", msg)
+	}
+
+	genesis, err := a.genesis.Get()
+	if err != nil {
+		return xerrors.Errorf("failed to read genesis: %v", err)
+	}
+
+	err = proof.Verify(genesis, a.verifierFac)
+	if err != nil {
+		return xerrors.Errorf("failed to verify proof: %v", err)
+	}
+
+	if proof.GetValue() == nil {
+		return xerrors.New("read request not found")
+	}
+
+	var record readRecord
+
+	err = json.Unmarshal(proof.GetValue(), &record)
+	if err != nil {
+		return xerrors.Errorf("failed to unmarshal record: %v", err)
+	}
+
+	// The key is computed from the record so that the value of another key
+	// can't be used as a read request.
+	if !bytes.Equal(proof.GetKey(), ReadKey(record.ID)) {
+		return xerrors.Errorf("invalid key %x", proof.GetKey())
+	}
+
+	err = matchPoint(record.K, K)
+	if err != nil {
+		return xerrors.Errorf("mismatch K: %v", err)
+	}
+
+	err = matchPoint(record.PubK, pubk)
+	if err != nil {
+		return xerrors.Errorf("mismatch public key: %v", err)
+	}
+
+	return nil
+}
+
+// matchPoint returns nil if the data is the marshaled point, or if both are
+// empty.
+func matchPoint(data []byte, point kyber.Point) error {
+	if point == nil {
+		if len(data) > 0 {
+			return xerrors.New("missing point")
+		}
+
+		return nil
+	}
+
+	expected, err := point.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal point: %v", err)
+	}
+
+	if !bytes.Equal(data, expected) {
+		return xerrors.Errorf("%x != %x", data, expected)
+	}
+
+	return nil
+}
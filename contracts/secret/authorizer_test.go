@@ -0,0 +1,172 @@
+package secret
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/dkg/pedersen"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+// The authorizer must be usable by the DKG, and verify the proofs of cosipbft.
+var (
+	_ pedersen.Authorizer = Authorizer{}
+	_ verifiableProof     = cosipbft.Proof{}
+)
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	genstore := makeGenesisStore(t)
+
+	K := suite.Point().Pick(suite.RandomStream())
+	pubk := suite.Point().Pick(suite.RandomStream())
+
+	txID := []byte{1, 2, 3}
+
+	proof := fakeProof{
+		key:   ReadKey(txID),
+		value: makeRecord(t, txID, K, nil),
+	}
+
+	authorizer := NewAuthorizer(genstore, fakeProofFactory{proof: proof}, fake.VerifierFactory{})
+
+	// A plain decryption would release the secret to anyone who can rebuild
+	// the proof from the chain.
+	err := authorizer.Authorize(nil, K, nil)
+	require.EqualError(t, err, "missing public key of the reencryption")
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, "mismatch public key:  != "+encodePoint(t, pubk))
+
+	proof.value = makeRecord(t, txID, K, pubk)
+	authorizer.proofFac = fakeProofFactory{proof: proof}
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.NoError(t, err)
+
+	err = authorizer.Authorize(nil, suite.Point(), pubk)
+	require.Error(t, err)
+	require.Regexp(t, "^mismatch K: ", err.Error())
+
+	err = authorizer.Authorize(nil, K, suite.Point())
+	require.Error(t, err)
+	require.Regexp(t, "^mismatch public key: ", err.Error())
+
+	err = authorizer.Authorize(nil, fakePoint{}, pubk)
+	require.EqualError(t, err, fake.Err("mismatch K: failed to marshal point"))
+}
+
+func TestAuthorizer_AuthorizeFail(t *testing.T) {
+	genstore := makeGenesisStore(t)
+
+	K := suite.Point().Pick(suite.RandomStream())
+	pubk := suite.Point().Pick(suite.RandomStream())
+	txID := []byte{1, 2, 3}
+
+	authorizer := NewAuthorizer(genstore, fake.NewBadMessageFactory(), fake.VerifierFactory{})
+
+	err := authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, fake.Err("failed to deserialize proof"))
+
+	authorizer.proofFac = fake.MessageFactory{}
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, "invalid proof 'fake.Message'")
+
+	authorizer.proofFac = fakeProofFactory{proof: fakeProof{err: fake.GetError()}}
+	authorizer.genesis = blockstore.NewGenesisStore()
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, "failed to read genesis: missing genesis block")
+
+	authorizer.genesis = genstore
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, fake.Err("failed to verify proof"))
+
+	authorizer.proofFac = fakeProofFactory{proof: fakeProof{}}
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, "read request not found")
+
+	authorizer.proofFac = fakeProofFactory{proof: fakeProof{value: []byte("{")}}
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, "failed to unmarshal record: unexpected end of JSON input")
+
+	// A record stored under another key is not a read request.
+	authorizer.proofFac = fakeProofFactory{proof: fakeProof{
+		key:   []byte("abc"),
+		value: makeRecord(t, txID, K, nil),
+	}}
+
+	err = authorizer.Authorize(nil, K, pubk)
+	require.EqualError(t, err, "invalid key 616263")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeGenesisStore(t *testing.T) blockstore.GenesisStore {
+	genesis, err := types.NewGenesis(authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner)))
+	require.NoError(t, err)
+
+	genstore := blockstore.NewGenesisStore()
+	require.NoError(t, genstore.Set(genesis))
+
+	return genstore
+}
+
+func makeRecord(t *testing.T, txID []byte, K, pubk kyber.Point) []byte {
+	record := readRecord{
+		ID:     txID,
+		Secret: "abc",
+		K:      marshalPoint(t, K),
+	}
+
+	if pubk != nil {
+		record.PubK = marshalPoint(t, pubk)
+	}
+
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+
+	return data
+}
+
+type fakeProof struct {
+	key   []byte
+	value []byte
+	err   error
+}
+
+func (p fakeProof) GetKey() []byte {
+	return p.key
+}
+
+func (p fakeProof) GetValue() []byte {
+	return p.value
+}
+
+func (p fakeProof) Verify(types.Genesis, crypto.VerifierFactory) error {
+	return p.err
+}
+
+func (p fakeProof) Serialize(serde.Context) ([]byte, error) {
+	return nil, nil
+}
+
+type fakeProofFactory struct {
+	proof fakeProof
+}
+
+func (f fakeProofFactory) Deserialize(serde.Context, []byte) (serde.Message, error) {
+	return f.proof, nil
+}
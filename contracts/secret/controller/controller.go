@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/secret"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/dkg"
+	"golang.org/x/xerrors"
+)
+
+// miniController is a CLI initializer to register the secret contract and the
+// authorizer of the decryptions.
+//
+// - implements node.Initializer
+type miniController struct {
+}
+
+// NewController creates a new minimal controller for the secret contract. It
+// must be started after the ordering service and before the DKG so that the
+// DKG uses the authorizer.
+func NewController() node.Initializer {
+	return miniController{}
+}
+
+// SetCommands implements node.Initializer.
+func (miniController) SetCommands(builder node.Builder) {
+}
+
+// OnStart implements node.Initializer. It registers the secret contract and
+// injects the authorizer that verifies the proofs of the read requests. It
+// fails when the DKG is already started, as it would release its shares
+// without verifying the proofs.
+func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
+	var d dkg.DKG
+	err := inj.Resolve(&d)
+	if err == nil {
+		return xerrors.New("the secret controller must start before the DKG")
+	}
+
+	var access access.Service
+	err = inj.Resolve(&access)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve access service: %v", err)
+	}
+
+	var exec *native.Service
+	err = inj.Resolve(&exec)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve native service: %v", err)
+	}
+
+	var genstore blockstore.GenesisStore
+	err = inj.Resolve(&genstore)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve genesis store: %v", err)
+	}
+
+	var proofFac cosipbft.ProofFactory
+	err = inj.Resolve(&proofFac)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve proof factory: %v", err)
+	}
+
+	var signing cosi.CollectiveSigning
+	err = inj.Resolve(&signing)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve collective signing: %v", err)
+	}
+
+	secret.RegisterContract(exec, secret.NewContract(access))
+
+	inj.Inject(secret.NewAuthorizer(genstore, proofFac, signing.GetVerifierFactory()))
+
+	return nil
+}
+
+// OnStop implements node.Initializer.
+func (miniController) OnStop(inj node.Injector) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/secret"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/dkg/pedersen"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestSetCommands(t *testing.T) {
+	ctrl := NewController()
+	ctrl.SetCommands(nil)
+}
+
+func TestOnStart(t *testing.T) {
+	ctrl := NewController()
+
+	injector := node.NewInjector()
+	err := ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err, "failed to resolve access service: "+
+		"couldn't find dependency for 'access.Service'")
+
+	injector.Inject(&fakeAccess{})
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err, "failed to resolve native service: "+
+		"couldn't find dependency for '*native.Service'")
+
+	injector.Inject(native.NewExecution())
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err, "failed to resolve genesis store: "+
+		"couldn't find dependency for 'blockstore.GenesisStore'")
+
+	injector.Inject(blockstore.NewGenesisStore())
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err, "failed to resolve proof factory: "+
+		"couldn't find dependency for 'cosipbft.ProofFactory'")
+
+	injector.Inject(cosipbft.ProofFactory{})
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err, "failed to resolve collective signing: "+
+		"couldn't find dependency for 'cosi.CollectiveSigning'")
+
+	injector.Inject(fakeCosi{})
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.NoError(t, err)
+
+	var authorizer pedersen.Authorizer
+	require.NoError(t, injector.Resolve(&authorizer))
+	require.IsType(t, secret.Authorizer{}, authorizer)
+}
+
+func TestOnStart_AfterDKG(t *testing.T) {
+	ctrl := NewController()
+
+	injector := node.NewInjector()
+	injector.Inject(fakeDKG{})
+
+	err := ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err, "the secret controller must start before the DKG")
+}
+
+func TestOnStop(t *testing.T) {
+	ctrl := NewController()
+
+	err := ctrl.OnStop(nil)
+	require.NoError(t, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeAccess struct {
+	access.Service
+}
+
+type fakeCosi struct {
+	cosi.CollectiveSigning
+}
+
+func (fakeCosi) GetVerifierFactory() crypto.VerifierFactory {
+	return fake.VerifierFactory{}
+}
+
+type fakeDKG struct {
+	dkg.DKG
+}
@@ -0,0 +1,490 @@
+// Package secret implements a native contract that stores ciphertexts of a DKG
+// with an access policy. A reader asks for a secret with a read transaction,
+// and the DKG nodes only release their shares for the secret after verifying
+// the proof that an authorized read transaction has been committed.
+//
+// A writer proves that it knows the ephemeral scalar of the ciphertext of a
+// secret, and the proof is bound to the ID of the secret. It prevents anyone
+// from storing the ciphertext of another secret under an ID it can read, and
+// the ephemeral key of a ciphertext can only be stored once.
+package secret
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/prefixed"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/suites"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractUID is the unique (4-bytes) identifier of the contract, it is
+	// used to prefix keys in the K/V store and by DARCs for access control.
+	ContractUID = "SECR"
+
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Secret"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract. Should be one of the Command type.
+	CmdArg = "secret:command"
+
+	// IDArg is the argument's name in the transaction that contains the
+	// identifier of the secret.
+	IDArg = "secret:id"
+
+	// CiphertextArg is the argument's name in the transaction that contains
+	// the ciphertext of the secret, as encoded by EncodeCiphertext.
+	CiphertextArg = "secret:ciphertext"
+
+	// ProofArg is the argument's name in the transaction that contains the
+	// proof of knowledge of the ephemeral scalar of the ciphertext, as created
+	// by ProveCiphertext.
+	ProofArg = "secret:proof"
+
+	// PublicKeyArg is the argument's name in the transaction that contains the
+	// hexadecimal public key the secret is reencrypted to. It is required so
+	// that the shares are only released to the key of the reader.
+	PublicKeyArg = "secret:public_key"
+
+	// CredentialAllCommand defines the credential command that is allowed to
+	// write secrets.
+	CredentialAllCommand = "all"
+
+	// CredentialReadCommand defines the credential command that is allowed to
+	// read a secret. The credential is identified by the ID of the secret so
+	// that each secret has its own access policy.
+	CredentialReadCommand = "read"
+)
+
+// Command defines a type of command for the secret contract
+type Command string
+
+const (
+	// CmdWrite defines the command to store a new secret.
+	CmdWrite Command = "WRITE"
+
+	// CmdRead defines the command to request the decryption of a secret.
+	CmdRead Command = "READ"
+)
+
+var (
+	suite = suites.MustFind("Ed25519")
+
+	keySecret    = []byte("secret")
+	keyRead      = []byte("read")
+	keyEphemeral = []byte("ephemeral")
+)
+
+// NewCreds creates new credentials for a secret contract execution.
+func NewCreds() access.Credential {
+	return access.NewContractCreds([]byte(ContractUID), ContractName, CredentialAllCommand)
+}
+
+// NewReadCreds creates new credentials to read the secret with the given ID.
+func NewReadCreds(id string) access.Credential {
+	return access.NewContractCreds([]byte(id), ContractName, CredentialReadCommand)
+}
+
+// RegisterContract registers the secret contract to the given execution
+// service.
+func RegisterContract(exec *native.Service, c Contract) {
+	exec.Set(ContractName, c)
+}
+
+// ReadKey returns the key of the read request of the transaction in the tree,
+// which is the key to get the proof of.
+func ReadKey(txID []byte) []byte {
+	return prefixed.NewPrefixedKey([]byte(ContractUID), readKey(txID))
+}
+
+// EncodeCiphertext returns the textual representation of a ciphertext, which
+// is made of the hexadecimal points separated by colons.
+func EncodeCiphertext(K kyber.Point, Cs []kyber.Point) (string, error) {
+	parts := make([]string, 0, len(Cs)+1)
+
+	for _, point := range append([]kyber.Point{K}, Cs...) {
+		data, err := point.MarshalBinary()
+		if err != nil {
+			return "", xerrors.Errorf("failed to marshal point: %v", err)
+		}
+
+		parts = append(parts, hex.EncodeToString(data))
+	}
+
+	return strings.Join(parts, ":"), nil
+}
+
+// DecodeCiphertext returns the points of the textual representation of a
+// ciphertext.
+func DecodeCiphertext(str string) (kyber.Point, []kyber.Point, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) < 2 {
+		return nil, nil, xerrors.Errorf("malformed ciphertext '%s'", str)
+	}
+
+	points := make([]kyber.Point, len(parts))
+
+	for i, part := range parts {
+		point, err := decodePoint(part)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("malformed point %d: %v", i, err)
+		}
+
+		points[i] = point
+	}
+
+	return points[0], points[1:], nil
+}
+
+// ProveCiphertext returns the textual representation of the proof that the
+// writer of the secret knows the ephemeral scalar k of the ciphertext, that is
+// K = k*G. The proof is bound to the ID of the secret and to the ciphertext.
+func ProveCiphertext(id string, k kyber.Scalar, K kyber.Point,
+	Cs []kyber.Point) (string, error) {
+
+	s := suite.Scalar().Pick(suite.RandomStream())
+	W := suite.Point().Mul(s, nil)
+
+	e, err := challenge(id, K, Cs, W)
+	if err != nil {
+		return "", xerrors.Errorf("failed to compute challenge: %v", err)
+	}
+
+	f := suite.Scalar().Add(s, suite.Scalar().Mul(e, k))
+
+	parts := make([]string, 2)
+
+	for i, scalar := range []kyber.Scalar{e, f} {
+		data, err := scalar.MarshalBinary()
+		if err != nil {
+			return "", xerrors.Errorf("failed to marshal scalar: %v", err)
+		}
+
+		parts[i] = hex.EncodeToString(data)
+	}
+
+	return strings.Join(parts, ":"), nil
+}
+
+// verifyCiphertext verifies the proof of knowledge of the ephemeral scalar of
+// the ciphertext for the ID of the secret.
+func verifyCiphertext(id string, K kyber.Point, Cs []kyber.Point, proof string) error {
+	parts := strings.Split(proof, ":")
+	if len(parts) != 2 {
+		return xerrors.Errorf("malformed proof '%s'", proof)
+	}
+
+	scalars := make([]kyber.Scalar, len(parts))
+
+	for i, part := range parts {
+		data, err := hex.DecodeString(part)
+		if err != nil {
+			return xerrors.Errorf("malformed scalar %d: %v", i, err)
+		}
+
+		scalars[i] = suite.Scalar()
+
+		err = scalars[i].UnmarshalBinary(data)
+		if err != nil {
+			return xerrors.Errorf("malformed scalar %d: %v", i, err)
+		}
+	}
+
+	e, f := scalars[0], scalars[1]
+
+	// W = f*G - e*K
+	W := suite.Point().Sub(suite.Point().Mul(f, nil), suite.Point().Mul(e, K))
+
+	expected, err := challenge(id, K, Cs, W)
+	if err != nil {
+		return xerrors.Errorf("failed to compute challenge: %v", err)
+	}
+
+	if !expected.Equal(e) {
+		return xerrors.New("challenge mismatch")
+	}
+
+	return nil
+}
+
+// challenge returns the challenge of the proof of knowledge of the ephemeral
+// scalar, which is the hash of the ID of the secret, of the ciphertext and of
+// the commitment.
+func challenge(id string, K kyber.Point, Cs []kyber.Point, W kyber.Point) (kyber.Scalar, error) {
+	hash := sha256.New()
+
+	length := make([]byte, 8)
+	binary.LittleEndian.PutUint64(length, uint64(len(id)))
+
+	hash.Write(length)
+	hash.Write([]byte(id))
+
+	for _, point := range append(append([]kyber.Point{K}, Cs...), W) {
+		_, err := point.MarshalTo(hash)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal point: %v", err)
+		}
+	}
+
+	return suite.Scalar().SetBytes(hash.Sum(nil)), nil
+}
+
+// Get returns the ciphertext of the secret stored in the snapshot.
+func Get(snap store.Readable, id string) (kyber.Point, []kyber.Point, error) {
+	value, err := prefixed.NewReadable(ContractUID, snap).Get(secretKey(id))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to read secret: %v", err)
+	}
+
+	if value == nil {
+		return nil, nil, xerrors.Errorf("secret '%s' not found", id)
+	}
+
+	K, Cs, err := DecodeCiphertext(string(value))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to decode secret: %v", err)
+	}
+
+	return K, Cs, nil
+}
+
+// readRecord is the value stored for a read request. It is the value of the
+// proof given to the DKG nodes.
+type readRecord struct {
+	// ID is the ID of the read transaction, which is part of the key of the
+	// record.
+	ID     []byte
+	Secret string
+	K      []byte
+	PubK   []byte `json:",omitempty"`
+}
+
+// Contract is a smart contract that stores the secrets and the read requests
+// of the readers.
+//
+// - implements native.Contract
+type Contract struct {
+	// access is the access control service managing this smart contract
+	access access.Service
+}
+
+// NewContract creates a new secret contract
+func NewContract(srvc access.Service) Contract {
+	return Contract{
+		access: srvc,
+	}
+}
+
+// Execute implements native.Contract. It runs the appropriate command.
+func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	cmd := step.Current.GetArg(CmdArg)
+	if len(cmd) == 0 {
+		return xerrors.Errorf("'%s' not found in tx arg", CmdArg)
+	}
+
+	// The secrets are written with the credentials of the contract, but each
+	// secret is read with its own credentials.
+	creds := NewCreds()
+	if Command(cmd) == CmdRead {
+		creds = NewReadCreds(string(step.Current.GetArg(IDArg)))
+	}
+
+	err := c.access.Match(snap, creds, step.Current.GetIdentity())
+	if err != nil {
+		return xerrors.Errorf("identity not authorized: %v (%v)",
+			step.Current.GetIdentity(), err)
+	}
+
+	snap = prefixed.NewSnapshot(ContractUID, snap)
+
+	switch Command(cmd) {
+	case CmdWrite:
+		err := c.write(snap, step)
+		if err != nil {
+			return xerrors.Errorf("failed to WRITE: %v", err)
+		}
+	case CmdRead:
+		err := c.read(snap, step)
+		if err != nil {
+			return xerrors.Errorf("failed to READ: %v", err)
+		}
+	default:
+		return xerrors.Errorf("unknown command: %s", cmd)
+	}
+
+	return nil
+}
+
+// UID returns the unique 4-bytes contract identifier.
+//
+// - implements native.Contract
+func (c Contract) UID() string {
+	return ContractUID
+}
+
+// write performs the WRITE command. A secret can't be overwritten, and the
+// ciphertext must come with the proof of knowledge of its ephemeral scalar for
+// the ID of the secret. The ephemeral key of a ciphertext is recorded so that
+// it can't be stored again.
+func (c Contract) write(snap store.Snapshot, step execution.Step) error {
+	id := string(step.Current.GetArg(IDArg))
+	if id == "" {
+		return xerrors.Errorf("'%s' not found in tx arg", IDArg)
+	}
+
+	current, err := snap.Get(secretKey(id))
+	if err != nil {
+		return xerrors.Errorf("failed to read secret: %v", err)
+	}
+
+	if current != nil {
+		return xerrors.Errorf("secret '%s' already exists", id)
+	}
+
+	ciphertext := step.Current.GetArg(CiphertextArg)
+
+	K, Cs, err := DecodeCiphertext(string(ciphertext))
+	if err != nil {
+		return xerrors.Errorf("failed to decode ciphertext: %v", err)
+	}
+
+	proof := step.Current.GetArg(ProofArg)
+	if len(proof) == 0 {
+		return xerrors.Errorf("'%s' not found in tx arg", ProofArg)
+	}
+
+	err = verifyCiphertext(id, K, Cs, string(proof))
+	if err != nil {
+		return xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	data, err := K.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal K: %v", err)
+	}
+
+	owner, err := snap.Get(ephemeralKey(data))
+	if err != nil {
+		return xerrors.Errorf("failed to read ephemeral key: %v", err)
+	}
+
+	if owner != nil {
+		return xerrors.Errorf("ephemeral key already used by secret '%s'", owner)
+	}
+
+	err = snap.Set(secretKey(id), ciphertext)
+	if err != nil {
+		return xerrors.Errorf("failed to set secret: %v", err)
+	}
+
+	err = snap.Set(ephemeralKey(data), []byte(id))
+	if err != nil {
+		return xerrors.Errorf("failed to set ephemeral key: %v", err)
+	}
+
+	dela.Logger.Info().
+		Str("contract", ContractName).
+		Msgf("setting secret '%s'", id)
+
+	return nil
+}
+
+// read performs the READ command. It stores the record of the request under
+// the ID of the transaction so that the reader can get its proof.
+func (c Contract) read(snap store.Snapshot, step execution.Step) error {
+	id := string(step.Current.GetArg(IDArg))
+
+	value, err := snap.Get(secretKey(id))
+	if err != nil {
+		return xerrors.Errorf("failed to read secret: %v", err)
+	}
+
+	if value == nil {
+		return xerrors.Errorf("secret '%s' not found", id)
+	}
+
+	K, _, err := DecodeCiphertext(string(value))
+	if err != nil {
+		return xerrors.Errorf("failed to decode secret: %v", err)
+	}
+
+	record := readRecord{
+		ID:     step.Current.GetID(),
+		Secret: id,
+	}
+
+	record.K, err = K.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal K: %v", err)
+	}
+
+	pubkey := step.Current.GetArg(PublicKeyArg)
+	if len(pubkey) == 0 {
+		return xerrors.Errorf("'%s' not found in tx arg", PublicKeyArg)
+	}
+
+	point, err := decodePoint(string(pubkey))
+	if err != nil {
+		return xerrors.Errorf("malformed public key: %v", err)
+	}
+
+	record.PubK, err = point.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal public key: %v", err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal record: %v", err)
+	}
+
+	err = snap.Set(readKey(record.ID), data)
+	if err != nil {
+		return xerrors.Errorf("failed to set read request: %v", err)
+	}
+
+	dela.Logger.Info().
+		Str("contract", ContractName).
+		Msgf("read request %x for secret '%s'", record.ID, id)
+
+	return nil
+}
+
+func decodePoint(str string) (kyber.Point, error) {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	point := suite.Point()
+
+	err = point.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return point, nil
+}
+
+func secretKey(id string) []byte {
+	return append(append([]byte{}, keySecret...), id...)
+}
+
+func ephemeralKey(K []byte) []byte {
+	return append(append([]byte{}, keyEphemeral...), K...)
+}
+
+func readKey(txID []byte) []byte {
+	return append(append([]byte{}, keyRead...), txID...)
+}
@@ -0,0 +1,365 @@
+package secret
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/prefixed"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestExecute(t *testing.T) {
+	contract := NewContract(fakeAccess{err: fake.GetError()})
+
+	err := contract.Execute(fake.NewSnapshot(), makeStep(t, CmdArg, "WRITE"))
+	require.EqualError(t, err,
+		"identity not authorized: fake.PublicKey ("+fake.GetError().Error()+")")
+
+	contract = NewContract(fakeAccess{})
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t))
+	require.EqualError(t, err, "'secret:command' not found in tx arg")
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, CmdArg, "WRITE", IDArg, "a"))
+	require.EqualError(t, err, fake.Err("failed to WRITE: failed to read secret"))
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, CmdArg, "READ"))
+	require.EqualError(t, err, fake.Err("failed to READ: failed to read secret"))
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, CmdArg, "fake"))
+	require.EqualError(t, err, "unknown command: fake")
+}
+
+func TestExecute_Credentials(t *testing.T) {
+	srvc := &recordAccess{}
+	contract := NewContract(srvc)
+
+	contract.Execute(fake.NewSnapshot(), makeStep(t, CmdArg, "WRITE", IDArg, "abc"))
+	require.Equal(t, NewCreds(), srvc.creds)
+
+	contract.Execute(fake.NewSnapshot(), makeStep(t, CmdArg, "READ", IDArg, "abc"))
+	require.Equal(t, NewReadCreds("abc"), srvc.creds)
+	require.Equal(t, []byte("abc"), srvc.creds.GetID())
+	require.Equal(t, "go.dedis.ch/dela.Secret:read", srvc.creds.GetRule())
+}
+
+func TestScenario(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	snap := fake.NewSnapshot()
+
+	K, _, ciphertext, proof := makeSecret(t, "abc", 2)
+
+	err := contract.Execute(snap, makeStep(t, CmdArg, "WRITE", IDArg, "abc",
+		CiphertextArg, ciphertext, ProofArg, proof))
+	require.NoError(t, err)
+
+	storedK, storedCs, err := Get(snap, "abc")
+	require.NoError(t, err)
+	require.True(t, K.Equal(storedK))
+	require.Len(t, storedCs, 2)
+
+	pubk := suite.Point().Pick(suite.RandomStream())
+	tx := makeTx(t, CmdArg, "READ", IDArg, "abc", PublicKeyArg, encodePoint(t, pubk))
+
+	err = contract.Execute(snap, execution.Step{Current: tx})
+	require.NoError(t, err)
+
+	value, err := snap.Get(ReadKey(tx.GetID()))
+	require.NoError(t, err)
+
+	var record readRecord
+	require.NoError(t, json.Unmarshal(value, &record))
+	require.Equal(t, tx.GetID(), record.ID)
+	require.Equal(t, "abc", record.Secret)
+	require.Equal(t, marshalPoint(t, K), record.K)
+	require.Equal(t, marshalPoint(t, pubk), record.PubK)
+
+	_, _, err = Get(snap, "def")
+	require.EqualError(t, err, "secret 'def' not found")
+
+	_, _, err = Get(fake.NewBadSnapshot(), "abc")
+	require.EqualError(t, err, fake.Err("failed to read secret"))
+
+	snap.Set(prefixed.NewPrefixedKey([]byte(ContractUID), secretKey("def")), []byte("abc"))
+
+	_, _, err = Get(snap, "def")
+	require.EqualError(t, err, "failed to decode secret: malformed ciphertext 'abc'")
+}
+
+func TestContract_Write(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	snap := fake.NewSnapshot()
+
+	_, _, ciphertext, proof := makeSecret(t, "abc", 1)
+
+	err := contract.write(snap, makeStep(t))
+	require.EqualError(t, err, "'secret:id' not found in tx arg")
+
+	err = contract.write(snap, makeStep(t, IDArg, "abc", CiphertextArg, "zz"))
+	require.EqualError(t, err, "failed to decode ciphertext: malformed ciphertext 'zz'")
+
+	err = contract.write(snap, makeStep(t, IDArg, "abc", CiphertextArg, ciphertext))
+	require.EqualError(t, err, "'secret:proof' not found in tx arg")
+
+	snap.ErrWrite = fake.GetError()
+
+	err = contract.write(snap, makeStep(t, IDArg, "abc", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.EqualError(t, err, fake.Err("failed to set secret"))
+
+	snap.ErrWrite = nil
+
+	err = contract.write(snap, makeStep(t, IDArg, "abc", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.NoError(t, err)
+
+	err = contract.write(snap, makeStep(t, IDArg, "abc", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.EqualError(t, err, "secret 'abc' already exists")
+}
+
+func TestContract_WriteProof(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	snap := fake.NewSnapshot()
+
+	_, _, ciphertext, proof := makeSecret(t, "abc", 1)
+
+	// The proof of another ID can't be used to store the ciphertext.
+	err := contract.write(snap, makeStep(t, IDArg, "def", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.EqualError(t, err, "invalid proof: challenge mismatch")
+
+	err = contract.write(snap, makeStep(t, IDArg, "def", CiphertextArg, ciphertext,
+		ProofArg, "zz"))
+	require.EqualError(t, err, "invalid proof: malformed proof 'zz'")
+
+	err = contract.write(snap, makeStep(t, IDArg, "def", CiphertextArg, ciphertext,
+		ProofArg, "zz:aa"))
+	require.Error(t, err)
+	require.Regexp(t, "^invalid proof: malformed scalar 0: ", err.Error())
+
+	err = contract.write(snap, makeStep(t, IDArg, "def", CiphertextArg, ciphertext,
+		ProofArg, "aa:aa"))
+	require.Error(t, err)
+	require.Regexp(t, "^invalid proof: malformed scalar 0: ", err.Error())
+
+	err = contract.write(snap, makeStep(t, IDArg, "abc", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.NoError(t, err)
+
+	// The writer knows the ephemeral scalar but the key is already used.
+	k := suite.Scalar().Pick(suite.RandomStream())
+	K := suite.Point().Mul(k, nil)
+	Cs := []kyber.Point{suite.Point().Pick(suite.RandomStream())}
+
+	ciphertext, err = EncodeCiphertext(K, Cs)
+	require.NoError(t, err)
+
+	proof, err = ProveCiphertext("def", k, K, Cs)
+	require.NoError(t, err)
+
+	err = contract.write(snap, makeStep(t, IDArg, "def", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.NoError(t, err)
+
+	proof, err = ProveCiphertext("ghi", k, K, Cs)
+	require.NoError(t, err)
+
+	err = contract.write(snap, makeStep(t, IDArg, "ghi", CiphertextArg, ciphertext,
+		ProofArg, proof))
+	require.EqualError(t, err, "ephemeral key already used by secret 'def'")
+
+	_, err = ProveCiphertext("ghi", k, fakePoint{}, Cs)
+	require.EqualError(t, err, fake.Err("failed to compute challenge: failed to marshal point"))
+}
+
+func TestContract_Read(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	snap := fake.NewSnapshot()
+
+	K, Cs := makeCiphertext(1)
+
+	ciphertext, err := EncodeCiphertext(K, Cs)
+	require.NoError(t, err)
+
+	err = contract.read(snap, makeStep(t, IDArg, "abc"))
+	require.EqualError(t, err, "secret 'abc' not found")
+
+	snap.Set(secretKey("bad"), []byte("zz"))
+
+	err = contract.read(snap, makeStep(t, IDArg, "bad"))
+	require.EqualError(t, err, "failed to decode secret: malformed ciphertext 'zz'")
+
+	snap.Set(secretKey("abc"), []byte(ciphertext))
+
+	err = contract.read(snap, makeStep(t, IDArg, "abc"))
+	require.EqualError(t, err, "'secret:public_key' not found in tx arg")
+
+	err = contract.read(snap, makeStep(t, IDArg, "abc", PublicKeyArg, "aa"))
+	require.Error(t, err)
+	require.Regexp(t, "^malformed public key: ", err.Error())
+
+	pubk := suite.Point().Pick(suite.RandomStream())
+
+	snap.ErrWrite = fake.GetError()
+
+	err = contract.read(snap, makeStep(t, IDArg, "abc", PublicKeyArg, encodePoint(t, pubk)))
+	require.EqualError(t, err, fake.Err("failed to set read request"))
+
+	snap.ErrWrite = nil
+
+	tx := makeTx(t, IDArg, "abc", PublicKeyArg, encodePoint(t, pubk))
+
+	err = contract.read(snap, execution.Step{Current: tx})
+	require.NoError(t, err)
+
+	value, err := snap.Get(readKey(tx.GetID()))
+	require.NoError(t, err)
+
+	var record readRecord
+	require.NoError(t, json.Unmarshal(value, &record))
+	require.Equal(t, marshalPoint(t, K), record.K)
+	require.Equal(t, marshalPoint(t, pubk), record.PubK)
+}
+
+func TestCiphertext_Encoding(t *testing.T) {
+	K, Cs := makeCiphertext(3)
+
+	str, err := EncodeCiphertext(K, Cs)
+	require.NoError(t, err)
+
+	K2, Cs2, err := DecodeCiphertext(str)
+	require.NoError(t, err)
+	require.True(t, K.Equal(K2))
+	require.Len(t, Cs2, 3)
+
+	for i := range Cs {
+		require.True(t, Cs[i].Equal(Cs2[i]))
+	}
+
+	_, err = EncodeCiphertext(fakePoint{}, nil)
+	require.EqualError(t, err, fake.Err("failed to marshal point"))
+
+	_, _, err = DecodeCiphertext(encodePoint(t, K))
+	require.EqualError(t, err, "malformed ciphertext '"+encodePoint(t, K)+"'")
+
+	_, _, err = DecodeCiphertext(encodePoint(t, K) + ":zz")
+	require.Error(t, err)
+	require.Regexp(t, "^malformed point 1: encoding/hex", err.Error())
+
+	_, _, err = DecodeCiphertext("aa:" + encodePoint(t, K))
+	require.Error(t, err)
+	require.Regexp(t, "^malformed point 0: ", err.Error())
+}
+
+func TestContract_UID(t *testing.T) {
+	contract := NewContract(fakeAccess{})
+	require.Equal(t, ContractUID, contract.UID())
+}
+
+func TestRegisterContract(t *testing.T) {
+	RegisterContract(native.NewExecution(), Contract{})
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// makeSecret returns the ciphertext of a secret with its textual representation
+// and the proof of the writer for the ID.
+func makeSecret(t *testing.T, id string, n int) (kyber.Point, []kyber.Point, string, string) {
+	k := suite.Scalar().Pick(suite.RandomStream())
+	K := suite.Point().Mul(k, nil)
+
+	_, Cs := makeCiphertext(n)
+
+	ciphertext, err := EncodeCiphertext(K, Cs)
+	require.NoError(t, err)
+
+	proof, err := ProveCiphertext(id, k, K, Cs)
+	require.NoError(t, err)
+
+	return K, Cs, ciphertext, proof
+}
+
+func makeCiphertext(n int) (kyber.Point, []kyber.Point) {
+	Cs := make([]kyber.Point, n)
+	for i := range Cs {
+		Cs[i] = suite.Point().Pick(suite.RandomStream())
+	}
+
+	return suite.Point().Pick(suite.RandomStream()), Cs
+}
+
+func marshalPoint(t *testing.T, point kyber.Point) []byte {
+	data, err := point.MarshalBinary()
+	require.NoError(t, err)
+
+	return data
+}
+
+func encodePoint(t *testing.T, point kyber.Point) string {
+	return hex.EncodeToString(marshalPoint(t, point))
+}
+
+func makeStep(t *testing.T, args ...string) execution.Step {
+	return execution.Step{Current: makeTx(t, args...)}
+}
+
+func makeTx(t *testing.T, args ...string) txn.Transaction {
+	options := []signed.TransactionOption{}
+	for i := 0; i < len(args)-1; i += 2 {
+		options = append(options, signed.WithArg(args[i], []byte(args[i+1])))
+	}
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{}, options...)
+	require.NoError(t, err)
+
+	return tx
+}
+
+type fakeAccess struct {
+	access.Service
+
+	err error
+}
+
+func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
+	return srvc.err
+}
+
+// recordAccess is an access service that records the last credentials.
+type recordAccess struct {
+	access.Service
+
+	creds access.Credential
+}
+
+func (srvc *recordAccess) Match(s store.Readable, creds access.Credential,
+	_ ...access.Identity) error {
+
+	srvc.creds = creds
+
+	return nil
+}
+
+type fakePoint struct {
+	kyber.Point
+}
+
+func (fakePoint) MarshalBinary() ([]byte, error) {
+	return nil, fake.GetError()
+}
+
+func (fakePoint) MarshalTo(io.Writer) (int, error) {
+	return 0, fake.GetError()
+}
@@ -12,6 +12,7 @@ import (
 
 func init() {
 	types.RegisterPermissionFormat(serde.FormatJSON, permFormat{})
+	types.RegisterPermissionFormat(serde.FormatCBOR, permFormat{})
 }
 
 // PermissionJSON is the JSON message for a permission.
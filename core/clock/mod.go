@@ -0,0 +1,252 @@
+// Package clock defines the interface to read the time and to wait for it to
+// pass, so that the components depending on timeouts can be driven either by
+// the system time or by a virtual time in simulations.
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the interface to read the time and to wait for it to pass.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives the current time once the duration
+	// has elapsed.
+	After(d time.Duration) <-chan time.Time
+
+	// Sleep blocks until the duration has elapsed.
+	Sleep(d time.Duration)
+
+	// WithTimeout returns a copy of the parent context that is canceled once
+	// the duration has elapsed.
+	WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc)
+}
+
+// system is the clock of the operating system.
+//
+// - implements clock.Clock
+type system struct{}
+
+// NewSystem returns the clock of the operating system.
+func NewSystem() Clock {
+	return system{}
+}
+
+// Now implements clock.Clock. It returns the local time.
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+// After implements clock.Clock. It uses a system timer.
+func (system) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep implements clock.Clock. It pauses the current go-routine.
+func (system) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// WithTimeout implements clock.Clock. It returns a context with a deadline.
+func (system) WithTimeout(ctx context.Context,
+	d time.Duration) (context.Context, context.CancelFunc) {
+
+	return context.WithTimeout(ctx, d)
+}
+
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// Virtual is a clock where the time only moves forward when it is advanced.
+// The waiters are released in the order of their deadline, and in the order
+// they started to wait for the same deadline, so that a simulation driven by
+// the clock is reproducible.
+//
+// - implements clock.Clock
+type Virtual struct {
+	sync.Mutex
+
+	now     time.Time
+	seq     uint64
+	waiters []*waiter
+}
+
+// NewVirtual returns a virtual clock that starts at the given time.
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{
+		now: start,
+	}
+}
+
+// Now implements clock.Clock. It returns the virtual time.
+func (c *Virtual) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.now
+}
+
+// After implements clock.Clock. It returns a channel that receives the virtual
+// time once the clock has been advanced past the duration.
+func (c *Virtual) After(d time.Duration) <-chan time.Time {
+	return c.wait(d).ch
+}
+
+// Sleep implements clock.Clock. It blocks until the clock has been advanced
+// past the duration.
+func (c *Virtual) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// WithTimeout implements clock.Clock. It returns a context that is canceled
+// with context.DeadlineExceeded as the cause once the clock has been advanced
+// past the duration.
+func (c *Virtual) WithTimeout(ctx context.Context,
+	d time.Duration) (context.Context, context.CancelFunc) {
+
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	w := c.wait(d)
+
+	go func() {
+		select {
+		case <-w.ch:
+			cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			c.remove(w)
+		}
+	}()
+
+	// The waiter is removed before the cancel function returns so that the
+	// clock never moves to the deadline of a context that is already done.
+	return ctx, func() {
+		c.remove(w)
+		cancel(context.Canceled)
+	}
+}
+
+// Advance moves the time forward by the duration and releases the waiters in
+// the order of their deadline.
+func (c *Virtual) Advance(d time.Duration) {
+	c.Lock()
+	target := c.now.Add(d)
+	c.Unlock()
+
+	for c.releaseNext(target) {
+	}
+
+	c.Lock()
+	if target.After(c.now) {
+		c.now = target
+	}
+	c.Unlock()
+}
+
+// AdvanceNext moves the time forward to the earliest deadline and releases only
+// the first waiter of that deadline, so that a simulation can let the
+// components react to a timer before the next one is released. It returns
+// false if nobody is waiting.
+func (c *Virtual) AdvanceNext() bool {
+	next, ok := c.Next()
+	if !ok {
+		return false
+	}
+
+	return c.releaseNext(next)
+}
+
+// Next returns the earliest deadline of the waiters, or false if nobody is
+// waiting.
+func (c *Virtual) Next() (time.Time, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.waiters) == 0 {
+		return time.Time{}, false
+	}
+
+	return c.waiters[0].deadline, true
+}
+
+// Len returns the number of waiters.
+func (c *Virtual) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return len(c.waiters)
+}
+
+// Seq returns the number of waits that have been requested since the creation
+// of the clock, which tells if the components are still active.
+func (c *Virtual) Seq() uint64 {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.seq
+}
+
+func (c *Virtual) wait(d time.Duration) *waiter {
+	c.Lock()
+	defer c.Unlock()
+
+	c.seq++
+
+	w := &waiter{
+		deadline: c.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		w.ch <- c.now
+		return w
+	}
+
+	index := sort.Search(len(c.waiters), func(i int) bool {
+		return c.waiters[i].deadline.After(w.deadline)
+	})
+
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[index+1:], c.waiters[index:])
+	c.waiters[index] = w
+
+	return w
+}
+
+func (c *Virtual) remove(w *waiter) {
+	c.Lock()
+	defer c.Unlock()
+
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Virtual) releaseNext(target time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.waiters) == 0 || c.waiters[0].deadline.After(target) {
+		return false
+	}
+
+	w := c.waiters[0]
+	c.waiters = c.waiters[1:]
+
+	if w.deadline.After(c.now) {
+		c.now = w.deadline
+	}
+
+	w.ch <- c.now
+
+	return true
+}
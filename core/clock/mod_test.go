@@ -0,0 +1,116 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystem(t *testing.T) {
+	c := NewSystem()
+
+	require.WithinDuration(t, time.Now(), c.Now(), time.Second)
+
+	start := time.Now()
+	c.Sleep(10 * time.Millisecond)
+	<-c.After(10 * time.Millisecond)
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := c.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	<-ctx.Done()
+	require.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
+
+func TestVirtual_After(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewVirtual(start)
+
+	require.Equal(t, start, c.Now())
+
+	second := c.After(2 * time.Second)
+	first := c.After(time.Second)
+	third := c.After(2 * time.Second)
+
+	require.Equal(t, 3, c.Len())
+	require.Equal(t, uint64(3), c.Seq())
+
+	next, found := c.Next()
+	require.True(t, found)
+	require.Equal(t, start.Add(time.Second), next)
+
+	c.Advance(500 * time.Millisecond)
+	require.Equal(t, start.Add(500*time.Millisecond), c.Now())
+	require.Len(t, first, 0)
+
+	c.Advance(2 * time.Second)
+	require.Equal(t, start.Add(2500*time.Millisecond), c.Now())
+	require.Equal(t, start.Add(time.Second), <-first)
+	require.Equal(t, start.Add(2*time.Second), <-second)
+	require.Equal(t, start.Add(2*time.Second), <-third)
+
+	_, found = c.Next()
+	require.False(t, found)
+
+	// A non-positive duration is released immediately.
+	require.Equal(t, c.Now(), <-c.After(0))
+}
+
+func TestVirtual_AdvanceNext(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewVirtual(start)
+
+	require.False(t, c.AdvanceNext())
+
+	first := c.After(time.Second)
+	second := c.After(time.Second)
+
+	require.True(t, c.AdvanceNext())
+	require.Equal(t, start.Add(time.Second), c.Now())
+	require.Equal(t, start.Add(time.Second), <-first)
+	require.Len(t, second, 0)
+	require.Equal(t, 1, c.Len())
+
+	require.True(t, c.AdvanceNext())
+	require.Equal(t, start.Add(time.Second), <-second)
+	require.False(t, c.AdvanceNext())
+}
+
+func TestVirtual_Sleep(t *testing.T) {
+	c := NewVirtual(time.Unix(0, 0))
+
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return c.Len() == 1 }, time.Second, time.Millisecond)
+
+	c.Advance(time.Minute)
+	<-done
+}
+
+func TestVirtual_WithTimeout(t *testing.T) {
+	c := NewVirtual(time.Unix(0, 0))
+
+	ctx, cancel := c.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	c.Advance(time.Minute)
+
+	<-ctx.Done()
+	require.Equal(t, context.DeadlineExceeded, context.Cause(ctx))
+
+	ctx, cancel = c.WithTimeout(context.Background(), time.Minute)
+	require.Equal(t, 1, c.Len())
+
+	cancel()
+
+	<-ctx.Done()
+	require.Equal(t, context.Canceled, context.Cause(ctx))
+	require.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,396 @@
+// Package audit implements the verification of the chain stored in the
+// database of a node. The chain is replayed from the genesis block: the forward
+// links and their signatures are verified against the roster of each block,
+// and the transactions are executed again in a new tree to confirm that the
+// roots match the ones of the blocks. It allows a node to be audited while it
+// is stopped, for instance after an incident.
+package audit
+
+import (
+	"bytes"
+
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+// Param is the structure of the components required to audit a chain. They
+// must be built the same way as the ones of the node, so that the transactions
+// are executed by the same contracts.
+type Param struct {
+	// DB is the database of the node, which is only read.
+	DB kv.DB
+
+	// Scratch is an empty database where the tree is rebuilt.
+	Scratch kv.DB
+
+	Validation      validation.Service
+	Access          access.Service
+	RosterFactory   authority.Factory
+	LinkFactory     types.LinkFactory
+	VerifierFactory crypto.VerifierFactory
+
+	// HashFactory is the hash factory of the chain, which must be shared with
+	// the factories of the transactions and the links.
+	HashFactory *types.ChainHashFactory
+}
+
+// Report is the summary of an audit. When the audit fails, it describes the
+// part of the chain that has been verified before the divergence.
+type Report struct {
+	Genesis      types.Digest
+	Blocks       uint64
+	Transactions int
+	Rejected     int
+	Leaves       int
+}
+
+// Auditor verifies the chain of a database.
+type Auditor struct {
+	param   Param
+	context serde.Context
+}
+
+// NewAuditor creates a new auditor from the parameters.
+func NewAuditor(param Param) Auditor {
+	return Auditor{
+		param:   param,
+		context: json.NewContext(),
+	}
+}
+
+// Run replays the chain of the database from the genesis block. It returns the
+// report and the first divergence found as an error, if any.
+func (a Auditor) Run() (Report, error) {
+	report := Report{}
+
+	genstore := blockstore.NewGenesisDiskStore(a.param.DB,
+		types.NewGenesisFactory(a.param.RosterFactory))
+
+	err := genstore.Load()
+	if err != nil {
+		return report, xerrors.Errorf("failed to load genesis: %v", err)
+	}
+
+	if !genstore.Exists() {
+		return report, xerrors.New("genesis not found")
+	}
+
+	genesis, err := genstore.Get()
+	if err != nil {
+		return report, xerrors.Errorf("failed to read genesis: %v", err)
+	}
+
+	// The algorithm of the chain must be set before anything is hashed.
+	a.param.HashFactory.SetAlgorithm(genesis.GetHashAlgorithm())
+	a.param.HashFactory.SetFingerprintVersion(genesis.GetFingerprintVersion())
+
+	report.Genesis = genesis.GetHash()
+
+	tree, err := a.replayGenesis(genesis)
+	if err != nil {
+		return report, xerrors.Errorf("genesis: %v", err)
+	}
+
+	blocks := blockstore.NewDiskStore(a.param.DB, a.param.LinkFactory)
+
+	err = blocks.Load()
+	if err != nil {
+		return report, xerrors.Errorf("failed to load blocks: %v", err)
+	}
+
+	roster := genesis.GetRoster()
+	prev := genesis.GetHash()
+
+	for index := uint64(0); index < blocks.Len(); index++ {
+		link, err := blocks.GetByIndex(index)
+		if err != nil {
+			return report, xerrors.Errorf("block %d: %v", index, err)
+		}
+
+		tree, roster, err = a.replayBlock(link, index, prev, roster, tree, &report)
+		if err != nil {
+			return report, xerrors.Errorf("block %d: %v", index, err)
+		}
+
+		prev = link.GetTo()
+		report.Blocks++
+	}
+
+	err = a.compareState(tree, &report)
+	if err != nil {
+		return report, xerrors.Errorf("state: %v", err)
+	}
+
+	return report, nil
+}
+
+// replayGenesis builds the tree of the genesis block in the scratch database,
+// which must match the root of the genesis.
+func (a Auditor) replayGenesis(genesis types.Genesis) (hashtree.Tree, error) {
+	roster := genesis.GetRoster()
+
+	value, err := roster.Serialize(a.context)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize roster: %v", err)
+	}
+
+	tree := binprefix.NewMerkleTree(a.param.Scratch, binprefix.Nonce{},
+		binprefix.WithHashFactory(a.param.HashFactory))
+
+	stageTree, err := tree.Stage(func(snap store.Snapshot) error {
+		creds := viewchange.NewCreds()
+
+		iter := roster.PublicKeyIterator()
+		for iter.HasNext() {
+			err := a.param.Access.Grant(snap, creds, iter.GetNext())
+			if err != nil {
+				return xerrors.Errorf("failed to set access: %v", err)
+			}
+		}
+
+		err := snap.Set(viewchange.GetRosterKey(), value)
+		if err != nil {
+			return xerrors.Errorf("failed to store roster: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("while updating tree: %v", err)
+	}
+
+	root := types.Digest{}
+	copy(root[:], stageTree.GetRoot())
+
+	if root != genesis.GetRoot() {
+		return nil, xerrors.Errorf("mismatch tree root '%v' != '%v'", root, genesis.GetRoot())
+	}
+
+	err = stageTree.Commit()
+	if err != nil {
+		return nil, xerrors.Errorf("tree commit failed: %v", err)
+	}
+
+	return stageTree, nil
+}
+
+// replayBlock verifies the link to the block with the roster of the previous
+// block, then executes its transactions on the tree. It returns the tree and
+// the roster after the block.
+func (a Auditor) replayBlock(link types.BlockLink, index uint64, prev types.Digest,
+	roster authority.Authority, tree hashtree.Tree,
+	report *Report) (hashtree.Tree, authority.Authority, error) {
+
+	block := link.GetBlock()
+
+	if block.GetIndex() != index {
+		return nil, nil, xerrors.Errorf("mismatch index %d != %d", block.GetIndex(), index)
+	}
+
+	if link.GetFrom() != prev {
+		return nil, nil, xerrors.Errorf("mismatch from: '%v' != '%v'", link.GetFrom(), prev)
+	}
+
+	err := a.verifyLink(link, roster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txs := block.GetTransactions()
+	expected := block.GetData().GetTransactionResults()
+
+	stageTree, err := tree.Stage(func(snap store.Snapshot) error {
+		res, err := a.param.Validation.Validate(snap, txs)
+		if err != nil {
+			return xerrors.Errorf("validation failed: %v", err)
+		}
+
+		results := res.GetTransactionResults()
+
+		if len(results) != len(expected) {
+			return xerrors.Errorf("mismatch number of results %d != %d",
+				len(results), len(expected))
+		}
+
+		for i, r := range results {
+			accepted, reason := r.GetStatus()
+			recorded, recordedReason := expected[i].GetStatus()
+
+			if accepted != recorded {
+				// The reason of the rejection helps to find the cause.
+				if accepted {
+					reason = recordedReason
+				}
+
+				return xerrors.Errorf("mismatch status of tx %#x: %t != %t (%s)",
+					txs[i].GetID(), accepted, recorded, reason)
+			}
+
+			if !accepted {
+				report.Rejected++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, xerrors.Errorf("while updating tree: %v", err)
+	}
+
+	root := types.Digest{}
+	copy(root[:], stageTree.GetRoot())
+
+	if root != block.GetTreeRoot() {
+		return nil, nil, xerrors.Errorf("mismatch tree root '%v' != '%v'",
+			root, block.GetTreeRoot())
+	}
+
+	next, err := a.readRoster(stageTree)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	// The change set of the link is not covered by the signatures, therefore
+	// it must lead to the same roster as the one recorded in the tree.
+	applied := roster.Apply(link.GetChangeSet())
+
+	same, err := sameRoster(applied, roster.Apply(roster.Diff(next)))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to compare rosters: %v", err)
+	}
+
+	if !same {
+		return nil, nil, xerrors.New("mismatch change set with the roster of the tree")
+	}
+
+	err = stageTree.Commit()
+	if err != nil {
+		return nil, nil, xerrors.Errorf("tree commit failed: %v", err)
+	}
+
+	report.Transactions += len(txs)
+
+	return stageTree, applied, nil
+}
+
+// verifyLink verifies the prepare and the commit signatures of the link with
+// the roster.
+func (a Auditor) verifyLink(link types.Link, roster authority.Authority) error {
+	verifier, err := a.param.VerifierFactory.FromAuthority(roster)
+	if err != nil {
+		return xerrors.Errorf("verifier factory failed: %v", err)
+	}
+
+	if link.GetPrepareSignature() == nil {
+		return xerrors.New("unexpected nil prepare signature in link")
+	}
+
+	if link.GetCommitSignature() == nil {
+		return xerrors.New("unexpected nil commit signature in link")
+	}
+
+	err = verifier.Verify(link.GetHash().Bytes(), link.GetPrepareSignature())
+	if err != nil {
+		return xerrors.Errorf("invalid prepare signature: %v", err)
+	}
+
+	msg, err := link.GetPrepareSignature().MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal signature: %v", err)
+	}
+
+	err = verifier.Verify(msg, link.GetCommitSignature())
+	if err != nil {
+		return xerrors.Errorf("invalid commit signature: %v", err)
+	}
+
+	return nil
+}
+
+func (a Auditor) readRoster(tree hashtree.Tree) (authority.Authority, error) {
+	data, err := tree.Get(viewchange.GetRosterKey())
+	if err != nil {
+		return nil, xerrors.Errorf("read from tree: %v", err)
+	}
+
+	roster, err := a.param.RosterFactory.AuthorityOf(a.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decode failed: %v", err)
+	}
+
+	return roster, nil
+}
+
+// compareState compares the leaves of the tree of the node with the ones of
+// the rebuilt tree.
+func (a Auditor) compareState(tree hashtree.Tree, report *Report) error {
+	opt := binprefix.WithHashFactory(a.param.HashFactory)
+
+	rebuilt := binprefix.NewMerkleTree(a.param.Scratch, binprefix.Nonce{}, opt)
+
+	expected := 0
+
+	err := rebuilt.WalkLeaves(func(key, value []byte) error {
+		expected++
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to read rebuilt tree: %v", err)
+	}
+
+	stored := binprefix.NewMerkleTree(a.param.DB, binprefix.Nonce{}, opt)
+
+	err = stored.WalkLeaves(func(key, value []byte) error {
+		other, err := tree.Get(key)
+		if err != nil {
+			return xerrors.Errorf("failed to read key %#x: %v", key, err)
+		}
+
+		if !bytes.Equal(value, other) {
+			return xerrors.Errorf("mismatch value of key %#x", key)
+		}
+
+		report.Leaves++
+
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("while comparing the tree: %v", err)
+	}
+
+	if report.Leaves != expected {
+		return xerrors.Errorf("mismatch number of leaves %d != %d", report.Leaves, expected)
+	}
+
+	return nil
+}
+
+func sameRoster(a, b authority.Authority) (bool, error) {
+	bufA := new(bytes.Buffer)
+
+	err := a.Fingerprint(bufA)
+	if err != nil {
+		return false, xerrors.Errorf("couldn't fingerprint: %v", err)
+	}
+
+	bufB := new(bytes.Buffer)
+
+	err = b.Fingerprint(bufB)
+	if err != nil {
+		return false, xerrors.Errorf("couldn't fingerprint: %v", err)
+	}
+
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes()), nil
+}
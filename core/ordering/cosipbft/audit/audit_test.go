@@ -0,0 +1,361 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/access/darc"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+const testContractName = "test"
+
+func TestAuditor_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	db, scratch := makeDBs(t)
+	makeChain(t, db, signer, signer, nil)
+
+	auditor := NewAuditor(makeParam(db, scratch, signer, makeExec()))
+
+	report, err := auditor.Run()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), report.Blocks)
+	require.Equal(t, 3, report.Transactions)
+	require.Equal(t, 1, report.Rejected)
+	require.Equal(t, 5, report.Leaves)
+	require.NotEqual(t, types.Digest{}, report.Genesis)
+}
+
+func TestAuditor_NoGenesis_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	db, scratch := makeDBs(t)
+
+	auditor := NewAuditor(makeParam(db, scratch, signer, makeExec()))
+
+	_, err := auditor.Run()
+	require.EqualError(t, err, "genesis not found")
+}
+
+func TestAuditor_BadGenesis_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	db, scratch := makeDBs(t)
+	makeChain(t, db, signer, signer, nil)
+
+	param := makeParam(db, scratch, signer, makeExec())
+	param.Access = badAccess{}
+
+	_, err := NewAuditor(param).Run()
+	require.EqualError(t, err, fake.Err("genesis: while updating tree: "+
+		"callback failed: failed to set access"))
+
+	param.Access = badAccess{skip: true}
+
+	_, err = NewAuditor(param).Run()
+	require.Error(t, err)
+	require.Regexp(t, "^genesis: mismatch tree root", err.Error())
+}
+
+func TestAuditor_BadSignature_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	db, scratch := makeDBs(t)
+	makeChain(t, db, signer, bls.NewSigner(), nil)
+
+	report, err := NewAuditor(makeParam(db, scratch, signer, makeExec())).Run()
+	require.Error(t, err)
+	require.Regexp(t, "^block 0: invalid prepare signature: ", err.Error())
+	require.Equal(t, uint64(0), report.Blocks)
+}
+
+func TestAuditor_BadExecution_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	db, scratch := makeDBs(t)
+	makeChain(t, db, signer, signer, nil)
+
+	// Without the contract, the transaction is rejected.
+	param := makeParam(db, scratch, signer, native.NewExecution())
+
+	_, err := NewAuditor(param).Run()
+	require.Error(t, err)
+	require.Regexp(t, "^block 0: while updating tree: callback failed: "+
+		"mismatch status of tx 0x[0-9a-f]+: false != true "+
+		"\\(failed to execute transaction: unknown contract 'test'\\)$", err.Error())
+
+	// The contract writes a different value, therefore the root is different.
+	exec := native.NewExecution()
+	exec.Set(testContractName, testContract{suffix: "!"})
+
+	db, scratch = makeDBs(t)
+	makeChain(t, db, signer, signer, nil)
+
+	_, err = NewAuditor(makeParam(db, scratch, signer, exec)).Run()
+	require.Error(t, err)
+	require.Regexp(t, "^block 0: mismatch tree root", err.Error())
+}
+
+func TestAuditor_BadChangeSet_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	cs := authority.NewChangeSet()
+	cs.Add(fake.NewAddress(1), bls.NewSigner().GetPublicKey())
+
+	db, scratch := makeDBs(t)
+	makeChain(t, db, signer, signer, cs)
+
+	report, err := NewAuditor(makeParam(db, scratch, signer, makeExec())).Run()
+	require.EqualError(t, err, "block 1: mismatch change set with the roster of the tree")
+	require.Equal(t, uint64(1), report.Blocks)
+	require.Equal(t, 2, report.Transactions)
+}
+
+func TestAuditor_BadState_Run(t *testing.T) {
+	signer := bls.NewSigner()
+
+	db, scratch := makeDBs(t)
+	makeChain(t, db, signer, signer, nil)
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+	require.NoError(t, tree.Load())
+
+	stage, err := tree.Stage(func(snap store.Snapshot) error {
+		return snap.Set([]byte("C"), []byte("3"))
+	})
+	require.NoError(t, err)
+	require.NoError(t, stage.Commit())
+
+	report, err := NewAuditor(makeParam(db, scratch, signer, makeExec())).Run()
+	require.EqualError(t, err, "state: while comparing the tree: mismatch value of key 0x43")
+	require.Equal(t, uint64(2), report.Blocks)
+
+	stage, err = stage.Stage(func(snap store.Snapshot) error {
+		require.NoError(t, snap.Delete([]byte("C")))
+		return snap.Delete([]byte("A"))
+	})
+	require.NoError(t, err)
+	require.NoError(t, stage.Commit())
+
+	_, scratch = makeDBs(t)
+
+	_, err = NewAuditor(makeParam(db, scratch, signer, makeExec())).Run()
+	require.EqualError(t, err, "state: mismatch number of leaves 4 != 5")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeDBs(t *testing.T) (kv.DB, kv.DB) {
+	dir, err := os.MkdirTemp(os.TempDir(), "dela-audit")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	db, err := kv.New(filepath.Join(dir, "dela.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	scratch, err := kv.New(filepath.Join(dir, "scratch.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { scratch.Close() })
+
+	return db, scratch
+}
+
+func makeExec() *native.Service {
+	exec := native.NewExecution()
+	exec.Set(testContractName, testContract{})
+
+	return exec
+}
+
+func makeParam(db, scratch kv.DB, signer bls.Signer, exec *native.Service) Param {
+	hashFac := types.NewChainHashFactory()
+	hashOpt := types.WithFactoryHash(hashFac)
+
+	txFac := signed.NewTransactionFactory(signed.WithFactoryHash(hashFac))
+	vs := simple.NewService(exec, txFac)
+
+	rosterFac := authority.NewFactory(fake.AddressFactory{}, signer.GetPublicKeyFactory())
+
+	blockFac := types.NewBlockFactory(vs.GetFactory(), hashOpt)
+	csFac := authority.NewChangeSetFactory(fake.AddressFactory{}, signer.GetPublicKeyFactory())
+	linkFac := types.NewLinkFactory(blockFac, signer.GetSignatureFactory(), csFac, hashOpt)
+
+	return Param{
+		DB:              db,
+		Scratch:         scratch,
+		Validation:      vs,
+		Access:          darc.NewService(json.NewContext()),
+		RosterFactory:   rosterFac,
+		LinkFactory:     linkFac,
+		VerifierFactory: signer.GetVerifierFactory(),
+		HashFactory:     hashFac,
+	}
+}
+
+// makeChain writes a chain of two blocks to the database, in the same way as a
+// node. The roster is made of the member and the links are signed by the
+// signer. The change set, if any, is set to the last link.
+func makeChain(t *testing.T, db kv.DB, member bls.Signer, signer crypto.Signer,
+	cs authority.ChangeSet) {
+
+	hashFac := types.NewChainHashFactory()
+	vs := simple.NewService(makeExec(), signed.NewTransactionFactory())
+
+	roster := authority.New([]mino.Address{fake.NewAddress(0)},
+		[]crypto.PublicKey{member.GetPublicKey()})
+
+	value, err := roster.Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{}, binprefix.WithHashFactory(hashFac))
+
+	stage, err := tree.Stage(func(snap store.Snapshot) error {
+		srvc := darc.NewService(json.NewContext())
+
+		err := srvc.Grant(snap, viewchange.NewCreds(), member.GetPublicKey())
+		require.NoError(t, err)
+
+		return snap.Set(viewchange.GetRosterKey(), value)
+	})
+	require.NoError(t, err)
+	require.NoError(t, stage.Commit())
+
+	root := types.Digest{}
+	copy(root[:], stage.GetRoot())
+
+	genesis, err := types.NewGenesis(roster, types.WithGenesisRoot(root))
+	require.NoError(t, err)
+
+	rosterFac := authority.NewFactory(fake.AddressFactory{}, member.GetPublicKeyFactory())
+
+	genstore := blockstore.NewGenesisDiskStore(db, types.NewGenesisFactory(rosterFac))
+	require.NoError(t, genstore.Set(genesis))
+
+	csFac := authority.NewChangeSetFactory(fake.AddressFactory{}, member.GetPublicKeyFactory())
+	blockFac := types.NewBlockFactory(vs.GetFactory())
+	linkFac := types.NewLinkFactory(blockFac, member.GetSignatureFactory(), csFac)
+
+	blocks := blockstore.NewDiskStore(db, linkFac)
+
+	batches := [][]txn.Transaction{
+		{
+			makeTx(t, member, 0, "A", "1"),
+			makeTx(t, member, 5, "Z", "0"),
+		},
+		{
+			makeTx(t, member, 1, "B", "2"),
+		},
+	}
+
+	prev := genesis.GetHash()
+
+	for index, txs := range batches {
+		var res simple.Result
+
+		stage, err = stage.Stage(func(snap store.Snapshot) error {
+			r, err := vs.Validate(snap, txs)
+			res = r.(simple.Result)
+
+			return err
+		})
+		require.NoError(t, err)
+
+		copy(root[:], stage.GetRoot())
+
+		block, err := types.NewBlock(res, types.WithIndex(uint64(index)),
+			types.WithTreeRoot(root))
+		require.NoError(t, err)
+
+		opts := []types.LinkOption{}
+		if cs != nil && index == len(batches)-1 {
+			opts = append(opts, types.WithChangeSet(cs))
+		}
+
+		link, err := types.NewBlockLink(prev, block, opts...)
+		require.NoError(t, err)
+
+		prepare, err := signer.Sign(link.GetHash().Bytes())
+		require.NoError(t, err)
+
+		data, err := prepare.MarshalBinary()
+		require.NoError(t, err)
+
+		commit, err := signer.Sign(data)
+		require.NoError(t, err)
+
+		opts = append(opts, types.WithSignatures(prepare, commit))
+
+		link, err = types.NewBlockLink(prev, block, opts...)
+		require.NoError(t, err)
+
+		require.NoError(t, blocks.Store(link))
+		require.NoError(t, stage.Commit())
+
+		prev = link.GetTo()
+	}
+}
+
+func makeTx(t *testing.T, signer bls.Signer, nonce uint64, key, value string) txn.Transaction {
+	tx, err := signed.NewTransaction(nonce, signer.GetPublicKey(),
+		signed.WithArg(native.ContractArg, []byte(testContractName)),
+		signed.WithArg("key", []byte(key)),
+		signed.WithArg("value", []byte(value)))
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Sign(signer))
+
+	return tx
+}
+
+type testContract struct {
+	suffix string
+}
+
+func (c testContract) Execute(snap store.Snapshot, step execution.Step) error {
+	value := append(step.Current.GetArg("value"), c.suffix...)
+
+	return snap.Set(step.Current.GetArg("key"), value)
+}
+
+func (testContract) UID() string {
+	return "TEST"
+}
+
+type badAccess struct {
+	access.Service
+
+	skip bool
+}
+
+func (a badAccess) Grant(store.Snapshot, access.Credential, ...access.Identity) error {
+	if a.skip {
+		return nil
+	}
+
+	return fake.GetError()
+}
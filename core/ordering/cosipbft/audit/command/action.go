@@ -0,0 +1,136 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.dedis.ch/dela/cli"
+	accessContract "go.dedis.ch/dela/contracts/access"
+	accessController "go.dedis.ch/dela/contracts/access/controller"
+	"go.dedis.ch/dela/contracts/value"
+	"go.dedis.ch/dela/core/access/darc"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
+	"go.dedis.ch/dela/core/ordering/cosipbft/audit"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	threshold "go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/mino/minogrpc"
+	"go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+// action defines the cli action of the audit command. Defining the printer
+// helps in testing the command.
+type action struct {
+	printer   io.Writer
+	contracts []Contract
+}
+
+func (a action) chainAction(flags cli.Flags) error {
+	config := flags.Path("config")
+
+	db, err := kv.NewReadOnly(filepath.Join(config, "dela.db"))
+	if err != nil {
+		return xerrors.Errorf("failed to open database: %v", err)
+	}
+
+	defer db.Close()
+
+	// The identities of the access contract are the ones of today, which are
+	// used for every block of the chain.
+	accessStore, err := accessController.NewReadOnlyStore(filepath.Join(config, "access.json"))
+	if err != nil {
+		return xerrors.Errorf("failed to read access store: %v", err)
+	}
+
+	// The tree is rebuilt in a temporary database so that the one of the node
+	// is never written.
+	dir, err := os.MkdirTemp("", "dela-audit")
+	if err != nil {
+		return xerrors.Errorf("failed to create folder: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	scratch, err := kv.New(filepath.Join(dir, "tree.db"))
+	if err != nil {
+		return xerrors.Errorf("failed to open scratch database: %v", err)
+	}
+
+	defer scratch.Close()
+
+	report, err := audit.NewAuditor(makeParam(db, scratch, accessStore, a.contracts)).Run()
+
+	fmt.Fprintf(a.printer, "Genesis: %v\n", report.Genesis)
+	fmt.Fprintf(a.printer, "Blocks: %d\n", report.Blocks)
+	fmt.Fprintf(a.printer, "Transactions: %d (%d rejected)\n",
+		report.Transactions, report.Rejected)
+
+	if err != nil {
+		return xerrors.Errorf("divergence after %d block(s): %v", report.Blocks, err)
+	}
+
+	fmt.Fprintf(a.printer, "Leaves: %d\n", report.Leaves)
+	fmt.Fprintln(a.printer, "The chain is consistent")
+
+	return nil
+}
+
+// makeParam creates the components of a node to audit its chain. The value and
+// the access contracts are registered along with the given ones.
+func makeParam(db, scratch kv.DB, accessStore store.Readable,
+	contracts []Contract) audit.Param {
+
+	// The signer is only used for its factories, which support the algorithms
+	// a roster can use.
+	signer := common.NewSigner(bls.Algorithm, bls.NewSigner())
+	signer.Add(bls12381.Algorithm, bls12381.NewSigner())
+
+	addrFac := minogrpc.NewAddressFactory()
+
+	exec := native.NewExecution()
+	access := darc.NewService(json.NewContext())
+
+	rosterFac := authority.NewFactory(addrFac, signer.GetPublicKeyFactory())
+	cosipbft.RegisterRosterContract(exec, rosterFac, access)
+
+	value.RegisterContract(exec, value.NewContract(access))
+	accessContract.RegisterContract(exec, accessContract.NewContract(access, accessStore))
+
+	for _, register := range contracts {
+		register(exec, access)
+	}
+
+	hashFac := types.NewChainHashFactory()
+	hashOpt := types.WithFactoryHash(hashFac)
+
+	txFac := signed.NewTransactionFactory(signed.WithFactoryHash(hashFac))
+	vs := simple.NewService(exec, txFac)
+
+	sigFac := threshold.NewSignatureFactory(signer.GetSignatureFactory())
+
+	blockFac := types.NewBlockFactory(vs.GetFactory(), hashOpt)
+	csFac := authority.NewChangeSetFactory(addrFac, signer.GetPublicKeyFactory())
+	linkFac := types.NewLinkFactory(blockFac, sigFac, csFac, hashOpt)
+
+	return audit.Param{
+		DB:              db,
+		Scratch:         scratch,
+		Validation:      vs,
+		Access:          access,
+		RosterFactory:   rosterFac,
+		LinkFactory:     linkFac,
+		VerifierFactory: threshold.NewThresholdVerifierFactory(signer.GetVerifierFactory()),
+		HashFactory:     hashFac,
+	}
+}
@@ -0,0 +1,134 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/access/darc"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/serde/json"
+)
+
+func TestAction_Chain(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "dela-audit")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	makeGenesis(t, filepath.Join(dir, "dela.db"))
+
+	registered := 0
+	contract := func(exec *native.Service, access access.Service) {
+		require.NotNil(t, exec)
+		require.NotNil(t, access)
+		registered++
+	}
+
+	out := new(bytes.Buffer)
+	action := action{printer: out, contracts: []Contract{contract}}
+
+	flags := node.FlagSet{"config": dir}
+
+	err = action.chainAction(flags)
+	require.NoError(t, err)
+	require.Regexp(t, "^Genesis: [0-9a-f]{8}\nBlocks: 0\nTransactions: 0 \\(0 rejected\\)\n"+
+		"Leaves: 2\nThe chain is consistent\n$", out.String())
+	require.NoFileExists(t, filepath.Join(dir, "access.json"))
+	require.Equal(t, 1, registered)
+}
+
+func TestAction_BadChain(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "dela-audit")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	out := new(bytes.Buffer)
+	action := action{printer: out}
+
+	flags := node.FlagSet{"config": dir}
+
+	err = action.chainAction(flags)
+	require.Error(t, err)
+	require.Regexp(t, "^failed to open database: failed to open db: ", err.Error())
+
+	db, err := kv.New(filepath.Join(dir, "dela.db"))
+	require.NoError(t, err)
+
+	err = action.chainAction(flags)
+	require.EqualError(t, err, "failed to open database: database is in use")
+
+	require.NoError(t, db.Close())
+
+	err = action.chainAction(flags)
+	require.EqualError(t, err, "divergence after 0 block(s): genesis not found")
+	require.Contains(t, out.String(), "Blocks: 0\n")
+	require.NotContains(t, out.String(), "consistent")
+
+	err = os.WriteFile(filepath.Join(dir, "access.json"), []byte("{"), os.ModePerm)
+	require.NoError(t, err)
+
+	err = action.chainAction(flags)
+	require.Error(t, err)
+	require.Regexp(t, "^failed to read access store: failed to read json: ", err.Error())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// makeGenesis writes the genesis block of a chain and its tree to the database
+// in the same way as a node.
+func makeGenesis(t *testing.T, path string) {
+	db, err := kv.New(path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	signer := bls.NewSigner()
+
+	roster := authority.New([]mino.Address{session.NewAddress("127.0.0.1:2000")},
+		[]crypto.PublicKey{signer.GetPublicKey()})
+
+	value, err := roster.Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+
+	stage, err := tree.Stage(func(snap store.Snapshot) error {
+		srvc := darc.NewService(json.NewContext())
+
+		err := srvc.Grant(snap, viewchange.NewCreds(), signer.GetPublicKey())
+		require.NoError(t, err)
+
+		return snap.Set(viewchange.GetRosterKey(), value)
+	})
+	require.NoError(t, err)
+	require.NoError(t, stage.Commit())
+
+	root := types.Digest{}
+	copy(root[:], stage.GetRoot())
+
+	genesis, err := types.NewGenesis(roster, types.WithGenesisRoot(root))
+	require.NoError(t, err)
+
+	rosterFac := authority.NewFactory(session.AddressFactory{}, signer.GetPublicKeyFactory())
+
+	genstore := blockstore.NewGenesisDiskStore(db, types.NewGenesisFactory(rosterFac))
+	require.NoError(t, genstore.Set(genesis))
+}
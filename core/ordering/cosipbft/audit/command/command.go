@@ -0,0 +1,41 @@
+// Package command defines the cli commands to audit the chain of a node.
+package command
+
+import (
+	"os"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution/native"
+)
+
+// Contract registers a contract of the chain on the execution service that
+// replays the transactions.
+type Contract func(exec *native.Service, access access.Service)
+
+// Initializer implements the initializer of the audit commands. The value and
+// the access contracts are always registered, and the other contracts used by
+// the chain must be given so that their transactions can be replayed.
+//
+// - implements cli.Initializer
+type Initializer struct {
+	Contracts []Contract
+}
+
+// SetCommands implements cli.Initializer.
+func (i Initializer) SetCommands(provider cli.Provider) {
+	action := action{
+		printer:   os.Stdout,
+		contracts: i.Contracts,
+	}
+
+	cmd := provider.SetCommand("chain")
+	cmd.SetDescription("verify the chain in the database of a stopped node, " +
+		"and report the first divergence")
+	cmd.SetFlags(cli.StringFlag{
+		Name:     "config",
+		Usage:    "path to the config folder of the node",
+		Required: true,
+	})
+	cmd.SetAction(action.chainAction)
+}
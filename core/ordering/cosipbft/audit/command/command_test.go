@@ -0,0 +1,50 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestSetCommands(t *testing.T) {
+	init := Initializer{}
+
+	call := &fake.Call{}
+	provider := fakeBuilder{call: call}
+	init.SetCommands(provider)
+
+	require.Equal(t, 4, call.Len())
+	require.Equal(t, "chain", call.Get(0, 0))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeCommandBuilder struct {
+	cli.CommandBuilder
+
+	call *fake.Call
+}
+
+func (b fakeCommandBuilder) SetDescription(value string) {
+	b.call.Add(value)
+}
+
+func (b fakeCommandBuilder) SetFlags(flags ...cli.Flag) {
+	b.call.Add(flags)
+}
+
+func (b fakeCommandBuilder) SetAction(a cli.Action) {
+	b.call.Add(a)
+}
+
+type fakeBuilder struct {
+	call *fake.Call
+}
+
+func (b fakeBuilder) SetCommand(name string) cli.CommandBuilder {
+	b.call.Add(name)
+	return fakeCommandBuilder{call: b.call}
+}
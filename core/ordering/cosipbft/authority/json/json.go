@@ -13,6 +13,8 @@ import (
 func init() {
 	authority.RegisterChangeSetFormat(serde.FormatJSON, changeSetFormat{})
 	authority.RegisterRosterFormat(serde.FormatJSON, rosterFormat{})
+	authority.RegisterChangeSetFormat(serde.FormatCBOR, changeSetFormat{})
+	authority.RegisterRosterFormat(serde.FormatCBOR, rosterFormat{})
 }
 
 // Player is a JSON message that contains the address and the public key of a
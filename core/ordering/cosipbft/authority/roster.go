@@ -7,11 +7,13 @@ package authority
 
 import (
 	"io"
+	"sort"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
@@ -104,29 +106,28 @@ func FromAuthority(authority crypto.CollectiveAuthority) Roster {
 	return New(addrs, pubkeys)
 }
 
-// Fingerprint implements serde.Fingerprinter. It marshals the roster and writes
-// the result in the given writer.
+// Fingerprint implements serde.Fingerprinter. It writes the canonical encoding
+// of the list of the participants, each with its address and its public key.
 func (r Roster) Fingerprint(w io.Writer) error {
+	participants := make([]interface{}, len(r.addrs))
+
 	for i, addr := range r.addrs {
-		data, err := addr.MarshalText()
+		addrData, err := addr.MarshalText()
 		if err != nil {
 			return xerrors.Errorf("couldn't marshal address: %v", err)
 		}
 
-		_, err = w.Write(data)
-		if err != nil {
-			return xerrors.Errorf("couldn't write address: %v", err)
-		}
-
-		data, err = r.pubkeys[i].MarshalBinary()
+		pubkeyData, err := r.pubkeys[i].MarshalBinary()
 		if err != nil {
 			return xerrors.Errorf("couldn't marshal public key: %v", err)
 		}
 
-		_, err = w.Write(data)
-		if err != nil {
-			return xerrors.Errorf("couldn't write public key: %v", err)
-		}
+		participants[i] = [][]byte{addrData, pubkeyData}
+	}
+
+	err := canonical.Write(w, participants...)
+	if err != nil {
+		return xerrors.Errorf("couldn't write roster: %v", err)
 	}
 
 	return nil
@@ -183,7 +184,8 @@ func (r Roster) Apply(in ChangeSet) Authority {
 }
 
 // Diff implements authority.Authority. It returns the change set that must be
-// applied to the current authority to get the given one.
+// applied to the current authority to get the given one. A member that keeps
+// its address but changes its public key is removed and added again.
 func (r Roster) Diff(o Authority) ChangeSet {
 	changeset := NewChangeSet()
 
@@ -196,7 +198,13 @@ func (r Roster) Diff(o Authority) ChangeSet {
 	k := 0
 	for i < len(r.addrs) || k < len(other.addrs) {
 		if i < len(r.addrs) && k < len(other.addrs) {
-			if r.addrs[i].Equal(other.addrs[k]) {
+			if r.addrs[i].Equal(other.addrs[k]) && r.pubkeys[i].Equal(other.pubkeys[k]) {
+				i++
+				k++
+			} else if r.addrs[i].Equal(other.addrs[k]) {
+				changeset.remove = append(changeset.remove, uint(i))
+				changeset.addrs = append(changeset.addrs, other.addrs[k])
+				changeset.pubkeys = append(changeset.pubkeys, other.pubkeys[k])
 				i++
 				k++
 			} else {
@@ -213,6 +221,12 @@ func (r Roster) Diff(o Authority) ChangeSet {
 		}
 	}
 
+	// The removals are applied in descending order so that the indices stay
+	// valid.
+	sort.Slice(changeset.remove, func(i, j int) bool {
+		return changeset.remove[i] > changeset.remove[j]
+	})
+
 	return changeset
 }
 
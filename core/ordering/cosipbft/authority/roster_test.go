@@ -100,7 +100,9 @@ func TestRoster_Fingerprint(t *testing.T) {
 	out := new(bytes.Buffer)
 	err := roster.Fingerprint(out)
 	require.NoError(t, err)
-	require.Equal(t, "\x00\x00\x00\x00PK\x01\x00\x00\x00PK", out.String())
+	// [[h'00000000', h'504b'], [h'01000000', h'504b']]
+	require.Equal(t, "\x82\x82\x44\x00\x00\x00\x00\x42PK\x82\x44\x01\x00\x00\x00\x42PK",
+		out.String())
 
 	roster.addrs[0] = fake.NewBadAddress()
 	err = roster.Fingerprint(out)
@@ -113,10 +115,7 @@ func TestRoster_Fingerprint(t *testing.T) {
 
 	roster.pubkeys[0] = fake.PublicKey{}
 	err = roster.Fingerprint(fake.NewBadHash())
-	require.EqualError(t, err, fake.Err("couldn't write address"))
-
-	err = roster.Fingerprint(fake.NewBadHashWithDelay(1))
-	require.EqualError(t, err, fake.Err("couldn't write public key"))
+	require.EqualError(t, err, fake.Err("couldn't write roster: failed to write"))
 }
 
 func TestRoster_Take(t *testing.T) {
@@ -163,9 +162,18 @@ func TestRoster_Diff(t *testing.T) {
 	roster4 := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
 	roster4.addrs[1] = fake.NewAddress(5)
 	diff = roster1.Diff(roster4).(*RosterChangeSet)
-	require.Equal(t, []uint{1, 2}, diff.remove)
+	require.Equal(t, []uint{2, 1}, diff.remove)
 	require.Len(t, diff.addrs, 2)
 	require.Len(t, diff.pubkeys, 2)
+	require.Equal(t, roster4, roster1.Apply(diff))
+
+	// A member that changes its public key is replaced.
+	roster5 := FromAuthority(fake.NewAuthority(3, bls.Generate))
+	roster6 := FromAuthority(fake.NewAuthority(3, bls.Generate))
+	diff = roster5.Diff(roster6).(*RosterChangeSet)
+	require.Equal(t, []uint{2, 1, 0}, diff.remove)
+	require.Len(t, diff.addrs, 3)
+	require.Equal(t, roster6, roster5.Apply(diff))
 
 	diff = roster1.Diff((Authority)(nil)).(*RosterChangeSet)
 	require.Equal(t, NewChangeSet(), diff)
@@ -171,6 +171,9 @@ func (s *InDisk) GetByIndex(index uint64) (link types.BlockLink, err error) {
 
 	err = s.doView(func(tx kv.ReadableTx) error {
 		bucket := tx.GetBucket(s.bucket)
+		if bucket == nil {
+			return xerrors.Errorf("index %d not found: %w", index, ErrNoBlock)
+		}
 
 		value := bucket.Get(key)
 
@@ -67,7 +67,7 @@ func TestInDisk_Store(t *testing.T) {
 	require.Len(t, store.indices, 2)
 
 	err = store.Store(makeLink(t, types.Digest{}))
-	require.EqualError(t, err, "mismatch digests '00000000' (new) != 'b68f5931' (last)")
+	require.EqualError(t, err, "mismatch digests '00000000' (new) != 'b738dbca' (last)")
 
 	store = NewDiskStore(db, makeBlockFac())
 	err = store.Store(badLink{})
@@ -106,7 +106,10 @@ func TestInDisk_GetByIndex(t *testing.T) {
 
 	store := NewDiskStore(db, makeBlockFac())
 
-	err := store.Store(makeLink(t, types.Digest{}, types.WithIndex(0)))
+	_, err := store.GetByIndex(0)
+	require.EqualError(t, err, "index 0 not found: no block")
+
+	err = store.Store(makeLink(t, types.Digest{}, types.WithIndex(0)))
 	require.NoError(t, err)
 
 	err = store.Store(makeLink(t, store.last.GetTo(), types.WithIndex(1)))
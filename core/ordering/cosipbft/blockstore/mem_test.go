@@ -29,7 +29,7 @@ func TestInMemory_Store(t *testing.T) {
 	require.NoError(t, err)
 
 	err = store.Store(makeLink(t, types.Digest{}))
-	require.EqualError(t, err, "mismatch link '00000000' != '2c34ce1d'")
+	require.EqualError(t, err, "mismatch link '00000000' != 'f27d212c'")
 }
 
 func TestInMemory_Get(t *testing.T) {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blocksync/types"
 	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
@@ -53,6 +54,9 @@ type SyncParam struct {
 	LinkFactory     otypes.LinkFactory
 	ChainFactory    otypes.ChainFactory
 	VerifierFactory crypto.VerifierFactory
+	// Clock is optional and defaults to the system clock. Only the fast
+	// synchronizer reads it, as the block synchronizer has no timeout.
+	Clock clock.Clock
 }
 
 // NewSynchronizer creates a new block synchronizer.
@@ -5,6 +5,9 @@
 package viewchange
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/execution"
@@ -12,8 +15,11 @@ import (
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
-	"go.dedis.ch/dela/serde/json"
+	sjson "go.dedis.ch/dela/serde/json"
 	"golang.org/x/xerrors"
 )
 
@@ -28,6 +34,11 @@ const (
 	// AuthorityArg is the key of the argument for the new authority.
 	AuthorityArg = "viewchange:authority"
 
+	// ProofsArg is the key of the argument for the proofs of possession of the
+	// public keys that join the authority. It is a JSON object that maps the
+	// index of a member in the new authority to its serialized proof.
+	ProofsArg = "viewchange:proofs"
+
 	messageOnlyOne          = "only one view change per block is allowed"
 	messageArgMissing       = "authority not found in transaction"
 	messageStorageEmpty     = "authority not found in storage"
@@ -36,14 +47,23 @@ const (
 	messageStorageFailure   = "storage failure"
 	messageDuplicate        = "duplicate in roster"
 	messageUnauthorized     = "unauthorized identity"
+	messageMixedKeys        = "public keys of different types"
+	messageProofsInvalid    = "invalid proofs of possession"
+	messageProofMissing     = "missing proof of possession"
+	messageProofMismatch    = "invalid proof of possession"
+	messageSwapIdentity     = "only the member can swap its key"
 )
 
 var (
-	rosterKey [32]byte
+	rosterKey    [32]byte
+	migrationKey [32]byte
 )
 
 func init() {
 	copy(rosterKey[:4], ContractUID)
+
+	copy(migrationKey[:4], ContractUID)
+	migrationKey[4] = 1
 }
 
 // GetRosterKey returns the key used to store the roster in the K/V storage.
@@ -52,6 +72,12 @@ func GetRosterKey() []byte {
 	return rosterKey[:]
 }
 
+// GetMigrationKey returns the key used to store the roster of a migration in
+// progress, with the public keys already swapped by the members.
+func GetMigrationKey() []byte {
+	return migrationKey[:]
+}
+
 // RegisterContract registers the view change contract to the given execution
 // service.
 func RegisterContract(exec *native.Service, c Contract) {
@@ -74,22 +100,51 @@ type Manager struct {
 func NewManager(mgr txn.Manager) Manager {
 	return Manager{
 		manager: mgr,
-		context: json.NewContext(),
+		context: sjson.NewContext(),
 	}
 }
 
 // Make creates a new transaction using the provided manager. It contains the
 // new roster that the transaction should apply.
 func (mgr Manager) Make(roster authority.Authority) (txn.Transaction, error) {
+	return mgr.MakeWithProofs(roster, nil)
+}
+
+// MakeWithProofs creates a new transaction that contains the new roster and the
+// proofs of possession of the public keys that join it, indexed by their
+// position in the new roster.
+func (mgr Manager) MakeWithProofs(roster authority.Authority,
+	proofs map[int]crypto.Signature) (txn.Transaction, error) {
+
 	data, err := roster.Serialize(mgr.context)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to serialize roster: %v", err)
 	}
 
-	tx, err := mgr.manager.Make(
-		txn.Arg{Key: native.ContractArg, Value: []byte(ContractName)},
-		txn.Arg{Key: AuthorityArg, Value: data},
-	)
+	args := []txn.Arg{
+		{Key: native.ContractArg, Value: []byte(ContractName)},
+		{Key: AuthorityArg, Value: data},
+	}
+
+	if len(proofs) > 0 {
+		raw := make(map[int][]byte, len(proofs))
+
+		for index, proof := range proofs {
+			raw[index], err = proof.Serialize(mgr.context)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to serialize proof: %v", err)
+			}
+		}
+
+		data, err = json.Marshal(raw)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal proofs: %v", err)
+		}
+
+		args = append(args, txn.Arg{Key: ProofsArg, Value: data})
+	}
+
+	tx, err := mgr.manager.Make(args...)
 	if err != nil {
 		return nil, xerrors.Errorf("creating transaction: %v", err)
 	}
@@ -97,12 +152,44 @@ func (mgr Manager) Make(roster authority.Authority) (txn.Transaction, error) {
 	return tx, nil
 }
 
+// MakeSwap creates a new transaction that swaps the public key of the member
+// at the address for the one of the signer, which must be of another
+// algorithm. The proof of possession of the new key is included when the signer
+// supports it. The transaction must be signed by the current key of the
+// member.
+func (mgr Manager) MakeSwap(roster authority.Authority, addr mino.Address,
+	signer crypto.Signer) (txn.Transaction, error) {
+
+	_, index := roster.GetPublicKey(addr)
+	if index < 0 {
+		return nil, xerrors.Errorf("%v is not a member", addr)
+	}
+
+	var proofs map[int]crypto.Signature
+
+	prover, ok := signer.(crypto.PossessionProver)
+	if ok {
+		proof, err := prover.ProvePossession()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to prove possession: %v", err)
+		}
+
+		proofs = map[int]crypto.Signature{index: proof}
+	}
+
+	return mgr.MakeWithProofs(replaceKey(roster, addr, signer.GetPublicKey()), proofs)
+}
+
 // Contract is a contract to update the roster at a given key in the storage. It
-// only allows one member change per transaction.
+// only allows one member change per transaction. A migration to another
+// algorithm is done by every member swapping its own public key in a
+// transaction signed by its current key, and the roster is replaced once they
+// all have, as a roster can't mix algorithms.
 //
 // - implements native.Contract
 type Contract struct {
 	rosterFac authority.Factory
+	sigFac    crypto.SignatureFactory
 	access    access.Service
 	context   serde.Context
 }
@@ -111,14 +198,16 @@ type Contract struct {
 func NewContract(rFac authority.Factory, srvc access.Service) Contract {
 	return Contract{
 		rosterFac: rFac,
+		sigFac:    common.NewSignatureFactory(),
 		access:    srvc,
-		context:   json.NewContext(),
+		context:   sjson.NewContext(),
 	}
 }
 
 // Execute implements native.Contract. It looks for the roster in the
 // transaction and updates the storage if there is at most one membership
-// change.
+// change, or records the swap of the key of a member for a migration. Public
+// keys that support a proof of possession must provide one to join the roster.
 func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
 	for _, tx := range step.Previous {
 		// Only one view change transaction is allowed per block to prevent
@@ -149,6 +238,11 @@ func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
 		return xerrors.New(messageStorageCorrupted)
 	}
 
+	swapped := findSwap(curr, roster)
+	if swapped != nil {
+		return c.swapKey(snap, step.Current, curr, roster, swapped)
+	}
+
 	changeset := curr.Diff(roster)
 
 	if changeset.NumChanges() > 1 {
@@ -162,9 +256,16 @@ func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
 		}
 	}
 
-	creds := NewCreds()
+	if !sameKeyType(roster) {
+		return xerrors.New(messageMixedKeys)
+	}
 
-	err = c.access.Match(snap, creds, step.Current.GetIdentity())
+	err = c.verifyProofs(roster, changeset, step.Current.GetArg(ProofsArg))
+	if err != nil {
+		return err
+	}
+
+	err = c.access.Match(snap, NewCreds(), step.Current.GetIdentity())
 	if err != nil {
 		reportErr(step.Current, xerrors.Errorf("access control: %v", err))
 
@@ -188,6 +289,276 @@ func (c Contract) UID() string {
 	return ContractUID
 }
 
+// swapKey records the new public key of the member at the address, which it
+// swaps for one of another algorithm. The keys swapped by the members are kept
+// aside until every member has swapped its key, at which point the roster is
+// replaced by the new keys. Another change of the roster in between discards
+// the swaps.
+func (c Contract) swapKey(snap store.Snapshot, tx txn.Transaction, curr,
+	next authority.Authority, addr mino.Address) error {
+
+	// Only the member can swap its key, otherwise a single member could
+	// replace the keys of the others.
+	currKey, _ := curr.GetPublicKey(addr)
+	if !currKey.Equal(tx.GetIdentity()) {
+		return xerrors.Errorf("%s: %v", messageSwapIdentity, addr)
+	}
+
+	pubkey, _ := next.GetPublicKey(addr)
+
+	changeset := authority.NewChangeSet()
+	changeset.Add(addr, pubkey)
+
+	err := c.verifyProofs(next, changeset, tx.GetArg(ProofsArg))
+	if err != nil {
+		return err
+	}
+
+	creds := NewCreds()
+
+	err = c.access.Match(snap, creds, tx.GetIdentity())
+	if err != nil {
+		reportErr(tx, xerrors.Errorf("access control: %v", err))
+
+		return xerrors.Errorf("%s: %v", messageUnauthorized, tx.GetIdentity())
+	}
+
+	pending, err := c.readMigration(snap, curr, keyType(pubkey))
+	if err != nil {
+		reportErr(tx, err)
+
+		return xerrors.New(messageStorageCorrupted)
+	}
+
+	pending = replaceKey(pending, addr, pubkey)
+
+	data, err := pending.Serialize(c.context)
+	if err != nil {
+		reportErr(tx, xerrors.Errorf("failed to serialize roster: %v", err))
+
+		return xerrors.New(messageStorageFailure)
+	}
+
+	if !sameKeyType(pending) {
+		err = snap.Set(migrationKey[:], data)
+		if err != nil {
+			reportErr(tx, xerrors.Errorf("writing store: %v", err))
+
+			return xerrors.New(messageStorageFailure)
+		}
+
+		return nil
+	}
+
+	// Every member has swapped its key, therefore the roster migrates. The
+	// members keep their right to change the roster with their new keys.
+	pubkeys := pending.PublicKeyIterator()
+	for pubkeys.HasNext() {
+		err = c.access.Grant(snap, creds, pubkeys.GetNext())
+		if err != nil {
+			reportErr(tx, xerrors.Errorf("access control: %v", err))
+
+			return xerrors.New(messageStorageFailure)
+		}
+	}
+
+	err = snap.Set(rosterKey[:], data)
+	if err == nil {
+		err = snap.Delete(migrationKey[:])
+	}
+
+	if err != nil {
+		reportErr(tx, xerrors.Errorf("writing store: %v", err))
+
+		return xerrors.New(messageStorageFailure)
+	}
+
+	return nil
+}
+
+// readMigration returns the roster of the migration in progress, or the
+// current one if there is none or if it doesn't apply to the current roster
+// anymore.
+func (c Contract) readMigration(snap store.Snapshot, curr authority.Authority,
+	kind string) (authority.Authority, error) {
+
+	data, err := snap.Get(migrationKey[:])
+	if err != nil {
+		return nil, xerrors.Errorf("reading store: %v", err)
+	}
+
+	if len(data) == 0 {
+		return curr, nil
+	}
+
+	pending, err := c.rosterFac.AuthorityOf(c.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("migration roster: %v", err)
+	}
+
+	if !isMigrationOf(curr, pending, kind) {
+		return curr, nil
+	}
+
+	return pending, nil
+}
+
+// verifyProofs checks the proof of possession of every public key that joins
+// the roster and supports one.
+func (c Contract) verifyProofs(roster authority.Authority, changeset authority.ChangeSet,
+	data []byte) error {
+
+	proofs := make(map[int][]byte)
+
+	if len(data) > 0 {
+		err := json.Unmarshal(data, &proofs)
+		if err != nil {
+			return xerrors.Errorf("%s: %v", messageProofsInvalid, err)
+		}
+	}
+
+	for _, addr := range changeset.GetNewAddresses() {
+		pubkey, index := roster.GetPublicKey(addr)
+
+		verifier, ok := pubkey.(crypto.PossessionVerifier)
+		if !ok {
+			continue
+		}
+
+		raw, found := proofs[index]
+		if !found {
+			return xerrors.Errorf("%s: %v", messageProofMissing, addr)
+		}
+
+		proof, err := c.sigFac.SignatureOf(c.context, raw)
+		if err != nil {
+			return xerrors.Errorf("%s: %v: %v", messageProofMismatch, addr, err)
+		}
+
+		err = verifier.VerifyPossession(proof)
+		if err != nil {
+			return xerrors.Errorf("%s: %v: %v", messageProofMismatch, addr, err)
+		}
+	}
+
+	return nil
+}
+
+// findSwap returns the address of the only member of the new roster that
+// swaps its public key for one of another algorithm, or nil if the new roster
+// is not such a swap.
+func findSwap(curr, next authority.Authority) mino.Address {
+	if curr.Len() == 0 || curr.Len() != next.Len() {
+		return nil
+	}
+
+	currAddrs := curr.AddressIterator()
+	nextAddrs := next.AddressIterator()
+	currKeys := curr.PublicKeyIterator()
+	nextKeys := next.PublicKeyIterator()
+
+	var swapped mino.Address
+
+	for currAddrs.HasNext() && nextAddrs.HasNext() {
+		addr := nextAddrs.GetNext()
+		if !currAddrs.GetNext().Equal(addr) {
+			return nil
+		}
+
+		currKey := currKeys.GetNext()
+		nextKey := nextKeys.GetNext()
+
+		if currKey.Equal(nextKey) {
+			continue
+		}
+
+		if swapped != nil || keyType(currKey) == keyType(nextKey) {
+			return nil
+		}
+
+		swapped = addr
+	}
+
+	return swapped
+}
+
+// isMigrationOf returns true when the roster of a migration has the addresses
+// of the current roster, with either the same keys or keys of the kind.
+func isMigrationOf(curr, pending authority.Authority, kind string) bool {
+	if curr.Len() != pending.Len() {
+		return false
+	}
+
+	currAddrs := curr.AddressIterator()
+	pendingAddrs := pending.AddressIterator()
+	currKeys := curr.PublicKeyIterator()
+	pendingKeys := pending.PublicKeyIterator()
+
+	for currAddrs.HasNext() && pendingAddrs.HasNext() {
+		if !currAddrs.GetNext().Equal(pendingAddrs.GetNext()) {
+			return false
+		}
+
+		currKey := currKeys.GetNext()
+		pendingKey := pendingKeys.GetNext()
+
+		if !currKey.Equal(pendingKey) && keyType(pendingKey) != kind {
+			return false
+		}
+	}
+
+	return true
+}
+
+// replaceKey returns a copy of the roster where the public key of the member
+// at the address is replaced.
+func replaceKey(roster authority.Authority, target mino.Address,
+	pubkey crypto.PublicKey) authority.Authority {
+
+	addrs := make([]mino.Address, 0, roster.Len())
+	pubkeys := make([]crypto.PublicKey, 0, roster.Len())
+
+	addrIter := roster.AddressIterator()
+	keyIter := roster.PublicKeyIterator()
+
+	for addrIter.HasNext() && keyIter.HasNext() {
+		addr := addrIter.GetNext()
+		key := keyIter.GetNext()
+
+		if addr.Equal(target) {
+			key = pubkey
+		}
+
+		addrs = append(addrs, addr)
+		pubkeys = append(pubkeys, key)
+	}
+
+	return authority.New(addrs, pubkeys)
+}
+
+// sameKeyType returns true when every public key of the roster is of the same
+// type, as collective signatures require a single algorithm.
+func sameKeyType(roster authority.Authority) bool {
+	iter := roster.PublicKeyIterator()
+	if !iter.HasNext() {
+		return true
+	}
+
+	first := keyType(iter.GetNext())
+
+	for iter.HasNext() {
+		if keyType(iter.GetNext()) != first {
+			return false
+		}
+	}
+
+	return true
+}
+
+func keyType(pubkey crypto.PublicKey) string {
+	return fmt.Sprintf("%T", pubkey)
+}
+
 // reportErr prints a log with the actual error while the transaction will
 // contain a simplified explanation.
 func reportErr(tx txn.Transaction, err error) {
@@ -11,7 +11,13 @@ import (
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -75,9 +81,273 @@ func TestContract_Execute(t *testing.T) {
 	require.EqualError(t, err, "unauthorized identity: fake.PublicKey")
 }
 
+func TestContract_Execute_Proofs(t *testing.T) {
+	fac := authority.NewFactory(fake.AddressFactory{}, makeSigner().GetPublicKeyFactory())
+	contract := NewContract(fac, fakeAccess{})
+
+	ca := fake.NewAuthority(3, bls12381.Generate)
+	roster := authority.FromAuthority(ca)
+	store := fakeStore{roster: serializeRoster(t, roster)}
+
+	// A new member joins with a proof of possession of its key.
+	joiner := fake.NewAuthority(4, bls12381.Generate)
+	next := authority.New(
+		append(makeAddrs(roster), joiner.GetAddress(3)),
+		append(makeKeys(roster), joiner.GetSigner(3).GetPublicKey()),
+	)
+
+	proof := makeProof(t, joiner.GetSigner(3))
+
+	err := contract.Execute(store, makeProofStep(t, next, map[int]crypto.Signature{3: proof}))
+	require.NoError(t, err)
+
+	err = contract.Execute(store, makeProofStep(t, next, nil))
+	require.EqualError(t, err, "missing proof of possession: fake.Address[3]")
+
+	wrong := makeProof(t, ca.GetSigner(0))
+	err = contract.Execute(store, makeProofStep(t, next, map[int]crypto.Signature{3: wrong}))
+	require.ErrorContains(t, err, "invalid proof of possession: fake.Address[3]: ")
+
+	err = contract.Execute(store, makeRawProofStep(t, next, "[]"))
+	require.ErrorContains(t, err, "invalid proofs of possession: ")
+
+	err = contract.Execute(store, makeRawProofStep(t, next, `{"3":"AA=="}`))
+	require.ErrorContains(t, err, "invalid proof of possession: fake.Address[3]: ")
+
+	// A member of another algorithm cannot join.
+	other := fake.NewAuthority(4, bls.Generate)
+	mixed := authority.New(
+		append(makeAddrs(roster), other.GetAddress(3)),
+		append(makeKeys(roster), other.GetSigner(3).GetPublicKey()),
+	)
+
+	err = contract.Execute(store, makeProofStep(t, mixed, nil))
+	require.EqualError(t, err, messageMixedKeys)
+}
+
+func TestContract_Execute_Migration(t *testing.T) {
+	fac := authority.NewFactory(fake.AddressFactory{}, makeSigner().GetPublicKeyFactory())
+
+	granted := 0
+	contract := NewContract(fac, fakeAccess{granted: &granted})
+
+	currCA := fake.NewAuthority(3, bls.Generate)
+	curr := authority.FromAuthority(currCA)
+
+	snap := fake.NewSnapshot()
+	require.NoError(t, snap.Set(GetRosterKey(), serializeRoster(t, curr)))
+
+	ca := fake.NewAuthority(3, bls12381.Generate)
+	next := authority.FromAuthority(ca)
+
+	// Every member swaps its own key, and the roster is replaced after the
+	// last one.
+	for i := 0; i < ca.Len(); i++ {
+		step := makeSwapStep(t, curr, currCA.GetSigner(i), ca.GetAddress(i), ca.GetSigner(i))
+
+		err := contract.Execute(snap, step)
+		require.NoError(t, err)
+
+		value, err := snap.Get(GetRosterKey())
+		require.NoError(t, err)
+
+		if i < ca.Len()-1 {
+			require.Equal(t, serializeRoster(t, curr), value)
+			require.NotNil(t, getValue(t, snap, GetMigrationKey()))
+		} else {
+			require.Equal(t, serializeRoster(t, next), value)
+			require.Nil(t, getValue(t, snap, GetMigrationKey()))
+		}
+	}
+
+	require.Equal(t, 3, granted)
+}
+
+func TestContract_Execute_BadMigration(t *testing.T) {
+	fac := authority.NewFactory(fake.AddressFactory{}, makeSigner().GetPublicKeyFactory())
+	contract := NewContract(fac, fakeAccess{})
+
+	currCA := fake.NewAuthority(3, bls.Generate)
+	curr := authority.FromAuthority(currCA)
+
+	snap := fake.NewSnapshot()
+	require.NoError(t, snap.Set(GetRosterKey(), serializeRoster(t, curr)))
+
+	ca := fake.NewAuthority(3, bls12381.Generate)
+
+	// A member cannot swap the key of another one.
+	step := makeSwapStep(t, curr, currCA.GetSigner(1), ca.GetAddress(0), ca.GetSigner(0))
+	err := contract.Execute(snap, step)
+	require.EqualError(t, err, "only the member can swap its key: fake.Address[0]")
+
+	// A single transaction cannot swap several keys.
+	partial := authority.New(makeAddrs(curr),
+		append(makeKeys(authority.FromAuthority(ca))[:2], makeKeys(curr)[2]))
+	err = contract.Execute(snap, makeProofStep(t, partial, nil))
+	require.EqualError(t, err, messageTooManyChanges)
+
+	step = makeSwapStep(t, curr, currCA.GetSigner(0), ca.GetAddress(0), ca.GetSigner(0))
+	step.Current, err = signed.NewTransaction(0, currCA.GetSigner(0).GetPublicKey(),
+		signed.WithArg(native.ContractArg, []byte(ContractName)),
+		signed.WithArg(AuthorityArg, step.Current.GetArg(AuthorityArg)))
+	require.NoError(t, err)
+
+	err = contract.Execute(snap, step)
+	require.EqualError(t, err, "missing proof of possession: fake.Address[0]")
+
+	step = makeSwapStep(t, curr, currCA.GetSigner(0), ca.GetAddress(0), ca.GetSigner(0))
+
+	contract.access = fakeAccess{err: fake.GetError()}
+	err = contract.Execute(snap, step)
+	require.Regexp(t, "^unauthorized identity: ", err)
+
+	contract.access = fakeAccess{}
+	require.NoError(t, snap.Set(GetMigrationKey(), []byte("[{}]")))
+	err = contract.Execute(snap, step)
+	require.EqualError(t, err, messageStorageCorrupted)
+
+	// A migration of another roster is discarded.
+	other := authority.FromAuthority(fake.NewAuthority(2, bls12381.Generate))
+	require.NoError(t, snap.Set(GetMigrationKey(), serializeRoster(t, other)))
+	err = contract.Execute(snap, step)
+	require.NoError(t, err)
+
+	pending, err := fac.AuthorityOf(sjson.NewContext(), getValue(t, snap, GetMigrationKey()))
+	require.NoError(t, err)
+	require.Equal(t, 3, pending.Len())
+
+	snap.ErrWrite = fake.GetError()
+	err = contract.Execute(snap, step)
+	require.EqualError(t, err, messageStorageFailure)
+
+	snap.ErrWrite = nil
+	contract.access = fakeAccess{errGrant: fake.GetError()}
+	require.NoError(t, snap.Set(GetMigrationKey(), serializeRoster(t, authority.New(
+		makeAddrs(curr),
+		append(makeKeys(curr)[:1], makeKeys(authority.FromAuthority(ca))[1:]...),
+	))))
+	err = contract.Execute(snap, step)
+	require.EqualError(t, err, messageStorageFailure)
+}
+
+func TestManager_MakeSwap(t *testing.T) {
+	mgr := NewManager(signed.NewManager(fake.NewSigner(), fake.NewClient()))
+
+	roster := authority.FromAuthority(fake.NewAuthority(2, bls.Generate))
+
+	tx, err := mgr.MakeSwap(roster, fake.NewAddress(1), bls12381.NewSigner())
+	require.NoError(t, err)
+	require.Regexp(t, `^\{"1":"[A-Za-z0-9+/=]+"\}$`, string(tx.GetArg(ProofsArg)))
+
+	tx, err = mgr.MakeSwap(roster, fake.NewAddress(1), fake.NewSigner())
+	require.NoError(t, err)
+	require.Nil(t, tx.GetArg(ProofsArg))
+
+	_, err = mgr.MakeSwap(roster, fake.NewAddress(5), bls12381.NewSigner())
+	require.EqualError(t, err, "fake.Address[5] is not a member")
+}
+
+func TestManager_MakeWithProofs(t *testing.T) {
+	mgr := NewManager(signed.NewManager(fake.NewSigner(), fake.NewClient()))
+
+	signer := bls12381.NewSigner()
+	proof := makeProof(t, signer)
+
+	tx, err := mgr.MakeWithProofs(authority.New(nil, nil), map[int]crypto.Signature{0: proof})
+	require.NoError(t, err)
+	require.Regexp(t, `^\{"0":"[A-Za-z0-9+/=]+"\}$`, string(tx.GetArg(ProofsArg)))
+
+	tx, err = mgr.MakeWithProofs(authority.New(nil, nil), nil)
+	require.NoError(t, err)
+	require.Nil(t, tx.GetArg(ProofsArg))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func makeSigner() *common.Signer {
+	signer := common.NewSigner(bls.Algorithm, bls.NewSigner())
+	signer.Add(bls12381.Algorithm, bls12381.NewSigner())
+
+	return signer
+}
+
+func serializeRoster(t *testing.T, roster authority.Authority) []byte {
+	data, err := roster.Serialize(sjson.NewContext())
+	require.NoError(t, err)
+
+	return data
+}
+
+func makeAddrs(roster authority.Authority) []mino.Address {
+	addrs := make([]mino.Address, 0, roster.Len())
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		addrs = append(addrs, iter.GetNext())
+	}
+
+	return addrs
+}
+
+func makeKeys(roster authority.Authority) []crypto.PublicKey {
+	pubkeys := make([]crypto.PublicKey, 0, roster.Len())
+
+	iter := roster.PublicKeyIterator()
+	for iter.HasNext() {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	return pubkeys
+}
+
+func makeProof(t *testing.T, signer crypto.Signer) crypto.Signature {
+	proof, err := signer.(crypto.PossessionProver).ProvePossession()
+	require.NoError(t, err)
+
+	return proof
+}
+
+func getValue(t *testing.T, snap store.Snapshot, key []byte) []byte {
+	value, err := snap.Get(key)
+	require.NoError(t, err)
+
+	return value
+}
+
+func makeSwapStep(t *testing.T, roster authority.Authority, member crypto.Signer,
+	addr mino.Address, signer crypto.Signer) execution.Step {
+
+	mgr := NewManager(signed.NewManager(member, fake.NewClient()))
+
+	tx, err := mgr.MakeSwap(roster, addr, signer)
+	require.NoError(t, err)
+
+	return execution.Step{Current: tx}
+}
+
+func makeProofStep(t *testing.T, roster authority.Authority,
+	proofs map[int]crypto.Signature) execution.Step {
+
+	mgr := NewManager(signed.NewManager(fake.NewSigner(), fake.NewClient()))
+
+	tx, err := mgr.MakeWithProofs(roster, proofs)
+	require.NoError(t, err)
+
+	return execution.Step{Current: tx}
+}
+
+func makeRawProofStep(t *testing.T, roster authority.Authority, proofs string) execution.Step {
+	tx, err := signed.NewTransaction(0, fake.PublicKey{},
+		signed.WithArg(native.ContractArg, []byte(ContractName)),
+		signed.WithArg(AuthorityArg, serializeRoster(t, roster)),
+		signed.WithArg(ProofsArg, []byte(proofs)),
+	)
+	require.NoError(t, err)
+
+	return execution.Step{Current: tx}
+}
+
 func makeStep(t *testing.T, arg string) execution.Step {
 	return execution.Step{Current: makeTx(t, arg)}
 }
@@ -97,11 +367,16 @@ func makeTx(t *testing.T, arg string) txn.Transaction {
 type fakeStore struct {
 	store.Snapshot
 
+	roster []byte
 	errGet error
 	errSet error
 }
 
 func (snap fakeStore) Get(key []byte) ([]byte, error) {
+	if snap.roster != nil {
+		return snap.roster, snap.errGet
+	}
+
 	return []byte("[{}]"), snap.errGet
 }
 
@@ -142,7 +417,17 @@ func (badManager) Make(opts ...txn.Arg) (txn.Transaction, error) {
 type fakeAccess struct {
 	access.Service
 
-	err error
+	err      error
+	errGrant error
+	granted  *int
+}
+
+func (srvc fakeAccess) Grant(store.Snapshot, access.Credential, ...access.Identity) error {
+	if srvc.granted != nil {
+		*srvc.granted++
+	}
+
+	return srvc.errGrant
 }
 
 func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
@@ -11,22 +11,39 @@ import (
 	"encoding/base64"
 	"fmt"
 	"strings"
+	"text/tabwriter"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/participation"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/cosi"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde/json"
 	"golang.org/x/xerrors"
 )
 
 const separator = ":"
 
+// endorsingMino is implemented by the overlays that endorse their certificates
+// with the signer of the node.
+type endorsingMino interface {
+	HasEndorsement() bool
+}
+
+// algorithmSigner is implemented by the signers that hold a key per algorithm.
+type algorithmSigner interface {
+	GetAlgorithm() string
+
+	Get(algorithm string) crypto.AggregateSigner
+}
+
 // Service is the expected interface of the ordering service that is extended
 // with some additional functions.
 type Service interface {
@@ -34,7 +51,9 @@ type Service interface {
 
 	GetRoster() (authority.Authority, error)
 
-	Setup(ctx context.Context, ca crypto.CollectiveAuthority) error
+	GetParticipation() ([]participation.Stats, error)
+
+	Setup(ctx context.Context, ca crypto.CollectiveAuthority, opts ...cosipbft.SetupOption) error
 }
 
 // SetupAction is an action to create a new chain with a list of participants.
@@ -56,12 +75,28 @@ func (a setupAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("injector: %v", err)
 	}
 
+	var opts []cosipbft.SetupOption
+
+	if ctx.Flags.String("hash") != "" {
+		algo, err := crypto.ParseHashAlgorithm(ctx.Flags.String("hash"))
+		if err != nil {
+			return xerrors.Errorf("invalid hash: %v", err)
+		}
+
+		opts = append(opts, cosipbft.WithChainHash(algo))
+	}
+
+	if ctx.Flags.Int("format-version") > 0 {
+		version := uint32(ctx.Flags.Int("format-version"))
+		opts = append(opts, cosipbft.WithFormatVersion(version))
+	}
+
 	timeout := ctx.Flags.Duration("timeout")
 
 	setupCtx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	err = srvc.Setup(setupCtx, roster)
+	err = srvc.Setup(setupCtx, roster, opts...)
 	if err != nil {
 		return xerrors.Errorf("failed to setup: %v", err)
 	}
@@ -70,22 +105,58 @@ func (a setupAction) Execute(ctx node.Context) error {
 }
 
 func (a setupAction) readMembers(ctx node.Context) (authority.Authority, error) {
+	roster, proofs, err := readMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The roster of a new chain is not checked by the view change contract, so
+	// the proofs of possession are verified here.
+	pubkeys := roster.PublicKeyIterator()
+	for i := 0; pubkeys.HasNext(); i++ {
+		verifier, ok := pubkeys.GetNext().(crypto.PossessionVerifier)
+		if !ok {
+			continue
+		}
+
+		proof, found := proofs[i]
+		if !found {
+			return nil, xerrors.Errorf("member %d: missing proof of possession", i)
+		}
+
+		err = verifier.VerifyPossession(proof)
+		if err != nil {
+			return nil, xerrors.Errorf("member %d: %v", i, err)
+		}
+	}
+
+	return roster, nil
+}
+
+// readMembers returns the roster made of the members of the flags, and the
+// proofs of possession indexed by the position of the member in the roster.
+func readMembers(ctx node.Context) (authority.Authority, map[int]crypto.Signature, error) {
 	members := ctx.Flags.StringSlice("member")
 
 	addrs := make([]mino.Address, len(members))
 	pubkeys := make([]crypto.PublicKey, len(members))
+	proofs := make(map[int]crypto.Signature)
 
 	for i, member := range members {
-		addr, pubkey, err := decodeMember(ctx, member)
+		addr, pubkey, proof, err := decodeMember(ctx, member)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to decode: %v", err)
+			return nil, nil, xerrors.Errorf("failed to decode: %v", err)
 		}
 
 		addrs[i] = addr
 		pubkeys[i] = pubkey
+
+		if proof != nil {
+			proofs[i] = proof
+		}
 	}
 
-	return authority.New(addrs, pubkeys), nil
+	return authority.New(addrs, pubkeys), proofs, nil
 }
 
 // ExportAction is an action to display a base64 string describing the node. It
@@ -95,7 +166,9 @@ func (a setupAction) readMembers(ctx node.Context) (authority.Authority, error)
 type exportAction struct{}
 
 // Execute implements node.ActionTemplate. It looks for the node address and
-// public key and prints "$ADDR_BASE64:$PUBLIC_KEY_BASE64".
+// public key and prints "$ADDR_BASE64:$PUBLIC_KEY_BASE64". The proof of
+// possession of the public key is appended as ":$PROOF_BASE64" when the
+// algorithm supports it.
 func (a exportAction) Execute(ctx node.Context) error {
 	var m mino.Mino
 	err := ctx.Injector.Resolve(&m)
@@ -114,7 +187,12 @@ func (a exportAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("injector: %v", err)
 	}
 
-	pubkey, err := c.GetSigner().GetPublicKey().MarshalBinary()
+	signer, err := selectSigner(c.GetSigner(), ctx.Flags.String("algorithm"))
+	if err != nil {
+		return xerrors.Errorf("signer: %v", err)
+	}
+
+	pubkey, err := signer.GetPublicKey().MarshalBinary()
 	if err != nil {
 		return xerrors.Errorf("failed to marshal public key: %v", err)
 	}
@@ -122,11 +200,98 @@ func (a exportAction) Execute(ctx node.Context) error {
 	desc := base64.StdEncoding.EncodeToString(addr) + separator +
 		base64.StdEncoding.EncodeToString(pubkey)
 
+	prover, ok := signer.(crypto.PossessionProver)
+	if ok {
+		proof, err := prover.ProvePossession()
+		if err != nil {
+			return xerrors.Errorf("failed to prove possession: %v", err)
+		}
+
+		data, err := proof.Serialize(json.NewContext())
+		if err != nil {
+			return xerrors.Errorf("failed to serialize proof: %v", err)
+		}
+
+		desc += separator + base64.StdEncoding.EncodeToString(data)
+	}
+
 	fmt.Fprint(ctx.Out, desc)
 
 	return nil
 }
 
+// participationAction is an action to print the number of blocks signed by
+// each member of the roster.
+//
+// - implements node.ActionTemplate
+type participationAction struct{}
+
+// Execute implements node.ActionTemplate. It prints a table of the members
+// with their participation statistics. The members that have missed less
+// consecutive blocks than the flag are omitted.
+func (participationAction) Execute(ctx node.Context) error {
+	var srvc Service
+	err := ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	stats, err := srvc.GetParticipation()
+	if err != nil {
+		return xerrors.Errorf("failed to read participation: %v", err)
+	}
+
+	minMissed := uint64(ctx.Flags.Int("missed"))
+
+	w := tabwriter.NewWriter(ctx.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tBLOCKS\tPREPARES\tCOMMITS\tLAST COMMIT\tMISSED")
+
+	for _, st := range stats {
+		if st.Missed < minMissed {
+			continue
+		}
+
+		last := "-"
+		if st.Commits > 0 {
+			last = fmt.Sprintf("%d", st.LastCommit)
+		}
+
+		fmt.Fprintf(w, "%v\t%d\t%d\t%d\t%s\t%d\n", st.Address, st.Blocks,
+			st.Prepares, st.Commits, last, st.Missed)
+	}
+
+	return w.Flush()
+}
+
+// selectSigner returns the signer of the algorithm, or the active one when the
+// name is empty.
+func selectSigner(signer crypto.Signer, name string) (crypto.Signer, error) {
+	multi, ok := signer.(algorithmSigner)
+	if !ok {
+		if name != "" {
+			return nil, xerrors.Errorf("signer '%T' has a single algorithm", signer)
+		}
+
+		return signer, nil
+	}
+
+	algorithm := multi.GetAlgorithm()
+
+	if name != "" {
+		algorithm, ok = algorithms[name]
+		if !ok {
+			return nil, xerrors.Errorf("unknown algorithm '%s'", name)
+		}
+	}
+
+	selected := multi.Get(algorithm)
+	if selected == nil {
+		return nil, xerrors.Errorf("no key for algorithm '%s'", algorithm)
+	}
+
+	return selected, nil
+}
+
 // RosterAddAction is an action to require a roster change in the change by
 // adding a new member.
 //
@@ -147,8 +312,73 @@ func (rosterAddAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("while preparing tx: %v", err)
 	}
 
+	return sendRosterTx(ctx, srvc, tx)
+}
+
+// RosterMigrateAction is an action to swap the public key of the node for one
+// of another algorithm. The roster migrates once every member has swapped its
+// key.
+//
+// - implements node.ActionTemplate
+type rosterMigrateAction struct{}
+
+// Execute implements node.ActionTemplate. It sends a transaction signed by the
+// current key of the node to swap it for the key of the algorithm. The nodes
+// sign with the new keys after the block of the last swap.
+func (rosterMigrateAction) Execute(ctx node.Context) error {
+	var m mino.Mino
+	err := ctx.Injector.Resolve(&m)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	// Only the BN256 key endorses the certificates of the overlay, which would
+	// be rejected by the peers after the migration.
+	endorsed, ok := m.(endorsingMino)
+	if ok && endorsed.HasEndorsement() {
+		return xerrors.New("migration is not supported with endorsement")
+	}
+
+	var srvc Service
+	err = ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var c cosi.CollectiveSigning
+	err = ctx.Injector.Resolve(&c)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	signer, err := selectSigner(c.GetSigner(), ctx.Flags.String("algorithm"))
+	if err != nil {
+		return xerrors.Errorf("signer: %v", err)
+	}
+
+	roster, err := srvc.GetRoster()
+	if err != nil {
+		return xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	mgr, err := makeManager(ctx)
+	if err != nil {
+		return xerrors.Errorf("txn manager: %v", err)
+	}
+
+	tx, err := viewchange.NewManager(mgr).MakeSwap(roster, m.GetAddress(), signer)
+	if err != nil {
+		return xerrors.Errorf("transaction: %v", err)
+	}
+
+	return sendRosterTx(ctx, srvc, tx)
+}
+
+// sendRosterTx adds the transaction to the pool and waits for it to be
+// accepted if the flag is set.
+func sendRosterTx(ctx node.Context, srvc Service, tx txn.Transaction) error {
 	var p pool.Pool
-	err = ctx.Injector.Resolve(&p)
+	err := ctx.Injector.Resolve(&p)
 	if err != nil {
 		return xerrors.Errorf("injector: %v", err)
 	}
@@ -203,7 +433,7 @@ func prepareRosterTx(ctx node.Context, srvc Service) (txn.Transaction, error) {
 		return nil, xerrors.Errorf("failed to read roster: %v", err)
 	}
 
-	addr, pubkey, err := decodeMember(ctx, ctx.Flags.String("member"))
+	addr, pubkey, proof, err := decodeMember(ctx, ctx.Flags.String("member"))
 	if err != nil {
 		return nil, xerrors.Errorf("failed to decode member: %v", err)
 	}
@@ -211,12 +441,18 @@ func prepareRosterTx(ctx node.Context, srvc Service) (txn.Transaction, error) {
 	cset := authority.NewChangeSet()
 	cset.Add(addr, pubkey)
 
+	// The new member is appended to the roster.
+	var proofs map[int]crypto.Signature
+	if proof != nil {
+		proofs = map[int]crypto.Signature{roster.Len(): proof}
+	}
+
 	mgr, err := makeManager(ctx)
 	if err != nil {
 		return nil, xerrors.Errorf("txn manager: %v", err)
 	}
 
-	tx, err := viewchange.NewManager(mgr).Make(roster.Apply(cset))
+	tx, err := viewchange.NewManager(mgr).MakeWithProofs(roster.Apply(cset), proofs)
 	if err != nil {
 		return nil, xerrors.Errorf("transaction: %v", err)
 	}
@@ -241,22 +477,26 @@ func makeManager(ctx node.Context) (txn.Manager, error) {
 	return mgr, nil
 }
 
-func decodeMember(ctx node.Context, str string) (mino.Address, crypto.PublicKey, error) {
+// decodeMember returns the address, the public key and the proof of possession
+// of a member. The proof is nil when the description does not include one.
+func decodeMember(ctx node.Context, str string) (mino.Address, crypto.PublicKey,
+	crypto.Signature, error) {
+
 	parts := strings.Split(str, separator)
-	if len(parts) != 2 {
-		return nil, nil, xerrors.New("invalid member base64 string")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, nil, nil, xerrors.New("invalid member base64 string")
 	}
 
 	// 1. Deserialize the address.
 	var m mino.Mino
 	err := ctx.Injector.Resolve(&m)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("injector: %v", err)
+		return nil, nil, nil, xerrors.Errorf("injector: %v", err)
 	}
 
 	addrBuf, err := base64.StdEncoding.DecodeString(parts[0])
 	if err != nil {
-		return nil, nil, xerrors.Errorf("base64 address: %v", err)
+		return nil, nil, nil, xerrors.Errorf("base64 address: %v", err)
 	}
 
 	addr := m.GetAddressFactory().FromText(addrBuf)
@@ -265,18 +505,33 @@ func decodeMember(ctx node.Context, str string) (mino.Address, crypto.PublicKey,
 	var c cosi.CollectiveSigning
 	err = ctx.Injector.Resolve(&c)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("injector: %v", err)
+		return nil, nil, nil, xerrors.Errorf("injector: %v", err)
 	}
 
 	pubkeyBuf, err := base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return nil, nil, xerrors.Errorf("base64 public key: %v", err)
+		return nil, nil, nil, xerrors.Errorf("base64 public key: %v", err)
 	}
 
 	pubkey, err := c.GetPublicKeyFactory().FromBytes(pubkeyBuf)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("failed to decode public key: %v", err)
+		return nil, nil, nil, xerrors.Errorf("failed to decode public key: %v", err)
+	}
+
+	if len(parts) == 2 {
+		return addr, pubkey, nil, nil
+	}
+
+	// 3. Deserialize the proof of possession.
+	proofBuf, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("base64 proof: %v", err)
+	}
+
+	proof, err := c.GetSigner().GetSignatureFactory().SignatureOf(json.NewContext(), proofBuf)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("failed to decode proof: %v", err)
 	}
 
-	return addr, pubkey, nil
+	return addr, pubkey, proof, nil
 }
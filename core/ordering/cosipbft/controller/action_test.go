@@ -3,7 +3,9 @@ package controller
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,14 +13,21 @@ import (
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/participation"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/core/txn/pool/mem"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/cosi"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/common"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -33,7 +42,25 @@ func TestSetupAction_Execute(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, calls.Len())
 	require.Equal(t, 2, calls.Get(0, 1).(mino.Players).Len())
+	require.Equal(t, 0, calls.Get(0, 2))
 
+	ctx.Flags.(node.FlagSet)["hash"] = "blake3-256"
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls.Get(1, 2))
+
+	ctx.Flags.(node.FlagSet)["format-version"] = 1
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls.Get(2, 2))
+
+	delete(ctx.Flags.(node.FlagSet), "format-version")
+
+	ctx.Flags.(node.FlagSet)["hash"] = "md5"
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "invalid hash: unknown hash algorithm 'md5'")
+
+	delete(ctx.Flags.(node.FlagSet), "hash")
 	ctx.Flags.(node.FlagSet)["member"] = []interface{}{""}
 	err = action.Execute(ctx)
 	require.EqualError(t, err,
@@ -150,22 +177,230 @@ func TestRosterAddAction_Execute(t *testing.T) {
 func TestDecodeMember(t *testing.T) {
 	ctx := prepContext(nil)
 
-	_, _, err := decodeMember(ctx, "a:a")
+	_, _, _, err := decodeMember(ctx, "a:a")
 	require.EqualError(t, err, "base64 address: illegal base64 data at input byte 0")
 
-	_, _, err = decodeMember(ctx, ":a")
+	_, _, _, err = decodeMember(ctx, ":a")
 	require.EqualError(t, err, "base64 public key: illegal base64 data at input byte 0")
 
+	_, _, proof, err := decodeMember(ctx, "::")
+	require.NoError(t, err)
+	require.Equal(t, fake.Signature{}, proof)
+
+	_, _, _, err = decodeMember(ctx, "::a")
+	require.EqualError(t, err, "base64 proof: illegal base64 data at input byte 0")
+
+	_, _, _, err = decodeMember(ctx, ":::")
+	require.EqualError(t, err, "invalid member base64 string")
+
+	ctx.Injector.Inject(fakeCosi{errSig: true})
+	_, _, _, err = decodeMember(ctx, "::")
+	require.EqualError(t, err, fake.Err("failed to decode proof"))
+
 	ctx.Injector = node.NewInjector()
 	ctx.Injector.Inject(fake.Mino{})
-	_, _, err = decodeMember(ctx, ":")
+	_, _, _, err = decodeMember(ctx, ":")
 	require.EqualError(t, err, "injector: couldn't find dependency for 'cosi.CollectiveSigning'")
 
 	ctx.Injector.Inject(fakeCosi{err: true})
-	_, _, err = decodeMember(ctx, ":")
+	_, _, _, err = decodeMember(ctx, ":")
 	require.EqualError(t, err, fake.Err("failed to decode public key"))
 }
 
+func TestSetupAction_Proofs(t *testing.T) {
+	signer := bls12381.NewSigner()
+	member := exportMember(t, signer)
+
+	ctx := prepContext(&fake.Call{})
+	ctx.Injector.Inject(fakeCosi{signer: makeSigner(signer)})
+	ctx.Flags.(node.FlagSet)["member"] = []interface{}{member}
+
+	err := setupAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	// Without the proof.
+	parts := strings.Split(member, separator)
+	ctx.Flags.(node.FlagSet)["member"] = []interface{}{parts[0] + separator + parts[1]}
+
+	err = setupAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to read roster: member 0: missing proof of possession")
+
+	// With the proof of another key.
+	other := exportMember(t, bls12381.NewSigner())
+	proof := strings.Split(other, separator)[2]
+	ctx.Flags.(node.FlagSet)["member"] = []interface{}{
+		parts[0] + separator + parts[1] + separator + proof,
+	}
+
+	err = setupAction{}.Execute(ctx)
+	require.EqualError(t, err, "failed to read roster: member 0: "+
+		"invalid proof of possession: signature mismatch")
+}
+
+func TestExportAction_Algorithm(t *testing.T) {
+	signer := makeSigner(bls12381.NewSigner())
+
+	ctx := prepContext(nil)
+	ctx.Injector.Inject(fakeCosi{signer: signer})
+
+	buffer := new(bytes.Buffer)
+	ctx.Out = buffer
+
+	// The active algorithm has no proof of possession.
+	err := exportAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(buffer.String(), separator), 2)
+
+	buffer.Reset()
+	ctx.Flags.(node.FlagSet)["algorithm"] = "bls12381"
+
+	err = exportAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(buffer.String(), separator), 3)
+
+	ctx.Flags.(node.FlagSet)["algorithm"] = "unknown"
+	err = exportAction{}.Execute(ctx)
+	require.EqualError(t, err, "signer: unknown algorithm 'unknown'")
+
+	ctx.Injector.Inject(fakeCosi{})
+	err = exportAction{}.Execute(ctx)
+	require.EqualError(t, err, "signer: signer 'fake.Signer' has a single algorithm")
+
+	ctx.Injector.Inject(fakeCosi{signer: common.NewSigner(bls.Algorithm, bls.NewSigner())})
+	ctx.Flags.(node.FlagSet)["algorithm"] = "bls12381"
+	err = exportAction{}.Execute(ctx)
+	require.EqualError(t, err, "signer: no key for algorithm 'BLS-CURVE-BLS12381'")
+}
+
+func TestParticipationAction_Execute(t *testing.T) {
+	stats := []participation.Stats{
+		{Address: fake.NewAddress(0), Blocks: 3, Prepares: 3, Commits: 3, LastCommit: 2},
+		{Address: fake.NewAddress(1), Blocks: 3, Prepares: 1, Missed: 3},
+	}
+
+	ctx := prepContext(nil)
+	ctx.Injector.Inject(fakeService{stats: stats})
+
+	buffer := new(bytes.Buffer)
+	ctx.Out = buffer
+
+	err := participationAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"fake.Address[0]", "3", "3", "3", "2", "0"},
+		strings.Fields(lines[1]))
+	require.Equal(t, []string{"fake.Address[1]", "3", "1", "0", "-", "3"},
+		strings.Fields(lines[2]))
+
+	buffer.Reset()
+	ctx.Flags.(node.FlagSet)["missed"] = 1
+
+	err = participationAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(buffer.String()), "\n"), 2)
+
+	ctx.Injector.Inject(fakeService{err: fake.GetError()})
+	err = participationAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to read participation"))
+
+	ctx = node.Context{Injector: node.NewInjector()}
+	err = participationAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for "+
+		"'controller.Service'")
+}
+
+func TestRosterAddAction_Proof(t *testing.T) {
+	signer := bls12381.NewSigner()
+
+	calls := &fake.Call{}
+
+	ctx := prepContext(nil)
+	ctx.Injector.Inject(fakeCosi{signer: makeSigner(signer)})
+	ctx.Injector.Inject(fakeTxManager{calls: calls})
+	ctx.Flags.(node.FlagSet)["member"] = exportMember(t, signer)
+
+	err := rosterAddAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls.Len())
+
+	args := calls.Get(0, 0).([]txn.Arg)
+	require.Len(t, args, 3)
+	require.Equal(t, viewchange.ProofsArg, args[2].Key)
+	require.Contains(t, string(args[2].Value), `"0":`)
+}
+
+func TestRosterMigrateAction_Execute(t *testing.T) {
+	action := rosterMigrateAction{}
+
+	signer := bls12381.NewSigner()
+	roster := authority.New(
+		[]mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+		[]crypto.PublicKey{bls.NewSigner().GetPublicKey(), bls.NewSigner().GetPublicKey()},
+	)
+
+	calls := &fake.Call{}
+
+	ctx := prepContext(nil)
+	ctx.Injector.Inject(fakeCosi{signer: makeSigner(signer)})
+	ctx.Injector.Inject(fakeTxManager{calls: calls})
+	ctx.Injector.Inject(fakeService{roster: roster, events: []ordering.Event{
+		{Transactions: []validation.TransactionResult{fakeResult{}}},
+	}})
+	ctx.Flags.(node.FlagSet)["algorithm"] = "bls12381"
+	ctx.Flags.(node.FlagSet)["wait"] = float64(time.Second)
+
+	err := action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls.Len())
+
+	args := calls.Get(0, 0).([]txn.Arg)
+	require.Len(t, args, 3)
+	require.Contains(t, string(args[2].Value), `"0":`)
+	require.NotContains(t, string(args[2].Value), `"1":`)
+
+	ctx.Injector.Inject(fakeTxManager{errMake: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("transaction: creating transaction"))
+
+	ctx.Injector.Inject(fakeTxManager{errSync: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("txn manager: sync"))
+
+	ctx.Injector.Inject(fakeService{err: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to read roster"))
+
+	ctx.Flags.(node.FlagSet)["algorithm"] = "unknown"
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "signer: unknown algorithm 'unknown'")
+
+	ctx.Injector.Inject(fakeService{})
+	ctx.Injector.Inject(fakeTxManager{})
+	ctx.Flags.(node.FlagSet)["algorithm"] = "bls12381"
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "transaction: fake.Address[0] is not a member")
+
+	ctx.Injector = node.NewInjector()
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'mino.Mino'")
+
+	ctx.Injector.Inject(fakeEndorsingMino{endorsed: true})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "migration is not supported with endorsement")
+
+	ctx.Injector.Inject(fakeEndorsingMino{})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'controller.Service'")
+
+	ctx.Injector.Inject(fakeService{})
+	err = action.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'cosi.CollectiveSigning'")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -193,15 +428,27 @@ type fakeService struct {
 	ordering.Service
 	calls  *fake.Call
 	events []ordering.Event
+	stats  []participation.Stats
+	roster authority.Authority
 	err    error
 }
 
+func (s fakeService) GetParticipation() ([]participation.Stats, error) {
+	return s.stats, s.err
+}
+
 func (s fakeService) GetRoster() (authority.Authority, error) {
+	if s.roster != nil {
+		return s.roster, s.err
+	}
+
 	return authority.New(nil, nil), s.err
 }
 
-func (s fakeService) Setup(ctx context.Context, ca crypto.CollectiveAuthority) error {
-	s.calls.Add(ctx, ca)
+func (s fakeService) Setup(ctx context.Context, ca crypto.CollectiveAuthority,
+	opts ...cosipbft.SetupOption) error {
+
+	s.calls.Add(ctx, ca, len(opts))
 	return s.err
 }
 
@@ -219,9 +466,20 @@ func (s fakeService) Close() error {
 	return s.err
 }
 
+type fakeEndorsingMino struct {
+	fake.Mino
+	endorsed bool
+}
+
+func (m fakeEndorsingMino) HasEndorsement() bool {
+	return m.endorsed
+}
+
 type fakeCosi struct {
 	cosi.CollectiveSigning
-	err bool
+	err    bool
+	errSig bool
+	signer crypto.Signer
 }
 
 func (c fakeCosi) GetPublicKeyFactory() crypto.PublicKeyFactory {
@@ -229,17 +487,62 @@ func (c fakeCosi) GetPublicKeyFactory() crypto.PublicKeyFactory {
 		return fake.NewBadPublicKeyFactory()
 	}
 
+	if c.signer != nil {
+		return c.signer.GetPublicKeyFactory()
+	}
+
 	return fake.NewPublicKeyFactory(fake.PublicKey{})
 }
 
+func (c fakeCosi) GetSignatureFactory() crypto.SignatureFactory {
+	return c.signer.GetSignatureFactory()
+}
+
+func (c fakeCosi) GetVerifierFactory() crypto.VerifierFactory {
+	return c.signer.(crypto.AggregateSigner).GetVerifierFactory()
+}
+
 func (c fakeCosi) GetSigner() crypto.Signer {
 	if c.err {
 		return fake.NewSignerWithPublicKey(fake.NewBadPublicKey())
 	}
 
+	if c.errSig {
+		return fake.NewSignerWithSignatureFactory(fake.NewBadSignatureFactory())
+	}
+
+	if c.signer != nil {
+		return c.signer
+	}
+
 	return fake.NewSigner()
 }
 
+// makeSigner returns a signer with BN256 as the active algorithm.
+func makeSigner(signer bls12381.Signer) *common.Signer {
+	multi := common.NewSigner(bls.Algorithm, bls.NewSigner())
+	multi.Add(bls12381.Algorithm, signer)
+
+	return multi
+}
+
+// exportMember returns the description of a member with the public key and the
+// proof of possession of the signer.
+func exportMember(t *testing.T, signer crypto.Signer) string {
+	pubkey, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	proof, err := signer.(crypto.PossessionProver).ProvePossession()
+	require.NoError(t, err)
+
+	data, err := proof.Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString([]byte("A")) + separator +
+		base64.StdEncoding.EncodeToString(pubkey) + separator +
+		base64.StdEncoding.EncodeToString(data)
+}
+
 type fakeTx struct {
 	txn.Transaction
 }
@@ -271,11 +574,14 @@ func (res fakeResult) GetStatus() (bool, string) {
 
 type fakeTxManager struct {
 	txn.Manager
+	calls   *fake.Call
 	errMake error
 	errSync error
 }
 
 func (mgr fakeTxManager) Make(args ...txn.Arg) (txn.Transaction, error) {
+	mgr.calls.Add(args)
+
 	return fakeTx{}, mgr.errMake
 }
 
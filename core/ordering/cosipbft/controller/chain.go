@@ -0,0 +1,493 @@
+// This file contains the implementation of the actions to export a chain to a
+// file and to import it into a new database.
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// chainStreamFormat is the identifier of the stream of a chain.
+	chainStreamFormat = "dela-chain"
+
+	// chainStreamVersion is the version of the stream of a chain.
+	chainStreamVersion = 1
+)
+
+// chainHeader is the first record of the stream of a chain. It describes the
+// records that follow, which are the genesis block, the forward links of the
+// blocks before the exported range, the block links of the range, and the
+// leaves of the tree if the snapshot is included.
+type chainHeader struct {
+	Format   string
+	Version  uint32
+	Encoding serde.Format
+
+	// Links is the number of forward links without their block that precede
+	// the first block, so that the roster can be followed from the genesis.
+	Links uint64
+
+	// Blocks is the number of block links.
+	Blocks uint64
+
+	// Tree is true when the stream ends with the leaves of the tree at the last
+	// block.
+	Tree bool
+}
+
+// chainRecord is a record of the stream of a chain. Exactly one of the fields
+// is set.
+type chainRecord struct {
+	Genesis json.RawMessage `json:",omitempty"`
+	Link    json.RawMessage `json:",omitempty"`
+	Block   json.RawMessage `json:",omitempty"`
+	Leaf    *chainLeaf      `json:",omitempty"`
+}
+
+// chainLeaf is a key/value pair of the tree.
+type chainLeaf struct {
+	Key   []byte
+	Value []byte
+}
+
+// leafWalker is implemented by the trees that can iterate over their leaves.
+type leafWalker interface {
+	WalkLeaves(fn func(key, value []byte) error) error
+}
+
+// readTx is a read-only transaction that the stores can use to read a
+// consistent state of the database.
+//
+// - implements store.Transaction
+type readTx struct {
+	kv.ReadableTx
+}
+
+// OnCommit implements store.Transaction. It does nothing as a read-only
+// transaction is never committed.
+func (readTx) OnCommit(func()) {}
+
+// chainExportAction is an action to write the chain, or a range of it, to a
+// file.
+//
+// - implements node.ActionTemplate
+type chainExportAction struct{}
+
+// Execute implements node.ActionTemplate. It writes the stream of the chain to
+// the file. The snapshot of the tree is only available at the last block, as
+// the tree only keeps the latest state.
+func (a chainExportAction) Execute(ctx node.Context) error {
+	var genstore blockstore.GenesisStore
+	err := ctx.Injector.Resolve(&genstore)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var db kv.DB
+	err = ctx.Injector.Resolve(&db)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	genesis, err := genstore.Get()
+	if err != nil {
+		return xerrors.Errorf("failed to read genesis: %v", err)
+	}
+
+	header, err := a.makeHeader(ctx, blocks.Len())
+	if err != nil {
+		return xerrors.Errorf("invalid range: %v", err)
+	}
+
+	path := ctx.Flags.String("out")
+
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return xerrors.Errorf("failed to create output: %v", err)
+	}
+
+	err = exportChain(out, db, genesis, blocks, header)
+	if err == nil {
+		err = out.Close()
+	} else {
+		out.Close()
+	}
+
+	if err != nil {
+		os.Remove(path)
+		return xerrors.Errorf("failed to export: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "Exported %d block(s) to %s", header.Blocks, path)
+
+	return nil
+}
+
+// makeHeader returns the header of the range of the flags. A negative end of
+// the range is the last block of the chain.
+func (a chainExportAction) makeHeader(ctx node.Context, length uint64) (chainHeader, error) {
+	last := int(length) - 1
+
+	from := ctx.Flags.Int("from")
+	to := ctx.Flags.Int("to")
+
+	if to < 0 {
+		to = last
+	}
+
+	if to > last {
+		return chainHeader{}, xerrors.Errorf("block %d not found: %w", to, blockstore.ErrNoBlock)
+	}
+
+	if from < 0 || from > to+1 {
+		return chainHeader{}, xerrors.Errorf("block %d is after block %d", from, to)
+	}
+
+	tree := ctx.Flags.Bool("tree")
+
+	if tree && to != last {
+		return chainHeader{}, xerrors.Errorf("tree snapshot is only at the last block %d", last)
+	}
+
+	header := chainHeader{
+		Format:   chainStreamFormat,
+		Version:  chainStreamVersion,
+		Encoding: serde.FormatJSON,
+		Links:    uint64(from),
+		Blocks:   uint64(to + 1 - from),
+		Tree:     tree,
+	}
+
+	return header, nil
+}
+
+// exportChain writes the stream of the chain described by the header. The
+// blocks and the tree are read in a single transaction so that the snapshot
+// matches the last block.
+func exportChain(w io.Writer, db kv.DB, genesis types.Genesis,
+	blocks blockstore.BlockStore, header chainHeader) error {
+
+	ctx := sjson.NewContext()
+	enc := json.NewEncoder(w)
+
+	err := enc.Encode(header)
+	if err != nil {
+		return xerrors.Errorf("failed to write header: %v", err)
+	}
+
+	data, err := genesis.Serialize(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to serialize genesis: %v", err)
+	}
+
+	err = enc.Encode(chainRecord{Genesis: data})
+	if err != nil {
+		return xerrors.Errorf("failed to write genesis: %v", err)
+	}
+
+	return db.View(func(tx kv.ReadableTx) error {
+		blocks := blocks.WithTx(readTx{tx})
+
+		end := header.Links + header.Blocks
+
+		for index := uint64(0); index < end; index++ {
+			link, err := blocks.GetByIndex(index)
+			if err != nil {
+				return xerrors.Errorf("failed to read block: %v", err)
+			}
+
+			var record chainRecord
+
+			if index < header.Links {
+				record.Link, err = link.Reduce().Serialize(ctx)
+			} else {
+				record.Block, err = link.Serialize(ctx)
+			}
+
+			if err != nil {
+				return xerrors.Errorf("failed to serialize link: %v", err)
+			}
+
+			err = enc.Encode(record)
+			if err != nil {
+				return xerrors.Errorf("failed to write link: %v", err)
+			}
+		}
+
+		if !header.Tree {
+			return nil
+		}
+
+		// The store is ahead of the transaction when a block is committed in
+		// the meantime, and the tree would not match the last block.
+		_, err := blocks.GetByIndex(end)
+		if !errors.Is(err, blockstore.ErrNoBlock) {
+			return xerrors.Errorf("block %d committed during the export", end)
+		}
+
+		tree, ok := binprefix.NewMerkleTree(db, binprefix.Nonce{}).WithTx(readTx{tx}).(leafWalker)
+		if !ok {
+			return xerrors.New("tree cannot walk its leaves")
+		}
+
+		err = tree.WalkLeaves(func(key, value []byte) error {
+			return enc.Encode(chainRecord{Leaf: &chainLeaf{Key: key, Value: value}})
+		})
+		if err != nil {
+			return xerrors.Errorf("failed to write tree: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// chainImportAction is an action to load the stream of a chain into a new
+// database, which a node can then start from.
+//
+// - implements node.ActionTemplate
+type chainImportAction struct{}
+
+// Execute implements node.ActionTemplate. It verifies the chain of the stream
+// and writes the genesis block, the blocks and the tree to the new database.
+// The database is removed if the stream is not valid.
+func (chainImportAction) Execute(ctx node.Context) error {
+	var m mino.Mino
+	err := ctx.Injector.Resolve(&m)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var c cosi.CollectiveSigning
+	err = ctx.Injector.Resolve(&c)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	in, err := os.Open(ctx.Flags.String("in"))
+	if err != nil {
+		return xerrors.Errorf("failed to open input: %v", err)
+	}
+
+	defer in.Close()
+
+	path := ctx.Flags.String("db")
+
+	_, err = os.Stat(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		return xerrors.Errorf("database '%s' already exists", path)
+	}
+
+	db, err := kv.New(path)
+	if err != nil {
+		return xerrors.Errorf("failed to open database: %v", err)
+	}
+
+	header, err := importChain(in, db, m.GetAddressFactory(), c)
+
+	db.Close()
+
+	if err != nil {
+		os.Remove(path)
+		return xerrors.Errorf("failed to import: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "Imported %d block(s) to %s", header.Blocks, path)
+
+	if !header.Tree {
+		fmt.Fprint(ctx.Out, " without the tree")
+	}
+
+	return nil
+}
+
+// importChain reads the stream of a chain and writes it to the database. The
+// whole chain is verified from the genesis block, and the root of the tree must
+// match the last block.
+func importChain(r io.Reader, db kv.DB, addrFac mino.AddressFactory,
+	c cosi.CollectiveSigning) (chainHeader, error) {
+
+	dec := json.NewDecoder(r)
+
+	var header chainHeader
+	err := dec.Decode(&header)
+	if err != nil {
+		return header, xerrors.Errorf("failed to read header: %v", err)
+	}
+
+	if header.Format != chainStreamFormat || header.Version != chainStreamVersion ||
+		header.Encoding != serde.FormatJSON {
+
+		return header, xerrors.Errorf("unsupported stream '%s' version %d in %s",
+			header.Format, header.Version, header.Encoding)
+	}
+
+	// A new database needs every block to be loaded by a node.
+	if header.Links > 0 {
+		return header, xerrors.Errorf("stream starts at block %d instead of 0", header.Links)
+	}
+
+	ctx := sjson.NewContext()
+
+	// The digests are computed with the hash algorithm of the chain, which is
+	// known once the genesis block is read.
+	hashFac := types.NewChainHashFactory()
+	hashOpt := types.WithFactoryHash(hashFac)
+
+	txFac := signed.NewTransactionFactory(signed.WithFactoryHash(hashFac))
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(txFac), hashOpt)
+	csFac := authority.NewChangeSetFactory(addrFac, c.GetPublicKeyFactory())
+	linkFac := types.NewLinkFactory(blockFac, c.GetSignatureFactory(), csFac, hashOpt)
+	genesisFac := types.NewGenesisFactory(authority.NewFactory(addrFac, c.GetPublicKeyFactory()))
+
+	var record chainRecord
+	err = dec.Decode(&record)
+	if err != nil {
+		return header, xerrors.Errorf("failed to read genesis: %v", err)
+	}
+
+	msg, err := genesisFac.Deserialize(ctx, record.Genesis)
+	if err != nil {
+		return header, xerrors.Errorf("failed to decode genesis: %v", err)
+	}
+
+	genesis, ok := msg.(types.Genesis)
+	if !ok {
+		return header, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	hashFac.SetAlgorithm(genesis.GetHashAlgorithm())
+	hashFac.SetFingerprintVersion(genesis.GetFingerprintVersion())
+
+	var links []types.BlockLink
+
+	for index := uint64(0); index < header.Blocks; index++ {
+		var record chainRecord
+		err = dec.Decode(&record)
+		if err != nil {
+			return header, xerrors.Errorf("failed to read block %d: %v", index, err)
+		}
+
+		link, err := linkFac.BlockLinkOf(ctx, record.Block)
+		if err != nil {
+			return header, xerrors.Errorf("failed to decode block %d: %v", index, err)
+		}
+
+		if link.GetBlock().GetIndex() != index {
+			return header, xerrors.Errorf("unexpected block %d instead of %d",
+				link.GetBlock().GetIndex(), index)
+		}
+
+		links = append(links, link)
+	}
+
+	root := genesis.GetRoot()
+
+	if len(links) > 0 {
+		// The verification skips the links until it finds the digest, which
+		// must therefore be the first one so that every link is verified.
+		if links[0].GetFrom() != genesis.GetHash() {
+			return header, xerrors.Errorf("mismatch genesis '%v' != '%v'",
+				links[0].GetFrom(), genesis.GetHash())
+		}
+
+		prevs := make([]types.Link, len(links)-1)
+		for i, link := range links[:len(links)-1] {
+			prevs[i] = link.Reduce()
+		}
+
+		chain := types.NewChain(links[len(links)-1], prevs)
+
+		err = chain.Verify(genesis, genesis.GetHash(), c.GetVerifierFactory())
+		if err != nil {
+			return header, xerrors.Errorf("invalid chain: %v", err)
+		}
+
+		root = chain.GetBlock().GetTreeRoot()
+	}
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{}, binprefix.WithHashFactory(hashFac))
+
+	stage, err := tree.Stage(func(snap store.Snapshot) error {
+		for header.Tree && dec.More() {
+			var record chainRecord
+			err := dec.Decode(&record)
+			if err != nil {
+				return xerrors.Errorf("failed to read leaf: %v", err)
+			}
+
+			if record.Leaf == nil {
+				return xerrors.New("record is not a leaf")
+			}
+
+			err = snap.Set(record.Leaf.Key, record.Leaf.Value)
+			if err != nil {
+				return xerrors.Errorf("failed to set leaf: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return header, xerrors.Errorf("failed to read tree: %v", err)
+	}
+
+	if dec.More() {
+		return header, xerrors.New("unexpected record after the chain")
+	}
+
+	stageRoot := types.Digest{}
+	copy(stageRoot[:], stage.GetRoot())
+
+	if header.Tree && stageRoot != root {
+		return header, xerrors.Errorf("mismatch tree root '%v' != '%v'", stageRoot, root)
+	}
+
+	err = blockstore.NewGenesisDiskStore(db, genesisFac).Set(genesis)
+	if err != nil {
+		return header, xerrors.Errorf("failed to store genesis: %v", err)
+	}
+
+	blocks := blockstore.NewDiskStore(db, linkFac)
+
+	for _, link := range links {
+		err = blocks.Store(link)
+		if err != nil {
+			return header, xerrors.Errorf("failed to store block: %v", err)
+		}
+	}
+
+	if header.Tree {
+		err = stage.Commit()
+		if err != nil {
+			return header, xerrors.Errorf("failed to store tree: %v", err)
+		}
+	}
+
+	return header, nil
+}
@@ -0,0 +1,480 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestChainExportAction_Execute(t *testing.T) {
+	dir := t.TempDir()
+	signer := bls.NewSigner()
+
+	ctx := prepChainContext(t, dir, signer)
+
+	buffer := new(bytes.Buffer)
+	ctx.Out = buffer
+
+	path := filepath.Join(dir, "chain")
+
+	ctx.Flags.(node.FlagSet)["out"] = path
+	ctx.Flags.(node.FlagSet)["to"] = -1
+	ctx.Flags.(node.FlagSet)["tree"] = true
+
+	err := chainExportAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Exported 2 block(s) to "+path, buffer.String())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 6)
+	require.Equal(t, `{"Format":"dela-chain","Version":1,"Encoding":"JSON","Links":0,`+
+		`"Blocks":2,"Tree":true}`, lines[0])
+	require.Contains(t, lines[1], `{"Genesis":`)
+	require.Contains(t, lines[2], `{"Block":`)
+	require.Contains(t, lines[3], `{"Block":`)
+	require.Contains(t, lines[4], `{"Leaf":`)
+	require.Contains(t, lines[5], `{"Leaf":`)
+
+	ctx.Flags.(node.FlagSet)["from"] = 1
+	ctx.Flags.(node.FlagSet)["tree"] = false
+	err = chainExportAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 4)
+	require.Contains(t, lines[0], `"Links":1,"Blocks":1,"Tree":false`)
+	require.Contains(t, lines[2], `{"Link":`)
+	require.Contains(t, lines[3], `{"Block":`)
+
+	ctx.Flags.(node.FlagSet)["to"] = 0
+	ctx.Flags.(node.FlagSet)["tree"] = true
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err, "invalid range: tree snapshot is only at the last block 1")
+
+	ctx.Flags.(node.FlagSet)["from"] = 2
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err, "invalid range: block 2 is after block 0")
+
+	ctx.Flags.(node.FlagSet)["to"] = 2
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err, "invalid range: block 2 not found: no block")
+
+	ctx.Flags.(node.FlagSet)["from"] = 0
+	ctx.Flags.(node.FlagSet)["to"] = -1
+	ctx.Flags.(node.FlagSet)["out"] = filepath.Join(dir, "unknown", "chain")
+	err = chainExportAction{}.Execute(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create output: ")
+
+	ctx.Injector = node.NewInjector()
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.GenesisStore'")
+
+	ctx.Injector.Inject(blockstore.NewGenesisStore())
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'kv.DB'")
+
+	ctx.Injector.Inject(fakeDB{})
+	err = chainExportAction{}.Execute(ctx)
+	require.EqualError(t, err, "failed to read genesis: missing genesis block")
+}
+
+func TestChainExportAction_Empty(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	genesis, err := types.NewGenesis(authority.New(nil, nil))
+	require.NoError(t, err)
+
+	genstore := blockstore.NewGenesisStore()
+	require.NoError(t, genstore.Set(genesis))
+
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    node.FlagSet{"out": filepath.Join(dir, "chain"), "to": -1, "tree": true},
+		Out:      new(bytes.Buffer),
+	}
+
+	ctx.Injector.Inject(genstore)
+	ctx.Injector.Inject(blockstore.NewDiskStore(db, nil))
+	ctx.Injector.Inject(db)
+
+	err = chainExportAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Exported 0 block(s) to "+filepath.Join(dir, "chain"),
+		ctx.Out.(*bytes.Buffer).String())
+}
+
+func TestExportChain(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx := prepChainContext(t, dir, bls.NewSigner())
+
+	var genstore blockstore.GenesisStore
+	require.NoError(t, ctx.Injector.Resolve(&genstore))
+
+	var blocks blockstore.BlockStore
+	require.NoError(t, ctx.Injector.Resolve(&blocks))
+
+	var db kv.DB
+	require.NoError(t, ctx.Injector.Resolve(&db))
+
+	genesis, err := genstore.Get()
+	require.NoError(t, err)
+
+	header := chainHeader{Blocks: 2, Tree: true}
+
+	err = exportChain(fake.NewBadHash(), db, genesis, blocks, header)
+	require.EqualError(t, err, fake.Err("failed to write header"))
+
+	err = exportChain(fake.NewBadHashWithDelay(1), db, genesis, blocks, header)
+	require.EqualError(t, err, fake.Err("failed to write genesis"))
+
+	err = exportChain(fake.NewBadHashWithDelay(2), db, genesis, blocks, header)
+	require.EqualError(t, err, fake.Err("failed to write link"))
+
+	err = exportChain(fake.NewBadHashWithDelay(4), db, genesis, blocks, header)
+	require.EqualError(t, err, fake.Err("failed to write tree"))
+
+	err = exportChain(io.Discard, db, genesis, blocks, chainHeader{Blocks: 3})
+	require.EqualError(t, err, "failed to read block: index 2 not found: no block")
+
+	header.Blocks = 1
+	err = exportChain(io.Discard, db, genesis, blocks, header)
+	require.EqualError(t, err, "block 1 committed during the export")
+}
+
+func TestChainImportAction_Execute(t *testing.T) {
+	dir := t.TempDir()
+	signer := bls.NewSigner()
+
+	ctx := prepChainContext(t, dir, signer)
+
+	path := filepath.Join(dir, "chain")
+
+	ctx.Flags.(node.FlagSet)["out"] = path
+	ctx.Flags.(node.FlagSet)["to"] = -1
+	ctx.Flags.(node.FlagSet)["tree"] = true
+
+	err := chainExportAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	buffer := new(bytes.Buffer)
+	ctx.Out = buffer
+
+	dbPath := filepath.Join(dir, "imported.db")
+
+	ctx.Flags.(node.FlagSet)["in"] = path
+	ctx.Flags.(node.FlagSet)["db"] = dbPath
+
+	err = chainImportAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Imported 2 block(s) to "+dbPath, buffer.String())
+
+	db, err := kv.New(dbPath)
+	require.NoError(t, err)
+
+	genstore := blockstore.NewGenesisDiskStore(db, makeGenesisFac(signer))
+	require.NoError(t, genstore.Load())
+	require.True(t, genstore.Exists())
+
+	blocks := blockstore.NewDiskStore(db, makeLinkFac(signer))
+	require.NoError(t, blocks.Load())
+	require.Equal(t, uint64(2), blocks.Len())
+
+	last, err := blocks.Last()
+	require.NoError(t, err)
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+	require.NoError(t, tree.Load())
+	require.Equal(t, last.GetBlock().GetTreeRoot().Bytes(), tree.GetRoot())
+
+	value, err := tree.Get([]byte("B"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), value)
+
+	require.NoError(t, db.Close())
+
+	err = chainImportAction{}.Execute(ctx)
+	require.EqualError(t, err, "database '"+dbPath+"' already exists")
+
+	// The database is removed when the stream is not valid.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data[:len(data)-10], 0600))
+
+	ctx.Flags.(node.FlagSet)["db"] = filepath.Join(dir, "other.db")
+	err = chainImportAction{}.Execute(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to import: failed to read tree: ")
+	require.NoFileExists(t, filepath.Join(dir, "other.db"))
+
+	ctx.Flags.(node.FlagSet)["tree"] = false
+	err = chainExportAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	buffer.Reset()
+	err = chainImportAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Imported 2 block(s) to "+filepath.Join(dir, "other.db")+
+		" without the tree", buffer.String())
+
+	ctx.Flags.(node.FlagSet)["db"] = filepath.Join(dir, "unknown", "imported.db")
+	err = chainImportAction{}.Execute(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to open database: ")
+
+	ctx.Flags.(node.FlagSet)["in"] = filepath.Join(dir, "unknown")
+	err = chainImportAction{}.Execute(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to open input: ")
+
+	ctx.Injector = node.NewInjector()
+	err = chainImportAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'mino.Mino'")
+
+	ctx.Injector.Inject(fake.Mino{})
+	err = chainImportAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'cosi.CollectiveSigning'")
+}
+
+func TestImportChain(t *testing.T) {
+	dir := t.TempDir()
+	signer := bls.NewSigner()
+
+	ctx := prepChainContext(t, dir, signer)
+
+	path := filepath.Join(dir, "chain")
+
+	ctx.Flags.(node.FlagSet)["out"] = path
+	ctx.Flags.(node.FlagSet)["to"] = -1
+	ctx.Flags.(node.FlagSet)["tree"] = true
+
+	err := chainExportAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	importLines := func(lines ...string) error {
+		db, err := kv.New(filepath.Join(t.TempDir(), "test.db"))
+		require.NoError(t, err)
+
+		defer db.Close()
+
+		r := strings.NewReader(strings.Join(lines, "\n"))
+
+		_, err = importChain(r, db, fake.AddressFactory{}, fakeCosi{signer: signer})
+		return err
+	}
+
+	require.NoError(t, importLines(lines...))
+
+	err = importLines()
+	require.EqualError(t, err, "failed to read header: EOF")
+
+	err = importLines(`{"Format":"dela-chain","Version":2,"Encoding":"JSON"}`)
+	require.EqualError(t, err, "unsupported stream 'dela-chain' version 2 in JSON")
+
+	err = importLines(strings.Replace(lines[0], `"Links":0`, `"Links":1`, 1))
+	require.EqualError(t, err, "stream starts at block 1 instead of 0")
+
+	err = importLines(lines[0])
+	require.EqualError(t, err, "failed to read genesis: EOF")
+
+	err = importLines(lines[0], "{}")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decode genesis: ")
+
+	err = importLines(lines[:2]...)
+	require.EqualError(t, err, "failed to read block 0: EOF")
+
+	err = importLines(lines[0], lines[1], lines[1])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decode block 0: ")
+
+	err = importLines(lines[0], lines[1], lines[3])
+	require.EqualError(t, err, "unexpected block 1 instead of 0")
+
+	err = importLines(lines[0], lines[1], lines[2], lines[2])
+	require.EqualError(t, err, "unexpected block 0 instead of 1")
+
+	err = importLines(lines[0], lines[1], lines[2], lines[3], lines[4])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mismatch tree root ")
+
+	err = importLines(append(lines, lines[1])...)
+	require.EqualError(t, err, "failed to read tree: callback failed: record is not a leaf")
+
+	err = importLines(append(lines, "{")...)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read tree: callback failed: failed to read leaf: ")
+
+	noTree := strings.Replace(lines[0], `"Tree":true`, `"Tree":false`, 1)
+
+	err = importLines(noTree, lines[1], lines[2], lines[3])
+	require.NoError(t, err)
+
+	err = importLines(noTree, lines[1], lines[2], lines[3], lines[4])
+	require.EqualError(t, err, "unexpected record after the chain")
+
+	_, err = importChain(strings.NewReader(strings.Join(lines, "\n")), fakeDB{},
+		fake.AddressFactory{}, fakeCosi{signer: signer})
+	require.EqualError(t, err, fake.Err("failed to read tree"))
+
+	// The records of another chain, which is signed by another roster, are
+	// rejected.
+	other := prepChainContext(t, t.TempDir(), bls.NewSigner())
+	other.Flags = node.FlagSet{"out": path, "to": -1}
+
+	err = chainExportAction{}.Execute(other)
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+
+	otherLines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	err = importLines(noTree, otherLines[1], lines[2], lines[3])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mismatch genesis ")
+
+	err = importLines(noTree, lines[1], lines[2], otherLines[3])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid chain: invalid prepare signature: ")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// prepChainContext returns a context with the stores of a chain of two blocks
+// signed by the signer, and a tree with two leaves.
+func prepChainContext(t *testing.T, dir string, signer bls.Signer) node.Context {
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+
+	stage, err := tree.Stage(func(snap store.Snapshot) error {
+		require.NoError(t, snap.Set([]byte("A"), []byte("1")))
+		require.NoError(t, snap.Set([]byte("B"), []byte("2")))
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, stage.Commit())
+
+	root := types.Digest{}
+	copy(root[:], stage.GetRoot())
+
+	roster := authority.New([]mino.Address{fake.NewAddress(0)},
+		[]crypto.PublicKey{signer.GetPublicKey()})
+
+	genesis, err := types.NewGenesis(roster)
+	require.NoError(t, err)
+
+	genstore := blockstore.NewGenesisDiskStore(db, makeGenesisFac(signer))
+	require.NoError(t, genstore.Set(genesis))
+
+	blocks := blockstore.NewDiskStore(db, makeLinkFac(signer))
+
+	prev := genesis.GetHash()
+
+	for index := uint64(0); index < 2; index++ {
+		block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(index),
+			types.WithTreeRoot(root))
+		require.NoError(t, err)
+
+		link, err := types.NewBlockLink(prev, block)
+		require.NoError(t, err)
+
+		prepare, err := signer.Sign(link.GetHash().Bytes())
+		require.NoError(t, err)
+
+		data, err := prepare.MarshalBinary()
+		require.NoError(t, err)
+
+		commit, err := signer.Sign(data)
+		require.NoError(t, err)
+
+		link, err = types.NewBlockLink(prev, block, types.WithSignatures(prepare, commit))
+		require.NoError(t, err)
+
+		require.NoError(t, blocks.Store(link))
+
+		prev = link.GetTo()
+	}
+
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    make(node.FlagSet),
+		Out:      new(bytes.Buffer),
+	}
+
+	ctx.Injector.Inject(fake.Mino{})
+	ctx.Injector.Inject(fakeCosi{signer: signer})
+	ctx.Injector.Inject(genstore)
+	ctx.Injector.Inject(blocks)
+	ctx.Injector.Inject(db)
+
+	return ctx
+}
+
+func makeGenesisFac(signer bls.Signer) types.GenesisFactory {
+	return types.NewGenesisFactory(authority.NewFactory(fake.AddressFactory{},
+		signer.GetPublicKeyFactory()))
+}
+
+func makeLinkFac(signer bls.Signer) types.LinkFactory {
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+	csFac := authority.NewChangeSetFactory(fake.AddressFactory{}, signer.GetPublicKeyFactory())
+
+	return types.NewLinkFactory(blockFac, signer.GetSignatureFactory(), csFac)
+}
+
+type fakeDB struct {
+	kv.DB
+}
+
+func (fakeDB) Update(func(kv.WritableTx) error) error {
+	return fake.GetError()
+}
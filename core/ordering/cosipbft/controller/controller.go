@@ -5,9 +5,14 @@ package controller
 
 import (
 	"encoding"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/contracts/value"
 	"go.dedis.ch/dela/crypto"
 
@@ -28,37 +33,105 @@ import (
 	"go.dedis.ch/dela/core/validation/simple"
 	"go.dedis.ch/dela/cosi/threshold"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/common"
 	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/nodesigner"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/json"
 	"golang.org/x/xerrors"
 )
 
-const privateKeyFile = "private.key"
+const (
+	privateKeyFile = nodesigner.PrivateKeyFile
+
+	// bls12381KeyFile is the file of the BLS12-381 private key of the node,
+	// used once the roster has migrated to this algorithm.
+	bls12381KeyFile = "private.bls12381.key"
+
+	// bls12381KeyFlag is the flag to create the BLS12-381 key of the node when
+	// it doesn't exist yet.
+	bls12381KeyFlag = "bls12381-key"
+
+	// signerSocketFlag is the flag of the socket of a signer daemon that holds
+	// the BN256 key of the node.
+	signerSocketFlag = nodesigner.SocketFlag
+
+	// signerKeyFlag is the flag of the file of the BN256 key of the node, which
+	// is the private key file of the config folder by default.
+	signerKeyFlag = nodesigner.KeyFlag
+
+	// cosiTreeFlag is the flag of the depth of the tree along which the
+	// collective signatures are aggregated.
+	cosiTreeFlag = "cosi-tree"
+)
+
+// algorithms maps the names accepted by the commands to the algorithms of the
+// signer.
+var algorithms = map[string]string{
+	"bn256":    bls.Algorithm,
+	"bls12381": bls12381.Algorithm,
+}
 
 func blsSigner() encoding.BinaryMarshaler {
 	return bls.NewSigner()
 }
 
+func bls12381Signer() encoding.BinaryMarshaler {
+	return bls12381.NewSigner()
+}
+
+// rosterSetter is implemented by the network overlays that need the roster to
+// verify the identity of the participants. The roster is nil while the chain
+// has none.
+type rosterSetter interface {
+	SetRoster(func() (crypto.CollectiveAuthority, error))
+}
+
 // miniController is a CLI initializer to inject an ordering service that is
 // using collective signatures and PBFT for the consensus.
 //
 // - implements node.Initializer
 type miniController struct {
-	signerFn func() encoding.BinaryMarshaler
+	signerFn         func() encoding.BinaryMarshaler
+	bls12381SignerFn func() encoding.BinaryMarshaler
 }
 
 // NewController creates a new minimal controller for cosipbft.
 func NewController() node.Initializer {
 	return miniController{
-		signerFn: blsSigner,
+		signerFn:         blsSigner,
+		bls12381SignerFn: bls12381Signer,
 	}
 }
 
 // SetCommands implements node.Initializer. It sets the command to control the
 // service.
 func (miniController) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(append(loader.PassphraseFlags(""),
+		cli.StringFlag{
+			Name: signerSocketFlag,
+			Usage: "path to the unix socket of a signer daemon that holds the " +
+				"BN256 key, instead of the key file",
+		},
+		cli.StringFlag{
+			Name: signerKeyFlag,
+			Usage: "path to the file of the BN256 key, instead of " +
+				privateKeyFile + " in the config folder",
+		},
+		cli.BoolFlag{
+			Name: bls12381KeyFlag,
+			Usage: "create the BLS12-381 key of the node if it doesn't exist, " +
+				"which is required to migrate the roster to this algorithm",
+		},
+		cli.IntFlag{
+			Name: cosiTreeFlag,
+			Usage: "aggregate the collective signatures along a tree of the " +
+				"participants with this depth, or 0 to disable",
+		})...)
+
 	cmd := builder.SetCommand("ordering")
 	cmd.SetDescription("Ordering service administration")
 
@@ -70,6 +143,18 @@ func (miniController) SetCommands(builder node.Builder) {
 			Usage: "maximum amount of time to setup",
 			Value: 20 * time.Second,
 		},
+		cli.StringFlag{
+			Name: "hash",
+			Usage: fmt.Sprintf("hash algorithm of the chain, one of %s",
+				strings.Join(crypto.HashAlgorithms(), ", ")),
+			Value: crypto.Sha256.String(),
+		},
+		cli.IntFlag{
+			Name: "format-version",
+			Usage: "version of the fingerprints of the chain, 1 for the nodes of " +
+				"before the canonical encoding",
+			Value: int(canonical.Version),
+		},
 		cli.StringSliceFlag{
 			Name:     "member",
 			Required: true,
@@ -80,12 +165,76 @@ func (miniController) SetCommands(builder node.Builder) {
 
 	sub = cmd.SetSubCommand("export")
 	sub.SetDescription("Export the node information")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name: "algorithm",
+			Usage: "algorithm of the exported public key: 'bn256' or 'bls12381'. " +
+				"By default it uses the algorithm of the roster",
+		},
+	)
 	sub.SetAction(builder.MakeAction(exportAction{}))
 
-	sub = cmd.SetSubCommand("roster")
-	sub.SetDescription("Roster administration")
+	sub = cmd.SetSubCommand("participation")
+	sub.SetDescription("Show the blocks signed by each member of the roster")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name: "missed",
+			Usage: "only show the members that have missed at least this " +
+				"number of consecutive blocks",
+		},
+	)
+	sub.SetAction(builder.MakeAction(participationAction{}))
+
+	chain := cmd.SetSubCommand("chain")
+	chain.SetDescription("Chain administration")
 
-	sub = sub.SetSubCommand("add")
+	sub = chain.SetSubCommand("export")
+	sub.SetDescription("Write the chain to a file, which can be imported into " +
+		"a new database")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:  "from",
+			Usage: "index of the first block",
+		},
+		cli.IntFlag{
+			Name:  "to",
+			Usage: "index of the last block, or the last block of the chain if negative",
+			Value: -1,
+		},
+		cli.StringFlag{
+			Name:     "out",
+			Required: true,
+			Usage:    "the file where the node writes the chain",
+		},
+		cli.BoolFlag{
+			Name: "tree",
+			Usage: "include a snapshot of the tree, which is only available " +
+				"up to the last block",
+		},
+	)
+	sub.SetAction(builder.MakeAction(chainExportAction{}))
+
+	sub = chain.SetSubCommand("import")
+	sub.SetDescription("Verify a chain written by the export and load it into " +
+		"a new database")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "in",
+			Required: true,
+			Usage:    "the file of the chain, read by the node",
+		},
+		cli.StringFlag{
+			Name:     "db",
+			Required: true,
+			Usage:    "path of the new database, which must not exist",
+		},
+	)
+	sub.SetAction(builder.MakeAction(chainImportAction{}))
+
+	roster := cmd.SetSubCommand("roster")
+	roster.SetDescription("Roster administration")
+
+	sub = roster.SetSubCommand("add")
 	sub.SetDescription("Add a member to the chain")
 	sub.SetFlags(
 		cli.StringFlag{
@@ -99,6 +248,22 @@ func (miniController) SetCommands(builder node.Builder) {
 		},
 	)
 	sub.SetAction(builder.MakeAction(rosterAddAction{}))
+
+	sub = roster.SetSubCommand("migrate")
+	sub.SetDescription("Swap the public key of the node for one of another " +
+		"algorithm, the roster migrates once every member has swapped its key")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  "algorithm",
+			Usage: "algorithm of the new public key: 'bn256' or 'bls12381'",
+			Value: "bls12381",
+		},
+		cli.DurationFlag{
+			Name:  "wait",
+			Usage: "wait for the transaction to be processed",
+		},
+	)
+	sub.SetAction(builder.MakeAction(rosterMigrateAction{}))
 }
 
 // OnStart implements node.Initializer. It starts the ordering components and
@@ -115,7 +280,12 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return xerrors.Errorf("signer: %v", err)
 	}
 
-	cosi := threshold.NewThreshold(onet.WithSegment("cosi"), signer)
+	var opts []threshold.Option
+	if flags.Int(cosiTreeFlag) > 0 {
+		opts = append(opts, threshold.WithTree(flags.Int(cosiTreeFlag)))
+	}
+
+	cosi := threshold.NewThreshold(onet.WithSegment("cosi"), signer, opts...)
 	cosi.SetThreshold(threshold.ByzantineThreshold)
 
 	exec := native.NewExecution()
@@ -126,7 +296,12 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 
 	value.RegisterContract(exec, value.NewContract(access))
 
-	txFac := signed.NewTransactionFactory()
+	// The digests are computed with the hash algorithm of the chain, which is
+	// known once the genesis block is loaded or received.
+	hashFac := types.NewChainHashFactory()
+	hashOpt := types.WithFactoryHash(hashFac)
+
+	txFac := signed.NewTransactionFactory(signed.WithFactoryHash(hashFac))
 	vs := simple.NewService(exec, txFac)
 
 	pool, err := poolimpl.NewPool(gossip.NewFlat(onet.WithSegment("pool"), txFac))
@@ -140,7 +315,24 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return xerrors.Errorf("injector: %v", err)
 	}
 
-	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+	genstore := blockstore.NewGenesisDiskStore(db, types.NewGenesisFactory(rosterFac))
+
+	err = genstore.Load()
+	if err != nil {
+		return xerrors.Errorf("failed to load genesis: %v", err)
+	}
+
+	if genstore.Exists() {
+		genesis, err := genstore.Get()
+		if err != nil {
+			return xerrors.Errorf("failed to read genesis: %v", err)
+		}
+
+		hashFac.SetAlgorithm(genesis.GetHashAlgorithm())
+		hashFac.SetFingerprintVersion(genesis.GetFingerprintVersion())
+	}
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{}, binprefix.WithHashFactory(hashFac))
 
 	param := cosipbft.ServiceParam{
 		Mino:       onet,
@@ -157,16 +349,9 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return xerrors.Errorf("failed to load tree: %v", err)
 	}
 
-	genstore := blockstore.NewGenesisDiskStore(db, types.NewGenesisFactory(rosterFac))
-
-	err = genstore.Load()
-	if err != nil {
-		return xerrors.Errorf("failed to load genesis: %v", err)
-	}
-
-	blockFac := types.NewBlockFactory(vs.GetFactory())
+	blockFac := types.NewBlockFactory(vs.GetFactory(), hashOpt)
 	csFac := authority.NewChangeSetFactory(onet.GetAddressFactory(), cosi.GetPublicKeyFactory())
-	linkFac := types.NewLinkFactory(blockFac, cosi.GetSignatureFactory(), csFac)
+	linkFac := types.NewLinkFactory(blockFac, cosi.GetSignatureFactory(), csFac, hashOpt)
 
 	blocks := blockstore.NewDiskStore(db, linkFac)
 
@@ -176,12 +361,42 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 	}
 
 	srvc, err := cosipbft.NewService(param, cosipbft.WithGenesisStore(genstore),
-		cosipbft.WithBlockStore(blocks))
+		cosipbft.WithBlockStore(blocks), cosipbft.WithHashFactory(hashFac))
 	if err != nil {
 		return xerrors.Errorf("service: %v", err)
 	}
 
+	getRoster := func() (crypto.CollectiveAuthority, error) {
+		return srvc.GetRoster()
+	}
+
+	// The roster can only change with a new block, therefore the signer
+	// follows it only when the chain grows.
+	signer.SetRoster(getRoster, blocks.Len)
+
+	setter, ok := onet.(rosterSetter)
+	if ok {
+		setter.SetRoster(func() (crypto.CollectiveAuthority, error) {
+			// The chain has no roster before the genesis, while the nodes
+			// connect to each other to set it up.
+			if !genstore.Exists() {
+				return nil, nil
+			}
+
+			return getRoster()
+		})
+	}
+
+	// The proofs of the service can be verified by other components, for
+	// instance to authorize the decryptions of a DKG.
+	chainFac := types.NewChainFactory(linkFac)
+	proofFac := cosipbft.NewProofFactory(binprefix.NewPathFactory(hashFac), chainFac)
+
 	inj.Inject(srvc)
+	inj.Inject(hashFac)
+	inj.Inject(genstore)
+	inj.Inject(blocks)
+	inj.Inject(proofFac)
 	inj.Inject(cosi)
 	inj.Inject(pool)
 	inj.Inject(vs)
@@ -219,20 +434,142 @@ func (miniController) OnStop(inj node.Injector) error {
 	return nil
 }
 
-func (m miniController) getSigner(flags cli.Flags) (crypto.AggregateSigner, error) {
-	loader := loader.NewFileLoader(filepath.Join(flags.Path("config"), privateKeyFile))
+// getSigner loads the private keys of the node. They are stored encrypted when
+// a passphrase is given. The BLS12-381 key is only created when the flag is
+// set, and loaded if it exists.
+func (m miniController) getSigner(flags cli.Flags) (*rosterSigner, error) {
+	source, err := loader.PassphraseFromFlags(flags, "")
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	signer, err := m.getBN256Signer(flags, source)
+	if err != nil {
+		return nil, err
+	}
+
+	multi := common.NewSigner(bls.Algorithm, signer)
+
+	path := filepath.Join(flags.Path("config"), bls12381KeyFile)
+
+	_, err = os.Stat(path)
+	if err != nil && !flags.Bool(bls12381KeyFlag) {
+		return &rosterSigner{Signer: multi}, nil
+	}
+
+	signerdata, err := loader.NewKeyLoader(path, source).
+		LoadOrCreate(generator{newFn: m.bls12381SignerFn})
+	if err != nil {
+		return nil, xerrors.Errorf("while loading %s: %v", bls12381KeyFile, err)
+	}
+
+	other, err := bls12381.NewSignerFromBytes(signerdata)
+	if err != nil {
+		return nil, xerrors.Errorf("while unmarshaling %s: %v", bls12381KeyFile, err)
+	}
+
+	multi.Add(bls12381.Algorithm, other)
+
+	return &rosterSigner{Signer: multi}, nil
+}
+
+// getBN256Signer returns a remote signer if a signer daemon is set, otherwise
+// the signer of the key file.
+func (m miniController) getBN256Signer(flags cli.Flags,
+	source loader.PassphraseSource) (crypto.AggregateSigner, error) {
+
+	return nodesigner.LoadWith(flags, source, generator{newFn: m.signerFn})
+}
+
+// rosterSigner is a signer that uses the algorithm of the current roster, so
+// that a node keeps signing with the right key after the chain migrates to
+// another algorithm.
+//
+// - implements crypto.AggregateSigner
+type rosterSigner struct {
+	*common.Signer
+
+	lock    sync.Mutex
+	roster  func() (crypto.CollectiveAuthority, error)
+	version func() uint64
+
+	// followed is true when the algorithm has been selected for the roster of
+	// the version.
+	followed bool
+	current  uint64
+}
+
+// SetRoster sets the function that returns the current roster, and the one
+// that returns its version. The roster is read again only when the version
+// changes.
+func (s *rosterSigner) SetRoster(roster func() (crypto.CollectiveAuthority, error),
+	version func() uint64) {
+
+	s.lock.Lock()
+	s.roster = roster
+	s.version = version
+	s.followed = false
+	s.lock.Unlock()
+}
+
+// GetAlgorithm returns the algorithm of the current roster.
+func (s *rosterSigner) GetAlgorithm() string {
+	s.follow()
+
+	return s.Signer.GetAlgorithm()
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// algorithm of the current roster.
+func (s *rosterSigner) GetPublicKey() crypto.PublicKey {
+	s.follow()
+
+	return s.Signer.GetPublicKey()
+}
+
+// Sign implements crypto.Signer. It signs the message with the algorithm of
+// the current roster.
+func (s *rosterSigner) Sign(msg []byte) (crypto.Signature, error) {
+	s.follow()
+
+	return s.Signer.Sign(msg)
+}
+
+// Unscoped implements crypto.UnscopedSigner. It returns the signer of the
+// messages without scope of the algorithm of the current roster.
+func (s *rosterSigner) Unscoped() crypto.Signer {
+	s.follow()
+
+	return s.Signer.Unscoped()
+}
+
+// follow activates the algorithm of the current roster if its version has
+// changed. The active algorithm is kept when the roster is not yet available.
+func (s *rosterSigner) follow() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.roster == nil {
+		return
+	}
+
+	version := s.version()
+	if s.followed && version == s.current {
+		return
+	}
 
-	signerdata, err := loader.LoadOrCreate(generator{newFn: m.signerFn})
+	roster, err := s.roster()
 	if err != nil {
-		return nil, xerrors.Errorf("while loading: %v", err)
+		return
 	}
 
-	signer, err := bls.NewSignerFromBytes(signerdata)
+	_, err = s.ActivateFor(roster)
 	if err != nil {
-		return nil, xerrors.Errorf("while unmarshaling: %v", err)
+		dela.Logger.Warn().Err(err).Msg("no signer for the roster")
 	}
 
-	return signer, nil
+	s.followed = true
+	s.current = version
 }
 
 // generator is an implementation to generate a private key.
@@ -9,8 +9,16 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/remote"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -36,6 +44,56 @@ func TestMinimal_OnStart(t *testing.T) {
 
 	err = m.OnStart(flags, inj)
 	require.NoError(t, err)
+
+	var genstore blockstore.GenesisStore
+	require.NoError(t, inj.Resolve(&genstore))
+
+	var proofFac cosipbft.ProofFactory
+	require.NoError(t, inj.Resolve(&proofFac))
+}
+
+func TestMinimal_Tree_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[cosiTreeFlag] = 2
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(db)
+
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+}
+
+func TestMinimal_SetRoster_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	m := NewController().(miniController)
+
+	onet := &fakeRosterMino{}
+
+	inj := node.NewInjector()
+	inj.Inject(onet)
+	inj.Inject(db)
+
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+	require.NotNil(t, onet.roster)
+
+	// The chain is not yet set up, so it has no roster.
+	roster, err := onet.roster()
+	require.NoError(t, err)
+	require.Nil(t, roster)
 }
 
 func TestMinimal_MissingMino_OnStart(t *testing.T) {
@@ -63,6 +121,128 @@ func TestMinimal_FailLoadKey_OnStart(t *testing.T) {
 		fake.Err("signer: while loading: generator failed: failed to marshal signer"))
 }
 
+func TestMinimal_FailLoadBLS12381Key_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[bls12381KeyFlag] = true
+
+	m := NewController().(miniController)
+	m.bls12381SignerFn = badFn
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(fake.NewInMemoryDB())
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err, fake.Err("signer: while loading private.bls12381.key: "+
+		"generator failed: failed to marshal signer"))
+}
+
+func TestMinimal_Encrypted_OnStart(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset["passphrase-env"] = "DELA_TEST_PASSPHRASE"
+	fset[bls12381KeyFlag] = true
+
+	m := NewController().(miniController)
+
+	signer, err := m.getSigner(fset)
+	require.NoError(t, err)
+
+	for _, name := range []string{privateKeyFile, bls12381KeyFile} {
+		data, err := os.ReadFile(filepath.Join(fset.Path("config"), name))
+		require.NoError(t, err)
+		require.True(t, loader.IsEncrypted(data))
+	}
+
+	// The same keys are decrypted when the node restarts.
+	other, err := m.getSigner(fset)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(other.GetPublicKey()))
+
+	delete(fset, "passphrase-env")
+
+	_, err = m.getSigner(fset)
+	require.EqualError(t, err,
+		"while loading: key is encrypted: a passphrase is required")
+
+	fset["passphrase-env"] = "DELA_TEST_PASSPHRASE"
+	fset["passphrase-prompt"] = true
+
+	_, err = m.getSigner(fset)
+	require.EqualError(t, err, "passphrase: only one of --passphrase-env, "+
+		"--passphrase-file and --passphrase-prompt is allowed")
+}
+
+func TestMinimal_BLS12381Key_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+
+	m := NewController().(miniController)
+
+	// The key is not created unless it is requested.
+	signer, err := m.getSigner(fset)
+	require.NoError(t, err)
+	require.Nil(t, signer.Get(bls12381.Algorithm))
+
+	_, err = os.Stat(filepath.Join(dir, bls12381KeyFile))
+	require.True(t, os.IsNotExist(err))
+
+	fset[bls12381KeyFlag] = true
+
+	signer, err = m.getSigner(fset)
+	require.NoError(t, err)
+	require.NotNil(t, signer.Get(bls12381.Algorithm))
+
+	// The key is loaded once it exists.
+	delete(fset, bls12381KeyFlag)
+
+	other, err := m.getSigner(fset)
+	require.NoError(t, err)
+	require.True(t, signer.Get(bls12381.Algorithm).GetPublicKey().
+		Equal(other.Get(bls12381.Algorithm).GetPublicKey()))
+}
+
+func TestMinimal_RemoteSigner_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	key := bls.NewSigner()
+
+	guard, err := remote.NewGuard("")
+	require.NoError(t, err)
+
+	srv := remote.NewServer(key, guard, pbft.NewScopeVerifier())
+	require.NoError(t, srv.Listen(filepath.Join(dir, "signer.sock")))
+
+	defer srv.Close()
+
+	fset := flags.(node.FlagSet)
+	fset[signerSocketFlag] = filepath.Join(dir, "signer.sock")
+
+	m := NewController().(miniController)
+
+	signer, err := m.getSigner(fset)
+	require.NoError(t, err)
+	require.True(t, key.GetPublicKey().Equal(signer.GetPublicKey()))
+
+	// The key of the node is kept by the daemon.
+	_, err = os.Stat(filepath.Join(dir, privateKeyFile))
+	require.True(t, os.IsNotExist(err))
+
+	fset[signerSocketFlag] = filepath.Join(dir, "unknown.sock")
+
+	_, err = m.getSigner(fset)
+	require.Regexp(t, "^remote signer: failed to get public key: ", err)
+}
+
 func TestMinimal_MissingDB_OnStart(t *testing.T) {
 	flags, _, clean := makeFlags(t)
 	defer clean()
@@ -94,6 +274,60 @@ func TestMinimal_MalformedKey_OnStart(t *testing.T) {
 	err = m.OnStart(flags, inj)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "signer: while unmarshaling: ")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, privateKeyFile)))
+
+	file, err = os.Create(filepath.Join(dir, bls12381KeyFile))
+	require.NoError(t, err)
+
+	file.Close()
+
+	err = m.OnStart(flags, inj)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signer: while unmarshaling private.bls12381.key: ")
+}
+
+func TestRosterSigner_Follow(t *testing.T) {
+	signer := &rosterSigner{Signer: makeSigner(bls12381.NewSigner())}
+
+	// The roster is not yet known.
+	require.Equal(t, bls.Algorithm, signer.GetAlgorithm())
+
+	version := uint64(0)
+	getVersion := func() uint64 { return version }
+
+	signer.SetRoster(func() (crypto.CollectiveAuthority, error) {
+		return nil, fake.GetError()
+	}, getVersion)
+	require.Equal(t, bls.Algorithm, signer.GetAlgorithm())
+
+	reads := 0
+	roster := fake.NewAuthority(3, bls12381.Generate)
+
+	signer.SetRoster(func() (crypto.CollectiveAuthority, error) {
+		reads++
+		return roster, nil
+	}, getVersion)
+	require.Equal(t, bls12381.Algorithm, signer.GetAlgorithm())
+	require.IsType(t, bls12381.PublicKey{}, signer.GetPublicKey())
+
+	sig, err := signer.Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+	require.IsType(t, bls12381.Signature{}, sig)
+
+	// The roster is read again only when the version changes.
+	require.Equal(t, 1, reads)
+
+	version = 1
+	roster = fake.NewAuthority(3, bls.Generate)
+	require.Equal(t, bls.Algorithm, signer.GetAlgorithm())
+	require.Equal(t, 2, reads)
+
+	// The algorithm is kept when no signer supports the roster.
+	version = 2
+	roster = fake.NewAuthority(3, fake.NewSigner)
+	require.Equal(t, bls.Algorithm, signer.GetAlgorithm())
+	require.Equal(t, 3, reads)
 }
 
 func TestMinimal_OnStop(t *testing.T) {
@@ -156,6 +390,16 @@ func badFn() encoding.BinaryMarshaler {
 	return fake.NewBadHash()
 }
 
+type fakeRosterMino struct {
+	fake.Mino
+
+	roster func() (crypto.CollectiveAuthority, error)
+}
+
+func (m *fakeRosterMino) SetRoster(roster func() (crypto.CollectiveAuthority, error)) {
+	m.roster = roster
+}
+
 type fakePool struct {
 	pool.Pool
 
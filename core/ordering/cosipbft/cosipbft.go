@@ -37,6 +37,7 @@ import (
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
@@ -44,6 +45,7 @@ import (
 	"go.dedis.ch/dela/core/ordering/cosipbft/blocksync"
 	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
 	"go.dedis.ch/dela/core/ordering/cosipbft/fastsync"
+	"go.dedis.ch/dela/core/ordering/cosipbft/participation"
 	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store"
@@ -56,6 +58,8 @@ import (
 	"go.dedis.ch/dela/cosi/threshold"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde/canonical"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
@@ -107,6 +111,7 @@ type Service struct {
 	actor       cosi.Actor
 	val         validation.Service
 	verifierFac crypto.VerifierFactory
+	signers     *participation.Store
 
 	timeoutRound             time.Duration
 	timeoutRoundAfterFailure time.Duration
@@ -123,6 +128,7 @@ type serviceTemplate struct {
 	blocks     blockstore.BlockStore
 	genesis    blockstore.GenesisStore
 	syncMethod syncMethodType
+	clock      clock.Clock
 }
 
 // ServiceOption is the type of option to set some fields of the service.
@@ -149,6 +155,14 @@ func WithHashFactory(fac crypto.HashFactory) ServiceOption {
 	}
 }
 
+// WithClock is an option to set the clock used for the timeouts of the rounds.
+// It allows a simulation to drive the service with a virtual time.
+func WithClock(c clock.Clock) ServiceOption {
+	return func(tmpl *serviceTemplate) {
+		tmpl.clock = c
+	}
+}
+
 // WithBlockSync enables the old, slow syncing algorithm in the cosipbft module.
 func WithBlockSync() ServiceOption {
 	return func(tmpl *serviceTemplate) {
@@ -183,15 +197,27 @@ func NewService(param ServiceParam, opts ...ServiceOption) (*Service, error) {
 // This is useful for testing purposes.
 func NewServiceStruct(param ServiceParam, opts ...ServiceOption) (*Service, error) {
 	tmpl := serviceTemplate{
-		hashFac: crypto.NewHashFactory(crypto.Sha256),
+		hashFac: types.NewChainHashFactory(),
 		genesis: blockstore.NewGenesisStore(),
 		blocks:  blockstore.NewInMemory(),
+		clock:   clock.NewSystem(),
 	}
 
 	for _, opt := range opts {
 		opt(&tmpl)
 	}
 
+	chainHash, ok := tmpl.hashFac.(*types.ChainHashFactory)
+	if ok && tmpl.genesis.Exists() {
+		genesis, err := tmpl.genesis.Get()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read genesis: %v", err)
+		}
+
+		chainHash.SetAlgorithm(genesis.GetHashAlgorithm())
+		chainHash.SetFingerprintVersion(genesis.GetFingerprintVersion())
+	}
+
 	proc := newProcessor()
 	proc.hashFactory = tmpl.hashFac
 	proc.blocks = tmpl.blocks
@@ -201,8 +227,11 @@ func NewServiceStruct(param ServiceParam, opts ...ServiceOption) (*Service, erro
 		param.Cosi.GetPublicKeyFactory())
 	proc.tree = blockstore.NewTreeCache(param.Tree)
 	proc.access = param.Access
+	proc.clock = tmpl.clock
 	proc.logger = dela.Logger.With().Str("addr", param.Mino.GetAddress().String()).Logger()
 
+	signers := participation.NewStore(param.DB, param.Mino.GetAddressFactory())
+
 	pcparam := pbft.StateMachineParam{
 		Logger:          proc.logger,
 		Validation:      param.Validation,
@@ -213,14 +242,18 @@ func NewServiceStruct(param ServiceParam, opts ...ServiceOption) (*Service, erro
 		Tree:            proc.tree,
 		AuthorityReader: proc.readRoster,
 		DB:              param.DB,
+		Participation:   signers,
+		HashFactory:     tmpl.hashFac,
 	}
 
 	proc.pbftsm = pbft.NewStateMachine(pcparam)
 
-	blockFac := types.NewBlockFactory(param.Validation.GetFactory())
+	hashOpt := types.WithFactoryHash(tmpl.hashFac)
+
+	blockFac := types.NewBlockFactory(param.Validation.GetFactory(), hashOpt)
 	csFac := authority.NewChangeSetFactory(param.Mino.GetAddressFactory(),
 		param.Cosi.GetPublicKeyFactory())
-	linkFac := types.NewLinkFactory(blockFac, param.Cosi.GetSignatureFactory(), csFac)
+	linkFac := types.NewLinkFactory(blockFac, param.Cosi.GetSignatureFactory(), csFac, hashOpt)
 	chainFac := types.NewChainFactory(linkFac)
 
 	syncparam := blocksync.SyncParam{
@@ -231,6 +264,7 @@ func NewServiceStruct(param ServiceParam, opts ...ServiceOption) (*Service, erro
 		LinkFactory:     linkFac,
 		ChainFactory:    chainFac,
 		VerifierFactory: param.Cosi.GetVerifierFactory(),
+		Clock:           tmpl.clock,
 	}
 	if tmpl.syncMethod == syncMethodBlock {
 		proc.bsync = blocksync.NewSynchronizer(syncparam)
@@ -260,6 +294,7 @@ func NewServiceStruct(param ServiceParam, opts ...ServiceOption) (*Service, erro
 		actor:                    actor,
 		val:                      param.Validation,
 		verifierFac:              param.Cosi.GetVerifierFactory(),
+		signers:                  signers,
 		timeoutRound:             DefaultRoundTimeout,
 		timeoutRoundAfterFailure: DefaultFailedRoundTimeout,
 		transactionTimeout:       DefaultTransactionTimeout,
@@ -312,9 +347,54 @@ func (s *Service) SetTimeouts(round, roundAfterFailure, transaction time.Duratio
 	s.transactionTimeout = transaction
 }
 
+// SetupOption is the type of option to set some fields of a new chain.
+type SetupOption func(*setupTemplate)
+
+type setupTemplate struct {
+	hashAlgorithm crypto.HashAlgorithm
+	version       uint32
+}
+
+// WithChainHash is an option to set the hash algorithm of a new chain. It is
+// recorded in the genesis block so that every node computes the digests of
+// the blocks, the tree and the transactions with it. SHA-256 is used by
+// default.
+func WithChainHash(algo crypto.HashAlgorithm) SetupOption {
+	return func(tmpl *setupTemplate) {
+		tmpl.hashAlgorithm = algo
+	}
+}
+
+// WithFormatVersion is an option to set the version of the fingerprints of a
+// new chain. It is recorded in the genesis block so that every node computes
+// the digests with it, whatever the version of the encoding. The version 1 is
+// the one of the nodes of before the canonical encoding, which can then join
+// the chain. The canonical encoding is used by default.
+func WithFormatVersion(version uint32) SetupOption {
+	return func(tmpl *setupTemplate) {
+		tmpl.version = version
+	}
+}
+
 // Setup creates a genesis block and sends it to the collective authority.
-func (s *Service) Setup(ctx context.Context, ca crypto.CollectiveAuthority) error {
-	err := s.storeGenesis(authority.FromAuthority(ca), nil)
+func (s *Service) Setup(ctx context.Context, ca crypto.CollectiveAuthority,
+	opts ...SetupOption) error {
+
+	tmpl := setupTemplate{
+		hashAlgorithm: crypto.Sha256,
+		version:       canonical.Version,
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	if tmpl.version != registry.LegacyVersion && tmpl.version != canonical.Version {
+		return xerrors.Errorf("unsupported format version %d", tmpl.version)
+	}
+
+	err := s.storeGenesis(authority.FromAuthority(ca), tmpl.hashAlgorithm,
+		tmpl.version, nil)
 	if err != nil {
 		return xerrors.Errorf("creating genesis: %v", err)
 	}
@@ -339,6 +419,7 @@ func (s *Service) Setup(ctx context.Context, ca crypto.CollectiveAuthority) erro
 	s.logger.Info().
 		Int("roster", ca.Len()).
 		Stringer("digest", genesis.GetHash()).
+		Stringer("hash", genesis.GetHashAlgorithm()).
 		Msg("new chain has been created")
 
 	return nil
@@ -379,6 +460,12 @@ func (s *Service) GetRoster() (authority.Authority, error) {
 	return s.getCurrentRoster()
 }
 
+// GetParticipation returns the statistics of the members that have signed the
+// blocks of the chain.
+func (s *Service) GetParticipation() ([]participation.Stats, error) {
+	return s.signers.GetStats()
+}
+
 // Watch implements ordering.Service. It returns a channel that will be
 // populated with new incoming blocks and some information about them. The
 // channel must be listened at all time and the context must be closed when
@@ -432,19 +519,26 @@ func (s *Service) watchBlocks() {
 			s.logger.Err(err).Msg("roster refresh failed")
 		}
 
+		// 3. Read the members that signed the block.
+		participants, err := s.signers.GetBlock(link.GetBlock().GetIndex())
+		if err != nil {
+			s.logger.Err(err).Msg("reading participation")
+		}
+
 		event := ordering.Event{
 			Index:        link.GetBlock().GetIndex(),
 			Transactions: link.GetBlock().GetData().GetTransactionResults(),
+			Participants: participants,
 		}
 
-		// 3. Notify the main loop that a new block has been created, but ignore
+		// 4. Notify the main loop that a new block has been created, but ignore
 		// if the channel is busy.
 		select {
 		case s.events <- event:
 		default:
 		}
 
-		// 4. Notify the new block to potential listeners.
+		// 5. Notify the new block to potential listeners.
 		s.watcher.Notify(event)
 
 		s.logger.Info().
@@ -495,7 +589,7 @@ func (s *Service) main() error {
 	for {
 		// When a round failure occurs, it sleeps with a given backoff to give a
 		// chance to the system to recover without exhausting the resources.
-		time.Sleep(calculateBackoff(backoff))
+		s.clock.Sleep(calculateBackoff(backoff))
 
 		select {
 		case <-s.closing:
@@ -560,7 +654,7 @@ func (s *Service) doLeaderRound(
 	roster authority.Authority,
 	timeout time.Duration,
 ) error {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx, cancel := s.clock.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	s.logger.Debug().Uint64("index", s.blocks.Len()).Msg("round has started")
@@ -594,7 +688,7 @@ func (s *Service) doLeaderRound(
 func (s *Service) doFollowerRound(ctx context.Context, roster authority.Authority) error {
 	// A follower has to wait for the new block, or the round timeout, to proceed.
 	select {
-	case <-time.After(s.timeoutRound):
+	case <-s.clock.After(s.timeoutRound):
 		if !s.roundHasFailed() {
 			return nil
 		}
@@ -610,7 +704,7 @@ func (s *Service) doFollowerRound(ctx context.Context, roster authority.Authorit
 
 		viewMsg := types.NewViewMessage(view.GetID(), view.GetLeader(), view.GetSignature())
 
-		ctx, cancel := context.WithTimeout(ctx, s.timeoutRound)
+		ctx, cancel := s.clock.WithTimeout(ctx, s.timeoutRound)
 		defer cancel()
 
 		resps, err := s.rpc.Call(ctx, viewMsg, roster)
@@ -664,7 +758,7 @@ func (s *Service) roundHasFailed() bool {
 		return false
 	}
 
-	if time.Since(stats.OldestTx) > s.transactionTimeout {
+	if s.clock.Now().Sub(stats.OldestTx) > s.transactionTimeout {
 		s.logger.Warn().Msg("found a rotten transaction")
 		s.failedRound = true
 	}
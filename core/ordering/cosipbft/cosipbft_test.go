@@ -35,11 +35,16 @@ import (
 	"go.dedis.ch/dela/cosi/threshold"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/crypto/remote"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
 	"go.dedis.ch/dela/mino/minoch"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/dela/serde/registry"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -50,6 +55,66 @@ func TestService_Scenario_Basic_Fastsync(t *testing.T) {
 	testserviceScenarioBasic(t, syncMethodFast)
 }
 
+// The nodes pinned to the version 1 encode the messages as the nodes of before
+// the canonical encoding, and the other nodes with the latest version. They
+// agree on the digests because the fingerprints follow the version of the
+// chain and not the version of the encoding.
+func TestService_Scenario_MixedVersions(t *testing.T) {
+	for _, version := range []uint32{registry.LegacyVersion, canonical.Version} {
+		t.Run(fmt.Sprintf("chain v%d", version), func(t *testing.T) {
+			testServiceMixedVersions(t, version)
+		})
+	}
+}
+
+func testServiceMixedVersions(t *testing.T, version uint32) {
+	versions := []uint32{registry.LegacyVersion, registry.LegacyVersion, 0, 0}
+
+	nodes, ro, clean := makeAuthorityVersions(t, versions, 10, makeSigner)
+	defer clean()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[2].service.Setup(ctx, ro, WithFormatVersion(version))
+	require.NoError(t, err)
+
+	watchers := make([]<-chan ordering.Event, len(nodes))
+	for i, node := range nodes {
+		watchers[i] = node.service.Watch(ctx)
+	}
+
+	// The transactions are sent alternately by a node of each version.
+	for i := 0; i < 4; i++ {
+		node := nodes[(i%2)*2]
+
+		tx := makeTx(t, uint64(i), nodes[0].signer, signed.WithHashFactory(node.hashFac))
+
+		err = node.pool.Add(tx)
+		require.NoError(t, err)
+
+		for _, events := range watchers {
+			evt := waitEvent(t, events, 10*DefaultRoundTimeout)
+			require.Equal(t, uint64(i), evt.Index)
+		}
+	}
+
+	expected, err := nodes[2].service.blocks.Last()
+	require.NoError(t, err)
+
+	for _, node := range nodes {
+		genesis, err := node.service.genesis.Get()
+		require.NoError(t, err)
+		require.Equal(t, version, genesis.GetFingerprintVersion())
+		require.Equal(t, version, node.hashFac.GetFingerprintVersion())
+
+		last, err := node.service.blocks.Last()
+		require.NoError(t, err)
+		require.Equal(t, expected.GetHash(), last.GetHash())
+		require.Equal(t, expected.GetBlock().GetHash(), last.GetBlock().GetHash())
+	}
+}
+
 // This test is known to be VERY flaky on Windows.
 // Further investigation is needed.
 func testserviceScenarioBasic(t *testing.T, sm syncMethodType) {
@@ -81,6 +146,7 @@ func testserviceScenarioBasic(t *testing.T, sm syncMethodType) {
 
 	evt := waitEvent(t, events, 3*DefaultRoundTimeout)
 	require.Equal(t, uint64(0), evt.Index)
+	require.Len(t, evt.Participants, 4)
 
 	err = nodes[0].pool.Add(makeTx(t, 1, signer))
 	require.NoError(t, err)
@@ -100,6 +166,7 @@ func testserviceScenarioBasic(t *testing.T, sm syncMethodType) {
 	evt4 := nodes[4].service.Watch(ctx)
 	evt = waitEvent(t, events, 10*DefaultRoundTimeout)
 	require.Equal(t, uint64(3), evt.Index)
+	require.Len(t, evt.Participants, 5)
 
 	// Waiting for node4 to catch up all blocks
 	for i := 0; i < 4; i++ {
@@ -123,6 +190,189 @@ func testserviceScenarioBasic(t *testing.T, sm syncMethodType) {
 	require.NotNil(t, proof.GetValue())
 
 	checkProof(t, proof.(Proof), nodes[0].service)
+
+	stats, err := nodes[0].service.GetParticipation()
+	require.NoError(t, err)
+	require.Len(t, stats, 5)
+
+	for _, st := range stats {
+		require.NotZero(t, st.Blocks)
+		require.LessOrEqual(t, st.Commits, st.Blocks)
+	}
+}
+
+func TestService_Scenario_RemoteSigner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping flaky test")
+	}
+
+	// Every node signs with a signer daemon that verifies the scopes of the
+	// messages, and refuses to sign two different blocks at the same index and
+	// view.
+	remoteSigner := func(t *testing.T) crypto.AggregateSigner {
+		guard, err := remote.NewGuard("")
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "signer.sock")
+
+		srv := remote.NewServer(bls.NewSigner(), guard, pbft.NewScopeVerifier(),
+			remote.WithUnscoped())
+		require.NoError(t, srv.Listen(path))
+
+		t.Cleanup(func() { srv.Close() })
+
+		signer, err := remote.NewSigner(path, bls.NewSigner())
+		require.NoError(t, err)
+
+		return common.NewSigner(bls.Algorithm, signer)
+	}
+
+	nodes, ro, clean := makeAuthoritySigners(t, 4, 10, remoteSigner)
+	defer clean()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := nodes[0].service.Watch(ctx)
+
+	// The hash of a transaction can't be told apart from the digest of a
+	// block, therefore the daemon refuses it even without a scope, and the
+	// transactions are signed with another key.
+	tx, err := signed.NewTransaction(0, nodes[0].signer.GetPublicKey())
+	require.NoError(t, err)
+
+	err = tx.Sign(nodes[0].signer.(crypto.UnscopedSigner).Unscoped())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "requires a scope")
+
+	signer := bls.NewSigner()
+
+	for i := 0; i < 3; i++ {
+		err = nodes[0].pool.Add(makeTx(t, uint64(i), signer))
+		require.NoError(t, err)
+
+		evt := waitEvent(t, events, 10*DefaultRoundTimeout)
+		require.Equal(t, uint64(i), evt.Index)
+	}
+}
+
+func TestService_Scenario_ChainHash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping flaky test")
+	}
+
+	nodes, ro, clean := makeAuthority(t, 4)
+	defer clean()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro, WithChainHash(crypto.Blake3_256))
+	require.NoError(t, err)
+
+	events := nodes[0].service.Watch(ctx)
+
+	// The transaction IDs must be computed with the algorithm of the chain
+	// for the signatures to verify.
+	hashFac := crypto.NewHashFactory(crypto.Blake3_256)
+
+	for i := 0; i < 3; i++ {
+		tx, err := signed.NewTransaction(uint64(i), nodes[0].signer.GetPublicKey(),
+			signed.WithArg(native.ContractArg, []byte(testContractName)),
+			signed.WithHashFactory(hashFac))
+		require.NoError(t, err)
+		require.NoError(t, tx.Sign(nodes[0].signer))
+
+		err = nodes[0].pool.Add(tx)
+		require.NoError(t, err)
+
+		evt := waitEvent(t, events, 10*DefaultRoundTimeout)
+		require.Equal(t, uint64(i), evt.Index)
+	}
+
+	for _, node := range nodes {
+		genesis, err := node.service.genesis.Get()
+		require.NoError(t, err)
+		require.Equal(t, crypto.Blake3_256, genesis.GetHashAlgorithm())
+	}
+}
+
+func TestService_Scenario_Migration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping flaky test")
+	}
+
+	nodes, ro, clean := makeAuthority(t, 4)
+	defer clean()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := make([]<-chan ordering.Event, len(nodes))
+	for i, node := range nodes {
+		events[i] = node.service.Watch(ctx)
+	}
+
+	signer := bls.NewSigner()
+
+	err = nodes[0].pool.Add(makeTx(t, 0, signer))
+	require.NoError(t, err)
+
+	for _, ch := range events {
+		waitEvent(t, ch, 10*DefaultRoundTimeout)
+	}
+
+	// Every member swaps its key for a BLS12-381 one with a transaction signed
+	// by its current key. The roster migrates with the last swap.
+	for i, node := range nodes {
+		roster, err := node.service.GetRoster()
+		require.NoError(t, err)
+
+		other := node.signer.(*common.Signer).Get(bls12381.Algorithm)
+
+		mgr := signed.NewManager(node.signer, fake.NewClient())
+		tx, err := viewchange.NewManager(mgr).MakeSwap(roster, node.onet.GetAddress(), other)
+		require.NoError(t, err)
+
+		err = node.pool.Add(tx)
+		require.NoError(t, err)
+
+		for _, ch := range events {
+			evt := waitEvent(t, ch, 10*DefaultRoundTimeout)
+			require.Equal(t, uint64(i+1), evt.Index)
+
+			accepted, msg := evt.Transactions[0].GetStatus()
+			require.True(t, accepted, msg)
+		}
+	}
+
+	for _, node := range nodes {
+		roster, err := node.service.GetRoster()
+		require.NoError(t, err)
+
+		_, err = node.signer.(*common.Signer).ActivateFor(roster)
+		require.NoError(t, err)
+	}
+
+	// The next block is signed by the new keys.
+	err = nodes[0].pool.Add(makeTx(t, 1, signer))
+	require.NoError(t, err)
+
+	evt := waitEvent(t, events[0], 10*DefaultRoundTimeout)
+	require.Equal(t, uint64(len(nodes)+1), evt.Index)
+
+	// The chain can be verified from the genesis roster by following the
+	// change sets of the links.
+	proof, err := nodes[0].service.GetProof(viewchange.GetRosterKey())
+	require.NoError(t, err)
+
+	checkProof(t, proof.(Proof), nodes[0].service)
 }
 
 func TestService_Scenario_ViewChange_Basic(t *testing.T) {
@@ -328,6 +578,34 @@ func TestService_Setup(t *testing.T) {
 	genesis, err := srvc.genesis.Get()
 	require.NoError(t, err)
 	require.Equal(t, 3, genesis.GetRoster().Len())
+	require.Equal(t, canonical.Version, genesis.GetFingerprintVersion())
+
+	err = srvc.Setup(ctx, a, WithFormatVersion(3))
+	require.EqualError(t, err, "unsupported format version 3")
+}
+
+func TestService_FormatVersion_Setup(t *testing.T) {
+	rpc := fake.NewRPC()
+
+	chainHash := types.NewChainHashFactory()
+
+	srvc := &Service{processor: newProcessor()}
+	srvc.rpc = rpc
+	srvc.hashFactory = chainHash
+	srvc.tree = blockstore.NewTreeCache(fakeTree{})
+	srvc.genesis = blockstore.NewGenesisStore()
+	srvc.access = fakeAccess{}
+
+	rpc.Done()
+
+	err := srvc.Setup(context.Background(), fake.NewAuthority(3, fake.NewSigner),
+		WithFormatVersion(registry.LegacyVersion))
+	require.NoError(t, err)
+
+	genesis, err := srvc.genesis.Get()
+	require.NoError(t, err)
+	require.Equal(t, registry.LegacyVersion, genesis.GetFingerprintVersion())
+	require.Equal(t, registry.LegacyVersion, chainHash.GetFingerprintVersion())
 }
 
 func TestService_AlreadySet_Setup(t *testing.T) {
@@ -747,7 +1025,8 @@ func TestService_FailCreateBlock_DoPBFT(t *testing.T) {
 
 	err := srvc.doPBFT(ctx)
 	require.EqualError(t, err,
-		fake.Err("creating block failed: fingerprint failed: couldn't write index"))
+		fake.Err("creating block failed: fingerprint failed: couldn't write block: "+
+			"failed to write"))
 }
 
 func TestService_FailPrepare_DoPBFT(t *testing.T) {
@@ -972,6 +1251,7 @@ type testNode struct {
 	db      kv.DB
 	dbpath  string
 	signer  crypto.Signer
+	hashFac *types.ChainHashFactory
 }
 
 const testContractName = "abc"
@@ -988,10 +1268,10 @@ func (e testExec) UID() string {
 	return "TEST"
 }
 
-func makeTx(t *testing.T, nonce uint64, signer crypto.Signer) txn.Transaction {
-	opts := []signed.TransactionOption{
-		signed.WithArg(native.ContractArg, []byte(testContractName)),
-	}
+func makeTx(t *testing.T, nonce uint64, signer crypto.Signer,
+	opts ...signed.TransactionOption) txn.Transaction {
+
+	opts = append(opts, signed.WithArg(native.ContractArg, []byte(testContractName)))
 
 	tx, err := signed.NewTransaction(nonce, signer.GetPublicKey(), opts...)
 	require.NoError(t, err)
@@ -1046,19 +1326,51 @@ func makeAuthorityTimeout(t *testing.T, n int, mult int, opts ...ServiceOption)
 	[]testNode,
 	authority.Authority,
 	func(),
+) {
+	return makeAuthoritySigners(t, n, mult, makeSigner, opts...)
+}
+
+// makeSigner returns a signer that holds a key of each algorithm so that the
+// roster can migrate from one to the other.
+func makeSigner(t *testing.T) crypto.AggregateSigner {
+	signer := common.NewSigner(bls.Algorithm, bls.NewSigner())
+	signer.Add(bls12381.Algorithm, bls12381.NewSigner())
+
+	return signer
+}
+
+func makeAuthoritySigners(t *testing.T, n int, mult int,
+	signerFn func(*testing.T) crypto.AggregateSigner, opts ...ServiceOption) (
+	[]testNode,
+	authority.Authority,
+	func(),
+) {
+	return makeAuthorityVersions(t, make([]uint32, n), mult, signerFn, opts...)
+}
+
+// makeAuthorityVersions creates a node for each version pinned to encode the
+// messages, which is the latest one when it is zero.
+func makeAuthorityVersions(t *testing.T, versions []uint32, mult int,
+	signerFn func(*testing.T) crypto.AggregateSigner, opts ...ServiceOption) (
+	[]testNode,
+	authority.Authority,
+	func(),
 ) {
 	manager := minoch.NewManager()
 
+	n := len(versions)
 	addrs := make([]mino.Address, n)
 	pubkeys := make([]crypto.PublicKey, n)
 	nodes := make([]testNode, n)
 
 	for i := 0; i < n; i++ {
-		m := minoch.MustCreate(manager, fmt.Sprintf("node%d", i))
+		ctx := registry.WithVersion(json.NewContext(), versions[i])
+
+		m := minoch.MustCreate(manager, fmt.Sprintf("node%d", i), minoch.WithContext(ctx))
 
 		addrs[i] = m.GetAddress()
 
-		signer := bls.NewSigner()
+		signer := signerFn(t)
 		pubkeys[i] = signer.GetPublicKey()
 
 		c := threshold.NewThreshold(m, signer)
@@ -1070,12 +1382,16 @@ func makeAuthorityTimeout(t *testing.T, n int, mult int, opts ...ServiceOption)
 		db, err := kv.New(filepath.Join(dir, "test.db"))
 		require.NoError(t, err)
 
-		txFac := signed.NewTransactionFactory()
+		// The hash algorithm of the chain is shared by the components of the
+		// node, as it is only known after the genesis is created.
+		hashFac := types.NewChainHashFactory()
+
+		txFac := signed.NewTransactionFactory(signed.WithFactoryHash(hashFac))
 
 		p, err := poolgossip.NewPool(gossip.NewFlat(m, txFac))
 		require.NoError(t, err)
 
-		tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+		tree := binprefix.NewMerkleTree(db, binprefix.Nonce{}, binprefix.WithHashFactory(hashFac))
 
 		exec := native.NewExecution()
 		exec.Set(testContractName, testExec{})
@@ -1097,7 +1413,7 @@ func makeAuthorityTimeout(t *testing.T, n int, mult int, opts ...ServiceOption)
 			DB:         db,
 		}
 
-		srv, err := NewServiceStruct(param, opts...)
+		srv, err := NewServiceStruct(param, append([]ServiceOption{WithHashFactory(hashFac)}, opts...)...)
 		require.NoError(t, err)
 		mTime := time.Millisecond * time.Duration(mult)
 		srv.SetTimeouts(300*mTime, 500*mTime, 700*mTime)
@@ -1110,6 +1426,7 @@ func makeAuthorityTimeout(t *testing.T, n int, mult int, opts ...ServiceOption)
 			db:      db,
 			dbpath:  dir,
 			signer:  c.GetSigner(),
+			hashFac: hashFac,
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blocksync"
 	"go.dedis.ch/dela/core/ordering/cosipbft/fastsync/types"
@@ -32,6 +33,7 @@ type fastSync struct {
 	rpc    mino.RPC
 	pbftsm pbft.StateMachine
 	blocks blockstore.BlockStore
+	clock  clock.Clock
 
 	Mino mino.Mino
 
@@ -48,6 +50,11 @@ func NewSynchronizer(param blocksync.SyncParam) Synchronizer {
 
 	logger := dela.Logger.With().Str("addr", param.Mino.GetAddress().String()).Logger()
 
+	clk := param.Clock
+	if clk == nil {
+		clk = clock.NewSystem()
+	}
+
 	h := &handler{
 		latest:      &latest,
 		catchUpLock: new(sync.Mutex),
@@ -56,6 +63,7 @@ func NewSynchronizer(param blocksync.SyncParam) Synchronizer {
 		blocks:      param.Blocks,
 		pbftsm:      param.PBFT,
 		verifierFac: param.VerifierFactory,
+		clock:       clk,
 	}
 
 	fac := types.NewMessageFactory(param.LinkFactory)
@@ -65,6 +73,7 @@ func NewSynchronizer(param blocksync.SyncParam) Synchronizer {
 		rpc:         mino.MustCreateRPC(param.Mino, "fastsync", h, fac),
 		pbftsm:      param.PBFT,
 		blocks:      param.Blocks,
+		clock:       clk,
 		latest:      &latest,
 		catchUpLock: h.catchUpLock,
 		Mino:        param.Mino,
@@ -81,7 +90,7 @@ func (s fastSync) Sync(ctx context.Context, players mino.Players, config Config)
 		return xerrors.Errorf("need at least 1 node to contact")
 	}
 	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolName)
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(timeoutSync))
+	ctx, cancel := s.clock.WithTimeout(ctx, timeoutSync)
 	defer cancel()
 
 	// Make sure that the address of this node is at the beginning of the list.
@@ -220,14 +229,14 @@ type handler struct {
 	genesis     blockstore.GenesisStore
 	pbftsm      pbft.StateMachine
 	verifierFac crypto.VerifierFactory
+	clock       clock.Clock
 }
 
 // Stream implements mino.Handler. It waits for a request message and then
 // replies with eventually missing BlockLinks of the requester.
 func (h *handler) Stream(out mino.Sender, in mino.Receiver) error {
 	h.logger.Debug().Msg("Starting stream")
-	ctx := context.Background()
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(timeoutSync))
+	ctx, cancel := h.clock.WithTimeout(context.Background(), timeoutSync)
 	defer cancel()
 
 	for sentAllBlocks := false; !sentAllBlocks; {
@@ -61,10 +61,10 @@ func TestDefaultSync_SplitMessage(t *testing.T) {
 	}{
 		{0, 1},
 		{1, 10},
-		{255, 10},
-		{256, 5},
-		{1024, 2},
-		{2550, 1},
+		{344, 10},
+		{345, 5},
+		{1380, 2},
+		{3440, 1},
 	}
 	for _, test := range tests {
 		syncs, genesis, roster := makeNodes(t, 2)
@@ -11,6 +11,7 @@ import (
 
 func init() {
 	types.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+	types.RegisterMessageFormat(serde.FormatCBOR, msgFormat{})
 }
 
 // RequestCatchupMessageJSON is the JSON representation of a request catchup
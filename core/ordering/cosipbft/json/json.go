@@ -9,21 +9,45 @@ import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
+// The genesis, the blocks and the links are stored by the nodes, therefore
+// their formats are versioned so that the data of the older versions can still
+// be decoded after an upgrade. The version 2 introduces the canonical
+// fingerprints, and the messages of the version 1 keep the legacy ones.
+var (
+	genesisVersions = registry.NewVersionedFormat("cosipbft.Genesis")
+	blockVersions   = registry.NewVersionedFormat("cosipbft.Block")
+	linkVersions    = registry.NewVersionedFormat("cosipbft.Link")
+)
+
 func init() {
-	types.RegisterGenesisFormat(serde.FormatJSON, genesisFormat{})
+	genesisVersions.Register(1, genesisFormat{legacy: true})
+	genesisVersions.Register(2, genesisFormat{})
+	blockVersions.Register(1, blockFormat{legacy: true})
+	blockVersions.Register(2, blockFormat{})
+	linkVersions.Register(1, linkFormat{legacy: true})
+	linkVersions.Register(2, linkFormat{})
+
+	types.RegisterGenesisFormat(serde.FormatJSON, genesisVersions)
 	types.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
-	types.RegisterBlockFormat(serde.FormatJSON, blockFormat{})
-	types.RegisterLinkFormat(serde.FormatJSON, linkFormat{})
+	types.RegisterBlockFormat(serde.FormatJSON, blockVersions)
+	types.RegisterLinkFormat(serde.FormatJSON, linkVersions)
 	types.RegisterChainFormat(serde.FormatJSON, chainFormat{})
+	types.RegisterGenesisFormat(serde.FormatCBOR, genesisVersions)
+	types.RegisterMessageFormat(serde.FormatCBOR, msgFormat{})
+	types.RegisterBlockFormat(serde.FormatCBOR, blockVersions)
+	types.RegisterLinkFormat(serde.FormatCBOR, linkVersions)
+	types.RegisterChainFormat(serde.FormatCBOR, chainFormat{})
 }
 
 // GenesisJSON is the JSON message for a genesis block.
 type GenesisJSON struct {
-	Roster   json.RawMessage
-	TreeRoot []byte
+	Roster        json.RawMessage
+	TreeRoot      []byte
+	HashAlgorithm string `json:",omitempty"`
 }
 
 // BlockJSON is the JSON message for a block.
@@ -93,6 +117,7 @@ type MessageJSON struct {
 // - implements serde.FormatEngine
 type genesisFormat struct {
 	hashFac crypto.HashFactory
+	legacy  bool
 }
 
 // Encode implements serde.FormatEngine. It returns the serialized data of the
@@ -113,6 +138,12 @@ func (f genesisFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, err
 		TreeRoot: genesis.GetRoot().Bytes(),
 	}
 
+	// The default algorithm is omitted to keep the format of the existing
+	// chains.
+	if genesis.GetHashAlgorithm() != crypto.Sha256 {
+		m.HashAlgorithm = genesis.GetHashAlgorithm().String()
+	}
+
 	data, err := ctx.Marshal(m)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to marshal: %v", err)
@@ -147,10 +178,23 @@ func (f genesisFormat) Decode(ctx serde.Context, data []byte) (serde.Message, er
 
 	opts := []types.GenesisOption{types.WithGenesisRoot(root)}
 
+	if m.HashAlgorithm != "" {
+		algo, err := crypto.ParseHashAlgorithm(m.HashAlgorithm)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid hash algorithm: %v", err)
+		}
+
+		opts = append(opts, types.WithGenesisHashAlgorithm(algo))
+	}
+
 	if f.hashFac != nil {
 		opts = append(opts, types.WithGenesisHashFactory(f.hashFac))
 	}
 
+	if f.legacy {
+		opts = append(opts, types.WithGenesisLegacyFingerprint())
+	}
+
 	genesis, err := types.NewGenesis(roster, opts...)
 	if err != nil {
 		return nil, xerrors.Errorf("creating genesis: %v", err)
@@ -164,6 +208,7 @@ func (f genesisFormat) Decode(ctx serde.Context, data []byte) (serde.Message, er
 // - implements serde.FormatEngine
 type blockFormat struct {
 	hashFac crypto.HashFactory
+	legacy  bool
 }
 
 // Encode implements serde.FormatEngine. It returns the serialized data of the
@@ -222,8 +267,17 @@ func (f blockFormat) Decode(ctx serde.Context, data []byte) (serde.Message, erro
 		types.WithIndex(m.Index),
 	}
 
-	if f.hashFac != nil {
-		opts = append(opts, types.WithHashFactory(f.hashFac))
+	hashFac := f.hashFac
+	if hashFac == nil {
+		hashFac = crypto.HashFactoryOf(ctx)
+	}
+
+	if hashFac != nil {
+		opts = append(opts, types.WithHashFactory(hashFac))
+	}
+
+	if f.legacy {
+		opts = append(opts, types.WithLegacyFingerprint())
 	}
 
 	block, err := types.NewBlock(blockdata, opts...)
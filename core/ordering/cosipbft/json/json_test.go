@@ -7,8 +7,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -40,6 +42,14 @@ func TestGenesisFormat_Encode(t *testing.T) {
 
 	_, err = format.Encode(ctx, genesis)
 	require.EqualError(t, err, fake.Err("failed to serialize roster"))
+
+	genesis, err = types.NewGenesis(fakeRoster{},
+		types.WithGenesisHashAlgorithm(crypto.Blake3_256))
+	require.NoError(t, err)
+
+	data, err = format.Encode(ctx, genesis)
+	require.NoError(t, err)
+	require.Regexp(t, `"HashAlgorithm":"blake3-256"`, string(data))
 }
 
 func TestGenesisFormat_Decode(t *testing.T) {
@@ -54,6 +64,14 @@ func TestGenesisFormat_Decode(t *testing.T) {
 	msg, err := format.Decode(ctx, []byte(`{}`))
 	require.NoError(t, err)
 	require.NotNil(t, msg, genesis)
+	require.Equal(t, canonical.Version, msg.(types.Genesis).GetFormatVersion())
+
+	format.legacy = true
+	msg, err = format.Decode(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), msg.(types.Genesis).GetFormatVersion())
+
+	format.legacy = false
 
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal"))
@@ -70,6 +88,14 @@ func TestGenesisFormat_Decode(t *testing.T) {
 	_, err = format.Decode(ctx, []byte(`{}`))
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "creating genesis: fingerprint failed: ")
+
+	format.hashFac = nil
+	msg, err = format.Decode(ctx, []byte(`{"HashAlgorithm":"sha3-256"}`))
+	require.NoError(t, err)
+	require.Equal(t, crypto.Sha3_256, msg.(types.Genesis).GetHashAlgorithm())
+
+	_, err = format.Decode(ctx, []byte(`{"HashAlgorithm":"md5"}`))
+	require.EqualError(t, err, "invalid hash algorithm: unknown hash algorithm 'md5'")
 }
 
 func TestBlockFormat_Encode(t *testing.T) {
@@ -110,6 +136,13 @@ func TestBlockFormat_Decode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, block, msg)
 
+	format.legacy = true
+	msg, err = format.Decode(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), msg.(types.Block).GetFormatVersion())
+
+	format.legacy = false
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal"))
 
@@ -125,6 +158,16 @@ func TestBlockFormat_Decode(t *testing.T) {
 	_, err = format.Decode(ctx, []byte(`{}`))
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "creating block: fingerprint failed: ")
+
+	block, err = types.NewBlock(fakeResult{},
+		types.WithHashFactory(crypto.NewHashFactory(crypto.Blake2b256)))
+	require.NoError(t, err)
+
+	format.hashFac = nil
+	hashCtx := crypto.WithHashFactory(ctx, crypto.NewHashFactory(crypto.Blake2b256))
+	msg, err = format.Decode(hashCtx, []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, block.GetHash(), msg.(types.Block).GetHash())
 }
 
 func TestMsgFormat_Encode(t *testing.T) {
@@ -290,6 +333,14 @@ func (fakeRoster) Fingerprint(io.Writer) error {
 	return nil
 }
 
+func (fakeRoster) AddressIterator() mino.AddressIterator {
+	return authority.New(nil, nil).AddressIterator()
+}
+
+func (fakeRoster) PublicKeyIterator() crypto.PublicKeyIterator {
+	return authority.New(nil, nil).PublicKeyIterator()
+}
+
 type fakeRosterFac struct {
 	authority.Factory
 
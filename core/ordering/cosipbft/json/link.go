@@ -13,6 +13,7 @@ import (
 // - implements serde.FormatEngine
 type linkFormat struct {
 	hashFac crypto.HashFactory
+	legacy  bool
 }
 
 // Encode implements serde.FormatEngine. It serializes the link or the block
@@ -110,8 +111,17 @@ func (fmt linkFormat) Decode(ctx serde.Context, data []byte) (serde.Message, err
 		types.WithChangeSet(changeset),
 	}
 
-	if fmt.hashFac != nil {
-		opts = append(opts, types.WithLinkHashFactory(fmt.hashFac))
+	hashFac := fmt.hashFac
+	if hashFac == nil {
+		hashFac = crypto.HashFactoryOf(ctx)
+	}
+
+	if hashFac != nil {
+		opts = append(opts, types.WithLinkHashFactory(hashFac))
+	}
+
+	if fmt.legacy {
+		opts = append(opts, types.WithLinkLegacyFingerprint())
 	}
 
 	if len(m.Block) > 0 {
@@ -8,6 +8,7 @@ import (
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/testing/fake"
 )
@@ -72,6 +73,13 @@ func TestLinkFormat_Decode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, makeBlockLink(t), msg)
 
+	format.legacy = true
+	msg, err = format.Decode(ctx, []byte(`{"From":[1],"To":[2]}`))
+	require.NoError(t, err)
+	require.Equal(t, makeLink(t, types.WithLinkLegacyFingerprint()), msg)
+
+	format.legacy = false
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal"))
 
@@ -112,6 +120,12 @@ func TestLinkFormat_Decode(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(),
 		"creating block link: creating forward link: failed to fingerprint: ")
+
+	format.hashFac = nil
+	hashFac := crypto.NewHashFactory(crypto.Sha3_256)
+	msg, err = format.Decode(crypto.WithHashFactory(ctx, hashFac), []byte(`{"From":[1],"To":[2]}`))
+	require.NoError(t, err)
+	require.Equal(t, makeLink(t, types.WithLinkHashFactory(hashFac)), msg)
 }
 
 // -----------------------------------------------------------------------------
@@ -178,6 +192,10 @@ func (fakeResult) Fingerprint(io.Writer) error {
 	return nil
 }
 
+func (fakeResult) GetTransactionResults() []validation.TransactionResult {
+	return nil
+}
+
 type fakeResultFac struct {
 	validation.ResultFactory
 
@@ -0,0 +1,325 @@
+// Package participation records which members of the roster have signed the
+// blocks of the chain.
+//
+// The collective signatures of the prepare and commit phases hold a mask of
+// the members that contributed to them. The store reads it for every block and
+// keeps statistics per member so that an operator can notice a member that is
+// silently offline before the threshold of the consensus is at risk.
+package participation
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+const (
+	phasePrepare = "prepare"
+	phaseCommit  = "commit"
+)
+
+var (
+	blocksBucket = []byte("participation-blocks")
+	statsBucket  = []byte("participation-stats")
+)
+
+// defines prometheus metrics
+var (
+	promSignatures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_cosipbft_participation_signatures_total",
+		Help: "number of blocks signed by a member for a phase",
+	}, []string{"address", "phase"})
+
+	promMissed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dela_cosipbft_participation_missed",
+		Help: "number of consecutive blocks a member did not sign",
+	}, []string{"address"})
+)
+
+func init() {
+	dela.PromCollectors = append(dela.PromCollectors, promSignatures, promMissed)
+}
+
+// maskedSignature is implemented by the collective signatures that know which
+// members have signed, like the threshold signature.
+type maskedSignature interface {
+	HasBit(index int) bool
+}
+
+// Stats are the participation statistics of a member of the roster.
+type Stats struct {
+	Address mino.Address
+
+	// Blocks is the number of blocks created while being a member.
+	Blocks uint64
+
+	// Prepares is the number of prepare signatures of the member.
+	Prepares uint64
+
+	// Commits is the number of commit signatures of the member.
+	Commits uint64
+
+	// LastCommit is the index of the latest block the member has signed, which
+	// is only relevant if Commits is not zero.
+	LastCommit uint64
+
+	// Missed is the number of consecutive blocks the member has not signed.
+	Missed uint64
+}
+
+type memberJSON struct {
+	Address   []byte
+	Prepared  bool
+	Committed bool
+}
+
+type statsJSON struct {
+	Blocks     uint64
+	Prepares   uint64
+	Commits    uint64
+	LastCommit uint64
+	Missed     uint64
+}
+
+// Store persists the participation of the members to the blocks in a key/value
+// database.
+type Store struct {
+	db  kv.DB
+	fac mino.AddressFactory
+}
+
+// NewStore creates a new store using the database. The factory decodes the
+// addresses of the members.
+func NewStore(db kv.DB, fac mino.AddressFactory) *Store {
+	return &Store{
+		db:  db,
+		fac: fac,
+	}
+}
+
+// Record reads the participation of the roster members from the prepare and
+// commit signatures of the block, and persists it with the updated statistics
+// through the transaction. A signature without a mask means that every member
+// has signed.
+func (s *Store) Record(txn kv.WritableTx, index uint64, roster mino.Players,
+	prepare, commit crypto.Signature) ([]ordering.Participant, error) {
+
+	blocks, err := txn.GetBucketOrCreate(blocksBucket)
+	if err != nil {
+		return nil, xerrors.Errorf("bucket failed: %v", err)
+	}
+
+	stats, err := txn.GetBucketOrCreate(statsBucket)
+	if err != nil {
+		return nil, xerrors.Errorf("bucket failed: %v", err)
+	}
+
+	participants := make([]ordering.Participant, 0, roster.Len())
+	members := make([]memberJSON, 0, roster.Len())
+	missed := make([]uint64, 0, roster.Len())
+
+	iter := roster.AddressIterator()
+	for i := 0; iter.HasNext(); i++ {
+		addr := iter.GetNext()
+
+		key, err := addr.MarshalText()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal address: %v", err)
+		}
+
+		p := ordering.Participant{
+			Address:   addr,
+			Prepared:  hasSigned(prepare, i),
+			Committed: hasSigned(commit, i),
+		}
+
+		st, err := updateStats(stats, key, index, p)
+		if err != nil {
+			return nil, xerrors.Errorf("stats of '%v': %v", addr, err)
+		}
+
+		participants = append(participants, p)
+		missed = append(missed, st.Missed)
+		members = append(members, memberJSON{
+			Address:   key,
+			Prepared:  p.Prepared,
+			Committed: p.Committed,
+		})
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	err = blocks.Set(makeKey(index), data)
+	if err != nil {
+		return nil, xerrors.Errorf("while writing: %v", err)
+	}
+
+	txn.OnCommit(func() {
+		for i, p := range participants {
+			observe(p, missed[i])
+		}
+	})
+
+	return participants, nil
+}
+
+// GetBlock returns the participation of the members to the block at the index,
+// or nil if it has not been recorded.
+func (s *Store) GetBlock(index uint64) ([]ordering.Participant, error) {
+	var participants []ordering.Participant
+
+	err := s.db.View(func(txn kv.ReadableTx) error {
+		bucket := txn.GetBucket(blocksBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		data := bucket.Get(makeKey(index))
+		if data == nil {
+			return nil
+		}
+
+		var members []memberJSON
+
+		err := json.Unmarshal(data, &members)
+		if err != nil {
+			return xerrors.Errorf("failed to decode: %v", err)
+		}
+
+		participants = make([]ordering.Participant, len(members))
+		for i, m := range members {
+			participants[i] = ordering.Participant{
+				Address:   s.fac.FromText(m.Address),
+				Prepared:  m.Prepared,
+				Committed: m.Committed,
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read block %d: %v", index, err)
+	}
+
+	return participants, nil
+}
+
+// GetStats returns the statistics of every member that has been part of the
+// roster.
+func (s *Store) GetStats() ([]Stats, error) {
+	var list []Stats
+
+	err := s.db.View(func(txn kv.ReadableTx) error {
+		bucket := txn.GetBucket(statsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(key, value []byte) error {
+			var st statsJSON
+
+			err := json.Unmarshal(value, &st)
+			if err != nil {
+				return xerrors.Errorf("failed to decode: %v", err)
+			}
+
+			list = append(list, Stats{
+				Address:    s.fac.FromText(key),
+				Blocks:     st.Blocks,
+				Prepares:   st.Prepares,
+				Commits:    st.Commits,
+				LastCommit: st.LastCommit,
+				Missed:     st.Missed,
+			})
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read stats: %v", err)
+	}
+
+	return list, nil
+}
+
+func updateStats(bucket kv.Bucket, key []byte, index uint64,
+	p ordering.Participant) (statsJSON, error) {
+
+	var st statsJSON
+
+	data := bucket.Get(key)
+	if data != nil {
+		err := json.Unmarshal(data, &st)
+		if err != nil {
+			return st, xerrors.Errorf("failed to decode: %v", err)
+		}
+	}
+
+	st.Blocks++
+
+	if p.Prepared {
+		st.Prepares++
+	}
+
+	if p.Committed {
+		st.Commits++
+		st.LastCommit = index
+		st.Missed = 0
+	} else {
+		st.Missed++
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return st, xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	err = bucket.Set(key, data)
+	if err != nil {
+		return st, xerrors.Errorf("while writing: %v", err)
+	}
+
+	return st, nil
+}
+
+func hasSigned(sig crypto.Signature, index int) bool {
+	masked, ok := sig.(maskedSignature)
+	if !ok {
+		return true
+	}
+
+	return masked.HasBit(index)
+}
+
+func observe(p ordering.Participant, missed uint64) {
+	addr := p.Address.String()
+
+	if p.Prepared {
+		promSignatures.WithLabelValues(addr, phasePrepare).Inc()
+	}
+
+	if p.Committed {
+		promSignatures.WithLabelValues(addr, phaseCommit).Inc()
+	}
+
+	promMissed.WithLabelValues(addr).Set(float64(missed))
+}
+
+func makeKey(index uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, index)
+
+	return key
+}
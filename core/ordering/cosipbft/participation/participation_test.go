@@ -0,0 +1,146 @@
+package participation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestStore_Record(t *testing.T) {
+	db, err := kv.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	store := NewStore(db, fake.AddressFactory{})
+	roster := fake.NewAuthority(3, fake.NewSigner)
+
+	// Member 2 doesn't sign the commit of the first block, and nothing at all
+	// for the second one.
+	record(t, db, store, 0, roster, makeSignature(0b111), makeSignature(0b011))
+	record(t, db, store, 1, roster, makeSignature(0b011), makeSignature(0b011))
+
+	participants, err := store.GetBlock(0)
+	require.NoError(t, err)
+	require.Equal(t, []ordering.Participant{
+		{Address: fake.NewAddress(0), Prepared: true, Committed: true},
+		{Address: fake.NewAddress(1), Prepared: true, Committed: true},
+		{Address: fake.NewAddress(2), Prepared: true, Committed: false},
+	}, participants)
+
+	stats, err := store.GetStats()
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+	require.Equal(t, Stats{
+		Address:    fake.NewAddress(0),
+		Blocks:     2,
+		Prepares:   2,
+		Commits:    2,
+		LastCommit: 1,
+	}, stats[0])
+	require.Equal(t, Stats{
+		Address:  fake.NewAddress(2),
+		Blocks:   2,
+		Prepares: 1,
+		Missed:   2,
+	}, stats[2])
+
+	// A signature without a mask is signed by every member.
+	record(t, db, store, 2, roster, fake.Signature{}, fake.Signature{})
+
+	stats, err = store.GetStats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), stats[2].Missed)
+	require.Equal(t, uint64(2), stats[2].LastCommit)
+
+	participants, err = store.GetBlock(3)
+	require.NoError(t, err)
+	require.Nil(t, participants)
+}
+
+func TestStore_Empty(t *testing.T) {
+	db, err := kv.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	store := NewStore(db, fake.AddressFactory{})
+
+	participants, err := store.GetBlock(0)
+	require.NoError(t, err)
+	require.Nil(t, participants)
+
+	stats, err := store.GetStats()
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
+
+func TestStore_BadRecord(t *testing.T) {
+	db, err := kv.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	store := NewStore(db, fake.AddressFactory{})
+
+	roster := fake.NewAuthorityFromMino(fake.NewSigner, fake.NewBadMino())
+	require.Equal(t, 1, roster.Len())
+
+	err = db.Update(func(txn kv.WritableTx) error {
+		_, err := store.Record(txn, 0, roster, fake.Signature{}, fake.Signature{})
+		return err
+	})
+	require.EqualError(t, err, fake.Err("failed to marshal address"))
+
+	err = db.Update(func(txn kv.WritableTx) error {
+		bucket, err := txn.GetBucketOrCreate(statsBucket)
+		require.NoError(t, err)
+		require.NoError(t, bucket.Set([]byte{0, 0, 0, 0}, []byte("{")))
+
+		bucket, err = txn.GetBucketOrCreate(blocksBucket)
+		require.NoError(t, err)
+
+		return bucket.Set(makeKey(0), []byte("{"))
+	})
+	require.NoError(t, err)
+
+	_, err = store.GetBlock(0)
+	require.EqualError(t, err, "failed to read block 0: failed to decode: "+
+		"unexpected end of JSON input")
+
+	_, err = store.GetStats()
+	require.EqualError(t, err, "failed to read stats: failed to decode: "+
+		"unexpected end of JSON input")
+
+	err = db.Update(func(txn kv.WritableTx) error {
+		_, err := store.Record(txn, 1, fake.NewAuthority(1, fake.NewSigner),
+			fake.Signature{}, fake.Signature{})
+		return err
+	})
+	require.EqualError(t, err, "stats of 'fake.Address[0]': failed to decode: "+
+		"unexpected end of JSON input")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func record(t *testing.T, db kv.DB, store *Store, index uint64, roster mino.Players,
+	prepare, commit crypto.Signature) {
+
+	err := db.Update(func(txn kv.WritableTx) error {
+		_, err := store.Record(txn, index, roster, prepare, commit)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func makeSignature(mask byte) crypto.Signature {
+	return types.NewSignature(fake.Signature{}, []byte{mask})
+}
@@ -23,6 +23,7 @@ import (
 	"go.dedis.ch/dela/core"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/participation"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
@@ -155,7 +156,10 @@ type StateMachine interface {
 }
 
 type round struct {
-	leader     uint16
+	leader uint16
+	// view counts the view changes since the beginning of the round, as the
+	// leader index starts again from zero after the last member.
+	view       uint64
 	threshold  int
 	id         types.Digest
 	block      types.Block
@@ -191,6 +195,9 @@ type pbftsm struct {
 	authReader AuthorityReader
 	db         kv.DB
 
+	// participation records the members that signed the blocks, if set.
+	participation *participation.Store
+
 	// verifierFac creates a verifier for the aggregated signature.
 	verifierFac crypto.VerifierFactory
 	// signer signs and verify single signature for the view change.
@@ -212,14 +219,24 @@ type StateMachineParam struct {
 	Tree            blockstore.TreeCache
 	AuthorityReader AuthorityReader
 	DB              kv.DB
+	Participation   *participation.Store
+
+	// HashFactory computes the digests of the links. It uses SHA-256 if it is
+	// not set.
+	HashFactory crypto.HashFactory
 }
 
 // NewStateMachine returns a new state machine.
 func NewStateMachine(param StateMachineParam) StateMachine {
+	hashFac := param.HashFactory
+	if hashFac == nil {
+		hashFac = crypto.NewHashFactory(crypto.Sha256)
+	}
+
 	return &pbftsm{
 		logger:      param.Logger,
 		watcher:     core.NewWatcher(),
-		hashFac:     crypto.NewHashFactory(crypto.Sha256),
+		hashFac:     hashFac,
 		val:         param.Validation,
 		verifierFac: param.VerifierFactory,
 		signer:      param.Signer,
@@ -229,6 +246,8 @@ func NewStateMachine(param StateMachineParam) StateMachine {
 		db:          param.DB,
 		state:       NoneState,
 		authReader:  param.AuthorityReader,
+
+		participation: param.Participation,
 	}
 }
 
@@ -319,6 +338,8 @@ func (m *pbftsm) Prepare(from mino.Address, block types.Block) (types.Digest, er
 	if m.state == PrepareState || m.state == CommitState {
 		// The leader should only propose one block, therefore the accepted
 		// proposal identifier is sent back, whatever the input is.
+		m.bindPrepare(m.round.block)
+
 		return id, nil
 	}
 
@@ -331,6 +352,8 @@ func (m *pbftsm) Prepare(from mino.Address, block types.Block) (types.Digest, er
 
 	m.setState(PrepareState)
 
+	m.bindPrepare(block)
+
 	return m.round.id, nil
 }
 
@@ -363,6 +386,16 @@ func (m *pbftsm) Commit(id types.Digest, sig crypto.Signature) error {
 
 	m.setState(CommitState)
 
+	buffer, err := sig.MarshalBinary()
+	if err == nil {
+		m.bindScope(buffer, crypto.SignScope{
+			Kind:  scopeCommit,
+			Index: m.round.block.GetIndex(),
+			View:  m.round.view,
+			ID:    id.Bytes(),
+		})
+	}
+
 	return nil
 }
 
@@ -390,6 +423,7 @@ func (m *pbftsm) Finalize(id types.Digest, sig crypto.Signature) error {
 
 	m.round.prevViews = nil
 	m.round.views = nil
+	m.round.view = 0
 	m.round.committed = false
 
 	m.setState(InitialState)
@@ -541,6 +575,13 @@ func (m *pbftsm) Expire(addr mino.Address) (View, error) {
 		Leader: newLeader,
 	}
 
+	m.bindScope(View{id: lastID, leader: newLeader}.bytes(), crypto.SignScope{
+		Kind:  scopeView,
+		Index: uint64(m.blocks.Len()),
+		View:  m.round.view + 1,
+		ID:    lastID.Bytes(),
+	})
+
 	view, err := NewViewAndSign(param, m.signer)
 	if err != nil {
 		return view, xerrors.Errorf("create view: %v", err)
@@ -600,6 +641,7 @@ func (m *pbftsm) CatchUp(link types.BlockLink) error {
 
 	m.round.views = nil
 	m.round.prevViews = nil
+	m.round.view = 0
 	m.setState(InitialState)
 
 	return nil
@@ -768,6 +810,14 @@ func (m *pbftsm) verifyFinalize(r *round, sig crypto.Signature, ro authority.Aut
 			return xerrors.Errorf("store block: %v", err)
 		}
 
+		// 3. Record the members that signed the block.
+		if m.participation != nil {
+			_, err = m.participation.Record(txn, r.block.GetIndex(), ro, r.prepareSig, sig)
+			if err != nil {
+				return xerrors.Errorf("record participation: %v", err)
+			}
+		}
+
 		// Only release the tree cache at the very end of the transaction, so
 		// that a call to get the tree will hold until the block is stored.
 		txn.OnCommit(func() {
@@ -813,6 +863,7 @@ func (m *pbftsm) checkViewChange(view View) {
 		m.round.prevViews = m.round.views
 		m.round.views = nil
 		m.round.leader = view.leader
+		m.round.view++
 
 		if m.round.committed {
 			m.setState(CommitState)
@@ -822,6 +873,46 @@ func (m *pbftsm) checkViewChange(view View) {
 	}
 }
 
+// bindScope declares what the message commits to, if the signer supports it, so
+// that it can refuse to sign conflicting messages for the same round.
+func (m *pbftsm) bindScope(msg []byte, scope crypto.SignScope) {
+	scoped, ok := m.signer.(crypto.ScopedSigner)
+	if !ok {
+		return
+	}
+
+	scoped.BindScope(msg, scope)
+}
+
+// bindPrepare declares the scope of the proposal of the round, with the
+// preimage of its identifier so that the index can be verified.
+func (m *pbftsm) bindPrepare(block types.Block) {
+	_, ok := m.signer.(crypto.ScopedSigner)
+	if !ok {
+		return
+	}
+
+	from, err := m.getLatestID()
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("couldn't read latest digest for the scope")
+		return
+	}
+
+	data, err := newPrepareData(from, block)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("couldn't create the scope")
+		return
+	}
+
+	m.bindScope(m.round.id[:], crypto.SignScope{
+		Kind:  scopePrepare,
+		Index: block.GetIndex(),
+		View:  m.round.view,
+		ID:    m.round.id.Bytes(),
+		Data:  data,
+	})
+}
+
 func (m *pbftsm) getLatestID() (types.Digest, error) {
 	if m.blocks.Len() == 0 {
 		genesis, err := m.genesis.Get()
@@ -11,6 +11,7 @@ import (
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/participation"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
@@ -110,7 +111,8 @@ func TestStateMachine_Prepare(t *testing.T) {
 		AuthorityReader: func(hashtree.Tree) (authority.Authority, error) {
 			return ro, nil
 		},
-		DB: db,
+		DB:     db,
+		Signer: &scopedSigner{Signer: fake.NewSigner()},
 	}
 
 	param.Genesis.Set(types.Genesis{})
@@ -136,6 +138,13 @@ func TestStateMachine_Prepare(t *testing.T) {
 	id, err = sm.Prepare(from, block)
 	require.NoError(t, err)
 	require.Equal(t, sm.round.id, id)
+
+	signer := param.Signer.(*scopedSigner)
+	require.Len(t, signer.scopes, 2)
+	require.Equal(t, id.Bytes(), signer.msgs[1])
+	require.Equal(t, scopePrepare, signer.scopes[1].Kind)
+	require.Equal(t, id.Bytes(), signer.scopes[1].ID)
+	require.NoError(t, NewScopeVerifier().VerifyScope(signer.msgs[1], signer.scopes[1]))
 }
 
 func TestStateMachine_WhileViewChange_Prepare(t *testing.T) {
@@ -315,7 +324,8 @@ func TestStateMachine_FailCreateLink_Prepare(t *testing.T) {
 
 	_, err = sm.Prepare(fake.NewAddress(0), block)
 	require.EqualError(t, err,
-		fake.Err("failed to create link: failed to fingerprint: couldn't write from"))
+		fake.Err("failed to create link: failed to fingerprint: couldn't write link: "+
+			"failed to write"))
 }
 
 func TestStateMachine_Commit(t *testing.T) {
@@ -325,8 +335,11 @@ func TestStateMachine_Commit(t *testing.T) {
 		watcher:     core.NewWatcher(),
 		tree:        blockstore.NewTreeCache(badTree{}),
 		authReader:  goodReader,
+		signer:      &scopedSigner{Signer: fake.NewSigner()},
 		round: round{
-			id: types.Digest{1},
+			id:     types.Digest{1},
+			leader: 2,
+			view:   5,
 		},
 	}
 
@@ -335,6 +348,12 @@ func TestStateMachine_Commit(t *testing.T) {
 	require.Equal(t, CommitState, sm.state)
 	require.True(t, sm.round.committed)
 	require.NotNil(t, sm.round.prepareSig)
+
+	signer := sm.signer.(*scopedSigner)
+	require.Len(t, signer.scopes, 1)
+	require.Equal(t, []byte{0xfe}, signer.msgs[0])
+	require.Equal(t, crypto.SignScope{Kind: scopeCommit, View: 5,
+		ID: types.Digest{1}.Bytes()}, signer.scopes[0])
 }
 
 func TestStateMachine_WhileViewChange_Commit(t *testing.T) {
@@ -407,7 +426,8 @@ func TestStateMachine_Finalize(t *testing.T) {
 		AuthorityReader: func(hashtree.Tree) (authority.Authority, error) {
 			return ro, nil
 		},
-		DB: db,
+		DB:            db,
+		Participation: participation.NewStore(db, fake.AddressFactory{}),
 	}
 
 	param.Genesis.Set(types.Genesis{})
@@ -419,6 +439,11 @@ func TestStateMachine_Finalize(t *testing.T) {
 
 	err := sm.Finalize(types.Digest{1}, fake.Signature{})
 	require.NoError(t, err)
+
+	participants, err := param.Participation.GetBlock(0)
+	require.NoError(t, err)
+	require.Len(t, participants, 3)
+	require.True(t, participants[2].Committed)
 }
 
 func TestStateMachine_NotCommitted_Finalize(t *testing.T) {
@@ -577,6 +602,39 @@ func TestStateMachine_FailStoreBlock_Finalize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("database failed: store block"))
 }
 
+func TestStateMachine_FailRecordParticipation_Finalize(t *testing.T) {
+	tree, db, clean := makeTree(t)
+	defer clean()
+
+	ro := authority.New([]mino.Address{fake.NewBadAddress()},
+		[]crypto.PublicKey{fake.PublicKey{}})
+
+	sm := &pbftsm{
+		state: CommitState,
+		tree:  blockstore.NewTreeCache(tree),
+		authReader: func(hashtree.Tree) (authority.Authority, error) {
+			return ro, nil
+		},
+		verifierFac:   fake.NewVerifierFactory(fake.Verifier{}),
+		genesis:       blockstore.NewGenesisStore(),
+		blocks:        blockstore.NewInMemory(),
+		db:            db,
+		hashFac:       crypto.NewHashFactory(crypto.Sha256),
+		participation: participation.NewStore(db, fake.AddressFactory{}),
+		round: round{
+			prepareSig: fake.Signature{},
+			tree:       tree.(hashtree.StagingTree),
+		},
+	}
+
+	sm.genesis.Set(types.Genesis{})
+
+	err := sm.Finalize(types.Digest{1}, fake.Signature{})
+	require.EqualError(t, err,
+		fake.Err("database failed: record participation: failed to marshal address"))
+	require.Equal(t, uint64(0), sm.blocks.Len())
+}
+
 func TestStateMachine_Accept(t *testing.T) {
 	ro := authority.FromAuthority(fake.NewAuthority(4, fake.NewSigner))
 
@@ -685,6 +743,7 @@ func TestStateMachine_AcceptAll(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, sm.round.threshold)
 	require.Equal(t, uint16(5), sm.round.leader)
+	require.Equal(t, uint64(1), sm.round.view)
 	require.Equal(t, InitialState, sm.state)
 	require.Nil(t, sm.round.views)
 	require.Len(t, sm.round.prevViews, 3)
@@ -728,6 +787,16 @@ func TestStateMachine_Expire(t *testing.T) {
 	require.Equal(t, uint16(1), view.leader)
 	require.NoError(t, view.Verify(sm.signer.GetPublicKey()))
 
+	signer := &scopedSigner{Signer: bls.NewSigner()}
+	sm.signer = signer
+
+	view, err = sm.Expire(fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{view.bytes()}, signer.msgs)
+	require.Equal(t, scopeView, signer.scopes[0].Kind)
+	require.Equal(t, uint64(1), signer.scopes[0].View)
+	require.Equal(t, view.id.Bytes(), signer.scopes[0].ID)
+
 	sm.signer = fake.NewBadSigner()
 	_, err = sm.Expire(fake.NewAddress(0))
 	require.EqualError(t, err, fake.Err("create view: signer"))
@@ -979,3 +1048,15 @@ func goodReader(hashtree.Tree) (authority.Authority, error) {
 func badReader(hashtree.Tree) (authority.Authority, error) {
 	return nil, fake.GetError()
 }
+
+type scopedSigner struct {
+	crypto.Signer
+
+	msgs   [][]byte
+	scopes []crypto.SignScope
+}
+
+func (s *scopedSigner) BindScope(msg []byte, scope crypto.SignScope) {
+	s.msgs = append(s.msgs, msg)
+	s.scopes = append(s.scopes, scope)
+}
@@ -0,0 +1,189 @@
+// This file contains the scopes of the messages signed by the state machine,
+// and their verification by a signer.
+
+package pbft
+
+import (
+	"bytes"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde/canonical"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// scopePrepare is the kind of scope of the signature of a block proposal.
+	scopePrepare = "prepare"
+	// scopeCommit is the kind of scope of the signature of a commit.
+	scopeCommit = "commit"
+	// scopeView is the kind of scope of the signature of a view change.
+	scopeView = "view"
+
+	// viewSize is the size of the message of a view, which is the leader and
+	// the digest of the latest block.
+	viewSize = 2 + len(types.Digest{})
+)
+
+// hashAlgorithms are the algorithms a chain can use. The verifier doesn't know
+// the one of the chain, therefore it tries each of them.
+var hashAlgorithms = []crypto.HashAlgorithm{
+	crypto.Sha256,
+	crypto.Sha3_256,
+	crypto.Blake2b256,
+	crypto.Blake3_256,
+}
+
+// prepareData is the data of the scope of a proposal. It is the preimage of
+// the digest of the link, so that the index of the block can be verified.
+type prepareData struct {
+	From     types.Digest
+	Index    uint64
+	TreeRoot types.Digest
+	Data     []byte
+}
+
+// newPrepareData returns the data of the scope of the proposal of the block
+// after the previous one.
+func newPrepareData(from types.Digest, block types.Block) ([]byte, error) {
+	data, err := canonical.Fingerprint(block.GetData())
+	if err != nil {
+		return nil, xerrors.Errorf("data fingerprint failed: %v", err)
+	}
+
+	return canonical.Marshal(prepareData{
+		From:     from,
+		Index:    block.GetIndex(),
+		TreeRoot: block.GetTreeRoot(),
+		Data:     data,
+	})
+}
+
+// digest returns the digest of the link of the data with the algorithm.
+func (d prepareData) digest(algorithm crypto.HashAlgorithm) (types.Digest, error) {
+	fac := crypto.NewHashFactory(algorithm)
+
+	to := types.Digest{}
+
+	h := fac.New()
+	err := canonical.Write(h, d.Index, d.TreeRoot, d.Data)
+	if err != nil {
+		return to, xerrors.Errorf("couldn't write block: %v", err)
+	}
+
+	copy(to[:], h.Sum(nil))
+
+	id := types.Digest{}
+
+	h = fac.New()
+	err = canonical.Write(h, d.From, to)
+	if err != nil {
+		return id, xerrors.Errorf("couldn't write link: %v", err)
+	}
+
+	copy(id[:], h.Sum(nil))
+
+	return id, nil
+}
+
+// ScopeVerifier verifies the scopes of the messages signed by the state
+// machine, so that a signer daemon doesn't trust the node that declares them:
+//
+//   - the message of a proposal is the digest of the link, and the data of its
+//     scope is the preimage that includes the index of the block;
+//   - the message of a view change includes the digest of the latest block;
+//   - the message of a commit is the signature of the proposal, which can't be
+//     verified without the roster, therefore it is only signed for a proposal
+//     signed before at the same index.
+//
+// The view of a round is not part of the messages, and a signer can only
+// refuse to sign conflicting messages for the same view.
+//
+// - implements remote.ScopeVerifier
+type ScopeVerifier struct{}
+
+// NewScopeVerifier creates a new verifier of the scopes of the state machine.
+func NewScopeVerifier() ScopeVerifier {
+	return ScopeVerifier{}
+}
+
+// VerifyScope implements remote.ScopeVerifier. It returns nil if the message
+// matches the scope, otherwise an error.
+func (ScopeVerifier) VerifyScope(msg []byte, scope crypto.SignScope) error {
+	switch scope.Kind {
+	case scopePrepare:
+		return verifyPrepareScope(msg, scope)
+	case scopeCommit:
+		// The message of a commit must not be the one of another kind, as it
+		// is not verified.
+		if hasScopedSize(msg) {
+			return xerrors.Errorf("invalid commit of %d bytes", len(msg))
+		}
+
+		return nil
+	case scopeView:
+		if len(msg) != viewSize {
+			return xerrors.Errorf("invalid view of %d bytes", len(msg))
+		}
+
+		if !bytes.Equal(msg[viewSize-len(types.Digest{}):], scope.ID) {
+			return xerrors.Errorf("mismatch view id %x", scope.ID)
+		}
+
+		return nil
+	default:
+		return xerrors.Errorf("unknown kind '%s'", scope.Kind)
+	}
+}
+
+// GetRequirement implements remote.ScopeVerifier. It returns the kind of scope
+// that must be signed before a scope of the kind.
+func (ScopeVerifier) GetRequirement(kind string) string {
+	if kind == scopeCommit {
+		return scopePrepare
+	}
+
+	return ""
+}
+
+// IsScoped implements remote.ScopeVerifier. It returns true if the message has
+// the size of a prepare or a view change message.
+func (ScopeVerifier) IsScoped(msg []byte) bool {
+	return hasScopedSize(msg)
+}
+
+// hasScopedSize returns true if the message has the size of the message of a
+// prepare or of a view change.
+func hasScopedSize(msg []byte) bool {
+	return len(msg) == len(types.Digest{}) || len(msg) == viewSize
+}
+
+func verifyPrepareScope(msg []byte, scope crypto.SignScope) error {
+	if !bytes.Equal(msg, scope.ID) {
+		return xerrors.Errorf("mismatch prepare id %x", scope.ID)
+	}
+
+	var data prepareData
+
+	err := canonical.Unmarshal(scope.Data, &data)
+	if err != nil {
+		return xerrors.Errorf("failed to decode data: %v", err)
+	}
+
+	if data.Index != scope.Index {
+		return xerrors.Errorf("mismatch index %d != %d", data.Index, scope.Index)
+	}
+
+	for _, algorithm := range hashAlgorithms {
+		id, err := data.digest(algorithm)
+		if err != nil {
+			return xerrors.Errorf("failed to compute digest: %v", err)
+		}
+
+		if bytes.Equal(id[:], msg) {
+			return nil
+		}
+	}
+
+	return xerrors.New("data is not the preimage of the message")
+}
@@ -0,0 +1,98 @@
+package pbft
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+)
+
+func TestScopeVerifier_Prepare(t *testing.T) {
+	verifier := NewScopeVerifier()
+
+	for _, algorithm := range []crypto.HashAlgorithm{crypto.Sha256, crypto.Blake3_256} {
+		fac := crypto.NewHashFactory(algorithm)
+
+		block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(3),
+			types.WithTreeRoot(types.Digest{2}), types.WithHashFactory(fac))
+		require.NoError(t, err)
+
+		link, err := types.NewBlockLink(types.Digest{1}, block, types.WithLinkHashFactory(fac))
+		require.NoError(t, err)
+
+		data, err := newPrepareData(types.Digest{1}, block)
+		require.NoError(t, err)
+
+		msg := link.GetHash().Bytes()
+
+		scope := crypto.SignScope{Kind: scopePrepare, Index: 3, ID: msg, Data: data}
+
+		require.NoError(t, verifier.VerifyScope(msg, scope))
+
+		scope.Index = 4
+		err = verifier.VerifyScope(msg, scope)
+		require.EqualError(t, err, "mismatch index 3 != 4")
+
+		scope.Index = 3
+		scope.ID = []byte{1}
+		err = verifier.VerifyScope(msg, scope)
+		require.EqualError(t, err, "mismatch prepare id 01")
+
+		scope.ID = types.Digest{3}.Bytes()
+		err = verifier.VerifyScope(scope.ID, scope)
+		require.EqualError(t, err, "data is not the preimage of the message")
+
+		scope.ID = msg
+		scope.Data = []byte{}
+		err = verifier.VerifyScope(msg, scope)
+		require.Regexp(t, "^failed to decode data: ", err)
+	}
+}
+
+func TestScopeVerifier_Commit(t *testing.T) {
+	verifier := NewScopeVerifier()
+
+	scope := crypto.SignScope{Kind: scopeCommit, Index: 3, ID: types.Digest{1}.Bytes()}
+
+	require.NoError(t, verifier.VerifyScope([]byte{0xaa}, scope))
+
+	err := verifier.VerifyScope(types.Digest{1}.Bytes(), scope)
+	require.EqualError(t, err, "invalid commit of 32 bytes")
+
+	err = verifier.VerifyScope(make([]byte, viewSize), scope)
+	require.EqualError(t, err, "invalid commit of 34 bytes")
+
+	require.Equal(t, scopePrepare, verifier.GetRequirement(scopeCommit))
+	require.Equal(t, "", verifier.GetRequirement(scopePrepare))
+}
+
+func TestScopeVerifier_IsScoped(t *testing.T) {
+	verifier := NewScopeVerifier()
+
+	require.True(t, verifier.IsScoped(types.Digest{1}.Bytes()))
+	require.True(t, verifier.IsScoped(make([]byte, viewSize)))
+	require.False(t, verifier.IsScoped([]byte{0xaa}))
+}
+
+func TestScopeVerifier_View(t *testing.T) {
+	verifier := NewScopeVerifier()
+
+	msg := View{id: types.Digest{1}, leader: 2}.bytes()
+
+	scope := crypto.SignScope{Kind: scopeView, Index: 3, ID: types.Digest{1}.Bytes()}
+
+	require.NoError(t, verifier.VerifyScope(msg, scope))
+
+	err := verifier.VerifyScope(msg[1:], scope)
+	require.EqualError(t, err, "invalid view of 33 bytes")
+
+	scope.ID = types.Digest{2}.Bytes()
+	err = verifier.VerifyScope(msg, scope)
+	require.EqualError(t, err, fmt.Sprintf("mismatch view id %x", scope.ID))
+
+	err = verifier.VerifyScope(msg, crypto.SignScope{Kind: "unknown"})
+	require.EqualError(t, err, "unknown kind 'unknown'")
+}
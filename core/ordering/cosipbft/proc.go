@@ -13,6 +13,7 @@ import (
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela/core"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blocksync"
@@ -27,6 +28,7 @@ import (
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
@@ -55,6 +57,7 @@ type processor struct {
 	rosterFac   authority.Factory
 	hashFactory crypto.HashFactory
 	access      access.Service
+	clock       clock.Clock
 
 	context serde.Context
 	genesis blockstore.GenesisStore
@@ -69,6 +72,7 @@ func newProcessor() *processor {
 	proc := &processor{
 		watcher: core.NewWatcher(),
 		context: json.NewContext(),
+		clock:   clock.NewSystem(),
 		started: make(chan struct{}),
 		catchup: make(chan mino.Players),
 	}
@@ -185,9 +189,11 @@ func (h *processor) Process(req mino.Request) (serde.Message, error) {
 			return nil, nil
 		}
 
-		root := msg.GetGenesis().GetRoot()
+		genesis := msg.GetGenesis()
+		root := genesis.GetRoot()
 
-		return nil, h.storeGenesis(msg.GetGenesis().GetRoster(), &root)
+		return nil, h.storeGenesis(genesis.GetRoster(), genesis.GetHashAlgorithm(),
+			genesis.GetFingerprintVersion(), &root)
 	case types.DoneMessage:
 		if h.pbftsm.GetState() == pbft.InitialState {
 			h.logger.Warn().Msgf("Got block without commit from %v - catching up", req.Address)
@@ -234,12 +240,21 @@ func (h *processor) readRoster(tree hashtree.Tree) (authority.Authority, error)
 	return roster, nil
 }
 
-func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest) error {
+func (h *processor) storeGenesis(roster authority.Authority, algo crypto.HashAlgorithm,
+	version uint32, match *types.Digest) error {
+
 	value, err := roster.Serialize(h.context)
 	if err != nil {
 		return xerrors.Errorf("failed to serialize roster: %v", err)
 	}
 
+	// The algorithm of the chain must be set before the tree is updated so that
+	// the root is computed with it.
+	chainHash, ok := h.hashFactory.(*types.ChainHashFactory)
+	if ok {
+		chainHash.SetAlgorithm(algo)
+	}
+
 	stageTree, err := h.tree.Get().Stage(func(snap store.Snapshot) error {
 		err := h.makeAccess(snap, roster)
 		if err != nil {
@@ -264,7 +279,16 @@ func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest
 		return xerrors.Errorf("mismatch tree root '%v' != '%v'", match, root)
 	}
 
-	genesis, err := types.NewGenesis(roster, types.WithGenesisRoot(root))
+	opts := []types.GenesisOption{
+		types.WithGenesisRoot(root),
+		types.WithGenesisHashAlgorithm(algo),
+	}
+
+	if version == registry.LegacyVersion {
+		opts = append(opts, types.WithGenesisLegacyFingerprint())
+	}
+
+	genesis, err := types.NewGenesis(roster, opts...)
 	if err != nil {
 		return xerrors.Errorf("creating genesis: %v", err)
 	}
@@ -276,6 +300,12 @@ func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest
 
 	h.tree.Set(stageTree)
 
+	// The blocks, the links and the transactions of the chain are then
+	// fingerprinted with the version of the genesis block.
+	if ok {
+		chainHash.SetFingerprintVersion(genesis.GetFingerprintVersion())
+	}
+
 	err = h.genesis.Set(genesis)
 	if err != nil {
 		return xerrors.Errorf("set genesis failed: %v", err)
@@ -314,7 +344,7 @@ func (h *processor) catchupHandler() {
 					break
 				}
 				h.logger.Err(err).Msg("Couldn't sync - trying again in 10 seconds")
-				time.Sleep(10 * time.Second)
+				h.clock.Sleep(10 * time.Second)
 			}
 			cancel()
 		}
@@ -123,6 +123,27 @@ func TestProcessor_GenesisMessage_Process(t *testing.T) {
 	proc.genesis = fakeGenesisStore{errSet: fake.GetError()}
 	_, err = proc.Process(req)
 	require.EqualError(t, err, fake.Err("set genesis failed"))
+
+	// The algorithm of the chain is set by the genesis.
+	chainHash := types.NewChainHashFactory()
+
+	proc = newProcessor()
+	proc.tree = blockstore.NewTreeCache(fakeTree{})
+	proc.genesis = blockstore.NewGenesisStore()
+	proc.access = fakeAccess{}
+	proc.hashFactory = chainHash
+
+	genesis, err = types.NewGenesis(ro, types.WithGenesisRoot(root),
+		types.WithGenesisHashAlgorithm(crypto.Sha3_256))
+	require.NoError(t, err)
+
+	_, err = proc.Process(mino.Request{Message: types.NewGenesisMessage(genesis)})
+	require.NoError(t, err)
+	require.Equal(t, crypto.Sha3_256, chainHash.GetAlgorithm())
+
+	stored, err := proc.genesis.Get()
+	require.NoError(t, err)
+	require.Equal(t, genesis.GetHash(), stored.GetHash())
 }
 
 func TestProcessor_DoneMessage_Process(t *testing.T) {
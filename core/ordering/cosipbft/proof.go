@@ -6,16 +6,28 @@
 package cosipbft
 
 import (
+	"encoding/json"
+
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store/hashtree"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
+var proofFormats = registry.NewSimpleRegistry()
+
+func init() {
+	proofFormats.Register(serde.FormatJSON, proofFormat{})
+	proofFormats.Register(serde.FormatCBOR, proofFormat{})
+}
+
 // Proof is a combination of elements that will prove the inclusion or the
 // absence of a key/value pair in the given block.
 //
 // - implements ordering.Proof
+// - implements serde.Message
 type Proof struct {
 	path  hashtree.Path
 	chain types.Chain
@@ -64,3 +76,135 @@ func (p Proof) Verify(genesis types.Genesis, fac crypto.VerifierFactory) error {
 
 	return nil
 }
+
+// Serialize implements serde.Message. It returns the data of the proof so that
+// it can be verified by a third party. The path must be serializable.
+func (p Proof) Serialize(ctx serde.Context) ([]byte, error) {
+	format := proofFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, p)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	return data, nil
+}
+
+// ProofFactory is the factory to deserialize proofs.
+//
+// - implements serde.Factory
+type ProofFactory struct {
+	pathFac  serde.Factory
+	chainFac types.ChainFactory
+}
+
+// NewProofFactory returns a new factory of proofs that deserializes the path
+// and the chain with the given factories.
+func NewProofFactory(pathFac serde.Factory, chainFac types.ChainFactory) ProofFactory {
+	return ProofFactory{
+		pathFac:  pathFac,
+		chainFac: chainFac,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the proof associated to
+// the data if appropriate, otherwise it returns an error.
+func (f ProofFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := proofFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, pathKey{}, f.pathFac)
+	ctx = serde.WithFactory(ctx, chainKey{}, f.chainFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	proof, ok := msg.(Proof)
+	if !ok {
+		return nil, xerrors.Errorf("invalid proof '%T'", msg)
+	}
+
+	return proof, nil
+}
+
+type pathKey struct{}
+
+type chainKey struct{}
+
+// ProofJSON is the JSON representation of a proof.
+type ProofJSON struct {
+	Path  json.RawMessage
+	Chain json.RawMessage
+}
+
+type proofFormat struct{}
+
+func (f proofFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	proof, ok := msg.(Proof)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	path, ok := proof.path.(serde.Message)
+	if !ok {
+		return nil, xerrors.Errorf("path '%T' is not serializable", proof.path)
+	}
+
+	pathData, err := path.Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize path: %v", err)
+	}
+
+	chainData, err := proof.chain.Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize chain: %v", err)
+	}
+
+	m := ProofJSON{
+		Path:  pathData,
+		Chain: chainData,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f proofFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := ProofJSON{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	pathFac := ctx.GetFactory(pathKey{})
+	if pathFac == nil {
+		return nil, xerrors.New("missing path factory")
+	}
+
+	msg, err := pathFac.Deserialize(ctx, m.Path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to deserialize path: %v", err)
+	}
+
+	path, ok := msg.(hashtree.Path)
+	if !ok {
+		return nil, xerrors.Errorf("invalid path '%T'", msg)
+	}
+
+	chainFac, ok := ctx.GetFactory(chainKey{}).(types.ChainFactory)
+	if !ok {
+		return nil, xerrors.New("missing chain factory")
+	}
+
+	chain, err := chainFac.ChainOf(ctx, m.Chain)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to deserialize chain: %v", err)
+	}
+
+	return newProof(path, chain), nil
+}
@@ -9,9 +9,17 @@ import (
 	"go.dedis.ch/dela/core/store/hashtree"
 	"go.dedis.ch/dela/core/validation/simple"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/cbor"
+	"go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/dela/testing/fake"
 )
 
+func init() {
+	proofFormats.Register(fake.BadFormat, fake.NewBadFormat())
+	proofFormats.Register(fake.GoodFormat, fake.Format{Msg: fake.Message{}})
+}
+
 func TestProof_GetKey(t *testing.T) {
 	p := Proof{
 		path: fakePath{},
@@ -50,11 +58,106 @@ func TestProof_Verify(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to verify chain"))
 }
 
+func TestProof_Serialize(t *testing.T) {
+	p := newProof(fakePath{}, fakeChain{})
+
+	data, err := p.Serialize(json.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, `{"Path":{},"Chain":{}}`, string(data))
+
+	fac := NewProofFactory(fakePathFactory{}, fakeChainFactory{})
+
+	msg, err := fac.Deserialize(json.NewContext(), data)
+	require.NoError(t, err)
+	require.Equal(t, p, msg)
+
+	data, err = p.Serialize(cbor.NewContext())
+	require.NoError(t, err)
+
+	msg, err = fac.Deserialize(cbor.NewContext(), data)
+	require.NoError(t, err)
+	require.Equal(t, p, msg)
+
+	_, err = p.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("failed to encode"))
+}
+
+func TestProofFactory_Deserialize(t *testing.T) {
+	fac := NewProofFactory(fakePathFactory{}, fakeChainFactory{})
+
+	_, err := fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("failed to decode"))
+
+	_, err = fac.Deserialize(fake.NewContext(), nil)
+	require.EqualError(t, err, "invalid proof 'fake.Message'")
+}
+
+func TestProofFormat_Encode(t *testing.T) {
+	format := proofFormat{}
+	ctx := json.NewContext()
+
+	_, err := format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(ctx, newProof(struct{ hashtree.Path }{}, fakeChain{}))
+	require.EqualError(t, err, "path 'struct { hashtree.Path }' is not serializable")
+
+	_, err = format.Encode(ctx, newProof(fakePath{err: fake.GetError()}, fakeChain{}))
+	require.EqualError(t, err, fake.Err("failed to serialize path"))
+
+	_, err = format.Encode(ctx, newProof(fakePath{}, fakeChain{err: fake.GetError()}))
+	require.EqualError(t, err, fake.Err("failed to serialize chain"))
+
+	_, err = format.Encode(fake.NewBadContext(), newProof(fakePath{}, fakeChain{}))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestProofFormat_Decode(t *testing.T) {
+	format := proofFormat{}
+	ctx := json.NewContext()
+	ctx = serde.WithFactory(ctx, pathKey{}, fakePathFactory{})
+	ctx = serde.WithFactory(ctx, chainKey{}, fakeChainFactory{})
+
+	data := []byte(`{"Path":{},"Chain":{}}`)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, newProof(fakePath{}, fakeChain{}), msg)
+
+	_, err = format.Decode(fake.NewBadContext(), data)
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	_, err = format.Decode(json.NewContext(), data)
+	require.EqualError(t, err, "missing path factory")
+
+	badCtx := serde.WithFactory(ctx, pathKey{}, fakePathFactory{err: fake.GetError()})
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, fake.Err("failed to deserialize path"))
+
+	badCtx = serde.WithFactory(ctx, pathKey{}, fake.MessageFactory{})
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "invalid path 'fake.Message'")
+
+	badCtx = serde.WithFactory(ctx, chainKey{}, nil)
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "missing chain factory")
+
+	badCtx = serde.WithFactory(ctx, chainKey{}, fakeChainFactory{err: fake.GetError()})
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, fake.Err("failed to deserialize chain"))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
 type fakePath struct {
 	hashtree.Path
+
+	err error
+}
+
+func (p fakePath) Serialize(serde.Context) ([]byte, error) {
+	return []byte("{}"), p.err
 }
 
 func (p fakePath) GetKey() []byte {
@@ -83,3 +186,25 @@ func (c fakeChain) GetBlock() types.Block {
 func (c fakeChain) Verify(types.Genesis, types.Digest, crypto.VerifierFactory) error {
 	return c.err
 }
+
+func (c fakeChain) Serialize(serde.Context) ([]byte, error) {
+	return []byte("{}"), c.err
+}
+
+type fakePathFactory struct {
+	err error
+}
+
+func (f fakePathFactory) Deserialize(serde.Context, []byte) (serde.Message, error) {
+	return fakePath{}, f.err
+}
+
+type fakeChainFactory struct {
+	types.ChainFactory
+
+	err error
+}
+
+func (f fakeChainFactory) ChainOf(serde.Context, []byte) (types.Chain, error) {
+	return fakeChain{}, f.err
+}
@@ -15,6 +15,7 @@ import (
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
@@ -51,13 +52,17 @@ func (d Digest) Bytes() []byte {
 }
 
 // Genesis is the very first block of a chain. It contains the initial roster
-// and tree root.
+// and tree root, and the hash algorithm of the chain.
 //
 // - implements serde.Message
 type Genesis struct {
-	digest   Digest
-	roster   authority.Authority
-	treeRoot Digest
+	digest        Digest
+	roster        authority.Authority
+	treeRoot      Digest
+	hashAlgorithm crypto.HashAlgorithm
+	// legacy is true when the genesis block is fingerprinted with the encoding
+	// that precedes the canonical one, so that its digest does not change.
+	legacy bool
 }
 
 type genesisTemplate struct {
@@ -75,6 +80,15 @@ func WithGenesisRoot(root Digest) GenesisOption {
 	}
 }
 
+// WithGenesisHashAlgorithm is an option to set the hash algorithm of the chain.
+// The digest of the genesis block is also computed with it.
+func WithGenesisHashAlgorithm(algo crypto.HashAlgorithm) GenesisOption {
+	return func(tmpl *genesisTemplate) {
+		tmpl.hashAlgorithm = algo
+		tmpl.hashFactory = crypto.NewHashFactory(algo)
+	}
+}
+
 // WithGenesisHashFactory is an option to set the hash factory.
 func WithGenesisHashFactory(fac crypto.HashFactory) GenesisOption {
 	return func(tmpl *genesisTemplate) {
@@ -82,6 +96,15 @@ func WithGenesisHashFactory(fac crypto.HashFactory) GenesisOption {
 	}
 }
 
+// WithGenesisLegacyFingerprint is an option to fingerprint the genesis block
+// with the encoding that precedes the canonical one. It is used to decode the
+// genesis blocks of the chains created before the canonical encoding.
+func WithGenesisLegacyFingerprint() GenesisOption {
+	return func(tmpl *genesisTemplate) {
+		tmpl.legacy = true
+	}
+}
+
 // NewGenesis creates a new genesis block with the provided roster.
 func NewGenesis(ro authority.Authority, opts ...GenesisOption) (Genesis, error) {
 	tmpl := genesisTemplate{
@@ -122,6 +145,11 @@ func (g Genesis) GetRoot() Digest {
 	return g.treeRoot
 }
 
+// GetHashAlgorithm returns the hash algorithm of the chain.
+func (g Genesis) GetHashAlgorithm() crypto.HashAlgorithm {
+	return g.hashAlgorithm
+}
+
 // Serialize implements serde.Message. It returns the serialized data for this
 // genesis block.
 func (g Genesis) Serialize(ctx serde.Context) ([]byte, error) {
@@ -135,17 +163,85 @@ func (g Genesis) Serialize(ctx serde.Context) ([]byte, error) {
 	return data, nil
 }
 
-// Fingerprint implements serde.Fingerprinter. It deterministically writes a
-// binary representation of the genesis block into the writer.
+// GetFormatVersion implements registry.VersionedMessage. It returns the version
+// of the fingerprints of the genesis block, so that the version of the chain is
+// kept whatever the version pinned to encode the messages.
+func (g Genesis) GetFormatVersion() uint32 {
+	return g.GetFingerprintVersion()
+}
+
+// GetFingerprintVersion returns the version of the fingerprints of the chain
+// that starts with the genesis block.
+func (g Genesis) GetFingerprintVersion() uint32 {
+	if g.legacy {
+		return registry.LegacyVersion
+	}
+
+	return canonical.Version
+}
+
+// Fingerprint implements serde.Fingerprinter. It writes the canonical encoding
+// of the tree root, the fingerprint of the roster and the hash algorithm of the
+// genesis block.
 func (g Genesis) Fingerprint(w io.Writer) error {
+	if g.legacy {
+		return g.legacyFingerprint(w)
+	}
+
+	roster, err := canonical.Fingerprint(g.roster)
+	if err != nil {
+		return xerrors.Errorf("roster fingerprint failed: %v", err)
+	}
+
+	err = canonical.Write(w, g.treeRoot, roster, g.hashAlgorithm.String())
+	if err != nil {
+		return xerrors.Errorf("couldn't write genesis: %v", err)
+	}
+
+	return nil
+}
+
+// legacyFingerprint writes the concatenation of the tree root, the addresses
+// and the public keys of the roster, and the hash algorithm when it is not the
+// default one.
+func (g Genesis) legacyFingerprint(w io.Writer) error {
 	_, err := w.Write(g.treeRoot[:])
 	if err != nil {
 		return xerrors.Errorf("couldn't write root: %v", err)
 	}
 
-	err = g.roster.Fingerprint(w)
-	if err != nil {
-		return xerrors.Errorf("roster fingerprint failed: %v", err)
+	addrs := g.roster.AddressIterator()
+	pubkeys := g.roster.PublicKeyIterator()
+
+	for addrs.HasNext() && pubkeys.HasNext() {
+		data, err := addrs.GetNext().MarshalText()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal address: %v", err)
+		}
+
+		_, err = w.Write(data)
+		if err != nil {
+			return xerrors.Errorf("couldn't write address: %v", err)
+		}
+
+		data, err = pubkeys.GetNext().MarshalBinary()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal public key: %v", err)
+		}
+
+		_, err = w.Write(data)
+		if err != nil {
+			return xerrors.Errorf("couldn't write public key: %v", err)
+		}
+	}
+
+	// The default algorithm is omitted so that the digest of the chains created
+	// before the algorithm was recorded does not change.
+	if g.hashAlgorithm != crypto.Sha256 {
+		_, err = w.Write([]byte(g.hashAlgorithm.String()))
+		if err != nil {
+			return xerrors.Errorf("couldn't write hash algorithm: %v", err)
+		}
 	}
 
 	return nil
@@ -193,6 +289,9 @@ type Block struct {
 	index    uint64
 	data     validation.Result
 	treeRoot Digest
+	// legacy is true when the block is fingerprinted with the encoding that
+	// precedes the canonical one, so that its digest does not change.
+	legacy bool
 }
 
 type blockTemplate struct {
@@ -224,6 +323,16 @@ func WithHashFactory(fac crypto.HashFactory) BlockOption {
 	}
 }
 
+// WithLegacyFingerprint is an option to fingerprint the block with the encoding
+// that precedes the canonical one. It is used to decode the blocks created
+// before the canonical encoding, and the hash factory of a chain overrides it
+// with the version of the chain.
+func WithLegacyFingerprint() BlockOption {
+	return func(tmpl *blockTemplate) {
+		tmpl.legacy = true
+	}
+}
+
 // NewBlock creates a new block.
 func NewBlock(data validation.Result, opts ...BlockOption) (Block, error) {
 	tmpl := blockTemplate{
@@ -238,6 +347,14 @@ func NewBlock(data validation.Result, opts ...BlockOption) (Block, error) {
 		opt(&tmpl)
 	}
 
+	// The fingerprint follows the version of the chain when it is known, so
+	// that every node computes the same digest whatever the version of the
+	// encoding.
+	version := registry.FingerprintVersionOf(tmpl.hashFactory)
+	if version != 0 {
+		tmpl.legacy = version == registry.LegacyVersion
+	}
+
 	h := tmpl.hashFactory.New()
 	err := tmpl.Fingerprint(h)
 	if err != nil {
@@ -282,9 +399,39 @@ func (b Block) GetTreeRoot() Digest {
 	return b.treeRoot
 }
 
-// Fingerprint implements serde.Fingerprinter. It deterministically writes a
-// binary representation of the block into the writer.
+// GetFormatVersion implements registry.VersionedMessage. It returns the legacy
+// version of the format when the block has a legacy fingerprint.
+func (b Block) GetFormatVersion() uint32 {
+	if b.legacy {
+		return registry.LegacyVersion
+	}
+
+	return 0
+}
+
+// Fingerprint implements serde.Fingerprinter. It writes the canonical encoding
+// of the index, the tree root and the fingerprint of the data of the block.
 func (b Block) Fingerprint(w io.Writer) error {
+	if b.legacy {
+		return b.legacyFingerprint(w)
+	}
+
+	data, err := canonical.Fingerprint(b.data)
+	if err != nil {
+		return xerrors.Errorf("data fingerprint failed: %v", err)
+	}
+
+	err = canonical.Write(w, b.index, b.treeRoot, data)
+	if err != nil {
+		return xerrors.Errorf("couldn't write block: %v", err)
+	}
+
+	return nil
+}
+
+// legacyFingerprint writes the concatenation of the index, the tree root and,
+// for each transaction, its fingerprint and whether it is accepted.
+func (b Block) legacyFingerprint(w io.Writer) error {
 	buffer := make([]byte, 8)
 	binary.LittleEndian.PutUint64(buffer, b.index)
 	_, err := w.Write(buffer)
@@ -297,9 +444,23 @@ func (b Block) Fingerprint(w io.Writer) error {
 		return xerrors.Errorf("couldn't write root: %v", err)
 	}
 
-	err = b.data.Fingerprint(w)
-	if err != nil {
-		return xerrors.Errorf("data fingerprint failed: %v", err)
+	for _, res := range b.data.GetTransactionResults() {
+		err = res.GetTransaction().Fingerprint(w)
+		if err != nil {
+			return xerrors.Errorf("couldn't fingerprint tx: %v", err)
+		}
+
+		accepted, _ := res.GetStatus()
+
+		bit := []byte{0}
+		if accepted {
+			bit[0] = 1
+		}
+
+		_, err = w.Write(bit)
+		if err != nil {
+			return xerrors.Errorf("couldn't write accepted: %v", err)
+		}
 	}
 
 	return nil
@@ -321,17 +482,40 @@ func (b Block) Serialize(ctx serde.Context) ([]byte, error) {
 // DataKey is the key for the validated data factory.
 type DataKey struct{}
 
+// FactoryOption is the type of option to set some fields of the block and
+// link factories.
+type FactoryOption func(*factoryTemplate)
+
+type factoryTemplate struct {
+	hashFac crypto.HashFactory
+}
+
+// WithFactoryHash is an option to set the hash factory that computes the
+// digests of the blocks and links that are deserialized.
+func WithFactoryHash(fac crypto.HashFactory) FactoryOption {
+	return func(tmpl *factoryTemplate) {
+		tmpl.hashFac = fac
+	}
+}
+
 // BlockFactory is a factory to deserialize block messages.
 //
 // - implements serde.Factory
 type BlockFactory struct {
 	dataFac validation.ResultFactory
+	hashFac crypto.HashFactory
 }
 
 // NewBlockFactory creates a new block factory.
-func NewBlockFactory(fac validation.ResultFactory) BlockFactory {
+func NewBlockFactory(fac validation.ResultFactory, opts ...FactoryOption) BlockFactory {
+	tmpl := factoryTemplate{}
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
 	return BlockFactory{
 		dataFac: fac,
+		hashFac: tmpl.hashFac,
 	}
 }
 
@@ -342,6 +526,10 @@ func (f BlockFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message
 
 	ctx = serde.WithFactory(ctx, DataKey{}, f.dataFac)
 
+	if f.hashFac != nil {
+		ctx = crypto.WithHashFactory(ctx, f.hashFac)
+	}
+
 	msg, err := format.Decode(ctx, data)
 	if err != nil {
 		return nil, xerrors.Errorf("decoding block failed: %v", err)
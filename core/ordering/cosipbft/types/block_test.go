@@ -2,7 +2,9 @@ package types
 
 import (
 	"bytes"
+	"encoding/hex"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,6 +12,11 @@ import (
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
+	"go.dedis.ch/dela/serde/registry"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -18,8 +25,16 @@ func init() {
 	RegisterGenesisFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterBlockFormat(fake.GoodFormat, fake.Format{Msg: Block{}})
 	RegisterBlockFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterBlockFormat(hashFormat, fake.Format{Msg: Block{}, Call: hashCalls})
 }
 
+var (
+	// hashFormat is a format that records the context of the calls so that the
+	// tests can check the hash factory of the context.
+	hashFormat = serde.Format("hash")
+	hashCalls  = &fake.Call{}
+)
+
 func TestDigest_String(t *testing.T) {
 	digest := Digest{1, 2, 3, 4}
 
@@ -60,6 +75,19 @@ func TestGenesis_GetRoot(t *testing.T) {
 	require.Equal(t, Digest{5}, genesis.GetRoot())
 }
 
+func TestGenesis_GetHashAlgorithm(t *testing.T) {
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	genesis, err := NewGenesis(ro)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Sha256, genesis.GetHashAlgorithm())
+
+	other, err := NewGenesis(ro, WithGenesisHashAlgorithm(crypto.Blake3_256))
+	require.NoError(t, err)
+	require.Equal(t, crypto.Blake3_256, other.GetHashAlgorithm())
+	require.NotEqual(t, genesis.GetHash(), other.GetHash())
+}
+
 func TestGenesis_Serialize(t *testing.T) {
 	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
 
@@ -83,14 +111,74 @@ func TestGenesis_Fingerprint(t *testing.T) {
 	buffer := new(bytes.Buffer)
 	err = genesis.Fingerprint(buffer)
 	require.NoError(t, err)
-	require.Regexp(t, "^\x05(\x00){35,}PK", buffer.String())
+	// [h'05..', h'8182440000000042504b', "sha256"]
+	require.Equal(t, "835820"+"05"+strings.Repeat("00", 31)+"4a8182440000000042504b"+
+		"66"+hex.EncodeToString([]byte("sha256")), hex.EncodeToString(buffer.Bytes()))
 
 	_, err = NewGenesis(ro, WithGenesisHashFactory(fake.NewHashFactory(fake.NewBadHash())))
-	require.EqualError(t, err, fake.Err("fingerprint failed: couldn't write root"))
+	require.EqualError(t, err,
+		fake.Err("fingerprint failed: couldn't write genesis: failed to write"))
 
 	genesis.roster = badRoster{}
 	err = genesis.Fingerprint(buffer)
 	require.EqualError(t, err, fake.Err("roster fingerprint failed"))
+
+	genesis, err = NewGenesis(ro, WithGenesisRoot(Digest{5}),
+		WithGenesisHashAlgorithm(crypto.Sha3_256))
+	require.NoError(t, err)
+
+	buffer.Reset()
+	err = genesis.Fingerprint(buffer)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(buffer.String(), "\x68sha3-256"))
+}
+
+func TestGenesis_LegacyFingerprint(t *testing.T) {
+	ro := authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+
+	genesis, err := NewGenesis(ro, WithGenesisRoot(Digest{5}))
+	require.NoError(t, err)
+	require.Equal(t, canonical.Version, genesis.GetFormatVersion())
+
+	genesis, err = NewGenesis(ro, WithGenesisRoot(Digest{5}), WithGenesisLegacyFingerprint())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), genesis.GetFormatVersion())
+
+	buffer := new(bytes.Buffer)
+	err = genesis.Fingerprint(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "\x05"+strings.Repeat("\x00", 31)+"\x00\x00\x00\x00PK", buffer.String())
+
+	err = genesis.Fingerprint(fake.NewBadHash())
+	require.EqualError(t, err, fake.Err("couldn't write root"))
+
+	err = genesis.Fingerprint(fake.NewBadHashWithDelay(1))
+	require.EqualError(t, err, fake.Err("couldn't write address"))
+
+	err = genesis.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write public key"))
+
+	genesis.roster = authority.New([]mino.Address{fake.NewBadAddress()},
+		[]crypto.PublicKey{fake.PublicKey{}})
+	err = genesis.Fingerprint(buffer)
+	require.EqualError(t, err, fake.Err("couldn't marshal address"))
+
+	genesis.roster = authority.New([]mino.Address{fake.NewAddress(0)},
+		[]crypto.PublicKey{fake.NewBadPublicKey()})
+	err = genesis.Fingerprint(buffer)
+	require.EqualError(t, err, fake.Err("couldn't marshal public key"))
+
+	genesis, err = NewGenesis(ro, WithGenesisRoot(Digest{5}),
+		WithGenesisHashAlgorithm(crypto.Sha3_256), WithGenesisLegacyFingerprint())
+	require.NoError(t, err)
+
+	buffer.Reset()
+	err = genesis.Fingerprint(buffer)
+	require.NoError(t, err)
+	require.Regexp(t, "^\x05(\x00){35,}PK.*sha3-256$", buffer.String())
+
+	err = genesis.Fingerprint(fake.NewBadHashWithDelay(3))
+	require.EqualError(t, err, fake.Err("couldn't write hash algorithm"))
 }
 
 func TestGenesisFactory_Deserialize(t *testing.T) {
@@ -147,7 +235,59 @@ func TestBlock_Fingerprint(t *testing.T) {
 
 	err := block.Fingerprint(buffer)
 	require.NoError(t, err)
-	require.Regexp(t, "^\x03(\x00){7}\x04(\x00){31}$", buffer.String())
+	// [3, h'04..', h'80']
+	require.Equal(t, "8303"+"5820"+"04"+strings.Repeat("00", 31)+"4180",
+		hex.EncodeToString(buffer.Bytes()))
+
+	err = block.Fingerprint(fake.NewBadHash())
+	require.EqualError(t, err, fake.Err("couldn't write block: failed to write"))
+
+	block.data = badData{}
+	err = block.Fingerprint(io.Discard)
+	require.EqualError(t, err, fake.Err("data fingerprint failed"))
+
+	_, err = NewBlock(simple.NewResult(nil),
+		WithHashFactory(fake.NewHashFactory(fake.NewBadHash())))
+	require.EqualError(t, err,
+		fake.Err("fingerprint failed: couldn't write block: failed to write"))
+}
+
+func TestBlock_LegacyFingerprint(t *testing.T) {
+	tx, err := signed.NewTransaction(2, fake.PublicKey{}, signed.WithLegacyFingerprint())
+	require.NoError(t, err)
+
+	data := simple.NewResult([]simple.TransactionResult{simple.NewTransactionResult(tx, true, "")})
+
+	block, err := NewBlock(data, WithIndex(3), WithTreeRoot(Digest{4}))
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), block.GetFormatVersion())
+
+	block, err = NewBlock(data, WithIndex(3), WithTreeRoot(Digest{4}), WithLegacyFingerprint())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), block.GetFormatVersion())
+
+	// The version of the chain wins over the one of the encoding.
+	chainHash := NewChainHashFactory()
+	chainHash.SetFingerprintVersion(canonical.Version)
+
+	other, err := NewBlock(data, WithIndex(3), WithTreeRoot(Digest{4}),
+		WithLegacyFingerprint(), WithHashFactory(chainHash))
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), other.GetFormatVersion())
+
+	chainHash.SetFingerprintVersion(registry.LegacyVersion)
+
+	other, err = NewBlock(data, WithIndex(3), WithTreeRoot(Digest{4}),
+		WithHashFactory(chainHash))
+	require.NoError(t, err)
+	require.Equal(t, block.GetHash(), other.GetHash())
+
+	buffer := new(bytes.Buffer)
+
+	err = block.Fingerprint(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "\x03"+strings.Repeat("\x00", 7)+"\x04"+strings.Repeat("\x00", 31)+
+		"\x02"+strings.Repeat("\x00", 7)+"PK\x01", buffer.String())
 
 	err = block.Fingerprint(fake.NewBadHash())
 	require.EqualError(t, err, fake.Err("couldn't write index"))
@@ -155,12 +295,11 @@ func TestBlock_Fingerprint(t *testing.T) {
 	err = block.Fingerprint(fake.NewBadHashWithDelay(1))
 	require.EqualError(t, err, fake.Err("couldn't write root"))
 
-	block.data = badData{}
-	err = block.Fingerprint(io.Discard)
-	require.EqualError(t, err, fake.Err("data fingerprint failed"))
+	err = block.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't fingerprint tx: couldn't write nonce"))
 
-	_, err = NewBlock(block.data, WithHashFactory(fake.NewHashFactory(fake.NewBadHash())))
-	require.EqualError(t, err, fake.Err("fingerprint failed: couldn't write index"))
+	err = block.Fingerprint(fake.NewBadHashWithDelay(4))
+	require.EqualError(t, err, fake.Err("couldn't write accepted"))
 }
 
 func TestBlock_Serialize(t *testing.T) {
@@ -185,6 +324,15 @@ func TestBlockFactory_Deserialize(t *testing.T) {
 
 	_, err = fac.Deserialize(fake.NewBadContext(), nil)
 	require.EqualError(t, err, fake.Err("decoding block failed"))
+
+	hashFac := crypto.NewHashFactory(crypto.Blake3_256)
+	fac = NewBlockFactory(simple.NewResultFactory(txFac), WithFactoryHash(hashFac))
+
+	_, err = fac.Deserialize(fake.NewContextWithFormat(hashFormat), nil)
+	require.NoError(t, err)
+
+	ctx := hashCalls.Get(hashCalls.Len()-1, 0).(serde.Context)
+	require.Equal(t, hashFac, crypto.HashFactoryOf(ctx))
 }
 
 // -----------------------------------------------------------------------------
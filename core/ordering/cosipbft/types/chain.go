@@ -11,6 +11,7 @@ import (
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
@@ -42,6 +43,9 @@ type forwardLink struct {
 	changeset  authority.ChangeSet
 	prepareSig crypto.Signature
 	commitSig  crypto.Signature
+	// legacy is true when the link is fingerprinted with the encoding that
+	// precedes the canonical one, so that its digest does not change.
+	legacy bool
 }
 
 type linkTemplate struct {
@@ -77,6 +81,16 @@ func WithLinkHashFactory(fac crypto.HashFactory) LinkOption {
 	}
 }
 
+// WithLinkLegacyFingerprint is the option to fingerprint the link with the
+// encoding that precedes the canonical one. It is used to decode the links
+// created before the canonical encoding, and the hash factory of a chain
+// overrides it with the version of the chain.
+func WithLinkLegacyFingerprint() LinkOption {
+	return func(tmpl *linkTemplate) {
+		tmpl.legacy = true
+	}
+}
+
 // NewForwardLink creates a new forward link between the two block digests.
 func NewForwardLink(from, to Digest, opts ...LinkOption) (Link, error) {
 	tmpl := linkTemplate{
@@ -92,6 +106,11 @@ func NewForwardLink(from, to Digest, opts ...LinkOption) (Link, error) {
 		opt(&tmpl)
 	}
 
+	version := registry.FingerprintVersionOf(tmpl.hashFac)
+	if version != 0 {
+		tmpl.legacy = version == registry.LegacyVersion
+	}
+
 	h := tmpl.hashFac.New()
 	err := tmpl.Fingerprint(h)
 	if err != nil {
@@ -136,9 +155,34 @@ func (link forwardLink) GetChangeSet() authority.ChangeSet {
 	return link.changeset
 }
 
-// Fingerprint implements serde.Fingerprinter. It deterministically writes a
-// binary representation of the block link.
+// GetFormatVersion implements registry.VersionedMessage. It returns the legacy
+// version of the format when the link has a legacy fingerprint.
+func (link forwardLink) GetFormatVersion() uint32 {
+	if link.legacy {
+		return registry.LegacyVersion
+	}
+
+	return 0
+}
+
+// Fingerprint implements serde.Fingerprinter. It writes the canonical encoding
+// of the digests of the previous and the next blocks.
 func (link forwardLink) Fingerprint(w io.Writer) error {
+	if link.legacy {
+		return link.legacyFingerprint(w)
+	}
+
+	err := canonical.Write(w, link.from, link.GetTo())
+	if err != nil {
+		return xerrors.Errorf("couldn't write link: %v", err)
+	}
+
+	return nil
+}
+
+// legacyFingerprint writes the concatenation of the digests of the previous and
+// the next blocks.
+func (link forwardLink) legacyFingerprint(w io.Writer) error {
 	_, err := w.Write(link.from[:])
 	if err != nil {
 		return xerrors.Errorf("couldn't write from: %v", err)
@@ -227,18 +271,26 @@ type linkFac struct {
 	blockFac serde.Factory
 	sigFac   crypto.SignatureFactory
 	csFac    authority.ChangeSetFactory
+	hashFac  crypto.HashFactory
 }
 
 // NewLinkFactory creates a new block link factory.
 func NewLinkFactory(
 	blockFac serde.Factory,
 	sigFac crypto.SignatureFactory, csFac authority.ChangeSetFactory,
+	opts ...FactoryOption,
 ) LinkFactory {
 
+	tmpl := factoryTemplate{}
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
 	return linkFac{
 		blockFac: blockFac,
 		sigFac:   sigFac,
 		csFac:    csFac,
+		hashFac:  tmpl.hashFac,
 	}
 }
 
@@ -251,6 +303,10 @@ func (fac linkFac) Deserialize(ctx serde.Context, data []byte) (serde.Message, e
 	ctx = serde.WithFactory(ctx, AggregateKey{}, fac.sigFac)
 	ctx = serde.WithFactory(ctx, ChangeSetKey{}, fac.csFac)
 
+	if fac.hashFac != nil {
+		ctx = crypto.WithHashFactory(ctx, fac.hashFac)
+	}
+
 	msg, err := format.Decode(ctx, data)
 	if err != nil {
 		return nil, xerrors.Errorf("decoding link failed: %v", err)
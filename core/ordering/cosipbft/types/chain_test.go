@@ -2,12 +2,17 @@ package types
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
+	"go.dedis.ch/dela/serde/registry"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -15,6 +20,7 @@ func init() {
 	RegisterLinkFormat(fake.GoodFormat, fake.Format{Msg: blockLink{}})
 	RegisterLinkFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterLinkFormat(serde.Format("badtype"), fake.Format{Msg: fake.Message{}})
+	RegisterLinkFormat(hashFormat, fake.Format{Msg: blockLink{}, Call: hashCalls})
 	RegisterChainFormat(fake.GoodFormat, fake.Format{Msg: chain{}})
 	RegisterChainFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterChainFormat(serde.Format("badtype"), fake.Format{Msg: fake.Message{}})
@@ -40,7 +46,7 @@ func TestForwardLink_New(t *testing.T) {
 	}
 
 	_, err = NewForwardLink(Digest{1}, Digest{2}, opts...)
-	require.EqualError(t, err, fake.Err("failed to fingerprint: couldn't write from"))
+	require.EqualError(t, err, fake.Err("failed to fingerprint: couldn't write link: failed to write"))
 }
 
 func TestForwardLink_GetHash(t *testing.T) {
@@ -102,7 +108,44 @@ func TestForwardLink_Fingerprint(t *testing.T) {
 
 	err = link.Fingerprint(buffer)
 	require.NoError(t, err)
-	require.Regexp(t, "^\x01\x00{31}\x02\x00{31}$", buffer.String())
+	// [h'01..', h'02..']
+	require.Equal(t, "82"+"5820"+"01"+strings.Repeat("00", 31)+"5820"+"02"+strings.Repeat("00", 31),
+		hex.EncodeToString(buffer.Bytes()))
+
+	err = link.Fingerprint(fake.NewBadHash())
+	require.EqualError(t, err, fake.Err("couldn't write link: failed to write"))
+}
+
+func TestForwardLink_LegacyFingerprint(t *testing.T) {
+	link, err := NewForwardLink(Digest{1}, Digest{2})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), link.(forwardLink).GetFormatVersion())
+
+	link, err = NewForwardLink(Digest{1}, Digest{2}, WithLinkLegacyFingerprint())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), link.(forwardLink).GetFormatVersion())
+
+	// The version of the chain wins over the one of the encoding.
+	chainHash := NewChainHashFactory()
+	chainHash.SetFingerprintVersion(canonical.Version)
+
+	other, err := NewForwardLink(Digest{1}, Digest{2}, WithLinkLegacyFingerprint(),
+		WithLinkHashFactory(chainHash))
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), other.(forwardLink).GetFormatVersion())
+
+	chainHash.SetFingerprintVersion(registry.LegacyVersion)
+
+	other, err = NewForwardLink(Digest{1}, Digest{2}, WithLinkHashFactory(chainHash))
+	require.NoError(t, err)
+	require.Equal(t, link.GetHash(), other.GetHash())
+
+	buffer := new(bytes.Buffer)
+
+	err = link.Fingerprint(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "\x01"+strings.Repeat("\x00", 31)+"\x02"+strings.Repeat("\x00", 31),
+		buffer.String())
 
 	err = link.Fingerprint(fake.NewBadHash())
 	require.EqualError(t, err, fake.Err("couldn't write from"))
@@ -120,7 +163,8 @@ func TestBlockLink_New(t *testing.T) {
 
 	_, err = NewBlockLink(Digest{}, Block{}, opt)
 	require.EqualError(t, err,
-		fake.Err("creating forward link: failed to fingerprint: couldn't write from"))
+		fake.Err("creating forward link: failed to fingerprint: couldn't write link: "+
+			"failed to write"))
 }
 
 func TestBlockLink_GetBlock(t *testing.T) {
@@ -183,6 +227,16 @@ func TestLinkFac_BlockLinkOf(t *testing.T) {
 
 	_, err = fac.BlockLinkOf(fake.NewContextWithFormat(serde.Format("badtype")), nil)
 	require.EqualError(t, err, "invalid block link 'fake.Message'")
+
+	hashFac := crypto.NewHashFactory(crypto.Sha3_256)
+	fac = NewLinkFactory(BlockFactory{}, fake.SignatureFactory{}, csFac,
+		WithFactoryHash(hashFac))
+
+	_, err = fac.BlockLinkOf(fake.NewContextWithFormat(hashFormat), nil)
+	require.NoError(t, err)
+
+	ctx := hashCalls.Get(hashCalls.Len()-1, 0).(serde.Context)
+	require.Equal(t, hashFac, crypto.HashFactoryOf(ctx))
 }
 
 func TestChain_GetLinks(t *testing.T) {
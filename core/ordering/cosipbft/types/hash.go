@@ -0,0 +1,69 @@
+// This file contains the implementation of the hash factory of a chain.
+
+package types
+
+import (
+	"hash"
+	"sync"
+
+	"go.dedis.ch/dela/crypto"
+)
+
+// ChainHashFactory is the hash factory of a chain. It uses the algorithm
+// recorded in the genesis block, and SHA-256 until it is known, so that the
+// digests of the blocks, the tree and the transactions are the same for every
+// node of the chain. It also fixes the version of the fingerprints of the
+// messages to the one of the genesis block, whatever the version of their
+// encoding.
+//
+// - implements crypto.HashFactory
+// - implements registry.FingerprintVersioner
+type ChainHashFactory struct {
+	sync.RWMutex
+	algorithm crypto.HashAlgorithm
+	version   uint32
+}
+
+// NewChainHashFactory creates a new hash factory using SHA-256.
+func NewChainHashFactory() *ChainHashFactory {
+	return &ChainHashFactory{
+		algorithm: crypto.Sha256,
+	}
+}
+
+// GetAlgorithm returns the current algorithm.
+func (f *ChainHashFactory) GetAlgorithm() crypto.HashAlgorithm {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.algorithm
+}
+
+// SetAlgorithm sets the algorithm of the chain.
+func (f *ChainHashFactory) SetAlgorithm(algo crypto.HashAlgorithm) {
+	f.Lock()
+	f.algorithm = algo
+	f.Unlock()
+}
+
+// GetFingerprintVersion implements registry.FingerprintVersioner. It returns
+// the version of the fingerprints of the chain, or zero until it is known.
+func (f *ChainHashFactory) GetFingerprintVersion() uint32 {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.version
+}
+
+// SetFingerprintVersion sets the version of the fingerprints of the chain.
+func (f *ChainHashFactory) SetFingerprintVersion(version uint32) {
+	f.Lock()
+	f.version = version
+	f.Unlock()
+}
+
+// New implements crypto.HashFactory. It returns a new hash of the algorithm of
+// the chain.
+func (f *ChainHashFactory) New() hash.Hash {
+	return crypto.NewHashFactory(f.GetAlgorithm()).New()
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde/registry"
+)
+
+func TestChainHashFactory_New(t *testing.T) {
+	fac := NewChainHashFactory()
+	require.Equal(t, crypto.Sha256, fac.GetAlgorithm())
+
+	h := fac.New()
+	h.Write([]byte("abc"))
+
+	expected := crypto.NewHashFactory(crypto.Sha256).New()
+	expected.Write([]byte("abc"))
+	require.Equal(t, expected.Sum(nil), h.Sum(nil))
+
+	fac.SetAlgorithm(crypto.Blake2b256)
+	require.Equal(t, crypto.Blake2b256, fac.GetAlgorithm())
+
+	h = fac.New()
+	h.Write([]byte("abc"))
+
+	expected = crypto.NewHashFactory(crypto.Blake2b256).New()
+	expected.Write([]byte("abc"))
+	require.Equal(t, expected.Sum(nil), h.Sum(nil))
+}
+
+func TestChainHashFactory_FingerprintVersion(t *testing.T) {
+	fac := NewChainHashFactory()
+	require.Equal(t, uint32(0), registry.FingerprintVersionOf(fac))
+
+	fac.SetFingerprintVersion(registry.LegacyVersion)
+	require.Equal(t, registry.LegacyVersion, fac.GetFingerprintVersion())
+	require.Equal(t, registry.LegacyVersion, registry.FingerprintVersionOf(fac))
+}
@@ -13,6 +13,7 @@ import (
 
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/mino"
 )
 
 // Proof contains the value of a specific key.
@@ -24,10 +25,22 @@ type Proof interface {
 	GetValue() []byte
 }
 
+// Participant tells which phases of the consensus a member of the roster has
+// signed for a block.
+type Participant struct {
+	Address   mino.Address
+	Prepared  bool
+	Committed bool
+}
+
 // Event describes the current state of the service after an update.
 type Event struct {
 	Index        uint64
 	Transactions []validation.TransactionResult
+
+	// Participants is the list of the roster members with their participation
+	// to the block, when the service supports it.
+	Participants []Participant
 }
 
 // Service is the interface of an ordering service. It provides the primitives
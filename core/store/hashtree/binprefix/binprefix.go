@@ -59,14 +59,31 @@ type MerkleTree struct {
 	hashFactory crypto.HashFactory
 }
 
+// TreeOption is the type of option to set some fields of the tree.
+type TreeOption func(*MerkleTree)
+
+// WithHashFactory is an option to set the hash factory of the nodes of the
+// tree.
+func WithHashFactory(fac crypto.HashFactory) TreeOption {
+	return func(t *MerkleTree) {
+		t.hashFactory = fac
+	}
+}
+
 // NewMerkleTree creates a new Merkle tree-based storage.
-func NewMerkleTree(db kv.DB, nonce Nonce) *MerkleTree {
-	return &MerkleTree{
+func NewMerkleTree(db kv.DB, nonce Nonce, opts ...TreeOption) *MerkleTree {
+	t := &MerkleTree{
 		tree:        NewTree(nonce),
 		db:          db,
 		bucket:      []byte("hashtree"),
 		hashFactory: crypto.NewHashFactory(crypto.Sha256),
 	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
 }
 
 // Load tries to read the bucket and scan it for existing leafs and populate the
@@ -146,6 +163,35 @@ func (t *MerkleTree) GetPath(key []byte) (hashtree.Path, error) {
 	return path, nil
 }
 
+// WalkLeaves calls the function with the key and the value of every leaf that
+// is committed to the disk, which is the state of the last commit. The order
+// of the leaves is not specified.
+func (t *MerkleTree) WalkLeaves(fn func(key, value []byte) error) error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.doView(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(t.bucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Scan([]byte{}, func(key, value []byte) error {
+			msg, err := t.tree.factory.Deserialize(t.tree.context, value)
+			if err != nil {
+				return xerrors.Errorf("tree node malformed: %v", err)
+			}
+
+			leaf, ok := msg.(*LeafNode)
+			if !ok {
+				return nil
+			}
+
+			return fn(leaf.GetKey(), leaf.GetValue())
+		})
+	})
+}
+
 // Stage implements hashtree.Tree. It executes the callback over a clone of the
 // current tree and returns the clone with the root calculated.
 func (t *MerkleTree) Stage(fn func(store.Snapshot) error) (hashtree.StagingTree, error) {
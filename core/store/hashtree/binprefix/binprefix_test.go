@@ -231,6 +231,26 @@ func TestMerkleTree_GetRoot(t *testing.T) {
 	require.NotEqual(t, empty.GetRoot(), filled.GetRoot())
 }
 
+func TestMerkleTree_WithHashFactory_GetRoot(t *testing.T) {
+	tree := NewMerkleTree(fakeDB{}, Nonce{},
+		WithHashFactory(crypto.NewHashFactory(crypto.Blake3_256)))
+
+	root, err := tree.Stage(func(snap store.Snapshot) error {
+		return snap.Set([]byte("ping"), []byte("pong"))
+	})
+	require.NoError(t, err)
+
+	other, err := NewMerkleTree(fakeDB{}, Nonce{}).Stage(func(snap store.Snapshot) error {
+		return snap.Set([]byte("ping"), []byte("pong"))
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, other.GetRoot(), root.GetRoot())
+
+	path, err := root.GetPath([]byte("ping"))
+	require.NoError(t, err)
+	require.Equal(t, root.GetRoot(), path.GetRoot())
+}
+
 func TestMerkleTree_GetPath(t *testing.T) {
 	tree := NewMerkleTree(fakeDB{}, Nonce{})
 
@@ -258,6 +278,58 @@ func TestMerkleTree_GetPath(t *testing.T) {
 	require.EqualError(t, err, couldntError("search key"))
 }
 
+func TestMerkleTree_WalkLeaves(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	tree := NewMerkleTree(db, Nonce{})
+
+	leaves := map[string]string{}
+	walk := func(key, value []byte) error {
+		leaves[string(key)] = string(value)
+		return nil
+	}
+
+	err := tree.WalkLeaves(walk)
+	require.NoError(t, err)
+	require.Empty(t, leaves)
+
+	stage, err := tree.Stage(func(snap store.Snapshot) error {
+		for _, key := range []string{"A", "B", "C"} {
+			err := snap.Set([]byte(key), []byte("value"+key))
+			require.NoError(t, err)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The leaves of the staged tree are only visible once committed.
+	err = stage.(*MerkleTree).WalkLeaves(walk)
+	require.NoError(t, err)
+	require.Empty(t, leaves)
+
+	require.NoError(t, stage.Commit())
+
+	err = stage.(*MerkleTree).WalkLeaves(walk)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"A": "valueA", "B": "valueB", "C": "valueC"}, leaves)
+
+	err = tree.WalkLeaves(func(key, value []byte) error {
+		return fake.GetError()
+	})
+	require.EqualError(t, err, fake.GetError().Error())
+
+	tree.tx = fakeTx{bucket: newFakeBucket([]byte("A"), []byte("{}"))}
+	err = tree.WalkLeaves(walk)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "tree node malformed: ")
+
+	tree.tx = wrongTx{}
+	err = tree.WalkLeaves(walk)
+	require.EqualError(t, err, "transaction 'binprefix.wrongTx' is not readable")
+}
+
 func TestMerkleTree_Stage(t *testing.T) {
 	tree := NewMerkleTree(fakeDB{}, Nonce{})
 
@@ -36,6 +36,15 @@ type NodeJSON struct {
 	Empty    *EmptyNodeJSON    `json:",omitempty"`
 }
 
+// PathJSON is the JSON representation of a path. The root is not part of it as
+// it is computed from the other fields.
+type PathJSON struct {
+	Nonce     []byte
+	Key       []byte
+	Value     []byte
+	Interiors [][]byte
+}
+
 type nodeFormat struct{}
 
 func (f nodeFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
@@ -121,3 +130,43 @@ func (f nodeFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error
 
 	return nil, xerrors.New("message is empty")
 }
+
+type pathFormat struct{}
+
+func (f pathFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	path, ok := msg.(Path)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	m := PathJSON{
+		Nonce:     path.nonce,
+		Key:       path.key,
+		Value:     path.value,
+		Interiors: path.interiors,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f pathFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := PathJSON{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	path := Path{
+		nonce:     m.Nonce,
+		key:       m.Key,
+		value:     m.Value,
+		interiors: m.Interiors,
+	}
+
+	return path, nil
+}
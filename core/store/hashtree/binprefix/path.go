@@ -8,14 +8,19 @@ import (
 	"math/big"
 
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
+var pathFormats = registry.NewSimpleRegistry()
+
 // Path is a path from the root to a leaf, represented as a series of interior
 // nodes hashes. The end of the path is either a leaf with a key holding a
 // value, or an empty node.
 //
 // - implements hashtree.Path
+// - implements serde.Message
 type Path struct {
 	nonce []byte
 	key   []byte
@@ -51,6 +56,19 @@ func (s Path) GetRoot() []byte {
 	return s.root
 }
 
+// Serialize implements serde.Message. It returns the data of the path, without
+// the root which is computed again when deserializing.
+func (s Path) Serialize(ctx serde.Context) ([]byte, error) {
+	format := pathFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, s)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	return data, nil
+}
+
 func (s Path) computeRoot(fac crypto.HashFactory) ([]byte, error) {
 	key := new(big.Int)
 	key.SetBytes(s.key)
@@ -89,3 +107,47 @@ func (s Path) computeRoot(fac crypto.HashFactory) ([]byte, error) {
 
 	return curr, nil
 }
+
+// PathFactory is the factory to deserialize paths. The root of a path is
+// computed from the leaf and the interior nodes, so that it can be compared to
+// the root of a trusted tree.
+//
+// - implements serde.Factory
+type PathFactory struct {
+	hashFactory crypto.HashFactory
+}
+
+// NewPathFactory returns a new factory of paths that computes the roots with
+// the hash factory of the tree.
+func NewPathFactory(fac crypto.HashFactory) PathFactory {
+	return PathFactory{
+		hashFactory: fac,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the path associated to
+// the data if appropriate, otherwise it returns an error.
+func (f PathFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := pathFormats.Get(ctx.GetFormat())
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	path, ok := msg.(Path)
+	if !ok {
+		return nil, xerrors.Errorf("invalid path '%T'", msg)
+	}
+
+	if len(path.interiors) >= MaxDepth*8 {
+		return nil, xerrors.Errorf("path too long: %d", len(path.interiors))
+	}
+
+	path.root, err = path.computeRoot(f.hashFactory)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to compute root: %v", err)
+	}
+
+	return path, nil
+}
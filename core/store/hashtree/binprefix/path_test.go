@@ -1,12 +1,22 @@
 package binprefix
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/cbor"
+	"go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/dela/testing/fake"
 )
 
+func init() {
+	pathFormats.Register(fake.BadFormat, fake.NewBadFormat())
+	pathFormats.Register(fake.GoodFormat, fake.Format{Msg: fake.Message{}})
+}
+
 func TestPath_GetKey(t *testing.T) {
 	path := newPath([]byte{}, []byte("ping"))
 
@@ -41,3 +51,56 @@ func TestPath_ComputeRoot(t *testing.T) {
 	_, err = path.computeRoot(fake.NewHashFactory(fake.NewBadHash()))
 	require.EqualError(t, err, fake.Err("while preparing: empty node failed"))
 }
+
+func TestPath_Serialize(t *testing.T) {
+	tree := NewMerkleTree(fakeDB{}, Nonce{1})
+	fac := crypto.NewHashFactory(crypto.Sha256)
+
+	for _, key := range []string{"A", "B", "C", "D"} {
+		err := tree.tree.Insert([]byte(key), []byte(key), &fakeBucket{})
+		require.NoError(t, err)
+	}
+
+	err := tree.tree.CalculateRoot(fac, &fakeBucket{})
+	require.NoError(t, err)
+
+	for _, ctx := range []serde.Context{json.NewContext(), cbor.NewContext()} {
+		for _, key := range []string{"A", "C"} {
+			path, err := tree.GetPath([]byte(key))
+			require.NoError(t, err)
+
+			data, err := path.(Path).Serialize(ctx)
+			require.NoError(t, err)
+
+			msg, err := NewPathFactory(fac).Deserialize(ctx, data)
+			require.NoError(t, err)
+			require.Equal(t, path, msg)
+			require.Equal(t, tree.GetRoot(), msg.(Path).GetRoot())
+		}
+	}
+
+	_, err = Path{}.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("failed to encode"))
+}
+
+func TestPathFactory_Deserialize(t *testing.T) {
+	fac := NewPathFactory(crypto.NewHashFactory(crypto.Sha256))
+
+	_, err := fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("failed to decode"))
+
+	_, err = fac.Deserialize(fake.NewContext(), nil)
+	require.EqualError(t, err, "invalid path 'fake.Message'")
+
+	_, err = fac.Deserialize(json.NewContext(), []byte("{}"))
+	require.NoError(t, err)
+
+	_, err = fac.Deserialize(json.NewContext(), []byte(`{"Interiors":[`+
+		strings.Repeat(`"",`, MaxDepth*8)+`""]}`))
+	require.EqualError(t, err, "path too long: 257")
+
+	fac = NewPathFactory(fake.NewHashFactory(fake.NewBadHash()))
+
+	_, err = fac.Deserialize(json.NewContext(), []byte("{}"))
+	require.EqualError(t, err, fake.Err("failed to compute root: while preparing: empty node failed"))
+}
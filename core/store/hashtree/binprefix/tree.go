@@ -19,6 +19,9 @@ import (
 
 func init() {
 	nodeFormats.Register(serde.FormatJSON, nodeFormat{})
+	nodeFormats.Register(serde.FormatCBOR, nodeFormat{})
+	pathFormats.Register(serde.FormatJSON, pathFormat{})
+	pathFormats.Register(serde.FormatCBOR, pathFormat{})
 }
 
 // Nonce is the type of the tree nonce.
@@ -9,11 +9,16 @@ package kv
 
 import (
 	"bytes"
+	"time"
 
 	"go.etcd.io/bbolt"
 	"golang.org/x/xerrors"
 )
 
+// readOnlyTimeout is the time to wait for the lock of a database opened in
+// read-only mode, which is held for as long as a node runs.
+const readOnlyTimeout = time.Second
+
 // BoltDB is an adapter of the KV database using bboltdb.
 //
 // - implements kv.DB
@@ -35,6 +40,31 @@ func New(path string) (DB, error) {
 	return bdb, nil
 }
 
+// NewReadOnly opens an existing database in read-only mode, so that it can be
+// inspected without being modified. It fails if the database is in use by a
+// running node.
+func NewReadOnly(path string) (DB, error) {
+	opts := &bbolt.Options{
+		ReadOnly: true,
+		Timeout:  readOnlyTimeout,
+	}
+
+	db, err := bbolt.Open(path, 0400, opts)
+	if err == bbolt.ErrTimeout {
+		return nil, xerrors.New("database is in use")
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open db: %v", err)
+	}
+
+	bdb := boltDB{
+		bolt: db,
+	}
+
+	return bdb, nil
+}
+
 // View implements kv.DB. It executes the read-only transaction in the context
 // of the database.
 func (db boltDB) View(fn func(ReadableTx) error) error {
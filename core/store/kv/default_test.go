@@ -64,6 +64,49 @@ func TestBoltDB_Close(t *testing.T) {
 	require.Error(t, db.(boltDB).bolt.Sync())
 }
 
+func TestBoltDB_NewReadOnly(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), delaTestDir)
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.db")
+
+	_, err = NewReadOnly(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to open db: ")
+
+	db, err := New(path)
+	require.NoError(t, err)
+
+	err = db.Update(func(txn WritableTx) error {
+		bucket, err := txn.GetBucketOrCreate([]byte("bucket"))
+		require.NoError(t, err)
+
+		return bucket.Set([]byte("ping"), []byte("pong"))
+	})
+	require.NoError(t, err)
+
+	_, err = NewReadOnly(path)
+	require.EqualError(t, err, "database is in use")
+
+	require.NoError(t, db.Close())
+
+	db, err = NewReadOnly(path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	err = db.View(func(txn ReadableTx) error {
+		require.Equal(t, []byte("pong"), txn.GetBucket([]byte("bucket")).Get([]byte("ping")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.Update(func(txn WritableTx) error { return nil })
+	require.EqualError(t, err, "database is in read-only mode")
+}
+
 func TestBoltTx_GetBucket(t *testing.T) {
 	dir, err := os.MkdirTemp(os.TempDir(), delaTestDir)
 	require.NoError(t, err)
@@ -6,11 +6,13 @@
 package controller
 
 import (
+	"io"
 	"sync"
 
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/remote"
 
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/txn"
@@ -21,8 +23,10 @@ import (
 
 // getManager is the function called when we need a transaction manager. It
 // allows us to use a different manager for the tests.
-var getManager = func(signer crypto.Signer, s signed.Client) txn.Manager {
-	return signed.NewManager(signer, s)
+var getManager = func(signer crypto.Signer, s signed.Client,
+	opts ...signed.ManagerOption) txn.Manager {
+
+	return signed.NewManager(signer, s, opts...)
 }
 
 // addAction describes an action to add a new transaction to the pool.
@@ -55,12 +59,26 @@ func (a *addAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("failed to get signer: %v", err)
 	}
 
+	closer, ok := signer.(io.Closer)
+	if ok {
+		defer closer.Close()
+	}
+
 	nonce := ctx.Flags.Int(nonceFlag)
 	if nonce != -1 {
 		a.client.nonce = uint64(nonce)
 	}
 
-	manager := getManager(signer, a.client)
+	// The ID of the transaction is computed with the hash algorithm of the
+	// chain when the ordering service provides it.
+	var opts []signed.ManagerOption
+
+	var hashFac crypto.HashFactory
+	if ctx.Injector.Resolve(&hashFac) == nil {
+		opts = append(opts, signed.WithManagerHash(hashFac))
+	}
+
+	manager := getManager(signer, a.client, opts...)
 
 	tx, err := manager.Make(args...)
 	if err != nil {
@@ -93,8 +111,23 @@ func getArgs(ctx node.Context) ([]txn.Arg, error) {
 	return args, nil
 }
 
-// getSigner creates a signer from the signerFlag flag in context.
+// getSigner creates a signer from the signerFlag flag in context, or a remote
+// signer if the socket of a signer daemon is given instead.
 func getSigner(ctx node.Context) (crypto.Signer, error) {
+	socket := ctx.Flags.Path(socketFlag)
+	if socket != "" {
+		signer, err := remote.NewSigner(socket, bls.NewSigner())
+		if err != nil {
+			return nil, xerrors.Errorf("remote signer: %v", err)
+		}
+
+		return signer.Unscoped(), nil
+	}
+
+	if ctx.Flags.Path(signerFlag) == "" {
+		return nil, xerrors.Errorf("one of --%s and --%s is required", signerFlag, socketFlag)
+	}
+
 	l := loader.NewFileLoader(ctx.Flags.Path(signerFlag))
 
 	signerdata, err := l.Load()
@@ -9,12 +9,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/core/txn/pool/mem"
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/remote"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -48,7 +50,7 @@ func TestExecute(t *testing.T) {
 	err = action.Execute(ctx)
 	require.EqualError(t, err, "failed to include tx: "+fake.Err("failed to add"))
 
-	getManager = func(c crypto.Signer, s signed.Client) txn.Manager {
+	getManager = func(c crypto.Signer, s signed.Client, opts ...signed.ManagerOption) txn.Manager {
 		return badManager{failSync: true}
 	}
 
@@ -80,6 +82,38 @@ func TestExecute(t *testing.T) {
 	require.EqualError(t, err, "injector: couldn't find dependency for 'pool.Pool'")
 }
 
+func TestGetSigner_Remote(t *testing.T) {
+	dir := t.TempDir()
+
+	key := bls.NewSigner()
+
+	guard, err := remote.NewGuard("")
+	require.NoError(t, err)
+
+	srv := remote.NewServer(key, guard, pbft.NewScopeVerifier(), remote.WithUnscoped())
+	require.NoError(t, srv.Listen(filepath.Join(dir, "signer.sock")))
+
+	defer srv.Close()
+
+	ctx := node.Context{
+		Flags: node.FlagSet{socketFlag: filepath.Join(dir, "signer.sock")},
+	}
+
+	signer, err := getSigner(ctx)
+	require.NoError(t, err)
+	require.True(t, key.GetPublicKey().Equal(signer.GetPublicKey()))
+
+	ctx.Flags = node.FlagSet{socketFlag: filepath.Join(dir, "unknown.sock")}
+
+	_, err = getSigner(ctx)
+	require.Regexp(t, "^remote signer: failed to get public key: ", err)
+
+	ctx.Flags = node.FlagSet{}
+
+	_, err = getSigner(ctx)
+	require.EqualError(t, err, "one of --key and --signer-socket is required")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -13,6 +13,10 @@ const (
 	// signerFlag is the flag name containing the path to the private keyfile.
 	signerFlag = "key"
 
+	// socketFlag is the flag name containing the path to the socket of a
+	// signer daemon.
+	socketFlag = "signer-socket"
+
 	// nonceFlag is the flag name containing the nonce.
 	nonceFlag = "nonce"
 )
@@ -46,7 +50,11 @@ func (miniController) SetCommands(builder node.Builder) {
 	}, cli.StringFlag{
 		Name:     signerFlag,
 		Usage:    "path to the private keyfile",
-		Required: true,
+		Required: false,
+	}, cli.StringFlag{
+		Name:     socketFlag,
+		Usage:    "path to the socket of a signer daemon, instead of --key",
+		Required: false,
 	})
 	sub.SetAction(builder.MakeAction(&addAction{
 		client: &client{},
@@ -3,9 +3,9 @@ package pool
 import (
 	"context"
 	"sync"
-	"time"
 
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"golang.org/x/xerrors"
@@ -58,6 +58,7 @@ type simpleGatherer struct {
 	limit      int
 	queue      []item
 	validators []Filter
+	clock      clock.Clock
 
 	// A string key is generated for each unique identity, which will have its
 	// own list of transactions, so that a limited size can be enforced
@@ -65,12 +66,30 @@ type simpleGatherer struct {
 	txs map[string]transactions
 }
 
+// GathererOption is the type of option to set some fields of a gatherer.
+type GathererOption func(*simpleGatherer)
+
+// WithClock is an option to set the clock used to timestamp the transactions
+// for the statistics.
+func WithClock(c clock.Clock) GathererOption {
+	return func(g *simpleGatherer) {
+		g.clock = c
+	}
+}
+
 // NewSimpleGatherer creates a new gatherer.
-func NewSimpleGatherer() Gatherer {
-	return &simpleGatherer{
+func NewSimpleGatherer(opts ...GathererOption) Gatherer {
+	g := &simpleGatherer{
 		limit: DefaultIdentitySize,
 		txs:   make(map[string]transactions),
+		clock: clock.NewSystem(),
 	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
 }
 
 // AddFilter implements pool.Gatherer. It adds the filter to the list that a
@@ -105,7 +124,7 @@ func (g *simpleGatherer) Add(tx txn.Transaction) error {
 
 	g.txs[key] = g.txs[key].Add(transactionStats{
 		tx,
-		time.Now(),
+		g.clock.Now(),
 	})
 
 	g.notify(g.calculateLength())
@@ -170,7 +189,7 @@ func (g *simpleGatherer) Stats() Stats {
 	txs := g.makeStatsArray()
 	stats := Stats{
 		TxCount:  len(txs),
-		OldestTx: time.Now(),
+		OldestTx: g.clock.Now(),
 	}
 
 	for _, tx := range txs {
@@ -187,9 +206,11 @@ func (g *simpleGatherer) ResetStats() {
 	g.Lock()
 	defer g.Unlock()
 
+	now := g.clock.Now()
+
 	txs := g.makeStatsArray()
 	for _, tx := range txs {
-		tx.ResetStats()
+		tx.ResetStats(now)
 	}
 }
 
@@ -4,9 +4,11 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/testing/fake"
@@ -23,6 +25,23 @@ func TestSimpleGatherer_Len(t *testing.T) {
 	require.Equal(t, 3, gatherer.Stats().TxCount)
 }
 
+func TestSimpleGatherer_Stats(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clk := clock.NewVirtual(start)
+
+	gatherer := NewSimpleGatherer(WithClock(clk)).(*simpleGatherer)
+	require.Equal(t, start, gatherer.Stats().OldestTx)
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+
+	clk.Advance(time.Minute)
+	require.NoError(t, gatherer.Add(newTx(0, "Bob")))
+
+	stats := gatherer.Stats()
+	require.Equal(t, 2, stats.TxCount)
+	require.Equal(t, start, stats.OldestTx)
+}
+
 func TestSimpleGatherer_Add(t *testing.T) {
 	gatherer := NewSimpleGatherer().(*simpleGatherer)
 	gatherer.AddFilter(nil)
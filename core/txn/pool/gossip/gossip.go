@@ -26,7 +26,8 @@ type Pool struct {
 }
 
 // NewPool creates a new empty pool and starts to gossip incoming transaction.
-func NewPool(gossiper gossip.Gossiper) (*Pool, error) {
+// The options are applied to the gatherer of the transactions.
+func NewPool(gossiper gossip.Gossiper, opts ...pool.GathererOption) (*Pool, error) {
 	actor, err := gossiper.Listen()
 	if err != nil {
 		return nil, xerrors.Errorf("failed to listen: %v", err)
@@ -35,7 +36,7 @@ func NewPool(gossiper gossip.Gossiper) (*Pool, error) {
 	p := &Pool{
 		logger:   dela.Logger,
 		actor:    actor,
-		gatherer: pool.NewSimpleGatherer(),
+		gatherer: pool.NewSimpleGatherer(opts...),
 		closing:  make(chan struct{}),
 	}
 
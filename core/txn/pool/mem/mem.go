@@ -17,10 +17,11 @@ type Pool struct {
 	gatherer pool.Gatherer
 }
 
-// NewPool creates a new service.
-func NewPool() *Pool {
+// NewPool creates a new service. The options are applied to the gatherer of
+// the transactions.
+func NewPool(opts ...pool.GathererOption) *Pool {
 	return &Pool{
-		gatherer: pool.NewSimpleGatherer(),
+		gatherer: pool.NewSimpleGatherer(opts...),
 	}
 }
 
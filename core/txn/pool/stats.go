@@ -13,8 +13,8 @@ type transactionStats struct {
 	insertionTime time.Time
 }
 
-// ResetStats resets the insertion time to now.
+// ResetStats resets the insertion time to the given time.
 // It is used when a leader view change is initiated.
-func (t *transactionStats) ResetStats() {
-	t.insertionTime = time.Now()
+func (t *transactionStats) ResetStats(now time.Time) {
+	t.insertionTime = now
 }
@@ -12,6 +12,7 @@ import (
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
 )
 
 // MgrController is a CLI controller that will inject a transaction manager
@@ -50,10 +51,28 @@ func (mgrController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return err
 	}
 
-	mgr := signed.NewManager(c.GetSigner(), client{
+	// The ID of the transactions is computed with the hash algorithm of the
+	// chain when the ordering service provides it.
+	var opts []signed.ManagerOption
+
+	var hashFac crypto.HashFactory
+	if inj.Resolve(&hashFac) == nil {
+		opts = append(opts, signed.WithManagerHash(hashFac))
+	}
+
+	// The transactions have no scope, therefore they are signed with the
+	// unscoped signer when the signer refuses them otherwise.
+	signer := c.GetSigner()
+
+	unscoped, ok := signer.(crypto.UnscopedSigner)
+	if ok {
+		signer = unscoped.Unscoped()
+	}
+
+	mgr := signed.NewManager(signer, client{
 		srvc: srvc,
 		mgr:  nonceMgr,
-	})
+	}, opts...)
 
 	inj.Inject(mgr)
 
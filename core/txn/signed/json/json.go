@@ -7,11 +7,22 @@ import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/common"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
+// The transactions are stored in the blocks, therefore their format is
+// versioned so that the data of the older versions can still be decoded after
+// an upgrade. The version 2 introduces the canonical fingerprint, and the
+// transactions of the version 1 keep the legacy one.
+var txVersions = registry.NewVersionedFormat("signed.Transaction")
+
 func init() {
-	signed.RegisterTransactionFormat(serde.FormatJSON, txFormat{})
+	txVersions.Register(1, txFormat{legacy: true})
+	txVersions.Register(2, txFormat{})
+
+	signed.RegisterTransactionFormat(serde.FormatJSON, txVersions)
+	signed.RegisterTransactionFormat(serde.FormatCBOR, txVersions)
 }
 
 // TransactionJSON is the JSON message of a transaction.
@@ -27,6 +38,7 @@ type TransactionJSON struct {
 // - implements serde.FormatEngine
 type txFormat struct {
 	hashFactory crypto.HashFactory
+	legacy      bool
 }
 
 // Encode implements serde.FormatEngine. It returns the JSON data of the
@@ -90,15 +102,24 @@ func (fmt txFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error
 		return nil, xerrors.Errorf("signature: %v", err)
 	}
 
-	args := make([]signed.TransactionOption, 0, len(m.Args)+2)
+	args := make([]signed.TransactionOption, 0, len(m.Args)+3)
 	for key, value := range m.Args {
 		args = append(args, signed.WithArg(key, value))
 	}
 
 	args = append(args, signed.WithSignature(sig))
 
-	if fmt.hashFactory != nil {
-		args = append(args, signed.WithHashFactory(fmt.hashFactory))
+	if fmt.legacy {
+		args = append(args, signed.WithLegacyFingerprint())
+	}
+
+	hashFactory := fmt.hashFactory
+	if hashFactory == nil {
+		hashFactory = crypto.HashFactoryOf(ctx)
+	}
+
+	if hashFactory != nil {
+		args = append(args, signed.WithHashFactory(hashFactory))
 	}
 
 	tx, err := signed.NewTransaction(m.Nonce, pubkey, args...)
@@ -52,13 +52,23 @@ func TestTxFormat_Decode(t *testing.T) {
 	expected := makeTx(t, 2, fake.PublicKey{}, signed.WithArg("B", []byte{1}))
 	require.Equal(t, expected, msg)
 
+	format.legacy = true
+	msg, err = format.Decode(ctx, []byte(`{"Nonce":2,"Args":{"B":"AQ=="}}`))
+	require.NoError(t, err)
+	expected = makeTx(t, 2, fake.PublicKey{}, signed.WithArg("B", []byte{1}),
+		signed.WithLegacyFingerprint())
+	require.Equal(t, expected, msg)
+	require.Equal(t, uint32(1), msg.(*signed.Transaction).GetFormatVersion())
+
+	format.legacy = false
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal"))
 
 	format.hashFactory = fake.NewHashFactory(fake.NewBadHash())
 	_, err = format.Decode(ctx, []byte(`{}`))
-	require.EqualError(t, err,
-		fake.Err("failed to create tx: couldn't fingerprint tx: couldn't write nonce"))
+	require.EqualError(t, err, fake.Err("failed to create tx: couldn't fingerprint tx: "+
+		"couldn't write transaction: failed to write"))
 
 	badCtx := serde.WithFactory(ctx, signed.PublicKeyFac{}, nil)
 	_, err = format.Decode(badCtx, []byte(`{}`))
@@ -75,6 +85,13 @@ func TestTxFormat_Decode(t *testing.T) {
 	badCtx = serde.WithFactory(ctx, signed.SignatureFac{}, fake.NewBadSignatureFactory())
 	_, err = format.Decode(badCtx, []byte(`{}`))
 	require.EqualError(t, err, fake.Err("signature: malformed"))
+
+	hashFac := crypto.NewHashFactory(crypto.Sha3_256)
+	format.hashFactory = nil
+	msg, err = format.Decode(crypto.WithHashFactory(ctx, hashFac), []byte(`{"Nonce":2}`))
+	require.NoError(t, err)
+	expected = makeTx(t, 2, fake.PublicKey{}, signed.WithHashFactory(hashFac))
+	require.Equal(t, expected.GetID(), msg.(txn.Transaction).GetID())
 }
 
 // -----------------------------------------------------------------------------
@@ -18,6 +18,7 @@ import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/common"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
@@ -39,6 +40,9 @@ type Transaction struct {
 	pubkey crypto.PublicKey
 	sig    crypto.Signature
 	hash   []byte
+	// legacy is true when the transaction is fingerprinted with the encoding
+	// that precedes the canonical one, so that its identifier does not change.
+	legacy bool
 }
 
 type template struct {
@@ -73,6 +77,16 @@ func WithHashFactory(f crypto.HashFactory) TransactionOption {
 	}
 }
 
+// WithLegacyFingerprint is an option to fingerprint the transaction with the
+// encoding that precedes the canonical one. It is used to decode the
+// transactions created before the canonical encoding, and the hash factory of a
+// chain overrides it with the version of the chain.
+func WithLegacyFingerprint() TransactionOption {
+	return func(tmpl *template) {
+		tmpl.legacy = true
+	}
+}
+
 // NewTransaction creates a new transaction with the provided nonce.
 func NewTransaction(nonce uint64, pk crypto.PublicKey, opts ...TransactionOption) (
 	*Transaction,
@@ -91,6 +105,13 @@ func NewTransaction(nonce uint64, pk crypto.PublicKey, opts ...TransactionOption
 		opt(&tmpl)
 	}
 
+	// The identifier follows the version of the chain when it is known, so
+	// that it doesn't depend on the version of the encoding.
+	version := registry.FingerprintVersionOf(tmpl.hashFactory)
+	if version != 0 {
+		tmpl.legacy = version == registry.LegacyVersion
+	}
+
 	h := tmpl.hashFactory.New()
 	err := tmpl.Fingerprint(h)
 	if err != nil {
@@ -165,9 +186,46 @@ func (t *Transaction) Sign(signer crypto.Signer) error {
 	return nil
 }
 
-// Fingerprint implements serde.Fingerprinter. It writes a deterministic binary
-// representation of the transaction.
+// GetFormatVersion implements registry.VersionedMessage. It returns the legacy
+// version of the format when the transaction has a legacy fingerprint.
+func (t *Transaction) GetFormatVersion() uint32 {
+	if t.legacy {
+		return registry.LegacyVersion
+	}
+
+	return 0
+}
+
+// Fingerprint implements serde.Fingerprinter. It writes the canonical encoding
+// of the nonce, the arguments and the public key of the transaction, which
+// defines its identifier.
 func (t *Transaction) Fingerprint(w io.Writer) error {
+	if t.legacy {
+		return t.legacyFingerprint(w)
+	}
+
+	pubkey, err := t.pubkey.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal public key: %v", err)
+	}
+
+	// The arguments are never null, but an empty map or byte string instead.
+	args := make(map[string][]byte, len(t.args))
+	for key, value := range t.args {
+		args[key] = append([]byte{}, value...)
+	}
+
+	err = canonical.Write(w, t.nonce, args, pubkey)
+	if err != nil {
+		return xerrors.Errorf("couldn't write transaction: %v", err)
+	}
+
+	return nil
+}
+
+// legacyFingerprint writes the concatenation of the nonce, the sorted arguments
+// and the public key of the transaction.
+func (t *Transaction) legacyFingerprint(w io.Writer) error {
 	buffer := make([]byte, 8)
 	binary.LittleEndian.PutUint64(buffer, t.nonce)
 
@@ -229,14 +287,33 @@ type SignatureFac struct{}
 type TransactionFactory struct {
 	pubkeyFac common.PublicKeyFactory
 	sigFac    common.SignatureFactory
+	hashFac   crypto.HashFactory
+}
+
+// FactoryOption is the type of option to set some fields of the transaction
+// factory.
+type FactoryOption func(*TransactionFactory)
+
+// WithFactoryHash is an option to set the hash factory that computes the ID of
+// the transactions that are deserialized.
+func WithFactoryHash(fac crypto.HashFactory) FactoryOption {
+	return func(f *TransactionFactory) {
+		f.hashFac = fac
+	}
 }
 
 // NewTransactionFactory returns a new factory.
-func NewTransactionFactory() TransactionFactory {
-	return TransactionFactory{
+func NewTransactionFactory(opts ...FactoryOption) TransactionFactory {
+	f := TransactionFactory{
 		pubkeyFac: common.NewPublicKeyFactory(),
 		sigFac:    common.NewSignatureFactory(),
 	}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return f
 }
 
 // Deserialize implements serde.Factory. It populates the transaction from the
@@ -253,6 +330,10 @@ func (f TransactionFactory) TransactionOf(ctx serde.Context, data []byte) (txn.T
 	ctx = serde.WithFactory(ctx, PublicKeyFac{}, f.pubkeyFac)
 	ctx = serde.WithFactory(ctx, SignatureFac{}, f.sigFac)
 
+	if f.hashFac != nil {
+		ctx = crypto.WithHashFactory(ctx, f.hashFac)
+	}
+
 	msg, err := format.Decode(ctx, data)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to decode: %v", err)
@@ -284,16 +365,34 @@ type TransactionManager struct {
 	hashFac crypto.HashFactory
 }
 
+// ManagerOption is the type of option to set some fields of the transaction
+// manager.
+type ManagerOption func(*TransactionManager)
+
+// WithManagerHash is an option to set the hash factory that computes the ID of
+// the transactions.
+func WithManagerHash(fac crypto.HashFactory) ManagerOption {
+	return func(mgr *TransactionManager) {
+		mgr.hashFac = fac
+	}
+}
+
 // NewManager creates a new transaction manager.
 //
 // - implements txn.Manager
-func NewManager(signer crypto.Signer, client Client) *TransactionManager {
-	return &TransactionManager{
+func NewManager(signer crypto.Signer, client Client, opts ...ManagerOption) *TransactionManager {
+	mgr := &TransactionManager{
 		client:  client,
 		signer:  signer,
 		nonce:   0,
 		hashFac: crypto.NewHashFactory(crypto.Sha256),
 	}
+
+	for _, opt := range opts {
+		opt(mgr)
+	}
+
+	return mgr
 }
 
 // Make implements txn.Manager. It creates a transaction populated with the
@@ -2,6 +2,7 @@ package signed
 
 import (
 	"bytes"
+	"hash"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -17,8 +18,16 @@ func init() {
 	RegisterTransactionFormat(fake.GoodFormat, fake.Format{Msg: &Transaction{}})
 	RegisterTransactionFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterTransactionFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
+	RegisterTransactionFormat(hashFormat, fake.Format{Msg: &Transaction{}, Call: hashCalls})
 }
 
+var (
+	// hashFormat is a format that records the context of the calls so that the
+	// tests can check the hash factory of the context.
+	hashFormat = serde.Format("HASH")
+	hashCalls  = &fake.Call{}
+)
+
 func TestTransaction_New(t *testing.T) {
 	signer := bls.NewSigner()
 
@@ -34,7 +43,7 @@ func TestTransaction_New(t *testing.T) {
 
 	_, err = NewTransaction(0, fake.PublicKey{},
 		WithHashFactory(fake.NewHashFactory(fake.NewBadHash())))
-	require.EqualError(t, err, fake.Err("couldn't fingerprint tx: couldn't write nonce"))
+	require.EqualError(t, err, fake.Err("couldn't fingerprint tx: couldn't write transaction: failed to write"))
 
 	_, err = NewTransaction(1, signer.GetPublicKey(), WithSignature(tx.GetSignature()))
 	require.EqualError(t, err, "invalid signature: bls verify failed: bls: invalid signature")
@@ -114,6 +123,34 @@ func TestTransaction_Fingerprint(t *testing.T) {
 	tx, err := NewTransaction(2, fake.PublicKey{}, WithArg("A", []byte{1, 2, 3}))
 	require.NoError(t, err)
 
+	buffer := new(bytes.Buffer)
+	err = tx.Fingerprint(buffer)
+	require.NoError(t, err)
+	// [2, {"A": h'010203'}, h'504b']
+	require.Equal(t, "\x83\x02\xa1\x61A\x43\x01\x02\x03\x42PK", buffer.String())
+
+	buffer.Reset()
+	tx, err = NewTransaction(0, fake.PublicKey{}, WithArg("A", nil))
+	require.NoError(t, err)
+
+	err = tx.Fingerprint(buffer)
+	require.NoError(t, err)
+	// [0, {"A": h''}, h'504b']
+	require.Equal(t, "\x83\x00\xa1\x61A\x40\x42PK", buffer.String())
+
+	err = tx.Fingerprint(fake.NewBadHash())
+	require.EqualError(t, err, fake.Err("couldn't write transaction: failed to write"))
+
+	tx.pubkey = fake.NewBadPublicKey()
+	err = tx.Fingerprint(buffer)
+	require.EqualError(t, err, fake.Err("failed to marshal public key"))
+}
+
+func TestTransaction_LegacyFingerprint(t *testing.T) {
+	tx, err := NewTransaction(2, fake.PublicKey{}, WithArg("A", []byte{1, 2, 3}),
+		WithLegacyFingerprint())
+	require.NoError(t, err)
+
 	buffer := new(bytes.Buffer)
 	err = tx.Fingerprint(buffer)
 	require.NoError(t, err)
@@ -133,6 +170,34 @@ func TestTransaction_Fingerprint(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to marshal public key"))
 }
 
+func TestTransaction_ChainFingerprint(t *testing.T) {
+	legacy, err := NewTransaction(2, fake.PublicKey{}, WithLegacyFingerprint())
+	require.NoError(t, err)
+
+	canonical, err := NewTransaction(2, fake.PublicKey{})
+	require.NoError(t, err)
+
+	// The version of the chain wins over the one of the encoding.
+	tx, err := NewTransaction(2, fake.PublicKey{}, WithLegacyFingerprint(),
+		WithHashFactory(fakeChainHash{version: 2}))
+	require.NoError(t, err)
+	require.Equal(t, canonical.GetID(), tx.GetID())
+
+	tx, err = NewTransaction(2, fake.PublicKey{}, WithHashFactory(fakeChainHash{version: 1}))
+	require.NoError(t, err)
+	require.Equal(t, legacy.GetID(), tx.GetID())
+}
+
+func TestTransaction_GetFormatVersion(t *testing.T) {
+	tx, err := NewTransaction(0, fake.PublicKey{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), tx.GetFormatVersion())
+
+	tx, err = NewTransaction(0, fake.PublicKey{}, WithLegacyFingerprint())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), tx.GetFormatVersion())
+}
+
 func TestTransaction_Serialize(t *testing.T) {
 	tx, err := NewTransaction(0, fake.PublicKey{})
 	require.NoError(t, err)
@@ -157,6 +222,15 @@ func TestTransactionFactory_Deserialize(t *testing.T) {
 
 	_, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("BAD_TYPE")), nil)
 	require.EqualError(t, err, "invalid transaction of type 'fake.Message'")
+
+	hashFac := crypto.NewHashFactory(crypto.Blake3_256)
+	factory = NewTransactionFactory(WithFactoryHash(hashFac))
+
+	_, err = factory.Deserialize(fake.NewContextWithFormat(hashFormat), nil)
+	require.NoError(t, err)
+
+	ctx := hashCalls.Get(hashCalls.Len()-1, 0).(serde.Context)
+	require.Equal(t, hashFac, crypto.HashFactoryOf(ctx))
 }
 
 func TestManager_Make(t *testing.T) {
@@ -176,6 +250,16 @@ func TestManager_Make(t *testing.T) {
 	mgr.signer = fake.NewBadSigner()
 	_, err = mgr.Make()
 	require.EqualError(t, err, fake.Err("failed to sign: signer"))
+
+	hashFac := crypto.NewHashFactory(crypto.Blake3_256)
+	mgr = NewManager(fake.NewSigner(), fake.NewClient(), WithManagerHash(hashFac))
+
+	tx, err = mgr.Make()
+	require.NoError(t, err)
+
+	expected, err := NewTransaction(0, fake.PublicKey{}, WithHashFactory(hashFac))
+	require.NoError(t, err)
+	require.Equal(t, expected.GetID(), tx.GetID())
 }
 
 func TestManager_Sync(t *testing.T) {
@@ -200,3 +284,19 @@ type fakeClient struct {
 func (c fakeClient) GetNonce(access.Identity) (uint64, error) {
 	return 42, c.err
 }
+
+// fakeChainHash is a hash factory that fixes the version of the fingerprints,
+// as the one of a chain does.
+type fakeChainHash struct {
+	crypto.HashFactory
+
+	version uint32
+}
+
+func (f fakeChainHash) New() hash.Hash {
+	return crypto.NewHashFactory(crypto.Sha256).New()
+}
+
+func (f fakeChainHash) GetFingerprintVersion() uint32 {
+	return f.version
+}
@@ -12,6 +12,8 @@ import (
 func init() {
 	simple.RegisterTransactionResultFormat(serde.FormatJSON, txResFormat{})
 	simple.RegisterResultFormat(serde.FormatJSON, resFormat{})
+	simple.RegisterTransactionResultFormat(serde.FormatCBOR, txResFormat{})
+	simple.RegisterResultFormat(serde.FormatCBOR, resFormat{})
 }
 
 // TransactionResultJSON is the JSON message for transaction results.
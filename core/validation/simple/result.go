@@ -11,6 +11,7 @@ import (
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
@@ -132,24 +133,24 @@ func (d Result) GetTransactionResults() []validation.TransactionResult {
 	return res
 }
 
-// Fingerprint implements serde.Fingerprinter. It writes a deterministic binary
-// representation of the result.
+// Fingerprint implements serde.Fingerprinter. It writes the canonical encoding
+// of the list of the transactions, each with its fingerprint and whether it is
+// accepted.
 func (d Result) Fingerprint(w io.Writer) error {
-	for _, res := range d.txs {
-		err := res.tx.Fingerprint(w)
+	results := make([]interface{}, len(d.txs))
+
+	for i, res := range d.txs {
+		tx, err := canonical.Fingerprint(res.tx)
 		if err != nil {
 			return xerrors.Errorf("couldn't fingerprint tx: %v", err)
 		}
 
-		bit := []byte{0}
-		if res.accepted {
-			bit[0] = 1
-		}
+		results[i] = []interface{}{tx, res.accepted}
+	}
 
-		_, err = w.Write(bit)
-		if err != nil {
-			return xerrors.Errorf("couldn't write accepted: %v", err)
-		}
+	err := canonical.Write(w, results...)
+	if err != nil {
+		return xerrors.Errorf("couldn't write result: %v", err)
 	}
 
 	return nil
@@ -75,9 +75,11 @@ func TestResult_Fingerprint(t *testing.T) {
 	buffer := new(bytes.Buffer)
 	err := res.Fingerprint(buffer)
 	require.NoError(t, err)
+	// [[h'', false], [h'', true]]
+	require.Equal(t, []byte{0x82, 0x82, 0x40, 0xf4, 0x82, 0x40, 0xf5}, buffer.Bytes())
 
 	err = res.Fingerprint(fake.NewBadHash())
-	require.EqualError(t, err, fake.Err("couldn't write accepted"))
+	require.EqualError(t, err, fake.Err("couldn't write result: failed to write"))
 
 	res.txs[0].tx = fakeTx{err: fake.GetError()}
 	err = res.Fingerprint(buffer)
@@ -11,6 +11,7 @@ import (
 
 func init() {
 	cosi.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+	cosi.RegisterMessageFormat(serde.FormatCBOR, msgFormat{})
 }
 
 // Request is the JSON message sent to request signature.
@@ -36,7 +36,8 @@ type thresholdActor struct {
 // of only a subset of the participants, depending on the threshold. The
 // function will return as soon as a valid signature is available.
 // The context must be canceled at some point, and it will interrupt the
-// protocol if it is not done yet.
+// protocol if it is not done yet. When the tree aggregation is enabled, the
+// participants sign along the tree first.
 func (a thresholdActor) Sign(
 	ctx context.Context, msg serde.Message,
 	ca crypto.CollectiveAuthority,
@@ -58,39 +59,127 @@ func (a thresholdActor) Sign(
 	// signatures.
 	thres := a.thresholdFn.Load().(cosi.Threshold)(ca.Len())
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if a.treeHeight > 0 {
+		return a.signTree(ctx, cancel, sender, rcvr, msg, ca, digest, thres)
+	}
+
 	req := cosi.SignatureRequest{
 		Value: msg,
 	}
 
 	errs := sender.Send(req, iter2slice(ca)...)
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	go a.waitResp(errs, ca.Len()-thres, cancel)
+
+	agg := newAggregation(tree{}, 0, nil, nil, digest, a.signer)
+
+	return a.collect(ctx, rcvr, ca, agg, thres)
+}
+
+// signTree sends the request of a tree aggregation and waits for the
+// contributions of the branches. The participants that are missing when the
+// tree is done, or when it times out, are contacted individually.
+func (a thresholdActor) signTree(
+	ctx context.Context, cancel func(),
+	sender mino.Sender, rcvr mino.Receiver,
+	msg serde.Message, ca crypto.CollectiveAuthority, digest []byte, thres int,
+) (crypto.Signature, error) {
+
+	addrs := iter2slice(ca)
+
+	pubkeys := make([]crypto.PublicKey, 0, ca.Len())
+	iter := ca.PublicKeyIterator()
+	for iter.HasNext() {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	t := newTree(len(addrs), a.treeHeight)
+	agg := newAggregation(t, 0, addrs, pubkeys, digest, a.signer)
+
+	req := types.NewAggregateRequest(msg, addrs, pubkeys, t.branches, a.treeTimeout)
+
+	errs := sender.Send(req, addrs...)
 
 	go a.waitResp(errs, ca.Len()-thres, cancel)
 
-	count := 0
-	signature := new(types.Signature)
-	for count < thres {
-		addr, resp, err := rcvr.Recv(ctx)
+	treeCtx, treeCancel := context.WithTimeout(ctx, a.treeTimeout)
+	defer treeCancel()
+
+	for !agg.done() && agg.count() < thres {
+		addr, resp, err := rcvr.Recv(treeCtx)
+		if err != nil && ctx.Err() == nil && treeCtx.Err() != nil {
+			break
+		}
 		if err != nil {
 			return nil, xerrors.Errorf("couldn't receive more messages: %v", err)
 		}
 
-		pubkey, index := ca.GetPublicKey(addr)
-		if index >= 0 {
-			err = a.merge(signature, resp, index, pubkey, digest)
-			if err != nil {
-				a.logger.Warn().Err(err).Msg("failed to process signature response")
-			} else {
-				count++
+		err = a.process(agg, ca, addr, resp)
+		if err != nil {
+			a.logger.Warn().Err(err).Msg("failed to process contribution")
+		}
+	}
+
+	if agg.count() < thres {
+		missing := make([]mino.Address, 0, len(addrs))
+		for i, addr := range addrs {
+			if !agg.signature.HasBit(i) {
+				missing = append(missing, addr)
 			}
 		}
+
+		a.logger.Info().
+			Int("missing", len(missing)).
+			Msg("tree aggregation is incomplete")
+
+		errs := sender.Send(cosi.SignatureRequest{Value: msg}, missing...)
+
+		go a.waitResp(errs, len(missing)-(thres-agg.count()), cancel)
+	}
+
+	return a.collect(ctx, rcvr, ca, agg, thres)
+}
+
+// collect receives the signatures until the threshold is reached.
+func (a thresholdActor) collect(
+	ctx context.Context, rcvr mino.Receiver,
+	ca crypto.CollectiveAuthority, agg *aggregation, thres int,
+) (crypto.Signature, error) {
+
+	for agg.count() < thres {
+		addr, resp, err := rcvr.Recv(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't receive more messages: %v", err)
+		}
+
+		err = a.process(agg, ca, addr, resp)
+		if err != nil {
+			a.logger.Warn().Err(err).Msg("failed to process signature response")
+		}
+	}
+
+	// Each signature, or aggregate of a subtree, is verified, so we can assume
+	// the aggregated signature is correct.
+	return agg.signature, nil
+}
+
+func (a thresholdActor) process(agg *aggregation, ca crypto.CollectiveAuthority,
+	addr mino.Address, resp serde.Message) error {
+
+	contrib, ok := resp.(types.AggregateResponse)
+	if ok {
+		return agg.add(addr, contrib.GetSignature())
+	}
+
+	pubkey, index := ca.GetPublicKey(addr)
+	if index < 0 {
+		return xerrors.Errorf("unknown participant %v", addr)
 	}
 
-	// Each signature is individually verified, so we can assume the aggregated
-	// signature is correct.
-	return signature, nil
+	return a.merge(agg.signature, resp, index, pubkey, agg.digest)
 }
 
 func (a thresholdActor) waitResp(errs <-chan error, maxErrs int, cancel func()) {
@@ -8,8 +8,11 @@ package threshold
 import (
 	"context"
 	"io"
+	"time"
 
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
@@ -31,52 +34,220 @@ func newHandler(c *Threshold, hasher cosi.Reactor) thresholdHandler {
 	}
 }
 
+// stream is the state of a stream for the tree aggregation.
+type stream struct {
+	leader mino.Address
+	own    crypto.Signature
+	agg    *aggregation
+	sent   bool
+
+	// early are the contributions received before the request.
+	early []contribution
+}
+
+type contribution struct {
+	from mino.Address
+	sig  *types.Signature
+}
+
+// waiting returns true if the node is waiting for its children.
+func (s *stream) waiting() bool {
+	return s.agg != nil && !s.sent
+}
+
 // Stream implements mino.RPC. It listens for incoming messages and tries to
-// send back the signature. If the message is malformed, it is ignored.
+// send back the signature. If the message is malformed, it is ignored. During
+// a tree aggregation, it forwards the aggregate of its subtree to its parent
+// when every child has contributed or when it times out.
 func (h thresholdHandler) Stream(out mino.Sender, in mino.Receiver) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	s := &stream{}
+
 	for {
-		addr, msg, err := in.Recv(ctx)
+		recvCtx, cancelRecv := context.WithCancel(ctx)
+		if s.waiting() {
+			recvCtx, cancelRecv = context.WithDeadline(ctx, s.agg.deadline)
+		}
+
+		addr, msg, err := in.Recv(recvCtx)
+		cancelRecv()
+
 		if err == io.EOF {
 			return nil
 		}
+		if err != nil && s.waiting() && recvCtx.Err() != nil {
+			err = h.forward(out, s)
+			if err != nil {
+				h.logger.Warn().Err(err).Send()
+			}
+
+			continue
+		}
 		if err != nil {
 			return xerrors.Errorf("failed to receive: %v", err)
 		}
 
-		err = h.processRequest(out, msg, addr)
+		err = h.process(out, s, addr, msg)
 		if err != nil {
 			h.logger.Warn().Err(err).Send()
 		}
 	}
 }
 
-func (h thresholdHandler) processRequest(sender mino.Sender, msg serde.Message, addr mino.Address) error {
-	req, ok := msg.(cosi.SignatureRequest)
-	if !ok {
+func (h thresholdHandler) process(out mino.Sender, s *stream, addr mino.Address,
+	msg serde.Message) error {
+
+	switch in := msg.(type) {
+	case cosi.SignatureRequest:
+		return h.processRequest(out, s, in, addr)
+	case types.AggregateRequest:
+		return h.processAggregate(out, s, in, addr)
+	case types.AggregateResponse:
+		if s.agg == nil {
+			s.early = append(s.early, contribution{from: addr, sig: in.GetSignature()})
+			return nil
+		}
+
+		if s.sent {
+			return xerrors.Errorf("late contribution from %v", addr)
+		}
+
+		err := s.agg.add(addr, in.GetSignature())
+		if err != nil {
+			return xerrors.Errorf("invalid contribution: %v", err)
+		}
+
+		if s.agg.done() {
+			return h.forward(out, s)
+		}
+
+		return nil
+	default:
 		return xerrors.Errorf("invalid request type '%T'", msg)
 	}
+}
+
+func (h thresholdHandler) processRequest(sender mino.Sender, s *stream,
+	req cosi.SignatureRequest, addr mino.Address) error {
+
+	// The signature of the tree aggregation is reused when the leader contacts
+	// the node individually so that the message is processed only once.
+	signature := s.own
 
-	buffer, err := h.reactor.Invoke(addr, req.Value)
+	if signature == nil {
+		var err error
+		signature, err = h.sign(addr, req.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp := cosi.SignatureResponse{
+		Signature: signature,
+	}
+
+	err := <-sender.Send(resp, addr)
+	if err != nil {
+		return xerrors.Errorf("couldn't send the response: %v", err)
+	}
+
+	return nil
+}
+
+func (h thresholdHandler) processAggregate(out mino.Sender, s *stream,
+	req types.AggregateRequest, addr mino.Address) error {
+
+	if s.agg != nil {
+		return xerrors.Errorf("duplicate request from %v", addr)
+	}
+
+	addrs := req.GetAddresses()
+	pubkeys := req.GetPublicKeys()
+
+	if req.GetBranches() < 1 || len(addrs) != len(pubkeys) {
+		return xerrors.Errorf("malformed request with %d branches, %d addresses "+
+			"and %d public keys", req.GetBranches(), len(addrs), len(pubkeys))
+	}
+
+	me := h.mino.GetAddress()
+
+	index := -1
+	for i, participant := range addrs {
+		if participant.Equal(me) {
+			index = i
+		}
+	}
+
+	if index < 0 {
+		return xerrors.Errorf("%v is not a participant", me)
+	}
+
+	digest, err := h.reactor.Invoke(addr, req.GetValue())
 	if err != nil {
 		return xerrors.Errorf("couldn't hash message: %v", err)
 	}
 
-	signature, err := h.signer.Sign(buffer)
+	signature, err := h.signer.Sign(digest)
 	if err != nil {
 		return xerrors.Errorf("couldn't sign: %v", err)
 	}
 
-	resp := cosi.SignatureResponse{
-		Signature: signature,
+	t := tree{size: len(addrs), branches: req.GetBranches()}
+
+	agg := newAggregation(t, index+1, addrs, pubkeys, digest, h.signer)
+	agg.deadline = time.Now().Add(agg.waitFor(req.GetTimeout()))
+
+	err = agg.signature.Merge(h.signer, index, signature)
+	if err != nil {
+		return xerrors.Errorf("couldn't merge signature: %v", err)
 	}
 
-	err = <-sender.Send(resp, addr)
+	s.leader = addr
+	s.own = signature
+	s.agg = agg
+
+	for _, c := range s.early {
+		err = agg.add(c.from, c.sig)
+		if err != nil {
+			h.logger.Warn().Err(err).Msg("invalid contribution")
+		}
+	}
+
+	s.early = nil
+
+	if agg.done() {
+		return h.forward(out, s)
+	}
+
+	return nil
+}
+
+// forward sends the aggregate of the subtree to the parent of the node.
+func (h thresholdHandler) forward(out mino.Sender, s *stream) error {
+	s.sent = true
+
+	resp := types.NewAggregateResponse(s.agg.signature)
+
+	err := <-out.Send(resp, s.agg.parentOf(s.leader))
 	if err != nil {
-		return xerrors.Errorf("couldn't send the response: %v", err)
+		return xerrors.Errorf("couldn't send the contribution: %v", err)
 	}
 
 	return nil
 }
+
+func (h thresholdHandler) sign(addr mino.Address, value serde.Message) (crypto.Signature, error) {
+	buffer, err := h.reactor.Invoke(addr, value)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't hash message: %v", err)
+	}
+
+	signature, err := h.signer.Sign(buffer)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't sign: %v", err)
+	}
+
+	return signature, nil
+}
@@ -1,10 +1,16 @@
 package threshold
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -90,3 +96,168 @@ func TestThresholdHandler_BadSigner_Stream(t *testing.T) {
 	require.NoError(t, err)
 	check(t)
 }
+
+func TestThresholdHandler_Aggregate_Stream(t *testing.T) {
+	signers, addrs, pubkeys := makeParticipants(3)
+	leader := fake.NewAddress(99)
+
+	handler := newHandler(
+		&Threshold{mino: fake.Mino{}, signer: signers[0]},
+		fakeReactor{},
+	)
+
+	// The first participant is the parent of the third one, which contributes
+	// before the request arrives.
+	req := types.NewAggregateRequest(fake.Message{}, addrs, pubkeys, 2, time.Second)
+	contrib := types.NewAggregateResponse(signSubtree(t, signers, []byte{0xff}, 2))
+
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(addrs[2], contrib),
+		fake.NewRecvMsg(leader, req),
+		fake.NewRecvMsg(leader, cosi.SignatureRequest{Value: fake.Message{}}),
+	)
+	sender := &recordSender{}
+
+	err := handler.Stream(sender, recv)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 2)
+	require.Equal(t, []mino.Address{leader, leader}, sender.to)
+
+	resp := sender.msgs[0].(types.AggregateResponse)
+	require.Equal(t, []int{0, 2}, resp.GetSignature().GetIndices())
+
+	verifier, err := types.NewThresholdVerifierFactory(signers[0].GetVerifierFactory()).
+		FromArray(pubkeys)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify([]byte{0xff}, resp.GetSignature()))
+
+	// The signature of the tree is reused for the individual request.
+	own := sender.msgs[1].(cosi.SignatureResponse)
+	require.NoError(t, signers[0].GetPublicKey().Verify([]byte{0xff}, own.Signature))
+}
+
+func TestThresholdHandler_AggregateTimeout_Stream(t *testing.T) {
+	signers, addrs, pubkeys := makeParticipants(3)
+	leader := fake.NewAddress(99)
+
+	handler := newHandler(
+		&Threshold{mino: fake.Mino{}, signer: signers[0]},
+		fakeReactor{},
+	)
+
+	req := types.NewAggregateRequest(fake.Message{}, addrs, pubkeys, 2, 50*time.Millisecond)
+
+	recv := &blockingReceiver{
+		Receiver: fake.NewReceiver(fake.NewRecvMsg(leader, req)),
+		ch:       make(chan struct{}),
+	}
+	sender := &recordSender{
+		sent: recv.ch,
+	}
+
+	err := handler.Stream(sender, recv)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 1)
+
+	resp := sender.msgs[0].(types.AggregateResponse)
+	require.Equal(t, []int{0}, resp.GetSignature().GetIndices())
+}
+
+func TestThresholdHandler_ProcessAggregate(t *testing.T) {
+	signers, addrs, pubkeys := makeParticipants(3)
+	leader := fake.NewAddress(99)
+
+	handler := newHandler(
+		&Threshold{mino: fake.Mino{}, signer: signers[0]},
+		fakeReactor{},
+	)
+
+	req := types.NewAggregateRequest(fake.Message{}, addrs, pubkeys, 2, time.Second)
+
+	s := &stream{}
+	err := handler.process(fake.Sender{}, s, leader, req)
+	require.NoError(t, err)
+	require.True(t, s.waiting())
+
+	err = handler.process(fake.Sender{}, s, leader, req)
+	require.EqualError(t, err, "duplicate request from fake.Address[99]")
+
+	contrib := types.NewAggregateResponse(signSubtree(t, signers, []byte{0xff}, 1))
+	err = handler.process(fake.Sender{}, s, addrs[1], contrib)
+	require.EqualError(t, err,
+		"invalid contribution: unexpected contribution from fake.Address[1]")
+
+	contrib = types.NewAggregateResponse(signSubtree(t, signers, []byte{0xff}, 2))
+	err = handler.process(fake.NewBadSender(), s, addrs[2], contrib)
+	require.EqualError(t, err, fake.Err("couldn't send the contribution"))
+	require.False(t, s.waiting())
+
+	err = handler.process(fake.Sender{}, s, addrs[2], contrib)
+	require.EqualError(t, err, "late contribution from fake.Address[2]")
+
+	req = types.NewAggregateRequest(fake.Message{}, addrs, pubkeys[:2], 2, time.Second)
+	err = handler.process(fake.Sender{}, &stream{}, leader, req)
+	require.EqualError(t, err, "malformed request with 2 branches, 3 addresses and 2 public keys")
+
+	req = types.NewAggregateRequest(fake.Message{}, addrs[1:], pubkeys[1:], 2, time.Second)
+	err = handler.process(fake.Sender{}, &stream{}, leader, req)
+	require.EqualError(t, err, "fake.Address[0] is not a participant")
+
+	req = types.NewAggregateRequest(fake.Message{}, addrs, pubkeys, 2, time.Second)
+
+	handler.reactor = fakeReactor{err: fake.GetError()}
+	err = handler.process(fake.Sender{}, &stream{}, leader, req)
+	require.EqualError(t, err, fake.Err("couldn't hash message"))
+
+	handler.reactor = fakeReactor{}
+	handler.signer = fake.NewBadSigner()
+	err = handler.process(fake.Sender{}, &stream{}, leader, req)
+	require.EqualError(t, err, fake.Err("couldn't sign"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type recordSender struct {
+	msgs []serde.Message
+	to   []mino.Address
+	sent chan struct{}
+}
+
+func (s *recordSender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	s.msgs = append(s.msgs, msg)
+	s.to = append(s.to, addrs...)
+
+	if s.sent != nil {
+		close(s.sent)
+	}
+
+	errs := make(chan error)
+	close(errs)
+
+	return errs
+}
+
+// blockingReceiver returns the messages and then blocks until the context is
+// done, or returns io.EOF once something has been sent.
+type blockingReceiver struct {
+	*fake.Receiver
+
+	ch chan struct{}
+}
+
+func (r *blockingReceiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	if r.Receiver.Msgs != nil {
+		addr, msg, err := r.Receiver.Recv(ctx)
+		r.Receiver.Msgs = nil
+
+		return addr, msg, err
+	}
+
+	select {
+	case <-r.ch:
+		return nil, nil, io.EOF
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
@@ -2,15 +2,20 @@ package json
 
 import (
 	"encoding/json"
+	"time"
 
 	"go.dedis.ch/dela/cosi/threshold/types"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
 )
 
 func init() {
 	types.RegisterSignatureFormat(serde.FormatJSON, sigFormat{})
+	types.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+	types.RegisterSignatureFormat(serde.FormatCBOR, sigFormat{})
+	types.RegisterMessageFormat(serde.FormatCBOR, msgFormat{})
 }
 
 // Signature is the JSON message for the signature.
@@ -76,3 +81,189 @@ func (f sigFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error)
 
 	return s, nil
 }
+
+// AggregateRequest is the JSON message of a request of tree aggregation.
+type AggregateRequest struct {
+	Value      json.RawMessage
+	Addresses  [][]byte
+	PublicKeys []json.RawMessage
+	Branches   int
+	Timeout    int64
+}
+
+// AggregateResponse is the JSON message of a response of tree aggregation.
+type AggregateResponse struct {
+	Signature json.RawMessage
+}
+
+// Message is a JSON container to differentiate the messages of the tree
+// aggregation. The field names differ from the ones of the collective signing
+// messages so that the data of the latter decodes to an empty container.
+type Message struct {
+	AggregateRequest  *AggregateRequest  `json:",omitempty"`
+	AggregateResponse *AggregateResponse `json:",omitempty"`
+}
+
+// MsgFormat is the engine to encode and decode the messages of the tree
+// aggregation in JSON format.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// message if appropriate, otherwise an error.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	var m Message
+
+	switch in := msg.(type) {
+	case types.AggregateRequest:
+		req, err := encodeRequest(ctx, in)
+		if err != nil {
+			return nil, xerrors.Errorf("request: %v", err)
+		}
+
+		m.AggregateRequest = req
+	case types.AggregateResponse:
+		sig, err := in.GetSignature().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize signature: %v", err)
+		}
+
+		m.AggregateResponse = &AggregateResponse{Signature: sig}
+	default:
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message of the JSON
+// data if appropriate, otherwise an error. It returns a nil message when the
+// data is not a message of the tree aggregation.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := Message{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal message: %v", err)
+	}
+
+	if m.AggregateRequest != nil {
+		req, err := decodeRequest(ctx, m.AggregateRequest)
+		if err != nil {
+			return nil, xerrors.Errorf("request: %v", err)
+		}
+
+		return req, nil
+	}
+
+	if m.AggregateResponse != nil {
+		factory := ctx.GetFactory(types.SigKey{})
+
+		fac, ok := factory.(crypto.SignatureFactory)
+		if !ok {
+			return nil, xerrors.Errorf("invalid signature factory '%T'", factory)
+		}
+
+		sig, err := fac.SignatureOf(ctx, m.AggregateResponse.Signature)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't deserialize signature: %v", err)
+		}
+
+		thres, ok := sig.(*types.Signature)
+		if !ok {
+			return nil, xerrors.Errorf("invalid signature of type '%T'", sig)
+		}
+
+		return types.NewAggregateResponse(thres), nil
+	}
+
+	return nil, nil
+}
+
+func encodeRequest(ctx serde.Context, req types.AggregateRequest) (*AggregateRequest, error) {
+	value, err := req.GetValue().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't serialize value: %v", err)
+	}
+
+	addrs := req.GetAddresses()
+	rawAddrs := make([][]byte, len(addrs))
+
+	for i, addr := range addrs {
+		rawAddrs[i], err = addr.MarshalText()
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't marshal address: %v", err)
+		}
+	}
+
+	pubkeys := req.GetPublicKeys()
+	rawKeys := make([]json.RawMessage, len(pubkeys))
+
+	for i, pubkey := range pubkeys {
+		rawKeys[i], err = pubkey.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize public key: %v", err)
+		}
+	}
+
+	m := &AggregateRequest{
+		Value:      value,
+		Addresses:  rawAddrs,
+		PublicKeys: rawKeys,
+		Branches:   req.GetBranches(),
+		Timeout:    int64(req.GetTimeout()),
+	}
+
+	return m, nil
+}
+
+func decodeRequest(ctx serde.Context, m *AggregateRequest) (types.AggregateRequest, error) {
+	var req types.AggregateRequest
+
+	msgFac := ctx.GetFactory(types.MsgKey{})
+	if msgFac == nil {
+		return req, xerrors.New("missing message factory")
+	}
+
+	value, err := msgFac.Deserialize(ctx, m.Value)
+	if err != nil {
+		return req, xerrors.Errorf("couldn't deserialize value: %v", err)
+	}
+
+	factory := ctx.GetFactory(types.AddrKey{})
+
+	addrFac, ok := factory.(mino.AddressFactory)
+	if !ok {
+		return req, xerrors.Errorf("invalid address factory '%T'", factory)
+	}
+
+	addrs := make([]mino.Address, len(m.Addresses))
+	for i, raw := range m.Addresses {
+		addrs[i] = addrFac.FromText(raw)
+	}
+
+	factory = ctx.GetFactory(types.PubKeyKey{})
+
+	pkFac, ok := factory.(crypto.PublicKeyFactory)
+	if !ok {
+		return req, xerrors.Errorf("invalid public key factory '%T'", factory)
+	}
+
+	pubkeys := make([]crypto.PublicKey, len(m.PublicKeys))
+	for i, raw := range m.PublicKeys {
+		pubkeys[i], err = pkFac.PublicKeyOf(ctx, raw)
+		if err != nil {
+			return req, xerrors.Errorf("couldn't deserialize public key: %v", err)
+		}
+	}
+
+	req = types.NewAggregateRequest(value, addrs, pubkeys, m.Branches,
+		time.Duration(m.Timeout))
+
+	return req, nil
+}
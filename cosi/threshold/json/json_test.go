@@ -2,9 +2,12 @@ package json
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/testing/fake"
 )
@@ -51,3 +54,122 @@ func TestFormat_Decode(t *testing.T) {
 	_, err = format.Decode(ctx, []byte(`{}`))
 	require.EqualError(t, err, "invalid factory of type '<nil>'")
 }
+
+func TestMsgFormat_AggregateRequest_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	req := types.NewAggregateRequest(fake.Message{}, []mino.Address{fake.NewAddress(1)},
+		[]crypto.PublicKey{fake.PublicKey{}}, 2, time.Second)
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, `{"AggregateRequest":{"Value":{},"Addresses":["AQAAAA=="],`+
+		`"PublicKeys":[{}],"Branches":2,"Timeout":1000000000}}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+
+	_, err = format.Encode(fake.NewBadContextWithDelay(1), req)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	req = types.NewAggregateRequest(fake.NewBadPublicKey(), nil, nil, 2, 0)
+	_, err = format.Encode(ctx, req)
+	require.EqualError(t, err, fake.Err("request: couldn't serialize value"))
+
+	req = types.NewAggregateRequest(fake.Message{}, []mino.Address{fake.NewBadAddress()},
+		nil, 2, 0)
+	_, err = format.Encode(ctx, req)
+	require.EqualError(t, err, fake.Err("request: couldn't marshal address"))
+
+	req = types.NewAggregateRequest(fake.Message{}, nil,
+		[]crypto.PublicKey{fake.NewBadPublicKey()}, 2, 0)
+	_, err = format.Encode(ctx, req)
+	require.EqualError(t, err, fake.Err("request: couldn't serialize public key"))
+}
+
+func TestMsgFormat_AggregateResponse_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+
+	resp := types.NewAggregateResponse(types.NewSignature(fake.Signature{}, []byte{0xab}))
+
+	data, err := format.Encode(ctx, resp)
+	require.NoError(t, err)
+	require.Equal(t, `{"AggregateResponse":{"Signature":{"Mask":"qw==","Aggregate":{}}}}`,
+		string(data))
+
+	resp = types.NewAggregateResponse(types.NewSignature(fake.NewBadSignature(), nil))
+	_, err = format.Encode(ctx, resp)
+	require.EqualError(t, err, fake.Err("couldn't serialize signature: "+
+		"couldn't encode signature: couldn't serialize aggregate"))
+}
+
+func TestMsgFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	ctx = serde.WithFactory(ctx, types.MsgKey{}, fake.MessageFactory{})
+	ctx = serde.WithFactory(ctx, types.AddrKey{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, types.PubKeyKey{}, fake.PublicKeyFactory{})
+	ctx = serde.WithFactory(ctx, types.SigKey{},
+		types.NewSignatureFactory(fake.SignatureFactory{}))
+
+	msg, err := format.Decode(ctx, []byte(`{"AggregateRequest":{"Addresses":["AQAAAA=="],`+
+		`"PublicKeys":[{}],"Branches":2,"Timeout":1000}}`))
+	require.NoError(t, err)
+
+	req := msg.(types.AggregateRequest)
+	require.Equal(t, fake.Message{}, req.GetValue())
+	require.Equal(t, []mino.Address{fake.NewAddress(1)}, req.GetAddresses())
+	require.Len(t, req.GetPublicKeys(), 1)
+	require.Equal(t, 2, req.GetBranches())
+	require.Equal(t, time.Microsecond, req.GetTimeout())
+
+	msg, err = format.Decode(ctx, []byte(`{"AggregateResponse":{"Signature":{"Mask":"qw=="}}}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xab}, msg.(types.AggregateResponse).GetSignature().GetMask())
+
+	msg, err = format.Decode(ctx, []byte(`{"Request":{}}`))
+	require.NoError(t, err)
+	require.Nil(t, msg)
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't unmarshal message"))
+
+	badCtx := serde.WithFactory(ctx, types.SigKey{}, fake.NewBadSignatureFactory())
+	_, err = format.Decode(badCtx, []byte(`{"AggregateResponse":{}}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize signature"))
+
+	badCtx = serde.WithFactory(ctx, types.SigKey{}, fake.SignatureFactory{})
+	_, err = format.Decode(badCtx, []byte(`{"AggregateResponse":{}}`))
+	require.EqualError(t, err, "invalid signature of type 'fake.Signature'")
+
+	badCtx = serde.WithFactory(ctx, types.SigKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"AggregateResponse":{}}`))
+	require.EqualError(t, err, "invalid signature factory '<nil>'")
+
+	data := []byte(`{"AggregateRequest":{"PublicKeys":[{}]}}`)
+
+	badCtx = serde.WithFactory(ctx, types.MsgKey{}, nil)
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "request: missing message factory")
+
+	badCtx = serde.WithFactory(ctx, types.MsgKey{}, fake.NewBadMessageFactory())
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, fake.Err("request: couldn't deserialize value"))
+
+	badCtx = serde.WithFactory(ctx, types.AddrKey{}, nil)
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "request: invalid address factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, types.PubKeyKey{}, nil)
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "request: invalid public key factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, types.PubKeyKey{}, fake.NewBadPublicKeyFactory())
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, fake.Err("request: couldn't deserialize public key"))
+}
@@ -4,11 +4,18 @@
 // not all the participants need to return their signature for the protocol to
 // end.
 //
+// By default, the orchestrator verifies the signature of every participant. The
+// tree aggregation instead arranges the participants in a tree where each node
+// verifies and aggregates the signatures of its subtree before forwarding them
+// to its parent, so that the orchestrator only verifies a few aggregates. The
+// participants missing after the tree are contacted individually.
+//
 // Documentation Last Review: 05.10.2020
 package threshold
 
 import (
 	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
@@ -18,6 +25,10 @@ import (
 	"go.dedis.ch/dela/mino"
 )
 
+// DefaultTreeTimeout is the default maximum amount of time the orchestrator
+// waits for the tree aggregation before contacting the missing participants.
+const DefaultTreeTimeout = 5 * time.Second
+
 var (
 	// protocolName denotes the value of the protocol span tag associated with
 	// the `sign` protocol.
@@ -49,6 +60,25 @@ func ByzantineThreshold(n int) int {
 	return n - f
 }
 
+// Option is the type of option to set some fields of the collective signing.
+type Option func(*Threshold)
+
+// WithTree is an option to aggregate the signatures along a tree of the
+// participants with the given maximum depth.
+func WithTree(height int) Option {
+	return func(c *Threshold) {
+		c.treeHeight = height
+	}
+}
+
+// WithTreeTimeout is an option to set the maximum amount of time to wait for
+// the tree aggregation.
+func WithTreeTimeout(timeout time.Duration) Option {
+	return func(c *Threshold) {
+		c.treeTimeout = timeout
+	}
+}
+
 // Threshold is an implementation of the cosi.CollectiveSigning interface that
 // is using streams to parallelize the work.
 type Threshold struct {
@@ -58,14 +88,22 @@ type Threshold struct {
 	// Stores the cosi.Threshold function. It will always contain a valid
 	// function by construction.
 	thresholdFn atomic.Value
+
+	treeHeight  int
+	treeTimeout time.Duration
 }
 
 // NewThreshold returns a new instance of a threshold collective signature.
-func NewThreshold(m mino.Mino, signer crypto.AggregateSigner) *Threshold {
+func NewThreshold(m mino.Mino, signer crypto.AggregateSigner, opts ...Option) *Threshold {
 	c := &Threshold{
-		logger: dela.Logger.With().Str("addr", m.GetAddress().String()).Logger(),
-		mino:   m,
-		signer: signer,
+		logger:      dela.Logger.With().Str("addr", m.GetAddress().String()).Logger(),
+		mino:        m,
+		signer:      signer,
+		treeTimeout: DefaultTreeTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
 
 	// Force the cosi.Threshold type to allow later updates of the same type.
@@ -111,7 +149,13 @@ func (c *Threshold) SetThreshold(fn cosi.Threshold) {
 // Listen implements cosi.CollectiveSigning. It creates the rpc endpoint and
 // returns the actor that can trigger a collective signature.
 func (c *Threshold) Listen(r cosi.Reactor) (cosi.Actor, error) {
-	factory := cosi.NewMessageFactory(r, c.signer.GetSignatureFactory())
+	factory := types.NewMessageFactory(
+		cosi.NewMessageFactory(r, c.signer.GetSignatureFactory()),
+		r,
+		c.mino.GetAddressFactory(),
+		c.signer.GetPublicKeyFactory(),
+		c.signer.GetSignatureFactory(),
+	)
 
 	actor := thresholdActor{
 		Threshold: c,
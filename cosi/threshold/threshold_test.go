@@ -2,10 +2,13 @@ package threshold
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold/types"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/mino"
@@ -41,6 +44,59 @@ func TestThreshold_Scenario_Basic(t *testing.T) {
 	require.NoError(t, verifier.Verify([]byte{0xff}, sig))
 }
 
+func TestThreshold_Scenario_Tree(t *testing.T) {
+	manager := minoch.NewManager()
+
+	n := 10
+	minos := make([]mino.Mino, n)
+	for i := range minos {
+		minos[i] = minoch.MustCreate(manager, fmt.Sprintf("node%d", i))
+	}
+
+	ca := fake.NewAuthorityFromMino(bls.Generate, minos...)
+
+	cosis := make([]*Threshold, n)
+	actors := make([]cosi.Actor, n)
+
+	for i, m := range minos {
+		cosis[i] = NewThreshold(m, ca.GetSigner(i).(crypto.AggregateSigner),
+			WithTree(2), WithTreeTimeout(500*time.Millisecond))
+
+		reactor := fakeReactor{}
+		if i == 0 {
+			// The first participant is an interior node of the tree which
+			// refuses to sign, so that its children are contacted individually.
+			reactor.err = fake.GetError()
+		}
+
+		actor, err := cosis[i].Listen(reactor)
+		require.NoError(t, err)
+
+		actors[i] = actor
+	}
+
+	verifier, err := cosis[9].GetVerifierFactory().FromAuthority(ca)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cosis[9].SetThreshold(OneThreshold)
+
+	sig, err := actors[9].Sign(ctx, fake.Message{}, ca)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify([]byte{0xff}, sig))
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, sig.(*types.Signature).GetIndices())
+
+	// Without the faulty participant, the tree is complete.
+	sub := ca.Take(mino.RangeFilter(1, n)).(crypto.CollectiveAuthority)
+	cosis[9].SetThreshold(nil)
+
+	sig, err = actors[9].Sign(ctx, fake.Message{}, sub)
+	require.NoError(t, err)
+	require.Len(t, sig.(*types.Signature).GetIndices(), n-1)
+}
+
 func TestDefaultThreshold(t *testing.T) {
 	require.Equal(t, 2, defaultThreshold(2))
 	require.Equal(t, 5, defaultThreshold(5))
@@ -0,0 +1,203 @@
+// This file contains the implementation of the tree aggregation where the
+// participants verify and aggregate the signatures of their subtree before
+// forwarding them to their parent.
+
+package threshold
+
+import (
+	"time"
+
+	"go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+// tree is a complete k-ary tree of the participants where the leader is the
+// root at position 0, and the participant at index i of the authority is at
+// position i+1.
+type tree struct {
+	size     int
+	branches int
+}
+
+// newTree returns the tree with the minimum number of branches, but at least
+// two, so that the participants are at most at the given depth.
+func newTree(size, height int) tree {
+	branches := 2
+	for capacity(branches, height) < size {
+		branches++
+	}
+
+	return tree{
+		size:     size,
+		branches: branches,
+	}
+}
+
+// capacity returns the number of participants of a tree without the root.
+func capacity(branches, height int) int {
+	total := 0
+	level := 1
+
+	for i := 0; i < height; i++ {
+		level *= branches
+		total += level
+	}
+
+	return total
+}
+
+func (t tree) children(pos int) []int {
+	children := make([]int, 0, t.branches)
+
+	for i := 1; i <= t.branches; i++ {
+		child := pos*t.branches + i
+		if child > t.size {
+			break
+		}
+
+		children = append(children, child)
+	}
+
+	return children
+}
+
+func (t tree) parent(pos int) int {
+	return (pos - 1) / t.branches
+}
+
+func (t tree) depth(pos int) int {
+	depth := 0
+	for pos > 0 {
+		pos = t.parent(pos)
+		depth++
+	}
+
+	return depth
+}
+
+// contains returns true if the position is in the subtree of the root.
+func (t tree) contains(root, pos int) bool {
+	for pos > root {
+		pos = t.parent(pos)
+	}
+
+	return pos == root
+}
+
+// aggregation is the state of a node of the tree during a signature. It
+// collects the contributions of its children and aggregates them to its own.
+type aggregation struct {
+	tree      tree
+	position  int
+	addrs     []mino.Address
+	pubkeys   []crypto.PublicKey
+	digest    []byte
+	signer    crypto.AggregateSigner
+	signature *types.Signature
+	pending   map[int]struct{}
+	deadline  time.Time
+}
+
+func newAggregation(t tree, pos int, addrs []mino.Address,
+	pubkeys []crypto.PublicKey, digest []byte, signer crypto.AggregateSigner) *aggregation {
+
+	pending := make(map[int]struct{})
+	for _, child := range t.children(pos) {
+		pending[child] = struct{}{}
+	}
+
+	return &aggregation{
+		tree:      t,
+		position:  pos,
+		addrs:     addrs,
+		pubkeys:   pubkeys,
+		digest:    digest,
+		signer:    signer,
+		signature: new(types.Signature),
+		pending:   pending,
+	}
+}
+
+// done returns true when every child has contributed.
+func (a *aggregation) done() bool {
+	return len(a.pending) == 0
+}
+
+// count returns the number of participants in the aggregated signature.
+func (a *aggregation) count() int {
+	return len(a.signature.GetIndices())
+}
+
+// add verifies the contribution of a child and aggregates it. A contribution
+// must only include participants of the subtree of the child.
+func (a *aggregation) add(from mino.Address, sig *types.Signature) error {
+	child := -1
+	for pos := range a.pending {
+		if a.addrs[pos-1].Equal(from) {
+			child = pos
+		}
+	}
+
+	if child < 0 {
+		return xerrors.Errorf("unexpected contribution from %v", from)
+	}
+
+	indices := sig.GetIndices()
+	if len(indices) == 0 {
+		return xerrors.Errorf("empty contribution from %v", from)
+	}
+
+	pubkeys := make([]crypto.PublicKey, len(indices))
+	for i, index := range indices {
+		if index >= len(a.pubkeys) || !a.tree.contains(child, index+1) {
+			return xerrors.Errorf("index %d is not in the subtree of %v", index, from)
+		}
+
+		pubkeys[i] = a.pubkeys[index]
+	}
+
+	verifier, err := a.signer.GetVerifierFactory().FromArray(pubkeys)
+	if err != nil {
+		return xerrors.Errorf("couldn't make verifier: %v", err)
+	}
+
+	err = verifier.Verify(a.digest, sig.GetAggregate())
+	if err != nil {
+		return xerrors.Errorf("couldn't verify: %v", err)
+	}
+
+	err = a.signature.Combine(a.signer, sig)
+	if err != nil {
+		return xerrors.Errorf("couldn't combine: %v", err)
+	}
+
+	delete(a.pending, child)
+
+	return nil
+}
+
+// parentOf returns the address of the parent of the node, where the root is
+// the given leader address.
+func (a *aggregation) parentOf(leader mino.Address) mino.Address {
+	parent := a.tree.parent(a.position)
+	if parent == 0 {
+		return leader
+	}
+
+	return a.addrs[parent-1]
+}
+
+// waitFor returns how long a node waits for its children, which is shorter
+// the deeper it is so that the parent still has time to forward.
+func (a *aggregation) waitFor(timeout time.Duration) time.Duration {
+	height := a.tree.depth(a.tree.size)
+	depth := a.tree.depth(a.position)
+
+	if height == 0 {
+		return 0
+	}
+
+	return timeout * time.Duration(height-depth) / time.Duration(height)
+}
@@ -0,0 +1,172 @@
+package threshold
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestTree_New(t *testing.T) {
+	require.Equal(t, 2, newTree(1, 3).branches)
+	require.Equal(t, 2, newTree(14, 3).branches)
+	require.Equal(t, 3, newTree(15, 3).branches)
+	require.Equal(t, 10, newTree(100, 2).branches)
+	require.Equal(t, 100, newTree(100, 1).branches)
+}
+
+func TestTree_Structure(t *testing.T) {
+	tr := tree{size: 10, branches: 3}
+
+	require.Equal(t, []int{1, 2, 3}, tr.children(0))
+	require.Equal(t, []int{4, 5, 6}, tr.children(1))
+	require.Equal(t, []int{10}, tr.children(3))
+	require.Empty(t, tr.children(4))
+
+	require.Equal(t, 0, tr.parent(3))
+	require.Equal(t, 1, tr.parent(6))
+	require.Equal(t, 3, tr.parent(10))
+
+	require.Equal(t, 0, tr.depth(0))
+	require.Equal(t, 1, tr.depth(2))
+	require.Equal(t, 2, tr.depth(10))
+
+	require.True(t, tr.contains(0, 10))
+	require.True(t, tr.contains(3, 10))
+	require.True(t, tr.contains(3, 3))
+	require.False(t, tr.contains(1, 10))
+	require.False(t, tr.contains(4, 1))
+}
+
+func TestAggregation_Add(t *testing.T) {
+	signers, addrs, pubkeys := makeParticipants(5)
+
+	tr := tree{size: 5, branches: 2}
+	digest := []byte{0xaa}
+
+	// The second participant has the fifth as child.
+	agg := newAggregation(tr, 0, addrs, pubkeys, digest, signers[0])
+	require.False(t, agg.done())
+
+	sub := signSubtree(t, signers, digest, 1, 4)
+
+	err := agg.add(fake.NewAddress(9), sub)
+	require.EqualError(t, err, "unexpected contribution from fake.Address[9]")
+
+	err = agg.add(addrs[1], types.NewSignature(nil, nil))
+	require.EqualError(t, err, "empty contribution from fake.Address[1]")
+
+	err = agg.add(addrs[1], signSubtree(t, signers, digest, 0))
+	require.EqualError(t, err, "index 0 is not in the subtree of fake.Address[1]")
+
+	err = agg.add(addrs[1], signSubtree(t, signers, []byte{0xbb}, 1))
+	require.Regexp(t, "^couldn't verify: ", err)
+
+	require.NoError(t, agg.add(addrs[1], sub))
+	require.Equal(t, 2, agg.count())
+
+	err = agg.add(addrs[1], sub)
+	require.EqualError(t, err, "unexpected contribution from fake.Address[1]")
+
+	require.NoError(t, agg.add(addrs[0], signSubtree(t, signers, digest, 0, 2, 3)))
+	require.True(t, agg.done())
+
+	verifier, err := types.NewThresholdVerifierFactory(signers[0].GetVerifierFactory()).
+		FromArray(pubkeys)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(digest, agg.signature))
+
+	agg = newAggregation(tr, 0, addrs, pubkeys, digest, badVerifierSigner{})
+
+	err = agg.add(addrs[0], signSubtree(t, signers, digest, 0))
+	require.EqualError(t, err, fake.Err("couldn't make verifier"))
+}
+
+func TestAggregation_Combine(t *testing.T) {
+	signers, addrs, pubkeys := makeParticipants(3)
+
+	tr := tree{size: 3, branches: 2}
+	digest := []byte{0xaa}
+
+	agg := newAggregation(tr, 0, addrs, pubkeys, digest, signers[0])
+	require.NoError(t, agg.signature.Merge(signers[0], 0, mustSign(t, signers[0], digest)))
+
+	err := agg.add(addrs[0], signSubtree(t, signers, digest, 0))
+	require.EqualError(t, err, "couldn't combine: index 0 already merged")
+}
+
+func TestAggregation_ParentOf(t *testing.T) {
+	_, addrs, pubkeys := makeParticipants(5)
+
+	tr := tree{size: 5, branches: 2}
+	leader := fake.NewAddress(99)
+
+	agg := newAggregation(tr, 2, addrs, pubkeys, nil, nil)
+	require.Equal(t, leader, agg.parentOf(leader))
+
+	agg = newAggregation(tr, 5, addrs, pubkeys, nil, nil)
+	require.Equal(t, addrs[1], agg.parentOf(leader))
+}
+
+func TestAggregation_WaitFor(t *testing.T) {
+	tr := tree{size: 10, branches: 3}
+
+	require.Equal(t, time.Second, newAggregation(tr, 0, nil, nil, nil, nil).waitFor(time.Second))
+	require.Equal(t, 500*time.Millisecond,
+		newAggregation(tr, 1, nil, nil, nil, nil).waitFor(time.Second))
+	require.Equal(t, time.Duration(0),
+		newAggregation(tr, 10, nil, nil, nil, nil).waitFor(time.Second))
+
+	empty := tree{size: 0, branches: 2}
+	require.Equal(t, time.Duration(0),
+		newAggregation(empty, 0, nil, nil, nil, nil).waitFor(time.Second))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeParticipants(n int) ([]crypto.AggregateSigner, []mino.Address, []crypto.PublicKey) {
+	signers := make([]crypto.AggregateSigner, n)
+	addrs := make([]mino.Address, n)
+	pubkeys := make([]crypto.PublicKey, n)
+
+	for i := range signers {
+		signers[i] = bls.NewSigner()
+		addrs[i] = fake.NewAddress(i)
+		pubkeys[i] = signers[i].GetPublicKey()
+	}
+
+	return signers, addrs, pubkeys
+}
+
+func signSubtree(t *testing.T, signers []crypto.AggregateSigner, digest []byte,
+	indices ...int) *types.Signature {
+
+	sig := new(types.Signature)
+	for _, index := range indices {
+		err := sig.Merge(signers[0], index, mustSign(t, signers[index], digest))
+		require.NoError(t, err)
+	}
+
+	return sig
+}
+
+func mustSign(t *testing.T, signer crypto.Signer, msg []byte) crypto.Signature {
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	return sig
+}
+
+type badVerifierSigner struct {
+	crypto.AggregateSigner
+}
+
+func (badVerifierSigner) GetVerifierFactory() crypto.VerifierFactory {
+	return fake.NewBadVerifierFactory()
+}
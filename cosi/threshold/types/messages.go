@@ -0,0 +1,187 @@
+// This file contains the messages of the tree aggregation of a threshold
+// collective signature.
+
+package types
+
+import (
+	"time"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(c serde.Format, f serde.FormatEngine) {
+	msgFormats.Register(c, f)
+}
+
+// AggregateRequest is the message sent by the leader to require a signature
+// that is aggregated along a tree of the participants. The participants are
+// ordered as in the collective authority so that every node can compute the
+// same tree.
+//
+// - implements serde.Message
+type AggregateRequest struct {
+	value    serde.Message
+	addrs    []mino.Address
+	pubkeys  []crypto.PublicKey
+	branches int
+	timeout  time.Duration
+}
+
+// NewAggregateRequest creates a new request for the value. The participants
+// are the addresses with their public key, and each node of the tree has at
+// most the number of branches as children. The timeout is the maximum amount
+// of time the leader waits for the tree.
+func NewAggregateRequest(value serde.Message, addrs []mino.Address,
+	pubkeys []crypto.PublicKey, branches int, timeout time.Duration) AggregateRequest {
+
+	return AggregateRequest{
+		value:    value,
+		addrs:    addrs,
+		pubkeys:  pubkeys,
+		branches: branches,
+		timeout:  timeout,
+	}
+}
+
+// GetValue returns the message to sign.
+func (req AggregateRequest) GetValue() serde.Message {
+	return req.value
+}
+
+// GetAddresses returns the addresses of the participants.
+func (req AggregateRequest) GetAddresses() []mino.Address {
+	return append([]mino.Address{}, req.addrs...)
+}
+
+// GetPublicKeys returns the public keys of the participants.
+func (req AggregateRequest) GetPublicKeys() []crypto.PublicKey {
+	return append([]crypto.PublicKey{}, req.pubkeys...)
+}
+
+// GetBranches returns the maximum number of children of a node.
+func (req AggregateRequest) GetBranches() int {
+	return req.branches
+}
+
+// GetTimeout returns the maximum amount of time the leader waits for the tree.
+func (req AggregateRequest) GetTimeout() time.Duration {
+	return req.timeout
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// request.
+func (req AggregateRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, req)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode request: %v", err)
+	}
+
+	return data, nil
+}
+
+// AggregateResponse is the message sent by a participant to its parent with
+// the aggregated signature of its subtree.
+//
+// - implements serde.Message
+type AggregateResponse struct {
+	signature *Signature
+}
+
+// NewAggregateResponse creates a new response with the signature.
+func NewAggregateResponse(sig *Signature) AggregateResponse {
+	return AggregateResponse{
+		signature: sig,
+	}
+}
+
+// GetSignature returns the aggregated signature of the subtree.
+func (resp AggregateResponse) GetSignature() *Signature {
+	return resp.signature
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// response.
+func (resp AggregateResponse) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, resp)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode response: %v", err)
+	}
+
+	return data, nil
+}
+
+// MsgKey is the key of the factory of the value to sign.
+type MsgKey struct{}
+
+// AddrKey is the key of the address factory.
+type AddrKey struct{}
+
+// PubKeyKey is the key of the public key factory.
+type PubKeyKey struct{}
+
+// SigKey is the key of the threshold signature factory.
+type SigKey struct{}
+
+// MessageFactory is the factory of the messages of the tree aggregation. The
+// other messages are deserialized by the fallback factory.
+//
+// - implements serde.Factory
+type MessageFactory struct {
+	fallback serde.Factory
+	msgFac   serde.Factory
+	addrFac  mino.AddressFactory
+	pkFac    crypto.PublicKeyFactory
+	sigFac   crypto.SignatureFactory
+}
+
+// NewMessageFactory creates a new message factory.
+func NewMessageFactory(fallback, msg serde.Factory, addr mino.AddressFactory,
+	pk crypto.PublicKeyFactory, sig crypto.SignatureFactory) MessageFactory {
+
+	return MessageFactory{
+		fallback: fallback,
+		msgFac:   msg,
+		addrFac:  addr,
+		pkFac:    pk,
+		sigFac:   NewSignatureFactory(sig),
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the message of the data.
+// The format returns a nil message when the data is not a message of the tree
+// aggregation, and the fallback factory is then used.
+func (f MessageFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, MsgKey{}, f.msgFac)
+	ctx = serde.WithFactory(ctx, AddrKey{}, f.addrFac)
+	ctx = serde.WithFactory(ctx, PubKeyKey{}, f.pkFac)
+	ctx = serde.WithFactory(ctx, SigKey{}, f.sigFac)
+
+	m, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode message: %v", err)
+	}
+
+	if m != nil {
+		return m, nil
+	}
+
+	m, err = f.fallback.Deserialize(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("fallback: %v", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{})
+	RegisterMessageFormat(serde.Format("AGG_TYPE"), fake.Format{Msg: AggregateResponse{}})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestAggregateRequest_Getters(t *testing.T) {
+	addrs := []mino.Address{fake.NewAddress(0)}
+	pubkeys := []crypto.PublicKey{fake.PublicKey{}}
+
+	req := NewAggregateRequest(fake.Message{}, addrs, pubkeys, 3, time.Second)
+
+	require.Equal(t, fake.Message{}, req.GetValue())
+	require.Equal(t, addrs, req.GetAddresses())
+	require.Equal(t, pubkeys, req.GetPublicKeys())
+	require.Equal(t, 3, req.GetBranches())
+	require.Equal(t, time.Second, req.GetTimeout())
+}
+
+func TestAggregateRequest_Serialize(t *testing.T) {
+	req := AggregateRequest{}
+
+	data, err := req.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = req.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode request"))
+}
+
+func TestAggregateResponse_GetSignature(t *testing.T) {
+	sig := NewSignature(fake.Signature{}, []byte{1})
+
+	resp := NewAggregateResponse(sig)
+	require.Equal(t, sig, resp.GetSignature())
+}
+
+func TestAggregateResponse_Serialize(t *testing.T) {
+	resp := AggregateResponse{}
+
+	data, err := resp.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = resp.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode response"))
+}
+
+func TestMessageFactory_Deserialize(t *testing.T) {
+	factory := NewMessageFactory(fake.MessageFactory{}, fake.MessageFactory{},
+		fake.AddressFactory{}, fake.PublicKeyFactory{}, fake.SignatureFactory{})
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, fake.Message{}, msg)
+
+	msg, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("AGG_TYPE")), nil)
+	require.NoError(t, err)
+	require.Equal(t, AggregateResponse{}, msg)
+
+	_, err = factory.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode message"))
+
+	factory.fallback = fake.NewBadMessageFactory()
+	_, err = factory.Deserialize(fake.NewContext(), nil)
+	require.EqualError(t, err, fake.Err("fallback"))
+}
@@ -116,6 +116,34 @@ func (s *Signature) Merge(signer crypto.AggregateSigner, index int, sig crypto.S
 	return nil
 }
 
+// Combine adds the aggregate of a signature of other participants. It returns
+// an error if a participant is in both signatures.
+func (s *Signature) Combine(signer crypto.AggregateSigner, other *Signature) error {
+	indices := other.GetIndices()
+
+	for _, index := range indices {
+		if s.HasBit(index) {
+			return xerrors.Errorf("index %d already merged", index)
+		}
+	}
+
+	if s.agg == nil {
+		s.agg = other.agg
+	} else {
+		var err error
+		s.agg, err = signer.Aggregate(s.agg, other.agg)
+		if err != nil {
+			return xerrors.Errorf("couldn't aggregate: %v", err)
+		}
+	}
+
+	for _, index := range indices {
+		s.setBit(index)
+	}
+
+	return nil
+}
+
 func (s *Signature) setBit(index int) {
 	if index < 0 {
 		return
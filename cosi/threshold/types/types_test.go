@@ -61,6 +61,25 @@ func TestSignature_Merge(t *testing.T) {
 	require.Equal(t, []byte{0b00000110}, sig.mask)
 }
 
+func TestSignature_Combine(t *testing.T) {
+	sig := &Signature{}
+
+	err := sig.Combine(fake.NewAggregateSigner(), NewSignature(fake.Signature{}, []byte{0x3}))
+	require.NoError(t, err)
+	require.NotNil(t, sig.agg)
+
+	err = sig.Combine(fake.NewAggregateSigner(), NewSignature(fake.Signature{}, []byte{0x4}))
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2}, sig.GetIndices())
+
+	err = sig.Combine(fake.NewAggregateSigner(), NewSignature(fake.Signature{}, []byte{0xa}))
+	require.EqualError(t, err, "index 1 already merged")
+
+	err = sig.Combine(fake.NewBadSigner(), NewSignature(fake.Signature{}, []byte{0x8}))
+	require.EqualError(t, err, fake.Err("couldn't aggregate"))
+	require.Equal(t, []byte{0x7}, sig.mask)
+}
+
 func TestSignature_SetBit(t *testing.T) {
 	sig := &Signature{}
 
@@ -441,7 +441,12 @@ func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
 func (s Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
 	buffers := make([][]byte, len(signatures))
 	for i, sig := range signatures {
-		buffers[i] = sig.(Signature).data
+		signature, ok := sig.(Signature)
+		if !ok {
+			return nil, xerrors.Errorf("invalid signature type '%T'", sig)
+		}
+
+		buffers[i] = signature.data
 	}
 
 	agg, err := bls.AggregateSignatures(suite, buffers...)
@@ -343,6 +343,9 @@ func TestSigner_Aggregate(t *testing.T) {
 
 	err := quick.Check(f, nil)
 	require.NoError(t, err)
+
+	_, err = NewSigner().Aggregate(fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
 }
 
 func TestSigner_MarshalBinary(t *testing.T) {
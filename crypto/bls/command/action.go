@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"go.dedis.ch/dela/crypto"
 
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/remote"
 	"golang.org/x/xerrors"
 )
 
@@ -21,8 +25,15 @@ type action struct {
 	genSigner func() ([]byte, error)
 	getPubKey func([]byte) (crypto.PublicKey, error)
 
-	readFile func(filename string) ([]byte, error)
-	saveFile func(path string, force bool, data []byte) error
+	readFile    func(filename string) ([]byte, error)
+	saveFile    func(path string, force bool, data []byte) error
+	replaceFile func(path string, data []byte) error
+
+	// wait blocks until the daemon must stop.
+	wait func()
+
+	// verifier verifies the scopes of the messages signed by the daemon.
+	verifier remote.ScopeVerifier
 }
 
 func (a action) newSignerAction(flags cli.Flags) error {
@@ -31,6 +42,18 @@ func (a action) newSignerAction(flags cli.Flags) error {
 		return xerrors.Errorf("failed to marshal signer: %v", err)
 	}
 
+	source, err := loader.PassphraseFromFlags(flags, "")
+	if err != nil {
+		return xerrors.Errorf("passphrase: %v", err)
+	}
+
+	if source != nil {
+		data, err = encrypt(data, source)
+		if err != nil {
+			return xerrors.Errorf("failed to encrypt signer: %v", err)
+		}
+	}
+
 	switch flags.String("save") {
 	case "":
 		fmt.Fprintln(a.printer, string(data))
@@ -50,6 +73,11 @@ func (a action) loadSignerAction(flags cli.Flags) error {
 		return xerrors.Errorf("failed to read data: %v", err)
 	}
 
+	data, err = decrypt(flags, "", data)
+	if err != nil {
+		return xerrors.Errorf("failed to decrypt signer: %v", err)
+	}
+
 	var out []byte
 
 	switch flags.String("format") {
@@ -89,13 +117,159 @@ func (a action) loadSignerAction(flags cli.Flags) error {
 	return nil
 }
 
+// rekeyAction encrypts the signer of a file with a new passphrase. The file can
+// be either in plaintext or encrypted with the old passphrase.
+func (a action) rekeyAction(flags cli.Flags) error {
+	path := flags.Path("path")
+
+	data, err := a.readFile(path)
+	if err != nil {
+		return xerrors.Errorf("failed to read data: %v", err)
+	}
+
+	data, err = decrypt(flags, oldPrefix, data)
+	if err != nil {
+		return xerrors.Errorf("failed to decrypt signer: %v", err)
+	}
+
+	source, err := loader.PassphraseFromFlags(flags, "")
+	if err != nil {
+		return xerrors.Errorf("passphrase: %v", err)
+	}
+
+	if source == nil {
+		return xerrors.New("a new passphrase is required")
+	}
+
+	data, err = encrypt(data, source)
+	if err != nil {
+		return xerrors.Errorf("failed to encrypt signer: %v", err)
+	}
+
+	err = a.replaceFile(path, data)
+	if err != nil {
+		return xerrors.Errorf("failed to save file: %v", err)
+	}
+
+	fmt.Fprintf(a.printer, "Signer of %s is encrypted with the new passphrase\n", path)
+
+	return nil
+}
+
+// exportAction writes the decrypted signer of a keystore to another file.
+func (a action) exportAction(flags cli.Flags) error {
+	data, err := a.readFile(flags.Path("path"))
+	if err != nil {
+		return xerrors.Errorf("failed to read data: %v", err)
+	}
+
+	data, err = decrypt(flags, "", data)
+	if err != nil {
+		return xerrors.Errorf("failed to decrypt signer: %v", err)
+	}
+
+	err = a.saveFile(flags.String("save"), flags.Bool("force"), data)
+	if err != nil {
+		return xerrors.Errorf("failed to save file: %v", err)
+	}
+
+	return nil
+}
+
+// serveAction runs a signer daemon for the signer of the file until the
+// process is interrupted.
+func (a action) serveAction(flags cli.Flags) error {
+	if a.verifier == nil {
+		return xerrors.New("no scope verifier")
+	}
+
+	data, err := a.readFile(flags.Path("path"))
+	if err != nil {
+		return xerrors.Errorf("failed to read data: %v", err)
+	}
+
+	data, err = decrypt(flags, "", data)
+	if err != nil {
+		return xerrors.Errorf("failed to decrypt signer: %v", err)
+	}
+
+	signer, err := bls.NewSignerFromBytes(data)
+	if err != nil {
+		return xerrors.Errorf("failed to unmarshal signer: %v", err)
+	}
+
+	guard, err := remote.NewGuard(flags.Path("state"))
+	if err != nil {
+		return xerrors.Errorf("guard: %v", err)
+	}
+
+	var opts []remote.ServerOption
+	if flags.Bool("allow-unscoped") {
+		opts = append(opts, remote.WithUnscoped())
+	}
+
+	srv := remote.NewServer(signer, guard, a.verifier, opts...)
+
+	err = srv.Listen(flags.Path("socket"))
+	if err != nil {
+		return xerrors.Errorf("failed to start daemon: %v", err)
+	}
+
+	fmt.Fprintf(a.printer, "Signer daemon listening on %s\n", flags.Path("socket"))
+
+	a.wait()
+
+	err = srv.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to stop daemon: %v", err)
+	}
+
+	return nil
+}
+
+func encrypt(data []byte, source loader.PassphraseSource) ([]byte, error) {
+	passphrase, err := source()
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	return loader.Encrypt(data, passphrase)
+}
+
+// decrypt returns the data as is when it is not encrypted, otherwise it
+// decrypts it with the passphrase of the flags.
+func decrypt(flags cli.Flags, prefix string, data []byte) ([]byte, error) {
+	if !loader.IsEncrypted(data) {
+		return data, nil
+	}
+
+	source, err := loader.PassphraseFromFlags(flags, prefix)
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	if source == nil {
+		return nil, xerrors.Errorf("signer is encrypted, use one of the --%spassphrase "+
+			"flags", prefix)
+	}
+
+	passphrase, err := source()
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	return loader.Decrypt(data, passphrase)
+}
+
+// saveToFile writes the data to the file with read permission only for the
+// current user, as it holds a private key.
 func saveToFile(path string, force bool, data []byte) error {
 	if !force && fileExist(path) {
 		return xerrors.Errorf("file '%s' already exist, use --force if you "+
 			"want to overwrite", path)
 	}
 
-	err := os.WriteFile(path, data, os.ModePerm)
+	err := loader.WriteFile(path, data)
 	if err != nil {
 		return xerrors.Errorf("failed to write file: %v", err)
 	}
@@ -108,6 +282,15 @@ func fileExist(path string) bool {
 	return !os.IsNotExist(err)
 }
 
+func waitSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	<-sigs
+
+	signal.Stop(sigs)
+}
+
 func getPubkey(data []byte) (crypto.PublicKey, error) {
 	signer, err := bls.NewSignerFromBytes(data)
 	if err != nil {
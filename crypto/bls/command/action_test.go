@@ -10,6 +10,8 @@ import (
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/remote"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -36,6 +38,40 @@ func TestNewSignerAction(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to save files"))
 }
 
+func TestNewSignerAction_Encrypted(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	var saved []byte
+
+	action := action{
+		printer:   io.Discard,
+		genSigner: bls.NewSigner().MarshalBinary,
+		saveFile: func(path string, force bool, data []byte) error {
+			saved = data
+			return nil
+		},
+	}
+
+	set := node.FlagSet{
+		"save":           "/tmp/private.key",
+		"passphrase-env": "DELA_TEST_PASSPHRASE",
+	}
+
+	err := action.newSignerAction(set)
+	require.NoError(t, err)
+	require.True(t, loader.IsEncrypted(saved))
+
+	set["passphrase-env"] = "DELA_TEST_EMPTY"
+	err = action.newSignerAction(set)
+	require.EqualError(t, err, "failed to encrypt signer: passphrase: "+
+		"environment variable 'DELA_TEST_EMPTY' is empty")
+
+	set["passphrase-file"] = "/not/exist"
+	err = action.newSignerAction(set)
+	require.EqualError(t, err, "passphrase: only one of --passphrase-env, "+
+		"--passphrase-file and --passphrase-prompt is allowed")
+}
+
 func TestLoadSignerAction(t *testing.T) {
 	action := action{
 		printer:  io.Discard,
@@ -79,6 +115,201 @@ func TestLoadSignerAction(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestLoadSignerAction_Encrypted(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	data := makeKeystore(t, "pass")
+
+	action := action{
+		printer:   io.Discard,
+		readFile:  func(string) ([]byte, error) { return data, nil },
+		getPubKey: getPubkey,
+	}
+
+	set := node.FlagSet{"format": "PUBKEY"}
+
+	err := action.loadSignerAction(set)
+	require.EqualError(t, err, "failed to decrypt signer: signer is encrypted, "+
+		"use one of the --passphrase flags")
+
+	set["passphrase-env"] = "DELA_TEST_PASSPHRASE"
+	err = action.loadSignerAction(set)
+	require.NoError(t, err)
+
+	data = makeKeystore(t, "other")
+	err = action.loadSignerAction(set)
+	require.EqualError(t, err, "failed to decrypt signer: wrong passphrase "+
+		"or corrupted keystore")
+}
+
+func TestRekeyAction(t *testing.T) {
+	t.Setenv("DELA_TEST_OLD", "old")
+	t.Setenv("DELA_TEST_NEW", "new")
+
+	signer, err := bls.NewSigner().MarshalBinary()
+	require.NoError(t, err)
+
+	var replaced []byte
+
+	action := action{
+		printer:  io.Discard,
+		readFile: func(string) ([]byte, error) { return signer, nil },
+		replaceFile: func(path string, data []byte) error {
+			replaced = data
+			return nil
+		},
+	}
+
+	set := node.FlagSet{"path": "private.key"}
+
+	err = action.rekeyAction(set)
+	require.EqualError(t, err, "a new passphrase is required")
+
+	// A plaintext signer is encrypted.
+	set["passphrase-env"] = "DELA_TEST_OLD"
+	err = action.rekeyAction(set)
+	require.NoError(t, err)
+
+	plaintext, err := loader.Decrypt(replaced, []byte("old"))
+	require.NoError(t, err)
+	require.Equal(t, signer, plaintext)
+
+	// An encrypted signer needs the old passphrase.
+	action.readFile = func(string) ([]byte, error) { return replaced, nil }
+	set["passphrase-env"] = "DELA_TEST_NEW"
+
+	err = action.rekeyAction(set)
+	require.EqualError(t, err, "failed to decrypt signer: signer is encrypted, "+
+		"use one of the --old-passphrase flags")
+
+	set["old-passphrase-env"] = "DELA_TEST_OLD"
+	err = action.rekeyAction(set)
+	require.NoError(t, err)
+
+	plaintext, err = loader.Decrypt(replaced, []byte("new"))
+	require.NoError(t, err)
+	require.Equal(t, signer, plaintext)
+
+	action.replaceFile = func(string, []byte) error { return fake.GetError() }
+	action.readFile = func(string) ([]byte, error) { return signer, nil }
+	err = action.rekeyAction(set)
+	require.EqualError(t, err, fake.Err("failed to save file"))
+
+	set["passphrase-prompt"] = true
+	err = action.rekeyAction(set)
+	require.EqualError(t, err, "passphrase: only one of --passphrase-env, "+
+		"--passphrase-file and --passphrase-prompt is allowed")
+
+	action.readFile = badReadFile
+	err = action.rekeyAction(set)
+	require.EqualError(t, err, fake.Err("failed to read data"))
+}
+
+func TestExportAction(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	data := makeKeystore(t, "pass")
+
+	var saved []byte
+
+	action := action{
+		printer:  io.Discard,
+		readFile: func(string) ([]byte, error) { return data, nil },
+		saveFile: func(path string, force bool, data []byte) error {
+			saved = data
+			return nil
+		},
+	}
+
+	set := node.FlagSet{"path": "private.key", "save": "plain.key"}
+
+	err := action.exportAction(set)
+	require.EqualError(t, err, "failed to decrypt signer: signer is encrypted, "+
+		"use one of the --passphrase flags")
+
+	set["passphrase-env"] = "DELA_TEST_PASSPHRASE"
+	err = action.exportAction(set)
+	require.NoError(t, err)
+
+	_, err = bls.NewSignerFromBytes(saved)
+	require.NoError(t, err)
+
+	action.saveFile = badSaveFile
+	err = action.exportAction(set)
+	require.EqualError(t, err, fake.Err("failed to save file"))
+
+	action.readFile = badReadFile
+	err = action.exportAction(set)
+	require.EqualError(t, err, fake.Err("failed to read data"))
+}
+
+func TestServeAction(t *testing.T) {
+	signer := bls.NewSigner()
+
+	data, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	set := node.FlagSet{
+		"path":           "private.key",
+		"socket":         filepath.Join(dir, "signer.sock"),
+		"state":          filepath.Join(dir, "state.json"),
+		"allow-unscoped": true,
+	}
+
+	action := action{
+		printer:  io.Discard,
+		readFile: func(string) ([]byte, error) { return data, nil },
+		verifier: fakeVerifier{err: fake.GetError()},
+	}
+
+	action.wait = func() {
+		client, err := remote.NewSigner(set.Path("socket"), bls.NewSigner())
+		require.NoError(t, err)
+		require.True(t, signer.GetPublicKey().Equal(client.GetPublicKey()))
+
+		_, err = client.Unscoped().Sign([]byte("tx"))
+		require.NoError(t, err)
+
+		// The daemon verifies the scopes of the consensus messages.
+		client.BindScope([]byte("A"), crypto.SignScope{Kind: "prepare", ID: []byte("A")})
+
+		_, err = client.Sign([]byte("A"))
+		require.EqualError(t, err, fake.Err("remote: daemon refused: invalid scope"))
+
+		require.NoError(t, client.Close())
+	}
+
+	err = action.serveAction(set)
+	require.NoError(t, err)
+
+	set["socket"] = filepath.Join(dir, "unknown", "signer.sock")
+	err = action.serveAction(set)
+	require.Regexp(t, "^failed to start daemon: failed to listen: ", err)
+
+	set["state"] = dir
+	err = action.serveAction(set)
+	require.Regexp(t, "^guard: failed to read state: ", err)
+
+	action.readFile = fakeReadFile
+	err = action.serveAction(set)
+	require.Regexp(t, "^failed to unmarshal signer: ", err)
+
+	action.readFile = func(string) ([]byte, error) { return makeKeystore(t, "pass"), nil }
+	err = action.serveAction(set)
+	require.EqualError(t, err, "failed to decrypt signer: signer is encrypted, "+
+		"use one of the --passphrase flags")
+
+	action.readFile = badReadFile
+	err = action.serveAction(set)
+	require.EqualError(t, err, fake.Err("failed to read data"))
+
+	action.verifier = nil
+	err = action.serveAction(set)
+	require.EqualError(t, err, "no scope verifier")
+}
+
 func TestSaveToFile(t *testing.T) {
 	path, err := os.MkdirTemp("", "dela-test-")
 	require.NoError(t, err)
@@ -93,6 +324,10 @@ func TestSaveToFile(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte{1}, res)
 
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0400), info.Mode().Perm())
+
 	err = saveToFile(file, false, nil)
 	require.Regexp(t, "^file '.*' already exist, use --force if you want to overwrite$", err)
 
@@ -124,6 +359,16 @@ func TestGetPUBKEY_Error(t *testing.T) {
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func makeKeystore(t *testing.T, passphrase string) []byte {
+	signer, err := bls.NewSigner().MarshalBinary()
+	require.NoError(t, err)
+
+	data, err := loader.Encrypt(signer, []byte(passphrase))
+	require.NoError(t, err)
+
+	return data
+}
+
 func badGenSigner() ([]byte, error) {
 	return nil, fake.GetError()
 }
@@ -155,3 +400,19 @@ func wrongGetPubKey([]byte) (crypto.PublicKey, error) {
 func fakeGetPubKey([]byte) (crypto.PublicKey, error) {
 	return bls.Generate().GetPublicKey(), nil
 }
+
+type fakeVerifier struct {
+	err error
+}
+
+func (v fakeVerifier) VerifyScope([]byte, crypto.SignScope) error {
+	return v.err
+}
+
+func (fakeVerifier) GetRequirement(string) string {
+	return ""
+}
+
+func (fakeVerifier) IsScoped(msg []byte) bool {
+	return len(msg) == 32
+}
@@ -6,12 +6,19 @@ import (
 
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/remote"
 )
 
-// Initializer implements the BLS initializer for the crypto CLI.
+// oldPrefix is the prefix of the flags of the passphrase being replaced.
+const oldPrefix = "old-"
+
+// Initializer implements the BLS initializer for the crypto CLI. The verifier
+// is the one of the scopes of the consensus that the signer daemon protects.
 //
 // - implements cli.Initializer
 type Initializer struct {
+	Verifier remote.ScopeVerifier
 }
 
 // SetCommands implements cli.Initializer.
@@ -19,10 +26,13 @@ func (i Initializer) SetCommands(provider cli.Provider) {
 	action := action{
 		printer: os.Stdout,
 
-		genSigner: bls.NewSigner().MarshalBinary,
-		getPubKey: getPubkey,
-		readFile:  os.ReadFile,
-		saveFile:  saveToFile,
+		genSigner:   bls.NewSigner().MarshalBinary,
+		getPubKey:   getPubkey,
+		readFile:    os.ReadFile,
+		saveFile:    saveToFile,
+		replaceFile: loader.WriteFile,
+		wait:        waitSignal,
+		verifier:    i.Verifier,
 	}
 
 	cmd := provider.SetCommand("bls")
@@ -30,7 +40,7 @@ func (i Initializer) SetCommands(provider cli.Provider) {
 
 	new := signer.SetSubCommand("new")
 	new.SetDescription("create a new bls signer")
-	new.SetFlags(cli.StringFlag{
+	new.SetFlags(append([]cli.Flag{cli.StringFlag{
 		Name:     "save",
 		Usage:    "if provided, save the signer to that file",
 		Required: false,
@@ -38,12 +48,12 @@ func (i Initializer) SetCommands(provider cli.Provider) {
 		Name:     "force",
 		Usage:    "in the case it saves the signer, will overwrite if needed",
 		Required: false,
-	})
+	}}, loader.PassphraseFlags("")...)...)
 	new.SetAction(action.newSignerAction)
 
 	read := signer.SetSubCommand("read")
 	read.SetDescription("read a signer")
-	read.SetFlags(cli.StringFlag{
+	read.SetFlags(append([]cli.Flag{cli.StringFlag{
 		Name:     "path",
 		Usage:    "path to the signer's file",
 		Required: true,
@@ -52,6 +62,57 @@ func (i Initializer) SetCommands(provider cli.Provider) {
 		Usage:    "output format: [PUBKEY | BASE64 | BASE64_PUBKEY]",
 		Value:    "PUBKEY",
 		Required: false,
-	})
+	}}, loader.PassphraseFlags("")...)...)
 	read.SetAction(action.loadSignerAction)
+
+	rekey := signer.SetSubCommand("rekey")
+	rekey.SetDescription("encrypt a signer with a new passphrase")
+	rekey.SetFlags(append(append([]cli.Flag{cli.StringFlag{
+		Name:     "path",
+		Usage:    "path to the signer's file, either in plaintext or encrypted",
+		Required: true,
+	}}, loader.PassphraseFlags(oldPrefix)...), loader.PassphraseFlags("")...)...)
+	rekey.SetAction(action.rekeyAction)
+
+	export := signer.SetSubCommand("export")
+	export.SetDescription("write the decrypted signer to a file")
+	export.SetFlags(append([]cli.Flag{cli.StringFlag{
+		Name:     "path",
+		Usage:    "path to the encrypted signer's file",
+		Required: true,
+	}, cli.StringFlag{
+		Name:     "save",
+		Usage:    "file where the decrypted signer is saved",
+		Required: true,
+	}, cli.BoolFlag{
+		Name:     "force",
+		Usage:    "will overwrite the file if needed",
+		Required: false,
+	}}, loader.PassphraseFlags("")...)...)
+	export.SetAction(action.exportAction)
+
+	serve := signer.SetSubCommand("serve")
+	serve.SetDescription("run a signer daemon that signs for the nodes " +
+		"connected to its socket")
+	serve.SetFlags(append([]cli.Flag{cli.StringFlag{
+		Name:     "path",
+		Usage:    "path to the signer's file",
+		Required: true,
+	}, cli.StringFlag{
+		Name:     "socket",
+		Usage:    "path to the unix socket the daemon listens on",
+		Required: true,
+	}, cli.StringFlag{
+		Name: "state",
+		Usage: "file where the daemon records what it signed to prevent " +
+			"double signing across restarts",
+		Required: true,
+	}, cli.BoolFlag{
+		Name: "allow-unscoped",
+		Usage: "sign the messages without scope like certificate " +
+			"endorsements, which are not protected at all and therefore " +
+			"not recommended",
+		Required: false,
+	}}, loader.PassphraseFlags("")...)...)
+	serve.SetAction(action.serveAction)
 }
@@ -15,7 +15,7 @@ func TestSetCommands(t *testing.T) {
 	provider := fakeBuilder{call: call}
 	init.SetCommands(provider)
 
-	require.Equal(t, 10, call.Len())
+	require.Equal(t, 22, call.Len())
 }
 
 // -----------------------------------------------------------------------------
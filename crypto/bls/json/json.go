@@ -10,6 +10,8 @@ import (
 func init() {
 	bls.RegisterPublicKeyFormat(serde.FormatJSON, pubkeyFormat{})
 	bls.RegisterSignatureFormat(serde.FormatJSON, sigFormat{})
+	bls.RegisterPublicKeyFormat(serde.FormatCBOR, pubkeyFormat{})
+	bls.RegisterSignatureFormat(serde.FormatCBOR, sigFormat{})
 }
 
 // PubkeyFormat is the engine to encode and decode BLS-BN256 public keys in JSON
@@ -0,0 +1,552 @@
+// Package bls12381 implements the cryptographic primitives using the BLS
+// signature scheme and the BLS12-381 elliptic curve.
+//
+// The public keys are points of G1 and the signatures are points of G2, which
+// are both encoded in their compressed form. The signatures of a message can be
+// aggregated, and verified against the aggregate of the public keys. As such an
+// aggregation is vulnerable to rogue-key attacks, a public key must come with a
+// proof of possession of the private key before it can be trusted as a member
+// of a collective authority. The proof is a signature of the public key itself
+// under a different domain.
+//
+// Related Papers:
+//
+// https://datatracker.ietf.org/doc/draft-irtf-cfrg-bls-signature/
+package bls12381
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Algorithm is the name of the curve used for the BLS signature.
+	Algorithm = "BLS-CURVE-BLS12381"
+
+	// signatureDomain is the domain separation tag of the hash to the curve
+	// of the messages.
+	signatureDomain = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+	// possessionDomain is the domain separation tag of the hash to the curve
+	// of the proofs of possession.
+	possessionDomain = "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+)
+
+var (
+	pubkeyFormats = registry.NewSimpleRegistry()
+	sigFormats    = registry.NewSimpleRegistry()
+)
+
+// RegisterPublicKeyFormat registers the engine for the provided format.
+func RegisterPublicKeyFormat(c serde.Format, f serde.FormatEngine) {
+	pubkeyFormats.Register(c, f)
+}
+
+// RegisterSignatureFormat registers the engine for the provided format.
+func RegisterSignatureFormat(c serde.Format, f serde.FormatEngine) {
+	sigFormats.Register(c, f)
+}
+
+// PublicKey is the adapter of a BLS12-381 public key.
+//
+// - implements crypto.PublicKey
+// - implements crypto.PossessionVerifier
+type PublicKey struct {
+	point *bls12381.G1
+}
+
+// NewPublicKey creates a new public key by unmarshaling the data into a point
+// of G1. It returns an error if the point is not a valid public key.
+func NewPublicKey(data []byte) (PublicKey, error) {
+	point := new(bls12381.G1)
+
+	err := point.SetBytes(data)
+	if err != nil {
+		return PublicKey{}, xerrors.Errorf("invalid point: %v", err)
+	}
+
+	if point.IsIdentity() || !point.IsOnG1() {
+		return PublicKey{}, xerrors.New("point is not a valid public key")
+	}
+
+	return PublicKey{point: point}, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It produces a slice of
+// bytes representing the public key.
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	return pk.point.BytesCompressed(), nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// public key.
+func (pk PublicKey) Serialize(ctx serde.Context) ([]byte, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, pk)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode public key: %v", err)
+	}
+
+	return data, nil
+}
+
+// Verify implements crypto.PublicKey. It returns nil if the signature matches
+// the message for this public key.
+func (pk PublicKey) Verify(msg []byte, sig crypto.Signature) error {
+	signature, ok := sig.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", sig)
+	}
+
+	err := verify(pk.point, msg, signature.data, signatureDomain)
+	if err != nil {
+		return xerrors.Errorf("bls verify failed: %v", err)
+	}
+
+	return nil
+}
+
+// VerifyPossession implements crypto.PossessionVerifier. It returns nil if the
+// proof shows that the owner of the public key knows the private key.
+func (pk PublicKey) VerifyPossession(proof crypto.Signature) error {
+	signature, ok := proof.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid proof type '%T'", proof)
+	}
+
+	err := verify(pk.point, pk.point.BytesCompressed(), signature.data, possessionDomain)
+	if err != nil {
+		return xerrors.Errorf("invalid proof of possession: %v", err)
+	}
+
+	return nil
+}
+
+// Equal implements crypto.PublicKey. It returns true if the other public key
+// is the same.
+func (pk PublicKey) Equal(other interface{}) bool {
+	pubkey, ok := other.(PublicKey)
+	if !ok {
+		return false
+	}
+
+	return pubkey.point.IsEqual(pk.point)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns a text
+// representation of the public key.
+func (pk PublicKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("bls12381:%x", pk.point.BytesCompressed())), nil
+}
+
+// String implements fmt.String. It returns a string representation of the
+// point.
+func (pk PublicKey) String() string {
+	buffer, _ := pk.MarshalText()
+
+	// Output only the prefix and 16 characters of the buffer in hexadecimal.
+	return string(buffer)[:9+16]
+}
+
+// Signature is the adapter of a BLS12-381 signature.
+//
+// - implements crypto.Signature
+type Signature struct {
+	data []byte
+}
+
+// NewSignature creates a new signature from the provided data.
+func NewSignature(data []byte) Signature {
+	return Signature{
+		data: data,
+	}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns a slice of
+// bytes representing the signature.
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	return sig.data, nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// signature.
+func (sig Signature) Serialize(ctx serde.Context) ([]byte, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, sig)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode signature: %v", err)
+	}
+
+	return data, nil
+}
+
+// Equal implements crypto.Signature. It returns true if both signatures are the
+// same.
+func (sig Signature) Equal(other crypto.Signature) bool {
+	otherSig, ok := other.(Signature)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(sig.data, otherSig.data)
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// signature.
+func (sig Signature) String() string {
+	return fmt.Sprintf("bls12381:%x", sig.data)
+}
+
+// publicKeyFactory is a factory to deserialize public keys of the BLS12-381
+// elliptic curve.
+//
+// - implements crypto.PublicKeyFactory
+type publicKeyFactory struct{}
+
+// NewPublicKeyFactory returns a new instance of the factory.
+func NewPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the public key of the data
+// if appropriate, otherwise an error.
+func (f publicKeyFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	m, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode public key: %v", err)
+	}
+
+	return m, nil
+}
+
+// PublicKeyOf implements crypto.PublicKeyFactory. It returns the public key of
+// the data if appropriate, otherwise an error.
+func (f publicKeyFactory) PublicKeyOf(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	m, err := f.Deserialize(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	pubkey, ok := m.(crypto.PublicKey)
+	if !ok {
+		return nil, xerrors.Errorf("invalid public key of type '%T'", m)
+	}
+
+	return pubkey, nil
+}
+
+// FromBytes implements crypto.PublicKeyFactory. It returns the public key
+// unmarshaled from the bytes.
+func (f publicKeyFactory) FromBytes(data []byte) (crypto.PublicKey, error) {
+	pubkey, err := NewPublicKey(data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+// signatureFactory is a factory to deserialize signatures of the BLS12-381
+// elliptic curve.
+//
+// - implements crypto.SignatureFactory
+type signatureFactory struct{}
+
+// NewSignatureFactory returns a new instance of the factory.
+func NewSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the signature of the data if
+// appropriate, otherwise an error.
+func (f signatureFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	m, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode signature: %v", err)
+	}
+
+	return m, nil
+}
+
+// SignatureOf implements crypto.SignatureFactory. It returns the signature of
+// the data if appropriate, otherwise an error.
+func (f signatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Signature, error) {
+	m, err := f.Deserialize(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, ok := m.(Signature)
+	if !ok {
+		return nil, xerrors.Errorf("invalid signature of type '%T'", m)
+	}
+
+	return sig, nil
+}
+
+// blsVerifier is a verifier for BLS signatures to match against a message and
+// the aggregate of the public keys of one or several identities.
+//
+// - implements crypto.Verifier
+type blsVerifier struct {
+	points []*bls12381.G1
+}
+
+// Verify implements crypto.Verifier. It returns nil if the signature matches
+// the message, or an error otherwise.
+func (v blsVerifier) Verify(msg []byte, sig crypto.Signature) error {
+	signature, ok := sig.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", sig)
+	}
+
+	aggKey := new(bls12381.G1)
+	aggKey.SetIdentity()
+
+	for _, point := range v.points {
+		aggKey.Add(aggKey, point)
+	}
+
+	return verify(aggKey, msg, signature.data, signatureDomain)
+}
+
+// verifierFactory is a factory to create verifiers from an authority or a list
+// of public keys. The public keys are expected to have been checked with their
+// proof of possession beforehand.
+//
+// - implements crypto.VerifierFactory
+type verifierFactory struct{}
+
+// NewVerifierFactory returns a new instance of the factory.
+func NewVerifierFactory() crypto.VerifierFactory {
+	return verifierFactory{}
+}
+
+// FromAuthority implements crypto.VerifierFactory. It returns a verifier that
+// will verify the signatures collectively signed by all the members of the
+// authority.
+func (v verifierFactory) FromAuthority(ca crypto.CollectiveAuthority) (crypto.Verifier, error) {
+	if ca == nil {
+		return nil, xerrors.New("authority is nil")
+	}
+
+	pubkeys := make([]crypto.PublicKey, 0, ca.Len())
+
+	iter := ca.PublicKeyIterator()
+	for iter.HasNext() {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	return v.FromArray(pubkeys)
+}
+
+// FromArray implements crypto.VerifierFactory. It returns a verifier that will
+// verify the signatures collectively signed by all the signers associated with
+// the public keys. It returns an error if the list is empty.
+func (v verifierFactory) FromArray(publicKeys []crypto.PublicKey) (crypto.Verifier, error) {
+	if len(publicKeys) == 0 {
+		return nil, xerrors.New("no public key")
+	}
+
+	points := make([]*bls12381.G1, len(publicKeys))
+
+	for i, pubkey := range publicKeys {
+		pk, ok := pubkey.(PublicKey)
+		if !ok {
+			return nil, xerrors.Errorf("invalid public key type: %T", pubkey)
+		}
+
+		points[i] = pk.point
+	}
+
+	return blsVerifier{points: points}, nil
+}
+
+// Signer is the adapter of a private key of the BLS12-381 elliptic curve.
+//
+// - implements crypto.AggregateSigner
+// - implements crypto.PossessionProver
+// - implements encoding.BinaryMarshaler
+type Signer struct {
+	public  *bls12381.G1
+	private *bls12381.Scalar
+}
+
+// NewSigner generates and returns a new random signer.
+func NewSigner() Signer {
+	return Generate().(Signer)
+}
+
+// NewSignerFromBytes restores a signer from a marshalling.
+func NewSignerFromBytes(data []byte) (crypto.AggregateSigner, error) {
+	scalar := new(bls12381.Scalar)
+
+	err := scalar.UnmarshalBinary(data)
+	if err != nil {
+		return nil, xerrors.Errorf("while unmarshaling scalar: %v", err)
+	}
+
+	if scalar.IsZero() == 1 {
+		return nil, xerrors.New("private key is zero")
+	}
+
+	return newSigner(scalar), nil
+}
+
+// Generate returns a new random BLS12-381 signer that supports aggregation.
+func Generate() crypto.Signer {
+	scalar := new(bls12381.Scalar)
+
+	for scalar.IsZero() == 1 {
+		err := scalar.Random(rand.Reader)
+		if err != nil {
+			panic(fmt.Sprintf("random source failed: %v", err))
+		}
+	}
+
+	return newSigner(scalar)
+}
+
+func newSigner(scalar *bls12381.Scalar) Signer {
+	public := new(bls12381.G1)
+	public.ScalarMult(scalar, bls12381.G1Generator())
+
+	return Signer{
+		public:  public,
+		private: scalar,
+	}
+}
+
+// GetVerifierFactory implements crypto.AggregateSigner. It returns the verifier
+// factory for BLS12-381 signatures.
+func (s Signer) GetVerifierFactory() crypto.VerifierFactory {
+	return verifierFactory{}
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns the public key
+// factory for BLS12-381 signatures.
+func (s Signer) GetPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns the signature
+// factory for BLS12-381 signatures.
+func (s Signer) GetSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// signer that can be used to verify signatures.
+func (s Signer) GetPublicKey() crypto.PublicKey {
+	return PublicKey{point: s.public}
+}
+
+// Sign implements crypto.Signer. It signs the message in parameter and returns
+// the signature.
+func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
+	return Signature{data: s.sign(msg, signatureDomain)}, nil
+}
+
+// ProvePossession implements crypto.PossessionProver. It returns the proof of
+// possession of the private key, which is the signature of the public key.
+func (s Signer) ProvePossession() (crypto.Signature, error) {
+	return Signature{data: s.sign(s.public.BytesCompressed(), possessionDomain)}, nil
+}
+
+// Aggregate implements crypto.AggregateSigner. It aggregates the signatures
+// into a single one that can be verified with the aggregated public key
+// associated.
+func (s Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
+	agg := new(bls12381.G2)
+	agg.SetIdentity()
+
+	for _, sig := range signatures {
+		signature, ok := sig.(Signature)
+		if !ok {
+			return nil, xerrors.Errorf("invalid signature type '%T'", sig)
+		}
+
+		point := new(bls12381.G2)
+
+		err := point.SetBytes(signature.data)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't aggregate: %v", err)
+		}
+
+		agg.Add(agg, point)
+	}
+
+	return Signature{data: agg.BytesCompressed()}, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns a binary
+// representation of the signer.
+func (s Signer) MarshalBinary() ([]byte, error) {
+	data, err := s.private.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("while marshaling scalar: %v", err)
+	}
+
+	return data, nil
+}
+
+func (s Signer) sign(msg []byte, domain string) []byte {
+	point := new(bls12381.G2)
+	point.Hash(msg, []byte(domain))
+	point.ScalarMult(s.private, point)
+
+	return point.BytesCompressed()
+}
+
+// verify checks that the signature matches the message for the public key by
+// comparing the pairings e(pubkey, H(msg)) and e(g1, sig). The identity is
+// rejected for both as it would match any message, for instance when the
+// public keys of an aggregate cancel each other.
+func verify(pubkey *bls12381.G1, msg, sig []byte, domain string) error {
+	if pubkey.IsIdentity() {
+		return xerrors.New("public key is the identity")
+	}
+
+	point := new(bls12381.G2)
+
+	err := point.SetBytes(sig)
+	if err != nil {
+		return xerrors.Errorf("invalid signature: %v", err)
+	}
+
+	if !point.IsOnG2() {
+		return xerrors.New("signature is not in the group")
+	}
+
+	if point.IsIdentity() {
+		return xerrors.New("signature is the identity")
+	}
+
+	hash := new(bls12381.G2)
+	hash.Hash(msg, []byte(domain))
+
+	res := bls12381.ProdPairFrac(
+		[]*bls12381.G1{pubkey, bls12381.G1Generator()},
+		[]*bls12381.G2{hash, point},
+		[]int{1, -1},
+	)
+
+	if !res.IsIdentity() {
+		return xerrors.New("signature mismatch")
+	}
+
+	return nil
+}
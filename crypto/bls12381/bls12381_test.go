@@ -0,0 +1,296 @@
+package bls12381
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func init() {
+	RegisterPublicKeyFormat(fake.GoodFormat, fake.Format{Msg: PublicKey{}})
+	RegisterPublicKeyFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
+	RegisterPublicKeyFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: Signature{}})
+	RegisterSignatureFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
+	RegisterSignatureFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestPublicKey_New(t *testing.T) {
+	signer := Generate()
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, 48)
+
+	pk, err := NewPublicKey(data)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(pk))
+
+	_, err = NewPublicKey(nil)
+	require.Error(t, err)
+
+	// The point at infinity is a valid encoding, but not a valid public key.
+	identity := make([]byte, 48)
+	identity[0] = 0xc0
+
+	_, err = NewPublicKey(identity)
+	require.EqualError(t, err, "point is not a valid public key")
+}
+
+func TestPublicKey_Serialize(t *testing.T) {
+	pubkey := NewSigner().GetPublicKey()
+
+	data, err := pubkey.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = pubkey.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode public key"))
+}
+
+func TestPublicKey_Verify(t *testing.T) {
+	msg := []byte("deadbeef")
+	signer := Generate()
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	err = signer.GetPublicKey().Verify(msg, sig)
+	require.NoError(t, err)
+
+	err = signer.GetPublicKey().Verify([]byte("abc"), sig)
+	require.EqualError(t, err, "bls verify failed: signature mismatch")
+
+	err = Generate().GetPublicKey().Verify(msg, sig)
+	require.EqualError(t, err, "bls verify failed: signature mismatch")
+
+	err = signer.GetPublicKey().Verify(msg, NewSignature([]byte{1, 2, 3}))
+	require.Error(t, err)
+
+	err = signer.GetPublicKey().Verify(msg, fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+}
+
+func TestPublicKey_VerifyPossession(t *testing.T) {
+	signer := NewSigner()
+
+	proof, err := signer.ProvePossession()
+	require.NoError(t, err)
+
+	pubkey := signer.GetPublicKey().(PublicKey)
+
+	err = pubkey.VerifyPossession(proof)
+	require.NoError(t, err)
+
+	err = NewSigner().GetPublicKey().(PublicKey).VerifyPossession(proof)
+	require.EqualError(t, err, "invalid proof of possession: signature mismatch")
+
+	// A signature of the public key is not a proof of possession as it belongs
+	// to a different domain.
+	data, err := pubkey.MarshalBinary()
+	require.NoError(t, err)
+
+	sig, err := signer.Sign(data)
+	require.NoError(t, err)
+
+	err = pubkey.VerifyPossession(sig)
+	require.EqualError(t, err, "invalid proof of possession: signature mismatch")
+
+	err = pubkey.VerifyPossession(fake.Signature{})
+	require.EqualError(t, err, "invalid proof type 'fake.Signature'")
+}
+
+func TestPublicKey_Equal(t *testing.T) {
+	signer := NewSigner()
+
+	require.True(t, signer.GetPublicKey().Equal(signer.GetPublicKey()))
+	require.False(t, signer.GetPublicKey().Equal(NewSigner().GetPublicKey()))
+	require.False(t, signer.GetPublicKey().Equal(fake.PublicKey{}))
+}
+
+func TestPublicKey_String(t *testing.T) {
+	pubkey := NewSigner().GetPublicKey()
+
+	text, err := pubkey.MarshalText()
+	require.NoError(t, err)
+	require.Regexp(t, "^bls12381:[0-9a-f]{96}$", string(text))
+
+	require.Regexp(t, "^bls12381:[0-9a-f]{16}$", pubkey.(PublicKey).String())
+}
+
+func TestPublicKeyFactory_PublicKeyOf(t *testing.T) {
+	factory := NewPublicKeyFactory()
+
+	pubkey, err := factory.PublicKeyOf(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.IsType(t, PublicKey{}, pubkey)
+
+	_, err = factory.PublicKeyOf(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode public key"))
+
+	_, err = factory.PublicKeyOf(fake.NewContextWithFormat(serde.Format("BAD_TYPE")), nil)
+	require.EqualError(t, err, "invalid public key of type 'fake.Message'")
+}
+
+func TestPublicKeyFactory_FromBytes(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := NewPublicKeyFactory().FromBytes(data)
+	require.NoError(t, err)
+	require.True(t, pubkey.Equal(signer.GetPublicKey()))
+
+	_, err = NewPublicKeyFactory().FromBytes(nil)
+	require.Error(t, err)
+}
+
+func TestSignature_Equal(t *testing.T) {
+	sig := NewSignature([]byte{1, 2, 3})
+
+	require.True(t, sig.Equal(NewSignature([]byte{1, 2, 3})))
+	require.False(t, sig.Equal(NewSignature([]byte{1, 2})))
+	require.False(t, sig.Equal(fake.Signature{}))
+	require.Equal(t, "bls12381:010203", sig.String())
+}
+
+func TestSignature_Serialize(t *testing.T) {
+	sig := NewSignature(nil)
+
+	data, err := sig.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = sig.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode signature"))
+}
+
+func TestSignatureFactory_SignatureOf(t *testing.T) {
+	factory := NewSignatureFactory()
+
+	sig, err := factory.SignatureOf(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.IsType(t, Signature{}, sig)
+
+	_, err = factory.SignatureOf(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode signature"))
+
+	_, err = factory.SignatureOf(fake.NewContextWithFormat(serde.Format("BAD_TYPE")), nil)
+	require.EqualError(t, err, "invalid signature of type 'fake.Message'")
+}
+
+func TestVerifierFactory_FromAuthority(t *testing.T) {
+	msg := []byte("deadbeef")
+	ca := fake.NewAuthority(3, Generate)
+
+	verifier, err := NewVerifierFactory().FromAuthority(ca)
+	require.NoError(t, err)
+
+	sigs := make([]crypto.Signature, ca.Len())
+	for i := range sigs {
+		sigs[i], err = ca.GetSigner(i).Sign(msg)
+		require.NoError(t, err)
+	}
+
+	signer := ca.GetSigner(0).(Signer)
+
+	agg, err := signer.Aggregate(sigs...)
+	require.NoError(t, err)
+
+	err = verifier.Verify(msg, agg)
+	require.NoError(t, err)
+
+	agg, err = signer.Aggregate(sigs[:2]...)
+	require.NoError(t, err)
+
+	err = verifier.Verify(msg, agg)
+	require.EqualError(t, err, "signature mismatch")
+
+	err = verifier.Verify(msg, fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	_, err = NewVerifierFactory().FromAuthority(nil)
+	require.EqualError(t, err, "authority is nil")
+
+	_, err = NewVerifierFactory().FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+	require.EqualError(t, err, "invalid public key type: fake.PublicKey")
+}
+
+func TestVerifierFactory_FromArray(t *testing.T) {
+	_, err := NewVerifierFactory().FromArray(nil)
+	require.EqualError(t, err, "no public key")
+
+	_, err = NewVerifierFactory().FromArray([]crypto.PublicKey{})
+	require.EqualError(t, err, "no public key")
+}
+
+func TestVerifier_Identity_Verify(t *testing.T) {
+	msg := []byte("deadbeef")
+	signer := NewSigner()
+
+	// The public keys cancel each other in the aggregate.
+	neg := *signer.public
+	neg.Neg()
+
+	verifier, err := NewVerifierFactory().FromArray([]crypto.PublicKey{
+		signer.GetPublicKey(), PublicKey{point: &neg},
+	})
+	require.NoError(t, err)
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	err = verifier.Verify(msg, sig)
+	require.EqualError(t, err, "public key is the identity")
+
+	verifier, err = NewVerifierFactory().FromArray([]crypto.PublicKey{signer.GetPublicKey()})
+	require.NoError(t, err)
+
+	identity, err := signer.Aggregate()
+	require.NoError(t, err)
+
+	err = verifier.Verify(msg, identity)
+	require.EqualError(t, err, "signature is the identity")
+}
+
+func TestSigner_Aggregate(t *testing.T) {
+	signer := NewSigner()
+
+	agg, err := signer.Aggregate()
+	require.NoError(t, err)
+	require.Len(t, agg.(Signature).data, 96)
+
+	_, err = signer.Aggregate(fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	_, err = signer.Aggregate(NewSignature([]byte{1}))
+	require.Error(t, err)
+}
+
+func TestSigner_MarshalBinary(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	restored, err := NewSignerFromBytes(data)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(restored.GetPublicKey()))
+
+	_, err = NewSignerFromBytes(make([]byte, 32))
+	require.EqualError(t, err, "private key is zero")
+
+	_, err = NewSignerFromBytes(nil)
+	require.Error(t, err)
+}
+
+func TestSigner_Factories(t *testing.T) {
+	signer := NewSigner()
+
+	require.Equal(t, verifierFactory{}, signer.GetVerifierFactory())
+	require.Equal(t, publicKeyFactory{}, signer.GetPublicKeyFactory())
+	require.Equal(t, signatureFactory{}, signer.GetSignatureFactory())
+}
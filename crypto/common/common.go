@@ -7,6 +7,7 @@ package common
 import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
@@ -74,6 +75,7 @@ func NewPublicKeyFactory() PublicKeyFac {
 	}
 
 	factory.RegisterAlgorithm(bls.Algorithm, bls.NewPublicKeyFactory())
+	factory.RegisterAlgorithm(bls12381.Algorithm, bls12381.NewPublicKeyFactory())
 
 	return factory
 }
@@ -126,13 +128,14 @@ type SignatureFactory struct {
 }
 
 // NewSignatureFactory returns a new instance of the common signature factory.
-// It registers the BLS algorithm by default.
+// It registers the BLS algorithms by default.
 func NewSignatureFactory() SignatureFactory {
 	factory := SignatureFactory{
 		factories: make(map[string]crypto.SignatureFactory),
 	}
 
 	factory.RegisterAlgorithm(bls.Algorithm, bls.NewSignatureFactory())
+	factory.RegisterAlgorithm(bls12381.Algorithm, bls12381.NewSignatureFactory())
 
 	return factory
 }
@@ -45,10 +45,10 @@ func TestPublicKeyFactory_RegisterAlgorithm(t *testing.T) {
 	factory := NewPublicKeyFactory()
 
 	// Check passive registrations.
-	require.Len(t, factory.factories, 1)
+	require.Len(t, factory.factories, 2)
 
 	factory.RegisterAlgorithm(testAlgorithm, fake.PublicKeyFactory{})
-	require.Len(t, factory.factories, 2)
+	require.Len(t, factory.factories, 3)
 }
 
 func TestPublicKeyFactory_Deserialize(t *testing.T) {
@@ -85,10 +85,10 @@ func TestPublicKeyFactory_PublicKeyOf(t *testing.T) {
 func TestSignatureFactory_RegisterAlgorithm(t *testing.T) {
 	factory := NewSignatureFactory()
 
-	require.Len(t, factory.factories, 1)
+	require.Len(t, factory.factories, 2)
 
 	factory.RegisterAlgorithm("fake", fake.SignatureFactory{})
-	require.Len(t, factory.factories, 2)
+	require.Len(t, factory.factories, 3)
 }
 
 func TestSignatureFactory_Deserialize(t *testing.T) {
@@ -8,6 +8,7 @@ import (
 
 func init() {
 	common.RegisterAlgorithmFormat(serde.FormatJSON, algoFormat{})
+	common.RegisterAlgorithmFormat(serde.FormatCBOR, algoFormat{})
 }
 
 // Algorithm is a common JSON message to identify which algorithm is used in a
@@ -0,0 +1,321 @@
+package common
+
+import (
+	"sync"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// Signer is an aggregate signer that holds a signer per algorithm and signs
+// with the active one. The factories support every algorithm of the signer so
+// that a chain can be followed across a migration from one algorithm to
+// another.
+//
+// - implements crypto.AggregateSigner
+// - implements crypto.PossessionProver
+// - implements crypto.ScopedSigner
+// - implements crypto.UnscopedSigner
+type Signer struct {
+	sync.Mutex
+
+	names   []string
+	signers map[string]crypto.AggregateSigner
+	active  string
+}
+
+// NewSigner creates a new signer with the given signer as the active one.
+func NewSigner(algorithm string, signer crypto.AggregateSigner) *Signer {
+	s := &Signer{
+		signers: make(map[string]crypto.AggregateSigner),
+		active:  algorithm,
+	}
+
+	s.Add(algorithm, signer)
+
+	return s
+}
+
+// Add adds or replaces the signer of an algorithm.
+func (s *Signer) Add(algorithm string, signer crypto.AggregateSigner) {
+	s.Lock()
+	defer s.Unlock()
+
+	_, found := s.signers[algorithm]
+	if !found {
+		s.names = append(s.names, algorithm)
+	}
+
+	s.signers[algorithm] = signer
+}
+
+// Get returns the signer of the algorithm, or nil if it is unknown.
+func (s *Signer) Get(algorithm string) crypto.AggregateSigner {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.signers[algorithm]
+}
+
+// GetAlgorithm returns the name of the active algorithm.
+func (s *Signer) GetAlgorithm() string {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.active
+}
+
+// Activate sets the algorithm used to sign.
+func (s *Signer) Activate(algorithm string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	_, found := s.signers[algorithm]
+	if !found {
+		return xerrors.Errorf("unknown algorithm '%s'", algorithm)
+	}
+
+	s.active = algorithm
+
+	return nil
+}
+
+// ActivateFor sets the algorithm that can verify the signatures of the
+// authority, and returns its name.
+func (s *Signer) ActivateFor(ca crypto.CollectiveAuthority) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, name := range s.names {
+		_, err := s.signers[name].GetVerifierFactory().FromAuthority(ca)
+		if err == nil {
+			s.active = name
+			return name, nil
+		}
+	}
+
+	return "", xerrors.New("no algorithm supports the authority")
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns a factory that
+// supports the public keys of every algorithm of the signer.
+func (s *Signer) GetPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{signer: s}
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns a factory that
+// supports the signatures of every algorithm of the signer.
+func (s *Signer) GetSignatureFactory() crypto.SignatureFactory {
+	factory := SignatureFactory{
+		factories: make(map[string]crypto.SignatureFactory),
+	}
+
+	for _, signer := range s.all() {
+		factory.RegisterAlgorithm(signer.name, signer.GetSignatureFactory())
+	}
+
+	return factory
+}
+
+// GetVerifierFactory implements crypto.AggregateSigner. It returns a factory
+// that creates the verifier of the algorithm of the public keys.
+func (s *Signer) GetVerifierFactory() crypto.VerifierFactory {
+	return verifierFactory{signer: s}
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// active signer.
+func (s *Signer) GetPublicKey() crypto.PublicKey {
+	return s.current().GetPublicKey()
+}
+
+// Sign implements crypto.Signer. It signs the message with the active signer.
+func (s *Signer) Sign(msg []byte) (crypto.Signature, error) {
+	return s.current().Sign(msg)
+}
+
+// Aggregate implements crypto.AggregateSigner. It aggregates the signatures
+// with the first signer, starting with the active one, that supports them.
+func (s *Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
+	var err error
+
+	for _, signer := range s.all() {
+		var agg crypto.Signature
+
+		agg, err = signer.Aggregate(signatures...)
+		if err == nil {
+			return agg, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("couldn't aggregate: %v", err)
+}
+
+// ProvePossession implements crypto.PossessionProver. It returns the proof of
+// possession of the active signer if it supports it.
+func (s *Signer) ProvePossession() (crypto.Signature, error) {
+	prover, ok := s.current().(crypto.PossessionProver)
+	if !ok {
+		return nil, xerrors.Errorf("algorithm '%s' has no proof of possession",
+			s.GetAlgorithm())
+	}
+
+	return prover.ProvePossession()
+}
+
+// BindScope implements crypto.ScopedSigner. It declares the scope of the
+// message to every signer that supports it, as the active algorithm can change
+// before the message is signed.
+func (s *Signer) BindScope(msg []byte, scope crypto.SignScope) {
+	for _, signer := range s.all() {
+		scoped, ok := signer.AggregateSigner.(crypto.ScopedSigner)
+		if ok {
+			scoped.BindScope(msg, scope)
+		}
+	}
+}
+
+// Unscoped implements crypto.UnscopedSigner. It returns a signer that signs the
+// messages without scope with the active signer.
+func (s *Signer) Unscoped() crypto.Signer {
+	return unscopedSigner{Signer: s}
+}
+
+func (s *Signer) current() crypto.AggregateSigner {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.signers[s.active]
+}
+
+type namedSigner struct {
+	crypto.AggregateSigner
+	name string
+}
+
+// all returns the signers with the active one first.
+func (s *Signer) all() []namedSigner {
+	s.Lock()
+	defer s.Unlock()
+
+	signers := []namedSigner{{AggregateSigner: s.signers[s.active], name: s.active}}
+
+	for _, name := range s.names {
+		if name != s.active {
+			signers = append(signers, namedSigner{AggregateSigner: s.signers[name], name: name})
+		}
+	}
+
+	return signers
+}
+
+// publicKeyFactory is a factory for the public keys of the algorithms of a
+// signer.
+//
+// - implements crypto.PublicKeyFactory
+type publicKeyFactory struct {
+	signer *Signer
+}
+
+// Deserialize implements serde.Factory. It returns the public key of the data
+// according to its algorithm.
+func (f publicKeyFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.PublicKeyOf(ctx, data)
+}
+
+// PublicKeyOf implements crypto.PublicKeyFactory. It returns the public key of
+// the data according to its algorithm.
+func (f publicKeyFactory) PublicKeyOf(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	factory := PublicKeyFac{
+		factories: make(map[string]crypto.PublicKeyFactory),
+	}
+
+	for _, signer := range f.signer.all() {
+		factory.RegisterAlgorithm(signer.name, signer.GetPublicKeyFactory())
+	}
+
+	return factory.PublicKeyOf(ctx, data)
+}
+
+// FromBytes implements crypto.PublicKeyFactory. It returns the public key of
+// the first algorithm, starting with the active one, that can unmarshal the
+// data.
+func (f publicKeyFactory) FromBytes(data []byte) (crypto.PublicKey, error) {
+	var err error
+
+	for _, signer := range f.signer.all() {
+		var pubkey crypto.PublicKey
+
+		pubkey, err = signer.GetPublicKeyFactory().FromBytes(data)
+		if err == nil {
+			return pubkey, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("no algorithm matches: %v", err)
+}
+
+// verifierFactory is a factory that creates the verifier of the algorithm the
+// public keys belong to.
+//
+// - implements crypto.VerifierFactory
+type verifierFactory struct {
+	signer *Signer
+}
+
+// FromAuthority implements crypto.VerifierFactory. It returns the verifier of
+// the first algorithm, starting with the active one, that supports the
+// authority.
+func (f verifierFactory) FromAuthority(ca crypto.CollectiveAuthority) (crypto.Verifier, error) {
+	var err error
+
+	for _, signer := range f.signer.all() {
+		var verifier crypto.Verifier
+
+		verifier, err = signer.GetVerifierFactory().FromAuthority(ca)
+		if err == nil {
+			return verifier, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("no algorithm matches: %v", err)
+}
+
+// FromArray implements crypto.VerifierFactory. It returns the verifier of the
+// first algorithm, starting with the active one, that supports the public
+// keys.
+func (f verifierFactory) FromArray(pubkeys []crypto.PublicKey) (crypto.Verifier, error) {
+	var err error
+
+	for _, signer := range f.signer.all() {
+		var verifier crypto.Verifier
+
+		verifier, err = signer.GetVerifierFactory().FromArray(pubkeys)
+		if err == nil {
+			return verifier, nil
+		}
+	}
+
+	return nil, xerrors.Errorf("no algorithm matches: %v", err)
+}
+
+// unscopedSigner signs the messages without scope with the active signer, or
+// its unscoped signer when it has one.
+//
+// - implements crypto.Signer
+type unscopedSigner struct {
+	*Signer
+}
+
+// Sign implements crypto.Signer. It signs the message without scope.
+func (s unscopedSigner) Sign(msg []byte) (crypto.Signature, error) {
+	signer := s.current()
+
+	unscoped, ok := signer.(crypto.UnscopedSigner)
+	if ok {
+		return unscoped.Unscoped().Sign(msg)
+	}
+
+	return signer.Sign(msg)
+}
@@ -0,0 +1,212 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestSigner_Activate(t *testing.T) {
+	signer := makeSigner()
+	require.Equal(t, bls.Algorithm, signer.GetAlgorithm())
+
+	err := signer.Activate(bls12381.Algorithm)
+	require.NoError(t, err)
+	require.Equal(t, bls12381.Algorithm, signer.GetAlgorithm())
+	require.IsType(t, bls12381.PublicKey{}, signer.GetPublicKey())
+
+	err = signer.Activate("unknown")
+	require.EqualError(t, err, "unknown algorithm 'unknown'")
+	require.Equal(t, bls12381.Algorithm, signer.GetAlgorithm())
+
+	require.NotNil(t, signer.Get(bls.Algorithm))
+	require.Nil(t, signer.Get("unknown"))
+}
+
+func TestSigner_ActivateFor(t *testing.T) {
+	signer := makeSigner()
+
+	name, err := signer.ActivateFor(fake.NewAuthority(2, bls12381.Generate))
+	require.NoError(t, err)
+	require.Equal(t, bls12381.Algorithm, name)
+	require.Equal(t, bls12381.Algorithm, signer.GetAlgorithm())
+
+	name, err = signer.ActivateFor(fake.NewAuthority(2, bls.Generate))
+	require.NoError(t, err)
+	require.Equal(t, bls.Algorithm, name)
+
+	_, err = signer.ActivateFor(fake.NewAuthority(2, fake.NewSigner))
+	require.EqualError(t, err, "no algorithm supports the authority")
+	require.Equal(t, bls.Algorithm, signer.GetAlgorithm())
+}
+
+func TestSigner_Sign(t *testing.T) {
+	msg := []byte("deadbeef")
+	signer := makeSigner()
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+	require.NoError(t, signer.GetPublicKey().Verify(msg, sig))
+
+	require.NoError(t, signer.Activate(bls12381.Algorithm))
+
+	sig, err = signer.Sign(msg)
+	require.NoError(t, err)
+	require.IsType(t, bls12381.Signature{}, sig)
+	require.NoError(t, signer.GetPublicKey().Verify(msg, sig))
+}
+
+func TestSigner_Aggregate(t *testing.T) {
+	msg := []byte("deadbeef")
+	signer := makeSigner()
+	ca := fake.NewAuthority(3, bls12381.Generate)
+
+	sigs := make([]crypto.Signature, ca.Len())
+	pubkeys := make([]crypto.PublicKey, ca.Len())
+	for i := range sigs {
+		var err error
+		sigs[i], err = ca.GetSigner(i).Sign(msg)
+		require.NoError(t, err)
+
+		pubkeys[i] = ca.GetSigner(i).GetPublicKey()
+	}
+
+	// The active algorithm is BN256 but the signatures are aggregated by the
+	// algorithm that supports them.
+	agg, err := signer.Aggregate(sigs...)
+	require.NoError(t, err)
+
+	verifier, err := signer.GetVerifierFactory().FromAuthority(ca)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(msg, agg))
+
+	verifier, err = signer.GetVerifierFactory().FromArray(pubkeys)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(msg, agg))
+
+	_, err = signer.Aggregate(fake.Signature{})
+	require.EqualError(t, err,
+		"couldn't aggregate: invalid signature type 'fake.Signature'")
+
+	_, err = signer.GetVerifierFactory().FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+	require.ErrorContains(t, err, "no algorithm matches: ")
+
+	_, err = signer.GetVerifierFactory().FromArray([]crypto.PublicKey{fake.PublicKey{}})
+	require.ErrorContains(t, err, "no algorithm matches: ")
+}
+
+func TestSigner_ProvePossession(t *testing.T) {
+	signer := makeSigner()
+
+	_, err := signer.ProvePossession()
+	require.EqualError(t, err,
+		"algorithm 'BLS-CURVE-BN256' has no proof of possession")
+
+	require.NoError(t, signer.Activate(bls12381.Algorithm))
+
+	proof, err := signer.ProvePossession()
+	require.NoError(t, err)
+
+	pubkey := signer.GetPublicKey().(crypto.PossessionVerifier)
+	require.NoError(t, pubkey.VerifyPossession(proof))
+}
+
+func TestSigner_PublicKeyFactory(t *testing.T) {
+	signer := makeSigner()
+	factory := signer.GetPublicKeyFactory()
+
+	other := bls12381.NewSigner()
+
+	data, err := other.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := factory.FromBytes(data)
+	require.NoError(t, err)
+	require.True(t, other.GetPublicKey().Equal(pubkey))
+
+	_, err = factory.FromBytes(nil)
+	require.ErrorContains(t, err, "no algorithm matches: ")
+
+	signer.Add(testAlgorithm, fake.NewSigner().(crypto.AggregateSigner))
+
+	ctx := fake.NewContext()
+
+	pubkey, err = factory.PublicKeyOf(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, fake.PublicKey{}, pubkey)
+
+	msg, err := factory.Deserialize(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, fake.PublicKey{}, msg)
+}
+
+func TestSigner_BindScope(t *testing.T) {
+	scoped := &scopedSigner{AggregateSigner: bls12381.NewSigner()}
+
+	signer := makeSigner()
+	signer.Add(bls12381.Algorithm, scoped)
+
+	scope := crypto.SignScope{Kind: "prepare", Index: 1}
+
+	// The scope reaches the signer even if it is not the active one.
+	signer.BindScope([]byte("deadbeef"), scope)
+	require.Equal(t, []crypto.SignScope{scope}, scoped.scopes)
+}
+
+func TestSigner_Unscoped(t *testing.T) {
+	scoped := &scopedSigner{AggregateSigner: bls12381.NewSigner()}
+
+	signer := makeSigner()
+	signer.Add(bls12381.Algorithm, scoped)
+
+	msg := []byte("deadbeef")
+
+	sig, err := signer.Unscoped().Sign(msg)
+	require.NoError(t, err)
+	require.NoError(t, signer.GetPublicKey().Verify(msg, sig))
+
+	// The unscoped signer of the active signer is used when it has one.
+	require.NoError(t, signer.Activate(bls12381.Algorithm))
+
+	sig, err = signer.Unscoped().Sign(msg)
+	require.NoError(t, err)
+	require.NoError(t, signer.GetPublicKey().Verify(msg, sig))
+	require.True(t, scoped.unscoped)
+}
+
+func TestSigner_SignatureFactory(t *testing.T) {
+	factory := makeSigner().GetSignatureFactory().(SignatureFactory)
+
+	require.Len(t, factory.factories, 2)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeSigner() *Signer {
+	signer := NewSigner(bls.Algorithm, bls.NewSigner())
+	signer.Add(bls12381.Algorithm, bls12381.NewSigner())
+
+	return signer
+}
+
+type scopedSigner struct {
+	crypto.AggregateSigner
+
+	scopes   []crypto.SignScope
+	unscoped bool
+}
+
+func (s *scopedSigner) BindScope(msg []byte, scope crypto.SignScope) {
+	s.scopes = append(s.scopes, scope)
+}
+
+func (s *scopedSigner) Unscoped() crypto.Signer {
+	s.unscoped = true
+
+	return s.AggregateSigner
+}
@@ -146,6 +146,56 @@ type AggregateSigner interface {
 	Aggregate(signatures ...Signature) (Signature, error)
 }
 
+// PossessionProver is implemented by the signers whose public keys must come
+// with a proof of possession of the private key before they can be aggregated
+// safely.
+type PossessionProver interface {
+	// ProvePossession returns a proof that the signer knows the private key
+	// of its public key.
+	ProvePossession() (Signature, error)
+}
+
+// PossessionVerifier is implemented by the public keys that must be checked
+// against a proof of possession before they can be aggregated safely.
+type PossessionVerifier interface {
+	// VerifyPossession returns nil if the proof matches the public key,
+	// otherwise an error.
+	VerifyPossession(proof Signature) error
+}
+
+// SignScope describes what a message commits to, so that a signer can refuse to
+// sign two conflicting messages, for instance two different blocks at the same
+// index and view.
+type SignScope struct {
+	// Kind is the kind of the message, like a prepare or a commit.
+	Kind string
+	// Index is the index of the block the message is about.
+	Index uint64
+	// View is the view of the round, which counts the view changes since the
+	// beginning of the index.
+	View uint64
+	// ID is the identifier of what the message commits to.
+	ID []byte
+	// Data is the information a signer needs to verify the scope against the
+	// message, in the format of the kind of scope.
+	Data []byte
+}
+
+// ScopedSigner is implemented by the signers that need to know what a message
+// commits to before they sign it.
+type ScopedSigner interface {
+	// BindScope declares the scope of the message before it is signed.
+	BindScope(msg []byte, scope SignScope)
+}
+
+// UnscopedSigner is implemented by the scoped signers that refuse to sign a
+// message without a scope. The messages that have none, like transactions,
+// must be signed explicitly with the unscoped signer.
+type UnscopedSigner interface {
+	// Unscoped returns the signer of the messages without a scope.
+	Unscoped() Signer
+}
+
 // CollectiveAuthority is a set of participants with each of them being
 // associated to a Mino address and a public key.
 type CollectiveAuthority interface {
@@ -11,6 +11,8 @@ import (
 func init() {
 	ed25519.RegisterPublicKeyFormat(serde.FormatJSON, pubkeyFormat{})
 	ed25519.RegisterSignatureFormat(serde.FormatJSON, sigFormat{})
+	ed25519.RegisterPublicKeyFormat(serde.FormatCBOR, pubkeyFormat{})
+	ed25519.RegisterSignatureFormat(serde.FormatCBOR, sigFormat{})
 }
 
 type pubkeyFormat struct{}
@@ -7,17 +7,86 @@ package crypto
 import (
 	"crypto/sha256"
 	"hash"
+	"strings"
 
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/sha3"
+	"golang.org/x/xerrors"
+	"lukechampine.com/blake3"
 )
 
+// HashAlgorithm is the identifier of a hash algorithm.
 type HashAlgorithm int
 
 const (
+	// Sha256 is the SHA-256 algorithm, which is the default one.
 	Sha256 HashAlgorithm = iota
+	// Sha3_224 is the SHA3-224 algorithm.
 	Sha3_224
+	// Sha3_256 is the SHA3-256 algorithm.
+	Sha3_256
+	// Blake2b256 is the BLAKE2b algorithm with a 256-bit digest.
+	Blake2b256
+	// Blake3_256 is the BLAKE3 algorithm with a 256-bit digest.
+	Blake3_256
 )
 
+var hashNames = map[HashAlgorithm]string{
+	Sha256:     "sha256",
+	Sha3_224:   "sha3-224",
+	Sha3_256:   "sha3-256",
+	Blake2b256: "blake2b-256",
+	Blake3_256: "blake3-256",
+}
+
+// String implements fmt.Stringer. It returns the name of the algorithm.
+func (a HashAlgorithm) String() string {
+	name, found := hashNames[a]
+	if !found {
+		return "unknown"
+	}
+
+	return name
+}
+
+// digestSize is the size of the digests of the algorithms that can be selected
+// for a chain.
+const digestSize = 32
+
+// HashAlgorithms returns the names of the algorithms that can be selected for
+// a chain. SHA3-224 is not one of them as its digest is shorter than the
+// digests of the chain.
+func HashAlgorithms() []string {
+	names := make([]string, 0, len(hashNames))
+	for a := Sha256; a <= Blake3_256; a++ {
+		if NewHashFactory(a).New().Size() == digestSize {
+			names = append(names, hashNames[a])
+		}
+	}
+
+	return names
+}
+
+// ParseHashAlgorithm returns the algorithm of the name, or an error if it is
+// not one that can be selected.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	for a, n := range hashNames {
+		if !strings.EqualFold(n, name) {
+			continue
+		}
+
+		size := NewHashFactory(a).New().Size()
+		if size != digestSize {
+			return 0, xerrors.Errorf("hash algorithm '%s' has a digest of %d bytes", n, size)
+		}
+
+		return a, nil
+	}
+
+	return 0, xerrors.Errorf("unknown hash algorithm '%s'", name)
+}
+
 // hashFactory is a hash factory that is using SHA algorithms.
 //
 // - implements crypto.HashFactory
@@ -43,7 +112,49 @@ func (f hashFactory) New() hash.Hash {
 		return sha256.New()
 	case Sha3_224:
 		return sha3.New224()
+	case Sha3_256:
+		return sha3.New256()
+	case Blake2b256:
+		// The error only happens with a key that is too long.
+		h, _ := blake2b.New256(nil)
+		return h
+	case Blake3_256:
+		return blake3.New(32, nil)
 	default:
 		panic("unknown hash type")
 	}
 }
+
+// hashKey is the key of the hash factory in a serialization context.
+type hashKey struct{}
+
+// contextHashFactory wraps a hash factory so that it can be stored in a
+// serialization context.
+//
+// - implements serde.Factory
+type contextHashFactory struct {
+	HashFactory
+}
+
+// Deserialize implements serde.Factory. It always returns an error as the
+// factory only provides the hash.
+func (contextHashFactory) Deserialize(serde.Context, []byte) (serde.Message, error) {
+	return nil, xerrors.New("hash factory cannot deserialize")
+}
+
+// WithHashFactory returns a serialization context with the hash factory, so
+// that the format engines compute the digests with it.
+func WithHashFactory(ctx serde.Context, f HashFactory) serde.Context {
+	return serde.WithFactory(ctx, hashKey{}, contextHashFactory{HashFactory: f})
+}
+
+// HashFactoryOf returns the hash factory of the serialization context, or nil
+// if it is not set.
+func HashFactoryOf(ctx serde.Context) HashFactory {
+	fac, ok := ctx.GetFactory(hashKey{}).(contextHashFactory)
+	if !ok {
+		return nil
+	}
+
+	return fac.HashFactory
+}
@@ -1,9 +1,11 @@
 package crypto
 
 import (
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/serde"
 )
 
 func TestSha256Factory_NewDeprecated(t *testing.T) {
@@ -21,9 +23,65 @@ func TestSha3224Factory_New(t *testing.T) {
 	require.NotNil(t, factory.New())
 }
 
+func TestHashFactory_New(t *testing.T) {
+	digests := map[HashAlgorithm]string{
+		Sha256:     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		Sha3_256:   "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		Blake2b256: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319",
+		Blake3_256: "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85",
+	}
+
+	for algo, expected := range digests {
+		h := NewHashFactory(algo).New()
+		h.Write([]byte("abc"))
+
+		require.Equal(t, expected, hex.EncodeToString(h.Sum(nil)), algo.String())
+	}
+}
+
 func TestUnsupportedPanics(t *testing.T) {
 	require.Panics(t, func() {
-		factory := NewHashFactory(3)
+		factory := NewHashFactory(99)
 		factory.New()
 	})
 }
+
+func TestHashAlgorithm_String(t *testing.T) {
+	require.Equal(t, "sha256", Sha256.String())
+	require.Equal(t, "blake3-256", Blake3_256.String())
+	require.Equal(t, "unknown", HashAlgorithm(99).String())
+}
+
+func TestHashAlgorithms(t *testing.T) {
+	require.Equal(t, []string{"sha256", "sha3-256", "blake2b-256", "blake3-256"},
+		HashAlgorithms())
+}
+
+func TestParseHashAlgorithm(t *testing.T) {
+	for _, name := range HashAlgorithms() {
+		algo, err := ParseHashAlgorithm(name)
+		require.NoError(t, err)
+		require.Equal(t, name, algo.String())
+	}
+
+	algo, err := ParseHashAlgorithm("SHA3-256")
+	require.NoError(t, err)
+	require.Equal(t, Sha3_256, algo)
+
+	_, err = ParseHashAlgorithm("md5")
+	require.EqualError(t, err, "unknown hash algorithm 'md5'")
+
+	_, err = ParseHashAlgorithm("sha3-224")
+	require.EqualError(t, err, "hash algorithm 'sha3-224' has a digest of 28 bytes")
+}
+
+func TestHashFactoryOf(t *testing.T) {
+	ctx := serde.NewContext(nil)
+	require.Nil(t, HashFactoryOf(ctx))
+
+	ctx = WithHashFactory(ctx, NewHashFactory(Blake3_256))
+	require.Equal(t, NewHashFactory(Blake3_256), HashFactoryOf(ctx))
+
+	_, err := ctx.GetFactory(hashKey{}).Deserialize(ctx, nil)
+	require.EqualError(t, err, "hash factory cannot deserialize")
+}
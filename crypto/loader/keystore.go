@@ -0,0 +1,353 @@
+// This file contains the implementation of an encrypted keystore.
+//
+// A keystore protects a private key at rest. The key that encrypts the data is
+// derived from a passphrase with scrypt, and the data is sealed with
+// XChaCha20-Poly1305. The parameters of the derivation are stored along the
+// ciphertext and authenticated with it.
+
+package loader
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+)
+
+const (
+	keystoreVersion = 1
+	keystoreKDF     = "scrypt"
+	keystoreCipher  = "xchacha20-poly1305"
+
+	saltLength = 32
+
+	defaultScryptN = 1 << 15
+	defaultScryptR = 8
+	defaultScryptP = 1
+)
+
+// keystoreJSON is the format of a keystore on the disk.
+type keystoreJSON struct {
+	Version    int
+	KDF        string
+	N          int
+	R          int
+	P          int
+	Salt       []byte
+	Cipher     string
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// KeystoreOption is the type of option to set some parameters of a keystore.
+type KeystoreOption func(*keystoreJSON)
+
+// WithScryptCost is an option to set the cost parameters of the key
+// derivation. Lower values make the passphrase easier to brute-force.
+func WithScryptCost(n, r, p int) KeystoreOption {
+	return func(ks *keystoreJSON) {
+		ks.N = n
+		ks.R = r
+		ks.P = p
+	}
+}
+
+// IsEncrypted returns true if the data is a keystore.
+func IsEncrypted(data []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return false
+	}
+
+	var ks keystoreJSON
+
+	err := json.Unmarshal(data, &ks)
+	if err != nil {
+		return false
+	}
+
+	return ks.Version > 0 && ks.KDF != "" && ks.Cipher != ""
+}
+
+// Encrypt returns the keystore of the data protected by the passphrase.
+func Encrypt(data, passphrase []byte, opts ...KeystoreOption) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, xerrors.New("passphrase is empty")
+	}
+
+	ks := keystoreJSON{
+		Version: keystoreVersion,
+		KDF:     keystoreKDF,
+		N:       defaultScryptN,
+		R:       defaultScryptR,
+		P:       defaultScryptP,
+		Salt:    make([]byte, saltLength),
+		Cipher:  keystoreCipher,
+		Nonce:   make([]byte, chacha20poly1305.NonceSizeX),
+	}
+
+	for _, opt := range opts {
+		opt(&ks)
+	}
+
+	_, err := rand.Read(ks.Salt)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate salt: %v", err)
+	}
+
+	_, err = rand.Read(ks.Nonce)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate nonce: %v", err)
+	}
+
+	aead, err := ks.makeCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, data, ks.header())
+
+	out, err := json.Marshal(ks)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal keystore: %v", err)
+	}
+
+	return out, nil
+}
+
+// Decrypt returns the data of the keystore if the passphrase is correct.
+func Decrypt(data, passphrase []byte) ([]byte, error) {
+	var ks keystoreJSON
+
+	err := json.Unmarshal(data, &ks)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed keystore: %v", err)
+	}
+
+	if ks.Version != keystoreVersion {
+		return nil, xerrors.Errorf("unsupported keystore version %d", ks.Version)
+	}
+
+	if ks.KDF != keystoreKDF || ks.Cipher != keystoreCipher {
+		return nil, xerrors.Errorf("unsupported keystore algorithms '%s' and '%s'",
+			ks.KDF, ks.Cipher)
+	}
+
+	if len(ks.Nonce) != chacha20poly1305.NonceSizeX {
+		return nil, xerrors.Errorf("invalid nonce length %d", len(ks.Nonce))
+	}
+
+	aead, err := ks.makeCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, ks.header())
+	if err != nil {
+		return nil, xerrors.New("wrong passphrase or corrupted keystore")
+	}
+
+	return plaintext, nil
+}
+
+func (ks keystoreJSON) makeCipher(passphrase []byte) (aeadCipher, error) {
+	key, err := scrypt.Key(passphrase, ks.Salt, ks.N, ks.R, ks.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, xerrors.Errorf("key derivation failed: %v", err)
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create cipher: %v", err)
+	}
+
+	return aead, nil
+}
+
+// header returns the parameters of the keystore that are authenticated with
+// the ciphertext.
+func (ks keystoreJSON) header() []byte {
+	return []byte(fmt.Sprintf("dela-keystore:%d:%s:%d:%d:%d:%x:%s",
+		ks.Version, ks.KDF, ks.N, ks.R, ks.P, ks.Salt, ks.Cipher))
+}
+
+type aeadCipher interface {
+	Seal(dst, nonce, plaintext, additionalData []byte) []byte
+	Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// encryptedLoader is a loader that stores the keys in an encrypted keystore.
+//
+// - implements loader.Loader
+type encryptedLoader struct {
+	file       Loader
+	passphrase PassphraseSource
+	opts       []KeystoreOption
+}
+
+// NewEncryptedFileLoader creates a new loader that stores the key in a
+// keystore file protected by the passphrase of the source.
+func NewEncryptedFileLoader(path string, source PassphraseSource,
+	opts ...KeystoreOption) Loader {
+
+	return encryptedLoader{
+		file:       NewFileLoader(path),
+		passphrase: source,
+		opts:       opts,
+	}
+}
+
+// NewKeyLoader returns a loader that stores the key encrypted when a source of
+// passphrase is given, or in plaintext otherwise.
+func NewKeyLoader(path string, source PassphraseSource) Loader {
+	if source == nil {
+		return plainLoader{Loader: NewFileLoader(path)}
+	}
+
+	return NewEncryptedFileLoader(path, source)
+}
+
+// plainLoader is a loader that refuses to return a keystore as if it was the
+// key itself.
+//
+// - implements loader.Loader
+type plainLoader struct {
+	Loader
+}
+
+// LoadOrCreate implements loader.Loader. It returns an error if the key is
+// encrypted.
+func (l plainLoader) LoadOrCreate(g Generator) ([]byte, error) {
+	data, err := l.Loader.LoadOrCreate(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkPlain(data)
+}
+
+// Load implements loader.Loader. It returns an error if the key is encrypted.
+func (l plainLoader) Load() ([]byte, error) {
+	data, err := l.Loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return checkPlain(data)
+}
+
+func checkPlain(data []byte) ([]byte, error) {
+	if IsEncrypted(data) {
+		return nil, xerrors.New("key is encrypted: a passphrase is required")
+	}
+
+	return data, nil
+}
+
+// LoadOrCreate implements loader.Loader. It either decrypts the key of the
+// keystore if it exists, or it generates a new one and stores it encrypted.
+func (l encryptedLoader) LoadOrCreate(g Generator) ([]byte, error) {
+	passphrase, err := l.passphrase()
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	gen := encryptedGenerator{
+		generator:  g,
+		passphrase: passphrase,
+		opts:       l.opts,
+	}
+
+	data, err := l.file.LoadOrCreate(&gen)
+	if err != nil {
+		return nil, err
+	}
+
+	if gen.plaintext != nil {
+		return gen.plaintext, nil
+	}
+
+	return decrypt(data, passphrase)
+}
+
+// Load implements loader.Loader. It decrypts the key of the keystore and
+// returns an error if it doesn't exist.
+func (l encryptedLoader) Load() ([]byte, error) {
+	data, err := l.file.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := l.passphrase()
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	return decrypt(data, passphrase)
+}
+
+func decrypt(data, passphrase []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, xerrors.New("key is not encrypted: use the rekey command " +
+			"to encrypt it")
+	}
+
+	plaintext, err := Decrypt(data, passphrase)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decrypt: %v", err)
+	}
+
+	return plaintext, nil
+}
+
+// encryptedGenerator is a generator that encrypts the generated key. It keeps
+// the plaintext so that it is not decrypted right after.
+//
+// - implements loader.Generator
+type encryptedGenerator struct {
+	generator  Generator
+	passphrase []byte
+	opts       []KeystoreOption
+	plaintext  []byte
+}
+
+// Generate implements loader.Generator. It returns the keystore of a new key.
+func (g *encryptedGenerator) Generate() ([]byte, error) {
+	data, err := g.generator.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	ks, err := Encrypt(data, g.passphrase, g.opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encrypt: %v", err)
+	}
+
+	g.plaintext = data
+
+	return ks, nil
+}
+
+// WriteFile writes the data to the file with read permission only for the
+// current user (0400). The file is replaced atomically if it exists.
+func WriteFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+
+	err := os.WriteFile(tmp, data, 0400)
+	if err != nil {
+		return xerrors.Errorf("while writing: %v", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		os.Remove(tmp)
+
+		return xerrors.Errorf("while replacing: %v", err)
+	}
+
+	return nil
+}
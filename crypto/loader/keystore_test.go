@@ -0,0 +1,190 @@
+package loader
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestKeystore_EncryptDecrypt(t *testing.T) {
+	data, err := Encrypt([]byte{1, 2, 3}, []byte("pass"), fastScrypt)
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(data))
+	require.NotContains(t, string(data), string([]byte{1, 2, 3}))
+
+	plaintext, err := Decrypt(data, []byte("pass"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, plaintext)
+
+	_, err = Decrypt(data, []byte("wrong"))
+	require.EqualError(t, err, "wrong passphrase or corrupted keystore")
+
+	_, err = Encrypt(nil, nil)
+	require.EqualError(t, err, "passphrase is empty")
+
+	_, err = Encrypt(nil, []byte("pass"), WithScryptCost(3, 8, 1))
+	require.EqualError(t, err,
+		"key derivation failed: scrypt: N must be > 1 and a power of 2")
+}
+
+func TestKeystore_Tampered(t *testing.T) {
+	data, err := Encrypt([]byte{1, 2, 3}, []byte("pass"), fastScrypt)
+	require.NoError(t, err)
+
+	// The parameters of the derivation are authenticated.
+	tamper := func(fn func(*keystoreJSON)) []byte {
+		var ks keystoreJSON
+		require.NoError(t, json.Unmarshal(data, &ks))
+
+		fn(&ks)
+
+		out, err := json.Marshal(ks)
+		require.NoError(t, err)
+
+		return out
+	}
+
+	_, err = Decrypt(tamper(func(ks *keystoreJSON) { ks.Salt[0] ^= 1 }), []byte("pass"))
+	require.EqualError(t, err, "wrong passphrase or corrupted keystore")
+
+	_, err = Decrypt(tamper(func(ks *keystoreJSON) { ks.Version = 2 }), []byte("pass"))
+	require.EqualError(t, err, "unsupported keystore version 2")
+
+	_, err = Decrypt(tamper(func(ks *keystoreJSON) { ks.KDF = "argon2" }), []byte("pass"))
+	require.EqualError(t, err,
+		"unsupported keystore algorithms 'argon2' and 'xchacha20-poly1305'")
+
+	_, err = Decrypt(tamper(func(ks *keystoreJSON) { ks.Nonce = nil }), []byte("pass"))
+	require.EqualError(t, err, "invalid nonce length 0")
+
+	_, err = Decrypt([]byte("{"), []byte("pass"))
+	require.EqualError(t, err, "malformed keystore: unexpected end of JSON input")
+}
+
+func TestIsEncrypted(t *testing.T) {
+	require.False(t, IsEncrypted(nil))
+	require.False(t, IsEncrypted([]byte{1, 2, 3}))
+	require.False(t, IsEncrypted([]byte("{")))
+	require.False(t, IsEncrypted([]byte("{}")))
+}
+
+func TestEncryptedLoader_LoadOrCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "private.key")
+
+	generator := fakeGenerator{
+		calls: fake.NewCall(),
+	}
+
+	loader := NewEncryptedFileLoader(path, staticSource("pass"), fastScrypt)
+
+	data, err := loader.LoadOrCreate(generator)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+	require.Equal(t, 1, generator.calls.Len())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(raw))
+
+	data, err = loader.LoadOrCreate(generator)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+	require.Equal(t, 1, generator.calls.Len())
+
+	data, err = loader.Load()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+
+	loader = NewEncryptedFileLoader(path, staticSource("wrong"))
+
+	_, err = loader.LoadOrCreate(generator)
+	require.EqualError(t, err,
+		"failed to decrypt: wrong passphrase or corrupted keystore")
+
+	_, err = NewKeyLoader(path, nil).Load()
+	require.EqualError(t, err, "key is encrypted: a passphrase is required")
+
+	_, err = NewKeyLoader(path, nil).LoadOrCreate(generator)
+	require.EqualError(t, err, "key is encrypted: a passphrase is required")
+}
+
+func TestEncryptedLoader_Plaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "private.key")
+
+	data, err := NewKeyLoader(path, nil).LoadOrCreate(fakeGenerator{})
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+
+	data, err = NewKeyLoader(path, nil).Load()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+
+	_, err = NewKeyLoader(path, staticSource("pass")).Load()
+	require.EqualError(t, err,
+		"key is not encrypted: use the rekey command to encrypt it")
+}
+
+func TestEncryptedLoader_Failures(t *testing.T) {
+	loader := NewEncryptedFileLoader("", badSource)
+
+	_, err := loader.LoadOrCreate(fakeGenerator{})
+	require.EqualError(t, err, fake.Err("passphrase"))
+
+	path := filepath.Join(t.TempDir(), "private.key")
+
+	loader = NewEncryptedFileLoader(path, badSource)
+
+	_, err = loader.Load()
+	require.Regexp(t, "^while opening file: ", err)
+
+	require.NoError(t, os.WriteFile(path, []byte{1}, 0600))
+
+	_, err = loader.Load()
+	require.EqualError(t, err, fake.Err("passphrase"))
+
+	loader = NewEncryptedFileLoader(path+".new", staticSource("pass"))
+
+	_, err = loader.LoadOrCreate(fakeGenerator{err: fake.GetError()})
+	require.EqualError(t, err, fake.Err("generator failed"))
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "private.key")
+
+	err := WriteFile(path, []byte{1})
+	require.NoError(t, err)
+
+	// The file is replaced even if it is read-only.
+	err = WriteFile(path, []byte{2})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, data)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0400), info.Mode().Perm())
+
+	err = WriteFile("/not/exist/private.key", nil)
+	require.Regexp(t, "^while writing: ", err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+var fastScrypt = WithScryptCost(1<<4, 8, 1)
+
+func staticSource(passphrase string) PassphraseSource {
+	return func() ([]byte, error) {
+		return []byte(passphrase), nil
+	}
+}
+
+func badSource() ([]byte, error) {
+	return nil, fake.GetError()
+}
@@ -0,0 +1,166 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"go.dedis.ch/dela/cli"
+	"golang.org/x/term"
+	"golang.org/x/xerrors"
+)
+
+const (
+	passphraseEnvFlag    = "passphrase-env"
+	passphraseFileFlag   = "passphrase-file"
+	passphrasePromptFlag = "passphrase-prompt"
+)
+
+// PassphraseSource is a function that returns the passphrase of a keystore.
+type PassphraseSource func() ([]byte, error)
+
+// prompted caches the passphrases typed by the user so that they are asked only
+// once per process, even when several components load a keystore.
+var prompted = struct {
+	sync.Mutex
+	values map[string][]byte
+}{
+	values: make(map[string][]byte),
+}
+
+// PassphraseFromEnv returns a source that reads the passphrase from the
+// environment variable.
+func PassphraseFromEnv(name string) PassphraseSource {
+	return func() ([]byte, error) {
+		value := os.Getenv(name)
+		if value == "" {
+			return nil, xerrors.Errorf("environment variable '%s' is empty", name)
+		}
+
+		return []byte(value), nil
+	}
+}
+
+// PassphraseFromFile returns a source that reads the passphrase from a file. A
+// trailing new line is ignored.
+func PassphraseFromFile(path string) PassphraseSource {
+	return func() ([]byte, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read passphrase file: %v", err)
+		}
+
+		data = bytes.TrimRight(data, "\r\n")
+		if len(data) == 0 {
+			return nil, xerrors.Errorf("passphrase file '%s' is empty", path)
+		}
+
+		return data, nil
+	}
+}
+
+// PassphraseFromPrompt returns a source that asks the passphrase to the user.
+// The input is not echoed when it is a terminal. The answer is cached for the
+// lifetime of the process.
+func PassphraseFromPrompt(prompt string, in io.Reader, out io.Writer) PassphraseSource {
+	return func() ([]byte, error) {
+		prompted.Lock()
+		defer prompted.Unlock()
+
+		value, found := prompted.values[prompt]
+		if found {
+			return value, nil
+		}
+
+		fmt.Fprint(out, prompt)
+
+		value, err := readPassphrase(in)
+
+		fmt.Fprintln(out)
+
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read passphrase: %v", err)
+		}
+
+		if len(value) == 0 {
+			return nil, xerrors.New("passphrase is empty")
+		}
+
+		prompted.values[prompt] = value
+
+		return value, nil
+	}
+}
+
+func readPassphrase(in io.Reader) ([]byte, error) {
+	file, ok := in.(*os.File)
+	if ok && term.IsTerminal(int(file.Fd())) {
+		return term.ReadPassword(int(file.Fd()))
+	}
+
+	line, err := bufio.NewReader(in).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// PassphraseFlags returns the flags to select the source of a passphrase. The
+// prefix distinguishes several passphrases in the same command.
+func PassphraseFlags(prefix string) []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  prefix + passphraseEnvFlag,
+			Usage: "name of the environment variable that holds the passphrase",
+		},
+		cli.StringFlag{
+			Name:  prefix + passphraseFileFlag,
+			Usage: "path to the file that holds the passphrase",
+		},
+		cli.BoolFlag{
+			Name:  prefix + passphrasePromptFlag,
+			Usage: "ask the passphrase on the terminal",
+		},
+	}
+}
+
+// PassphraseFromFlags returns the source of the passphrase defined by the
+// flags, or nil if none is set.
+func PassphraseFromFlags(flags cli.Flags, prefix string) (PassphraseSource, error) {
+	var sources []PassphraseSource
+
+	env := flags.String(prefix + passphraseEnvFlag)
+	if env != "" {
+		sources = append(sources, PassphraseFromEnv(env))
+	}
+
+	path := flags.String(prefix + passphraseFileFlag)
+	if path != "" {
+		sources = append(sources, PassphraseFromFile(path))
+	}
+
+	if flags.Bool(prefix + passphrasePromptFlag) {
+		prompt := "Passphrase: "
+		if prefix != "" {
+			prompt = fmt.Sprintf("Passphrase (%s): ", strings.TrimSuffix(prefix, "-"))
+		}
+
+		sources = append(sources, PassphraseFromPrompt(prompt, os.Stdin, os.Stderr))
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sources[0], nil
+	default:
+		return nil, xerrors.Errorf("only one of --%s%s, --%s%s and --%s%s is allowed",
+			prefix, passphraseEnvFlag, prefix, passphraseFileFlag,
+			prefix, passphrasePromptFlag)
+	}
+}
@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+)
+
+func TestPassphraseFromEnv(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	passphrase, err := PassphraseFromEnv("DELA_TEST_PASSPHRASE")()
+	require.NoError(t, err)
+	require.Equal(t, []byte("pass"), passphrase)
+
+	_, err = PassphraseFromEnv("DELA_TEST_EMPTY")()
+	require.EqualError(t, err, "environment variable 'DELA_TEST_EMPTY' is empty")
+}
+
+func TestPassphraseFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+
+	require.NoError(t, os.WriteFile(path, []byte("pass\n"), 0600))
+
+	passphrase, err := PassphraseFromFile(path)()
+	require.NoError(t, err)
+	require.Equal(t, []byte("pass"), passphrase)
+
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0600))
+
+	_, err = PassphraseFromFile(path)()
+	require.EqualError(t, err, "passphrase file '"+path+"' is empty")
+
+	_, err = PassphraseFromFile("/not/exist")()
+	require.Regexp(t, "^failed to read passphrase file: ", err)
+}
+
+func TestPassphraseFromPrompt(t *testing.T) {
+	out := new(bytes.Buffer)
+
+	source := PassphraseFromPrompt("Test prompt: ", strings.NewReader("pass\n"), out)
+
+	passphrase, err := source()
+	require.NoError(t, err)
+	require.Equal(t, []byte("pass"), passphrase)
+	require.Equal(t, "Test prompt: \n", out.String())
+
+	// The answer is cached.
+	source = PassphraseFromPrompt("Test prompt: ", strings.NewReader(""), out)
+
+	passphrase, err = source()
+	require.NoError(t, err)
+	require.Equal(t, []byte("pass"), passphrase)
+
+	source = PassphraseFromPrompt("Empty prompt: ", strings.NewReader("\n"), out)
+
+	_, err = source()
+	require.EqualError(t, err, "passphrase is empty")
+}
+
+func TestPassphraseFromFlags(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	flags := node.FlagSet{}
+
+	source, err := PassphraseFromFlags(flags, "")
+	require.NoError(t, err)
+	require.Nil(t, source)
+
+	flags["old-passphrase-env"] = "DELA_TEST_PASSPHRASE"
+
+	source, err = PassphraseFromFlags(flags, "old-")
+	require.NoError(t, err)
+
+	passphrase, err := source()
+	require.NoError(t, err)
+	require.Equal(t, []byte("pass"), passphrase)
+
+	flags["old-passphrase-file"] = "/not/exist"
+
+	_, err = PassphraseFromFlags(flags, "old-")
+	require.EqualError(t, err, "only one of --old-passphrase-env, "+
+		"--old-passphrase-file and --old-passphrase-prompt is allowed")
+
+	flags = node.FlagSet{"passphrase-prompt": true}
+
+	source, err = PassphraseFromFlags(flags, "")
+	require.NoError(t, err)
+	require.NotNil(t, source)
+
+	require.Len(t, PassphraseFlags(""), 3)
+}
@@ -0,0 +1,92 @@
+// Package nodesigner loads the signer of the node from the start flags, so
+// that the components of a node sign with the same key as its participant in
+// the roster.
+//
+// Documentation Last Review: 18.10.2026
+package nodesigner
+
+import (
+	"path/filepath"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/remote"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// PrivateKeyFile is the file of the BN256 key of the node in the config
+	// folder.
+	PrivateKeyFile = "private.key"
+
+	// SocketFlag is the flag of the socket of a signer daemon that holds the
+	// BN256 key of the node.
+	SocketFlag = "signer-socket"
+
+	// KeyFlag is the flag of the file of the BN256 key of the node, which is
+	// the private key file of the config folder by default.
+	KeyFlag = "signer-key"
+)
+
+// Load returns the BN256 signer of the node, from the signer daemon or the key
+// file set by the start flags. The key is created if it doesn't exist yet.
+func Load(flags cli.Flags) (crypto.AggregateSigner, error) {
+	source, err := loader.PassphraseFromFlags(flags, "")
+	if err != nil {
+		return nil, xerrors.Errorf("passphrase: %v", err)
+	}
+
+	return LoadWith(flags, source, generator{})
+}
+
+// LoadWith returns a remote signer if a signer daemon is set, otherwise the
+// signer of the key file, which is decrypted with the passphrase source. The
+// generator creates the key when the file doesn't exist.
+func LoadWith(flags cli.Flags, source loader.PassphraseSource,
+	g loader.Generator) (crypto.AggregateSigner, error) {
+
+	socket := flags.Path(SocketFlag)
+	if socket != "" {
+		signer, err := remote.NewSigner(socket, bls.NewSigner())
+		if err != nil {
+			return nil, xerrors.Errorf("remote signer: %v", err)
+		}
+
+		return signer, nil
+	}
+
+	path := flags.Path(KeyFlag)
+	if path == "" {
+		path = filepath.Join(flags.Path("config"), PrivateKeyFile)
+	}
+
+	signerdata, err := loader.NewKeyLoader(path, source).LoadOrCreate(g)
+	if err != nil {
+		return nil, xerrors.Errorf("while loading: %v", err)
+	}
+
+	signer, err := bls.NewSignerFromBytes(signerdata)
+	if err != nil {
+		return nil, xerrors.Errorf("while unmarshaling: %v", err)
+	}
+
+	return signer, nil
+}
+
+// generator generates a BN256 private key.
+//
+// - implements loader.Generator
+type generator struct{}
+
+// Generate implements loader.Generator. It returns the marshaled data of a new
+// BN256 private key.
+func (generator) Generate() ([]byte, error) {
+	data, err := bls.NewSigner().MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal signer: %v", err)
+	}
+
+	return data, nil
+}
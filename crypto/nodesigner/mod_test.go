@@ -0,0 +1,84 @@
+package nodesigner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	fset := node.FlagSet{"config": dir}
+
+	signer, err := Load(fset)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, PrivateKeyFile))
+
+	// The key is loaded once it exists.
+	other, err := Load(fset)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(other.GetPublicKey()))
+
+	fset[KeyFlag] = filepath.Join(dir, "node.key")
+
+	other, err = Load(fset)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, "node.key"))
+	require.False(t, signer.GetPublicKey().Equal(other.GetPublicKey()))
+
+	fset["passphrase-env"] = "DELA_TEST_PASSPHRASE"
+	fset["passphrase-prompt"] = true
+
+	_, err = Load(fset)
+	require.EqualError(t, err, "passphrase: only one of --passphrase-env, "+
+		"--passphrase-file and --passphrase-prompt is allowed")
+}
+
+func TestLoadWith(t *testing.T) {
+	dir := t.TempDir()
+
+	fset := node.FlagSet{"config": dir}
+
+	_, err := LoadWith(fset, nil, fakeGenerator{err: fake.GetError()})
+	require.EqualError(t, err, fake.Err("while loading: generator failed"))
+
+	err = os.WriteFile(filepath.Join(dir, PrivateKeyFile), []byte{}, 0400)
+	require.NoError(t, err)
+
+	_, err = LoadWith(fset, nil, generator{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "while unmarshaling: ")
+
+	fset[SocketFlag] = filepath.Join(dir, "unknown.sock")
+
+	_, err = LoadWith(fset, nil, generator{})
+	require.Regexp(t, "^remote signer: failed to get public key: ", err)
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	data, err := generator{}.Generate()
+	require.NoError(t, err)
+
+	_, err = bls.NewSignerFromBytes(data)
+	require.NoError(t, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeGenerator struct {
+	loader.Generator
+
+	err error
+}
+
+func (g fakeGenerator) Generate() ([]byte, error) {
+	return nil, g.err
+}
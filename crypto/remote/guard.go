@@ -0,0 +1,145 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/loader"
+	"golang.org/x/xerrors"
+)
+
+// record is what has been signed for a kind of scope at the highest index.
+type record struct {
+	Index uint64
+	Views map[uint64][]byte
+}
+
+// Guard is the double-sign protection of a signer daemon. It remembers, for
+// each kind of scope, what has been signed at the latest index and refuses to
+// sign something else for the same index and view, or for an older index.
+type Guard struct {
+	sync.Mutex
+
+	path    string
+	records map[string]*record
+}
+
+// NewGuard creates a new guard that stores its state in the file, so that the
+// protection survives a restart of the daemon. The state is only kept in
+// memory when the path is empty.
+func NewGuard(path string) (*Guard, error) {
+	g := &Guard{
+		path:    path,
+		records: make(map[string]*record),
+	}
+
+	if path == "" {
+		return g, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read state: %v", err)
+	}
+
+	err = json.Unmarshal(data, &g.records)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode state: %v", err)
+	}
+
+	return g, nil
+}
+
+// Check returns nil if the scope doesn't conflict with a previous one, in
+// which case the scope is recorded, otherwise an error.
+func (g *Guard) Check(scope crypto.SignScope) error {
+	g.Lock()
+	defer g.Unlock()
+
+	rec := g.records[scope.Kind]
+
+	if rec != nil && scope.Index < rec.Index {
+		return xerrors.Errorf("%s at index %d is behind index %d",
+			scope.Kind, scope.Index, rec.Index)
+	}
+
+	if rec != nil && scope.Index == rec.Index {
+		id, found := rec.Views[scope.View]
+		if found && !bytes.Equal(id, scope.ID) {
+			return xerrors.Errorf("%s at index %d and view %d already signed for %x",
+				scope.Kind, scope.Index, scope.View, id)
+		}
+
+		if found {
+			return nil
+		}
+	}
+
+	previous := rec
+
+	if rec == nil || scope.Index > rec.Index {
+		rec = &record{
+			Index: scope.Index,
+			Views: make(map[uint64][]byte),
+		}
+	}
+
+	rec.Views[scope.View] = scope.ID
+	g.records[scope.Kind] = rec
+
+	err := g.save()
+	if err != nil {
+		// The state is restored as the scope couldn't be recorded.
+		delete(rec.Views, scope.View)
+
+		if previous == nil {
+			delete(g.records, scope.Kind)
+		} else {
+			g.records[scope.Kind] = previous
+		}
+
+		return xerrors.Errorf("failed to save state: %v", err)
+	}
+
+	return nil
+}
+
+// Has returns true if the identifier has been signed for the kind of scope at
+// the index, whatever the view.
+func (g *Guard) Has(kind string, index uint64, id []byte) bool {
+	g.Lock()
+	defer g.Unlock()
+
+	rec := g.records[kind]
+	if rec == nil || rec.Index != index {
+		return false
+	}
+
+	for _, other := range rec.Views {
+		if bytes.Equal(other, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Guard) save() error {
+	if g.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(g.records)
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	return loader.WriteFile(g.path, data)
+}
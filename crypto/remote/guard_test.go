@@ -0,0 +1,106 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+)
+
+func TestGuard_Check(t *testing.T) {
+	guard, err := NewGuard("")
+	require.NoError(t, err)
+
+	require.NoError(t, guard.Check(makeScope(1, 0, "A")))
+	require.NoError(t, guard.Check(makeScope(1, 0, "A")))
+
+	err = guard.Check(makeScope(1, 0, "B"))
+	require.EqualError(t, err,
+		"prepare at index 1 and view 0 already signed for 41")
+
+	// Another view or another kind of scope is allowed.
+	require.NoError(t, guard.Check(makeScope(1, 1, "B")))
+	require.NoError(t, guard.Check(crypto.SignScope{Kind: "commit", Index: 1, ID: []byte("B")}))
+
+	require.NoError(t, guard.Check(makeScope(2, 0, "C")))
+
+	err = guard.Check(makeScope(1, 1, "B"))
+	require.EqualError(t, err, "prepare at index 1 is behind index 2")
+}
+
+func TestGuard_Has(t *testing.T) {
+	guard, err := NewGuard("")
+	require.NoError(t, err)
+
+	require.False(t, guard.Has("prepare", 1, []byte("A")))
+
+	require.NoError(t, guard.Check(makeScope(1, 2, "A")))
+
+	require.True(t, guard.Has("prepare", 1, []byte("A")))
+	require.False(t, guard.Has("prepare", 1, []byte("B")))
+	require.False(t, guard.Has("prepare", 2, []byte("A")))
+	require.False(t, guard.Has("commit", 1, []byte("A")))
+}
+
+func TestGuard_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	guard, err := NewGuard(path)
+	require.NoError(t, err)
+
+	require.NoError(t, guard.Check(makeScope(5, 2, "A")))
+
+	guard, err = NewGuard(path)
+	require.NoError(t, err)
+
+	err = guard.Check(makeScope(5, 2, "B"))
+	require.EqualError(t, err,
+		"prepare at index 5 and view 2 already signed for 41")
+
+	require.NoError(t, guard.Check(makeScope(6, 0, "B")))
+
+	require.NoError(t, os.Chmod(path, 0600))
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+	_, err = NewGuard(path)
+	require.EqualError(t, err, "failed to decode state: unexpected end of JSON input")
+
+	_, err = NewGuard(t.TempDir())
+	require.Regexp(t, "^failed to read state: ", err)
+}
+
+func TestGuard_FailSave(t *testing.T) {
+	guard, err := NewGuard(filepath.Join(t.TempDir(), "unknown", "state.json"))
+	require.NoError(t, err)
+
+	err = guard.Check(makeScope(1, 0, "A"))
+	require.Regexp(t, "^failed to save state: while writing: ", err)
+	require.Empty(t, guard.records)
+
+	guard.path = ""
+	require.NoError(t, guard.Check(makeScope(1, 0, "A")))
+
+	guard.path = filepath.Join(t.TempDir(), "unknown", "state.json")
+
+	err = guard.Check(makeScope(1, 1, "B"))
+	require.Error(t, err)
+	require.Len(t, guard.records["prepare"].Views, 1)
+
+	err = guard.Check(makeScope(2, 0, "B"))
+	require.Error(t, err)
+	require.Equal(t, uint64(1), guard.records["prepare"].Index)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeScope(index, view uint64, id string) crypto.SignScope {
+	return crypto.SignScope{
+		Kind:  "prepare",
+		Index: index,
+		View:  view,
+		ID:    []byte(id),
+	}
+}
@@ -0,0 +1,38 @@
+// Package remote implements a signer that forwards the signatures to a signer
+// daemon over a local socket, so that the private key is kept out of the
+// process of the node.
+//
+// The daemon refuses to sign two conflicting messages for the same block index
+// and view. The node declares the scope of a message before it asks for a
+// signature, and the daemon verifies the scope against the message with a
+// verifier that knows the messages of the consensus. Messages without a
+// scope, like certificate endorsements, are sent with another operation and
+// signed only if the daemon allows it. Even then, the daemon refuses to sign a
+// message without a scope that the verifier can't tell apart from a consensus
+// message, so that a consensus message is never signed without the
+// protection.
+package remote
+
+import (
+	"go.dedis.ch/dela/crypto"
+)
+
+const (
+	opPublicKey    = "pubkey"
+	opSign         = "sign"
+	opSignUnscoped = "sign-unscoped"
+)
+
+// request is the message sent by the signer to the daemon.
+type request struct {
+	Op      string
+	Message []byte            `json:",omitempty"`
+	Scope   *crypto.SignScope `json:",omitempty"`
+}
+
+// response is the message sent back by the daemon. The data is the serialized
+// public key or signature, or the error is set.
+type response struct {
+	Data  []byte `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
@@ -0,0 +1,271 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+// ScopeVerifier verifies the scopes declared by the nodes against their
+// messages, so that the daemon doesn't have to trust them.
+type ScopeVerifier interface {
+	// VerifyScope returns nil if the message matches the scope, otherwise an
+	// error.
+	VerifyScope(msg []byte, scope crypto.SignScope) error
+
+	// GetRequirement returns the kind of scope that must have been signed for
+	// the same index and identifier before a scope of the kind, or an empty
+	// string.
+	GetRequirement(kind string) string
+
+	// IsScoped returns true if the message might be one of the messages that
+	// need a scope, in which case it is never signed without one.
+	IsScoped(msg []byte) bool
+}
+
+// ServerOption is the type of option to set some fields of a signer daemon.
+type ServerOption func(*Server)
+
+// WithUnscoped is an option to allow the daemon to sign messages that have no
+// scope, like certificate endorsements. Those messages are not protected
+// against double signing, therefore the ones that the verifier might take for
+// a consensus message are still refused. It includes the transactions, as
+// their hash can't be told apart from the digest of a block.
+func WithUnscoped() ServerOption {
+	return func(s *Server) {
+		s.unscoped = true
+	}
+}
+
+// Server is a signer daemon that signs the requests of the remote signers
+// connected to its unix socket.
+type Server struct {
+	signer   crypto.Signer
+	guard    *Guard
+	verifier ScopeVerifier
+	unscoped bool
+	context  serde.Context
+	logger   zerolog.Logger
+
+	sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a new signer daemon for the signer. The guard protects the
+// signer against double signing, with the scopes accepted by the verifier.
+func NewServer(signer crypto.Signer, guard *Guard, verifier ScopeVerifier,
+	opts ...ServerOption) *Server {
+
+	s := &Server{
+		signer:   signer,
+		guard:    guard,
+		verifier: verifier,
+		context:  sjson.NewContext(),
+		conns:    make(map[net.Conn]struct{}),
+		logger:   dela.Logger.With().Str("service", "remote signer").Logger(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Listen starts to listen on the unix socket. Only the current user can
+// connect to it.
+func (s *Server) Listen(path string) error {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return xerrors.Errorf("failed to listen: %v", err)
+	}
+
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		listener.Close()
+		return xerrors.Errorf("failed to set permissions: %v", err)
+	}
+
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.serve()
+
+	s.logger.Info().Str("socket", path).Msg("signer daemon listening")
+
+	return nil
+}
+
+// Close stops the daemon and closes the connections.
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+
+	err := s.listener.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close listener: %v", err)
+	}
+
+	s.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.Unlock()
+
+	s.wg.Wait()
+
+	return nil
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("failed to accept connection")
+			continue
+		}
+
+		s.Lock()
+		if s.closed {
+			s.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.Unlock()
+
+		conns.Add(1)
+
+		go func() {
+			defer conns.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		s.Lock()
+		delete(s.conns, conn)
+		s.Unlock()
+	}()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	for {
+		var req request
+
+		err := dec.Decode(&req)
+		if err != nil {
+			return
+		}
+
+		data, err := s.handle(req)
+
+		resp := response{Data: data}
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("request refused")
+			resp.Error = err.Error()
+		}
+
+		err = enc.Encode(resp)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(req request) ([]byte, error) {
+	switch req.Op {
+	case opPublicKey:
+		data, err := s.signer.GetPublicKey().Serialize(s.context)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize public key: %v", err)
+		}
+
+		return data, nil
+	case opSign:
+		if req.Scope == nil {
+			return nil, xerrors.New("message without scope")
+		}
+
+		err := s.checkScope(req.Message, *req.Scope)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.sign(req.Message)
+	case opSignUnscoped:
+		if !s.unscoped {
+			return nil, xerrors.New("message without scope")
+		}
+
+		if s.verifier.IsScoped(req.Message) {
+			return nil, xerrors.Errorf("message of %d bytes requires a scope", len(req.Message))
+		}
+
+		return s.sign(req.Message)
+	default:
+		return nil, xerrors.Errorf("unknown operation '%s'", req.Op)
+	}
+}
+
+// checkScope verifies the scope against the message, and records it if it
+// doesn't conflict with what has been signed.
+func (s *Server) checkScope(msg []byte, scope crypto.SignScope) error {
+	err := s.verifier.VerifyScope(msg, scope)
+	if err != nil {
+		return xerrors.Errorf("invalid scope: %v", err)
+	}
+
+	kind := s.verifier.GetRequirement(scope.Kind)
+	if kind != "" && !s.guard.Has(kind, scope.Index, scope.ID) {
+		return xerrors.Errorf("%s of %x at index %d not signed", kind, scope.ID, scope.Index)
+	}
+
+	err = s.guard.Check(scope)
+	if err != nil {
+		return xerrors.Errorf("double sign protection: %v", err)
+	}
+
+	return nil
+}
+
+func (s *Server) sign(msg []byte) ([]byte, error) {
+	sig, err := s.signer.Sign(msg)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign: %v", err)
+	}
+
+	data, err := sig.Serialize(s.context)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize signature: %v", err)
+	}
+
+	return data, nil
+}
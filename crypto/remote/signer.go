@@ -0,0 +1,272 @@
+package remote
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// maxScopes is the number of scopes kept for the latest messages. A
+	// message can be signed several times, for instance when a proposal is
+	// sent again, therefore the scopes are only dropped when newer ones
+	// replace them.
+	maxScopes = 64
+)
+
+// Algorithm provides the primitives of a signature scheme that don't need the
+// private key. Any aggregate signer of the scheme can be used, as its key is
+// never used.
+type Algorithm interface {
+	GetPublicKeyFactory() crypto.PublicKeyFactory
+	GetSignatureFactory() crypto.SignatureFactory
+	GetVerifierFactory() crypto.VerifierFactory
+	Aggregate(signatures ...crypto.Signature) (crypto.Signature, error)
+}
+
+// SignerOption is the type of option to set some fields of a remote signer.
+type SignerOption func(*Signer)
+
+// WithTimeout is an option to set the maximum amount of time to wait for the
+// daemon to answer.
+func WithTimeout(timeout time.Duration) SignerOption {
+	return func(s *Signer) {
+		s.timeout = timeout
+	}
+}
+
+// Signer is a signer that asks a signer daemon to sign the messages. It
+// reconnects to the daemon when the connection is lost.
+//
+// - implements crypto.AggregateSigner
+// - implements crypto.ScopedSigner
+// - implements crypto.UnscopedSigner
+type Signer struct {
+	sync.Mutex
+
+	path      string
+	timeout   time.Duration
+	algorithm Algorithm
+	context   serde.Context
+	pubkey    crypto.PublicKey
+	scopes    map[string]crypto.SignScope
+	order     []string
+
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// NewSigner connects to the daemon listening on the unix socket and returns a
+// signer for its key.
+func NewSigner(path string, algorithm Algorithm, opts ...SignerOption) (*Signer, error) {
+	s := &Signer{
+		path:      path,
+		timeout:   defaultTimeout,
+		algorithm: algorithm,
+		context:   sjson.NewContext(),
+		scopes:    make(map[string]crypto.SignScope),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	data, err := s.call(request{Op: opPublicKey})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get public key: %v", err)
+	}
+
+	s.pubkey, err = algorithm.GetPublicKeyFactory().PublicKeyOf(s.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode public key: %v", err)
+	}
+
+	return s, nil
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns the public key
+// factory of the algorithm.
+func (s *Signer) GetPublicKeyFactory() crypto.PublicKeyFactory {
+	return s.algorithm.GetPublicKeyFactory()
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns the signature
+// factory of the algorithm.
+func (s *Signer) GetSignatureFactory() crypto.SignatureFactory {
+	return s.algorithm.GetSignatureFactory()
+}
+
+// GetVerifierFactory implements crypto.AggregateSigner. It returns the verifier
+// factory of the algorithm.
+func (s *Signer) GetVerifierFactory() crypto.VerifierFactory {
+	return s.algorithm.GetVerifierFactory()
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// daemon.
+func (s *Signer) GetPublicKey() crypto.PublicKey {
+	return s.pubkey
+}
+
+// Aggregate implements crypto.AggregateSigner. It aggregates the signatures
+// locally as it doesn't need the private key.
+func (s *Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
+	return s.algorithm.Aggregate(signatures...)
+}
+
+// BindScope implements crypto.ScopedSigner. It remembers the scope of the
+// message so that it is sent along the request of its signature. The oldest
+// scope is dropped when too many are kept.
+func (s *Signer) BindScope(msg []byte, scope crypto.SignScope) {
+	s.Lock()
+	defer s.Unlock()
+
+	_, found := s.scopes[string(msg)]
+	if !found {
+		s.order = append(s.order, string(msg))
+	}
+
+	s.scopes[string(msg)] = scope
+
+	if len(s.order) > maxScopes {
+		delete(s.scopes, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Unscoped implements crypto.UnscopedSigner. It returns a signer that asks the
+// daemon to sign the messages without scope, which it only does when it
+// allows it.
+func (s *Signer) Unscoped() crypto.Signer {
+	return unscopedSigner{Signer: s}
+}
+
+// Sign implements crypto.Signer. It asks the daemon to sign the message with
+// its scope. The message is never sent without a scope, so that a consensus
+// message is not signed without the double sign protection.
+func (s *Signer) Sign(msg []byte) (crypto.Signature, error) {
+	s.Lock()
+	scope, found := s.scopes[string(msg)]
+	s.Unlock()
+
+	if !found {
+		return nil, xerrors.New("missing scope of the message")
+	}
+
+	return s.sign(request{
+		Op:      opSign,
+		Message: msg,
+		Scope:   &scope,
+	})
+}
+
+func (s *Signer) sign(req request) (crypto.Signature, error) {
+	data, err := s.call(req)
+	if err != nil {
+		return nil, xerrors.Errorf("remote: %v", err)
+	}
+
+	sig, err := s.algorithm.GetSignatureFactory().SignatureOf(s.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode signature: %v", err)
+	}
+
+	return sig, nil
+}
+
+// Close closes the connection to the daemon.
+func (s *Signer) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	if err != nil {
+		return xerrors.Errorf("failed to close connection: %v", err)
+	}
+
+	return nil
+}
+
+func (s *Signer) call(req request) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout("unix", s.path, s.timeout)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to connect: %v", err)
+		}
+
+		s.conn = conn
+		s.enc = json.NewEncoder(conn)
+		s.dec = json.NewDecoder(conn)
+	}
+
+	var resp response
+
+	err := s.roundTrip(req, &resp)
+	if err != nil {
+		// The connection is dropped so that the next request reconnects.
+		s.conn.Close()
+		s.conn = nil
+
+		return nil, err
+	}
+
+	if resp.Error != "" {
+		return nil, xerrors.Errorf("daemon refused: %s", resp.Error)
+	}
+
+	return resp.Data, nil
+}
+
+func (s *Signer) roundTrip(req request, resp *response) error {
+	err := s.conn.SetDeadline(time.Now().Add(s.timeout))
+	if err != nil {
+		return xerrors.Errorf("failed to set deadline: %v", err)
+	}
+
+	err = s.enc.Encode(req)
+	if err != nil {
+		return xerrors.Errorf("failed to send request: %v", err)
+	}
+
+	err = s.dec.Decode(resp)
+	if err != nil {
+		return xerrors.Errorf("failed to read response: %v", err)
+	}
+
+	return nil
+}
+
+// unscopedSigner is the signer of the messages without scope of a remote
+// signer.
+//
+// - implements crypto.Signer
+type unscopedSigner struct {
+	*Signer
+}
+
+// Sign implements crypto.Signer. It asks the daemon to sign the message without
+// scope.
+func (s unscopedSigner) Sign(msg []byte) (crypto.Signature, error) {
+	return s.Signer.sign(request{
+		Op:      opSignUnscoped,
+		Message: msg,
+	})
+}
@@ -0,0 +1,206 @@
+package remote
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	key := bls.NewSigner()
+	path, srv := startServer(t, key)
+	defer srv.Close()
+
+	signer, err := NewSigner(path, bls.NewSigner())
+	require.NoError(t, err)
+
+	defer signer.Close()
+
+	require.True(t, key.GetPublicKey().Equal(signer.GetPublicKey()))
+	require.NotNil(t, signer.GetPublicKeyFactory())
+	require.NotNil(t, signer.GetSignatureFactory())
+	require.NotNil(t, signer.GetVerifierFactory())
+
+	msg := []byte("block A")
+	signer.BindScope(msg, makeScope(1, 0, "A"))
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+	require.NoError(t, key.GetPublicKey().Verify(msg, sig))
+
+	agg, err := signer.Aggregate(sig)
+	require.NoError(t, err)
+	require.NoError(t, key.GetPublicKey().Verify(msg, agg))
+
+	// The scope is kept so that the message can be signed again.
+	_, err = signer.Sign(msg)
+	require.NoError(t, err)
+
+	_, err = signer.Sign([]byte("block C"))
+	require.EqualError(t, err, "missing scope of the message")
+
+	msg = []byte("block B")
+	signer.BindScope(msg, makeScope(1, 0, "B"))
+
+	_, err = signer.Sign(msg)
+	require.EqualError(t, err, "remote: daemon refused: double sign protection: "+
+		"prepare at index 1 and view 0 already signed for 41")
+}
+
+func TestSigner_Unscoped(t *testing.T) {
+	path, srv := startServer(t, bls.NewSigner(), WithUnscoped())
+	defer srv.Close()
+
+	signer, err := NewSigner(path, bls.NewSigner(), WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	_, err = signer.Unscoped().Sign([]byte("tx"))
+	require.NoError(t, err)
+
+	// A message that might be a consensus message needs a scope.
+	_, err = signer.Unscoped().Sign(make([]byte, 32))
+	require.EqualError(t, err, "remote: daemon refused: message of 32 bytes requires a scope")
+
+	_, err = signer.call(request{Op: "unknown"})
+	require.EqualError(t, err, "daemon refused: unknown operation 'unknown'")
+
+	for i := 0; i < maxScopes+1; i++ {
+		signer.BindScope([]byte{byte(i)}, crypto.SignScope{})
+	}
+
+	// Only the oldest scope is dropped.
+	require.Len(t, signer.scopes, maxScopes)
+	require.NotContains(t, signer.scopes, string([]byte{0}))
+	require.Contains(t, signer.scopes, string([]byte{1}))
+}
+
+func TestSigner_RefuseUnscoped(t *testing.T) {
+	path, srv := startServer(t, bls.NewSigner())
+	defer srv.Close()
+
+	signer, err := NewSigner(path, bls.NewSigner())
+	require.NoError(t, err)
+
+	_, err = signer.Unscoped().Sign([]byte("tx"))
+	require.EqualError(t, err, "remote: daemon refused: message without scope")
+
+	_, err = signer.call(request{Op: opSign, Message: []byte("tx")})
+	require.EqualError(t, err, "daemon refused: message without scope")
+}
+
+func TestServer_VerifyScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signer.sock")
+
+	verifier := fakeVerifier{requirements: map[string]string{"commit": "prepare"}}
+
+	srv := NewServer(bls.NewSigner(), makeGuard(t), verifier)
+	require.NoError(t, srv.Listen(path))
+
+	defer srv.Close()
+
+	signer, err := NewSigner(path, bls.NewSigner())
+	require.NoError(t, err)
+
+	commit := crypto.SignScope{Kind: "commit", Index: 1, ID: []byte("A")}
+
+	signer.BindScope([]byte("commit A"), commit)
+
+	_, err = signer.Sign([]byte("commit A"))
+	require.EqualError(t, err, "remote: daemon refused: prepare of 41 at index 1 not signed")
+
+	signer.BindScope([]byte("A"), makeScope(1, 0, "A"))
+
+	_, err = signer.Sign([]byte("A"))
+	require.NoError(t, err)
+
+	_, err = signer.Sign([]byte("commit A"))
+	require.NoError(t, err)
+
+	srv.verifier = fakeVerifier{err: fake.GetError()}
+
+	_, err = signer.Sign([]byte("A"))
+	require.EqualError(t, err, fake.Err("remote: daemon refused: invalid scope"))
+}
+
+func TestSigner_Reconnect(t *testing.T) {
+	key := bls.NewSigner()
+	path, srv := startServer(t, key, WithUnscoped())
+
+	signer, err := NewSigner(path, bls.NewSigner())
+	require.NoError(t, err)
+
+	require.NoError(t, srv.Close())
+
+	_, err = signer.Unscoped().Sign([]byte("tx"))
+	require.Regexp(t, "^remote: failed to (send request|read response): ", err)
+
+	_, err = signer.Unscoped().Sign([]byte("tx"))
+	require.Regexp(t, "^remote: failed to connect: ", err)
+
+	srv = NewServer(key, makeGuard(t), fakeVerifier{}, WithUnscoped())
+	require.NoError(t, srv.Listen(path))
+
+	defer srv.Close()
+
+	_, err = signer.Unscoped().Sign([]byte("tx"))
+	require.NoError(t, err)
+
+	require.NoError(t, signer.Close())
+	require.NoError(t, signer.Close())
+}
+
+func TestSigner_FailConnect(t *testing.T) {
+	_, err := NewSigner(filepath.Join(t.TempDir(), "none.sock"), bls.NewSigner())
+	require.Regexp(t, "^failed to get public key: failed to connect: ", err)
+}
+
+func TestServer_Listen(t *testing.T) {
+	path, srv := startServer(t, bls.NewSigner())
+	defer srv.Close()
+
+	err := NewServer(bls.NewSigner(), makeGuard(t), fakeVerifier{}).Listen(path)
+	require.Regexp(t, "^failed to listen: ", err)
+
+	require.NoError(t, NewServer(bls.NewSigner(), makeGuard(t), fakeVerifier{}).Close())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func startServer(t *testing.T, signer crypto.Signer, opts ...ServerOption) (string, *Server) {
+	path := filepath.Join(t.TempDir(), "signer.sock")
+
+	srv := NewServer(signer, makeGuard(t), fakeVerifier{}, opts...)
+	require.NoError(t, srv.Listen(path))
+
+	return path, srv
+}
+
+func makeGuard(t *testing.T) *Guard {
+	guard, err := NewGuard("")
+	require.NoError(t, err)
+
+	return guard
+}
+
+type fakeVerifier struct {
+	requirements map[string]string
+	err          error
+}
+
+func (v fakeVerifier) VerifyScope([]byte, crypto.SignScope) error {
+	return v.err
+}
+
+func (v fakeVerifier) GetRequirement(kind string) string {
+	return v.requirements[kind]
+}
+
+func (v fakeVerifier) IsScoped(msg []byte) bool {
+	return len(msg) == 32
+}
@@ -51,4 +51,13 @@ type Actor interface {
 	// VerifiableDecrypt decrypts a pair of kyber points into the original message
 	// using the DKG internal private key and a verifiable encryption function.
 	VerifiableDecrypt(ciphertexts []types.Ciphertext) ([][]byte, error)
+
+	// Beacon computes the value of the random beacon for the input with a
+	// threshold of the participants, and returns their shares, which anyone
+	// can verify against the public key.
+	Beacon(input []byte) ([]types.BeaconShare, error)
+
+	// Sign signs the message with a threshold of the participants, and returns
+	// an EdDSA signature that verifies against the collective public key.
+	Sign(message []byte) ([]byte, error)
 }
@@ -0,0 +1,275 @@
+// This file contains the implementation of the random beacon. The value of an
+// input is the point of the input multiplied by the distributed private key,
+// which a threshold of the participants computes from their shares. As the
+// value is unique for the input, nobody can bias it, and anyone can verify it
+// against the distributed public key.
+
+package pedersen
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/internal/tracing"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	"golang.org/x/xerrors"
+)
+
+// protocolNameBeacon denotes the value of the protocol span tag associated with
+// the `dkg-beacon` protocol.
+const protocolNameBeacon = "dkg-beacon"
+
+const (
+	shareSeparator = ","
+	fieldSeparator = ":"
+)
+
+// Beacon implements dkg.Actor. It requests the shares of the value of the input
+// to the participants, and returns as soon as a threshold of valid shares are
+// received.
+func (a *Actor) Beacon(input []byte) ([]types.BeaconShare, error) {
+	if !a.startRes.Done() {
+		return nil, xerrors.Errorf(initDkgFirst)
+	}
+
+	ctx, cancel := a.withTimeout(decryptTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameBeacon)
+
+	addrs := a.startRes.getParticipants()
+
+	sender, receiver, err := a.rpc.Stream(ctx, mino.NewAddresses(addrs...))
+	if err != nil {
+		return nil, xerrors.Errorf(failedStreamCreation, err)
+	}
+
+	err = <-sender.Send(types.NewBeaconRequest(input), addrs...)
+	if err != nil {
+		// The shares of the participants that are reachable might be enough.
+		dela.Logger.Warn().Err(err).Msg("failed to send beacon request")
+	}
+
+	base := beaconBase(input)
+	poly := a.startRes.getPubPoly()
+	threshold := a.startRes.getThreshold()
+
+	shares := make([]types.BeaconShare, 0, threshold)
+	received := make(map[int64]struct{})
+
+	for len(shares) < threshold {
+		from, msg, err := receiver.Recv(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf(unexpectedStreamStop, err)
+		}
+
+		reply, ok := msg.(types.BeaconReply)
+		if !ok {
+			return nil, xerrors.Errorf(unexpectedReply, reply, msg)
+		}
+
+		beaconShare := reply.GetShare()
+
+		err = checkBeaconShare(base, poly, beaconShare)
+		if err != nil {
+			dela.Logger.Warn().Err(err).Stringer("from", from).Msg("invalid beacon share")
+			continue
+		}
+
+		_, found := received[beaconShare.I]
+		if found {
+			continue
+		}
+
+		received[beaconShare.I] = struct{}{}
+		shares = append(shares, beaconShare)
+	}
+
+	return shares, nil
+}
+
+// BeaconInput returns the input of a round of the random beacon, which is
+// chained to the value of the previous round.
+func BeaconInput(round uint64, previous []byte) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, round)
+
+	h := sha256.New()
+	h.Write(buffer)
+	h.Write(previous)
+
+	return h.Sum(nil)
+}
+
+// NewBeaconShare returns the share of the value of the input for the private
+// share, with a proof that the value and the public share use the same private
+// share.
+func NewBeaconShare(input []byte, priShare *share.PriShare) types.BeaconShare {
+	return newBeaconShare(input, priShare, suite.RandomStream())
+}
+
+// newBeaconShare returns the share of the value of the input with a proof that
+// draws its nonce from the stream.
+func newBeaconShare(input []byte, priShare *share.PriShare,
+	random cipher.Stream) types.BeaconShare {
+
+	V, X, E, F := proveDLEQ(beaconBase(input), priShare.V, random)
+
+	return types.BeaconShare{
+		I: int64(priShare.I),
+		V: V,
+		X: X,
+		E: E,
+		F: F,
+	}
+}
+
+// VerifyBeacon verifies the shares of the value of the input against the
+// distributed public key, and returns the randomness of the value.
+func VerifyBeacon(pubkey kyber.Point, input []byte, shares []types.BeaconShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, xerrors.New("no share")
+	}
+
+	base := beaconBase(input)
+
+	values := make([]*share.PubShare, len(shares))
+	pubshares := make([]*share.PubShare, len(shares))
+	received := make(map[int64]struct{})
+
+	for i, beaconShare := range shares {
+		_, found := received[beaconShare.I]
+		if found {
+			return nil, xerrors.Errorf("duplicate share %d", beaconShare.I)
+		}
+
+		received[beaconShare.I] = struct{}{}
+
+		err := checkBeaconShare(base, nil, beaconShare)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid share %d: %v", beaconShare.I, err)
+		}
+
+		values[i] = &share.PubShare{I: int(beaconShare.I), V: beaconShare.V}
+		pubshares[i] = &share.PubShare{I: int(beaconShare.I), V: beaconShare.X}
+	}
+
+	// As every share proves that the value and the public share use the same
+	// private share, the value is the one of the distributed key if the public
+	// shares interpolate to the distributed public key.
+	key, err := share.RecoverCommit(suite, pubshares, len(shares), len(shares))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to recover public key: %v", err)
+	}
+
+	if !key.Equal(pubkey) {
+		return nil, xerrors.New("shares don't match the public key")
+	}
+
+	value, err := share.RecoverCommit(suite, values, len(shares), len(shares))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to recover value: %v", err)
+	}
+
+	h := sha256.New()
+
+	_, err = value.MarshalTo(h)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal value: %v", err)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// EncodeBeaconShares returns the textual representation of the shares, as
+// <I>:<hex(V)>:<hex(X)>:<hex(E)>:<hex(F)> separated by commas.
+func EncodeBeaconShares(shares []types.BeaconShare) (string, error) {
+	encoded := make([]string, len(shares))
+
+	for i, beaconShare := range shares {
+		fields := []string{strconv.FormatInt(beaconShare.I, 10)}
+
+		for _, m := range []interface{ MarshalBinary() ([]byte, error) }{
+			beaconShare.V, beaconShare.X, beaconShare.E, beaconShare.F,
+		} {
+			data, err := m.MarshalBinary()
+			if err != nil {
+				return "", xerrors.Errorf("failed to marshal share %d: %v", beaconShare.I, err)
+			}
+
+			fields = append(fields, hex.EncodeToString(data))
+		}
+
+		encoded[i] = strings.Join(fields, fieldSeparator)
+	}
+
+	return strings.Join(encoded, shareSeparator), nil
+}
+
+// DecodeBeaconShares returns the shares of their textual representation.
+func DecodeBeaconShares(str string) ([]types.BeaconShare, error) {
+	parts := strings.Split(str, shareSeparator)
+	shares := make([]types.BeaconShare, len(parts))
+
+	for i, part := range parts {
+		fields := strings.Split(part, fieldSeparator)
+		if len(fields) != 5 {
+			return nil, xerrors.Errorf("malformed share '%s'", part)
+		}
+
+		index, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, xerrors.Errorf("malformed index: %v", err)
+		}
+
+		beaconShare := types.BeaconShare{
+			I: index,
+			V: suite.Point(),
+			X: suite.Point(),
+			E: suite.Scalar(),
+			F: suite.Scalar(),
+		}
+
+		for j, m := range []interface{ UnmarshalBinary([]byte) error }{
+			beaconShare.V, beaconShare.X, beaconShare.E, beaconShare.F,
+		} {
+			data, err := hex.DecodeString(fields[j+1])
+			if err != nil {
+				return nil, xerrors.Errorf("malformed share %d: %v", index, err)
+			}
+
+			err = m.UnmarshalBinary(data)
+			if err != nil {
+				return nil, xerrors.Errorf("malformed share %d: %v", index, err)
+			}
+		}
+
+		shares[i] = beaconShare
+	}
+
+	return shares, nil
+}
+
+// beaconBase returns the point of the input, of which nobody knows the discrete
+// logarithm.
+func beaconBase(input []byte) kyber.Point {
+	return suite.Point().Pick(suite.XOF(input))
+}
+
+// checkBeaconShare verifies the proof of the share and, if the public
+// polynomial is known, that the public share is the one of the participant.
+func checkBeaconShare(base kyber.Point, poly *share.PubPoly, s types.BeaconShare) error {
+	if poly != nil && !poly.Eval(int(s.I)).V.Equal(s.X) {
+		return xerrors.Errorf("unexpected public share for %d", s.I)
+	}
+
+	return checkDLEQ(base, s.V, s.X, s.E, s.F)
+}
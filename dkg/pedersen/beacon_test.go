@@ -0,0 +1,207 @@
+package pedersen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+func TestPedersen_Beacon(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+	input := []byte("abc")
+
+	share1 := NewBeaconShare(input, priPoly.Shares(3)[0])
+	share2 := NewBeaconShare(input, priPoly.Shares(3)[1])
+
+	actor := Actor{
+		rpc: fake.NewBadRPC(),
+		startRes: &state{
+			dkgState:     certified,
+			participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+			poly:         pubPoly,
+			threshold:    2,
+		},
+	}
+
+	_, err := actor.Beacon(input)
+	require.EqualError(t, err, fake.Err("failed to create stream"))
+
+	recv := fake.NewReceiver(fake.NewRecvMsg(fake.NewAddress(0), nil))
+	actor.rpc = fake.NewStreamRPC(recv, fake.NewBadSender())
+
+	_, err = actor.Beacon(input)
+	require.EqualError(t, err,
+		"got unexpected reply, expected types.BeaconReply but got: <nil>")
+
+	invalid := share1
+	invalid.I = 2
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewBeaconReply(invalid)),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewBeaconReply(share1)),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewBeaconReply(share1)),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	_, err = actor.Beacon(input)
+	require.EqualError(t, err, "stream stopped unexpectedly: EOF")
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewBeaconReply(share1)),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewBeaconReply(share2)),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	shares, err := actor.Beacon(input)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+
+	_, err = VerifyBeacon(pubPoly.Commit(), input, shares)
+	require.NoError(t, err)
+}
+
+func TestPedersen_BeaconNotDone(t *testing.T) {
+	actor := Actor{
+		startRes: &state{dkgState: initial},
+	}
+
+	_, err := actor.Beacon(nil)
+	require.EqualError(t, err, initDkgFirst)
+}
+
+func TestBeaconInput(t *testing.T) {
+	require.Len(t, BeaconInput(0, nil), 32)
+	require.Equal(t, BeaconInput(1, []byte{1}), BeaconInput(1, []byte{1}))
+	require.NotEqual(t, BeaconInput(1, []byte{1}), BeaconInput(2, []byte{1}))
+	require.NotEqual(t, BeaconInput(1, []byte{1}), BeaconInput(1, []byte{2}))
+}
+
+func TestVerifyBeacon(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 3, nil, suite.RandomStream())
+	pubkey := priPoly.Commit(nil).Commit()
+	input := []byte("abc")
+
+	shares := makeBeaconShares(input, priPoly.Shares(5))
+
+	value, err := VerifyBeacon(pubkey, input, shares[:3])
+	require.NoError(t, err)
+	require.Len(t, value, 32)
+
+	// Any threshold of the participants computes the same value.
+	other, err := VerifyBeacon(pubkey, input, shares[2:])
+	require.NoError(t, err)
+	require.Equal(t, value, other)
+
+	other, err = VerifyBeacon(pubkey, []byte("def"),
+		makeBeaconShares([]byte("def"), priPoly.Shares(5)[:3]))
+	require.NoError(t, err)
+	require.NotEqual(t, value, other)
+}
+
+func TestVerifyBeacon_Fail(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubkey := priPoly.Commit(nil).Commit()
+	input := []byte("abc")
+
+	shares := makeBeaconShares(input, priPoly.Shares(3))
+
+	_, err := VerifyBeacon(pubkey, input, nil)
+	require.EqualError(t, err, "no share")
+
+	_, err = VerifyBeacon(pubkey, input, []types.BeaconShare{shares[0], shares[0]})
+	require.EqualError(t, err, "duplicate share 0")
+
+	_, err = VerifyBeacon(pubkey, []byte("def"), shares[:2])
+	require.Error(t, err)
+	require.Regexp(t, "^invalid share 0: invalid proof", err.Error())
+
+	_, err = VerifyBeacon(suite.Point(), input, shares[:2])
+	require.EqualError(t, err, "shares don't match the public key")
+
+	// A share of a different polynomial is valid on its own but doesn't
+	// interpolate to the public key.
+	otherPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	forged := makeBeaconShares(input, otherPoly.Shares(3))
+
+	_, err = VerifyBeacon(pubkey, input, []types.BeaconShare{shares[0], forged[1]})
+	require.EqualError(t, err, "shares don't match the public key")
+
+	// Not enough shares to interpolate the public key of a higher threshold.
+	_, err = VerifyBeacon(pubkey, input, shares[:1])
+	require.EqualError(t, err, "shares don't match the public key")
+}
+
+func TestBeaconShares_EncodeDecode(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	shares := makeBeaconShares([]byte("abc"), priPoly.Shares(2))
+
+	str, err := EncodeBeaconShares(shares)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(str, ","), 2)
+
+	decoded, err := DecodeBeaconShares(str)
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+
+	for i := range shares {
+		require.Equal(t, shares[i].I, decoded[i].I)
+		require.True(t, shares[i].V.Equal(decoded[i].V))
+		require.True(t, shares[i].X.Equal(decoded[i].X))
+		require.True(t, shares[i].E.Equal(decoded[i].E))
+		require.True(t, shares[i].F.Equal(decoded[i].F))
+	}
+
+	_, err = VerifyBeacon(priPoly.Commit(nil).Commit(), []byte("abc"), decoded)
+	require.NoError(t, err)
+}
+
+func TestEncodeBeaconShares_Fail(t *testing.T) {
+	shares := []types.BeaconShare{{I: 1, V: badPoint{}}}
+
+	_, err := EncodeBeaconShares(shares)
+	require.EqualError(t, err, fake.Err("failed to marshal share 1"))
+}
+
+func TestDecodeBeaconShares_Fail(t *testing.T) {
+	_, err := DecodeBeaconShares("1:aa")
+	require.EqualError(t, err, "malformed share '1:aa'")
+
+	_, err = DecodeBeaconShares("a:aa:aa:aa:aa")
+	require.Error(t, err)
+	require.Regexp(t, "^malformed index: ", err.Error())
+
+	_, err = DecodeBeaconShares("1:zz:aa:aa:aa")
+	require.Error(t, err)
+	require.Regexp(t, "^malformed share 1: encoding/hex", err.Error())
+
+	_, err = DecodeBeaconShares("1:aa:aa:aa:aa")
+	require.Error(t, err)
+	require.Regexp(t, "^malformed share 1: ", err.Error())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeBeaconShares(input []byte, priShares []*share.PriShare) []types.BeaconShare {
+	shares := make([]types.BeaconShare, len(priShares))
+	for i, priShare := range priShares {
+		shares[i] = NewBeaconShare(input, priShare)
+	}
+
+	return shares
+}
+
+type badPoint struct {
+	kyber.Point
+}
+
+func (badPoint) MarshalBinary() ([]byte, error) {
+	return nil, fake.GetError()
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,8 +14,10 @@ import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/ed25519"
 	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/dkg/pedersen"
 	mTypes "go.dedis.ch/dela/dkg/pedersen/types"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/suites"
 	"golang.org/x/xerrors"
@@ -31,9 +34,7 @@ const malformedEncoded = "malformed encoded: %s"
 type setupAction struct{}
 
 func (a setupAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
@@ -45,12 +46,79 @@ func (a setupAction) Execute(ctx node.Context) error {
 
 	t := ctx.Flags.Int("threshold")
 
-	pubkey, err := actor.Setup(co, t)
+	path := ctx.Flags.String("transcript")
+	if path == "" {
+		pubkey, err := actor.Setup(co, t)
+		if err != nil {
+			return xerrors.Errorf("failed to setup: %v", err)
+		}
+
+		fmt.Fprintf(ctx.Out, "✅ Setup done.\n🔑 Pubkey: %s", pubkey.String())
+
+		return nil
+	}
+
+	transcriber, ok := actor.(transcriptActor)
+	if !ok {
+		return xerrors.Errorf("actor '%T' does not export a transcript", actor)
+	}
+
+	transcript, err := transcriber.SetupWithTranscript(co, t)
 	if err != nil {
 		return xerrors.Errorf("failed to setup: %v", err)
 	}
 
-	fmt.Fprintf(ctx.Out, "✅ Setup done.\n🔑 Pubkey: %s", pubkey.String())
+	data, err := transcript.Serialize(json.NewContext())
+	if err != nil {
+		return xerrors.Errorf("failed to serialize transcript: %v", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to write transcript: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "✅ Setup done.\n🔑 Pubkey: %s\n🚫 Excluded dealers: %v\n"+
+		"📜 Transcript written in %s", transcript.GetPublicKey(), pedersen.Excluded(transcript),
+		path)
+
+	return nil
+}
+
+// transcriptActor is implemented by the actors that export the transcript of
+// the setup.
+type transcriptActor interface {
+	SetupWithTranscript(co crypto.CollectiveAuthority, threshold int) (mTypes.Transcript, error)
+}
+
+// verifyTranscriptAction verifies a transcript written by the setup. It does not
+// need the node to be part of the DKG.
+type verifyTranscriptAction struct{}
+
+func (a verifyTranscriptAction) Execute(ctx node.Context) error {
+	data, err := os.ReadFile(ctx.Flags.String("transcript"))
+	if err != nil {
+		return xerrors.Errorf("failed to read transcript: %v", err)
+	}
+
+	msg, err := mTypes.NewMessageFactory(nil).Deserialize(json.NewContext(), data)
+	if err != nil {
+		return xerrors.Errorf("failed to deserialize transcript: %v", err)
+	}
+
+	transcript, ok := msg.(mTypes.Transcript)
+	if !ok {
+		return xerrors.Errorf("expected a transcript, got '%T'", msg)
+	}
+
+	err = pedersen.VerifyTranscript(transcript)
+	if err != nil {
+		return xerrors.Errorf("failed to verify transcript: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "✅ Transcript verified.\n🔑 Pubkey: %s\n"+
+		"👥 Qualified dealers: %v\n🚫 Excluded dealers: %v", transcript.GetPublicKey(),
+		transcript.GetQualified(), pedersen.Excluded(transcript))
 
 	return nil
 }
@@ -82,28 +150,19 @@ type listenAction struct {
 }
 
 func (a listenAction) Execute(ctx node.Context) error {
-	var dkgObject dkg.DKG
-
-	err := ctx.Injector.Resolve(&dkgObject)
-	if err != nil {
-		return xerrors.Errorf("failed to resolve dkg: %v", err)
-	}
-
-	actor, err := dkgObject.Listen()
+	pubkey, err := a.listen(ctx, ctx.Flags.String(idFlag))
 	if err != nil {
-		return xerrors.Errorf("failed to listen: %v", err)
+		return err
 	}
 
-	ctx.Injector.Inject(actor)
-
 	fmt.Fprintf(ctx.Out, "✅  Listen done, actor is created.")
 
-	str, err := encodeAuthority(ctx, a.pubkey)
+	str, err := encodeAuthority(ctx, pubkey)
 	if err != nil {
 		return xerrors.Errorf("failed to encode authority: %v", err)
 	}
 
-	path := filepath.Join(ctx.Flags.Path("config"), authconfig)
+	path := filepath.Join(ctx.Flags.Path("config"), authorityFile(ctx.Flags.String(idFlag)))
 
 	err = os.WriteFile(path, []byte(str), 0755)
 	if err != nil {
@@ -115,6 +174,88 @@ func (a listenAction) Execute(ctx node.Context) error {
 	return nil
 }
 
+// listen creates the actor of the instance and returns the public key of the
+// node for it.
+func (a listenAction) listen(ctx node.Context, id string) (kyber.Point, error) {
+	if id != "" {
+		var insts *instances
+
+		err := ctx.Injector.Resolve(&insts)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to resolve instances: %v", err)
+		}
+
+		_, pubkey, err := insts.listen(id)
+		if err != nil {
+			return nil, xerrors.Errorf("instance '%s': %v", id, err)
+		}
+
+		return pubkey, nil
+	}
+
+	var dkgObject dkg.DKG
+
+	err := ctx.Injector.Resolve(&dkgObject)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to resolve dkg: %v", err)
+	}
+
+	// The actor already exists when the node restored the result of a
+	// previous DKG on start.
+	var actor dkg.Actor
+
+	err = ctx.Injector.Resolve(&actor)
+	if err != nil {
+		actor, err = dkgObject.Listen()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to listen: %v", err)
+		}
+
+		ctx.Injector.Inject(actor)
+	}
+
+	return a.pubkey, nil
+}
+
+// authorityFile returns the name of the authority file of the instance.
+func authorityFile(id string) string {
+	if id == "" {
+		return authconfig
+	}
+
+	return authconfig + "-" + id
+}
+
+// getActor returns the actor of the instance selected by the flag, or the
+// default one.
+func getActor(ctx node.Context) (dkg.Actor, error) {
+	id := ctx.Flags.String(idFlag)
+	if id == "" {
+		var actor dkg.Actor
+
+		err := ctx.Injector.Resolve(&actor)
+		if err != nil {
+			return nil, err
+		}
+
+		return actor, nil
+	}
+
+	var insts *instances
+
+	err := ctx.Injector.Resolve(&insts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to resolve instances: %v", err)
+	}
+
+	actor, found := insts.getActor(id)
+	if !found {
+		return nil, xerrors.Errorf("unknown instance '%s'", id)
+	}
+
+	return actor, nil
+}
+
 func encodeAuthority(ctx node.Context, pk kyber.Point) (string, error) {
 	var m mino.Mino
 	err := ctx.Injector.Resolve(&m)
@@ -177,13 +318,17 @@ func decodeAuthority(ctx node.Context, str string) (mino.Address, kyber.Point, e
 type encryptAction struct{}
 
 func (a encryptAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
 
+	if ctx.Flags.String("in") != "" {
+		return streamFiles(ctx, func(dst io.Writer, src io.Reader) error {
+			return pedersen.EncryptStream(actor, dst, src)
+		})
+	}
+
 	message, err := hex.DecodeString(ctx.Flags.String("message"))
 	if err != nil {
 		return xerrors.Errorf("failed to decode message: %v", err)
@@ -207,13 +352,17 @@ func (a encryptAction) Execute(ctx node.Context) error {
 type decryptAction struct{}
 
 func (a decryptAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
 
+	if ctx.Flags.String("in") != "" {
+		return streamFiles(ctx, func(dst io.Writer, src io.Reader) error {
+			return pedersen.DecryptStream(actor, dst, src)
+		})
+	}
+
 	encrypted := ctx.Flags.String("encrypted")
 
 	k, cs, err := decodeEncrypted(encrypted)
@@ -231,12 +380,46 @@ func (a decryptAction) Execute(ctx node.Context) error {
 	return nil
 }
 
+// streamFiles runs the function from the input file to the output file, or to
+// the output of the command when no file is given. The output file is removed
+// when the function fails.
+func streamFiles(ctx node.Context, fn func(dst io.Writer, src io.Reader) error) error {
+	in, err := os.Open(ctx.Flags.String("in"))
+	if err != nil {
+		return xerrors.Errorf("failed to open input: %v", err)
+	}
+
+	defer in.Close()
+
+	path := ctx.Flags.String("out")
+	if path == "" {
+		return fn(ctx.Out, in)
+	}
+
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return xerrors.Errorf("failed to create output: %v", err)
+	}
+
+	err = fn(out, in)
+	if err == nil {
+		err = out.Close()
+	} else {
+		out.Close()
+	}
+
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
 type reencryptAction struct{}
 
 func (a reencryptAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
@@ -362,9 +545,7 @@ func decodeEncrypted(str string) (kyber.Point, []kyber.Point, error) {
 type verifiableEncryptAction struct{}
 
 func (a verifiableEncryptAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
@@ -441,9 +622,7 @@ func (a verifiableEncryptAction) Execute(ctx node.Context) error {
 type verifiableDecryptAction struct{}
 
 func (a verifiableDecryptAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
@@ -573,9 +752,7 @@ func (a verifiableDecryptAction) Execute(ctx node.Context) error {
 type reshareAction struct{}
 
 func (a reshareAction) Execute(ctx node.Context) error {
-	var actor dkg.Actor
-
-	err := ctx.Injector.Resolve(&actor)
+	actor, err := getActor(ctx)
 	if err != nil {
 		return xerrors.Errorf(resolveActorFailed, err)
 	}
@@ -596,3 +773,59 @@ func (a reshareAction) Execute(ctx node.Context) error {
 
 	return nil
 }
+
+type beaconAction struct{}
+
+func (a beaconAction) Execute(ctx node.Context) error {
+	actor, err := getActor(ctx)
+	if err != nil {
+		return xerrors.Errorf(resolveActorFailed, err)
+	}
+
+	round := ctx.Flags.Int("round")
+	if round < 0 {
+		return xerrors.Errorf("invalid round: %d", round)
+	}
+
+	previous, err := hex.DecodeString(ctx.Flags.String("previous"))
+	if err != nil {
+		return xerrors.Errorf("failed to decode previous: %v", err)
+	}
+
+	shares, err := actor.Beacon(pedersen.BeaconInput(uint64(round), previous))
+	if err != nil {
+		return xerrors.Errorf("failed to compute beacon: %v", err)
+	}
+
+	outStr, err := pedersen.EncodeBeaconShares(shares)
+	if err != nil {
+		return xerrors.Errorf("failed to encode the shares: %v", err)
+	}
+
+	fmt.Fprint(ctx.Out, outStr)
+
+	return nil
+}
+
+type signAction struct{}
+
+func (a signAction) Execute(ctx node.Context) error {
+	actor, err := getActor(ctx)
+	if err != nil {
+		return xerrors.Errorf(resolveActorFailed, err)
+	}
+
+	message, err := hex.DecodeString(ctx.Flags.String("message"))
+	if err != nil {
+		return xerrors.Errorf("failed to decode message: %v", err)
+	}
+
+	signature, err := actor.Sign(message)
+	if err != nil {
+		return xerrors.Errorf("failed to sign: %v", err)
+	}
+
+	fmt.Fprint(ctx.Out, hex.EncodeToString(signature))
+
+	return nil
+}
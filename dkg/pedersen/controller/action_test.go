@@ -5,16 +5,21 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/dkg/pedersen"
 	"go.dedis.ch/dela/dkg/pedersen/types"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/dela/testing/fake"
 	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
 )
 
 func TestSetupAction_NoActor(t *testing.T) {
@@ -24,6 +29,7 @@ func TestSetupAction_NoActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -92,6 +98,107 @@ func TestSetupAction_OK(t *testing.T) {
 	require.Regexp(t, "^✅ Setup done", out.String())
 }
 
+func TestSetupAction_Transcript(t *testing.T) {
+	a := setupAction{}
+
+	path := filepath.Join(t.TempDir(), "transcript.json")
+
+	inj := node.NewInjector()
+	inj.Inject(&fakeActor{})
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{"transcript": path},
+		Out:      &bytes.Buffer{},
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, "actor '*controller.fakeActor' does not export a transcript")
+
+	inj = node.NewInjector()
+	inj.Inject(&fakeTranscriptActor{err: fake.GetError()})
+	ctx.Injector = inj
+
+	err = a.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to setup"))
+
+	inj = node.NewInjector()
+	inj.Inject(&fakeTranscriptActor{transcript: makeTranscript()})
+	ctx.Injector = inj
+
+	out := &bytes.Buffer{}
+	ctx.Out = out
+
+	err = a.Execute(ctx)
+	require.NoError(t, err)
+	require.Regexp(t, "^✅ Setup done", out.String())
+	require.Regexp(t, "Excluded dealers: \\[\\]", out.String())
+	require.FileExists(t, path)
+
+	ctx.Flags = node.FlagSet{"transcript": filepath.Join(path, "unknown")}
+
+	err = a.Execute(ctx)
+	require.Error(t, err)
+	require.Regexp(t, "^failed to write transcript:", err.Error())
+}
+
+func TestVerifyTranscriptAction_Execute(t *testing.T) {
+	a := verifyTranscriptAction{}
+
+	path := filepath.Join(t.TempDir(), "transcript.json")
+
+	ctx := node.Context{
+		Flags: node.FlagSet{"transcript": path},
+	}
+
+	err := a.Execute(ctx)
+	require.Error(t, err)
+	require.Regexp(t, "^failed to read transcript:", err.Error())
+
+	err = os.WriteFile(path, []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	err = a.Execute(ctx)
+	require.Error(t, err)
+	require.Regexp(t, "^failed to deserialize transcript:", err.Error())
+
+	data, err := types.NewStartDone(suite.Point()).Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	err = os.WriteFile(path, data, 0644)
+	require.NoError(t, err)
+
+	err = a.Execute(ctx)
+	require.EqualError(t, err, "expected a transcript, got 'types.StartDone'")
+
+	transcript := makeTranscript()
+
+	data, err = types.NewTranscript(1, transcript.GetPublicKeys(), nil, nil, nil,
+		transcript.GetCommitments(), []int{}, suite.Point()).Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	err = os.WriteFile(path, data, 0644)
+	require.NoError(t, err)
+
+	err = a.Execute(ctx)
+	require.Error(t, err)
+	require.Regexp(t, "^failed to verify transcript: qualified dealers mismatch:", err.Error())
+
+	data, err = transcript.Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	err = os.WriteFile(path, data, 0644)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	ctx.Out = out
+
+	err = a.Execute(ctx)
+	require.NoError(t, err)
+	require.Regexp(t, "^✅ Transcript verified.", out.String())
+	require.Regexp(t, "Qualified dealers: \\[0\\]", out.String())
+}
+
 func TestGetCollectiveAuth(t *testing.T) {
 	pubKey := "RjEyNy4wLjAuMToyMDAx:RcTqbSXCIkZmmaGjLVAZs8TdTvq7b3SFr14F89h6ID8="
 
@@ -119,6 +226,7 @@ func TestListenAction_NoDKG(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -135,6 +243,7 @@ func TestListenAction_listenFail(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -152,6 +261,7 @@ func TestListenAction_encodeFail(t *testing.T) {
 	ctx := node.Context{
 		Injector: inj,
 		Out:      io.Discard,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -215,6 +325,103 @@ func TestListenAction_OK(t *testing.T) {
 	require.Regexp(t, "^✅  Listen done, actor is created.📜 Config file written in", out.String())
 }
 
+func TestListenAction_Restored(t *testing.T) {
+	a := listenAction{
+		pubkey: suite.Point(),
+	}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeDKG{
+		err: fake.GetError(),
+	})
+	inj.Inject(fakeActor{})
+	inj.Inject(fake.Mino{})
+
+	ctx := node.Context{
+		Injector: inj,
+		Out:      io.Discard,
+		Flags:    node.FlagSet{"config": t.TempDir()},
+	}
+
+	// The actor restored on start is used instead of listening again.
+	err := a.Execute(ctx)
+	require.NoError(t, err)
+}
+
+func TestListenAction_Named(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	a := listenAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	ctx := node.Context{
+		Injector: inj,
+		Out:      io.Discard,
+		Flags:    node.FlagSet{"config": tmpDir, "id": "abc"},
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, "failed to resolve instances: "+
+		"couldn't find dependency for '*controller.instances'")
+
+	insts := newInstances(func(id string) (dkg.DKG, kyber.Point, error) {
+		return fakeDKG{actor: fakeActor{}}, suite.Point(), nil
+	})
+	inj.Inject(insts)
+
+	err = a.Execute(ctx)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(tmpDir, "dkgauthority-abc"))
+	require.NoFileExists(t, filepath.Join(tmpDir, "dkgauthority"))
+
+	_, found := insts.getActor("abc")
+	require.True(t, found)
+
+	ctx.Flags = node.FlagSet{"config": tmpDir, "id": "a/b"}
+
+	err = a.Execute(ctx)
+	require.EqualError(t, err, "instance 'a/b': invalid id 'a/b'")
+}
+
+func TestGetActor(t *testing.T) {
+	inj := node.NewInjector()
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{"id": "abc"},
+	}
+
+	_, err := getActor(ctx)
+	require.EqualError(t, err, "failed to resolve instances: "+
+		"couldn't find dependency for '*controller.instances'")
+
+	insts := newInstances(nil)
+	inj.Inject(insts)
+
+	_, err = getActor(ctx)
+	require.EqualError(t, err, "unknown instance 'abc'")
+
+	insts.actors["abc"] = fakeActor{encryptErr: fake.GetError()}
+
+	actor, err := getActor(ctx)
+	require.NoError(t, err)
+	require.Equal(t, insts.actors["abc"], actor)
+
+	// The named instances don't replace the default one.
+	ctx.Flags = node.FlagSet{}
+
+	_, err = getActor(ctx)
+	require.EqualError(t, err, "couldn't find dependency for 'dkg.Actor'")
+
+	inj.Inject(fakeActor{})
+
+	actor, err = getActor(ctx)
+	require.NoError(t, err)
+	require.Equal(t, fakeActor{}, actor)
+}
+
 func TestEncodeAuthority_marshalFail(t *testing.T) {
 	inj := node.NewInjector()
 	inj.Inject(fakeDKG{
@@ -226,6 +433,7 @@ func TestEncodeAuthority_marshalFail(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	_, err := encodeAuthority(ctx, badPoint{})
@@ -243,6 +451,7 @@ func TestEncodeAuthority_pkFail(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	pk := badPoint{
@@ -260,6 +469,7 @@ func TestDecodeAuthority_noMino(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	_, _, err := decodeAuthority(ctx, pubKey)
@@ -274,6 +484,7 @@ func TestDecodeAuthority_noBase64Addr(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	_, _, err := decodeAuthority(ctx, pubKey)
@@ -288,6 +499,7 @@ func TestDecodeAuthority_noBase64Pubkey(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	_, _, err := decodeAuthority(ctx, pubKey)
@@ -302,6 +514,7 @@ func TestDecodeAuthority_badUnmarshalPubkey(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	_, _, err := decodeAuthority(ctx, pubKey)
@@ -315,6 +528,7 @@ func TestEncryptAction_noActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -425,6 +639,7 @@ func TestDecryptAction_noActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -504,6 +719,86 @@ func TestDecryptAction_OK(t *testing.T) {
 	require.Equal(t, expected, out.String())
 }
 
+func TestEncryptDecryptAction_Stream(t *testing.T) {
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain")
+	encrypted := filepath.Join(dir, "encrypted")
+
+	payload := bytes.Repeat([]byte("abc"), 100000)
+	require.NoError(t, os.WriteFile(plain, payload, 0600))
+
+	inj := node.NewInjector()
+	inj.Inject(&keyActor{})
+
+	err := encryptAction{}.Execute(node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{"in": plain, "out": encrypted},
+	})
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+
+	err = decryptAction{}.Execute(node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{"in": encrypted},
+		Out:      out,
+	})
+	require.NoError(t, err)
+	require.Equal(t, payload, out.Bytes())
+}
+
+func TestEncryptAction_streamFail(t *testing.T) {
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain")
+	encrypted := filepath.Join(dir, "encrypted")
+
+	require.NoError(t, os.WriteFile(plain, []byte("abc"), 0600))
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{encryptErr: fake.GetError()})
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{"in": filepath.Join(dir, "unknown")},
+	}
+
+	err := encryptAction{}.Execute(ctx)
+	require.Regexp(t, "^failed to open input: ", err.Error())
+
+	ctx.Flags = node.FlagSet{"in": plain, "out": filepath.Join(dir, "unknown", "out")}
+
+	err = encryptAction{}.Execute(ctx)
+	require.Regexp(t, "^failed to create output: ", err.Error())
+
+	ctx.Flags = node.FlagSet{"in": plain, "out": encrypted}
+
+	err = encryptAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to encrypt key"))
+	require.NoFileExists(t, encrypted)
+}
+
+func TestDecryptAction_streamFail(t *testing.T) {
+	dir := t.TempDir()
+
+	encrypted := filepath.Join(dir, "encrypted")
+	plain := filepath.Join(dir, "plain")
+
+	require.NoError(t, os.WriteFile(encrypted, []byte("abc"), 0600))
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{})
+
+	err := decryptAction{}.Execute(node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{"in": encrypted, "out": plain},
+	})
+	require.EqualError(t, err,
+		"failed to decode header: failed to read prefix: unexpected EOF")
+	require.NoFileExists(t, plain)
+}
+
 func TestReencryptAction_noActor(t *testing.T) {
 	a := reencryptAction{}
 
@@ -511,6 +806,7 @@ func TestReencryptAction_noActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -670,6 +966,7 @@ func TestVerifiableEncryptAction_noActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -978,6 +1275,7 @@ func TestVerifiableDecryptAction_noActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -1434,6 +1732,7 @@ func TestReshareAction_noActor(t *testing.T) {
 
 	ctx := node.Context{
 		Injector: inj,
+		Flags:    node.FlagSet{},
 	}
 
 	err := a.Execute(ctx)
@@ -1502,9 +1801,224 @@ func TestReshareAction_OK(t *testing.T) {
 	require.Equal(t, "✅ Reshare done.\n", out.String())
 }
 
+func TestBeaconAction_noActor(t *testing.T) {
+	a := beaconAction{}
+
+	inj := node.NewInjector()
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{},
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, "failed to resolve actor, did you call listen?: "+
+		"couldn't find dependency for 'dkg.Actor'")
+}
+
+func TestBeaconAction_badRound(t *testing.T) {
+	a := beaconAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{})
+
+	flags := node.FlagSet{
+		"round": -1,
+	}
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    flags,
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, "invalid round: -1")
+}
+
+func TestBeaconAction_badPrevious(t *testing.T) {
+	a := beaconAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{})
+
+	flags := node.FlagSet{
+		"previous": "not hex",
+	}
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    flags,
+	}
+
+	err := a.Execute(ctx)
+	require.Regexp(t, "^failed to decode previous:", err.Error())
+}
+
+func TestBeaconAction_beaconFail(t *testing.T) {
+	a := beaconAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{
+		beaconErr: fake.GetError(),
+	})
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{},
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to compute beacon"))
+}
+
+func TestBeaconAction_encodeFail(t *testing.T) {
+	a := beaconAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{
+		shares: []types.BeaconShare{{V: badPoint{err: fake.GetError()}}},
+	})
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{},
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err,
+		fake.Err("failed to encode the shares: failed to marshal share 0"))
+}
+
+func TestBeaconAction_OK(t *testing.T) {
+	a := beaconAction{}
+
+	priShare := &share.PriShare{I: 1, V: suite.Scalar().Pick(suite.RandomStream())}
+	input := pedersen.BeaconInput(2, []byte{0xae, 0xf1, 0x23})
+	shares := []types.BeaconShare{pedersen.NewBeaconShare(input, priShare)}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{shares: shares})
+
+	flags := node.FlagSet{
+		"round":    2,
+		"previous": "aef123",
+	}
+
+	out := &bytes.Buffer{}
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    flags,
+		Out:      out,
+	}
+
+	err := a.Execute(ctx)
+	require.NoError(t, err)
+
+	expected, err := pedersen.EncodeBeaconShares(shares)
+	require.NoError(t, err)
+	require.Equal(t, expected, out.String())
+}
+
+func TestSignAction_noActor(t *testing.T) {
+	a := signAction{}
+
+	inj := node.NewInjector()
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    node.FlagSet{},
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, "failed to resolve actor, did you call listen?: "+
+		"couldn't find dependency for 'dkg.Actor'")
+}
+
+func TestSignAction_badMessage(t *testing.T) {
+	a := signAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{})
+
+	flags := node.FlagSet{
+		"message": "not hex",
+	}
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    flags,
+	}
+
+	err := a.Execute(ctx)
+	require.Regexp(t, "^failed to decode message:", err.Error())
+}
+
+func TestSignAction_signFail(t *testing.T) {
+	a := signAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{
+		signErr: fake.GetError(),
+	})
+
+	flags := node.FlagSet{
+		"message": "aef123",
+	}
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    flags,
+	}
+
+	err := a.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to sign"))
+}
+
+func TestSignAction_OK(t *testing.T) {
+	a := signAction{}
+
+	inj := node.NewInjector()
+	inj.Inject(fakeActor{signature: []byte{0xaa, 0xbb}})
+
+	flags := node.FlagSet{
+		"message": "aef123",
+	}
+
+	out := &bytes.Buffer{}
+
+	ctx := node.Context{
+		Injector: inj,
+		Flags:    flags,
+		Out:      out,
+	}
+
+	err := a.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "aabb", out.String())
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
+// keyActor is an actor that returns the last encrypted message when
+// decrypting.
+type keyActor struct {
+	dkg.Actor
+
+	message []byte
+}
+
+func (a *keyActor) Encrypt(message []byte) (kyber.Point, []kyber.Point, error) {
+	a.message = message
+
+	return suite.Point(), []kyber.Point{suite.Point()}, nil
+}
+
+func (a *keyActor) Decrypt(kyber.Point, []kyber.Point) ([]byte, error) {
+	return a.message, nil
+}
+
 type fakeActor struct {
 	dkg.Actor
 
@@ -1515,6 +2029,8 @@ type fakeActor struct {
 	vencryptErr  error
 	vdecryptErr  error
 	reshareErr   error
+	beaconErr    error
+	signErr      error
 
 	k  kyber.Point
 	cs []kyber.Point
@@ -1523,6 +2039,33 @@ type fakeActor struct {
 	xhatenc      kyber.Point
 	ct           types.Ciphertext
 	vdecryptData [][]byte
+	shares       []types.BeaconShare
+	signature    []byte
+}
+
+// fakeTranscriptActor is a fake actor that exports the transcript of the
+// setup.
+type fakeTranscriptActor struct {
+	fakeActor
+
+	transcript types.Transcript
+	err        error
+}
+
+func (f fakeTranscriptActor) SetupWithTranscript(co crypto.CollectiveAuthority,
+	threshold int) (types.Transcript, error) {
+
+	return f.transcript, f.err
+}
+
+// makeTranscript returns the valid transcript of a setup with a single node,
+// which needs no response.
+func makeTranscript() types.Transcript {
+	secret := suite.Scalar().Pick(suite.RandomStream())
+	commitment := suite.Point().Mul(secret, nil)
+
+	return types.NewTranscript(1, []kyber.Point{suite.Point().Pick(suite.RandomStream())},
+		nil, nil, nil, [][]kyber.Point{{commitment}}, []int{0}, commitment)
 }
 
 func (f fakeActor) Setup(co crypto.CollectiveAuthority, threshold int) (
@@ -1560,6 +2103,14 @@ func (f fakeActor) Reshare(co crypto.CollectiveAuthority, newThreshold int) erro
 	return f.reshareErr
 }
 
+func (f fakeActor) Beacon(input []byte) ([]types.BeaconShare, error) {
+	return f.shares, f.beaconErr
+}
+
+func (f fakeActor) Sign(message []byte) ([]byte, error) {
+	return f.signature, f.signErr
+}
+
 type fakeDKG struct {
 	dkg.DKG
 
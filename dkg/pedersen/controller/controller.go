@@ -1,14 +1,39 @@
 package controller
 
 import (
+	"time"
+
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/dkg"
 	"go.dedis.ch/dela/dkg/pedersen"
 	"go.dedis.ch/dela/mino"
 	"golang.org/x/xerrors"
 )
 
+// passphrasePrefix is the prefix of the flags of the passphrase that encrypts
+// the DKG in the database.
+const passphrasePrefix = "dkg-"
+
+const (
+	// followFlag is the start flag to reshare the DKG to the roster of the
+	// ordering service every time it changes.
+	followFlag = "dkg-follow-roster"
+
+	// policyFlag is the start flag of the threshold of the committee when
+	// following the roster.
+	policyFlag = "dkg-threshold-policy"
+
+	// phaseTimeoutFlag is the start flag of the time a node waits for each
+	// phase of a setup.
+	phaseTimeoutFlag = "dkg-phase-timeout"
+)
+
 // NewMinimal returns a new minimal initializer
 func NewMinimal() node.Initializer {
 	return minimal{
@@ -26,8 +51,33 @@ type minimal struct {
 
 // Build implements node.Initializer. In this case we don't need any command.
 func (m minimal) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(append(loader.PassphraseFlags(passphrasePrefix),
+		cli.BoolFlag{
+			Name: followFlag,
+			Usage: "reshare the default DKG to the new roster of the ordering " +
+				"service every time it changes, keeping the same public key",
+		},
+		cli.StringFlag{
+			Name: policyFlag,
+			Usage: "the threshold of the committee when following the roster, " +
+				"one of byzantine, majority or all",
+			Value: "byzantine",
+		},
+		cli.DurationFlag{
+			Name: phaseTimeoutFlag,
+			Usage: "the time a node waits for the deals, and then for the " +
+				"responses of a setup, before it excludes the missing dealers",
+			Value: 10 * time.Minute,
+		},
+	)...)
+
 	cmd := builder.SetCommand("dkg")
 	cmd.SetDescription("DKG service administration")
+	cmd.SetFlags(cli.StringFlag{
+		Name: idFlag,
+		Usage: "the name of the DKG instance, which has its own participants, " +
+			"threshold and key. The default instance is used if not set",
+	})
 
 	sub := cmd.SetSubCommand("listen")
 	sub.SetDescription("initialize DKG, create the actor and save the authority configuration")
@@ -44,26 +94,60 @@ func (m minimal) SetCommands(builder node.Builder) {
 			Name:  "threshold",
 			Usage: "the threshold of the committee",
 		},
+		cli.StringFlag{
+			Name: "transcript",
+			Usage: "the file where the node writes the transcript of the setup, " +
+				"which lists the faulty dealers and can be verified offline",
+		},
 	)
 	sub.SetAction(builder.MakeAction(setupAction{}))
 
+	sub = cmd.SetSubCommand("verifyTranscript")
+	sub.SetDescription("verify a transcript of the setup and outputs the excluded dealers")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "transcript",
+			Usage:    "the file of the transcript, read by the node",
+			Required: true,
+		},
+	)
+	sub.SetAction(builder.MakeAction(verifyTranscriptAction{}))
+
 	sub = cmd.SetSubCommand("encrypt")
-	sub.SetDescription("encrypt a message and outputs <hex(K)>:<hex(C1):<hex(C2):...>")
+	sub.SetDescription("encrypt a message and outputs <hex(K)>:<hex(C1):<hex(C2):...>, " +
+		"or encrypt a file of any size with a symmetric key when --in is given")
 	sub.SetFlags(
 		cli.StringFlag{
 			Name:  "message",
 			Usage: "the message to encrypt, encoded in hex",
 		},
+		cli.StringFlag{
+			Name:  "in",
+			Usage: "the file to encrypt, read by the node",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "the file where the node writes the encrypted file, or the output if empty",
+		},
 	)
 	sub.SetAction(builder.MakeAction(encryptAction{}))
 
 	sub = cmd.SetSubCommand("decrypt")
-	sub.SetDescription("decrypt a message and outputs the decrypted message")
+	sub.SetDescription("decrypt a message and outputs the decrypted message, " +
+		"or decrypt a file encrypted by encrypt --in when --in is given")
 	sub.SetFlags(
 		cli.StringFlag{
 			Name:  "encrypted",
 			Usage: "the encrypted string, as <hex(K)>:<hex(C1):<hex(C2):...>",
 		},
+		cli.StringFlag{
+			Name:  "in",
+			Usage: "the file to decrypt, read by the node",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "the file where the node writes the decrypted file, or the output if empty",
+		},
 	)
 	sub.SetAction(builder.MakeAction(decryptAction{}))
 
@@ -125,17 +209,84 @@ func (m minimal) SetCommands(builder node.Builder) {
 		},
 	)
 	sub.SetAction(builder.MakeAction(reshareAction{}))
+
+	sub = cmd.SetSubCommand("beacon")
+	sub.SetDescription("compute a round of the random beacon and outputs its " +
+		"shares, as <I>:<hex(V)>:<hex(X)>:<hex(E)>:<hex(F)>,...")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:  "round",
+			Usage: "the index of the round",
+		},
+		cli.StringFlag{
+			Name:  "previous",
+			Usage: "the randomness of the previous round, encoded in hex",
+		},
+	)
+	sub.SetAction(builder.MakeAction(beaconAction{}))
+
+	sub = cmd.SetSubCommand("sign")
+	sub.SetDescription("sign a message with the DKG key and outputs the " +
+		"EdDSA signature, encoded in hex")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  "message",
+			Usage: "the message to sign, encoded in hex",
+		},
+	)
+	sub.SetAction(builder.MakeAction(signAction{}))
 }
 
 // OnStart implements node.Initializer. It creates and registers a pedersen DKG.
-func (m minimal) OnStart(ctx cli.Flags, inj node.Injector) error {
+// The private key and the result of the DKG are stored in the database, and
+// the actor is created right away when a previous DKG is restored.
+func (m minimal) OnStart(flags cli.Flags, inj node.Injector) error {
 	var no mino.Mino
 	err := inj.Resolve(&no)
 	if err != nil {
 		return xerrors.Errorf("failed to resolve mino: %v", err)
 	}
 
-	dkg, pubkey := pedersen.NewPedersen(no)
+	var db kv.DB
+	err = inj.Resolve(&db)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve db: %v", err)
+	}
+
+	var passphrase []byte
+
+	source, err := loader.PassphraseFromFlags(flags, passphrasePrefix)
+	if err != nil {
+		return xerrors.Errorf("passphrase: %v", err)
+	}
+
+	if source != nil {
+		passphrase, err = source()
+		if err != nil {
+			return xerrors.Errorf("failed to get passphrase: %v", err)
+		}
+	}
+
+	// The shares of the decryptions are only released to the requests with a
+	// valid proof when another component provides an authorizer. The secret
+	// controller refuses to start after the DKG so that the authorizer can't
+	// be missed.
+	var opts []pedersen.Option
+
+	if flags.Duration(phaseTimeoutFlag) > 0 {
+		opts = append(opts, pedersen.WithPhaseTimeout(flags.Duration(phaseTimeoutFlag)))
+	}
+
+	var authorizer pedersen.Authorizer
+	err = inj.Resolve(&authorizer)
+	if err == nil {
+		opts = append(opts, pedersen.WithAuthorizer(authorizer))
+	}
+
+	dkg, pubkey, err := pedersen.NewPersistentPedersen(no, db, passphrase, opts...)
+	if err != nil {
+		return xerrors.Errorf("failed to create dkg: %v", err)
+	}
 
 	inj.Inject(dkg)
 
@@ -151,10 +302,104 @@ func (m minimal) OnStart(ctx cli.Flags, inj node.Injector) error {
 	// the listen action is expecting the pubkey to be set
 	m.la.pubkey = pubkey
 
+	restored, err := dkg.HasResult()
+	if err != nil {
+		return xerrors.Errorf("failed to check result: %v", err)
+	}
+
+	// A node following the roster listens right away so that it can join the
+	// committee when it is added to the roster.
+	if restored || flags.Bool(followFlag) {
+		actor, err := dkg.Listen()
+		if err != nil {
+			return xerrors.Errorf("failed to listen: %v", err)
+		}
+
+		inj.Inject(actor)
+
+		if restored {
+			dela.Logger.Info().Msg("restored the result of the previous DKG")
+		}
+
+		if flags.Bool(followFlag) {
+			err = follow(flags, inj, no.GetAddress(), actor)
+			if err != nil {
+				return xerrors.Errorf("failed to follow roster: %v", err)
+			}
+		}
+	}
+
+	insts := newPersistentInstances(no, db, passphrase, opts...)
+
+	ids, err := pedersen.StoredIDs(db)
+	if err != nil {
+		return xerrors.Errorf("failed to list instances: %v", err)
+	}
+
+	for _, id := range ids {
+		_, _, err = insts.listen(id)
+		if err != nil {
+			return xerrors.Errorf("failed to restore instance '%s': %v", id, err)
+		}
+
+		dela.Logger.Info().Str("id", id).Msg("restored the result of the previous DKG")
+	}
+
+	inj.Inject(insts)
+
 	return nil
 }
 
-// OnStop implements node.Initializer.
-func (minimal) OnStop(node.Injector) error {
+// OnStop implements node.Initializer. It stops to follow the roster.
+func (minimal) OnStop(inj node.Injector) error {
+	var follower *pedersen.Follower
+
+	err := inj.Resolve(&follower)
+	if err == nil {
+		follower.Stop()
+	}
+
 	return nil
 }
+
+// follow starts to reshare the DKG of the actor to the roster of the ordering
+// service.
+func follow(flags cli.Flags, inj node.Injector, me mino.Address, actor dkg.Actor) error {
+	policy, err := thresholdPolicy(flags.String(policyFlag))
+	if err != nil {
+		return err
+	}
+
+	var roster pedersen.RosterService
+
+	err = inj.Resolve(&roster)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve ordering: %v", err)
+	}
+
+	pactor, ok := actor.(*pedersen.Actor)
+	if !ok {
+		return xerrors.Errorf("invalid actor '%T'", actor)
+	}
+
+	follower := pedersen.NewFollower(pactor, me, roster, pedersen.WithThresholdPolicy(policy))
+	follower.Start()
+
+	inj.Inject(follower)
+
+	return nil
+}
+
+// thresholdPolicy returns the threshold function of the policy.
+func thresholdPolicy(name string) (cosi.Threshold, error) {
+	switch name {
+	case "", "byzantine":
+		return threshold.ByzantineThreshold, nil
+	case "majority":
+		return func(n int) int { return n/2 + 1 }, nil
+	case "all":
+		return func(n int) int { return n }, nil
+	default:
+		return nil, xerrors.Errorf("unknown threshold policy '%s'", name)
+	}
+}
@@ -1,13 +1,19 @@
 package controller
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/dkg/pedersen"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
 	"golang.org/x/xerrors"
 )
 
@@ -22,14 +28,146 @@ func TestMinimal_OnStart(t *testing.T) {
 	minimal := NewMinimal()
 
 	inj := newInjector(fake.Mino{})
-	err := minimal.OnStart(nil, inj)
+	err := minimal.OnStart(node.FlagSet{}, inj)
 	require.NoError(t, err)
 
-	require.Len(t, inj.(*fakeInjector).history, 1)
+	require.Len(t, inj.(*fakeInjector).history, 2)
 	require.IsType(t, &pedersen.Pedersen{}, inj.(*fakeInjector).history[0])
+	require.IsType(t, &instances{}, inj.(*fakeInjector).history[1])
 
-	err = minimal.OnStart(nil, newBadInjector())
+	err = minimal.OnStart(node.FlagSet{}, newBadInjector())
 	require.EqualError(t, err, fake.Err("failed to resolve mino"))
+
+	inj.(*fakeInjector).db = nil
+	err = minimal.OnStart(node.FlagSet{}, inj)
+	require.EqualError(t, err, fake.Err("failed to resolve db"))
+}
+
+func TestMinimal_OnStartAuthorizer(t *testing.T) {
+	minimal := NewMinimal()
+
+	inj := newInjector(fake.Mino{})
+	inj.(*fakeInjector).authorizer = fakeAuthorizer{}
+
+	err := minimal.OnStart(node.FlagSet{}, inj)
+	require.NoError(t, err)
+	require.True(t, inj.(*fakeInjector).authorizerResolved)
+	require.Len(t, inj.(*fakeInjector).history, 2)
+}
+
+func TestMinimal_OnStartPhaseTimeout(t *testing.T) {
+	minimal := NewMinimal()
+
+	inj := newInjector(fake.Mino{})
+
+	flags := node.FlagSet{phaseTimeoutFlag: float64(time.Minute)}
+
+	err := minimal.OnStart(flags, inj)
+	require.NoError(t, err)
+	require.Len(t, inj.(*fakeInjector).history, 2)
+}
+
+func TestMinimal_OnStartPassphrase(t *testing.T) {
+	m := NewMinimal().(minimal)
+
+	t.Setenv("DKG_PASSPHRASE", "abc")
+
+	flags := node.FlagSet{"dkg-passphrase-env": "DKG_PASSPHRASE"}
+
+	inj := newInjector(fake.Mino{})
+	err := m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	pubkey := m.la.pubkey
+
+	// The key is the same after a restart.
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+	require.True(t, pubkey.Equal(m.la.pubkey))
+
+	err = m.OnStart(node.FlagSet{}, inj)
+	require.EqualError(t, err, "failed to create dkg: failed to load key: "+
+		"failed to read key: value is encrypted but no passphrase is given")
+
+	flags["dkg-passphrase-env"] = "DKG_UNKNOWN"
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err, "failed to get passphrase: "+
+		"environment variable 'DKG_UNKNOWN' is empty")
+
+	flags["dkg-passphrase-file"] = "/fake/file"
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err, "passphrase: only one of --dkg-passphrase-env, "+
+		"--dkg-passphrase-file and --dkg-passphrase-prompt is allowed")
+}
+
+func TestMinimal_OnStartRestoreFail(t *testing.T) {
+	minimal := NewMinimal()
+
+	db := fake.NewInMemoryDB()
+
+	err := db.Update(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate([]byte("dkg"))
+		require.NoError(t, err)
+
+		return bucket.Set([]byte("result/abc"), []byte("{"))
+	})
+	require.NoError(t, err)
+
+	inj := newInjector(fake.Mino{})
+	inj.(*fakeInjector).db = db
+
+	err = minimal.OnStart(node.FlagSet{}, inj)
+	require.EqualError(t, err, "failed to restore instance 'abc': failed to listen: "+
+		"failed to restore: failed to load: failed to unmarshal result: "+
+		"unexpected end of JSON input")
+}
+
+func TestMinimal_OnStartFollow(t *testing.T) {
+	minimal := NewMinimal()
+
+	flags := node.FlagSet{followFlag: true, policyFlag: "majority"}
+
+	inj := newInjector(fake.Mino{})
+	inj.(*fakeInjector).roster = fakeRoster{}
+
+	err := minimal.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	history := inj.(*fakeInjector).history
+	require.Len(t, history, 4)
+	require.IsType(t, &pedersen.Actor{}, history[1])
+	require.IsType(t, &pedersen.Follower{}, history[2])
+
+	inj.(*fakeInjector).follower = history[2].(*pedersen.Follower)
+
+	err = minimal.OnStop(inj)
+	require.NoError(t, err)
+
+	flags[policyFlag] = "abc"
+	err = minimal.OnStart(flags, inj)
+	require.EqualError(t, err, "failed to follow roster: unknown threshold policy 'abc'")
+
+	flags[policyFlag] = ""
+	inj.(*fakeInjector).roster = nil
+	err = minimal.OnStart(flags, inj)
+	require.EqualError(t, err, fake.Err("failed to follow roster: failed to resolve ordering"))
+}
+
+func TestThresholdPolicy(t *testing.T) {
+	policy, err := thresholdPolicy("byzantine")
+	require.NoError(t, err)
+	require.Equal(t, 5, policy(7))
+
+	policy, err = thresholdPolicy("majority")
+	require.NoError(t, err)
+	require.Equal(t, 4, policy(7))
+
+	policy, err = thresholdPolicy("all")
+	require.NoError(t, err)
+	require.Equal(t, 7, policy(7))
+
+	_, err = thresholdPolicy("abc")
+	require.EqualError(t, err, "unknown threshold policy 'abc'")
 }
 
 func TestMinimal_OnStop(t *testing.T) {
@@ -45,6 +183,7 @@ func TestMinimal_OnStop(t *testing.T) {
 func newInjector(mino mino.Mino) node.Injector {
 	return &fakeInjector{
 		mino: mino,
+		db:   fake.NewInMemoryDB(),
 	}
 }
 
@@ -58,13 +197,19 @@ func newBadInjector() node.Injector {
 //
 // - implements node.Injector
 type fakeInjector struct {
-	isBad   bool
-	mino    mino.Mino
-	history []interface{}
+	isBad      bool
+	mino       mino.Mino
+	db         kv.DB
+	authorizer pedersen.Authorizer
+	roster     pedersen.RosterService
+	follower   *pedersen.Follower
+	history    []interface{}
+
+	authorizerResolved bool
 }
 
 // Resolve implements node.Injector
-func (i fakeInjector) Resolve(el interface{}) error {
+func (i *fakeInjector) Resolve(el interface{}) error {
 	if i.isBad {
 		return fake.GetError()
 	}
@@ -75,6 +220,27 @@ func (i fakeInjector) Resolve(el interface{}) error {
 			return fake.GetError()
 		}
 		*msg = i.mino
+	case *kv.DB:
+		if i.db == nil {
+			return fake.GetError()
+		}
+		*msg = i.db
+	case *pedersen.Authorizer:
+		if i.authorizer == nil {
+			return fake.GetError()
+		}
+		*msg = i.authorizer
+		i.authorizerResolved = true
+	case *pedersen.RosterService:
+		if i.roster == nil {
+			return fake.GetError()
+		}
+		*msg = i.roster
+	case **pedersen.Follower:
+		if i.follower == nil {
+			return fake.GetError()
+		}
+		*msg = i.follower
 	default:
 		return xerrors.Errorf("unkown message '%T", msg)
 	}
@@ -89,3 +255,26 @@ func (i *fakeInjector) Inject(v interface{}) {
 	}
 	i.history = append(i.history, v)
 }
+
+type fakeAuthorizer struct{}
+
+func (fakeAuthorizer) Authorize([]byte, kyber.Point, kyber.Point) error {
+	return nil
+}
+
+type fakeRoster struct{}
+
+func (fakeRoster) Watch(ctx context.Context) <-chan ordering.Event {
+	ch := make(chan ordering.Event)
+
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (fakeRoster) GetRoster() (authority.Authority, error) {
+	return authority.New(nil, nil), nil
+}
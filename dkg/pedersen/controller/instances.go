@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"regexp"
+	"sync"
+
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/dkg/pedersen"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/xerrors"
+)
+
+// idFlag is the name of the flag that selects a named DKG instance.
+const idFlag = "id"
+
+// idRegexp defines the characters allowed in the name of an instance, which is
+// used for the RPC and the authority file.
+var idRegexp = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+// dkgFn is the function that creates the DKG of a named instance.
+type dkgFn func(id string) (dkg.DKG, kyber.Point, error)
+
+// namedDKG is a named DKG instance and the public key of the node for it.
+type namedDKG struct {
+	dkg    dkg.DKG
+	pubkey kyber.Point
+}
+
+// instances holds the named DKG instances of a node. The default instance is
+// injected as it is and is not part of it.
+type instances struct {
+	sync.Mutex
+
+	newDKG dkgFn
+	dkgs   map[string]namedDKG
+	actors map[string]dkg.Actor
+}
+
+func newInstances(fn dkgFn) *instances {
+	return &instances{
+		newDKG: fn,
+		dkgs:   make(map[string]namedDKG),
+		actors: make(map[string]dkg.Actor),
+	}
+}
+
+// newPersistentInstances returns the instances of a node that store their
+// values in the database, with the same passphrase and options as the default
+// instance.
+func newPersistentInstances(m mino.Mino, db kv.DB, passphrase []byte,
+	opts ...pedersen.Option) *instances {
+
+	return newInstances(func(id string) (dkg.DKG, kyber.Point, error) {
+		named := append([]pedersen.Option{pedersen.WithID(id)}, opts...)
+
+		return pedersen.NewPersistentPedersen(m, db, passphrase, named...)
+	})
+}
+
+// getDKG returns the DKG of the instance, or creates it if it doesn't exist
+// yet.
+func (i *instances) getDKG(id string) (dkg.DKG, kyber.Point, error) {
+	if !idRegexp.MatchString(id) {
+		return nil, nil, xerrors.Errorf("invalid id '%s'", id)
+	}
+
+	i.Lock()
+	defer i.Unlock()
+
+	named, found := i.dkgs[id]
+	if found {
+		return named.dkg, named.pubkey, nil
+	}
+
+	d, pubkey, err := i.newDKG(id)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to create instance '%s': %v", id, err)
+	}
+
+	i.dkgs[id] = namedDKG{dkg: d, pubkey: pubkey}
+
+	return d, pubkey, nil
+}
+
+// getActor returns the actor of the instance if it listens.
+func (i *instances) getActor(id string) (dkg.Actor, bool) {
+	i.Lock()
+	defer i.Unlock()
+
+	actor, found := i.actors[id]
+
+	return actor, found
+}
+
+// listen returns the actor of the instance, and creates it the first time.
+func (i *instances) listen(id string) (dkg.Actor, kyber.Point, error) {
+	d, pubkey, err := i.getDKG(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i.Lock()
+	defer i.Unlock()
+
+	actor, found := i.actors[id]
+	if found {
+		return actor, pubkey, nil
+	}
+
+	actor, err = d.Listen()
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to listen: %v", err)
+	}
+
+	i.actors[id] = actor
+
+	return actor, pubkey, nil
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestInstances_GetDKG(t *testing.T) {
+	calls := 0
+
+	insts := newInstances(func(id string) (dkg.DKG, kyber.Point, error) {
+		calls++
+		return fakeDKG{}, suite.Point(), nil
+	})
+
+	_, _, err := insts.getDKG("")
+	require.EqualError(t, err, "invalid id ''")
+
+	_, _, err = insts.getDKG("../abc")
+	require.EqualError(t, err, "invalid id '../abc'")
+
+	_, pubkey, err := insts.getDKG("abc")
+	require.NoError(t, err)
+	require.NotNil(t, pubkey)
+
+	// The instance is created only once.
+	_, _, err = insts.getDKG("abc")
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	insts.newDKG = func(id string) (dkg.DKG, kyber.Point, error) {
+		return nil, nil, fake.GetError()
+	}
+
+	_, _, err = insts.getDKG("def")
+	require.EqualError(t, err, fake.Err("failed to create instance 'def'"))
+}
+
+func TestInstances_Listen(t *testing.T) {
+	insts := newInstances(func(id string) (dkg.DKG, kyber.Point, error) {
+		return fakeDKG{actor: fakeActor{}}, suite.Point(), nil
+	})
+
+	_, found := insts.getActor("abc")
+	require.False(t, found)
+
+	_, _, err := insts.listen("")
+	require.EqualError(t, err, "invalid id ''")
+
+	actor, _, err := insts.listen("abc")
+	require.NoError(t, err)
+	require.Equal(t, fakeActor{}, actor)
+
+	res, found := insts.getActor("abc")
+	require.True(t, found)
+	require.Equal(t, actor, res)
+
+	// The actor is created only once, even if the DKG fails to listen again.
+	insts.dkgs["abc"] = namedDKG{dkg: fakeDKG{err: fake.GetError()}, pubkey: suite.Point()}
+
+	_, _, err = insts.listen("abc")
+	require.NoError(t, err)
+
+	insts.dkgs["def"] = namedDKG{dkg: fakeDKG{err: fake.GetError()}, pubkey: suite.Point()}
+
+	_, _, err = insts.listen("def")
+	require.EqualError(t, err, fake.Err("failed to listen"))
+}
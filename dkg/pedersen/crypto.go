@@ -1,9 +1,12 @@
 package pedersen
 
 import (
+	"crypto/cipher"
 	"crypto/sha256"
+
 	"go.dedis.ch/dela/dkg/pedersen/types"
 	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
 	"golang.org/x/xerrors"
 )
 
@@ -68,7 +71,9 @@ func checkEncryptionProof(cp types.Ciphertext) error {
 // decryption proof.
 //
 // See https://arxiv.org/pdf/2205.08529.pdf / section 5.4 Protocol / step 3
-func verifiableDecryption(ct types.Ciphertext, V kyber.Scalar, I int) (*types.ShareAndProof, error) {
+func verifiableDecryption(ct types.Ciphertext, V kyber.Scalar, I int,
+	random cipher.Stream) (*types.ShareAndProof, error) {
+
 	err := checkEncryptionProof(ct)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to check proof: %v", err)
@@ -79,7 +84,7 @@ func verifiableDecryption(ct types.Ciphertext, V kyber.Scalar, I int) (*types.Sh
 	// share of this party, needed for decrypting
 	partial := suite.Point().Sub(ct.C, ui)
 
-	si := suite.Scalar().Pick(suite.RandomStream())
+	si := suite.Scalar().Pick(random)
 	UHat := suite.Point().Mul(si, ct.K)
 	HHat := suite.Point().Mul(si, nil)
 
@@ -103,3 +108,162 @@ func verifiableDecryption(ct types.Ciphertext, V kyber.Scalar, I int) (*types.Sh
 
 	return &sp, nil
 }
+
+// checkDecryptReply verifies the proof of the partial decryption of C against
+// the public share of the participant, and returns its share of the secret.
+func checkDecryptReply(poly *share.PubPoly, K, C kyber.Point,
+	reply types.DecryptReply) (kyber.Point, error) {
+
+	err := checkIndex(poly, reply.I, reply.V)
+	if err != nil {
+		return nil, err
+	}
+
+	S := suite.Point().Sub(C, reply.V)
+
+	err = checkDLEQ(K, S, poly.Eval(int(reply.I)).V, reply.E, reply.F)
+	if err != nil {
+		return nil, xerrors.Errorf("share %d: %v", reply.I, err)
+	}
+
+	return S, nil
+}
+
+// checkReencryptReply verifies the proof of the reencrypted share against the
+// public share of the participant.
+func checkReencryptReply(poly *share.PubPoly, K, pubk kyber.Point,
+	reply types.ReencryptReply) error {
+
+	if reply.Ui == nil {
+		return xerrors.New("empty reply")
+	}
+
+	err := checkIndex(poly, int64(reply.Ui.I), reply.Ui.V)
+	if err != nil {
+		return err
+	}
+
+	if reply.Ei == nil || reply.Fi == nil {
+		return xerrors.Errorf("share %d: missing proof", reply.Ui.I)
+	}
+
+	base := suite.Point().Add(K, pubk)
+	Xi := poly.Eval(reply.Ui.I).V
+
+	uiHat := suite.Point().Sub(suite.Point().Mul(reply.Fi, base),
+		suite.Point().Mul(reply.Ei, reply.Ui.V))
+	hiHat := suite.Point().Sub(suite.Point().Mul(reply.Fi, nil),
+		suite.Point().Mul(reply.Ei, Xi))
+
+	hash := sha256.New()
+	reply.Ui.V.MarshalTo(hash)
+	uiHat.MarshalTo(hash)
+	hiHat.MarshalTo(hash)
+	e := suite.Scalar().SetBytes(hash.Sum(nil))
+
+	if !e.Equal(reply.Ei) {
+		return xerrors.Errorf("share %d: hash is not valid: %x != %x", reply.Ui.I, reply.Ei, e)
+	}
+
+	return nil
+}
+
+// checkVerifiableDecryptReply verifies the proofs of the shares of every
+// ciphertext, and that the shares use the public share of the participant.
+func checkVerifiableDecryptReply(poly *share.PubPoly, ciphertexts []types.Ciphertext,
+	reply types.VerifiableDecryptReply) error {
+
+	sps := reply.GetShareAndProof()
+	if len(sps) != len(ciphertexts) {
+		return xerrors.Errorf("expected %d shares, got %d", len(ciphertexts), len(sps))
+	}
+
+	for i, sp := range sps {
+		err := checkIndex(poly, sp.I, sp.V)
+		if err != nil {
+			return err
+		}
+
+		if sp.I != sps[0].I {
+			return xerrors.Errorf("mixed shares %d and %d", sps[0].I, sp.I)
+		}
+
+		if sp.Ui == nil || sp.Hi == nil || sp.Ei == nil || sp.Fi == nil {
+			return xerrors.Errorf("share %d: missing proof", sp.I)
+		}
+
+		if !poly.Eval(int(sp.I)).V.Equal(sp.Hi) {
+			return xerrors.Errorf("unexpected public share for %d", sp.I)
+		}
+
+		if !suite.Point().Sub(ciphertexts[i].C, sp.Ui).Equal(sp.V) {
+			return xerrors.Errorf("share %d: partial doesn't match the proof", sp.I)
+		}
+
+		err = checkDecryptionProof(sp, ciphertexts[i].K)
+		if err != nil {
+			return xerrors.Errorf("share %d: %v", sp.I, err)
+		}
+	}
+
+	return nil
+}
+
+// checkIndex verifies that the share has a value and an index for which the
+// public share is known.
+func checkIndex(poly *share.PubPoly, i int64, v kyber.Point) error {
+	if poly == nil {
+		return xerrors.New("missing public polynomial")
+	}
+
+	if i < 0 || v == nil {
+		return xerrors.Errorf("malformed share %d", i)
+	}
+
+	return nil
+}
+
+// proveDLEQ returns the value of the secret for the base and the generator, and
+// a proof (E, F) that both use the same secret without revealing it.
+func proveDLEQ(base kyber.Point, secret kyber.Scalar,
+	random cipher.Stream) (V, X kyber.Point, E, F kyber.Scalar) {
+
+	V = suite.Point().Mul(secret, base)
+	X = suite.Point().Mul(secret, nil)
+
+	r := suite.Scalar().Pick(random)
+	A := suite.Point().Mul(r, nil)
+	B := suite.Point().Mul(r, base)
+
+	E = dleqChallenge(base, V, X, A, B)
+	F = suite.Scalar().Add(r, suite.Scalar().Mul(E, secret))
+
+	return V, X, E, F
+}
+
+// checkDLEQ verifies the proof that V, for the base, and X, for the generator,
+// use the same secret.
+func checkDLEQ(base, V, X kyber.Point, E, F kyber.Scalar) error {
+	if E == nil || F == nil {
+		return xerrors.New("missing proof")
+	}
+
+	A := suite.Point().Sub(suite.Point().Mul(F, nil), suite.Point().Mul(E, X))
+	B := suite.Point().Sub(suite.Point().Mul(F, base), suite.Point().Mul(E, V))
+
+	challenge := dleqChallenge(base, V, X, A, B)
+	if !challenge.Equal(E) {
+		return xerrors.Errorf("invalid proof: %x != %x", E, challenge)
+	}
+
+	return nil
+}
+
+func dleqChallenge(points ...kyber.Point) kyber.Scalar {
+	hash := sha256.New()
+	for _, point := range points {
+		point.MarshalTo(hash)
+	}
+
+	return suite.Scalar().SetBytes(hash.Sum(nil))
+}
@@ -0,0 +1,181 @@
+package pedersen
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+func TestDLEQ_Scenario(t *testing.T) {
+	base := suite.Point().Pick(suite.RandomStream())
+	secret := suite.Scalar().Pick(suite.RandomStream())
+
+	V, X, E, F := proveDLEQ(base, secret, suite.RandomStream())
+	require.True(t, V.Equal(suite.Point().Mul(secret, base)))
+	require.True(t, X.Equal(suite.Point().Mul(secret, nil)))
+
+	err := checkDLEQ(base, V, X, E, F)
+	require.NoError(t, err)
+
+	err = checkDLEQ(base, V, X, nil, F)
+	require.EqualError(t, err, "missing proof")
+
+	err = checkDLEQ(base, X, V, E, F)
+	require.Error(t, err)
+	require.Regexp(t, "^invalid proof: ", err.Error())
+}
+
+func TestCheckDecryptReply(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	poly := priPoly.Commit(nil)
+	priShare := priPoly.Shares(2)[1]
+
+	K := suite.Point().Pick(suite.RandomStream())
+	C := suite.Point().Pick(suite.RandomStream())
+
+	reply := makeDecryptReply(K, C, priShare)
+
+	S, err := checkDecryptReply(poly, K, C, reply)
+	require.NoError(t, err)
+	require.True(t, S.Equal(suite.Point().Mul(priShare.V, K)))
+
+	_, err = checkDecryptReply(nil, K, C, reply)
+	require.EqualError(t, err, "missing public polynomial")
+
+	_, err = checkDecryptReply(poly, K, C, types.DecryptReply{I: -1, V: suite.Point()})
+	require.EqualError(t, err, "malformed share -1")
+
+	_, err = checkDecryptReply(poly, K, C, types.DecryptReply{I: 1})
+	require.EqualError(t, err, "malformed share 1")
+
+	invalid := reply
+	invalid.I = 0
+
+	_, err = checkDecryptReply(poly, K, C, invalid)
+	require.Error(t, err)
+	require.Regexp(t, "^share 0: invalid proof: ", err.Error())
+}
+
+func TestCheckReencryptReply(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	poly := priPoly.Commit(nil)
+	priShare := priPoly.Shares(2)[1]
+
+	K := suite.Point().Pick(suite.RandomStream())
+	pubk := suite.Point().Pick(suite.RandomStream())
+
+	reply := makeReencryptReply(K, pubk, priShare)
+
+	err := checkReencryptReply(poly, K, pubk, reply)
+	require.NoError(t, err)
+
+	err = checkReencryptReply(poly, K, pubk, types.ReencryptReply{})
+	require.EqualError(t, err, "empty reply")
+
+	err = checkReencryptReply(nil, K, pubk, reply)
+	require.EqualError(t, err, "missing public polynomial")
+
+	err = checkReencryptReply(poly, K, pubk, types.ReencryptReply{
+		Ui: &share.PubShare{I: 1, V: suite.Point()},
+	})
+	require.EqualError(t, err, "share 1: missing proof")
+
+	err = checkReencryptReply(poly, K, suite.Point(), reply)
+	require.Error(t, err)
+	require.Regexp(t, "^share 1: hash is not valid: ", err.Error())
+}
+
+func TestCheckVerifiableDecryptReply(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	poly := priPoly.Commit(nil)
+	priShares := priPoly.Shares(2)
+
+	a := Actor{
+		startRes: &state{dkgState: certified, distrKey: poly.Commit()},
+	}
+
+	GBar := suite.Point().Pick(suite.RandomStream())
+
+	ct1, _, err := a.VerifiableEncrypt([]byte("abc"), GBar)
+	require.NoError(t, err)
+
+	ct2, _, err := a.VerifiableEncrypt([]byte("def"), GBar)
+	require.NoError(t, err)
+
+	ciphertexts := []types.Ciphertext{ct1, ct2}
+
+	sp1, err := verifiableDecryption(ct1, priShares[0].V, 0, suite.RandomStream())
+	require.NoError(t, err)
+
+	sp2, err := verifiableDecryption(ct2, priShares[0].V, 0, suite.RandomStream())
+	require.NoError(t, err)
+
+	check := func(sps ...types.ShareAndProof) error {
+		return checkVerifiableDecryptReply(poly, ciphertexts,
+			types.NewVerifiableDecryptReply(sps))
+	}
+
+	require.NoError(t, check(*sp1, *sp2))
+
+	err = check(*sp1)
+	require.EqualError(t, err, "expected 2 shares, got 1")
+
+	err = checkVerifiableDecryptReply(nil, ciphertexts,
+		types.NewVerifiableDecryptReply([]types.ShareAndProof{*sp1, *sp2}))
+	require.EqualError(t, err, "missing public polynomial")
+
+	other, err := verifiableDecryption(ct2, priShares[1].V, 1, suite.RandomStream())
+	require.NoError(t, err)
+
+	err = check(*sp1, *other)
+	require.EqualError(t, err, "mixed shares 0 and 1")
+
+	missing := *sp2
+	missing.Hi = nil
+
+	err = check(*sp1, missing)
+	require.EqualError(t, err, "share 0: missing proof")
+
+	wrongIndex := *sp1
+	wrongIndex.I = 1
+
+	err = check(wrongIndex, *sp2)
+	require.EqualError(t, err, "unexpected public share for 1")
+
+	wrongPartial := *sp2
+	wrongPartial.V = suite.Point()
+
+	err = check(*sp1, wrongPartial)
+	require.EqualError(t, err, "share 0: partial doesn't match the proof")
+
+	wrongProof := *sp2
+	wrongProof.Fi = suite.Scalar()
+
+	err = check(*sp1, wrongProof)
+	require.Error(t, err)
+	require.Regexp(t, "^share 0: hash is not valid: ", err.Error())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeReencryptReply(K, pubk kyber.Point, priShare *share.PriShare) types.ReencryptReply {
+	base := suite.Point().Add(K, pubk)
+
+	ui := &share.PubShare{I: priShare.I, V: suite.Point().Mul(priShare.V, base)}
+
+	si := suite.Scalar().Pick(suite.RandomStream())
+
+	hash := sha256.New()
+	ui.V.MarshalTo(hash)
+	suite.Point().Mul(si, base).MarshalTo(hash)
+	suite.Point().Mul(si, nil).MarshalTo(hash)
+	ei := suite.Scalar().SetBytes(hash.Sum(nil))
+	fi := suite.Scalar().Add(si, suite.Scalar().Mul(ei, priShare.V))
+
+	return types.NewReencryptReply(pubk, ui, ei, fi)
+}
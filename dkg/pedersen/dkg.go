@@ -2,13 +2,18 @@ package pedersen
 
 import (
 	"context"
+	"crypto/cipher"
 	"crypto/sha256"
+	"errors"
+	"sort"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/debugtools/channel"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/dkg/pedersen/types"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
@@ -16,6 +21,8 @@ import (
 	"go.dedis.ch/kyber/v3/share"
 	pedersen "go.dedis.ch/kyber/v3/share/dkg/pedersen"
 	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+	"go.dedis.ch/kyber/v3/suites"
 	"golang.org/x/xerrors"
 )
 
@@ -24,6 +31,12 @@ const newState = "new state"
 const badState = "bad state: %v"
 const failedState = "failed to switch state: %v"
 
+// defaultPhaseTimeout is the time a node waits for the deals, and then for the
+// responses and the justifications of the setup, unless the DKG is created
+// with another one. The dealers whose deal is not certified by then are
+// excluded.
+const defaultPhaseTimeout = 10 * time.Minute
+
 type nodeType byte
 
 // enumeration of the node type
@@ -41,6 +54,11 @@ type dkgService interface {
 	Certified() bool
 	DistKeyShare() (*pedersen.DistKeyShare, error)
 	ProcessDeal(dd *pedersen.Deal) (*pedersen.Response, error)
+	ProcessJustification(j *pedersen.Justification) error
+	SetTimeout()
+	ThresholdCertified() bool
+	QUAL() []int
+	Verifiers() map[uint32]*vss.Verifier
 }
 
 // dkgInstance specify what a stream handler needs from a service that handles
@@ -49,15 +67,17 @@ type dkgInstance interface {
 	isRunning() bool
 	handleMessage(ctx context.Context, msg serde.Message, from mino.Address, out mino.Sender) error
 	getState() *state
+	restore(store *storage) error
 }
 
 // newInstance returns a new initialized dkg handler
 func newInstance(log zerolog.Logger, me mino.Address, privKey kyber.Scalar) *instance {
 	return &instance{
-		running:   true,
-		deals:     channel.WithExpiration[types.Deal](200),
-		responses: channel.WithExpiration[types.Response](100000),
-		reshares:  channel.WithExpiration[types.Reshare](200),
+		running:        true,
+		deals:          channel.WithExpiration[types.Deal](200),
+		responses:      channel.WithExpiration[types.Response](100000),
+		reshares:       channel.WithExpiration[types.Reshare](200),
+		justifications: channel.WithExpiration[types.Justification](200),
 
 		log:     log,
 		me:      me,
@@ -77,20 +97,64 @@ type instance struct {
 
 	running bool
 
-	deals     channel.Timed[types.Deal]
-	responses channel.Timed[types.Response]
-	reshares  channel.Timed[types.Reshare]
+	deals          channel.Timed[types.Deal]
+	responses      channel.Timed[types.Response]
+	reshares       channel.Timed[types.Reshare]
+	justifications channel.Timed[types.Justification]
 
 	dkg dkgService
+
+	// journal records the messages of the setup for its transcript.
+	journal *journal
+
 	log zerolog.Logger
 	me  mino.Address
 
 	privShare *share.PriShare
 	privKey   kyber.Scalar
 
+	// nonces are the nonces committed for the signing sessions in progress,
+	// which can only be used once and are dropped when they expire.
+	nonces map[string]signNonces
+
+	// store is the storage of the result of the DKG, or nil if it is only
+	// kept in memory.
+	store *storage
+
+	// authorizer decides if the shares of a decryption can be released, or
+	// nil if every request is accepted.
+	authorizer Authorizer
+
+	// phaseTimeout is the timeout of the phases of the setup, or zero for
+	// the default one.
+	phaseTimeout time.Duration
+
+	// clock is the clock of the timeouts of the phases, or nil for the system
+	// clock.
+	clock clock.Clock
+
+	// random is the stream of the secrets and of the nonces, or nil for the
+	// system randomness.
+	random cipher.Stream
+
 	startRes *state
 }
 
+// getSuite returns the suite of the DKG, which draws its randomness from the
+// stream of the instance when it has one.
+func (s *instance) getSuite() suites.Suite {
+	return suiteWith(s.random)
+}
+
+// getPhaseTimeout returns the timeout of the phases of the setup.
+func (s *instance) getPhaseTimeout() time.Duration {
+	if s.phaseTimeout <= 0 {
+		return defaultPhaseTimeout
+	}
+
+	return s.phaseTimeout
+}
+
 // isRunning implements dkgInstance. It tells if an instance of DKG is already
 // running or not.
 func (s *instance) isRunning() bool {
@@ -105,6 +169,40 @@ func (s *instance) getState() *state {
 	return s.startRes
 }
 
+// restore implements dkgInstance. It loads the result of a previous DKG from
+// the storage, if any, and stores the next results in it.
+func (s *instance) restore(store *storage) error {
+	distKey, err := store.load(s.startRes)
+	if err != nil {
+		return xerrors.Errorf("failed to load: %v", err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.store = store
+
+	if distKey != nil {
+		s.dkg = storedDKG{distKey: distKey}
+		s.privShare = distKey.Share
+	}
+
+	return nil
+}
+
+// persist stores the result of the DKG when the instance has a storage.
+func (s *instance) persist(distKey *pedersen.DistKeyShare) error {
+	s.Lock()
+	store := s.store
+	s.Unlock()
+
+	if store == nil {
+		return nil
+	}
+
+	return store.save(s.startRes, distKey)
+}
+
 // handleMessage implements dkgInstance. It handles the DKG messages.
 func (s *instance) handleMessage(
 	ctx context.Context,
@@ -164,6 +262,14 @@ func (s *instance) handleMessage(
 
 		s.responses.Send(msg)
 
+	case types.Justification:
+		err := s.startRes.checkState(initial, sharing, certified)
+		if err != nil {
+			return xerrors.Errorf(badState, err)
+		}
+
+		s.justifications.Send(msg)
+
 	case types.DecryptRequest:
 		err := s.startRes.checkState(certified)
 		if err != nil {
@@ -188,6 +294,35 @@ func (s *instance) handleMessage(
 
 		return s.handleReencryptRequest(out, msg, from)
 
+	case types.BeaconRequest:
+		err := s.startRes.checkState(certified)
+		if err != nil {
+			return xerrors.Errorf(badState, err)
+		}
+
+		return s.handleBeacon(out, msg, from)
+
+	case types.SignCommitRequest:
+		err := s.startRes.checkState(certified)
+		if err != nil {
+			return xerrors.Errorf(badState, err)
+		}
+
+		return s.handleSignCommit(out, msg, from)
+
+	case types.SignRequest:
+		err := s.startRes.checkState(certified)
+		if err != nil {
+			return xerrors.Errorf(badState, err)
+		}
+
+		return s.handleSign(out, msg, from)
+
+	case types.PublicKeyRequest:
+		// The public key is needed before the node joins the committee, so
+		// it is given in any state.
+		return s.handlePublicKey(out, from)
+
 	default:
 		return xerrors.Errorf("expected Start message, decrypt request or "+
 			"Deal as first message, got: %T", msg)
@@ -216,13 +351,37 @@ func (s *instance) start(
 
 	// create the DKG
 	t := start.GetThreshold()
-	d, err := pedersen.NewDistKeyGenerator(suite, s.privKey, start.GetPublicKeys(), t)
+	c := &pedersen.Config{
+		Suite:     s.getSuite(),
+		Longterm:  s.privKey,
+		NewNodes:  start.GetPublicKeys(),
+		Threshold: t,
+	}
+
+	// The secret of a fresh DKG is drawn from the reader of the configuration.
+	if s.random != nil {
+		c.Reader = streamReader{stream: s.random}
+		c.UserReaderOnly = true
+	}
+
+	d, err := pedersen.NewDistKeyHandler(c)
 	if err != nil {
 		return xerrors.Errorf("failed to create new DKG: %v", err)
 	}
 
 	s.dkg = d
 
+	me := 0
+	pubkey := suite.Point().Mul(s.privKey, nil)
+
+	for i, p := range start.GetPublicKeys() {
+		if p.Equal(pubkey) {
+			me = i
+		}
+	}
+
+	s.journal = newJournal(t, start.GetPublicKeys(), me)
+
 	s.startRes.init(start.GetAddresses(), start.GetPublicKeys(), start.GetThreshold())
 
 	err = s.doDKG(ctx, deals, resps, out, from)
@@ -258,8 +417,7 @@ func (s *instance) doDKG(
 	}
 
 	s.log.Info().Str("action", "certify").Msg(newState)
-	numResps := (len(s.startRes.getParticipants()) - 1) * (len(s.startRes.getParticipants()) - 1)
-	err = s.certify(ctx, resps, numResps)
+	err = s.qualify(ctx, resps, s.justifications, out)
 	if err != nil {
 		return xerrors.Errorf("failed to certify: %v", err)
 	}
@@ -280,6 +438,19 @@ func (s *instance) doDKG(
 	return nil
 }
 
+// dealIndices returns the indices of the deals in increasing order, so that
+// the deals are always sent in the same order.
+func dealIndices(deals map[int]*pedersen.Deal) []int {
+	indices := make([]int, 0, len(deals))
+	for i := range deals {
+		indices = append(indices, i)
+	}
+
+	sort.Ints(indices)
+
+	return indices
+}
+
 func (s *instance) deal(ctx context.Context, out mino.Sender) error {
 	// Send my Deals to the other nodes. Note that we take an optimistic
 	// approach and expect nodes to always accept messages. If not, the protocol
@@ -292,7 +463,9 @@ func (s *instance) deal(ctx context.Context, out mino.Sender) error {
 
 	participants := s.startRes.getParticipants()
 
-	for i, deal := range deals {
+	for _, i := range dealIndices(deals) {
+		deal := deals[i]
+
 		dealMsg := types.NewDeal(
 			deal.Index,
 			deal.Signature,
@@ -324,6 +497,10 @@ func (s *instance) deal(ctx context.Context, out mino.Sender) error {
 	return nil
 }
 
+// respond processes the deals of the other dealers and sends the responses.
+// An invalid deal is reported and skipped, and the node stops waiting for the
+// missing deals after the phase timeout, so that a faulty dealer is excluded
+// later instead of stopping the setup.
 func (s *instance) respond(
 	ctx context.Context,
 	deals channel.Timed[types.Deal],
@@ -331,19 +508,38 @@ func (s *instance) respond(
 ) error {
 	numReceivedDeals := 0
 
+	phaseCtx, cancel := withTimeout(ctx, s.clock, s.getPhaseTimeout())
+	defer cancel()
+
 	participants := s.startRes.getParticipants()
 	for numReceivedDeals < len(participants)-1 {
-		deal, err := deals.NonBlockingReceiveWithContext(ctx)
+		deal, err := deals.NonBlockingReceiveWithContext(phaseCtx)
 		if err != nil {
-			return xerrors.Errorf("context done: %v", err)
+			if ctx.Err() != nil {
+				return xerrors.Errorf("context done: %v", err)
+			}
+
+			s.log.Warn().Int("received", numReceivedDeals).Msg("deals are missing")
+
+			return nil
 		}
 
-		err = s.handleDeal(ctx, deal, out, participants)
+		numReceivedDeals++
+
+		s.journal.addDeal(deal)
+
+		resp, err := s.processDeal(deal)
 		if err != nil {
-			return xerrors.Errorf("failed to handle received deal: %v", err)
+			s.log.Warn().Err(err).Uint32("dealer", deal.GetIndex()).Msg("invalid deal")
+			continue
 		}
 
-		numReceivedDeals++
+		s.journal.addResponse(resp)
+
+		err = s.broadcast(ctx, resp, out, participants)
+		if err != nil {
+			return xerrors.Errorf("failed to send response: %v", err)
+		}
 
 		s.log.Trace().Str("total", strconv.Itoa(numReceivedDeals)).Msg("deal received")
 	}
@@ -373,17 +569,7 @@ func (s *instance) certify(
 			return xerrors.Errorf("context done: %v", err)
 		}
 
-		resp := pedersen.Response{
-			Index: msg.GetIndex(),
-			Response: &vss.Response{
-				SessionID: msg.GetResponse().GetSessionID(),
-				Index:     msg.GetResponse().GetIndex(),
-				Status:    msg.GetResponse().GetStatus(),
-				Signature: msg.GetResponse().GetSignature(),
-			},
-		}
-
-		_, err = s.dkg.ProcessResponse(&resp)
+		_, err = s.dkg.ProcessResponse(newKyberResponse(msg))
 		if err != nil {
 			return xerrors.Errorf("failed to process response: %v", err)
 		}
@@ -400,6 +586,258 @@ func (s *instance) certify(
 	return nil
 }
 
+// qualification is the state of a node while it collects the responses and the
+// justifications of the setup.
+type qualification struct {
+	// complaints are the complaints of the node about the dealers it has no
+	// valid deal from.
+	complaints map[uint32]types.Response
+	// pending are the responses about the dealers the node has no deal from,
+	// which are processed once the deal is revealed by a justification.
+	pending map[uint32][]types.Response
+	// justifications are the justifications waiting for the complaint they
+	// answer.
+	justifications map[messageKey]types.Justification
+}
+
+// qualify collects the responses and the justifications until every deal is
+// certified, or until the phase times out. A node without a valid deal from a
+// dealer complains about it, and the dealer must answer with a justification
+// that reveals the deal. The dealers whose deal is not certified at the end
+// are excluded, as long as a threshold of them remains.
+func (s *instance) qualify(
+	ctx context.Context,
+	resps channel.Timed[types.Response],
+	justifs channel.Timed[types.Justification],
+	out mino.Sender,
+) error {
+
+	q := &qualification{
+		complaints:     make(map[uint32]types.Response),
+		pending:        make(map[uint32][]types.Response),
+		justifications: make(map[messageKey]types.Justification),
+	}
+
+	phaseCtx, cancel := withTimeout(ctx, s.clock, s.getPhaseTimeout())
+	defer cancel()
+
+	for timeout := false; !timeout && !s.dkg.Certified(); {
+		select {
+		case msg := <-resps.Channel():
+			s.processResponse(ctx, q, msg, out)
+		case msg := <-justifs.Channel():
+			s.processJustification(ctx, q, msg, out)
+		case <-phaseCtx.Done():
+			if ctx.Err() != nil {
+				return xerrors.Errorf("context done: %v", ctx.Err())
+			}
+
+			s.log.Warn().Msg("some deals are not certified before the timeout")
+			timeout = true
+		}
+	}
+
+	s.dkg.SetTimeout()
+
+	if !s.dkg.ThresholdCertified() {
+		return xerrors.New("node is not certified")
+	}
+
+	qual := s.dkg.QUAL()
+	if len(qual) < len(s.startRes.getParticipants()) {
+		s.log.Warn().Ints("qualified", qual).Msg("faulty dealers are excluded")
+	}
+
+	return nil
+}
+
+// processResponse processes the response of a verifier, and sends the
+// justification when it is a complaint about the deal of the node.
+func (s *instance) processResponse(ctx context.Context, q *qualification,
+	msg types.Response, out mino.Sender) {
+
+	s.journal.addResponse(msg)
+
+	j, err := s.dkg.ProcessResponse(newKyberResponse(msg))
+	if errors.Is(err, vss.ErrNoDealBeforeResponse) {
+		dealer := msg.GetIndex()
+
+		q.pending[dealer] = append(q.pending[dealer], msg)
+
+		_, found := q.complaints[dealer]
+		if !found {
+			s.complain(ctx, q, msg, out)
+		}
+
+		return
+	}
+
+	if err != nil {
+		s.log.Warn().Err(err).Uint32("dealer", msg.GetIndex()).
+			Uint32("verifier", msg.GetResponse().GetIndex()).Msg("invalid response")
+		return
+	}
+
+	if j != nil {
+		justif := newJustification(j)
+
+		s.journal.addJustification(justif)
+
+		err = s.broadcast(ctx, justif, out, s.startRes.getParticipants())
+		if err != nil {
+			s.log.Warn().Err(err).Msg("failed to send justification")
+		}
+	}
+
+	s.applyJustifications(ctx, q, out)
+}
+
+// complain sends a complaint about a dealer the node has no valid deal from.
+// The session is taken from the response of another verifier, so that the
+// dealer can answer with a justification.
+func (s *instance) complain(ctx context.Context, q *qualification, msg types.Response,
+	out mino.Sender) {
+
+	resp := &vss.Response{
+		SessionID: msg.GetResponse().GetSessionID(),
+		Index:     uint32(s.journal.me),
+		Status:    vss.StatusComplaint,
+	}
+
+	sig, err := schnorr.Sign(s.getSuite(), s.privKey, resp.Hash(suite))
+	if err != nil {
+		s.log.Warn().Err(err).Msg("failed to sign complaint")
+		return
+	}
+
+	resp.Signature = sig
+
+	complaint := newResponse(&pedersen.Response{Index: msg.GetIndex(), Response: resp})
+
+	q.complaints[msg.GetIndex()] = complaint
+
+	s.journal.addResponse(complaint)
+
+	s.log.Warn().Uint32("dealer", msg.GetIndex()).Msg("complaining about a missing deal")
+
+	err = s.broadcast(ctx, complaint, out, s.startRes.getParticipants())
+	if err != nil {
+		s.log.Warn().Err(err).Msg("failed to send complaint")
+	}
+}
+
+// processJustification keeps the justification of a dealer until the
+// complaint it answers is known.
+func (s *instance) processJustification(ctx context.Context, q *qualification,
+	msg types.Justification, out mino.Sender) {
+
+	err := verifyJustificationSignature(s.startRes.getPublicKeys(), msg)
+	if err != nil {
+		s.log.Warn().Err(err).Uint32("dealer", msg.GetDealer()).Msg("invalid justification")
+		return
+	}
+
+	s.journal.addJustification(msg)
+
+	q.justifications[messageKey{dealer: msg.GetDealer(), verifier: msg.GetIndex()}] = msg
+
+	s.applyJustifications(ctx, q, out)
+}
+
+// applyJustifications processes the justifications whose complaint is known.
+// When a justification reveals the deal of the node, the responses about the
+// dealer that were waiting for it are processed.
+func (s *instance) applyJustifications(ctx context.Context, q *qualification,
+	out mino.Sender) {
+
+	var replay []types.Response
+
+	for key, justif := range q.justifications {
+		verifier, found := s.dkg.Verifiers()[key.dealer]
+		if !found {
+			delete(q.justifications, key)
+			continue
+		}
+
+		complaint, complained := q.complaints[key.dealer]
+		if complained && key.verifier == uint32(s.journal.me) && verifier.SessionID() == nil {
+			delete(q.justifications, key)
+
+			err := s.reveal(verifier, justif, complaint)
+			if err != nil {
+				s.log.Warn().Err(err).Uint32("dealer", key.dealer).Msg("invalid justification")
+			}
+
+			replay = append(replay, q.pending[key.dealer]...)
+			delete(q.pending, key.dealer)
+
+			continue
+		}
+
+		resp, found := verifier.Responses()[key.verifier]
+		if !found || resp.Status != vss.StatusComplaint {
+			continue
+		}
+
+		delete(q.justifications, key)
+
+		err := s.dkg.ProcessJustification(newKyberJustification(justif))
+		if err != nil {
+			s.log.Warn().Err(err).Uint32("dealer", key.dealer).Msg("invalid justification")
+		}
+	}
+
+	for _, resp := range replay {
+		s.processResponse(ctx, q, resp, out)
+	}
+}
+
+// reveal uses the deal revealed by the justification of a dealer the node
+// complained about, as if it was received in the first place. The complaint
+// stays when the deal is invalid.
+func (s *instance) reveal(verifier *vss.Verifier, justif types.Justification,
+	complaint types.Response) error {
+
+	pubkeys := s.startRes.getPublicKeys()
+	threshold := s.startRes.getThreshold()
+	dealer := justif.GetDealer()
+
+	sid := sessionID(pubkeys[dealer], pubkeys, justif.GetCommitments(), threshold)
+	kjustif := newKyberJustification(justif)
+
+	// The deal is kept even when it is invalid, so that the responses of the
+	// others about it can be processed.
+	_ = verifier.VerifyDeal(kjustif.Justification.Deal, true)
+
+	err := verifier.ProcessResponse(newKyberResponse(complaint).Response)
+	if err != nil {
+		return xerrors.Errorf("failed to process complaint: %v", err)
+	}
+
+	err = checkJustification(justif, sid, justif.GetCommitments(), threshold)
+	if err != nil {
+		return xerrors.Errorf("invalid deal: %v", err)
+	}
+
+	// The dealer does not respond to its own deal.
+	verifier.UnsafeSetResponseDKG(dealer, vss.StatusApproval)
+
+	return s.dkg.ProcessJustification(kjustif)
+}
+
+// commitments returns the commitments of the deals known by the node.
+func (s *instance) commitments() [][]kyber.Point {
+	commitments := make([][]kyber.Point, len(s.startRes.getPublicKeys()))
+
+	for i, verifier := range s.dkg.Verifiers() {
+		if int(i) < len(commitments) && verifier.SessionID() != nil {
+			commitments[i] = verifier.Commits()
+		}
+	}
+
+	return commitments
+}
+
 // finalize saves the result and announces it to the orchestrator.
 func (s *instance) finalize(ctx context.Context, from mino.Address, out mino.Sender) error {
 	// Send back the public DKG key
@@ -411,13 +849,24 @@ func (s *instance) finalize(ctx context.Context, from mino.Address, out mino.Sen
 	// Update the state before sending the acknowledgement to the orchestrator,
 	// so that it can process decrypt requests right away.
 	s.startRes.setDistKey(distKey.Public())
+	s.startRes.setPubPoly(share.NewPubPoly(suite, nil, distKey.Commitments()))
 
 	s.Lock()
 	s.privShare = distKey.PriShare()
 	s.Unlock()
 
+	err = s.persist(distKey)
+	if err != nil {
+		return xerrors.Errorf("failed to store result: %v", err)
+	}
+
 	done := types.NewStartDone(distKey.Public())
 
+	if s.journal != nil {
+		transcript := s.journal.transcript(s.commitments(), s.dkg.QUAL(), distKey.Public())
+		done = types.NewStartDoneWithTranscript(distKey.Public(), transcript)
+	}
+
 	select {
 	case err = <-out.Send(done, from):
 		if err != nil {
@@ -436,44 +885,41 @@ func (s *instance) handleDeal(
 	out mino.Sender, to []mino.Address,
 ) error {
 
-	deal := &pedersen.Deal{
-		Index: msg.GetIndex(),
-		Deal: &vss.EncryptedDeal{
-			DHKey:     msg.GetEncryptedDeal().GetDHKey(),
-			Signature: msg.GetEncryptedDeal().GetSignature(),
-			Nonce:     msg.GetEncryptedDeal().GetNonce(),
-			Cipher:    msg.GetEncryptedDeal().GetCipher(),
-		},
-		Signature: msg.GetSignature(),
+	resp, err := s.processDeal(msg)
+	if err != nil {
+		return xerrors.Errorf("failed to process deal: %v", err)
 	}
 
-	response, err := s.dkg.ProcessDeal(deal)
+	return s.broadcast(ctx, resp, out, to)
+}
+
+// processDeal processes the deal and returns the response of the node.
+func (s *instance) processDeal(msg types.Deal) (types.Response, error) {
+	response, err := s.dkg.ProcessDeal(newKyberDeal(msg))
 	if err != nil {
-		return xerrors.Errorf("failed to process deal: %v", err)
+		return types.Response{}, err
 	}
 
-	resp := types.NewResponse(
-		response.Index,
-		types.NewDealerResponse(
-			response.Response.Index,
-			response.Response.Status,
-			response.Response.SessionID,
-			response.Response.Signature,
-		),
-	)
+	return newResponse(response), nil
+}
+
+// broadcast sends the message to the other nodes.
+func (s *instance) broadcast(
+	ctx context.Context, msg serde.Message,
+	out mino.Sender, to []mino.Address,
+) error {
 
 	for _, addr := range to {
 		if addr.Equal(s.me) {
 			continue
 		}
 
-		s.log.Trace().Str("to", addr.String()).Uint32("dealer", response.Index).
-			Msg("sending response")
+		s.log.Trace().Str("to", addr.String()).Msgf("sending %T", msg)
 
-		errs := out.Send(resp, addr)
+		errs := out.Send(msg, addr)
 
 		select {
-		case err = <-errs:
+		case err := <-errs:
 			if err != nil {
 				return xerrors.Errorf("failed to send response to '%s': %v", addr, err)
 			}
@@ -506,9 +952,15 @@ func (s *instance) finalizeReshare(
 		// Update the state before sending to acknowledgement to the
 		// orchestrator, so that it can process decrypt requests right away.
 		s.startRes.setDistKey(distrKey.Public())
+		s.startRes.setPubPoly(share.NewPubPoly(suite, nil, distrKey.Commitments()))
 		s.Lock()
 		s.privShare = distrKey.PriShare()
 		s.Unlock()
+
+		err = s.persist(distrKey)
+		if err != nil {
+			return xerrors.Errorf("failed to store result: %v", err)
+		}
 	}
 
 	// all the old, new and common nodes should announce their public key to the
@@ -599,7 +1051,7 @@ func (s *instance) doReshare(
 		s.log.Trace().Msgf("old node: %v", s.startRes.getPublicKeys())
 
 		c := &pedersen.Config{
-			Suite:        suite,
+			Suite:        s.getSuite(),
 			Longterm:     s.privKey,
 			OldNodes:     s.startRes.getPublicKeys(),
 			NewNodes:     start.GetPubkeysNew(),
@@ -621,7 +1073,7 @@ func (s *instance) doReshare(
 			return xerrors.Errorf("old node failed to send deals: %v", err)
 		}
 
-		expectedResponses = (start.GetTNew()) * len(s.startRes.getParticipants())
+		expectedResponses = len(addrsNew) * len(addrsOld)
 
 	case commonNode:
 		// Update local DKG for resharing
@@ -633,7 +1085,7 @@ func (s *instance) doReshare(
 		s.log.Trace().Msgf("old node: %v", s.startRes.getPublicKeys())
 
 		c := &pedersen.Config{
-			Suite:        suite,
+			Suite:        s.getSuite(),
 			Longterm:     s.privKey,
 			OldNodes:     s.startRes.getPublicKeys(),
 			NewNodes:     start.GetPubkeysNew(),
@@ -664,7 +1116,7 @@ func (s *instance) doReshare(
 		// Save the specifications of the new committee in the handler state
 		s.startRes.init(start.GetAddrsNew(), start.GetPubkeysNew(), start.GetTNew())
 
-		expectedResponses = (start.GetTNew() - 1) * len(addrsOld)
+		expectedResponses = (len(addrsNew) - 1) * len(addrsOld)
 
 	case newNode:
 		// Process the incoming deals
@@ -676,7 +1128,7 @@ func (s *instance) doReshare(
 		// Save the specifications of the new committee in the handler state
 		s.startRes.init(start.GetAddrsNew(), start.GetPubkeysNew(), start.GetTNew())
 
-		expectedResponses = (start.GetTNew() - 1) * start.GetTOld()
+		expectedResponses = (len(addrsNew) - 1) * len(start.GetAddrsOld())
 	}
 
 	// All nodes should certify.
@@ -718,7 +1170,9 @@ func (s *instance) sendDealsResharing(
 
 	s.log.Trace().Msgf("%s is sending its deals", s.me)
 
-	for i, deal := range deals {
+	for _, i := range dealIndices(deals) {
+		deal := deals[i]
+
 		dealMsg := types.NewDeal(
 			deal.Index,
 			deal.Signature,
@@ -788,7 +1242,7 @@ func (s *instance) receiveDealsResharing(
 		if nt == newNode && numReceivedDeals == 0 {
 
 			c := &pedersen.Config{
-				Suite:        suite,
+				Suite:        s.getSuite(),
 				Longterm:     s.privKey,
 				OldNodes:     resharingRequest.GetPubkeysOld(),
 				NewNodes:     resharingRequest.GetPubkeysNew(),
@@ -829,10 +1283,19 @@ func (s *instance) handleDecrypt(
 		return xerrors.Errorf(initDkgFirst)
 	}
 
-	S := suite.Point().Mul(s.privShare.V, msg.K)
+	// A proof can be rebuilt by anyone from the chain, therefore the shares
+	// are only released reencrypted to the key of the reader when the
+	// decryptions are authorized.
+	if s.authorizer != nil {
+		return xerrors.New("decryption is not authorized, use a reencryption")
+	}
+
+	// The proof shows that the share of the key uses the private share of the
+	// participant, so that the actor can drop the invalid partials.
+	S, _, E, F := proveDLEQ(msg.K, s.privShare.V, s.getSuite().RandomStream())
 
 	partial := suite.Point().Sub(msg.C, S)
-	decryptReply := types.NewDecryptReply(int64(s.privShare.I), partial)
+	decryptReply := types.NewDecryptReply(int64(s.privShare.I), partial, E, F)
 
 	errs := out.Send(decryptReply, from)
 	err := <-errs
@@ -852,10 +1315,15 @@ func (s *instance) handleReencryptRequest(
 		return xerrors.Errorf(initDkgFirst)
 	}
 
+	err := s.authorize(msg.GetProof(), msg.K, msg.PubK)
+	if err != nil {
+		return xerrors.Errorf("unauthorized reencryption: %v", err)
+	}
+
 	ui := s.getUI(msg.K, msg.PubK)
 
 	// Calculating proofs of reencryption
-	si := suite.Scalar().Pick(suite.RandomStream())
+	si := suite.Scalar().Pick(s.getSuite().RandomStream())
 	uiHat := suite.Point().Mul(si, suite.Point().Add(msg.K, msg.PubK))
 	hiHat := suite.Point().Mul(si, nil)
 	hash := sha256.New()
@@ -868,7 +1336,7 @@ func (s *instance) handleReencryptRequest(
 	response := types.NewReencryptReply(msg.PubK, ui, ei, fi)
 
 	errs := out.Send(response, from)
-	err := <-errs
+	err = <-errs
 	if err != nil {
 		return xerrors.Errorf("got an error while sending the reencrypt reply: %v", err)
 	}
@@ -876,6 +1344,149 @@ func (s *instance) handleReencryptRequest(
 	return nil
 }
 
+// authorize returns nil if the instance has no authorizer, or if the proof
+// allows the reencryption of K to pubk.
+func (s *instance) authorize(proof []byte, K, pubk kyber.Point) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	return s.authorizer.Authorize(proof, K, pubk)
+}
+
+// handleBeacon sends the share of the beacon of the input. It is not gated by
+// the authorizer: the base of the share is hashed from the input, therefore it
+// never reveals a share of a decryption.
+func (s *instance) handleBeacon(out mino.Sender, msg types.BeaconRequest,
+	from mino.Address) error {
+
+	beaconShare := newBeaconShare(msg.GetInput(), s.privShare,
+		s.getSuite().RandomStream())
+
+	errs := out.Send(types.NewBeaconReply(beaconShare), from)
+	err := <-errs
+	if err != nil {
+		return xerrors.Errorf("got an error while sending the beacon reply: %v", err)
+	}
+
+	return nil
+}
+
+func (s *instance) handlePublicKey(out mino.Sender, from mino.Address) error {
+	pubkey := suite.Point().Mul(s.privKey, nil)
+
+	errs := out.Send(types.NewPublicKeyReply(pubkey), from)
+	err := <-errs
+	if err != nil {
+		return xerrors.Errorf("got an error while sending the public key reply: %v", err)
+	}
+
+	return nil
+}
+
+// handleSignCommit sends the commitment of fresh nonces for a signature. Like
+// the beacon, the signatures are not gated by the authorizer and any client of
+// the DKG can have a message signed by the distributed key.
+func (s *instance) handleSignCommit(out mino.Sender, msg types.SignCommitRequest,
+	from mino.Address) error {
+
+	current := now(s.clock)
+
+	nonces := signNonces{
+		d:      suite.Scalar().Pick(s.getSuite().RandomStream()),
+		e:      suite.Scalar().Pick(s.getSuite().RandomStream()),
+		expiry: current.Add(decryptTimeout),
+	}
+
+	s.Lock()
+
+	if s.nonces == nil {
+		s.nonces = make(map[string]signNonces)
+	}
+
+	for session, pending := range s.nonces {
+		if current.After(pending.expiry) {
+			delete(s.nonces, session)
+		}
+	}
+
+	_, found := s.nonces[string(msg.GetSession())]
+	if found {
+		s.Unlock()
+		return xerrors.Errorf("session %x already exists", msg.GetSession())
+	}
+
+	if len(s.nonces) >= maxSignSessions {
+		s.Unlock()
+		return xerrors.Errorf("too many signing sessions: %d", len(s.nonces))
+	}
+
+	s.nonces[string(msg.GetSession())] = nonces
+
+	s.Unlock()
+
+	commitment := types.SignCommitment{
+		I: int64(s.privShare.I),
+		D: suite.Point().Mul(nonces.d, nil),
+		E: suite.Point().Mul(nonces.e, nil),
+	}
+
+	errs := out.Send(types.NewSignCommitReply(commitment), from)
+	err := <-errs
+	if err != nil {
+		return xerrors.Errorf("got an error while sending the sign commit reply: %v", err)
+	}
+
+	return nil
+}
+
+func (s *instance) handleSign(out mino.Sender, msg types.SignRequest,
+	from mino.Address) error {
+
+	// The nonces are removed whatever happens next so that they are never used
+	// for two different signatures.
+	s.Lock()
+	nonces, found := s.nonces[string(msg.GetSession())]
+	delete(s.nonces, string(msg.GetSession()))
+	s.Unlock()
+
+	if !found {
+		return xerrors.Errorf("unknown session %x", msg.GetSession())
+	}
+
+	if now(s.clock).After(nonces.expiry) {
+		return xerrors.Errorf("session %x expired", msg.GetSession())
+	}
+
+	session, err := newSignSession(s.startRes.getDistKey(), msg.GetMessage(),
+		msg.GetCommitments())
+	if err != nil {
+		return xerrors.Errorf("failed to create session: %v", err)
+	}
+
+	i := int64(s.privShare.I)
+
+	commitment, found := session.commitments[i]
+	if !found {
+		return xerrors.Errorf("commitment %d not in the session", i)
+	}
+
+	if !commitment.D.Equal(suite.Point().Mul(nonces.d, nil)) ||
+		!commitment.E.Equal(suite.Point().Mul(nonces.e, nil)) {
+		return xerrors.Errorf("commitment %d doesn't match the nonces", i)
+	}
+
+	z := session.sign(nonces, s.privShare)
+
+	errs := out.Send(types.NewSignReply(i, z), from)
+	err = <-errs
+	if err != nil {
+		return xerrors.Errorf("got an error while sending the sign reply: %v", err)
+	}
+
+	return nil
+}
+
 func (s *instance) getUI(K, pubk kyber.Point) *share.PubShare {
 	v := suite.Point().Mul(s.privShare.V, K)
 	v.Add(v, suite.Point().Mul(s.privShare.V, pubk))
@@ -890,6 +1501,12 @@ func (s *instance) handleVerifiableDecrypt(
 	msg types.VerifiableDecryptRequest, from mino.Address,
 ) error {
 
+	// The request doesn't carry a proof, therefore the verifiable decryption
+	// is only available when every request is accepted.
+	if s.authorizer != nil {
+		return xerrors.New("verifiable decryption is not authorized")
+	}
+
 	type job struct {
 		index int // index where to put the response
 		ct    types.Ciphertext
@@ -927,7 +1544,8 @@ func (s *instance) handleVerifiableDecrypt(
 			defer wgBatchReply.Done()
 
 			for j := range jobChan {
-				sp, err := verifiableDecryption(j.ct, s.privShare.V, s.privShare.I)
+				sp, err := verifiableDecryption(j.ct, s.privShare.V, s.privShare.I,
+					s.getSuite().RandomStream())
 				if err != nil {
 					s.log.Err(err).Msg("verifiable decryption failed")
 				}
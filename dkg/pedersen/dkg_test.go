@@ -128,6 +128,17 @@ func TestDKGInstance_HandleReencryptRequestFail(t *testing.T) {
 	require.EqualError(t, err, "bad state: unexpected state: UNKNOWN != one of [Certified]")
 }
 
+func TestDKGInstance_HandleBeaconRequestFail(t *testing.T) {
+	s := instance{
+		startRes: &state{dkgState: 0xaa},
+	}
+
+	err := s.handleMessage(context.TODO(), types.BeaconRequest{},
+		fake.NewAddress(0), nil)
+
+	require.EqualError(t, err, "bad state: unexpected state: UNKNOWN != one of [Certified]")
+}
+
 func TestDKGInstance_HandleUnknown(t *testing.T) {
 	s := instance{
 		startRes: &state{dkgState: 0xaa},
@@ -234,8 +245,7 @@ func TestDKGInstance_doDKG_CertifyFail(t *testing.T) {
 	err := s.doDKG(ctx, channel.Timed[types.Deal]{},
 		channel.Timed[types.Response]{}, nil, nil)
 
-	require.EqualError(t, err, "failed to certify: context done: Could not "+
-		"receive data from channel.")
+	require.EqualError(t, err, "failed to certify: context done: context canceled")
 }
 
 func TestDKGInstance_doDKG_SwitchFail(t *testing.T) {
@@ -319,7 +329,9 @@ func TestDKGInstance_deal_ctxFail(t *testing.T) {
 	require.EqualError(t, err, "context done: context canceled")
 }
 
-func TestDKGInstance_respond_handleFail(t *testing.T) {
+func TestDKGInstance_respond_invalidDeal(t *testing.T) {
+	out := &bytes.Buffer{}
+
 	s := instance{
 		dkg: fakeDKGService{
 			processErr: fake.GetError(),
@@ -327,13 +339,51 @@ func TestDKGInstance_respond_handleFail(t *testing.T) {
 		startRes: &state{
 			participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
 		},
+		log: zerolog.New(out),
 	}
 
 	deals := channel.WithExpiration[types.Deal](1)
 	deals.Send(types.Deal{})
 
+	// The invalid deal is skipped so that the dealer is excluded later.
 	err := s.respond(context.Background(), deals, fake.NewBadSender())
-	require.EqualError(t, err, fake.Err("failed to handle received deal: failed to process deal"))
+	require.NoError(t, err)
+	require.Regexp(t, "invalid deal", out.String())
+}
+
+func TestDKGInstance_respond_sendFail(t *testing.T) {
+	s := instance{
+		dkg: fakeDKGService{
+			response: &pedersen.Response{Response: &vss.Response{}},
+		},
+		startRes: &state{
+			participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+		},
+		me: fake.NewAddress(0),
+	}
+
+	deals := channel.WithExpiration[types.Deal](1)
+	deals.Send(types.Deal{})
+
+	err := s.respond(context.Background(), deals, fake.NewBadSender())
+	require.EqualError(t, err, fake.Err("failed to send response: "+
+		"failed to send response to 'fake.Address[1]'"))
+}
+
+func TestDKGInstance_respond_timeout(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	s := instance{
+		startRes: &state{
+			participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+		},
+		log:          zerolog.New(out),
+		phaseTimeout: 10 * time.Millisecond,
+	}
+
+	err := s.respond(context.Background(), channel.WithExpiration[types.Deal](1), nil)
+	require.NoError(t, err)
+	require.Regexp(t, "deals are missing", out.String())
 }
 
 func TestDKGInstance_respond_ctxFail(t *testing.T) {
@@ -425,6 +475,68 @@ func TestDKGInstance_certify_ctxFail(t *testing.T) {
 	require.EqualError(t, err, "context done: Could not receive data from channel.")
 }
 
+func TestDKGInstance_qualify_timeout(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	s := instance{
+		dkg:          fakeDKGService{},
+		startRes:     &state{},
+		log:          zerolog.New(out),
+		phaseTimeout: 10 * time.Millisecond,
+	}
+
+	err := s.qualify(context.Background(), channel.WithExpiration[types.Response](1),
+		channel.WithExpiration[types.Justification](1), nil)
+	require.EqualError(t, err, "node is not certified")
+	require.Regexp(t, "some deals are not certified before the timeout", out.String())
+}
+
+func TestDKGInstance_qualify_excluded(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	s := instance{
+		dkg: fakeDKGService{certified: true, qual: []int{0}},
+		startRes: &state{
+			participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+		},
+		log: zerolog.New(out),
+	}
+
+	err := s.qualify(context.Background(), channel.WithExpiration[types.Response](1),
+		channel.WithExpiration[types.Justification](1), nil)
+	require.NoError(t, err)
+	require.Regexp(t, "faulty dealers are excluded", out.String())
+}
+
+func TestDKGInstance_processResponse_invalid(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	s := instance{
+		dkg:      fakeDKGService{respoErr: fake.GetError()},
+		startRes: &state{},
+		log:      zerolog.New(out),
+	}
+
+	q := &qualification{}
+
+	s.processResponse(context.Background(), q, types.Response{}, nil)
+	require.Regexp(t, "invalid response", out.String())
+}
+
+func TestDKGInstance_processJustification_invalid(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	s := instance{
+		startRes: &state{pubkeys: []kyber.Point{suite.Point()}},
+		log:      zerolog.New(out),
+	}
+
+	justif := types.NewJustification(0, nil, 1, nil, 2, nil, []byte("bad"))
+
+	s.processJustification(context.Background(), &qualification{}, justif, nil)
+	require.Regexp(t, "invalid justification", out.String())
+}
+
 func TestDKGInstance_finalize_sendFail(t *testing.T) {
 	s := instance{
 		dkg: fakeDKGService{
@@ -462,6 +574,22 @@ func TestDKGInstance_finalize_ctxFail(t *testing.T) {
 	require.Regexp(t, "context done", out.String())
 }
 
+func TestDKGInstance_finalize_storeFail(t *testing.T) {
+	s := instance{
+		dkg: fakeDKGService{
+			distKeyShare: &pedersen.DistKeyShare{
+				Commits: []kyber.Point{suite.Point()},
+				Share:   &share.PriShare{V: suite.Scalar()},
+			},
+		},
+		store:    &storage{db: fake.NewBadDB()},
+		startRes: &state{},
+	}
+
+	err := s.finalize(context.Background(), nil, fake.Sender{})
+	require.EqualError(t, err, fake.Err("failed to store result: failed to write result: bucket"))
+}
+
 func TestDKGInstance_finalizeReshare_sistKeyFail(t *testing.T) {
 	s := instance{
 		dkg: fakeDKGService{
@@ -486,6 +614,22 @@ func TestDKGInstance_finalizeReshare_sendFail(t *testing.T) {
 	require.EqualError(t, err, fake.Err("got an error while sending pub key"))
 }
 
+func TestDKGInstance_finalizeReshare_storeFail(t *testing.T) {
+	s := instance{
+		dkg: fakeDKGService{
+			distKeyShare: &pedersen.DistKeyShare{
+				Commits: []kyber.Point{suite.Point()},
+				Share:   &share.PriShare{V: suite.Scalar()},
+			},
+		},
+		store:    &storage{db: fake.NewBadDB()},
+		startRes: &state{},
+	}
+
+	err := s.finalizeReshare(context.Background(), commonNode, fake.Sender{}, nil)
+	require.EqualError(t, err, fake.Err("failed to store result: failed to write result: bucket"))
+}
+
 func TestDKGInstance_finalizeReshare_ctxFail(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := zerolog.New(out)
@@ -836,21 +980,18 @@ func TestDKGInstance_doReshare_certifyFail(t *testing.T) {
 			participants: []mino.Address{fake.NewAddress(2)},
 		},
 		dkg: fakeDKGService{
-			certified: true,
+			certified: false,
 		},
 		me: me,
 	}
 
+	// Without old nodes, no response is expected.
 	msg := types.NewStartResharing(2, 2, []mino.Address{me, fake.NewAddress(1)},
 		[]mino.Address{}, nil, nil)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	cancel()
-
-	err := s.doReshare(ctx, msg, nil, nil, channel.Timed[types.Reshare]{},
+	err := s.doReshare(context.Background(), msg, nil, nil, channel.Timed[types.Reshare]{},
 		channel.Timed[types.Response]{})
-	require.EqualError(t, err, "failed to certify: context done: "+
-		"Could not receive data from channel.")
+	require.EqualError(t, err, "failed to certify: node is not certified")
 }
 
 func TestDKGInstance_doReshare_switchStateFail(t *testing.T) {
@@ -958,6 +1099,90 @@ func TestDKGInstance_handleDecrypt_sendFail(t *testing.T) {
 	require.EqualError(t, err, fake.Err("got an error while sending the decrypt reply"))
 }
 
+func TestDKGInstance_Authorizer(t *testing.T) {
+	priShare := &share.PriShare{I: 1, V: suite.Scalar().Pick(suite.RandomStream())}
+
+	s := instance{
+		startRes: &state{
+			dkgState: certified,
+		},
+		privShare:  priShare,
+		authorizer: fakeAuthorizer{},
+	}
+
+	K := suite.Point().Pick(suite.RandomStream())
+	pubk := suite.Point().Pick(suite.RandomStream())
+
+	sender := &recordSender{}
+
+	err := s.handleDecrypt(sender, types.NewDecryptRequest(K, K), nil)
+	require.EqualError(t, err, "decryption is not authorized, use a reencryption")
+	require.Empty(t, sender.msgs)
+
+	req := types.NewReencryptRequest(K, pubk, []byte("ok"))
+
+	err = s.handleReencryptRequest(sender, *req, nil)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 1)
+
+	req = types.NewReencryptRequest(K, pubk, []byte("abc"))
+
+	err = s.handleReencryptRequest(sender, *req, nil)
+	require.EqualError(t, err, "unauthorized reencryption: invalid proof 'abc'")
+
+	err = s.handleVerifiableDecrypt(sender, types.VerifiableDecryptRequest{}, nil)
+	require.EqualError(t, err, "verifiable decryption is not authorized")
+	require.Len(t, sender.msgs, 1)
+}
+
+func TestDKGInstance_handleBeacon(t *testing.T) {
+	priShare := &share.PriShare{I: 2, V: suite.Scalar().Pick(suite.RandomStream())}
+
+	s := instance{
+		startRes: &state{
+			dkgState: certified,
+		},
+		privShare: priShare,
+	}
+
+	sender := &recordSender{}
+
+	err := s.handleMessage(context.TODO(), types.NewBeaconRequest([]byte("abc")),
+		fake.NewAddress(0), sender)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 1)
+
+	reply := sender.msgs[0].(types.BeaconReply)
+	require.Equal(t, int64(2), reply.GetShare().I)
+	require.NoError(t, checkBeaconShare(beaconBase([]byte("abc")), nil, reply.GetShare()))
+
+	err = s.handleBeacon(fake.NewBadSender(), types.NewBeaconRequest(nil), nil)
+	require.EqualError(t, err, fake.Err("got an error while sending the beacon reply"))
+}
+
+func TestDKGInstance_handlePublicKey(t *testing.T) {
+	privKey := suite.Scalar().Pick(suite.RandomStream())
+
+	s := instance{
+		startRes: &state{
+			dkgState: initial,
+		},
+		privKey: privKey,
+	}
+
+	sender := &recordSender{}
+
+	err := s.handleMessage(context.TODO(), types.NewPublicKeyRequest(), fake.NewAddress(0), sender)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 1)
+
+	reply := sender.msgs[0].(types.PublicKeyReply)
+	require.True(t, suite.Point().Mul(privKey, nil).Equal(reply.GetPublicKey()))
+
+	err = s.handlePublicKey(fake.NewBadSender(), nil)
+	require.EqualError(t, err, fake.Err("got an error while sending the public key reply"))
+}
+
 func TestDKGInstance_handleDeal_responseFail(t *testing.T) {
 	privKey1 := suite.Scalar().Pick(suite.RandomStream())
 	pubKey1 := suite.Point().Mul(privKey1, nil)
@@ -1094,10 +1319,14 @@ type fakeDKGService struct {
 	respoErr   error
 	shareErr   error
 	processErr error
+	justifErr  error
 
 	certified bool
+	qual      []int
+	verifiers map[uint32]*vss.Verifier
 
 	deals        map[int]*pedersen.Deal
+	response     *pedersen.Response
 	distKeyShare *pedersen.DistKeyShare
 }
 
@@ -1118,7 +1347,25 @@ func (s fakeDKGService) DistKeyShare() (*pedersen.DistKeyShare, error) {
 }
 
 func (s fakeDKGService) ProcessDeal(dd *pedersen.Deal) (*pedersen.Response, error) {
-	return nil, s.processErr
+	return s.response, s.processErr
+}
+
+func (s fakeDKGService) ProcessJustification(j *pedersen.Justification) error {
+	return s.justifErr
+}
+
+func (s fakeDKGService) SetTimeout() {}
+
+func (s fakeDKGService) ThresholdCertified() bool {
+	return s.certified
+}
+
+func (s fakeDKGService) QUAL() []int {
+	return s.qual
+}
+
+func (s fakeDKGService) Verifiers() map[uint32]*vss.Verifier {
+	return s.verifiers
 }
 
 type blockingSender struct {
@@ -1127,3 +1374,16 @@ type blockingSender struct {
 func (blockingSender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
 	return make(<-chan error)
 }
+
+type recordSender struct {
+	msgs []serde.Message
+}
+
+func (s *recordSender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	s.msgs = append(s.msgs, msg)
+
+	errs := make(chan error)
+	close(errs)
+
+	return errs
+}
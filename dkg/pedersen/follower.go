@@ -0,0 +1,237 @@
+package pedersen
+
+import (
+	"context"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/ed25519"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/internal/tracing"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/xerrors"
+)
+
+// RosterService is the part of the ordering service the DKG needs to follow
+// the roster, like the cosipbft service.
+type RosterService interface {
+	// Watch returns a channel populated with the new blocks. The channel must
+	// be listened at all time and the context closed when done.
+	Watch(ctx context.Context) <-chan ordering.Event
+
+	// GetRoster returns the current roster.
+	GetRoster() (authority.Authority, error)
+}
+
+// FollowerOption is the type of option to set some fields of a follower.
+type FollowerOption func(*Follower)
+
+// WithThresholdPolicy is an option to set the function that returns the
+// threshold of the new committee from its size. The byzantine threshold is
+// used by default.
+func WithThresholdPolicy(policy cosi.Threshold) FollowerOption {
+	return func(f *Follower) {
+		f.policy = policy
+	}
+}
+
+// Follower keeps the DKG committee in sync with the roster of the ordering
+// service. When a block changes the roster, the first member of the new roster
+// that is already part of the committee reshares the secret to the new roster,
+// so that the public key of the DKG stays the same.
+type Follower struct {
+	actor  *Actor
+	me     mino.Address
+	roster RosterService
+	policy cosi.Threshold
+	cancel context.CancelFunc
+}
+
+// NewFollower creates a new follower of the roster for the actor of the node
+// with the given address.
+func NewFollower(actor *Actor, me mino.Address, roster RosterService,
+	opts ...FollowerOption) *Follower {
+
+	f := &Follower{
+		actor:  actor,
+		me:     me,
+		roster: roster,
+		policy: threshold.ByzantineThreshold,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Start starts to follow the roster in the background. The roster is checked
+// right away, and then after each new block.
+func (f *Follower) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	events := f.roster.Watch(ctx)
+
+	// The events are drained while a resharing is running so that the
+	// ordering service is never blocked. Only the last check is kept as the
+	// roster is read again anyway.
+	checks := make(chan struct{}, 1)
+	checks <- struct{}{}
+
+	go func() {
+		for range events {
+			select {
+			case checks <- struct{}{}:
+			default:
+			}
+		}
+
+		close(checks)
+	}()
+
+	go func() {
+		for range checks {
+			err := f.update()
+			if err != nil {
+				dela.Logger.Warn().Err(err).Msg("failed to follow the roster")
+			}
+		}
+	}()
+}
+
+// Stop stops to follow the roster.
+func (f *Follower) Stop() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
+// update reshares the secret to the current roster when it differs from the
+// committee and the node leads the resharing.
+func (f *Follower) update() error {
+	if !f.actor.startRes.Done() {
+		return nil
+	}
+
+	roster, err := f.roster.GetRoster()
+	if err != nil {
+		return xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	addrs := make([]mino.Address, 0, roster.Len())
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		addrs = append(addrs, iter.GetNext())
+	}
+
+	participants := f.actor.startRes.getParticipants()
+
+	if len(difference(addrs, participants)) == 0 && len(difference(participants, addrs)) == 0 {
+		return nil
+	}
+
+	// Only one node must start the resharing, which must be a member of the
+	// current committee.
+	var leader mino.Address
+
+	for _, addr := range addrs {
+		if len(difference([]mino.Address{addr}, participants)) == 0 {
+			leader = addr
+			break
+		}
+	}
+
+	if leader == nil {
+		return xerrors.New("no member of the committee is left in the roster")
+	}
+
+	if !leader.Equal(f.me) {
+		return nil
+	}
+
+	dela.Logger.Info().Msgf("roster changed, resharing to %v", addrs)
+
+	pubkeys, err := f.actor.getPublicKeys(addrs)
+	if err != nil {
+		return xerrors.Errorf("failed to get public keys: %v", err)
+	}
+
+	err = f.actor.Reshare(authority.New(addrs, pubkeys), f.policy(len(addrs)))
+	if err != nil {
+		return xerrors.Errorf("failed to reshare: %v", err)
+	}
+
+	return nil
+}
+
+// getPublicKeys returns the public keys of the nodes in the DKG. The keys of
+// the participants are already known, and the other nodes are asked for
+// theirs.
+func (a *Actor) getPublicKeys(addrs []mino.Address) ([]crypto.PublicKey, error) {
+	participants := a.startRes.getParticipants()
+	known := a.startRes.getPublicKeys()
+
+	points := make([]kyber.Point, len(addrs))
+
+	for i, addr := range addrs {
+		for j, participant := range participants {
+			if addr.Equal(participant) {
+				points[i] = known[j]
+				break
+			}
+		}
+	}
+
+	unknown := difference(addrs, participants)
+
+	if len(unknown) > 0 {
+		ctx, cancel := a.withTimeout(resharingTimeout)
+		defer cancel()
+
+		ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameResharing)
+
+		sender, receiver, err := a.rpc.Stream(ctx, mino.NewAddresses(unknown...))
+		if err != nil {
+			return nil, xerrors.Errorf(failedStreamCreation, err)
+		}
+
+		err = <-sender.Send(types.NewPublicKeyRequest(), unknown...)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to send public key request: %v", err)
+		}
+
+		for missing := len(unknown); missing > 0; {
+			from, msg, err := receiver.Recv(ctx)
+			if err != nil {
+				return nil, xerrors.Errorf(unexpectedStreamStop, err)
+			}
+
+			reply, ok := msg.(types.PublicKeyReply)
+			if !ok {
+				return nil, xerrors.Errorf(unexpectedReply, reply, msg)
+			}
+
+			for i, addr := range addrs {
+				if points[i] == nil && addr.Equal(from) {
+					points[i] = reply.GetPublicKey()
+					missing--
+				}
+			}
+		}
+	}
+
+	pubkeys := make([]crypto.PublicKey, len(points))
+	for i, point := range points {
+		pubkeys[i] = ed25519.NewPublicKeyFromPoint(point)
+	}
+
+	return pubkeys, nil
+}
@@ -0,0 +1,312 @@
+package pedersen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/ed25519"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestFollower_Scenario(t *testing.T) {
+	oldLog := dela.Logger
+	defer func() {
+		dela.Logger = oldLog
+	}()
+
+	dela.Logger = dela.Logger.Level(zerolog.WarnLevel)
+
+	nbNodes := 4
+
+	addrs := make([]mino.Address, nbNodes)
+	pubkeys := make([]kyber.Point, nbNodes)
+	actors := make([]*Actor, nbNodes)
+
+	manager := minoch.NewManager()
+	roster := newFakeRosterService()
+
+	for i := range actors {
+		m := minoch.MustCreate(manager, fmt.Sprint("node", i))
+		addrs[i] = m.GetAddress()
+
+		d, pubkey := NewPedersen(m)
+		pubkeys[i] = pubkey
+
+		actor, err := d.Listen()
+		require.NoError(t, err)
+
+		actors[i] = actor.(*Actor)
+
+		follower := NewFollower(actors[i], addrs[i], roster)
+		follower.Start()
+		defer follower.Stop()
+	}
+
+	// The last node is not part of the first committee.
+	dkgPubKey, err := actors[0].Setup(NewAuthority(addrs[:3], pubkeys[:3]), 3)
+	require.NoError(t, err)
+
+	message := []byte("Hello world")
+
+	K, Cs, err := actors[0].Encrypt(message)
+	require.NoError(t, err)
+
+	// The new node joins the roster and gets a share of the same key.
+	roster.set(addrs, pubkeys)
+
+	waitCommittee(t, actors[3], addrs)
+	require.Equal(t, 3, actors[3].startRes.getThreshold())
+
+	pubkey, err := actors[3].GetPublicKey()
+	require.NoError(t, err)
+	require.True(t, dkgPubKey.Equal(pubkey))
+
+	decrypted, err := actors[3].Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+
+	// The first node leaves the roster, and the resharing is led by the next
+	// member.
+	roster.set(addrs[1:], pubkeys[1:])
+
+	waitCommittee(t, actors[1], addrs[1:])
+
+	pubkey, err = actors[2].GetPublicKey()
+	require.NoError(t, err)
+	require.True(t, dkgPubKey.Equal(pubkey))
+
+	decrypted, err = actors[2].Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+}
+
+func TestFollower_Update(t *testing.T) {
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1)}
+	points := []kyber.Point{suite.Point(), suite.Point()}
+
+	roster := newFakeRosterService()
+	roster.set(addrs, points)
+
+	actor := &Actor{
+		startRes: &state{dkgState: initial},
+		rpc:      fake.NewBadRPC(),
+	}
+
+	f := NewFollower(actor, addrs[0], roster)
+
+	// Nothing happens before the setup.
+	err := f.update()
+	require.NoError(t, err)
+
+	actor.startRes = &state{
+		dkgState:     certified,
+		participants: addrs,
+		pubkeys:      points,
+	}
+
+	err = f.update()
+	require.NoError(t, err)
+
+	f.me = addrs[1]
+	roster.set(addrs[1:], points[1:])
+
+	err = f.update()
+	require.EqualError(t, err, fake.Err("failed to reshare: failed to create stream"))
+
+	f.me = addrs[0]
+	roster.set([]mino.Address{addrs[0], fake.NewAddress(2)}, points)
+
+	err = f.update()
+	require.EqualError(t, err,
+		fake.Err("failed to get public keys: failed to create stream"))
+
+	// Only the leader reshares.
+	f.me = addrs[1]
+
+	err = f.update()
+	require.NoError(t, err)
+
+	roster.set([]mino.Address{fake.NewAddress(2)}, points[:1])
+
+	err = f.update()
+	require.EqualError(t, err, "no member of the committee is left in the roster")
+
+	roster.err = fake.GetError()
+
+	err = f.update()
+	require.EqualError(t, err, fake.Err("failed to read roster"))
+}
+
+func TestFollower_WithThresholdPolicy(t *testing.T) {
+	f := NewFollower(nil, nil, nil, WithThresholdPolicy(func(n int) int { return n }))
+
+	require.Equal(t, 5, f.policy(5))
+
+	f = NewFollower(nil, nil, nil)
+
+	require.Equal(t, 4, f.policy(5))
+}
+
+func TestFollower_Stop(t *testing.T) {
+	roster := newFakeRosterService()
+
+	f := NewFollower(&Actor{startRes: &state{}}, fake.NewAddress(0), roster)
+
+	// Stopping a follower that is not started is a no-op.
+	f.Stop()
+
+	f.Start()
+	f.Stop()
+
+	require.Eventually(t, func() bool {
+		roster.Lock()
+		defer roster.Unlock()
+
+		return len(roster.watchers) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestActor_GetPublicKeys(t *testing.T) {
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)}
+	known := suite.Point().Pick(suite.RandomStream())
+	other := suite.Point().Pick(suite.RandomStream())
+
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(addrs[2], types.NewPublicKeyReply(other)),
+		fake.NewRecvMsg(addrs[1], types.NewPublicKeyReply(other)),
+	)
+
+	a := Actor{
+		startRes: &state{
+			participants: addrs[:1],
+			pubkeys:      []kyber.Point{known},
+		},
+		rpc: fake.NewStreamRPC(recv, fake.Sender{}),
+	}
+
+	pubkeys, err := a.getPublicKeys(addrs)
+	require.NoError(t, err)
+	require.Equal(t, []crypto.PublicKey{
+		ed25519.NewPublicKeyFromPoint(known),
+		ed25519.NewPublicKeyFromPoint(other),
+		ed25519.NewPublicKeyFromPoint(other),
+	}, pubkeys)
+
+	// The keys of the participants are already known.
+	a.rpc = fake.NewBadRPC()
+
+	pubkeys, err = a.getPublicKeys(addrs[:1])
+	require.NoError(t, err)
+	require.Len(t, pubkeys, 1)
+
+	_, err = a.getPublicKeys(addrs)
+	require.EqualError(t, err, fake.Err("failed to create stream"))
+
+	a.rpc = fake.NewStreamRPC(fake.NewReceiver(), fake.NewBadSender())
+
+	_, err = a.getPublicKeys(addrs)
+	require.EqualError(t, err, fake.Err("failed to send public key request"))
+
+	a.rpc = fake.NewStreamRPC(fake.NewBadReceiver(), fake.Sender{})
+
+	_, err = a.getPublicKeys(addrs)
+	require.EqualError(t, err, fake.Err("stream stopped unexpectedly"))
+
+	recv = fake.NewReceiver(fake.NewRecvMsg(addrs[1], fake.Message{}))
+	a.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	_, err = a.getPublicKeys(addrs)
+	require.EqualError(t, err, "got unexpected reply, expected "+
+		"types.PublicKeyReply but got: fake.Message")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// waitCommittee waits until the actor is certified for the given committee.
+func waitCommittee(t *testing.T, actor *Actor, addrs []mino.Address) {
+	require.Eventually(t, func() bool {
+		participants := actor.startRes.getParticipants()
+
+		return actor.startRes.Done() && len(participants) == len(addrs) &&
+			len(difference(addrs, participants)) == 0
+	}, time.Minute, 100*time.Millisecond)
+}
+
+// fakeRosterService is a roster service that notifies the watchers every time
+// the roster is set.
+type fakeRosterService struct {
+	sync.Mutex
+
+	roster   authority.Authority
+	watchers []chan ordering.Event
+	err      error
+}
+
+func newFakeRosterService() *fakeRosterService {
+	return &fakeRosterService{
+		roster: authority.New(nil, nil),
+	}
+}
+
+func (s *fakeRosterService) set(addrs []mino.Address, points []kyber.Point) {
+	pubkeys := make([]crypto.PublicKey, len(points))
+	for i, point := range points {
+		pubkeys[i] = ed25519.NewPublicKeyFromPoint(point)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.roster = authority.New(addrs, pubkeys)
+
+	for _, ch := range s.watchers {
+		ch <- ordering.Event{}
+	}
+}
+
+func (s *fakeRosterService) Watch(ctx context.Context) <-chan ordering.Event {
+	s.Lock()
+	defer s.Unlock()
+
+	ch := make(chan ordering.Event, 1)
+	s.watchers = append(s.watchers, ch)
+
+	go func() {
+		<-ctx.Done()
+
+		s.Lock()
+		defer s.Unlock()
+
+		for i, watcher := range s.watchers {
+			if watcher == ch {
+				s.watchers = append(s.watchers[:i], s.watchers[i+1:]...)
+				break
+			}
+		}
+
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (s *fakeRosterService) GetRoster() (authority.Authority, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.roster, s.err
+}
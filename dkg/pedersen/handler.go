@@ -2,6 +2,7 @@ package pedersen
 
 import (
 	"context"
+	"crypto/cipher"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/kyber/v3"
 	"golang.org/x/xerrors"
@@ -25,19 +27,38 @@ var recvTimeout = time.Second * 30
 type Handler struct {
 	mino.UnsupportedHandler
 	sync.RWMutex
-	log zerolog.Logger
+	log   zerolog.Logger
+	clock clock.Clock
 
 	dkgInstance dkgInstance
 }
 
 // NewHandler creates a new handler
 func NewHandler(privKey kyber.Scalar, me mino.Address) *Handler {
+	return newHandler(privKey, me, nil, nil, 0, nil)
+}
+
+// newHandler creates a new handler whose instance releases the shares of the
+// decryptions only when the authorizer accepts the request. Every request is
+// accepted when the authorizer is nil. The timeouts run on the clock, or on
+// the system clock when it is nil, and the randomness is drawn from the
+// stream, or from the system randomness when it is nil.
+func newHandler(privKey kyber.Scalar, me mino.Address, authorizer Authorizer,
+	c clock.Clock, phaseTimeout time.Duration, random cipher.Stream) *Handler {
+
 	log := dela.Logger.With().Str("role", "DKG handler").Str("addr", me.String()).Logger()
 
+	instance := newInstance(log, me, privKey)
+	instance.authorizer = authorizer
+	instance.clock = c
+	instance.phaseTimeout = phaseTimeout
+	instance.random = random
+
 	return &Handler{
-		log: log,
+		log:   log,
+		clock: c,
 
-		dkgInstance: newInstance(log, me, privKey),
+		dkgInstance: instance,
 	}
 }
 
@@ -57,11 +78,12 @@ func (h *Handler) Stream(out mino.Sender, in mino.Receiver) error {
 	defer cancel()
 
 	for {
-		ctx, cancel := context.WithTimeout(context.Background(), recvTimeout)
+		ctx, cancel := withTimeout(context.Background(), h.clock, recvTimeout)
 		from, msg, err := in.Recv(ctx)
+		expired := errors.Is(context.Cause(ctx), context.DeadlineExceeded)
 		cancel()
 
-		if errors.Is(err, context.DeadlineExceeded) {
+		if err != nil && expired {
 			if !h.dkgInstance.isRunning() {
 				h.log.Info().Msg("stream done, deadline exceeded")
 				return nil
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/testing/fake"
 
 	"github.com/stretchr/testify/require"
@@ -37,6 +38,30 @@ func TestHandler_Stream_Deadline(t *testing.T) {
 	require.Regexp(t, "stream done, deadline exceeded", out.String())
 }
 
+func TestHandler_Stream_Clock(t *testing.T) {
+	c := clock.NewVirtual(time.Unix(0, 0))
+
+	out := &bytes.Buffer{}
+
+	h := Handler{
+		dkgInstance: fakeHandler{running: false},
+		log:         zerolog.New(out),
+		clock:       c,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Stream(nil, fake.NewBlockingReceiver())
+	}()
+
+	require.Eventually(t, func() bool { return c.Len() == 1 }, time.Second, time.Millisecond)
+
+	c.Advance(recvTimeout)
+
+	require.NoError(t, <-done)
+	require.Regexp(t, "stream done, deadline exceeded", out.String())
+}
+
 func TestHandler_Stream_EOF(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := zerolog.New(out)
@@ -0,0 +1,263 @@
+// This file contains the hybrid encryption of large payloads. The payload is
+// encrypted with a fresh symmetric key, and only the key is encrypted with the
+// distributed key, so that decrypting a payload of any size needs a single
+// threshold operation.
+//
+// The output starts with a header made of the magic, the version, the
+// encrypted key K, the number of points and the points C. The payload follows
+// in segments sealed with ChaCha20-Poly1305, where the nonce is the index of
+// the segment and a flag for the last one so that a truncation is detected.
+
+package pedersen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/xerrors"
+)
+
+// hybridVersion is the version of the format of the hybrid encryption.
+const hybridVersion = 1
+
+// segmentSize is the size of the plaintext of a segment.
+const segmentSize = 64 * 1024
+
+// hybridMagic is the prefix of the output of the hybrid encryption.
+var hybridMagic = []byte("DKGH")
+
+// EncryptStream encrypts the content of the reader into the writer. The content
+// is encrypted with a fresh symmetric key, which is itself encrypted with the
+// distributed key of the actor.
+func EncryptStream(actor dkg.Actor, dst io.Writer, src io.Reader) error {
+	key := make([]byte, chacha20poly1305.KeySize)
+
+	_, err := rand.Read(key)
+	if err != nil {
+		return xerrors.Errorf("failed to generate key: %v", err)
+	}
+
+	K, Cs, err := actor.Encrypt(key)
+	if err != nil {
+		return xerrors.Errorf("failed to encrypt key: %v", err)
+	}
+
+	header, err := encodeHybridHeader(K, Cs)
+	if err != nil {
+		return xerrors.Errorf("failed to encode header: %v", err)
+	}
+
+	_, err = dst.Write(header)
+	if err != nil {
+		return xerrors.Errorf("failed to write header: %v", err)
+	}
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return xerrors.Errorf("failed to create cipher: %v", err)
+	}
+
+	reader := bufio.NewReader(src)
+	plaintext := make([]byte, segmentSize)
+	sealed := make([]byte, 0, segmentSize+aead.Overhead())
+
+	for index := uint64(0); ; index++ {
+		n, last, err := readSegment(reader, plaintext)
+		if err != nil {
+			return xerrors.Errorf("failed to read segment %d: %v", index, err)
+		}
+
+		sealed = aead.Seal(sealed[:0], segmentNonce(index, last), plaintext[:n], header)
+
+		_, err = dst.Write(sealed)
+		if err != nil {
+			return xerrors.Errorf("failed to write segment %d: %v", index, err)
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// DecryptStream decrypts the content of the reader, as written by
+// EncryptStream, into the writer. The symmetric key is decrypted by the
+// participants of the DKG of the actor. Every segment is authenticated before
+// it is written, but the segments written before an error must be discarded.
+func DecryptStream(actor dkg.Actor, dst io.Writer, src io.Reader) error {
+	reader := bufio.NewReader(src)
+
+	K, Cs, header, err := decodeHybridHeader(reader)
+	if err != nil {
+		return xerrors.Errorf("failed to decode header: %v", err)
+	}
+
+	key, err := actor.Decrypt(K, Cs)
+	if err != nil {
+		return xerrors.Errorf("failed to decrypt key: %v", err)
+	}
+
+	if len(key) != chacha20poly1305.KeySize {
+		return xerrors.Errorf("invalid key size: %d", len(key))
+	}
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return xerrors.Errorf("failed to create cipher: %v", err)
+	}
+
+	sealed := make([]byte, segmentSize+aead.Overhead())
+	plaintext := make([]byte, 0, segmentSize)
+
+	for index := uint64(0); ; index++ {
+		n, last, err := readSegment(reader, sealed)
+		if err != nil {
+			return xerrors.Errorf("failed to read segment %d: %v", index, err)
+		}
+
+		plaintext, err = openSegment(aead, plaintext[:0], index, last, sealed[:n], header)
+		if err != nil {
+			return xerrors.Errorf("failed to decrypt segment %d: %v", index, err)
+		}
+
+		_, err = dst.Write(plaintext)
+		if err != nil {
+			return xerrors.Errorf("failed to write segment %d: %v", index, err)
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// readSegment fills the buffer from the reader and tells if it is the last
+// segment, which is the case when the reader has no more data after it.
+func readSegment(reader *bufio.Reader, buffer []byte) (int, bool, error) {
+	n, err := io.ReadFull(reader, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, true, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	_, err = reader.Peek(1)
+	if err == io.EOF {
+		return n, true, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return n, false, nil
+}
+
+func openSegment(aead cipher.AEAD, dst []byte, index uint64, last bool,
+	sealed, header []byte) ([]byte, error) {
+
+	if len(sealed) < aead.Overhead() {
+		return nil, xerrors.New("truncated segment")
+	}
+
+	return aead.Open(dst, segmentNonce(index, last), sealed, header)
+}
+
+// segmentNonce returns the nonce of the segment, made of its index and a flag
+// for the last segment. As every key is used for a single payload, the nonces
+// are never reused.
+func segmentNonce(index uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce, index)
+
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce
+}
+
+func encodeHybridHeader(K kyber.Point, Cs []kyber.Point) ([]byte, error) {
+	if len(Cs) > 255 {
+		return nil, xerrors.Errorf("too many points: %d", len(Cs))
+	}
+
+	buffer := new(bytes.Buffer)
+	buffer.Write(hybridMagic)
+	buffer.WriteByte(hybridVersion)
+
+	_, err := K.MarshalTo(buffer)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal K: %v", err)
+	}
+
+	buffer.WriteByte(byte(len(Cs)))
+
+	for _, C := range Cs {
+		_, err = C.MarshalTo(buffer)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal C: %v", err)
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// decodeHybridHeader reads the header from the reader and returns the points,
+// as well as the raw header that authenticates the segments.
+func decodeHybridHeader(reader io.Reader) (kyber.Point, []kyber.Point, []byte, error) {
+	buffer := new(bytes.Buffer)
+	reader = io.TeeReader(reader, buffer)
+
+	prefix := make([]byte, len(hybridMagic)+1)
+
+	_, err := io.ReadFull(reader, prefix)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("failed to read prefix: %v", err)
+	}
+
+	if !bytes.Equal(prefix[:len(hybridMagic)], hybridMagic) {
+		return nil, nil, nil, xerrors.New("invalid magic")
+	}
+
+	version := prefix[len(hybridMagic)]
+	if version != hybridVersion {
+		return nil, nil, nil, xerrors.Errorf("unsupported version %d", version)
+	}
+
+	K := suite.Point()
+
+	_, err = K.UnmarshalFrom(reader)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("failed to unmarshal K: %v", err)
+	}
+
+	num := make([]byte, 1)
+
+	_, err = io.ReadFull(reader, num)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("failed to read number of points: %v", err)
+	}
+
+	Cs := make([]kyber.Point, num[0])
+
+	for i := range Cs {
+		Cs[i] = suite.Point()
+
+		_, err = Cs[i].UnmarshalFrom(reader)
+		if err != nil {
+			return nil, nil, nil, xerrors.Errorf("failed to unmarshal C: %v", err)
+		}
+	}
+
+	return K, Cs, buffer.Bytes(), nil
+}
@@ -0,0 +1,202 @@
+package pedersen
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestHybrid_Scenario(t *testing.T) {
+	actor := newElGamalActor()
+
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, 3*segmentSize + 7} {
+		payload := make([]byte, size)
+		_, err := rand.Read(payload)
+		require.NoError(t, err)
+
+		encrypted := new(bytes.Buffer)
+
+		err = EncryptStream(actor, encrypted, bytes.NewReader(payload))
+		require.NoError(t, err)
+
+		decrypted := new(bytes.Buffer)
+
+		err = DecryptStream(actor, decrypted, bytes.NewReader(encrypted.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, size, decrypted.Len())
+		require.True(t, bytes.Equal(payload, decrypted.Bytes()))
+	}
+}
+
+func TestEncryptStream_Fail(t *testing.T) {
+	err := EncryptStream(elGamalActor{err: fake.GetError()}, nil, nil)
+	require.EqualError(t, err, fake.Err("failed to encrypt key"))
+
+	actor := newElGamalActor()
+
+	err = EncryptStream(actor, fake.NewBadHash(), bytes.NewReader(nil))
+	require.EqualError(t, err, fake.Err("failed to write header"))
+
+	err = EncryptStream(actor, new(bytes.Buffer), badReader{})
+	require.EqualError(t, err, fake.Err("failed to read segment 0"))
+
+	err = EncryptStream(actor, &limitedWriter{limit: 1}, bytes.NewReader(nil))
+	require.EqualError(t, err, fake.Err("failed to write segment 0"))
+}
+
+func TestDecryptStream_Fail(t *testing.T) {
+	actor := newElGamalActor()
+
+	// The payload fills exactly two segments, the second being the last one.
+	payload := make([]byte, 2*segmentSize)
+	encrypted := new(bytes.Buffer)
+
+	err := EncryptStream(actor, encrypted, bytes.NewReader(payload))
+	require.NoError(t, err)
+
+	data := encrypted.Bytes()
+	headerSize := len(hybridMagic) + 1 + 32 + 1 + 2*32
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(nil))
+	require.EqualError(t, err, "failed to decode header: failed to read prefix: EOF")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader([]byte("abcde")))
+	require.EqualError(t, err, "failed to decode header: invalid magic")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader([]byte("DKGH\x02")))
+	require.EqualError(t, err, "failed to decode header: unsupported version 2")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(data[:10]))
+	require.Regexp(t, "^failed to decode header: failed to unmarshal K:", err.Error())
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(data[:37]))
+	require.EqualError(t, err,
+		"failed to decode header: failed to read number of points: EOF")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(data[:40]))
+	require.Regexp(t, "^failed to decode header: failed to unmarshal C:", err.Error())
+
+	err = DecryptStream(elGamalActor{err: fake.GetError()}, nil, bytes.NewReader(data))
+	require.EqualError(t, err, fake.Err("failed to decrypt key"))
+
+	err = DecryptStream(elGamalActor{key: []byte{1}}, nil, bytes.NewReader(data))
+	require.EqualError(t, err, "invalid key size: 1")
+
+	tampered := append([]byte{}, data...)
+	tampered[headerSize+1] ^= 1
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(tampered))
+	require.EqualError(t, err,
+		"failed to decrypt segment 0: chacha20poly1305: message authentication failed")
+
+	// The segments can't be swapped, nor the last one be removed.
+	segment := segmentSize + 16
+	swapped := append([]byte{}, data[:headerSize]...)
+	swapped = append(swapped, data[headerSize+segment:headerSize+2*segment]...)
+	swapped = append(swapped, data[headerSize:headerSize+segment]...)
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(swapped))
+	require.EqualError(t, err,
+		"failed to decrypt segment 0: chacha20poly1305: message authentication failed")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(data[:headerSize+2*segment-1]))
+	require.EqualError(t, err,
+		"failed to decrypt segment 1: chacha20poly1305: message authentication failed")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(data[:headerSize+segment+1]))
+	require.EqualError(t, err, "failed to decrypt segment 1: truncated segment")
+
+	err = DecryptStream(actor, new(bytes.Buffer), bytes.NewReader(data[:headerSize+segment]))
+	require.EqualError(t, err,
+		"failed to decrypt segment 0: chacha20poly1305: message authentication failed")
+
+	err = DecryptStream(actor, fake.NewBadHash(), bytes.NewReader(data))
+	require.EqualError(t, err, fake.Err("failed to write segment 0"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// elGamalActor is an actor that encrypts and decrypts with a local key.
+type elGamalActor struct {
+	dkg.Actor
+
+	secret kyber.Scalar
+	key    []byte
+	err    error
+}
+
+func newElGamalActor() elGamalActor {
+	return elGamalActor{secret: suite.Scalar().Pick(suite.RandomStream())}
+}
+
+func (a elGamalActor) Encrypt(msg []byte) (kyber.Point, []kyber.Point, error) {
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+
+	pubkey := suite.Point().Mul(a.secret, nil)
+
+	k := suite.Scalar().Pick(suite.RandomStream())
+	K := suite.Point().Mul(k, nil)
+	S := suite.Point().Mul(k, pubkey)
+
+	var Cs []kyber.Point
+
+	for len(msg) > 0 {
+		M := suite.Point().Embed(msg, suite.RandomStream())
+		Cs = append(Cs, suite.Point().Add(S, M))
+
+		msg = msg[min(len(msg), suite.Point().EmbedLen()):]
+	}
+
+	return K, Cs, nil
+}
+
+func (a elGamalActor) Decrypt(K kyber.Point, Cs []kyber.Point) ([]byte, error) {
+	if a.err != nil || a.key != nil {
+		return a.key, a.err
+	}
+
+	S := suite.Point().Mul(a.secret, K)
+
+	var msg []byte
+
+	for _, C := range Cs {
+		data, err := suite.Point().Sub(C, S).Data()
+		if err != nil {
+			return nil, err
+		}
+
+		msg = append(msg, data...)
+	}
+
+	return msg, nil
+}
+
+type badReader struct{}
+
+func (badReader) Read([]byte) (int, error) {
+	return 0, fake.GetError()
+}
+
+// limitedWriter fails once the number of writes reaches the limit.
+type limitedWriter struct {
+	limit int
+	count int
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.count >= w.limit {
+		return 0, fake.GetError()
+	}
+
+	w.count++
+
+	return len(data), nil
+}
@@ -15,6 +15,7 @@ const ERROR_UNMARSHAL_K = "couldn't unmarshal K: %v"
 
 func init() {
 	types.RegisterMessageFormat(serde.FormatJSON, newMsgFormat())
+	types.RegisterMessageFormat(serde.FormatCBOR, newMsgFormat())
 }
 
 type Address []byte
@@ -66,8 +67,31 @@ type Response struct {
 	Response DealerResponse
 }
 
+type Justification struct {
+	Dealer      uint32
+	SessionID   []byte
+	Index       uint32
+	ShareI      int
+	ShareV      []byte
+	Threshold   uint32
+	Commitments []PublicKey
+	Signature   []byte
+}
+
+type Transcript struct {
+	Threshold      int
+	PublicKeys     []PublicKey
+	Deals          [][]Deal
+	Responses      []Response
+	Justifications []Justification
+	Commitments    [][]PublicKey
+	Qualified      []int
+	PublicKey      PublicKey
+}
+
 type StartDone struct {
-	PublicKey PublicKey
+	PublicKey  PublicKey
+	Transcript *Transcript `json:",omitempty"`
 }
 
 type DecryptRequest struct {
@@ -91,6 +115,8 @@ type VerifiableDecryptRequest struct {
 type DecryptReply struct {
 	V []byte
 	I int64
+	E []byte
+	F []byte
 }
 
 type ShareAndProof struct {
@@ -108,8 +134,9 @@ type VerifiableDecryptReply struct {
 }
 
 type ReencryptRequest struct {
-	K    []byte
-	PubK PublicKey
+	K     []byte
+	PubK  PublicKey
+	Proof []byte `json:",omitempty"`
 }
 
 type ReencryptReply struct {
@@ -120,19 +147,76 @@ type ReencryptReply struct {
 	Fi   []byte
 }
 
+type BeaconRequest struct {
+	Input []byte
+}
+
+type BeaconShare struct {
+	I int64
+	V PublicKey
+	X PublicKey
+	E []byte
+	F []byte
+}
+
+type BeaconReply struct {
+	Share BeaconShare
+}
+
+type PublicKeyRequest struct{}
+
+type PublicKeyReply struct {
+	PublicKey PublicKey
+}
+
+type SignCommitRequest struct {
+	Session []byte
+}
+
+type SignCommitment struct {
+	I int64
+	D PublicKey
+	E PublicKey
+}
+
+type SignCommitReply struct {
+	Commitment SignCommitment
+}
+
+type SignRequest struct {
+	Session     []byte
+	Message     []byte
+	Commitments []SignCommitment
+}
+
+type SignReply struct {
+	I int64
+	Z []byte
+}
+
 type Message struct {
 	Start                    *Start                    `json:",omitempty"`
 	StartResharing           *StartResharing           `json:",omitempty"`
 	Deal                     *Deal                     `json:",omitempty"`
 	Reshare                  *Reshare                  `json:",omitempty"`
 	Response                 *Response                 `json:",omitempty"`
+	Justification            *Justification            `json:",omitempty"`
 	StartDone                *StartDone                `json:",omitempty"`
+	Transcript               *Transcript               `json:",omitempty"`
 	DecryptRequest           *DecryptRequest           `json:",omitempty"`
 	DecryptReply             *DecryptReply             `json:",omitempty"`
 	VerifiableDecryptReply   *VerifiableDecryptReply   `json:",omitempty"`
 	VerifiableDecryptRequest *VerifiableDecryptRequest `json:",omitempty"`
 	ReencryptRequest         *ReencryptRequest         `json:",omitempty"`
 	ReencryptReply           *ReencryptReply           `json:",omitempty"`
+	BeaconRequest            *BeaconRequest            `json:",omitempty"`
+	BeaconReply              *BeaconReply              `json:",omitempty"`
+	PublicKeyRequest         *PublicKeyRequest         `json:",omitempty"`
+	PublicKeyReply           *PublicKeyReply           `json:",omitempty"`
+	SignCommitRequest        *SignCommitRequest        `json:",omitempty"`
+	SignCommitReply          *SignCommitReply          `json:",omitempty"`
+	SignRequest              *SignRequest              `json:",omitempty"`
+	SignReply                *SignReply                `json:",omitempty"`
 }
 
 // MsgFormat is the engine to encode and decode dkg messages in JSON format.
@@ -160,34 +244,25 @@ func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error)
 	case types.StartResharing:
 		m, err = encodeStartResharing(in)
 	case types.Deal:
-		d := Deal{
-			Index:     in.GetIndex(),
-			Signature: in.GetSignature(),
-			EncryptedDeal: EncryptedDeal{
-				DHKey:     in.GetEncryptedDeal().GetDHKey(),
-				Signature: in.GetEncryptedDeal().GetSignature(),
-				Nonce:     in.GetEncryptedDeal().GetNonce(),
-				Cipher:    in.GetEncryptedDeal().GetCipher(),
-			},
-		}
+		d := encodeDeal(in)
 
 		m = Message{Deal: &d}
 	case types.Reshare:
 		m, err = encodeReshare(in)
 	case types.Response:
-		r := Response{
-			Index: in.GetIndex(),
-			Response: DealerResponse{
-				SessionID: in.GetResponse().GetSessionID(),
-				Index:     in.GetResponse().GetIndex(),
-				Status:    in.GetResponse().GetStatus(),
-				Signature: in.GetResponse().GetSignature(),
-			},
-		}
+		r := encodeResponse(in)
 
 		m = Message{Response: &r}
+	case types.Justification:
+		var j Justification
+		j, err = encodeJustification(in)
+		m = Message{Justification: &j}
 	case types.StartDone:
 		m, err = encodeStartDone(in)
+	case types.Transcript:
+		var t Transcript
+		t, err = encodeTranscript(in)
+		m = Message{Transcript: &t}
 	case types.DecryptRequest:
 		m, err = encodeDecryptRequest(in)
 	case types.VerifiableDecryptRequest:
@@ -200,6 +275,22 @@ func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error)
 		m, err = encodeReencryptRequest(in)
 	case types.ReencryptReply:
 		m, err = encodeReencryptReply(in)
+	case types.BeaconRequest:
+		m = Message{BeaconRequest: &BeaconRequest{Input: in.GetInput()}}
+	case types.BeaconReply:
+		m, err = encodeBeaconReply(in)
+	case types.PublicKeyRequest:
+		m = Message{PublicKeyRequest: &PublicKeyRequest{}}
+	case types.PublicKeyReply:
+		m, err = encodePublicKeyReply(in)
+	case types.SignCommitRequest:
+		m = Message{SignCommitRequest: &SignCommitRequest{Session: in.GetSession()}}
+	case types.SignCommitReply:
+		m, err = encodeSignCommitReply(in)
+	case types.SignRequest:
+		m, err = encodeSignRequest(in)
+	case types.SignReply:
+		m, err = encodeSignReply(in)
 	default:
 		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
 	}
@@ -233,38 +324,23 @@ func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error)
 		return f.decodeStartResharing(ctx, m.StartResharing)
 
 	case m.Deal != nil:
-		deal := types.NewDeal(
-			m.Deal.Index,
-			m.Deal.Signature,
-			types.NewEncryptedDeal(
-				m.Deal.EncryptedDeal.DHKey,
-				m.Deal.EncryptedDeal.Signature,
-				m.Deal.EncryptedDeal.Nonce,
-				m.Deal.EncryptedDeal.Cipher,
-			),
-		)
-
-		return deal, nil
+		return decodeDeal(*m.Deal), nil
 
 	case m.Reshare != nil:
 		return f.decodeReshare(ctx, m.Reshare)
 
 	case m.Response != nil:
-		resp := types.NewResponse(
-			m.Response.Index,
-			types.NewDealerResponse(
-				m.Response.Response.Index,
-				m.Response.Response.Status,
-				m.Response.Response.SessionID,
-				m.Response.Response.Signature,
-			),
-		)
-
-		return resp, nil
+		return decodeResponse(*m.Response), nil
+
+	case m.Justification != nil:
+		return f.decodeJustification(*m.Justification)
 
 	case m.StartDone != nil:
 		return f.decodeStartDone(ctx, m.StartDone)
 
+	case m.Transcript != nil:
+		return f.decodeTranscript(*m.Transcript)
+
 	case m.DecryptRequest != nil:
 		return f.decodeDecryptRequest(ctx, m.DecryptRequest)
 
@@ -282,6 +358,30 @@ func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error)
 
 	case m.ReencryptReply != nil:
 		return f.decodeReencryptReply(ctx, m.ReencryptReply)
+
+	case m.BeaconRequest != nil:
+		return types.NewBeaconRequest(m.BeaconRequest.Input), nil
+
+	case m.BeaconReply != nil:
+		return f.decodeBeaconReply(ctx, m.BeaconReply)
+
+	case m.PublicKeyRequest != nil:
+		return types.NewPublicKeyRequest(), nil
+
+	case m.PublicKeyReply != nil:
+		return f.decodePublicKeyReply(m.PublicKeyReply)
+
+	case m.SignCommitRequest != nil:
+		return types.NewSignCommitRequest(m.SignCommitRequest.Session), nil
+
+	case m.SignCommitReply != nil:
+		return f.decodeSignCommitReply(ctx, m.SignCommitReply)
+
+	case m.SignRequest != nil:
+		return f.decodeSignRequest(ctx, m.SignRequest)
+
+	case m.SignReply != nil:
+		return f.decodeSignReply(ctx, m.SignReply)
 	}
 
 	return nil, xerrors.New("message is empty")
@@ -515,6 +615,15 @@ func encodeStartDone(msg types.StartDone) (Message, error) {
 		PublicKey: pubkey,
 	}
 
+	if msg.GetTranscript() != nil {
+		transcript, err := encodeTranscript(*msg.GetTranscript())
+		if err != nil {
+			return Message{}, xerrors.Errorf("couldn't encode transcript: %v", err)
+		}
+
+		ack.Transcript = &transcript
+	}
+
 	return Message{StartDone: &ack}, nil
 }
 
@@ -525,11 +634,266 @@ func (f msgFormat) decodeStartDone(ctx serde.Context, msg *StartDone) (serde.Mes
 		return nil, xerrors.Errorf("couldn't unmarshal public key: %v", err)
 	}
 
+	if msg.Transcript != nil {
+		transcript, err := f.decodeTranscript(*msg.Transcript)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't decode transcript: %v", err)
+		}
+
+		return types.NewStartDoneWithTranscript(point, transcript), nil
+	}
+
 	ack := types.NewStartDone(point)
 
 	return ack, nil
 }
 
+func encodeDeal(in types.Deal) Deal {
+	return Deal{
+		Index:     in.GetIndex(),
+		Signature: in.GetSignature(),
+		EncryptedDeal: EncryptedDeal{
+			DHKey:     in.GetEncryptedDeal().GetDHKey(),
+			Signature: in.GetEncryptedDeal().GetSignature(),
+			Nonce:     in.GetEncryptedDeal().GetNonce(),
+			Cipher:    in.GetEncryptedDeal().GetCipher(),
+		},
+	}
+}
+
+func decodeDeal(deal Deal) types.Deal {
+	return types.NewDeal(
+		deal.Index,
+		deal.Signature,
+		types.NewEncryptedDeal(
+			deal.EncryptedDeal.DHKey,
+			deal.EncryptedDeal.Signature,
+			deal.EncryptedDeal.Nonce,
+			deal.EncryptedDeal.Cipher,
+		),
+	)
+}
+
+func encodeResponse(in types.Response) Response {
+	return Response{
+		Index: in.GetIndex(),
+		Response: DealerResponse{
+			SessionID: in.GetResponse().GetSessionID(),
+			Index:     in.GetResponse().GetIndex(),
+			Status:    in.GetResponse().GetStatus(),
+			Signature: in.GetResponse().GetSignature(),
+		},
+	}
+}
+
+func decodeResponse(resp Response) types.Response {
+	return types.NewResponse(
+		resp.Index,
+		types.NewDealerResponse(
+			resp.Response.Index,
+			resp.Response.Status,
+			resp.Response.SessionID,
+			resp.Response.Signature,
+		),
+	)
+}
+
+func encodeJustification(msg types.Justification) (Justification, error) {
+	shareV, err := msg.GetShare().V.MarshalBinary()
+	if err != nil {
+		return Justification{}, xerrors.Errorf("couldn't marshal share: %v", err)
+	}
+
+	commitments, err := encodePoints(msg.GetCommitments())
+	if err != nil {
+		return Justification{}, xerrors.Errorf("couldn't marshal commitments: %v", err)
+	}
+
+	j := Justification{
+		Dealer:      msg.GetDealer(),
+		SessionID:   msg.GetSessionID(),
+		Index:       msg.GetIndex(),
+		ShareI:      msg.GetShare().I,
+		ShareV:      shareV,
+		Threshold:   msg.GetThreshold(),
+		Commitments: commitments,
+		Signature:   msg.GetSignature(),
+	}
+
+	return j, nil
+}
+
+func (f msgFormat) decodeJustification(j Justification) (types.Justification, error) {
+	shareV := f.suite.Scalar()
+	err := shareV.UnmarshalBinary(j.ShareV)
+	if err != nil {
+		return types.Justification{}, xerrors.Errorf("couldn't unmarshal share: %v", err)
+	}
+
+	commitments, err := f.decodePoints(j.Commitments)
+	if err != nil {
+		return types.Justification{}, xerrors.Errorf("couldn't unmarshal commitments: %v", err)
+	}
+
+	justification := types.NewJustification(
+		j.Dealer,
+		j.SessionID,
+		j.Index,
+		&share.PriShare{I: j.ShareI, V: shareV},
+		j.Threshold,
+		commitments,
+		j.Signature,
+	)
+
+	return justification, nil
+}
+
+func encodeTranscript(msg types.Transcript) (Transcript, error) {
+	pubkeys, err := encodePoints(msg.GetPublicKeys())
+	if err != nil {
+		return Transcript{}, xerrors.Errorf("couldn't marshal public keys: %v", err)
+	}
+
+	deals := make([][]Deal, len(msg.GetDeals()))
+	for i, received := range msg.GetDeals() {
+		deals[i] = make([]Deal, len(received))
+		for j, deal := range received {
+			deals[i][j] = encodeDeal(deal)
+		}
+	}
+
+	resps := make([]Response, len(msg.GetResponses()))
+	for i, resp := range msg.GetResponses() {
+		resps[i] = encodeResponse(resp)
+	}
+
+	justifs := make([]Justification, len(msg.GetJustifications()))
+	for i, justif := range msg.GetJustifications() {
+		justifs[i], err = encodeJustification(justif)
+		if err != nil {
+			return Transcript{}, xerrors.Errorf("couldn't encode justification: %v", err)
+		}
+	}
+
+	commitments := make([][]PublicKey, len(msg.GetCommitments()))
+	for i, points := range msg.GetCommitments() {
+		if points == nil {
+			continue
+		}
+
+		commitments[i], err = encodePoints(points)
+		if err != nil {
+			return Transcript{}, xerrors.Errorf("couldn't marshal commitments: %v", err)
+		}
+	}
+
+	pubkey, err := msg.GetPublicKey().MarshalBinary()
+	if err != nil {
+		return Transcript{}, xerrors.Errorf("couldn't marshal public key: %v", err)
+	}
+
+	transcript := Transcript{
+		Threshold:      msg.GetThreshold(),
+		PublicKeys:     pubkeys,
+		Deals:          deals,
+		Responses:      resps,
+		Justifications: justifs,
+		Commitments:    commitments,
+		Qualified:      msg.GetQualified(),
+		PublicKey:      pubkey,
+	}
+
+	return transcript, nil
+}
+
+func (f msgFormat) decodeTranscript(transcript Transcript) (types.Transcript, error) {
+	pubkeys, err := f.decodePoints(transcript.PublicKeys)
+	if err != nil {
+		return types.Transcript{}, xerrors.Errorf("couldn't unmarshal public keys: %v", err)
+	}
+
+	deals := make([][]types.Deal, len(transcript.Deals))
+	for i, received := range transcript.Deals {
+		deals[i] = make([]types.Deal, len(received))
+		for j, deal := range received {
+			deals[i][j] = decodeDeal(deal)
+		}
+	}
+
+	resps := make([]types.Response, len(transcript.Responses))
+	for i, resp := range transcript.Responses {
+		resps[i] = decodeResponse(resp)
+	}
+
+	justifs := make([]types.Justification, len(transcript.Justifications))
+	for i, justif := range transcript.Justifications {
+		justifs[i], err = f.decodeJustification(justif)
+		if err != nil {
+			return types.Transcript{}, xerrors.Errorf("couldn't decode justification: %v", err)
+		}
+	}
+
+	commitments := make([][]kyber.Point, len(transcript.Commitments))
+	for i, points := range transcript.Commitments {
+		if points == nil {
+			continue
+		}
+
+		commitments[i], err = f.decodePoints(points)
+		if err != nil {
+			return types.Transcript{}, xerrors.Errorf("couldn't unmarshal commitments: %v", err)
+		}
+	}
+
+	pubkey := f.suite.Point()
+	err = pubkey.UnmarshalBinary(transcript.PublicKey)
+	if err != nil {
+		return types.Transcript{}, xerrors.Errorf("couldn't unmarshal public key: %v", err)
+	}
+
+	msg := types.NewTranscript(
+		transcript.Threshold,
+		pubkeys,
+		deals,
+		resps,
+		justifs,
+		commitments,
+		transcript.Qualified,
+		pubkey,
+	)
+
+	return msg, nil
+}
+
+func encodePoints(points []kyber.Point) ([]PublicKey, error) {
+	res := make([]PublicKey, len(points))
+	for i, point := range points {
+		data, err := point.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't marshal point: %v", err)
+		}
+
+		res[i] = data
+	}
+
+	return res, nil
+}
+
+func (f msgFormat) decodePoints(data []PublicKey) ([]kyber.Point, error) {
+	points := make([]kyber.Point, len(data))
+	for i, buf := range data {
+		point := f.suite.Point()
+		err := point.UnmarshalBinary(buf)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't unmarshal point: %v", err)
+		}
+
+		points[i] = point
+	}
+
+	return points, nil
+}
+
 func encodeDecryptRequest(msg types.DecryptRequest) (Message, error) {
 	k, err := msg.GetK().MarshalBinary()
 	if err != nil {
@@ -579,8 +943,9 @@ func encodeReencryptRequest(msg types.ReencryptRequest) (Message, error) {
 	}
 
 	req := ReencryptRequest{
-		K:    k,
-		PubK: pubk,
+		K:     k,
+		PubK:  pubk,
+		Proof: msg.GetProof(),
 	}
 
 	return Message{ReencryptRequest: &req}, nil
@@ -739,9 +1104,21 @@ func encodeDecryptReply(msg types.DecryptReply) (Message, error) {
 		return Message{}, xerrors.Errorf("couldn't marshal V: %v", err)
 	}
 
+	e, err := msg.GetE().MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal E: %v", err)
+	}
+
+	f, err := msg.GetF().MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal F: %v", err)
+	}
+
 	resp := DecryptReply{
 		V: v,
 		I: msg.GetI(),
+		E: e,
+		F: f,
 	}
 
 	return Message{DecryptReply: &resp}, nil
@@ -754,7 +1131,19 @@ func (f msgFormat) decodeDecryptReply(ctx serde.Context, msg *DecryptReply) (ser
 		return nil, xerrors.Errorf("couldn't unmarshal V: %v", err)
 	}
 
-	resp := types.NewDecryptReply(msg.I, v)
+	e := f.suite.Scalar()
+	err = e.UnmarshalBinary(msg.E)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal E: %v", err)
+	}
+
+	fs := f.suite.Scalar()
+	err = fs.UnmarshalBinary(msg.F)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal F: %v", err)
+	}
+
+	resp := types.NewDecryptReply(msg.I, v, e, fs)
 
 	return resp, nil
 }
@@ -875,8 +1264,9 @@ func (f msgFormat) decodeReencryptRequest(ctx serde.Context, request *ReencryptR
 	}
 
 	resp := types.ReencryptRequest{
-		K:    k,
-		PubK: pubk,
+		K:     k,
+		PubK:  pubk,
+		Proof: request.Proof,
 	}
 
 	return resp, nil
@@ -920,3 +1310,199 @@ func (f msgFormat) decodeReencryptReply(ctx serde.Context, reply *ReencryptReply
 
 	return resp, nil
 }
+
+func encodeBeaconReply(msg types.BeaconReply) (Message, error) {
+	share := msg.GetShare()
+
+	v, err := share.V.MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal V: %v", err)
+	}
+
+	x, err := share.X.MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal X: %v", err)
+	}
+
+	e, err := share.E.MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal E: %v", err)
+	}
+
+	f, err := share.F.MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal F: %v", err)
+	}
+
+	reply := BeaconReply{
+		Share: BeaconShare{
+			I: share.I,
+			V: v,
+			X: x,
+			E: e,
+			F: f,
+		},
+	}
+
+	return Message{BeaconReply: &reply}, nil
+}
+
+func (f msgFormat) decodeBeaconReply(ctx serde.Context, reply *BeaconReply) (serde.Message, error) {
+	v := f.suite.Point()
+	err := v.UnmarshalBinary(reply.Share.V)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal V: %v", err)
+	}
+
+	x := f.suite.Point()
+	err = x.UnmarshalBinary(reply.Share.X)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal X: %v", err)
+	}
+
+	e := f.suite.Scalar()
+	err = e.UnmarshalBinary(reply.Share.E)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal E: %v", err)
+	}
+
+	fs := f.suite.Scalar()
+	err = fs.UnmarshalBinary(reply.Share.F)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal F: %v", err)
+	}
+
+	share := types.BeaconShare{
+		I: reply.Share.I,
+		V: v,
+		X: x,
+		E: e,
+		F: fs,
+	}
+
+	return types.NewBeaconReply(share), nil
+}
+
+func encodePublicKeyReply(msg types.PublicKeyReply) (Message, error) {
+	pubkey, err := msg.GetPublicKey().MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal public key: %v", err)
+	}
+
+	return Message{PublicKeyReply: &PublicKeyReply{PublicKey: pubkey}}, nil
+}
+
+func (f msgFormat) decodePublicKeyReply(reply *PublicKeyReply) (serde.Message, error) {
+	pubkey := f.suite.Point()
+	err := pubkey.UnmarshalBinary(reply.PublicKey)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal public key: %v", err)
+	}
+
+	return types.NewPublicKeyReply(pubkey), nil
+}
+
+func encodeSignCommitment(commitment types.SignCommitment) (SignCommitment, error) {
+	d, err := commitment.D.MarshalBinary()
+	if err != nil {
+		return SignCommitment{}, xerrors.Errorf("couldn't marshal D: %v", err)
+	}
+
+	e, err := commitment.E.MarshalBinary()
+	if err != nil {
+		return SignCommitment{}, xerrors.Errorf("couldn't marshal E: %v", err)
+	}
+
+	return SignCommitment{I: commitment.I, D: d, E: e}, nil
+}
+
+func (f msgFormat) decodeSignCommitment(commitment SignCommitment) (types.SignCommitment, error) {
+	d := f.suite.Point()
+	err := d.UnmarshalBinary(commitment.D)
+	if err != nil {
+		return types.SignCommitment{}, xerrors.Errorf("couldn't unmarshal D: %v", err)
+	}
+
+	e := f.suite.Point()
+	err = e.UnmarshalBinary(commitment.E)
+	if err != nil {
+		return types.SignCommitment{}, xerrors.Errorf("couldn't unmarshal E: %v", err)
+	}
+
+	return types.SignCommitment{I: commitment.I, D: d, E: e}, nil
+}
+
+func encodeSignCommitReply(msg types.SignCommitReply) (Message, error) {
+	commitment, err := encodeSignCommitment(msg.GetCommitment())
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't encode commitment: %v", err)
+	}
+
+	return Message{SignCommitReply: &SignCommitReply{Commitment: commitment}}, nil
+}
+
+func (f msgFormat) decodeSignCommitReply(ctx serde.Context,
+	reply *SignCommitReply) (serde.Message, error) {
+
+	commitment, err := f.decodeSignCommitment(reply.Commitment)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode commitment: %v", err)
+	}
+
+	return types.NewSignCommitReply(commitment), nil
+}
+
+func encodeSignRequest(msg types.SignRequest) (Message, error) {
+	commitments := make([]SignCommitment, len(msg.GetCommitments()))
+
+	for i, c := range msg.GetCommitments() {
+		commitment, err := encodeSignCommitment(c)
+		if err != nil {
+			return Message{}, xerrors.Errorf("couldn't encode commitment: %v", err)
+		}
+
+		commitments[i] = commitment
+	}
+
+	req := SignRequest{
+		Session:     msg.GetSession(),
+		Message:     msg.GetMessage(),
+		Commitments: commitments,
+	}
+
+	return Message{SignRequest: &req}, nil
+}
+
+func (f msgFormat) decodeSignRequest(ctx serde.Context, req *SignRequest) (serde.Message, error) {
+	commitments := make([]types.SignCommitment, len(req.Commitments))
+
+	for i, c := range req.Commitments {
+		commitment, err := f.decodeSignCommitment(c)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't decode commitment: %v", err)
+		}
+
+		commitments[i] = commitment
+	}
+
+	return types.NewSignRequest(req.Session, req.Message, commitments), nil
+}
+
+func encodeSignReply(msg types.SignReply) (Message, error) {
+	z, err := msg.GetShare().MarshalBinary()
+	if err != nil {
+		return Message{}, xerrors.Errorf("couldn't marshal Z: %v", err)
+	}
+
+	return Message{SignReply: &SignReply{I: msg.GetIndex(), Z: z}}, nil
+}
+
+func (f msgFormat) decodeSignReply(ctx serde.Context, reply *SignReply) (serde.Message, error) {
+	z := f.suite.Scalar()
+	err := z.UnmarshalBinary(reply.Z)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal Z: %v", err)
+	}
+
+	return types.NewSignReply(reply.I, z), nil
+}
@@ -205,18 +205,28 @@ func TestMessageFormat_EncodeVerifiableDecryptRequest(t *testing.T) {
 }
 
 func TestMessageFormat_EncodeDecryptReply(t *testing.T) {
-	resp := types.NewDecryptReply(5, suite.Point())
+	resp := types.NewDecryptReply(5, suite.Point(), suite.Scalar(), suite.Scalar())
 
 	format := newMsgFormat()
 	ctx := serde.NewContext(fake.ContextEngine{})
 
 	data, err := format.Encode(ctx, resp)
 	require.NoError(t, err)
-	require.Regexp(t, `{(("DecryptReply":{"V":"[^"]+","I":5}|"\w+":null),?)+}`, string(data))
+	require.Regexp(t, `{(("DecryptReply":{"V":"[^"]+","I":5,"E":"[^"]+","F":"[^"]+"}|"\w+":null),?)+}`, string(data))
 
 	resp.V = badPoint{}
 	_, err = format.Encode(ctx, resp)
 	require.EqualError(t, err, fake.Err("failed to encode message: couldn't marshal V"))
+
+	resp.V = suite.Point()
+	resp.E = badScallar{}
+	_, err = format.Encode(ctx, resp)
+	require.EqualError(t, err, fake.Err("failed to encode message: couldn't marshal E"))
+
+	resp.E = suite.Scalar()
+	resp.F = badScallar{}
+	_, err = format.Encode(ctx, resp)
+	require.EqualError(t, err, fake.Err("failed to encode message: couldn't marshal F"))
 }
 
 func TestMessageFormat_EncodeReencryptReply(t *testing.T) {
@@ -380,7 +390,8 @@ func TestMessageFormat_DecodeDecryptReply(t *testing.T) {
 	format := newMsgFormat()
 	ctx := serde.NewContext(fake.ContextEngine{})
 
-	data := []byte(fmt.Sprintf(`{"DecryptReply":{"I":4,"V":"%s"}}`, testPoint))
+	data := []byte(fmt.Sprintf(`{"DecryptReply":{"I":4,"V":"%s","E":"%s","F":"%s"}}`,
+		testPoint, testPoint, testPoint))
 	reply, err := format.Decode(ctx, data)
 	require.NoError(t, err)
 	require.IsType(t, types.DecryptReply{}, reply)
@@ -390,6 +401,14 @@ func TestMessageFormat_DecodeDecryptReply(t *testing.T) {
 	require.EqualError(t, err,
 		"couldn't unmarshal V: invalid Ed25519 curve point")
 
+	data = []byte(fmt.Sprintf(`{"DecryptReply":{"V":"%s"}}`, testPoint))
+	_, err = format.Decode(ctx, data)
+	require.EqualError(t, err, "couldn't unmarshal E: wrong size buffer")
+
+	data = []byte(fmt.Sprintf(`{"DecryptReply":{"V":"%s","E":"%s"}}`, testPoint, testPoint))
+	_, err = format.Decode(ctx, data)
+	require.EqualError(t, err, "couldn't unmarshal F: wrong size buffer")
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("couldn't deserialize message"))
 
@@ -401,10 +420,12 @@ func TestMessageFormat_DecodeReencryptRequest(t *testing.T) {
 	format := newMsgFormat()
 	ctx := serde.NewContext(fake.ContextEngine{})
 
-	data := []byte(fmt.Sprintf(`{"ReencryptRequest":{"K":"%s","PubK":"%s"}}`, testPoint, testPoint))
+	data := []byte(fmt.Sprintf(`{"ReencryptRequest":{"K":"%s","PubK":"%s","Proof":"cHJvb2Y="}}`,
+		testPoint, testPoint))
 	request, err := format.Decode(ctx, data)
 	require.NoError(t, err)
 	require.IsType(t, types.ReencryptRequest{}, request)
+	require.Equal(t, []byte("proof"), request.(types.ReencryptRequest).GetProof())
 
 	data = []byte(fmt.Sprintf(`{"ReencryptRequest":{"K":[],"PubK":"%s"}}`, testPoint))
 	_, err = format.Decode(ctx, data)
@@ -624,6 +645,369 @@ func TestMessageFormat_DecodeVerifiableDecryptReply(t *testing.T) {
 	require.EqualError(t, err, "couldn't unmarshal H_i: invalid Ed25519 curve point")
 }
 
+func TestMessageFormat_BeaconRequest(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	req := types.NewBeaconRequest([]byte{1, 2, 3})
+
+	data, err := format.Encode(ctx, req)
+	require.NoError(t, err)
+	require.Regexp(t, `"BeaconRequest":{"Input":"AQID"}`, string(data))
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, req, msg)
+}
+
+func TestMessageFormat_EncodeBeaconReply(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	check := func(attr string, share types.BeaconShare) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := format.Encode(ctx, types.NewBeaconReply(share))
+			require.EqualError(t, err,
+				fake.Err("failed to encode message: couldn't marshal "+attr))
+		}
+	}
+
+	t.Run("V", check("V", types.BeaconShare{V: badPoint{}}))
+	t.Run("X", check("X", types.BeaconShare{V: suite.Point(), X: badPoint{}}))
+	t.Run("E", check("E", types.BeaconShare{
+		V: suite.Point(),
+		X: suite.Point(),
+		E: badScallar{},
+	}))
+	t.Run("F", check("F", types.BeaconShare{
+		V: suite.Point(),
+		X: suite.Point(),
+		E: suite.Scalar(),
+		F: badScallar{},
+	}))
+}
+
+func TestMessageFormat_DecodeBeaconReply(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	share := types.BeaconShare{
+		I: 3,
+		V: suite.Point().Pick(suite.RandomStream()),
+		X: suite.Point().Pick(suite.RandomStream()),
+		E: suite.Scalar().Pick(suite.RandomStream()),
+		F: suite.Scalar().Pick(suite.RandomStream()),
+	}
+
+	data, err := format.Encode(ctx, types.NewBeaconReply(share))
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+
+	decoded := msg.(types.BeaconReply).GetShare()
+	require.Equal(t, int64(3), decoded.I)
+	require.True(t, share.V.Equal(decoded.V))
+	require.True(t, share.X.Equal(decoded.X))
+	require.True(t, share.E.Equal(decoded.E))
+	require.True(t, share.F.Equal(decoded.F))
+
+	_, err = format.Decode(ctx, []byte(`{"BeaconReply":{"Share":{}}}`))
+	require.EqualError(t, err, "couldn't unmarshal V: invalid Ed25519 curve point")
+
+	shareJSON := fmt.Sprintf(`{"V":"%s"}`, testPoint)
+	_, err = format.Decode(ctx,
+		[]byte(fmt.Sprintf(`{"BeaconReply":{"Share":%s}}`, shareJSON)))
+	require.EqualError(t, err, "couldn't unmarshal X: invalid Ed25519 curve point")
+
+	shareJSON = fmt.Sprintf(`{"V":"%s","X":"%s"}`, testPoint, testPoint)
+	_, err = format.Decode(ctx,
+		[]byte(fmt.Sprintf(`{"BeaconReply":{"Share":%s}}`, shareJSON)))
+	require.EqualError(t, err, "couldn't unmarshal E: wrong size buffer")
+
+	shareJSON = fmt.Sprintf(`{"V":"%s","X":"%s","E":"%s"}`, testPoint, testPoint, testPoint)
+	_, err = format.Decode(ctx,
+		[]byte(fmt.Sprintf(`{"BeaconReply":{"Share":%s}}`, shareJSON)))
+	require.EqualError(t, err, "couldn't unmarshal F: wrong size buffer")
+}
+
+func TestMessageFormat_PublicKeyRequest(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, types.NewPublicKeyRequest())
+	require.NoError(t, err)
+	require.Regexp(t, `"PublicKeyRequest":{}`, string(data))
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, types.NewPublicKeyRequest(), msg)
+}
+
+func TestMessageFormat_PublicKeyReply(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	pubkey := suite.Point().Pick(suite.RandomStream())
+
+	data, err := format.Encode(ctx, types.NewPublicKeyReply(pubkey))
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.True(t, pubkey.Equal(msg.(types.PublicKeyReply).GetPublicKey()))
+
+	_, err = format.Encode(ctx, types.NewPublicKeyReply(badPoint{}))
+	require.EqualError(t, err,
+		fake.Err("failed to encode message: couldn't marshal public key"))
+
+	_, err = format.Decode(ctx, []byte(`{"PublicKeyReply":{}}`))
+	require.EqualError(t, err, "couldn't unmarshal public key: invalid Ed25519 curve point")
+}
+
+func TestMessageFormat_SignCommitRequest(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	req := types.NewSignCommitRequest([]byte{1, 2, 3})
+
+	data, err := format.Encode(ctx, req)
+	require.NoError(t, err)
+	require.Regexp(t, `"SignCommitRequest":{"Session":"AQID"}`, string(data))
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, req, msg)
+}
+
+func TestMessageFormat_SignCommitReply(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	commitment := types.SignCommitment{
+		I: 2,
+		D: suite.Point().Pick(suite.RandomStream()),
+		E: suite.Point().Pick(suite.RandomStream()),
+	}
+
+	data, err := format.Encode(ctx, types.NewSignCommitReply(commitment))
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+
+	decoded := msg.(types.SignCommitReply).GetCommitment()
+	require.Equal(t, int64(2), decoded.I)
+	require.True(t, commitment.D.Equal(decoded.D))
+	require.True(t, commitment.E.Equal(decoded.E))
+
+	_, err = format.Encode(ctx, types.NewSignCommitReply(types.SignCommitment{D: badPoint{}}))
+	require.EqualError(t, err,
+		fake.Err("failed to encode message: couldn't encode commitment: couldn't marshal D"))
+
+	_, err = format.Encode(ctx, types.NewSignCommitReply(types.SignCommitment{
+		D: suite.Point(),
+		E: badPoint{},
+	}))
+	require.EqualError(t, err,
+		fake.Err("failed to encode message: couldn't encode commitment: couldn't marshal E"))
+
+	_, err = format.Decode(ctx, []byte(`{"SignCommitReply":{"Commitment":{}}}`))
+	require.EqualError(t, err,
+		"couldn't decode commitment: couldn't unmarshal D: invalid Ed25519 curve point")
+
+	_, err = format.Decode(ctx,
+		[]byte(fmt.Sprintf(`{"SignCommitReply":{"Commitment":{"D":"%s"}}}`, testPoint)))
+	require.EqualError(t, err,
+		"couldn't decode commitment: couldn't unmarshal E: invalid Ed25519 curve point")
+}
+
+func TestMessageFormat_SignRequest(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	commitment := types.SignCommitment{
+		I: 1,
+		D: suite.Point().Pick(suite.RandomStream()),
+		E: suite.Point().Pick(suite.RandomStream()),
+	}
+
+	req := types.NewSignRequest([]byte{1}, []byte{2}, []types.SignCommitment{commitment})
+
+	data, err := format.Encode(ctx, req)
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+
+	decoded := msg.(types.SignRequest)
+	require.Equal(t, []byte{1}, decoded.GetSession())
+	require.Equal(t, []byte{2}, decoded.GetMessage())
+	require.Len(t, decoded.GetCommitments(), 1)
+	require.Equal(t, int64(1), decoded.GetCommitments()[0].I)
+	require.True(t, commitment.D.Equal(decoded.GetCommitments()[0].D))
+	require.True(t, commitment.E.Equal(decoded.GetCommitments()[0].E))
+
+	req = types.NewSignRequest(nil, nil, []types.SignCommitment{{D: badPoint{}}})
+
+	_, err = format.Encode(ctx, req)
+	require.EqualError(t, err,
+		fake.Err("failed to encode message: couldn't encode commitment: couldn't marshal D"))
+
+	_, err = format.Decode(ctx, []byte(`{"SignRequest":{"Commitments":[{}]}}`))
+	require.EqualError(t, err,
+		"couldn't decode commitment: couldn't unmarshal D: invalid Ed25519 curve point")
+}
+
+func TestMessageFormat_SignReply(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	reply := types.NewSignReply(3, suite.Scalar().Pick(suite.RandomStream()))
+
+	data, err := format.Encode(ctx, reply)
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+
+	decoded := msg.(types.SignReply)
+	require.Equal(t, int64(3), decoded.GetIndex())
+	require.True(t, reply.GetShare().Equal(decoded.GetShare()))
+
+	_, err = format.Encode(ctx, types.NewSignReply(0, badScallar{}))
+	require.EqualError(t, err, fake.Err("failed to encode message: couldn't marshal Z"))
+
+	_, err = format.Decode(ctx, []byte(`{"SignReply":{}}`))
+	require.EqualError(t, err, "couldn't unmarshal Z: wrong size buffer")
+}
+
+func TestMessageFormat_Justification(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	justif := makeJustification()
+
+	data, err := format.Encode(ctx, justif)
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+
+	decoded := msg.(types.Justification)
+	require.Equal(t, justif.GetDealer(), decoded.GetDealer())
+	require.Equal(t, justif.GetSessionID(), decoded.GetSessionID())
+	require.Equal(t, justif.GetIndex(), decoded.GetIndex())
+	require.Equal(t, justif.GetShare().I, decoded.GetShare().I)
+	require.True(t, justif.GetShare().V.Equal(decoded.GetShare().V))
+	require.Equal(t, justif.GetThreshold(), decoded.GetThreshold())
+	require.Len(t, decoded.GetCommitments(), 1)
+	require.True(t, justif.GetCommitments()[0].Equal(decoded.GetCommitments()[0]))
+	require.Equal(t, justif.GetSignature(), decoded.GetSignature())
+
+	_, err = format.Encode(ctx, types.NewJustification(0, nil, 0,
+		&share.PriShare{V: badScallar{}}, 0, nil, nil))
+	require.EqualError(t, err, fake.Err("failed to encode message: couldn't marshal share"))
+
+	_, err = format.Encode(ctx, types.NewJustification(0, nil, 0,
+		justif.GetShare(), 0, []kyber.Point{badPoint{}}, nil))
+	require.EqualError(t, err, fake.Err("failed to encode message: "+
+		"couldn't marshal commitments: couldn't marshal point"))
+
+	_, err = format.Decode(ctx, []byte(`{"Justification":{}}`))
+	require.EqualError(t, err, "couldn't unmarshal share: wrong size buffer")
+
+	data = []byte(fmt.Sprintf(`{"Justification":{"ShareV":"%s","Commitments":[""]}}`,
+		testPoint))
+	_, err = format.Decode(ctx, data)
+	require.EqualError(t, err, "couldn't unmarshal commitments: "+
+		"couldn't unmarshal point: invalid Ed25519 curve point")
+}
+
+func TestMessageFormat_Transcript(t *testing.T) {
+	format := newMsgFormat()
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	point := suite.Point().Pick(suite.RandomStream())
+
+	transcript := types.NewTranscript(
+		2,
+		[]kyber.Point{point, point},
+		[][]types.Deal{{types.NewDeal(1, []byte{1},
+			types.NewEncryptedDeal([]byte{1}, []byte{2}, []byte{3}, []byte{4}))}, {}},
+		[]types.Response{types.NewResponse(1,
+			types.NewDealerResponse(0, true, []byte{2}, []byte{3}))},
+		[]types.Justification{makeJustification()},
+		[][]kyber.Point{{point}, nil},
+		[]int{0},
+		point,
+	)
+
+	data, err := format.Encode(ctx, types.NewStartDoneWithTranscript(point, transcript))
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+
+	decoded := msg.(types.StartDone).GetTranscript()
+	require.NotNil(t, decoded)
+	require.Equal(t, 2, decoded.GetThreshold())
+	require.Len(t, decoded.GetPublicKeys(), 2)
+	require.Equal(t, transcript.GetDeals(), decoded.GetDeals())
+	require.Equal(t, transcript.GetResponses(), decoded.GetResponses())
+	require.Len(t, decoded.GetJustifications(), 1)
+	require.Len(t, decoded.GetCommitments(), 2)
+	require.True(t, point.Equal(decoded.GetCommitments()[0][0]))
+	require.Nil(t, decoded.GetCommitments()[1])
+	require.Equal(t, []int{0}, decoded.GetQualified())
+	require.True(t, point.Equal(decoded.GetPublicKey()))
+
+	_, err = format.Encode(ctx, types.NewTranscript(0, []kyber.Point{badPoint{}},
+		nil, nil, nil, nil, nil, nil))
+	require.EqualError(t, err, fake.Err("failed to encode message: "+
+		"couldn't marshal public keys: couldn't marshal point"))
+
+	_, err = format.Encode(ctx, types.NewTranscript(0, nil, nil, nil,
+		[]types.Justification{types.NewJustification(0, nil, 0,
+			&share.PriShare{V: badScallar{}}, 0, nil, nil)}, nil, nil, nil))
+	require.EqualError(t, err, fake.Err("failed to encode message: "+
+		"couldn't encode justification: couldn't marshal share"))
+
+	_, err = format.Encode(ctx, types.NewTranscript(0, nil, nil, nil, nil,
+		[][]kyber.Point{{badPoint{}}}, nil, nil))
+	require.EqualError(t, err, fake.Err("failed to encode message: "+
+		"couldn't marshal commitments: couldn't marshal point"))
+
+	_, err = format.Encode(ctx, types.NewTranscript(0, nil, nil, nil, nil, nil, nil, badPoint{}))
+	require.EqualError(t, err, fake.Err("failed to encode message: "+
+		"couldn't marshal public key"))
+
+	_, err = format.Encode(ctx, types.NewStartDoneWithTranscript(point,
+		types.NewTranscript(0, nil, nil, nil, nil, nil, nil, badPoint{})))
+	require.EqualError(t, err, fake.Err("failed to encode message: "+
+		"couldn't encode transcript: couldn't marshal public key"))
+
+	_, err = format.Decode(ctx, []byte(`{"Transcript":{"PublicKeys":[""]}}`))
+	require.EqualError(t, err, "couldn't unmarshal public keys: "+
+		"couldn't unmarshal point: invalid Ed25519 curve point")
+
+	_, err = format.Decode(ctx, []byte(`{"Transcript":{"Justifications":[{}]}}`))
+	require.EqualError(t, err, "couldn't decode justification: "+
+		"couldn't unmarshal share: wrong size buffer")
+
+	_, err = format.Decode(ctx, []byte(`{"Transcript":{"Commitments":[[""]]}}`))
+	require.EqualError(t, err, "couldn't unmarshal commitments: "+
+		"couldn't unmarshal point: invalid Ed25519 curve point")
+
+	_, err = format.Decode(ctx, []byte(`{"Transcript":{}}`))
+	require.EqualError(t, err, "couldn't unmarshal public key: invalid Ed25519 curve point")
+
+	data = []byte(fmt.Sprintf(`{"StartDone":{"PublicKey":"%s","Transcript":{}}}`, testPoint))
+	_, err = format.Decode(ctx, data)
+	require.EqualError(t, err, "couldn't decode transcript: "+
+		"couldn't unmarshal public key: invalid Ed25519 curve point")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -644,3 +1028,15 @@ type badScallar struct {
 func (s badScallar) MarshalBinary() ([]byte, error) {
 	return nil, fake.GetError()
 }
+
+func makeJustification() types.Justification {
+	return types.NewJustification(
+		1,
+		[]byte{1, 2},
+		2,
+		&share.PriShare{I: 2, V: suite.Scalar().Pick(suite.RandomStream())},
+		3,
+		[]kyber.Point{suite.Point().Pick(suite.RandomStream())},
+		[]byte{3},
+	)
+}
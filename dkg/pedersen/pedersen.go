@@ -1,12 +1,16 @@
 package pedersen
 
 import (
+	"crypto/cipher"
 	"crypto/sha256"
+	"io"
 	"runtime"
 	"sync"
 	"time"
 
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/core/store/kv"
 
 	"go.dedis.ch/dela/crypto/ed25519"
 	"go.dedis.ch/dela/dkg"
@@ -19,7 +23,6 @@ import (
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/share"
 	"go.dedis.ch/kyber/v3/suites"
-	"go.dedis.ch/kyber/v3/util/random"
 	"golang.org/x/net/context"
 	"golang.org/x/xerrors"
 )
@@ -66,34 +69,247 @@ const (
 //
 // - implements dkg.DKG
 type Pedersen struct {
-	privKey kyber.Scalar
-	mino    mino.Mino
-	factory serde.Factory
+	privKey    kyber.Scalar
+	mino       mino.Mino
+	factory    serde.Factory
+	store      *storage
+	rpcName    string
+	authorizer Authorizer
+	clock      clock.Clock
+	random     cipher.Stream
+
+	phaseTimeout time.Duration
+}
+
+// Authorizer is the interface a node uses to decide if it releases its share
+// for a reencryption.
+type Authorizer interface {
+	// Authorize returns nil if the proof allows the reencryption of the
+	// ciphertext with K to pubk.
+	Authorize(proof []byte, K, pubk kyber.Point) error
+}
+
+// template is the configuration of a DKG that options can change.
+type template struct {
+	id         string
+	authorizer Authorizer
+	clock      clock.Clock
+	privKey    kyber.Scalar
+	random     io.Reader
+
+	phaseTimeout time.Duration
+}
+
+// Option is the type of option to configure a DKG.
+type Option func(*template)
+
+// WithID is an option to set the name of the DKG instance. Each instance has
+// its own RPC and its own values in the database so that several DKGs, with
+// different participants, can run on the same nodes. The name must be the same
+// on every participant.
+func WithID(id string) Option {
+	return func(tmpl *template) {
+		tmpl.id = id
+	}
+}
+
+// WithAuthorizer is an option to set the authorizer of the decryptions. The
+// node refuses to send its share when the proof of a reencryption is rejected,
+// and the plain and verifiable decryptions are disabled. The signatures and
+// the beacons are not gated by the authorizer.
+func WithAuthorizer(a Authorizer) Option {
+	return func(tmpl *template) {
+		tmpl.authorizer = a
+	}
+}
+
+// WithClock is an option to set the clock of the timeouts of the protocols,
+// which is the system clock by default.
+func WithClock(c clock.Clock) Option {
+	return func(tmpl *template) {
+		tmpl.clock = c
+	}
+}
+
+// WithPhaseTimeout is an option to set the time a node waits for the deals,
+// and then for the responses and the justifications of a setup, which is 10
+// minutes by default. The dealers whose deal is not certified by then are
+// excluded.
+func WithPhaseTimeout(d time.Duration) Option {
+	return func(tmpl *template) {
+		tmpl.phaseTimeout = d
+	}
+}
+
+// WithKey is an option to set the private key of the node instead of a random
+// one. It is ignored by a persistent DKG, which keeps its key in the database.
+func WithKey(privKey kyber.Scalar) Option {
+	return func(tmpl *template) {
+		tmpl.privKey = privKey
+	}
+}
+
+// WithRandom is an option to draw the secrets of the setups, the nonces of the
+// signatures and of the proofs, and the ephemeral keys of the encryptions from
+// a stream seeded by the reader instead of the system randomness, so that a
+// simulation can reproduce them. It must only be used in tests.
+func WithRandom(r io.Reader) Option {
+	return func(tmpl *template) {
+		tmpl.random = r
+	}
 }
 
 // NewPedersen returns a new DKG Pedersen factory
-func NewPedersen(m mino.Mino) (*Pedersen, kyber.Point) {
+func NewPedersen(m mino.Mino, opts ...Option) (*Pedersen, kyber.Point) {
+	tmpl := newTemplate(opts)
+
 	factory := types.NewMessageFactory(m.GetAddressFactory())
 
-	privkey := suite.Scalar().Pick(suite.RandomStream())
+	random := newStream(tmpl)
+
+	privkey := tmpl.privKey
+	if privkey == nil {
+		privkey = suite.Scalar().Pick(suiteWith(random).RandomStream())
+	}
+
 	pubkey := suite.Point().Mul(privkey, nil)
 
 	return &Pedersen{
-		privKey: privkey,
-		mino:    m,
-		factory: factory,
+		privKey:    privkey,
+		mino:       m,
+		factory:    factory,
+		rpcName:    tmpl.rpcName(),
+		authorizer: tmpl.authorizer,
+		clock:      tmpl.clock,
+		random:     random,
+
+		phaseTimeout: tmpl.phaseTimeout,
 	}, pubkey
 }
 
+// NewPersistentPedersen returns a new DKG Pedersen factory that keeps its
+// private key and the result of the DKG in the database, so that a node can
+// restart and still use its share. The values are encrypted when the
+// passphrase is not empty.
+func NewPersistentPedersen(m mino.Mino, db kv.DB, passphrase []byte,
+	opts ...Option) (*Pedersen, kyber.Point, error) {
+
+	tmpl := newTemplate(opts)
+
+	store := &storage{
+		db:         db,
+		bucket:     defaultBucket,
+		id:         tmpl.id,
+		passphrase: passphrase,
+		addrFac:    m.GetAddressFactory(),
+	}
+
+	privkey, err := store.loadOrCreateKey()
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to load key: %v", err)
+	}
+
+	pubkey := suite.Point().Mul(privkey, nil)
+
+	return &Pedersen{
+		privKey:    privkey,
+		mino:       m,
+		factory:    types.NewMessageFactory(m.GetAddressFactory()),
+		store:      store,
+		rpcName:    tmpl.rpcName(),
+		authorizer: tmpl.authorizer,
+		clock:      tmpl.clock,
+		random:     newStream(tmpl),
+
+		phaseTimeout: tmpl.phaseTimeout,
+	}, pubkey, nil
+}
+
+func newTemplate(opts []Option) template {
+	tmpl := template{
+		clock: clock.NewSystem(),
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	return tmpl
+}
+
+// withTimeout returns a context that is done once the duration has elapsed on
+// the clock of the actor.
+func (a *Actor) withTimeout(d time.Duration) (context.Context, context.CancelFunc) {
+	return withTimeout(context.Background(), a.clock, d)
+}
+
+// withTimeout returns a copy of the parent context that is done once the
+// duration has elapsed on the clock, or on the system clock if it is nil.
+func withTimeout(ctx context.Context, c clock.Clock,
+	d time.Duration) (context.Context, context.CancelFunc) {
+
+	if c == nil {
+		c = clock.NewSystem()
+	}
+
+	return c.WithTimeout(ctx, d)
+}
+
+// now returns the current time of the clock, or of the system clock if it is
+// nil.
+func now(c clock.Clock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+
+	return c.Now()
+}
+
+// rpcName returns the name of the RPC of the instance. The default instance
+// keeps the original name.
+func (tmpl template) rpcName() string {
+	if tmpl.id == "" {
+		return "dkg"
+	}
+
+	return "dkg-" + tmpl.id
+}
+
+// HasResult returns true if the database contains the result of a previous
+// DKG, in which case the actor is ready as soon as the node listens.
+func (s *Pedersen) HasResult() (bool, error) {
+	if s.store == nil {
+		return false, nil
+	}
+
+	data, err := s.store.read(keyResult)
+	if err != nil {
+		return false, xerrors.Errorf("failed to read result: %v", err)
+	}
+
+	return data != nil, nil
+}
+
 // Listen implements dkg.DKG. It must be called on each node that participates
-// in the DKG. Creates the RPC.
+// in the DKG. Creates the RPC and restores the result of a previous DKG when
+// the factory is persistent.
 func (s *Pedersen) Listen() (dkg.Actor, error) {
-	h := NewHandler(s.privKey, s.mino.GetAddress())
+	h := newHandler(s.privKey, s.mino.GetAddress(), s.authorizer, s.clock,
+		s.phaseTimeout, s.random)
+
+	if s.store != nil {
+		err := h.dkgInstance.restore(s.store)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to restore: %v", err)
+		}
+	}
 
 	a := &Actor{
-		rpc:      mino.MustCreateRPC(s.mino, "dkg", h, s.factory),
+		rpc:      mino.MustCreateRPC(s.mino, s.rpcName, h, s.factory),
 		factory:  s.factory,
 		startRes: h.dkgInstance.getState(),
+		clock:    s.clock,
+		random:   s.random,
 	}
 
 	return a, nil
@@ -109,18 +325,36 @@ type Actor struct {
 	rpc      mino.RPC
 	factory  serde.Factory
 	startRes *state
+	clock    clock.Clock
+	random   cipher.Stream
 }
 
 // Setup implement dkg.Actor. It initializes the DKG.
 func (a *Actor) Setup(coAuth crypto.CollectiveAuthority, threshold int) (kyber.Point, error) {
+	transcript, err := a.SetupWithTranscript(coAuth, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return transcript.GetPublicKey(), nil
+}
+
+// SetupWithTranscript initializes the DKG and returns its transcript, which
+// tells the dealers that are excluded and can be verified offline with
+// VerifyTranscript. The setup succeeds as soon as a threshold of nodes agrees
+// on the public key that follows from the transcript, and the nodes that
+// haven't finished by then are excluded from the result.
+func (a *Actor) SetupWithTranscript(coAuth crypto.CollectiveAuthority,
+	threshold int) (types.Transcript, error) {
 
 	if a.startRes.Done() {
-		return nil, xerrors.Errorf("startRes is already done, only one setup call is allowed")
+		return types.Transcript{}, xerrors.Errorf("startRes is already done, " +
+			"only one setup call is allowed")
 	}
 
 	nbNodes := coAuth.Len()
 	if nbNodes == 0 {
-		return nil, xerrors.Errorf("number of nodes cannot be zero")
+		return types.Transcript{}, xerrors.Errorf("number of nodes cannot be zero")
 	}
 
 	thresholdMin := 2
@@ -129,17 +363,17 @@ func (a *Actor) Setup(coAuth crypto.CollectiveAuthority, threshold int) (kyber.P
 	}
 
 	if threshold < thresholdMin || threshold > nbNodes {
-		return nil, xerrors.Errorf("DKG threshold (%d) needs to be between %d and %d",
-			threshold, thresholdMin, nbNodes)
+		return types.Transcript{}, xerrors.Errorf("DKG threshold (%d) needs to be "+
+			"between %d and %d", threshold, thresholdMin, nbNodes)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	ctx, cancel := a.withTimeout(setupTimeout)
 	defer cancel()
 	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameSetup)
 
 	sender, receiver, err := a.rpc.Stream(ctx, coAuth)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to stream: %v", err)
+		return types.Transcript{}, xerrors.Errorf("failed to stream: %v", err)
 	}
 
 	addrs := make([]mino.Address, 0, coAuth.Len())
@@ -154,7 +388,8 @@ func (a *Actor) Setup(coAuth crypto.CollectiveAuthority, threshold int) (kyber.P
 		pubkey := pubkeyIter.GetNext()
 		edKey, ok := pubkey.(ed25519.PublicKey)
 		if !ok {
-			return nil, xerrors.Errorf("expected ed25519.PublicKey, got '%T'", pubkey)
+			return types.Transcript{}, xerrors.Errorf("expected ed25519.PublicKey, "+
+				"got '%T'", pubkey)
 		}
 
 		pubkeys = append(pubkeys, edKey.GetPoint())
@@ -165,38 +400,98 @@ func (a *Actor) Setup(coAuth crypto.CollectiveAuthority, threshold int) (kyber.P
 	errs := sender.Send(message, addrs...)
 	err = <-errs
 	if err != nil {
-		return nil, xerrors.Errorf("failed to send start: %v", err)
+		return types.Transcript{}, xerrors.Errorf("failed to send start: %v", err)
 	}
 
-	dkgPubKeys := make([]kyber.Point, len(addrs))
+	dkgPubKeys := make([]kyber.Point, 0, len(addrs))
+	parts := make([]types.Transcript, len(addrs))
+	received := make([]bool, len(addrs))
 
-	for i := 0; i < len(addrs); i++ {
+	var transcript types.Transcript
+
+	// The setup is over once a threshold of nodes agrees with the transcript,
+	// so that it doesn't wait for the nodes that are down.
+	for agreed := false; !agreed; {
+		if len(dkgPubKeys) == len(addrs) {
+			return types.Transcript{}, xerrors.Errorf("the public keys does not match: %v",
+				dkgPubKeys)
+		}
 
-		addr, msg, err := receiver.Recv(context.Background())
+		addr, msg, err := receiver.Recv(ctx)
 		if err != nil {
-			return nil, xerrors.Errorf("got an error from '%s' while "+
+			return types.Transcript{}, xerrors.Errorf("got an error from '%s' while "+
 				"receiving: %v", addr, err)
 		}
 
 		doneMsg, ok := msg.(types.StartDone)
 		if !ok {
-			return nil, xerrors.Errorf("expected to receive a Done message, but "+
+			return types.Transcript{}, xerrors.Errorf("expected to receive a Done message, but "+
 				"go the following: %T", msg)
 		}
 
 		dela.Logger.Info().Msgf("node %q done", addr.String())
 
-		dkgPubKeys[i] = doneMsg.GetPublicKey()
+		if doneMsg.GetTranscript() == nil {
+			return types.Transcript{}, xerrors.Errorf("missing transcript from '%s'", addr)
+		}
 
-		// this is a simple check that every node sends back the same DKG pub
-		// key.
-		// TODO: handle the situation where a pub key is not the same
-		if i != 0 && !dkgPubKeys[i-1].Equal(doneMsg.GetPublicKey()) {
-			return nil, xerrors.Errorf("the public keys does not match: %v", dkgPubKeys)
+		index := indexOf(addrs, addr)
+		if index < 0 || received[index] {
+			return types.Transcript{}, xerrors.Errorf("unexpected node '%s'", addr)
+		}
+
+		received[index] = true
+		parts[index] = *doneMsg.GetTranscript()
+		dkgPubKeys = append(dkgPubKeys, doneMsg.GetPublicKey())
+
+		// The faulty nodes might have a different key, but a threshold of
+		// nodes must agree with the transcript.
+		if countAgreed(dkgPubKeys, doneMsg.GetPublicKey()) < threshold {
+			continue
+		}
+
+		transcript, err = mergeTranscripts(threshold, pubkeys, parts)
+		if err != nil {
+			return types.Transcript{}, xerrors.Errorf("failed to merge transcripts: %v", err)
+		}
+
+		agreed = countAgreed(dkgPubKeys, transcript.GetPublicKey()) >= threshold
+	}
+
+	missing := []int{}
+	for i, ok := range received {
+		if !ok {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) > 0 {
+		dela.Logger.Warn().Ints("excluded", missing).Msg("nodes that didn't finish are excluded")
+	}
+
+	excluded := Excluded(transcript)
+	if len(excluded) > 0 {
+		dela.Logger.Warn().Ints("excluded", excluded).Msg("faulty dealers are excluded")
+	}
+
+	return transcript, nil
+}
+
+// countAgreed returns the number of public keys equal to the one given.
+func countAgreed(pubkeys []kyber.Point, pubkey kyber.Point) int {
+	if pubkey == nil {
+		return 0
+	}
+
+	count := 0
+
+	for _, other := range pubkeys {
+		if other != nil && other.Equal(pubkey) {
+			count++
 		}
 	}
 
-	return dkgPubKeys[0], nil
+	return count
 }
 
 // GetPublicKey implements dkg.Actor
@@ -222,23 +517,21 @@ func (a *Actor) Encrypt(msg []byte) (kyber.Point, []kyber.Point, error) {
 		dela.Logger.Error().Msgf("Cannot encrypt: %v", err.Error())
 	}
 
+	random := suiteWith(a.random).RandomStream()
+
 	// ElGamal-encrypt the point to produce ciphertext (K,C).
-	r := suite.Scalar().Pick(suite.RandomStream())
+	r := suite.Scalar().Pick(random)
 
 	K := suite.Point().Mul(r, nil)
 	dela.Logger.Debug().Msgf("K: %v", K.String())
 
+	// C: ephemeral DH shared secret, which is never logged as it reveals the
+	// message.
 	C := suite.Point().Mul(r, pubK)
-	dela.Logger.Debug().Msgf("C: %v", C)
-
-	// S: ephemeral DH shared secret
-	S := suite.Point().Mul(r, pubK)
-	dela.Logger.Debug().Msgf("S: %v", S.String())
 
 	Cs := make([]kyber.Point, 0, 16)
 	for len(msg) > 0 {
-		kp := suite.Point().Embed(msg, suite.RandomStream())
-		dela.Logger.Debug().Msgf("kp: %v", kp.String())
+		kp := suite.Point().Embed(msg, random)
 
 		// message blinded with secret
 		c := suite.Point().Add(C, kp)
@@ -253,76 +546,88 @@ func (a *Actor) Encrypt(msg []byte) (kyber.Point, []kyber.Point, error) {
 	return K, Cs, nil
 }
 
-// Decrypt implements dkg.Actor. It gets the private shares of the nodes and
-// decrypt the  message.
+// Decrypt implements dkg.Actor. It gets the partial decryptions of the nodes
+// and decrypts the message as soon as a threshold of them are verified. The
+// nodes that don't answer or send an invalid share are skipped.
 func (a *Actor) Decrypt(K kyber.Point, Cs []kyber.Point) ([]byte, error) {
 
 	if !a.startRes.Done() {
 		return nil, xerrors.Errorf(initDkgFirst)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), decryptTimeout)
+	if len(Cs) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := a.withTimeout(decryptTimeout)
 	defer cancel()
 	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameDecrypt)
 
-	players := mino.NewAddresses(a.startRes.getParticipants()...)
+	addrs := a.startRes.getParticipants()
 
-	sender, receiver, err := a.rpc.Stream(ctx, players)
+	sender, receiver, err := a.rpc.Stream(ctx, mino.NewAddresses(addrs...))
 	if err != nil {
 		return nil, xerrors.Errorf(failedStreamCreation, err)
 	}
 
-	iterator := players.AddressIterator()
-	addrs := make([]mino.Address, 0, players.Len())
-
-	for iterator.HasNext() {
-		addrs = append(addrs, iterator.GetNext())
+	// The share of the secret of a participant only depends on K, therefore a
+	// single request is enough to decrypt all the parts of the message.
+	err = <-sender.Send(types.NewDecryptRequest(K, Cs[0]), addrs...)
+	if err != nil {
+		// The shares of the participants that are reachable might be enough.
+		dela.Logger.Warn().Err(err).Msg("failed to send decrypt request")
 	}
 
-	var decryptedMessage []byte
+	poly := a.startRes.getPubPoly()
+	threshold := a.startRes.getThreshold()
 
-	for _, C := range Cs {
-		message := types.NewDecryptRequest(K, C)
+	secretShares := make([]*share.PubShare, 0, threshold)
+	received := make(map[int64]struct{})
 
-		err = <-sender.Send(message, addrs...)
+	for len(secretShares) < threshold {
+		from, msg, err := receiver.Recv(ctx)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to send decrypt request: %v", err)
+			return nil, xerrors.Errorf(unexpectedStreamStop, err)
 		}
 
-		pubShares := make([]*share.PubShare, len(addrs))
-
-		for i := 0; i < len(addrs); i++ {
-			src, message, err := receiver.Recv(ctx)
-			if err != nil {
-				return []byte{}, xerrors.Errorf(unexpectedStreamStop, err)
-			}
-
-			dela.Logger.Debug().Msgf("Received a decryption reply from %v", src)
-
-			decryptReply, ok := message.(types.DecryptReply)
-			if !ok {
-				return []byte{}, xerrors.Errorf(unexpectedReply, decryptReply, message)
-			}
+		reply, ok := msg.(types.DecryptReply)
+		if !ok {
+			dela.Logger.Warn().Stringer("from", from).
+				Msgf(unexpectedReply, reply, msg)
+			continue
+		}
 
-			pubShares[i] = &share.PubShare{
-				I: int(decryptReply.I),
-				V: decryptReply.V,
-			}
+		_, found := received[reply.I]
+		if found {
+			continue
 		}
 
-		res, err := share.RecoverCommit(suite, pubShares, len(addrs), len(addrs))
+		S, err := checkDecryptReply(poly, K, Cs[0], reply)
 		if err != nil {
-			return []byte{}, xerrors.Errorf("failed to recover commit: %v", err)
+			dela.Logger.Warn().Err(err).Stringer("from", from).
+				Msg("invalid decrypt reply")
+			continue
 		}
 
-		decrypted, err := res.Data()
+		received[reply.I] = struct{}{}
+		secretShares = append(secretShares, &share.PubShare{I: int(reply.I), V: S})
+	}
+
+	S, err := share.RecoverCommit(suite, secretShares, threshold, len(addrs))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to recover commit: %v", err)
+	}
+
+	var decryptedMessage []byte
+
+	for _, C := range Cs {
+		decrypted, err := suite.Point().Sub(C, S).Data()
 		if err != nil {
-			return []byte{}, xerrors.Errorf("failed to get embedded data: %v", err)
+			return nil, xerrors.Errorf("failed to get embedded data: %v", err)
 		}
 
 		decryptedMessage = append(decryptedMessage, decrypted...)
 	}
-	dela.Logger.Info().Msgf("Decrypted message: %v", decryptedMessage)
 
 	return decryptedMessage, nil
 }
@@ -339,8 +644,10 @@ func (a *Actor) VerifiableEncrypt(message []byte, GBar kyber.Point) (types.Ciphe
 		return types.Ciphertext{}, nil, xerrors.Errorf(initDkgFirst)
 	}
 
+	random := suiteWith(a.random).RandomStream()
+
 	// Embed the message (or as much of it as will fit) into a curve point.
-	M := suite.Point().Embed(message, random.New())
+	M := suite.Point().Embed(message, random)
 
 	max := suite.Point().EmbedLen()
 	if max > len(message) {
@@ -350,14 +657,14 @@ func (a *Actor) VerifiableEncrypt(message []byte, GBar kyber.Point) (types.Ciphe
 	remainder := message[max:]
 
 	// ElGamal-encrypt the point to produce ciphertext (localK,localC).
-	localk := suite.Scalar().Pick(random.New())                  // ephemeral private key
+	localk := suite.Scalar().Pick(random)                        // ephemeral private key
 	localK := suite.Point().Mul(localk, nil)                     // ephemeral DH public key
 	localS := suite.Point().Mul(localk, a.startRes.getDistKey()) // ephemeral DH shared secret
 	localC := localS.Add(localS, M)                              // message blinded with secret
 
 	// producing the zero knowledge proof
 	UBar := suite.Point().Mul(localk, GBar)
-	s := suite.Scalar().Pick(random.New())
+	s := suite.Scalar().Pick(random)
 	W := suite.Point().Mul(s, nil)
 	WBar := suite.Point().Mul(s, GBar)
 
@@ -384,7 +691,8 @@ func (a *Actor) VerifiableEncrypt(message []byte, GBar kyber.Point) (types.Ciphe
 }
 
 // VerifiableDecrypt implements dkg.Actor. It does as Decrypt() but in addition
-// it checks whether the decryption proofs are valid.
+// it checks whether the decryption proofs are valid. The replies with an invalid
+// proof are skipped.
 //
 // See https://arxiv.org/pdf/2205.08529.pdf / section 5.4 Protocol / step 3
 func (a *Actor) VerifiableDecrypt(ciphertexts []types.Ciphertext) ([][]byte, error) {
@@ -393,38 +701,39 @@ func (a *Actor) VerifiableDecrypt(ciphertexts []types.Ciphertext) ([][]byte, err
 		return nil, xerrors.Errorf(initDkgFirst)
 	}
 
-	players := mino.NewAddresses(a.startRes.getParticipants()...)
+	if len(ciphertexts) == 0 {
+		return nil, nil
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), decryptTimeout)
+	ctx, cancel := a.withTimeout(decryptTimeout)
 	defer cancel()
 	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameDecrypt)
 
-	sender, receiver, err := a.rpc.Stream(ctx, players)
+	addrs := a.startRes.getParticipants()
+
+	sender, receiver, err := a.rpc.Stream(ctx, mino.NewAddresses(addrs...))
 	if err != nil {
 		return nil, xerrors.Errorf(failedStreamCreation, err)
 	}
 
-	players = mino.NewAddresses(a.startRes.getParticipants()...)
-	iterator := players.AddressIterator()
-
-	addrs := make([]mino.Address, 0, players.Len())
-	for iterator.HasNext() {
-		addrs = append(addrs, iterator.GetNext())
-	}
-
 	batchsize := len(ciphertexts)
 
 	message := types.NewVerifiableDecryptRequest(ciphertexts)
 	// sending the decrypt request to the nodes
 	err = <-sender.Send(message, addrs...)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to send verifiable decrypt request: %v", err)
+		// The shares of the participants that are reachable might be enough.
+		dela.Logger.Warn().Err(err).Msg("failed to send verifiable decrypt request")
 	}
 
-	responses := make([]types.VerifiableDecryptReply, len(addrs))
+	poly := a.startRes.getPubPoly()
+	threshold := a.startRes.getThreshold()
+
+	responses := make([]types.VerifiableDecryptReply, 0, threshold)
+	received := make(map[int64]struct{})
 
-	// receive decrypt reply from the nodes
-	for i := range addrs {
+	// receive decrypt reply from the nodes until a threshold of them are valid
+	for len(responses) < threshold {
 		from, message, err := receiver.Recv(ctx)
 		if err != nil {
 			return nil, xerrors.Errorf(unexpectedStreamStop, err)
@@ -432,12 +741,29 @@ func (a *Actor) VerifiableDecrypt(ciphertexts []types.Ciphertext) ([][]byte, err
 
 		dela.Logger.Debug().Msgf("received share from %v\n", from)
 
-		shareAndProof, ok := message.(types.VerifiableDecryptReply)
+		reply, ok := message.(types.VerifiableDecryptReply)
 		if !ok {
-			return nil, xerrors.Errorf(unexpectedReply, shareAndProof, message)
+			dela.Logger.Warn().Stringer("from", from).
+				Msgf(unexpectedReply, reply, message)
+			continue
+		}
+
+		err = checkVerifiableDecryptReply(poly, ciphertexts, reply)
+		if err != nil {
+			dela.Logger.Warn().Err(err).Stringer("from", from).
+				Msg("invalid verifiable decrypt reply")
+			continue
 		}
 
-		responses[i] = shareAndProof
+		index := reply.GetShareAndProof()[0].I
+
+		_, found := received[index]
+		if found {
+			continue
+		}
+
+		received[index] = struct{}{}
+		responses = append(responses, reply)
 	}
 
 	// the final decrypted message
@@ -454,13 +780,14 @@ func (a *Actor) VerifiableDecrypt(ciphertexts []types.Ciphertext) ([][]byte, err
 		close(jobChan)
 	}()
 
-	if batchsize < workerNum {
-		workerNum = batchsize
+	numWorkers := workerNum
+	if batchsize < numWorkers {
+		numWorkers = batchsize
 	}
 
-	worker := newWorker(len(addrs), decryptedMessage, responses, ciphertexts)
+	worker := newWorker(threshold, decryptedMessage, responses)
 
-	for i := 0; i < workerNum; i++ {
+	for i := 0; i < numWorkers; i++ {
 		wgBatchReply.Add(1)
 
 		go func() {
@@ -479,45 +806,39 @@ func (a *Actor) VerifiableDecrypt(ciphertexts []types.Ciphertext) ([][]byte, err
 	return decryptedMessage, nil
 }
 
-func newWorker(numParticipants int, decryptedMessage [][]byte,
-	responses []types.VerifiableDecryptReply, ciphertexts []types.Ciphertext) worker {
+func newWorker(threshold int, decryptedMessage [][]byte,
+	responses []types.VerifiableDecryptReply) worker {
 
 	return worker{
-		numParticipants:  numParticipants,
+		threshold:        threshold,
 		decryptedMessage: decryptedMessage,
 		responses:        responses,
-		ciphertexts:      ciphertexts,
 	}
 }
 
 // worker contains the data needed by a worker to perform the verifiable
 // decryption job. All its fields must be read-only, except the
-// decryptedMessage, which can be written at a provided jobIndex.
+// decryptedMessage, which can be written at a provided jobIndex. The responses
+// must have been verified beforehand.
 type worker struct {
-	numParticipants  int
+	threshold        int
 	decryptedMessage [][]byte
-	ciphertexts      []types.Ciphertext
 	responses        []types.VerifiableDecryptReply
 }
 
 func (w worker) work(jobIndex int) error {
-	pubShares := make([]*share.PubShare, w.numParticipants)
+	pubShares := make([]*share.PubShare, len(w.responses))
 
 	for k, response := range w.responses {
 		resp := response.GetShareAndProof()[jobIndex]
 
-		err := checkDecryptionProof(resp, w.ciphertexts[jobIndex].K)
-		if err != nil {
-			return xerrors.Errorf("failed to check the decryption proof: %v", err)
-		}
-
 		pubShares[k] = &share.PubShare{
 			I: int(resp.I),
 			V: resp.V,
 		}
 	}
 
-	res, err := share.RecoverCommit(suite, pubShares, w.numParticipants, w.numParticipants)
+	res, err := share.RecoverCommit(suite, pubShares, w.threshold, len(pubShares))
 	if err != nil {
 		return xerrors.Errorf("failed to recover the commit: %v", err)
 	}
@@ -556,11 +877,15 @@ func (a *Actor) Reshare(coAuth crypto.CollectiveAuthority, thresholdNew int) err
 		pubkeysNew = append(pubkeysNew, edKey.GetPoint())
 	}
 
+	// The key is captured before the resharing updates the state of this node
+	// when it is part of the committee.
+	distKey := a.startRes.getDistKey()
+
 	// Get the union of the new members and the old members
 	addrsAll := union(a.startRes.getParticipants(), addrsNew)
 	players := mino.NewAddresses(addrsAll...)
 
-	ctx, cancel := context.WithTimeout(context.Background(), resharingTimeout)
+	ctx, cancel := a.withTimeout(resharingTimeout)
 	defer cancel()
 
 	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameResharing)
@@ -632,6 +957,10 @@ func (a *Actor) Reshare(coAuth crypto.CollectiveAuthority, thresholdNew int) err
 		}
 	}
 
+	if !distKey.Equal(dkgPubKeys[0]) {
+		return xerrors.Errorf("the public key changed: %v != %v", dkgPubKeys[0], distKey)
+	}
+
 	dela.Logger.Info().Msgf("resharing done")
 
 	return nil
@@ -678,3 +1007,14 @@ func union(el1 []mino.Address, el2 []mino.Address) []mino.Address {
 
 	return addrsAll
 }
+
+// indexOf returns the index of addr in addrs, or -1 if it is not found.
+func indexOf(addrs []mino.Address, addr mino.Address) int {
+	for i, other := range addrs {
+		if other.Equal(addr) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -3,6 +3,7 @@ package pedersen
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"go.dedis.ch/dela/mino/minoch"
 	"go.dedis.ch/dela/testing/fake"
@@ -13,6 +14,8 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/ed25519"
 	"go.dedis.ch/dela/dkg"
@@ -20,7 +23,10 @@ import (
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc"
 	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	"go.dedis.ch/kyber/v3/sign/eddsa"
 )
 
 func TestPedersen_Listen(t *testing.T) {
@@ -32,6 +38,123 @@ func TestPedersen_Listen(t *testing.T) {
 	require.NotNil(t, actor)
 }
 
+func TestPedersen_WithID(t *testing.T) {
+	p, _ := NewPedersen(fake.Mino{})
+	require.Equal(t, "dkg", p.rpcName)
+
+	p, _ = NewPedersen(fake.Mino{}, WithID("abc"))
+	require.Equal(t, "dkg-abc", p.rpcName)
+}
+
+func TestPedersen_WithClock(t *testing.T) {
+	c := clock.NewVirtual(time.Unix(0, 0))
+
+	p, _ := NewPedersen(fake.Mino{}, WithClock(c))
+	require.Equal(t, c, p.clock)
+
+	actor, err := p.Listen()
+	require.NoError(t, err)
+	require.Equal(t, c, actor.(*Actor).clock)
+
+	ctx, cancel := actor.(*Actor).withTimeout(decryptTimeout)
+	defer cancel()
+
+	c.Advance(decryptTimeout)
+	<-ctx.Done()
+}
+
+func TestPedersen_WithKey(t *testing.T) {
+	privKey := suite.Scalar().Pick(suite.RandomStream())
+
+	p, pubkey := NewPedersen(fake.Mino{}, WithKey(privKey))
+	require.True(t, privKey.Equal(p.privKey))
+	require.True(t, pubkey.Equal(suite.Point().Mul(privKey, nil)))
+}
+
+func TestPedersen_WithPhaseTimeout(t *testing.T) {
+	p, _ := NewPedersen(fake.Mino{}, WithPhaseTimeout(time.Second))
+	require.Equal(t, time.Second, p.phaseTimeout)
+
+	h := newHandler(p.privKey, fake.NewAddress(0), nil, nil, p.phaseTimeout, nil)
+	require.Equal(t, time.Second, h.dkgInstance.(*instance).getPhaseTimeout())
+
+	h = NewHandler(p.privKey, fake.NewAddress(0))
+	require.Equal(t, defaultPhaseTimeout, h.dkgInstance.(*instance).getPhaseTimeout())
+}
+
+func TestPedersen_NamedScenario(t *testing.T) {
+	oldLog := dela.Logger
+	defer func() {
+		dela.Logger = oldLog
+	}()
+
+	dela.Logger = dela.Logger.Level(zerolog.WarnLevel)
+
+	nbNodes := 4
+
+	manager := minoch.NewManager()
+
+	minos := make([]*minoch.Minoch, nbNodes)
+	dbs := make([]kv.DB, nbNodes)
+
+	for i := range minos {
+		minos[i] = minoch.MustCreate(manager, fmt.Sprint("node", i))
+		dbs[i] = fake.NewInMemoryDB()
+	}
+
+	// Two instances held by different committees on the same nodes.
+	committees := map[string][]int{
+		"a": {0, 1, 2},
+		"b": {1, 2, 3},
+	}
+
+	pubkeys := make(map[string]kyber.Point)
+	actors := make(map[string][]dkg.Actor)
+
+	for id, committee := range committees {
+		addrs := make([]mino.Address, len(committee))
+		keys := make([]kyber.Point, len(committee))
+
+		for i, index := range committee {
+			d, pubkey, err := NewPersistentPedersen(minos[index], dbs[index], nil, WithID(id))
+			require.NoError(t, err)
+
+			actor, err := d.Listen()
+			require.NoError(t, err)
+
+			addrs[i] = minos[index].GetAddress()
+			keys[i] = pubkey
+			actors[id] = append(actors[id], actor)
+		}
+
+		pubkey, err := actors[id][0].Setup(NewAuthority(addrs, keys), 2)
+		require.NoError(t, err)
+
+		pubkeys[id] = pubkey
+	}
+
+	require.False(t, pubkeys["a"].Equal(pubkeys["b"]))
+
+	for id := range committees {
+		message := []byte("Hello " + id)
+
+		K, Cs, err := actors[id][0].Encrypt(message)
+		require.NoError(t, err)
+
+		decrypted, err := actors[id][2].Decrypt(K, Cs)
+		require.NoError(t, err)
+		require.Equal(t, message, decrypted)
+	}
+
+	ids, err := StoredIDs(dbs[1])
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ids)
+
+	ids, err = StoredIDs(dbs[3])
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, ids)
+}
+
 func TestPedersen_Setup(t *testing.T) {
 	actor := Actor{
 		rpc:      fake.NewBadRPC(),
@@ -81,8 +204,75 @@ func TestPedersen_Setup(t *testing.T) {
 	actor.rpc = rpc
 
 	_, err = actor.Setup(fakeAuthority, 2)
+	require.EqualError(t, err, "missing transcript from 'fake.Address[0]'")
+
+	setup := newFakeSetup(t, 3, 2)
+	setup.run(-1, -1, false)
+
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)}
+	authority := NewAuthority(addrs, setup.pubkeys)
+
+	actor.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(addrs[0], types.NewStartDoneWithTranscript(suite.Point(),
+			types.NewTranscript(2, nil, nil, nil, nil, nil, nil, nil))),
+		fake.NewRecvMsg(addrs[1], types.NewStartDoneWithTranscript(suite.Point(),
+			types.NewTranscript(2, nil, nil, nil, nil, nil, nil, nil))),
+		fake.NewRecvMsg(addrs[2], types.NewStartDoneWithTranscript(suite.Point(),
+			types.NewTranscript(2, nil, nil, nil, nil, nil, nil, nil))),
+	), fake.Sender{})
+
+	_, err = actor.Setup(authority, 2)
+	require.EqualError(t, err, "failed to merge transcripts: failed to evaluate: "+
+		"only 0 qualified dealers for a threshold of 2")
+
+	actor.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(addrs[0], types.NewStartDoneWithTranscript(suite.Point(),
+			setup.parts[0])),
+		fake.NewRecvMsg(addrs[1], types.NewStartDoneWithTranscript(suite.Point(),
+			setup.parts[1])),
+		fake.NewRecvMsg(addrs[2], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[2])),
+	), fake.Sender{})
+
+	_, err = actor.Setup(authority, 2)
 	require.Error(t, err)
 	require.Regexp(t, "^the public keys does not match:", err)
+
+	actor.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(addrs[0], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[0])),
+		fake.NewRecvMsg(addrs[1], types.NewStartDoneWithTranscript(suite.Point(),
+			setup.parts[1])),
+		fake.NewRecvMsg(addrs[2], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[2])),
+	), fake.Sender{})
+
+	transcript, err := actor.SetupWithTranscript(authority, 2)
+	require.NoError(t, err)
+	require.True(t, setup.pubkey.Equal(transcript.GetPublicKey()))
+	require.NoError(t, VerifyTranscript(transcript))
+
+	// The setup doesn't wait for the last node once a threshold agrees.
+	actor.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(addrs[0], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[0])),
+		fake.NewRecvMsg(addrs[2], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[2])),
+	), fake.Sender{})
+
+	transcript, err = actor.SetupWithTranscript(authority, 2)
+	require.NoError(t, err)
+	require.True(t, setup.pubkey.Equal(transcript.GetPublicKey()))
+
+	actor.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(addrs[0], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[0])),
+		fake.NewRecvMsg(addrs[0], types.NewStartDoneWithTranscript(setup.pubkey,
+			setup.parts[0])),
+	), fake.Sender{})
+
+	_, err = actor.SetupWithTranscript(authority, 2)
+	require.EqualError(t, err, "unexpected node 'fake.Address[0]'")
 }
 
 func TestPedersen_GetPublicKey(t *testing.T) {
@@ -99,69 +289,104 @@ func TestPedersen_GetPublicKey(t *testing.T) {
 }
 
 func TestPedersen_Decrypt(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	priShares := priPoly.Shares(3)
+	message := []byte("Hello world")
+
 	actor := Actor{
 		rpc: fake.NewBadRPC(),
 		startRes: &state{
-			dkgState:     certified,
-			participants: []mino.Address{fake.NewAddress(0)}, distrKey: suite.Point(),
+			dkgState: certified,
+			participants: []mino.Address{
+				fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2),
+			},
+			distrKey:  priPoly.Commit(nil).Commit(),
+			poly:      priPoly.Commit(nil),
+			threshold: 2,
 		},
 	}
 
-	K := suite.Point()
-	Cs := make([]kyber.Point, 1)
+	K, Cs, err := actor.Encrypt(message)
+	require.NoError(t, err)
 
-	_, err := actor.Decrypt(K, Cs)
+	_, err = actor.Decrypt(K, Cs)
 	require.EqualError(t, err, fake.Err("failed to create stream"))
 
-	rpc := fake.NewStreamRPC(fake.NewBadReceiver(), fake.NewBadSender())
-	actor.rpc = rpc
+	actor.rpc = fake.NewStreamRPC(fake.NewBadReceiver(), fake.NewBadSender())
 
 	_, err = actor.Decrypt(K, Cs)
-	require.EqualError(t, err, fake.Err("failed to send decrypt request"))
+	require.EqualError(t, err, fake.Err("stream stopped unexpectedly"))
 
-	recv := fake.NewReceiver(fake.NewRecvMsg(fake.NewAddress(0), nil))
+	reply1 := makeDecryptReply(K, Cs[0], priShares[0])
+	reply2 := makeDecryptReply(K, Cs[0], priShares[1])
 
-	rpc = fake.NewStreamRPC(recv, fake.Sender{})
-	actor.rpc = rpc
-
-	_, err = actor.Decrypt(K, Cs)
-	require.EqualError(t, err, "got unexpected reply, expected types.DecryptReply but got: <nil>")
+	invalid := reply2
+	invalid.I = 2
 
-	recv = fake.NewReceiver(
-		fake.NewRecvMsg(fake.NewAddress(0), types.DecryptReply{I: -1, V: suite.Point()}),
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), nil),
+		fake.NewRecvMsg(fake.NewAddress(1), invalid),
+		fake.NewRecvMsg(fake.NewAddress(0), reply1),
+		fake.NewRecvMsg(fake.NewAddress(0), reply1),
 	)
-
-	rpc = fake.NewStreamRPC(recv, fake.Sender{})
-	actor.rpc = rpc
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
 
 	_, err = actor.Decrypt(K, Cs)
-	require.EqualError(t, err, "failed to recover commit: share: not enough "+
-		"good public shares to reconstruct secret commitment")
+	require.EqualError(t, err, "stream stopped unexpectedly: EOF")
 
+	// The reply of the last node is not needed once the threshold is reached.
 	recv = fake.NewReceiver(
-		fake.NewRecvMsg(fake.NewAddress(0), types.DecryptReply{I: 1, V: suite.Point()}),
+		fake.NewRecvMsg(fake.NewAddress(1), invalid),
+		fake.NewRecvMsg(fake.NewAddress(0), reply1),
+		fake.NewRecvMsg(fake.NewAddress(1), reply2),
 	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.NewBadSender())
 
-	rpc = fake.NewStreamRPC(recv, fake.Sender{})
-	actor.rpc = rpc
+	decrypted, err := actor.Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
 
-	_, err = actor.Decrypt(K, Cs)
+	decrypted, err = actor.Decrypt(K, nil)
 	require.NoError(t, err)
+	require.Nil(t, decrypted)
 }
 
-func Test_Decrypt_StreamStop(t *testing.T) {
-	a := Actor{
-		rpc: fake.NewStreamRPC(fake.NewBadReceiver(), fake.Sender{}),
+func TestPedersen_DecryptBadData(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 1, nil, suite.RandomStream())
+	priShare := priPoly.Shares(1)[0]
+
+	// C is not an embedding of data, but the proof of the share is valid. A
+	// random point can be a valid embedding, so one that isn't is picked.
+	K := suite.Point().Pick(suite.RandomStream())
+	S := suite.Point().Mul(priShare.V, K)
+
+	var C kyber.Point
+
+	for {
+		C = suite.Point().Pick(suite.RandomStream())
+
+		_, err := suite.Point().Sub(C, S).Data()
+		if err != nil {
+			break
+		}
+	}
+
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), makeDecryptReply(K, C, priShare)),
+	)
+
+	actor := Actor{
+		rpc: fake.NewStreamRPC(recv, fake.Sender{}),
 		startRes: &state{
 			dkgState:     certified,
 			participants: []mino.Address{fake.NewAddress(0)},
+			poly:         priPoly.Commit(nil),
+			threshold:    1,
 		},
 	}
 
-	Cs := make([]kyber.Point, 1)
-
-	_, err := a.Decrypt(nil, Cs)
-	require.EqualError(t, err, fake.Err("stream stopped unexpectedly"))
+	_, err := actor.Decrypt(K, []kyber.Point{C})
+	require.Regexp(t, "^failed to get embedded data", err)
 }
 
 func TestPedersen_Scenario(t *testing.T) {
@@ -244,6 +469,80 @@ func TestPedersen_Scenario(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, message, decrypted)
 	}
+
+	pubkey, err := actors[0].GetPublicKey()
+	require.NoError(t, err)
+
+	// the distributed key should be usable to sign and to compute the beacon
+	for _, i := range []int{0, n - 1} {
+		sig, err := actors[i].Sign(message)
+		require.NoError(t, err)
+		require.NoError(t, eddsa.Verify(pubkey, message, sig))
+
+		shares, err := actors[i].Beacon(message)
+		require.NoError(t, err)
+
+		_, err = VerifyBeacon(pubkey, message, shares)
+		require.NoError(t, err)
+	}
+}
+
+func TestPedersen_WithAuthorizer(t *testing.T) {
+	p, _ := NewPedersen(fake.Mino{})
+	require.Nil(t, p.authorizer)
+
+	p, _ = NewPedersen(fake.Mino{}, WithAuthorizer(fakeAuthorizer{}))
+	require.Equal(t, fakeAuthorizer{}, p.authorizer)
+}
+
+func TestPedersen_AuthorizedScenario(t *testing.T) {
+	oldLog := dela.Logger
+	defer func() {
+		dela.Logger = oldLog
+	}()
+
+	dela.Logger = dela.Logger.Level(zerolog.WarnLevel)
+
+	nbNodes := 3
+
+	addrs := make([]mino.Address, nbNodes)
+	pubkeys := make([]kyber.Point, nbNodes)
+	actors := make([]dkg.Actor, nbNodes)
+
+	manager := minoch.NewManager()
+
+	for i := range actors {
+		m := minoch.MustCreate(manager, fmt.Sprint("node", i))
+		addrs[i] = m.GetAddress()
+
+		d, pubkey := NewPedersen(m, WithAuthorizer(fakeAuthorizer{}))
+		pubkeys[i] = pubkey
+
+		actor, err := d.Listen()
+		require.NoError(t, err)
+
+		actors[i] = actor
+	}
+
+	_, err := actors[0].Setup(NewAuthority(addrs, pubkeys), nbNodes)
+	require.NoError(t, err)
+
+	message := []byte("Hello world")
+
+	K, Cs, err := actors[1].Encrypt(message)
+	require.NoError(t, err)
+
+	kp := key.NewKeyPair(suite)
+
+	XhatEnc, err := actors[2].(*Actor).ReencryptWithProof(K, kp.Public, []byte("ok"))
+	require.NoError(t, err)
+
+	dkgPk, err := actors[2].GetPublicKey()
+	require.NoError(t, err)
+
+	decrypted, err := decryptReencrypted(Cs, XhatEnc, dkgPk, kp.Private)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
 }
 
 func TestPedersen_ReencryptScenario(t *testing.T) {
@@ -316,43 +615,161 @@ func TestPedersen_ReencryptScenario(t *testing.T) {
 	}
 }
 
-func Test_Worker_BadProof(t *testing.T) {
-	ct := types.Ciphertext{
-		K:    suite.Point(),
-		C:    suite.Point(),
-		UBar: suite.Point(),
-		E:    suite.Scalar(),
-		F:    suite.Scalar(),
-		GBar: suite.Point(),
+func TestPedersen_Reencrypt(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	priShares := priPoly.Shares(3)
+
+	actor := Actor{
+		rpc: fake.NewBadRPC(),
+		startRes: &state{
+			dkgState: certified,
+			participants: []mino.Address{
+				fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2),
+			},
+			poly:      priPoly.Commit(nil),
+			threshold: 2,
+		},
 	}
 
-	sap := types.ShareAndProof{
-		V:  suite.Point(),
-		I:  0,
-		Ui: suite.Point(),
-		Ei: suite.Scalar(),
-		Fi: suite.Scalar(),
-		Hi: suite.Point(),
+	_, err := actor.Reencrypt(nil, nil)
+	require.EqualError(t, err, fake.Err("failed to create stream"))
+
+	K := suite.Point().Pick(suite.RandomStream())
+	kp := key.NewKeyPair(suite)
+
+	reply1 := makeReencryptReply(K, kp.Public, priShares[0])
+	reply2 := makeReencryptReply(K, kp.Public, priShares[1])
+
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), fake.Message{}),
+		fake.NewRecvMsg(fake.NewAddress(1), types.ReencryptReply{}),
+		fake.NewRecvMsg(fake.NewAddress(0), reply1),
+		fake.NewRecvMsg(fake.NewAddress(0), reply1),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.NewBadSender())
+
+	_, err = actor.Reencrypt(K, kp.Public)
+	require.EqualError(t, err, "stream stopped unexpectedly: EOF")
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), reply1),
+		fake.NewRecvMsg(fake.NewAddress(1), reply2),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	XhatEnc, err := actor.Reencrypt(K, kp.Public)
+	require.NoError(t, err)
+
+	expected := suite.Point().Mul(priPoly.Secret(), suite.Point().Add(K, kp.Public))
+	require.True(t, expected.Equal(XhatEnc))
+}
+
+func TestPedersen_MisbehavingNodeScenario(t *testing.T) {
+	oldLog := dela.Logger
+	defer func() {
+		dela.Logger = oldLog
+	}()
+
+	dela.Logger = dela.Logger.Level(zerolog.ErrorLevel)
+
+	n := 5
+	threshold := 3
+
+	manager := minoch.NewManager()
+
+	addrs := make([]mino.Address, n)
+	pubkeys := make([]kyber.Point, n)
+	actors := make([]*Actor, n)
+	handlers := make([]*Handler, n)
+
+	for i := 0; i < n; i++ {
+		m := minoch.MustCreate(manager, fmt.Sprint("node", i))
+
+		p, pubkey := NewPedersen(m)
+
+		addrs[i] = m.GetAddress()
+		pubkeys[i] = pubkey
+		actors[i], handlers[i] = listenWithHandler(p)
 	}
 
-	w := worker{
-		numParticipants:  0,
-		decryptedMessage: [][]byte{},
-		ciphertexts: []types.Ciphertext{
-			ct,
-		},
-		responses: []types.VerifiableDecryptReply{types.NewVerifiableDecryptReply([]types.ShareAndProof{sap})},
+	_, err := actors[0].Setup(NewAuthority(addrs, pubkeys), threshold)
+	require.NoError(t, err)
+
+	// The last nodes answer with shares that are not the ones of the DKG, which
+	// must be skipped as long as a threshold of the nodes behave.
+	for _, h := range handlers[threshold:] {
+		inst := h.dkgInstance.(*instance)
+		inst.privShare = &share.PriShare{
+			I: inst.privShare.I,
+			V: suite.Scalar().Pick(suite.RandomStream()),
+		}
 	}
 
-	err := w.work(0)
-	require.Regexp(t, "^failed to check the decryption proof: hash is not valid", err.Error())
+	message := []byte("Hello world")
+
+	K, Cs, err := actors[0].Encrypt(message)
+	require.NoError(t, err)
+
+	decrypted, err := actors[0].Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+
+	kp := key.NewKeyPair(suite)
+
+	XhatEnc, err := actors[0].Reencrypt(K, kp.Public)
+	require.NoError(t, err)
+
+	decrypted, err = decryptReencrypted(Cs, XhatEnc, actors[0].startRes.getDistKey(), kp.Private)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+
+	GBar := suite.Point().Pick(suite.RandomStream())
+
+	ct, _, err := actors[0].VerifiableEncrypt(message, GBar)
+	require.NoError(t, err)
+
+	batch, err := actors[0].VerifiableDecrypt([]types.Ciphertext{ct})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{message}, batch)
+}
+
+func TestPedersen_FaultyDealerScenario(t *testing.T) {
+	oldLog := dela.Logger
+	defer func() {
+		dela.Logger = oldLog
+	}()
+
+	dela.Logger = dela.Logger.Level(zerolog.ErrorLevel)
+
+	// The last node sends an invalid deal to the first node, which complains.
+	// The dealer stays qualified only if it justifies its deal.
+	t.Run("justified", func(t *testing.T) {
+		transcript, actors := runFaultyDealerScenario(t, false)
+
+		require.Empty(t, Excluded(transcript))
+		require.NoError(t, VerifyTranscript(transcript))
+		require.Len(t, transcript.GetJustifications(), 1)
+
+		for _, actor := range actors {
+			pubkey, err := actor.GetPublicKey()
+			require.NoError(t, err)
+			require.True(t, pubkey.Equal(transcript.GetPublicKey()))
+		}
+	})
+
+	t.Run("excluded", func(t *testing.T) {
+		transcript, _ := runFaultyDealerScenario(t, true)
+
+		require.Equal(t, []int{3}, Excluded(transcript))
+		require.NoError(t, VerifyTranscript(transcript))
+		require.Empty(t, transcript.GetJustifications())
+	})
 }
 
 func Test_Worker_BadRecover(t *testing.T) {
 	w := worker{
-		numParticipants:  2,
+		threshold:        2,
 		decryptedMessage: [][]byte{},
-		ciphertexts:      []types.Ciphertext{},
 		responses:        []types.VerifiableDecryptReply{},
 	}
 
@@ -416,9 +833,40 @@ func Test_Reshare_BadReceiver(t *testing.T) {
 	require.EqualError(t, err, fake.Err("stream stopped unexpectedly"))
 }
 
+func Test_Reshare_KeyChanged(t *testing.T) {
+	done := types.NewStartDone(suite.Point().Pick(suite.RandomStream()))
+	recv := fake.NewReceiver(fake.NewRecvMsg(fake.NewAddress(0), done))
+
+	a := Actor{
+		startRes: &state{
+			dkgState:     certified,
+			distrKey:     suite.Point(),
+			participants: []mino.Address{fake.NewAddress(0)},
+		},
+		rpc: fake.NewStreamRPC(recv, fake.Sender{}),
+	}
+
+	co := NewAuthority([]mino.Address{fake.NewAddress(0)}, []kyber.Point{suite.Point()})
+
+	err := a.Reshare(co, 1)
+	require.Error(t, err)
+	require.Regexp(t, "^the public key changed: ", err.Error())
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
+// fakeAuthorizer accepts the requests with the proof "ok".
+type fakeAuthorizer struct{}
+
+func (fakeAuthorizer) Authorize(proof []byte, K, pubk kyber.Point) error {
+	if string(proof) != "ok" {
+		return xerrors.Errorf("invalid proof '%s'", proof)
+	}
+
+	return nil
+}
+
 //
 // Collective authority
 //
@@ -537,3 +985,122 @@ func decryptReencrypted(
 	}
 	return
 }
+
+func makeDecryptReply(K, C kyber.Point, priShare *share.PriShare) types.DecryptReply {
+	S, _, E, F := proveDLEQ(K, priShare.V, suite.RandomStream())
+
+	return types.NewDecryptReply(int64(priShare.I), suite.Point().Sub(C, S), E, F)
+}
+
+// listenWithHandler does as Listen but also returns the handler of the node.
+// runFaultyDealerScenario runs a setup of 4 nodes with a threshold of 3, where
+// the last node sends an invalid deal to the first one, and encrypts and
+// decrypts a message with the result.
+func runFaultyDealerScenario(t *testing.T, dropJustifications bool) (types.Transcript,
+	[]*Actor) {
+
+	n := 4
+	threshold := 3
+	timeout := 2 * time.Second
+
+	manager := minoch.NewManager()
+
+	addrs := make([]mino.Address, n)
+	pubkeys := make([]kyber.Point, n)
+	actors := make([]*Actor, n)
+
+	for i := 0; i < n; i++ {
+		m := minoch.MustCreate(manager, fmt.Sprint("node", i))
+
+		p, pubkey := NewPedersen(m, WithPhaseTimeout(timeout))
+
+		addrs[i] = m.GetAddress()
+		pubkeys[i] = pubkey
+
+		if i < n-1 {
+			actors[i], _ = listenWithHandler(p)
+			continue
+		}
+
+		h := faultyHandler{
+			Handler: newHandler(p.privKey, m.GetAddress(), nil, nil, timeout, nil),
+			victim:  addrs[0],
+			drop:    dropJustifications,
+		}
+
+		actors[i] = &Actor{
+			rpc:      mino.MustCreateRPC(m, p.rpcName, h, p.factory),
+			factory:  p.factory,
+			startRes: h.dkgInstance.getState(),
+		}
+	}
+
+	transcript, err := actors[0].SetupWithTranscript(NewAuthority(addrs, pubkeys), threshold)
+	require.NoError(t, err)
+
+	message := []byte("Hello world")
+
+	K, Cs, err := actors[0].Encrypt(message)
+	require.NoError(t, err)
+
+	decrypted, err := actors[0].Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+
+	return transcript, actors
+}
+
+// faultyHandler is a DKG handler that corrupts its deal for the victim, and
+// optionally drops its justifications.
+type faultyHandler struct {
+	*Handler
+
+	victim mino.Address
+	drop   bool
+}
+
+func (h faultyHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	return h.Handler.Stream(faultySender{Sender: out, handler: h}, in)
+}
+
+type faultySender struct {
+	mino.Sender
+
+	handler faultyHandler
+}
+
+func (s faultySender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	switch m := msg.(type) {
+	case types.Deal:
+		if len(addrs) == 1 && addrs[0].Equal(s.handler.victim) {
+			e := m.GetEncryptedDeal()
+			cipher := append([]byte{}, e.GetCipher()...)
+			cipher[0] ^= 0xff
+
+			msg = types.NewDeal(m.GetIndex(), m.GetSignature(), types.NewEncryptedDeal(
+				e.GetDHKey(), e.GetSignature(), e.GetNonce(), cipher))
+		}
+	case types.Justification:
+		if s.handler.drop {
+			errs := make(chan error)
+			close(errs)
+
+			return errs
+		}
+	}
+
+	return s.Sender.Send(msg, addrs...)
+}
+
+func listenWithHandler(p *Pedersen) (*Actor, *Handler) {
+	h := newHandler(p.privKey, p.mino.GetAddress(), p.authorizer, p.clock,
+		p.phaseTimeout, p.random)
+
+	a := &Actor{
+		rpc:      mino.MustCreateRPC(p.mino, p.rpcName, h, p.factory),
+		factory:  p.factory,
+		startRes: h.dkgInstance.getState(),
+	}
+
+	return a, h
+}
@@ -0,0 +1,394 @@
+// This file contains the implementation of the storage of a DKG. It keeps the
+// private key of the node and the result of the DKG in a database so that a
+// node can restart without losing its share. The values are encrypted with a
+// passphrase when one is given.
+
+package pedersen
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	pedersen "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"golang.org/x/xerrors"
+)
+
+var (
+	// defaultBucket is the name of the bucket of the DKG in the database.
+	defaultBucket = []byte("dkg")
+
+	keyPrivate = []byte("privkey")
+	keyResult  = []byte("result")
+)
+
+// idSeparator separates the name of a value from the identifier of the
+// instance in the keys of the database.
+const idSeparator = "/"
+
+// errRestored is the error returned when a restored DKG is asked to take part
+// in a sharing.
+const errRestored = "dkg is restored from the storage"
+
+// resultJSON is the format of the result of a DKG in the database.
+type resultJSON struct {
+	Participants [][]byte
+	PublicKeys   [][]byte
+	Threshold    int
+	Commitments  [][]byte
+	ShareIndex   int
+	Share        []byte
+}
+
+// storage stores the values of a DKG in a bucket of the database. The keys of
+// a named instance are suffixed with its identifier so that the instances of a
+// node share the bucket.
+type storage struct {
+	db         kv.DB
+	bucket     []byte
+	id         string
+	passphrase []byte
+	addrFac    mino.AddressFactory
+}
+
+// loadOrCreateKey returns the private key of the node in the database, or
+// creates and stores a new one if it doesn't exist yet.
+func (s storage) loadOrCreateKey() (kyber.Scalar, error) {
+	data, err := s.read(keyPrivate)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read key: %v", err)
+	}
+
+	privkey := suite.Scalar()
+
+	if data != nil {
+		err = privkey.UnmarshalBinary(data)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to unmarshal key: %v", err)
+		}
+
+		return privkey, nil
+	}
+
+	privkey.Pick(suite.RandomStream())
+
+	data, err = privkey.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal key: %v", err)
+	}
+
+	err = s.write(keyPrivate, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to write key: %v", err)
+	}
+
+	return privkey, nil
+}
+
+// save stores the result of the DKG, which is the state once certified and the
+// distributed key share of the node.
+func (s storage) save(st *state, distKey *pedersen.DistKeyShare) error {
+	participants := st.getParticipants()
+	pubkeys := st.getPublicKeys()
+
+	result := resultJSON{
+		Participants: make([][]byte, len(participants)),
+		PublicKeys:   make([][]byte, len(pubkeys)),
+		Threshold:    st.getThreshold(),
+		ShareIndex:   distKey.Share.I,
+	}
+
+	var err error
+
+	for i, addr := range participants {
+		result.Participants[i], err = addr.MarshalText()
+		if err != nil {
+			return xerrors.Errorf("failed to marshal address: %v", err)
+		}
+	}
+
+	result.PublicKeys, err = marshalPoints(pubkeys)
+	if err != nil {
+		return xerrors.Errorf("public keys: %v", err)
+	}
+
+	result.Commitments, err = marshalPoints(distKey.Commits)
+	if err != nil {
+		return xerrors.Errorf("commitments: %v", err)
+	}
+
+	result.Share, err = distKey.Share.V.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal share: %v", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal result: %v", err)
+	}
+
+	err = s.write(keyResult, data)
+	if err != nil {
+		return xerrors.Errorf("failed to write result: %v", err)
+	}
+
+	return nil
+}
+
+// load restores the result of the DKG in the state, and returns the
+// distributed key share of the node. It returns nil if no result is stored.
+func (s storage) load(st *state) (*pedersen.DistKeyShare, error) {
+	data, err := s.read(keyResult)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read result: %v", err)
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	var result resultJSON
+
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal result: %v", err)
+	}
+
+	participants := make([]mino.Address, len(result.Participants))
+	for i, addr := range result.Participants {
+		participants[i] = s.addrFac.FromText(addr)
+	}
+
+	pubkeys, err := unmarshalPoints(result.PublicKeys)
+	if err != nil {
+		return nil, xerrors.Errorf("public keys: %v", err)
+	}
+
+	commits, err := unmarshalPoints(result.Commitments)
+	if err != nil {
+		return nil, xerrors.Errorf("commitments: %v", err)
+	}
+
+	if len(commits) == 0 {
+		return nil, xerrors.New("commitments are missing")
+	}
+
+	distKey := &pedersen.DistKeyShare{
+		Commits: commits,
+		Share:   &share.PriShare{I: result.ShareIndex, V: suite.Scalar()},
+	}
+
+	err = distKey.Share.V.UnmarshalBinary(result.Share)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal share: %v", err)
+	}
+
+	st.Lock()
+	st.participants = participants
+	st.pubkeys = pubkeys
+	st.threshold = result.Threshold
+	st.distrKey = distKey.Public()
+	st.poly = share.NewPubPoly(suite, nil, commits)
+	st.dkgState = certified
+	st.Unlock()
+
+	return distKey, nil
+}
+
+func (s storage) read(key []byte) ([]byte, error) {
+	var data []byte
+
+	err := s.db.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(s.bucket)
+		if bucket == nil {
+			return nil
+		}
+
+		value := bucket.Get(s.key(key))
+		if value != nil {
+			data = append([]byte{}, value...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read db: %v", err)
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	if loader.IsEncrypted(data) {
+		if len(s.passphrase) == 0 {
+			return nil, xerrors.New("value is encrypted but no passphrase is given")
+		}
+
+		data, err = loader.Decrypt(data, s.passphrase)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decrypt: %v", err)
+		}
+	} else if len(s.passphrase) > 0 {
+		return nil, xerrors.New("value is not encrypted but a passphrase is given")
+	}
+
+	return data, nil
+}
+
+func (s storage) write(key, data []byte) error {
+	if len(s.passphrase) > 0 {
+		var err error
+
+		data, err = loader.Encrypt(data, s.passphrase)
+		if err != nil {
+			return xerrors.Errorf("failed to encrypt: %v", err)
+		}
+	}
+
+	return s.db.Update(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate(s.bucket)
+		if err != nil {
+			return xerrors.Errorf("bucket: %v", err)
+		}
+
+		err = bucket.Set(s.key(key), data)
+		if err != nil {
+			return xerrors.Errorf("while writing to bucket: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// key returns the key of the value for the instance of the storage.
+func (s storage) key(name []byte) []byte {
+	if s.id == "" {
+		return name
+	}
+
+	return []byte(string(name) + idSeparator + s.id)
+}
+
+// StoredIDs returns the names of the DKG instances that have stored a result
+// in the database, which are the ones to restore when a node starts. The
+// default instance is not part of the list.
+func StoredIDs(db kv.DB) ([]string, error) {
+	prefix := []byte(string(keyResult) + idSeparator)
+
+	var ids []string
+
+	err := db.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(defaultBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(key, value []byte) error {
+			if bytes.HasPrefix(key, prefix) {
+				ids = append(ids, string(key[len(prefix):]))
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read db: %v", err)
+	}
+
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+func marshalPoints(points []kyber.Point) ([][]byte, error) {
+	data := make([][]byte, len(points))
+
+	for i, point := range points {
+		buf, err := point.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal point: %v", err)
+		}
+
+		data[i] = buf
+	}
+
+	return data, nil
+}
+
+func unmarshalPoints(data [][]byte) ([]kyber.Point, error) {
+	points := make([]kyber.Point, len(data))
+
+	for i, buf := range data {
+		points[i] = suite.Point()
+
+		err := points[i].UnmarshalBinary(buf)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to unmarshal point: %v", err)
+		}
+	}
+
+	return points, nil
+}
+
+// storedDKG is the DKG service of a node that restored its result from the
+// storage. It only provides the distributed key share, which is enough for the
+// node to take part in a resharing.
+//
+// - implements dkgService
+type storedDKG struct {
+	distKey *pedersen.DistKeyShare
+}
+
+// Deals implements dkgService. It always returns an error.
+func (storedDKG) Deals() (map[int]*pedersen.Deal, error) {
+	return nil, xerrors.New(errRestored)
+}
+
+// ProcessResponse implements dkgService. It always returns an error.
+func (storedDKG) ProcessResponse(*pedersen.Response) (*pedersen.Justification, error) {
+	return nil, xerrors.New(errRestored)
+}
+
+// Certified implements dkgService. A restored DKG is always certified.
+func (storedDKG) Certified() bool {
+	return true
+}
+
+// DistKeyShare implements dkgService. It returns the restored key share.
+func (d storedDKG) DistKeyShare() (*pedersen.DistKeyShare, error) {
+	return d.distKey, nil
+}
+
+// ProcessDeal implements dkgService. It always returns an error.
+func (storedDKG) ProcessDeal(*pedersen.Deal) (*pedersen.Response, error) {
+	return nil, xerrors.New(errRestored)
+}
+
+// ProcessJustification implements dkgService. It always returns an error.
+func (storedDKG) ProcessJustification(*pedersen.Justification) error {
+	return xerrors.New(errRestored)
+}
+
+// SetTimeout implements dkgService. It does nothing.
+func (storedDKG) SetTimeout() {}
+
+// ThresholdCertified implements dkgService. A restored DKG is always certified.
+func (storedDKG) ThresholdCertified() bool {
+	return true
+}
+
+// QUAL implements dkgService. It returns nil as the deals are not kept.
+func (storedDKG) QUAL() []int {
+	return nil
+}
+
+// Verifiers implements dkgService. It returns nil as the deals are not kept.
+func (storedDKG) Verifiers() map[uint32]*vss.Verifier {
+	return nil
+}
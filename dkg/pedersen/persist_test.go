@@ -0,0 +1,379 @@
+package pedersen
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	pedersen "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v3/sign/eddsa"
+)
+
+func TestPedersen_RestartScenario(t *testing.T) {
+	oldLog := dela.Logger
+	defer func() {
+		dela.Logger = oldLog
+	}()
+
+	dela.Logger = dela.Logger.Level(zerolog.WarnLevel)
+
+	nbNodes := 4
+
+	dbs := make([]kv.DB, nbNodes)
+	for i := range dbs {
+		dbs[i] = fake.NewInMemoryDB()
+	}
+
+	// Only the first node encrypts its values.
+	passphrases := make([][]byte, nbNodes)
+	passphrases[0] = []byte("abc")
+
+	start := func() ([]dkg.Actor, []*Pedersen, CollectiveAuthority) {
+		manager := minoch.NewManager()
+
+		addrs := make([]mino.Address, nbNodes)
+		pubkeys := make([]kyber.Point, nbNodes)
+		dkgs := make([]*Pedersen, nbNodes)
+
+		for i := range dkgs {
+			m := minoch.MustCreate(manager, fmt.Sprint("node", i))
+
+			d, pubkey, err := NewPersistentPedersen(m, dbs[i], passphrases[i])
+			require.NoError(t, err)
+
+			addrs[i] = m.GetAddress()
+			pubkeys[i] = pubkey
+			dkgs[i] = d
+		}
+
+		actors := make([]dkg.Actor, nbNodes)
+		for i, d := range dkgs {
+			actor, err := d.Listen()
+			require.NoError(t, err)
+
+			actors[i] = actor
+		}
+
+		return actors, dkgs, NewAuthority(addrs, pubkeys)
+	}
+
+	actors, dkgs, authority := start()
+
+	restored, err := dkgs[0].HasResult()
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	pubkey, err := actors[0].Setup(authority, 3)
+	require.NoError(t, err)
+
+	message := []byte("Hello world")
+
+	K, Cs, err := actors[1].Encrypt(message)
+	require.NoError(t, err)
+
+	// Every node restarts with the same database.
+	actors, dkgs, restartedAuthority := start()
+	require.Equal(t, authority.Len(), restartedAuthority.Len())
+
+	for i, d := range dkgs {
+		restored, err := d.HasResult()
+		require.NoError(t, err)
+		require.True(t, restored)
+
+		key, err := actors[i].GetPublicKey()
+		require.NoError(t, err)
+		require.True(t, pubkey.Equal(key))
+	}
+
+	_, err = actors[0].Setup(restartedAuthority, 3)
+	require.EqualError(t, err, "startRes is already done, only one setup call is allowed")
+
+	decrypted, err := actors[2].Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+
+	sig, err := actors[3].Sign(message)
+	require.NoError(t, err)
+	require.NoError(t, eddsa.Verify(pubkey, message, sig))
+
+	// The restored shares can be reshared, and the new shares are stored.
+	err = actors[0].Reshare(restartedAuthority, 4)
+	require.NoError(t, err)
+
+	actors, _, _ = start()
+
+	decrypted, err = actors[1].Decrypt(K, Cs)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+}
+
+func TestPedersen_NewPersistentFail(t *testing.T) {
+	_, _, err := NewPersistentPedersen(fake.Mino{}, fake.NewBadViewDB(), nil)
+	require.EqualError(t, err,
+		fake.Err("failed to load key: failed to read key: failed to read db"))
+
+	_, _, err = NewPersistentPedersen(fake.Mino{}, fake.NewBadDB(), nil)
+	require.EqualError(t, err, fake.Err("failed to load key: failed to write key: bucket"))
+
+	db := fake.NewInMemoryDB()
+	store := storage{db: db, bucket: defaultBucket}
+
+	err = store.write(keyPrivate, []byte{1, 2, 3})
+	require.NoError(t, err)
+
+	_, _, err = NewPersistentPedersen(fake.Mino{}, db, nil)
+	require.EqualError(t, err, "failed to load key: failed to unmarshal key: "+
+		"wrong size buffer")
+}
+
+func TestPedersen_HasResult(t *testing.T) {
+	p, _ := NewPedersen(fake.Mino{})
+
+	restored, err := p.HasResult()
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	p.store = &storage{db: fake.NewBadViewDB()}
+
+	_, err = p.HasResult()
+	require.EqualError(t, err, fake.Err("failed to read result: failed to read db"))
+}
+
+func TestPedersen_ListenRestoreFail(t *testing.T) {
+	p, _ := NewPedersen(fake.Mino{})
+	p.store = &storage{db: fake.NewBadViewDB()}
+
+	_, err := p.Listen()
+	require.EqualError(t, err, fake.Err("failed to restore: failed to load: "+
+		"failed to read result: failed to read db"))
+}
+
+func TestStorage_SaveLoad(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+
+	_, commits := pubPoly.Info()
+
+	distKey := &pedersen.DistKeyShare{
+		Commits: commits,
+		Share:   priPoly.Shares(3)[1],
+	}
+
+	st := &state{
+		participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+		pubkeys:      []kyber.Point{suite.Point().Pick(suite.RandomStream())},
+		threshold:    2,
+	}
+
+	for _, passphrase := range [][]byte{nil, []byte("abc")} {
+		store := storage{
+			db:         fake.NewInMemoryDB(),
+			bucket:     defaultBucket,
+			passphrase: passphrase,
+			addrFac:    fake.AddressFactory{},
+		}
+
+		restored := &state{}
+
+		res, err := store.load(restored)
+		require.NoError(t, err)
+		require.Nil(t, res)
+		require.False(t, restored.Done())
+
+		err = store.save(st, distKey)
+		require.NoError(t, err)
+
+		res, err = store.load(restored)
+		require.NoError(t, err)
+		require.Equal(t, distKey.Share.I, res.Share.I)
+		require.True(t, distKey.Share.V.Equal(res.Share.V))
+		require.True(t, res.Public().Equal(pubPoly.Commit()))
+
+		require.True(t, restored.Done())
+		require.Equal(t, st.participants, restored.participants)
+		require.Equal(t, st.threshold, restored.threshold)
+		require.Len(t, restored.pubkeys, 1)
+		require.True(t, st.pubkeys[0].Equal(restored.pubkeys[0]))
+		require.True(t, restored.distrKey.Equal(pubPoly.Commit()))
+		require.True(t, restored.poly.Equal(pubPoly))
+	}
+}
+
+func TestStorage_SaveFail(t *testing.T) {
+	store := storage{db: fake.NewInMemoryDB(), bucket: defaultBucket}
+
+	distKey := &pedersen.DistKeyShare{
+		Commits: []kyber.Point{suite.Point()},
+		Share:   &share.PriShare{V: suite.Scalar()},
+	}
+
+	err := store.save(&state{participants: []mino.Address{fake.NewBadAddress()}}, distKey)
+	require.EqualError(t, err, fake.Err("failed to marshal address"))
+
+	err = store.save(&state{pubkeys: []kyber.Point{badPoint{}}}, distKey)
+	require.EqualError(t, err, fake.Err("public keys: failed to marshal point"))
+
+	err = store.save(&state{}, &pedersen.DistKeyShare{
+		Commits: []kyber.Point{badPoint{}},
+		Share:   distKey.Share,
+	})
+	require.EqualError(t, err, fake.Err("commitments: failed to marshal point"))
+
+	err = store.save(&state{}, &pedersen.DistKeyShare{
+		Share: &share.PriShare{V: badScalar{}},
+	})
+	require.EqualError(t, err, fake.Err("failed to marshal share"))
+
+	db := fake.NewInMemoryDB()
+	db.SetBucket(defaultBucket, fake.NewBadWriteBucket())
+	store.db = db
+
+	err = store.save(&state{}, distKey)
+	require.EqualError(t, err,
+		fake.Err("failed to write result: while writing to bucket"))
+
+	store.db = fake.NewBadDB()
+
+	err = store.save(&state{}, distKey)
+	require.EqualError(t, err, fake.Err("failed to write result: bucket"))
+}
+
+func TestStorage_LoadFail(t *testing.T) {
+	store := storage{
+		db:      fake.NewInMemoryDB(),
+		bucket:  defaultBucket,
+		addrFac: fake.AddressFactory{},
+	}
+
+	err := store.write(keyResult, []byte("{"))
+	require.NoError(t, err)
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "failed to unmarshal result: unexpected end of JSON input")
+
+	err = store.write(keyResult, []byte(`{"PublicKeys":["AA=="]}`))
+	require.NoError(t, err)
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "public keys: failed to unmarshal point: "+
+		"invalid Ed25519 curve point")
+
+	err = store.write(keyResult, []byte(`{"Commitments":["AA=="]}`))
+	require.NoError(t, err)
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "commitments: failed to unmarshal point: "+
+		"invalid Ed25519 curve point")
+
+	err = store.write(keyResult, []byte(`{}`))
+	require.NoError(t, err)
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "commitments are missing")
+
+	commit, err := suite.Point().MarshalBinary()
+	require.NoError(t, err)
+
+	err = store.write(keyResult, []byte(fmt.Sprintf(`{"Commitments":["%s"]}`,
+		base64.StdEncoding.EncodeToString(commit))))
+	require.NoError(t, err)
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "failed to unmarshal share: wrong size buffer")
+
+	store.passphrase = []byte("abc")
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "failed to read result: value is not encrypted "+
+		"but a passphrase is given")
+
+	err = store.write(keyResult, []byte(`{}`))
+	require.NoError(t, err)
+
+	store.passphrase = []byte("def")
+
+	_, err = store.load(&state{})
+	require.Error(t, err)
+	require.Regexp(t, "^failed to read result: failed to decrypt:", err.Error())
+
+	store.passphrase = nil
+
+	_, err = store.load(&state{})
+	require.EqualError(t, err, "failed to read result: value is encrypted but "+
+		"no passphrase is given")
+}
+
+func TestStorage_Key(t *testing.T) {
+	store := storage{}
+	require.Equal(t, []byte("result"), store.key(keyResult))
+
+	store.id = "abc"
+	require.Equal(t, []byte("result/abc"), store.key(keyResult))
+}
+
+func TestStoredIDs(t *testing.T) {
+	db := fake.NewInMemoryDB()
+
+	ids, err := StoredIDs(db)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	for _, id := range []string{"", "b", "a"} {
+		store := storage{db: db, bucket: defaultBucket, id: id}
+
+		require.NoError(t, store.write(keyResult, []byte{}))
+		require.NoError(t, store.write(keyPrivate, []byte{}))
+	}
+
+	store := storage{db: db, bucket: defaultBucket, id: "c"}
+	require.NoError(t, store.write(keyPrivate, []byte{}))
+
+	ids, err = StoredIDs(db)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ids)
+
+	_, err = StoredIDs(fake.NewBadViewDB())
+	require.EqualError(t, err, fake.Err("failed to read db"))
+}
+
+func TestStoredDKG(t *testing.T) {
+	distKey := &pedersen.DistKeyShare{}
+
+	d := storedDKG{distKey: distKey}
+
+	require.True(t, d.Certified())
+
+	res, err := d.DistKeyShare()
+	require.NoError(t, err)
+	require.Same(t, distKey, res)
+
+	_, err = d.Deals()
+	require.EqualError(t, err, errRestored)
+
+	_, err = d.ProcessResponse(nil)
+	require.EqualError(t, err, errRestored)
+
+	_, err = d.ProcessDeal(nil)
+	require.EqualError(t, err, errRestored)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badScalar struct {
+	kyber.Scalar
+}
+
+func (badScalar) MarshalBinary() ([]byte, error) {
+	return nil, fake.GetError()
+}
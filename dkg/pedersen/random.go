@@ -0,0 +1,85 @@
+// This file contains the source of randomness of a DKG. The secrets, the
+// nonces and the ephemeral keys are drawn from the system randomness, unless
+// the DKG is given a reader, in which case they are drawn from a stream seeded
+// by the reader so that a simulation can reproduce them.
+
+package pedersen
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"sync"
+
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/suites"
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+// lockedStream is a stream of randomness that the routines of a DKG can share.
+//
+// - implements cipher.Stream
+type lockedStream struct {
+	sync.Mutex
+
+	stream cipher.Stream
+}
+
+// XORKeyStream implements cipher.Stream. It draws the next bytes of the stream.
+func (s *lockedStream) XORKeyStream(dst, src []byte) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.stream.XORKeyStream(dst, src)
+}
+
+// streamReader is a reader of the bytes of a stream of randomness.
+//
+// - implements io.Reader
+type streamReader struct {
+	stream cipher.Stream
+}
+
+// Read implements io.Reader. It fills the buffer with the next bytes of the
+// stream.
+func (r streamReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+
+	r.stream.XORKeyStream(p, p)
+
+	return len(p), nil
+}
+
+// newStream returns the stream of randomness seeded by the reader of the
+// template, or nil if the template has no reader.
+func newStream(tmpl template) cipher.Stream {
+	if tmpl.random == nil {
+		return nil
+	}
+
+	return &lockedStream{stream: random.New(tmpl.random)}
+}
+
+// suiteWith returns the suite of the DKG that draws its randomness from the
+// stream, or the default suite if the stream is nil.
+func suiteWith(stream cipher.Stream) suites.Suite {
+	if stream == nil {
+		return suite
+	}
+
+	return edwards25519.NewBlakeSHA256Ed25519WithRand(stream)
+}
+
+// readRandom fills the buffer with the bytes of the stream, or with the system
+// randomness if the stream is nil.
+func readRandom(stream cipher.Stream, buf []byte) error {
+	if stream == nil {
+		_, err := rand.Read(buf)
+		return err
+	}
+
+	_, err := streamReader{stream: stream}.Read(buf)
+
+	return err
+}
@@ -0,0 +1,294 @@
+// This file contains the implementation of the threshold signature. It is a
+// two-round Schnorr signature in the spirit of FROST: a threshold of the
+// participants first commit to a pair of nonces, then each of them signs with
+// its share of the distributed key. The combination of the shares is a regular
+// EdDSA signature that verifies against the distributed public key.
+
+package pedersen
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"time"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/internal/tracing"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	"golang.org/x/xerrors"
+)
+
+// protocolNameSign denotes the value of the protocol span tag associated with
+// the `dkg-sign` protocol.
+const protocolNameSign = "dkg-sign"
+
+// sessionLength is the length in bytes of the identifier of a signing session.
+const sessionLength = 32
+
+// maxSignSessions is the maximum number of signing sessions a participant keeps
+// the nonces of at the same time.
+const maxSignSessions = 1000
+
+// signNonces is the pair of nonces a participant commits to for a signing
+// session. They expire with the timeout of the session, as no signature can be
+// requested afterwards.
+type signNonces struct {
+	d      kyber.Scalar
+	e      kyber.Scalar
+	expiry time.Time
+}
+
+// signSession contains the values derived from the commitments of the
+// participants that every one of them computes the same way.
+type signSession struct {
+	commitments map[int64]types.SignCommitment
+	bindings    map[int64]kyber.Scalar
+	R           kyber.Point
+	c           kyber.Scalar
+}
+
+// Sign implements dkg.Actor. It gathers the commitments of a threshold of the
+// participants, then requests and combines their shares of the signature. The
+// signature can be verified against the public key with eddsa.Verify.
+func (a *Actor) Sign(message []byte) ([]byte, error) {
+	if !a.startRes.Done() {
+		return nil, xerrors.Errorf(initDkgFirst)
+	}
+
+	ctx, cancel := a.withTimeout(decryptTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, tracing.ProtocolKey, protocolNameSign)
+
+	addrs := a.startRes.getParticipants()
+
+	sender, receiver, err := a.rpc.Stream(ctx, mino.NewAddresses(addrs...))
+	if err != nil {
+		return nil, xerrors.Errorf(failedStreamCreation, err)
+	}
+
+	sessionID := make([]byte, sessionLength)
+
+	err = readRandom(a.random, sessionID)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate session: %v", err)
+	}
+
+	err = <-sender.Send(types.NewSignCommitRequest(sessionID), addrs...)
+	if err != nil {
+		// The participants that are reachable might be enough.
+		dela.Logger.Warn().Err(err).Msg("failed to send sign commit request")
+	}
+
+	threshold := a.startRes.getThreshold()
+
+	commitments := make([]types.SignCommitment, 0, threshold)
+	signers := make([]mino.Address, 0, threshold)
+	received := make(map[int64]struct{})
+
+	for len(commitments) < threshold {
+		from, msg, err := receiver.Recv(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf(unexpectedStreamStop, err)
+		}
+
+		reply, ok := msg.(types.SignCommitReply)
+		if !ok {
+			return nil, xerrors.Errorf(unexpectedReply, reply, msg)
+		}
+
+		commitment := reply.GetCommitment()
+
+		_, found := received[commitment.I]
+		if found {
+			continue
+		}
+
+		received[commitment.I] = struct{}{}
+		commitments = append(commitments, commitment)
+		signers = append(signers, from)
+	}
+
+	pubkey := a.startRes.getDistKey()
+
+	session, err := newSignSession(pubkey, message, commitments)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create session: %v", err)
+	}
+
+	err = <-sender.Send(types.NewSignRequest(sessionID, message, commitments), signers...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to send sign request: %v", err)
+	}
+
+	poly := a.startRes.getPubPoly()
+	s := suite.Scalar().Zero()
+	signed := make(map[int64]struct{})
+
+	for len(signed) < threshold {
+		_, msg, err := receiver.Recv(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf(unexpectedStreamStop, err)
+		}
+
+		// The participants that were too late for the session might still
+		// send their commitment.
+		_, ok := msg.(types.SignCommitReply)
+		if ok {
+			continue
+		}
+
+		reply, ok := msg.(types.SignReply)
+		if !ok {
+			return nil, xerrors.Errorf(unexpectedReply, reply, msg)
+		}
+
+		_, found := signed[reply.GetIndex()]
+		if found {
+			continue
+		}
+
+		err = session.check(poly, reply.GetIndex(), reply.GetShare())
+		if err != nil {
+			return nil, xerrors.Errorf("invalid share %d: %v", reply.GetIndex(), err)
+		}
+
+		signed[reply.GetIndex()] = struct{}{}
+		s.Add(s, reply.GetShare())
+	}
+
+	R, err := session.R.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal R: %v", err)
+	}
+
+	S, err := s.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal S: %v", err)
+	}
+
+	return append(R, S...), nil
+}
+
+// newSignSession computes the group commitment of the session, which is the
+// sum of the commitments weighted by the binding factors, and the challenge of
+// the signature as defined by EdDSA.
+func newSignSession(pubkey kyber.Point, message []byte,
+	commitments []types.SignCommitment) (signSession, error) {
+
+	session := signSession{
+		commitments: make(map[int64]types.SignCommitment),
+		bindings:    make(map[int64]kyber.Scalar),
+		R:           suite.Point().Null(),
+	}
+
+	// The binding factors depend on the commitments of every participant so
+	// that no one can change the group commitment by choosing its nonces
+	// after seeing the others.
+	h := sha256.New()
+	h.Write(message)
+
+	for _, commitment := range commitments {
+		_, found := session.commitments[commitment.I]
+		if found {
+			return session, xerrors.Errorf("duplicate commitment %d", commitment.I)
+		}
+
+		session.commitments[commitment.I] = commitment
+
+		h.Write(signIndex(commitment.I))
+		commitment.D.MarshalTo(h)
+		commitment.E.MarshalTo(h)
+	}
+
+	digest := h.Sum(nil)
+
+	for _, commitment := range commitments {
+		binding := sha512.New()
+		binding.Write(signIndex(commitment.I))
+		binding.Write(digest)
+
+		rho := suite.Scalar().SetBytes(binding.Sum(nil))
+		session.bindings[commitment.I] = rho
+
+		session.R.Add(session.R, commitment.D)
+		session.R.Add(session.R, suite.Point().Mul(rho, commitment.E))
+	}
+
+	challenge := sha512.New()
+	session.R.MarshalTo(challenge)
+	pubkey.MarshalTo(challenge)
+	challenge.Write(message)
+
+	session.c = suite.Scalar().SetBytes(challenge.Sum(nil))
+
+	return session, nil
+}
+
+// sign returns the share of the signature of the participant for its nonces
+// and private share.
+func (s signSession) sign(nonces signNonces, priShare *share.PriShare) kyber.Scalar {
+	i := int64(priShare.I)
+
+	z := suite.Scalar().Mul(s.bindings[i], nonces.e)
+	z.Add(z, nonces.d)
+
+	lambda := s.lagrange(i)
+	lambda.Mul(lambda, s.c)
+
+	return z.Add(z, lambda.Mul(lambda, priShare.V))
+}
+
+// check verifies the share of the signature of a participant against its
+// public share, evaluated from the public polynomial.
+func (s signSession) check(poly *share.PubPoly, i int64, z kyber.Scalar) error {
+	commitment, found := s.commitments[i]
+	if !found {
+		return xerrors.New("not in the session")
+	}
+
+	lambda := s.lagrange(i)
+	lambda.Mul(lambda, s.c)
+
+	expected := suite.Point().Mul(s.bindings[i], commitment.E)
+	expected.Add(expected, commitment.D)
+	expected.Add(expected, suite.Point().Mul(lambda, poly.Eval(int(i)).V))
+
+	if !suite.Point().Mul(z, nil).Equal(expected) {
+		return xerrors.New("share doesn't match the commitment")
+	}
+
+	return nil
+}
+
+// lagrange returns the Lagrange coefficient of the participant at zero for the
+// participants of the session.
+func (s signSession) lagrange(i int64) kyber.Scalar {
+	xi := suite.Scalar().SetInt64(i + 1)
+
+	num := suite.Scalar().One()
+	den := suite.Scalar().One()
+
+	for j := range s.commitments {
+		if j == i {
+			continue
+		}
+
+		xj := suite.Scalar().SetInt64(j + 1)
+
+		num.Mul(num, xj)
+		den.Mul(den, suite.Scalar().Sub(xj, xi))
+	}
+
+	return num.Div(num, den)
+}
+
+func signIndex(i int64) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, uint64(i))
+
+	return buffer
+}
@@ -0,0 +1,348 @@
+package pedersen
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/testing/fake"
+	"go.dedis.ch/kyber/v3/share"
+	"go.dedis.ch/kyber/v3/sign/eddsa"
+)
+
+func TestPedersen_Sign(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+	priShares := priPoly.Shares(3)
+	message := []byte("abc")
+
+	nonces, commitments := makeSignNonces(priShares[1], priShares[2])
+
+	session, err := newSignSession(pubPoly.Commit(), message, commitments)
+	require.NoError(t, err)
+
+	z1 := session.sign(nonces[0], priShares[1])
+	z2 := session.sign(nonces[1], priShares[2])
+
+	actor := Actor{
+		rpc: fake.NewBadRPC(),
+		startRes: &state{
+			dkgState:     certified,
+			participants: []mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+			distrKey:     pubPoly.Commit(),
+			poly:         pubPoly,
+			threshold:    2,
+		},
+	}
+
+	_, err = actor.Sign(message)
+	require.EqualError(t, err, fake.Err("failed to create stream"))
+
+	recv := fake.NewReceiver(fake.NewRecvMsg(fake.NewAddress(0), nil))
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	_, err = actor.Sign(message)
+	require.EqualError(t, err,
+		"got unexpected reply, expected types.SignCommitReply but got: <nil>")
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignCommitReply(commitments[0])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignCommitReply(commitments[1])),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.NewBadSender())
+
+	_, err = actor.Sign(message)
+	require.EqualError(t, err, fake.Err("failed to send sign request"))
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignCommitReply(commitments[0])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignCommitReply(commitments[1])),
+		fake.NewRecvMsg(fake.NewAddress(1), nil),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	_, err = actor.Sign(message)
+	require.EqualError(t, err,
+		"got unexpected reply, expected types.SignReply but got: <nil>")
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignCommitReply(commitments[0])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignCommitReply(commitments[1])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignReply(2, z1)),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	_, err = actor.Sign(message)
+	require.EqualError(t, err, "invalid share 2: share doesn't match the commitment")
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignCommitReply(commitments[0])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignCommitReply(commitments[1])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignReply(1, z1)),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	_, err = actor.Sign(message)
+	require.EqualError(t, err, "stream stopped unexpectedly: EOF")
+
+	recv = fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignCommitReply(commitments[0])),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignCommitReply(commitments[0])),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignCommitReply(commitments[1])),
+		fake.NewRecvMsg(fake.NewAddress(2), types.NewSignCommitReply(types.SignCommitment{})),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignReply(1, z1)),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSignReply(1, z1)),
+		fake.NewRecvMsg(fake.NewAddress(1), types.NewSignReply(2, z2)),
+	)
+	actor.rpc = fake.NewStreamRPC(recv, fake.Sender{})
+
+	sig, err := actor.Sign(message)
+	require.NoError(t, err)
+	require.NoError(t, eddsa.Verify(pubPoly.Commit(), message, sig))
+	require.Error(t, eddsa.Verify(pubPoly.Commit(), []byte("def"), sig))
+}
+
+func TestPedersen_SignNotDone(t *testing.T) {
+	actor := Actor{
+		startRes: &state{dkgState: initial},
+	}
+
+	_, err := actor.Sign(nil)
+	require.EqualError(t, err, initDkgFirst)
+}
+
+func TestSignSession_Scenario(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 3, nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+	priShares := priPoly.Shares(5)
+	message := []byte("abc")
+
+	// Any threshold of the participants produces a valid signature.
+	for _, signers := range [][]*share.PriShare{priShares[:3], priShares[2:], {
+		priShares[4], priShares[0], priShares[2],
+	}} {
+		nonces, commitments := makeSignNonces(signers...)
+
+		session, err := newSignSession(pubPoly.Commit(), message, commitments)
+		require.NoError(t, err)
+
+		s := suite.Scalar().Zero()
+		for i, signer := range signers {
+			z := session.sign(nonces[i], signer)
+			require.NoError(t, session.check(pubPoly, int64(signer.I), z))
+
+			s.Add(s, z)
+		}
+
+		R, err := session.R.MarshalBinary()
+		require.NoError(t, err)
+
+		S, err := s.MarshalBinary()
+		require.NoError(t, err)
+
+		require.NoError(t, eddsa.Verify(pubPoly.Commit(), message, append(R, S...)))
+	}
+}
+
+func TestSignSession_Fail(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+
+	_, commitments := makeSignNonces(priPoly.Shares(2)...)
+
+	_, err := newSignSession(pubPoly.Commit(), nil,
+		[]types.SignCommitment{commitments[0], commitments[0]})
+	require.EqualError(t, err, "duplicate commitment 0")
+
+	session, err := newSignSession(pubPoly.Commit(), nil, commitments)
+	require.NoError(t, err)
+
+	err = session.check(pubPoly, 5, suite.Scalar())
+	require.EqualError(t, err, "not in the session")
+
+	err = session.check(pubPoly, 0, suite.Scalar())
+	require.EqualError(t, err, "share doesn't match the commitment")
+}
+
+func TestDKGInstance_Sign(t *testing.T) {
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+	priShares := priPoly.Shares(2)
+
+	nonces, commitments := makeSignNonces(priShares[0])
+
+	s := instance{
+		startRes: &state{
+			dkgState: certified,
+			distrKey: pubPoly.Commit(),
+		},
+		privShare: priShares[1],
+	}
+
+	sender := &recordSender{}
+
+	err := s.handleMessage(nil, types.NewSignCommitRequest([]byte{1}), fake.NewAddress(0), sender)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 1)
+
+	commitment := sender.msgs[0].(types.SignCommitReply).GetCommitment()
+	require.Equal(t, int64(1), commitment.I)
+
+	err = s.handleSignCommit(sender, types.NewSignCommitRequest([]byte{1}), nil)
+	require.EqualError(t, err, "session 01 already exists")
+
+	commitments = append(commitments, commitment)
+
+	req := types.NewSignRequest([]byte{1}, []byte("abc"), commitments)
+
+	err = s.handleMessage(nil, req, fake.NewAddress(0), sender)
+	require.NoError(t, err)
+	require.Len(t, sender.msgs, 2)
+
+	reply := sender.msgs[1].(types.SignReply)
+	require.Equal(t, int64(1), reply.GetIndex())
+
+	session, err := newSignSession(pubPoly.Commit(), []byte("abc"), commitments)
+	require.NoError(t, err)
+	require.NoError(t, session.check(pubPoly, 1, reply.GetShare()))
+
+	s0 := session.sign(nonces[0], priShares[0])
+
+	R, err := session.R.MarshalBinary()
+	require.NoError(t, err)
+
+	S, err := suite.Scalar().Add(s0, reply.GetShare()).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, eddsa.Verify(pubPoly.Commit(), []byte("abc"), append(R, S...)))
+
+	// The nonces of a session can only be used once.
+	err = s.handleSign(sender, req, nil)
+	require.EqualError(t, err, "unknown session 01")
+	require.Empty(t, s.nonces)
+}
+
+func TestDKGInstance_SignFail(t *testing.T) {
+	priShare := &share.PriShare{I: 1, V: suite.Scalar().Pick(suite.RandomStream())}
+
+	s := instance{
+		startRes: &state{
+			dkgState: certified,
+			distrKey: suite.Point(),
+		},
+		privShare: priShare,
+	}
+
+	err := s.handleMessage(nil, types.NewSignCommitRequest(nil), nil, &recordSender{})
+	require.NoError(t, err)
+
+	other, commitments := makeSignNonces(&share.PriShare{I: 0, V: suite.Scalar()})
+
+	req := types.NewSignRequest(nil, nil, []types.SignCommitment{commitments[0], commitments[0]})
+
+	err = s.handleSign(fake.NewBadSender(), req, nil)
+	require.EqualError(t, err, "failed to create session: duplicate commitment 0")
+
+	s.nonces = map[string]signNonces{"": other[0]}
+
+	err = s.handleSign(fake.NewBadSender(), types.NewSignRequest(nil, nil, commitments), nil)
+	require.EqualError(t, err, "commitment 1 not in the session")
+
+	// The commitment of the participant has been replaced.
+	commitments[0].I = 1
+	s.nonces = map[string]signNonces{"": {
+		d:      suite.Scalar(),
+		e:      suite.Scalar(),
+		expiry: time.Now().Add(decryptTimeout),
+	}}
+
+	err = s.handleSign(fake.NewBadSender(), types.NewSignRequest(nil, nil, commitments), nil)
+	require.EqualError(t, err, "commitment 1 doesn't match the nonces")
+
+	s.nonces = map[string]signNonces{"": other[0]}
+
+	err = s.handleSign(fake.NewBadSender(), types.NewSignRequest(nil, nil, commitments), nil)
+	require.EqualError(t, err, fake.Err("got an error while sending the sign reply"))
+
+	err = s.handleSignCommit(fake.NewBadSender(), types.NewSignCommitRequest([]byte{2}), nil)
+	require.EqualError(t, err, fake.Err("got an error while sending the sign commit reply"))
+}
+
+func TestDKGInstance_SignExpiry(t *testing.T) {
+	c := clock.NewVirtual(time.Now())
+
+	s := instance{
+		startRes: &state{
+			dkgState: certified,
+			distrKey: suite.Point(),
+		},
+		privShare: &share.PriShare{I: 1, V: suite.Scalar()},
+		clock:     c,
+	}
+
+	err := s.handleSignCommit(&recordSender{}, types.NewSignCommitRequest([]byte{1}), nil)
+	require.NoError(t, err)
+
+	c.Advance(decryptTimeout + time.Second)
+
+	err = s.handleSign(&recordSender{}, types.NewSignRequest([]byte{1}, nil, nil), nil)
+	require.EqualError(t, err, "session 01 expired")
+	require.Empty(t, s.nonces)
+
+	// The expired sessions are dropped when a new one starts.
+	for i := 0; i < maxSignSessions; i++ {
+		s.nonces[fmt.Sprintf("session-%d", i)] = signNonces{expiry: c.Now()}
+	}
+
+	c.Advance(time.Second)
+
+	err = s.handleSignCommit(&recordSender{}, types.NewSignCommitRequest([]byte{2}), nil)
+	require.NoError(t, err)
+	require.Len(t, s.nonces, 1)
+
+	for i := 0; i < maxSignSessions; i++ {
+		s.nonces[fmt.Sprintf("session-%d", i)] = signNonces{expiry: c.Now().Add(time.Second)}
+	}
+
+	err = s.handleSignCommit(&recordSender{}, types.NewSignCommitRequest([]byte{3}), nil)
+	require.EqualError(t, err, "too many signing sessions: 1001")
+}
+
+func TestDKGInstance_HandleSignRequestFail(t *testing.T) {
+	s := instance{
+		startRes: &state{dkgState: 0xaa},
+	}
+
+	err := s.handleMessage(nil, types.SignCommitRequest{}, nil, nil)
+	require.EqualError(t, err, "bad state: unexpected state: UNKNOWN != one of [Certified]")
+
+	err = s.handleMessage(nil, types.SignRequest{}, nil, nil)
+	require.EqualError(t, err, "bad state: unexpected state: UNKNOWN != one of [Certified]")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeSignNonces(priShares ...*share.PriShare) ([]signNonces, []types.SignCommitment) {
+	nonces := make([]signNonces, len(priShares))
+	commitments := make([]types.SignCommitment, len(priShares))
+
+	for i, priShare := range priShares {
+		nonces[i] = signNonces{
+			d:      suite.Scalar().Pick(suite.RandomStream()),
+			e:      suite.Scalar().Pick(suite.RandomStream()),
+			expiry: time.Now().Add(decryptTimeout),
+		}
+
+		commitments[i] = types.SignCommitment{
+			I: int64(priShare.I),
+			D: suite.Point().Mul(nonces[i].d, nil),
+			E: suite.Point().Mul(nonces[i].e, nil),
+		}
+	}
+
+	return nonces, commitments
+}
@@ -3,6 +3,7 @@ package pedersen
 import (
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
 	"golang.org/x/xerrors"
 	"sync"
 )
@@ -50,7 +51,9 @@ type state struct {
 	// participants is set once a sharing or resharing starts
 	participants []mino.Address
 	pubkeys      []kyber.Point
-	//TODO add verifiability (poly         *share.PubPoly)
+	// poly is the public polynomial of the shares, only set once the node is
+	// certified
+	poly      *share.PubPoly
 	threshold int
 	dkgState  dkgState
 }
@@ -127,6 +130,18 @@ func (s *state) setDistKey(key kyber.Point) {
 	s.Unlock()
 }
 
+func (s *state) getPubPoly() *share.PubPoly {
+	s.Lock()
+	defer s.Unlock()
+	return s.poly
+}
+
+func (s *state) setPubPoly(poly *share.PubPoly) {
+	s.Lock()
+	s.poly = poly
+	s.Unlock()
+}
+
 func (s *state) getParticipants() []mino.Address {
 	s.Lock()
 	defer s.Unlock()
@@ -0,0 +1,545 @@
+package pedersen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	pedersen "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+	"golang.org/x/xerrors"
+)
+
+// journal records the messages of a DKG setup that are part of its transcript.
+// Only the messages with a valid signature are recorded, once. A nil journal
+// records nothing.
+type journal struct {
+	threshold int
+	pubkeys   []kyber.Point
+	me        int
+
+	deals          []types.Deal
+	responses      []types.Response
+	justifications []types.Justification
+
+	seenResponses      map[messageKey]struct{}
+	seenJustifications map[messageKey]struct{}
+}
+
+// messageKey identifies the response of a verifier, or the justification of a
+// dealer for a verifier.
+type messageKey struct {
+	dealer   uint32
+	verifier uint32
+}
+
+func newJournal(threshold int, pubkeys []kyber.Point, me int) *journal {
+	return &journal{
+		threshold:          threshold,
+		pubkeys:            pubkeys,
+		me:                 me,
+		seenResponses:      make(map[messageKey]struct{}),
+		seenJustifications: make(map[messageKey]struct{}),
+	}
+}
+
+// addDeal records a deal received by the node.
+func (j *journal) addDeal(deal types.Deal) {
+	if j == nil {
+		return
+	}
+
+	if verifyDealSignature(j.pubkeys, deal) != nil {
+		return
+	}
+
+	j.deals = append(j.deals, deal)
+}
+
+// addResponse records a response of a verifier.
+func (j *journal) addResponse(resp types.Response) {
+	if j == nil {
+		return
+	}
+
+	key := messageKey{dealer: resp.GetIndex(), verifier: resp.GetResponse().GetIndex()}
+
+	_, seen := j.seenResponses[key]
+	if seen || verifyResponseSignature(j.pubkeys, resp) != nil {
+		return
+	}
+
+	j.seenResponses[key] = struct{}{}
+	j.responses = append(j.responses, resp)
+}
+
+// addJustification records a justification of a dealer.
+func (j *journal) addJustification(justif types.Justification) {
+	if j == nil {
+		return
+	}
+
+	key := messageKey{dealer: justif.GetDealer(), verifier: justif.GetIndex()}
+
+	_, seen := j.seenJustifications[key]
+	if seen || verifyJustificationSignature(j.pubkeys, justif) != nil {
+		return
+	}
+
+	j.seenJustifications[key] = struct{}{}
+	j.justifications = append(j.justifications, justif)
+}
+
+// transcript returns the part of the transcript known by the node. The
+// qualified dealers are sorted as the DKG lists them in any order.
+func (j *journal) transcript(commitments [][]kyber.Point, qual []int,
+	pubkey kyber.Point) types.Transcript {
+
+	deals := make([][]types.Deal, len(j.pubkeys))
+	deals[j.me] = j.deals
+
+	qual = append([]int(nil), qual...)
+	sort.Ints(qual)
+
+	return types.NewTranscript(j.threshold, j.pubkeys, deals, j.responses,
+		j.justifications, commitments, qual, pubkey)
+}
+
+// VerifyTranscript checks that the transcript of a DKG setup is consistent:
+// every message must be signed by its author, and the qualified dealers and the
+// public key must follow from the responses and the justifications. It only
+// needs the transcript, so that anyone can verify it offline.
+func VerifyTranscript(transcript types.Transcript) error {
+	qual, pubkey, err := evaluate(transcript)
+	if err != nil {
+		return xerrors.Errorf("invalid transcript: %v", err)
+	}
+
+	if !equalIndices(qual, transcript.GetQualified()) {
+		return xerrors.Errorf("qualified dealers mismatch: %v != %v",
+			transcript.GetQualified(), qual)
+	}
+
+	if transcript.GetPublicKey() == nil || !pubkey.Equal(transcript.GetPublicKey()) {
+		return xerrors.Errorf("public key mismatch: %v != %v",
+			transcript.GetPublicKey(), pubkey)
+	}
+
+	return nil
+}
+
+// Excluded returns the indices of the dealers of the transcript that are not
+// qualified.
+func Excluded(transcript types.Transcript) []int {
+	qualified := make(map[int]struct{})
+	for _, index := range transcript.GetQualified() {
+		qualified[index] = struct{}{}
+	}
+
+	excluded := []int{}
+
+	for i := range transcript.GetPublicKeys() {
+		_, found := qualified[i]
+		if !found {
+			excluded = append(excluded, i)
+		}
+	}
+
+	return excluded
+}
+
+// mergeTranscripts combines the parts of the transcript sent by the nodes, and
+// derives the qualified dealers and the public key from the result. The part
+// of a node is at its index. A justification only counts when another node
+// than its dealer received it, so that a dealer cannot hide it from the others.
+func mergeTranscripts(threshold int, pubkeys []kyber.Point,
+	parts []types.Transcript) (types.Transcript, error) {
+
+	n := len(pubkeys)
+
+	deals := make([][]types.Deal, n)
+	commitments := make([][]kyber.Point, n)
+
+	j := newJournal(threshold, pubkeys, 0)
+
+	for owner, part := range parts {
+		for i, received := range part.GetDeals() {
+			if i < n && len(deals[i]) == 0 {
+				deals[i] = received
+			}
+		}
+
+		for _, resp := range part.GetResponses() {
+			j.addResponse(resp)
+		}
+
+		for _, justif := range part.GetJustifications() {
+			if int(justif.GetDealer()) != owner {
+				j.addJustification(justif)
+			}
+		}
+	}
+
+	// The commitments of a dealer are the ones that the verifiers signed in
+	// their responses, through the session ID.
+	for dealer := range commitments {
+		for _, part := range parts {
+			if dealer >= len(part.GetCommitments()) || part.GetCommitments()[dealer] == nil {
+				continue
+			}
+
+			candidate := part.GetCommitments()[dealer]
+
+			if commitments[dealer] == nil {
+				commitments[dealer] = candidate
+			}
+
+			sid := sessionID(pubkeys[dealer], pubkeys, candidate, threshold)
+
+			if hasResponseForSession(j.responses, uint32(dealer), sid) {
+				commitments[dealer] = candidate
+				break
+			}
+		}
+	}
+
+	merged := types.NewTranscript(threshold, pubkeys, deals, j.responses,
+		j.justifications, commitments, nil, nil)
+
+	qual, pubkey, err := evaluate(merged)
+	if err != nil {
+		return types.Transcript{}, xerrors.Errorf("failed to evaluate: %v", err)
+	}
+
+	merged = types.NewTranscript(threshold, pubkeys, deals, j.responses,
+		j.justifications, commitments, qual, pubkey)
+
+	return merged, nil
+}
+
+// evaluate returns the qualified dealers of the transcript and the resulting
+// public key. A deal is certified when there is no complaint left after the
+// justifications, at least a threshold of approvals, and at most n-t absent
+// responses, which are the conditions of Kyber after the timeout. The dealer
+// implicitly approves its own deal.
+func evaluate(transcript types.Transcript) ([]int, kyber.Point, error) {
+	pubkeys := transcript.GetPublicKeys()
+	threshold := transcript.GetThreshold()
+	n := len(pubkeys)
+
+	if threshold < 1 || threshold > n {
+		return nil, nil, xerrors.Errorf("invalid threshold %d for %d participants", threshold, n)
+	}
+
+	if len(transcript.GetCommitments()) != n {
+		return nil, nil, xerrors.Errorf("expected commitments for %d dealers, got %d",
+			n, len(transcript.GetCommitments()))
+	}
+
+	if len(transcript.GetDeals()) > n {
+		return nil, nil, xerrors.Errorf("expected deals for %d participants, got %d",
+			n, len(transcript.GetDeals()))
+	}
+
+	for i, received := range transcript.GetDeals() {
+		for _, deal := range received {
+			err := verifyDealSignature(pubkeys, deal)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("invalid deal of %d for %d: %v",
+					deal.GetIndex(), i, err)
+			}
+		}
+	}
+
+	responses := make(map[messageKey]types.Response)
+
+	for _, resp := range transcript.GetResponses() {
+		err := verifyResponseSignature(pubkeys, resp)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("invalid response of %d for %d: %v",
+				resp.GetResponse().GetIndex(), resp.GetIndex(), err)
+		}
+
+		key := messageKey{dealer: resp.GetIndex(), verifier: resp.GetResponse().GetIndex()}
+
+		_, found := responses[key]
+		if !found {
+			responses[key] = resp
+		}
+	}
+
+	justifications := make(map[messageKey]types.Justification)
+
+	for _, justif := range transcript.GetJustifications() {
+		err := verifyJustificationSignature(pubkeys, justif)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("invalid justification of %d for %d: %v",
+				justif.GetDealer(), justif.GetIndex(), err)
+		}
+
+		key := messageKey{dealer: justif.GetDealer(), verifier: justif.GetIndex()}
+
+		_, found := justifications[key]
+		if !found {
+			justifications[key] = justif
+		}
+	}
+
+	qual := []int{}
+	pubkey := suite.Point().Null()
+
+	for dealer, commitments := range transcript.GetCommitments() {
+		if len(commitments) == 0 {
+			continue
+		}
+
+		sid := sessionID(pubkeys[dealer], pubkeys, commitments, threshold)
+
+		approvals := 1
+		absents := 0
+		complaint := false
+
+		for verifier := 0; verifier < n; verifier++ {
+			if verifier == dealer {
+				continue
+			}
+
+			key := messageKey{dealer: uint32(dealer), verifier: uint32(verifier)}
+
+			resp, found := responses[key]
+			if !found || !bytes.Equal(resp.GetResponse().GetSessionID(), sid) {
+				absents++
+				continue
+			}
+
+			if resp.GetResponse().GetStatus() == vss.StatusApproval {
+				approvals++
+				continue
+			}
+
+			justif, found := justifications[key]
+			if found && checkJustification(justif, sid, commitments, threshold) == nil {
+				approvals++
+			} else {
+				complaint = true
+			}
+		}
+
+		if !complaint && approvals >= threshold && absents <= n-threshold {
+			qual = append(qual, dealer)
+			pubkey = suite.Point().Add(pubkey, commitments[0])
+		}
+	}
+
+	if len(qual) < threshold {
+		return nil, nil, xerrors.Errorf("only %d qualified dealers for a threshold of %d",
+			len(qual), threshold)
+	}
+
+	return qual, pubkey, nil
+}
+
+// checkJustification verifies that the share revealed by the justification is
+// the one expected by the commitments of the deal.
+func checkJustification(justif types.Justification, sid []byte,
+	commitments []kyber.Point, threshold int) error {
+
+	if !bytes.Equal(justif.GetSessionID(), sid) {
+		return xerrors.New("wrong session")
+	}
+
+	if int(justif.GetThreshold()) != threshold {
+		return xerrors.Errorf("wrong threshold %d", justif.GetThreshold())
+	}
+
+	if !equalPoints(justif.GetCommitments(), commitments) {
+		return xerrors.New("wrong commitments")
+	}
+
+	priShare := justif.GetShare()
+	if priShare == nil || priShare.V == nil || priShare.I != int(justif.GetIndex()) {
+		return xerrors.New("wrong share index")
+	}
+
+	pubShare := share.NewPubPoly(suite, nil, commitments).Eval(priShare.I)
+
+	if !suite.Point().Mul(priShare.V, nil).Equal(pubShare.V) {
+		return xerrors.New("share does not match the commitments")
+	}
+
+	return nil
+}
+
+// verifyDealSignature verifies the signature of the dealer over the deal.
+func verifyDealSignature(pubkeys []kyber.Point, deal types.Deal) error {
+	if int(deal.GetIndex()) >= len(pubkeys) {
+		return xerrors.Errorf("unknown dealer %d", deal.GetIndex())
+	}
+
+	kdeal := newKyberDeal(deal)
+
+	buf, err := kdeal.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal deal: %v", err)
+	}
+
+	return schnorr.Verify(suite, pubkeys[deal.GetIndex()], buf, kdeal.Signature)
+}
+
+// verifyResponseSignature verifies the signature of the verifier over the
+// response.
+func verifyResponseSignature(pubkeys []kyber.Point, resp types.Response) error {
+	verifier := resp.GetResponse().GetIndex()
+
+	if int(resp.GetIndex()) >= len(pubkeys) || int(verifier) >= len(pubkeys) {
+		return xerrors.Errorf("unknown participant %d or %d", resp.GetIndex(), verifier)
+	}
+
+	kresp := newKyberResponse(resp).Response
+
+	return schnorr.Verify(suite, pubkeys[verifier], kresp.Hash(suite), kresp.Signature)
+}
+
+// verifyJustificationSignature verifies the signature of the dealer over the
+// justification.
+func verifyJustificationSignature(pubkeys []kyber.Point, justif types.Justification) error {
+	if int(justif.GetDealer()) >= len(pubkeys) {
+		return xerrors.Errorf("unknown dealer %d", justif.GetDealer())
+	}
+
+	kjustif := newKyberJustification(justif).Justification
+
+	return schnorr.Verify(suite, pubkeys[justif.GetDealer()], kjustif.Hash(suite),
+		kjustif.Signature)
+}
+
+// sessionID returns the session ID of a deal, as computed by Kyber.
+func sessionID(dealer kyber.Point, verifiers, commitments []kyber.Point, t int) []byte {
+	h := suite.Hash()
+	_, _ = dealer.MarshalTo(h)
+
+	for _, v := range verifiers {
+		_, _ = v.MarshalTo(h)
+	}
+
+	for _, c := range commitments {
+		_, _ = c.MarshalTo(h)
+	}
+
+	_ = binary.Write(h, binary.LittleEndian, uint32(t))
+
+	return h.Sum(nil)
+}
+
+func hasResponseForSession(resps []types.Response, dealer uint32, sid []byte) bool {
+	for _, resp := range resps {
+		if resp.GetIndex() == dealer && bytes.Equal(resp.GetResponse().GetSessionID(), sid) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newKyberDeal(msg types.Deal) *pedersen.Deal {
+	return &pedersen.Deal{
+		Index: msg.GetIndex(),
+		Deal: &vss.EncryptedDeal{
+			DHKey:     msg.GetEncryptedDeal().GetDHKey(),
+			Signature: msg.GetEncryptedDeal().GetSignature(),
+			Nonce:     msg.GetEncryptedDeal().GetNonce(),
+			Cipher:    msg.GetEncryptedDeal().GetCipher(),
+		},
+		Signature: msg.GetSignature(),
+	}
+}
+
+func newKyberResponse(msg types.Response) *pedersen.Response {
+	return &pedersen.Response{
+		Index: msg.GetIndex(),
+		Response: &vss.Response{
+			SessionID: msg.GetResponse().GetSessionID(),
+			Index:     msg.GetResponse().GetIndex(),
+			Status:    msg.GetResponse().GetStatus(),
+			Signature: msg.GetResponse().GetSignature(),
+		},
+	}
+}
+
+func newResponse(resp *pedersen.Response) types.Response {
+	return types.NewResponse(
+		resp.Index,
+		types.NewDealerResponse(
+			resp.Response.Index,
+			resp.Response.Status,
+			resp.Response.SessionID,
+			resp.Response.Signature,
+		),
+	)
+}
+
+func newKyberJustification(msg types.Justification) *pedersen.Justification {
+	return &pedersen.Justification{
+		Index: msg.GetDealer(),
+		Justification: &vss.Justification{
+			SessionID: msg.GetSessionID(),
+			Index:     msg.GetIndex(),
+			Deal: &vss.Deal{
+				SessionID:   msg.GetSessionID(),
+				SecShare:    msg.GetShare(),
+				T:           msg.GetThreshold(),
+				Commitments: msg.GetCommitments(),
+			},
+			Signature: msg.GetSignature(),
+		},
+	}
+}
+
+func newJustification(j *pedersen.Justification) types.Justification {
+	deal := j.Justification.Deal
+
+	return types.NewJustification(
+		j.Index,
+		j.Justification.SessionID,
+		j.Justification.Index,
+		deal.SecShare,
+		deal.T,
+		deal.Commitments,
+		j.Justification.Signature,
+	)
+}
+
+func equalIndices(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sorted := append([]int{}, b...)
+	sort.Ints(sorted)
+
+	for i := range a {
+		if a[i] != sorted[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalPoints(a, b []kyber.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] == nil || !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
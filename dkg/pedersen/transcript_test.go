@@ -0,0 +1,448 @@
+package pedersen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	pedersen "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+)
+
+func TestJournal_Nil(t *testing.T) {
+	var j *journal
+
+	j.addDeal(types.Deal{})
+	j.addResponse(types.Response{})
+	j.addJustification(types.Justification{})
+}
+
+func TestJournal_Add(t *testing.T) {
+	setup := newFakeSetup(t, 3, 2)
+	setup.run(-1, -1, false)
+
+	j := newJournal(2, setup.pubkeys, 0)
+
+	j.addDeal(setup.deals[1][0])
+	j.addDeal(types.NewDeal(1, []byte("bad"), types.EncryptedDeal{}))
+	require.Len(t, j.deals, 1)
+
+	resp := setup.responses[0]
+	j.addResponse(resp)
+	j.addResponse(resp)
+	j.addResponse(types.NewResponse(resp.GetIndex(), types.NewDealerResponse(
+		resp.GetResponse().GetIndex()+1, true, nil, nil)))
+	require.Len(t, j.responses, 1)
+
+	justif := makeTranscriptJustification(t, setup.privkeys[0], 0, 1)
+	j.addJustification(justif)
+	j.addJustification(justif)
+	j.addJustification(types.NewJustification(2, nil, 1, nil, 0, nil, nil))
+	require.Len(t, j.justifications, 1)
+
+	transcript := j.transcript(nil, []int{0}, suite.Point())
+	require.Len(t, transcript.GetDeals(), 3)
+	require.Len(t, transcript.GetDeals()[0], 1)
+}
+
+func TestVerifyTranscript(t *testing.T) {
+	setup := newFakeSetup(t, 4, 3)
+	setup.run(-1, -1, false)
+
+	transcript, err := mergeTranscripts(3, setup.pubkeys, setup.parts)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3}, transcript.GetQualified())
+	require.True(t, setup.pubkey.Equal(transcript.GetPublicKey()))
+	require.Empty(t, Excluded(transcript))
+
+	err = VerifyTranscript(transcript)
+	require.NoError(t, err)
+
+	err = VerifyTranscript(withQualified(transcript, []int{0, 1, 2}))
+	require.EqualError(t, err, "qualified dealers mismatch: [0 1 2] != [0 1 2 3]")
+
+	err = VerifyTranscript(withPublicKey(transcript, suite.Point()))
+	require.Error(t, err)
+	require.Regexp(t, "^public key mismatch: ", err.Error())
+
+	err = VerifyTranscript(withPublicKey(transcript, nil))
+	require.Error(t, err)
+	require.Regexp(t, "^public key mismatch: ", err.Error())
+}
+
+func TestVerifyTranscript_Complaint(t *testing.T) {
+	setup := newFakeSetup(t, 4, 3)
+	setup.run(3, 0, false)
+
+	transcript, err := mergeTranscripts(3, setup.pubkeys, setup.parts)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2}, transcript.GetQualified())
+	require.Equal(t, []int{3}, Excluded(transcript))
+
+	err = VerifyTranscript(transcript)
+	require.NoError(t, err)
+
+	// The public key of the honest nodes is the one of the transcript.
+	require.True(t, setup.pubkey.Equal(transcript.GetPublicKey()))
+}
+
+func TestVerifyTranscript_Justified(t *testing.T) {
+	setup := newFakeSetup(t, 4, 3)
+	setup.run(3, 0, true)
+
+	transcript, err := mergeTranscripts(3, setup.pubkeys, setup.parts)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3}, transcript.GetQualified())
+	require.Len(t, transcript.GetJustifications(), 1)
+
+	err = VerifyTranscript(transcript)
+	require.NoError(t, err)
+
+	// A justification that does not match the commitments leaves the
+	// complaint.
+	justif := transcript.GetJustifications()[0]
+	forged := makeTranscriptJustification(t, setup.privkeys[3], 3, 0)
+
+	err = VerifyTranscript(withJustifications(transcript, forged))
+	require.EqualError(t, err, "qualified dealers mismatch: [0 1 2 3] != [0 1 2]")
+
+	// The signature of the justification must be the one of the dealer.
+	forged = types.NewJustification(justif.GetDealer(), justif.GetSessionID(),
+		justif.GetIndex(), justif.GetShare(), justif.GetThreshold(),
+		justif.GetCommitments(), []byte("bad"))
+
+	err = VerifyTranscript(withJustifications(transcript, forged))
+	require.Error(t, err)
+	require.Regexp(t, "^invalid transcript: invalid justification of 3 for 0: ", err.Error())
+}
+
+func TestVerifyTranscript_Invalid(t *testing.T) {
+	setup := newFakeSetup(t, 3, 2)
+	setup.run(-1, -1, false)
+
+	transcript, err := mergeTranscripts(2, setup.pubkeys, setup.parts)
+	require.NoError(t, err)
+
+	err = VerifyTranscript(types.NewTranscript(0, setup.pubkeys, nil, nil, nil, nil, nil, nil))
+	require.EqualError(t, err, "invalid transcript: invalid threshold 0 for 3 participants")
+
+	err = VerifyTranscript(types.NewTranscript(2, setup.pubkeys, nil, nil, nil, nil, nil, nil))
+	require.EqualError(t, err,
+		"invalid transcript: expected commitments for 3 dealers, got 0")
+
+	err = VerifyTranscript(types.NewTranscript(2, setup.pubkeys, make([][]types.Deal, 4),
+		nil, nil, transcript.GetCommitments(), nil, nil))
+	require.EqualError(t, err,
+		"invalid transcript: expected deals for 3 participants, got 4")
+
+	deal := transcript.GetDeals()[1][0]
+	badDeal := types.NewDeal(deal.GetIndex(), []byte("bad"), deal.GetEncryptedDeal())
+
+	err = VerifyTranscript(types.NewTranscript(2, setup.pubkeys, [][]types.Deal{nil, {badDeal}},
+		nil, nil, transcript.GetCommitments(), nil, nil))
+	require.Error(t, err)
+	require.Regexp(t, "^invalid transcript: invalid deal of 0 for 1: ", err.Error())
+
+	resp := transcript.GetResponses()[0]
+	badResp := types.NewResponse(resp.GetIndex(), types.NewDealerResponse(
+		resp.GetResponse().GetIndex(), false, resp.GetResponse().GetSessionID(),
+		resp.GetResponse().GetSignature()))
+
+	err = VerifyTranscript(types.NewTranscript(2, setup.pubkeys, nil,
+		[]types.Response{badResp}, nil, transcript.GetCommitments(), nil, nil))
+	require.Error(t, err)
+	require.Regexp(t, "^invalid transcript: invalid response of ", err.Error())
+
+	err = VerifyTranscript(types.NewTranscript(2, setup.pubkeys, nil, nil, nil,
+		transcript.GetCommitments(), nil, nil))
+	require.EqualError(t, err,
+		"invalid transcript: only 0 qualified dealers for a threshold of 2")
+}
+
+func TestMergeTranscripts_Failure(t *testing.T) {
+	setup := newFakeSetup(t, 3, 2)
+
+	_, err := mergeTranscripts(2, setup.pubkeys, nil)
+	require.EqualError(t, err,
+		"failed to evaluate: only 0 qualified dealers for a threshold of 2")
+}
+
+func TestMergeTranscripts_Commitments(t *testing.T) {
+	setup := newFakeSetup(t, 3, 2)
+	setup.run(-1, -1, false)
+
+	// A part announces commitments that no verifier signed, which must be
+	// replaced by the ones of the other parts.
+	commitments := append([][]kyber.Point{}, setup.parts[1].GetCommitments()...)
+	commitments[0] = []kyber.Point{suite.Point(), suite.Point()}
+
+	first := setup.parts[1]
+	parts := []types.Transcript{
+		{},
+		types.NewTranscript(2, setup.pubkeys, first.GetDeals(), first.GetResponses(),
+			first.GetJustifications(), commitments, nil, nil),
+		setup.parts[2],
+	}
+
+	transcript, err := mergeTranscripts(2, setup.pubkeys, parts)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2}, transcript.GetQualified())
+	require.True(t, setup.pubkey.Equal(transcript.GetPublicKey()))
+}
+
+func TestMergeTranscripts_HiddenJustification(t *testing.T) {
+	setup := newFakeSetup(t, 4, 3)
+	setup.run(3, 0, true)
+
+	// Only the dealer knows its justification, which must not count.
+	parts := make([]types.Transcript, len(setup.parts))
+	for i, part := range setup.parts {
+		parts[i] = part
+
+		if i != 3 {
+			parts[i] = withJustifications(part)
+		}
+	}
+
+	transcript, err := mergeTranscripts(3, setup.pubkeys, parts)
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, Excluded(transcript))
+	require.Empty(t, transcript.GetJustifications())
+}
+
+func TestCheckJustification(t *testing.T) {
+	priv := suite.Scalar().Pick(suite.RandomStream())
+	justif := makeTranscriptJustification(t, priv, 0, 1)
+
+	sid := justif.GetSessionID()
+	commitments := justif.GetCommitments()
+
+	err := checkJustification(justif, sid, commitments, 2)
+	require.NoError(t, err)
+
+	err = checkJustification(justif, []byte("wrong"), commitments, 2)
+	require.EqualError(t, err, "wrong session")
+
+	err = checkJustification(justif, sid, commitments, 3)
+	require.EqualError(t, err, "wrong threshold 2")
+
+	err = checkJustification(justif, sid, commitments[:1], 2)
+	require.EqualError(t, err, "wrong commitments")
+
+	wrongIndex := types.NewJustification(0, sid, 2, justif.GetShare(), 2, commitments, nil)
+
+	err = checkJustification(wrongIndex, sid, commitments, 2)
+	require.EqualError(t, err, "wrong share index")
+
+	wrongShare := types.NewJustification(0, sid, 1, &share.PriShare{I: 1, V: suite.Scalar()},
+		2, commitments, nil)
+
+	err = checkJustification(wrongShare, sid, commitments, 2)
+	require.EqualError(t, err, "share does not match the commitments")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// fakeSetup runs a DKG setup with the Kyber generators in memory, and records
+// the parts of the transcript of each node.
+type fakeSetup struct {
+	t         *testing.T
+	threshold int
+	privkeys  []kyber.Scalar
+	pubkeys   []kyber.Point
+
+	// deals are the deals received by each node.
+	deals     [][]types.Deal
+	responses []types.Response
+	parts     []types.Transcript
+	// pubkey is the public key computed by the first node.
+	pubkey kyber.Point
+}
+
+func newFakeSetup(t *testing.T, n, threshold int) *fakeSetup {
+	setup := &fakeSetup{
+		t:         t,
+		threshold: threshold,
+		privkeys:  make([]kyber.Scalar, n),
+		pubkeys:   make([]kyber.Point, n),
+		deals:     make([][]types.Deal, n),
+	}
+
+	for i := range setup.privkeys {
+		setup.privkeys[i] = suite.Scalar().Pick(suite.RandomStream())
+		setup.pubkeys[i] = suite.Point().Mul(setup.privkeys[i], nil)
+	}
+
+	return setup
+}
+
+// run executes the setup. If faulty is a valid index, its deal for the victim
+// is lost and the victim complains about it. The faulty dealer justifies its
+// deal only when justify is true.
+func (f *fakeSetup) run(faulty, victim int, justify bool) {
+	n := len(f.pubkeys)
+
+	dkgs := make([]*pedersen.DistKeyGenerator, n)
+	journals := make([]*journal, n)
+
+	for i := range dkgs {
+		d, err := pedersen.NewDistKeyGenerator(suite, f.privkeys[i], f.pubkeys, f.threshold)
+		require.NoError(f.t, err)
+
+		dkgs[i] = d
+		journals[i] = newJournal(f.threshold, f.pubkeys, i)
+	}
+
+	for dealer, d := range dkgs {
+		deals, err := d.Deals()
+		require.NoError(f.t, err)
+
+		for to, deal := range deals {
+			msg := types.NewDeal(deal.Index, deal.Signature, types.NewEncryptedDeal(
+				deal.Deal.DHKey, deal.Deal.Signature, deal.Deal.Nonce, deal.Deal.Cipher))
+
+			f.deals[to] = append(f.deals[to], msg)
+			journals[to].addDeal(msg)
+
+			if dealer == faulty && to == victim {
+				continue
+			}
+
+			resp, err := dkgs[to].ProcessDeal(deal)
+			require.NoError(f.t, err)
+
+			f.responses = append(f.responses, newResponse(resp))
+		}
+	}
+
+	resps := append([]types.Response{}, f.responses...)
+
+	if faulty >= 0 {
+		resps = append(resps, f.complain(faulty, victim))
+	}
+
+	justifs := []types.Justification{}
+
+	for _, resp := range resps {
+		for k := range dkgs {
+			journals[k].addResponse(resp)
+
+			if uint32(k) == resp.GetResponse().GetIndex() {
+				continue
+			}
+
+			justif, err := dkgs[k].ProcessResponse(newKyberResponse(resp))
+			if err == vss.ErrNoDealBeforeResponse {
+				continue
+			}
+
+			require.NoError(f.t, err)
+
+			if justif != nil && justify {
+				justifs = append(justifs, newJustification(justif))
+			}
+		}
+	}
+
+	for _, justif := range justifs {
+		for k := range dkgs {
+			journals[k].addJustification(justif)
+
+			if k != victim && k != int(justif.GetDealer()) {
+				err := dkgs[k].ProcessJustification(newKyberJustification(justif))
+				require.NoError(f.t, err)
+			}
+		}
+	}
+
+	for k, d := range dkgs {
+		d.SetTimeout()
+
+		distKey, err := d.DistKeyShare()
+		require.NoError(f.t, err)
+
+		if k == 0 {
+			f.pubkey = distKey.Public()
+		}
+
+		inst := &instance{dkg: d, startRes: &state{pubkeys: f.pubkeys}}
+
+		f.parts = append(f.parts, journals[k].transcript(inst.commitments(), d.QUAL(),
+			distKey.Public()))
+	}
+}
+
+// complain returns the complaint of the victim for the deal of the dealer,
+// with the session ID signed by the other verifiers.
+func (f *fakeSetup) complain(dealer, victim int) types.Response {
+	var sid []byte
+
+	for _, resp := range f.responses {
+		if int(resp.GetIndex()) == dealer {
+			sid = resp.GetResponse().GetSessionID()
+		}
+	}
+
+	complaint := &vss.Response{
+		SessionID: sid,
+		Index:     uint32(victim),
+		Status:    vss.StatusComplaint,
+	}
+
+	sig, err := schnorr.Sign(suite, f.privkeys[victim], complaint.Hash(suite))
+	require.NoError(f.t, err)
+
+	complaint.Signature = sig
+
+	return newResponse(&pedersen.Response{Index: uint32(dealer), Response: complaint})
+}
+
+// makeTranscriptJustification returns a justification signed by the dealer,
+// for the share of the verifier of a random polynomial of degree 1.
+func makeTranscriptJustification(t *testing.T, priv kyber.Scalar,
+	dealer, verifier uint32) types.Justification {
+
+	priPoly := share.NewPriPoly(suite, 2, nil, suite.RandomStream())
+	_, commitments := priPoly.Commit(nil).Info()
+
+	justif := newJustification(&pedersen.Justification{
+		Index: dealer,
+		Justification: &vss.Justification{
+			SessionID: []byte("session"),
+			Index:     verifier,
+			Deal: &vss.Deal{
+				SessionID:   []byte("session"),
+				SecShare:    priPoly.Eval(int(verifier)),
+				T:           2,
+				Commitments: commitments,
+			},
+		},
+	})
+
+	kjustif := newKyberJustification(justif).Justification
+
+	sig, err := schnorr.Sign(suite, priv, kjustif.Hash(suite))
+	require.NoError(t, err)
+
+	return types.NewJustification(dealer, justif.GetSessionID(), verifier,
+		justif.GetShare(), 2, commitments, sig)
+}
+
+func withQualified(t types.Transcript, qual []int) types.Transcript {
+	return types.NewTranscript(t.GetThreshold(), t.GetPublicKeys(), t.GetDeals(),
+		t.GetResponses(), t.GetJustifications(), t.GetCommitments(), qual, t.GetPublicKey())
+}
+
+func withPublicKey(t types.Transcript, pubkey kyber.Point) types.Transcript {
+	return types.NewTranscript(t.GetThreshold(), t.GetPublicKeys(), t.GetDeals(),
+		t.GetResponses(), t.GetJustifications(), t.GetCommitments(), t.GetQualified(), pubkey)
+}
+
+func withJustifications(t types.Transcript, justifs ...types.Justification) types.Transcript {
+	return types.NewTranscript(t.GetThreshold(), t.GetPublicKeys(), t.GetDeals(),
+		t.GetResponses(), justifs, t.GetCommitments(), t.GetQualified(), t.GetPublicKey())
+}
@@ -48,6 +48,26 @@ type ShareAndProof struct {
 	Hi kyber.Point  // h_i
 }
 
+// BeaconShare is the share of a participant of the value of the random beacon
+// for an input. It proves with (E, F) that the value V and the public share X
+// use the same private share, i.e. log_G(X) = log_H(V) where H is the point of
+// the input.
+type BeaconShare struct {
+	I int64
+	V kyber.Point
+	X kyber.Point
+	E kyber.Scalar
+	F kyber.Scalar
+}
+
+// SignCommitment is the commitment of a participant to the pair of nonces it
+// uses for a signature, as D = d*G and E = e*G.
+type SignCommitment struct {
+	I int64
+	D kyber.Point
+	E kyber.Point
+}
+
 var msgFormats = registry.NewSimpleRegistry()
 
 // RegisterMessageFormat register the engine for the provided format.
@@ -381,12 +401,94 @@ func (r Response) Serialize(ctx serde.Context) ([]byte, error) {
 	return data, nil
 }
 
+// Justification matches the attributes defined in kyber pedersen.Justification.
+// It is sent by a dealer to every participant when a verifier complained about
+// its deal, and reveals the deal of that verifier so that everyone can check
+// it against the commitments.
+//
+// - implements serde.Message
+type Justification struct {
+	// Index of the dealer that justifies its deal.
+	dealer    uint32
+	sessionID []byte
+	// Index of the verifier that complained.
+	index       uint32
+	share       *share.PriShare
+	threshold   uint32
+	commitments []kyber.Point
+	signature   []byte
+}
+
+// NewJustification creates a new justification that reveals the share of the
+// verifier, and the commitments of the deal.
+func NewJustification(dealer uint32, sessionID []byte, index uint32, share *share.PriShare,
+	threshold uint32, commitments []kyber.Point, sig []byte) Justification {
+
+	return Justification{
+		dealer:      dealer,
+		sessionID:   sessionID,
+		index:       index,
+		share:       share,
+		threshold:   threshold,
+		commitments: commitments,
+		signature:   sig,
+	}
+}
+
+// GetDealer returns the index of the dealer.
+func (j Justification) GetDealer() uint32 {
+	return j.dealer
+}
+
+// GetSessionID returns the session ID in bytes.
+func (j Justification) GetSessionID() []byte {
+	return emptyIfNil(j.sessionID)
+}
+
+// GetIndex returns the index of the verifier that complained.
+func (j Justification) GetIndex() uint32 {
+	return j.index
+}
+
+// GetShare returns the share of the verifier.
+func (j Justification) GetShare() *share.PriShare {
+	return j.share
+}
+
+// GetThreshold returns the threshold of the deal.
+func (j Justification) GetThreshold() uint32 {
+	return j.threshold
+}
+
+// GetCommitments returns the commitments of the deal.
+func (j Justification) GetCommitments() []kyber.Point {
+	return j.commitments
+}
+
+// GetSignature returns the signature of the dealer in bytes.
+func (j Justification) GetSignature() []byte {
+	return emptyIfNil(j.signature)
+}
+
+// Serialize implements serde.Message.
+func (j Justification) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, j)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode justification: %v", err)
+	}
+
+	return data, nil
+}
+
 // StartDone should be sent by all the nodes to the initiator of the DKG when
 // the DKG setup is done.
 //
 // - implements serde.Message
 type StartDone struct {
-	pubkey kyber.Point
+	pubkey     kyber.Point
+	transcript *Transcript
 }
 
 // NewStartDone creates a new start done message.
@@ -396,11 +498,26 @@ func NewStartDone(pubkey kyber.Point) StartDone {
 	}
 }
 
+// NewStartDoneWithTranscript creates a new start done message that also
+// contains the part of the transcript known by the node.
+func NewStartDoneWithTranscript(pubkey kyber.Point, transcript Transcript) StartDone {
+	return StartDone{
+		pubkey:     pubkey,
+		transcript: &transcript,
+	}
+}
+
 // GetPublicKey returns the public key of the LTS.
 func (s StartDone) GetPublicKey() kyber.Point {
 	return s.pubkey
 }
 
+// GetTranscript returns the transcript of the node, or nil if it is not
+// provided.
+func (s StartDone) GetTranscript() *Transcript {
+	return s.transcript
+}
+
 // Serialize implements serde.Message.
 func (s StartDone) Serialize(ctx serde.Context) ([]byte, error) {
 	format := msgFormats.Get(ctx.GetFormat())
@@ -413,6 +530,95 @@ func (s StartDone) Serialize(ctx serde.Context) ([]byte, error) {
 	return data, nil
 }
 
+// Transcript is the public record of a DKG setup. It contains what a third
+// party needs to verify offline which dealers are qualified, and the resulting
+// public key. The participants are identified by their index in the list of
+// public keys.
+//
+// - implements serde.Message
+type Transcript struct {
+	threshold  int
+	publicKeys []kyber.Point
+	// deals contains for each participant the deals it received.
+	deals          [][]Deal
+	responses      []Response
+	justifications []Justification
+	// commitments contains for each dealer the commitments of its deal, or nil
+	// when they are unknown.
+	commitments [][]kyber.Point
+	qual        []int
+	pubkey      kyber.Point
+}
+
+// NewTranscript creates a new transcript.
+func NewTranscript(threshold int, publicKeys []kyber.Point, deals [][]Deal,
+	responses []Response, justifications []Justification, commitments [][]kyber.Point,
+	qual []int, pubkey kyber.Point) Transcript {
+
+	return Transcript{
+		threshold:      threshold,
+		publicKeys:     publicKeys,
+		deals:          deals,
+		responses:      responses,
+		justifications: justifications,
+		commitments:    commitments,
+		qual:           qual,
+		pubkey:         pubkey,
+	}
+}
+
+// GetThreshold returns the threshold of the DKG.
+func (t Transcript) GetThreshold() int {
+	return t.threshold
+}
+
+// GetPublicKeys returns the public keys of the participants.
+func (t Transcript) GetPublicKeys() []kyber.Point {
+	return t.publicKeys
+}
+
+// GetDeals returns the deals received by each participant.
+func (t Transcript) GetDeals() [][]Deal {
+	return t.deals
+}
+
+// GetResponses returns the responses of the verifiers.
+func (t Transcript) GetResponses() []Response {
+	return t.responses
+}
+
+// GetJustifications returns the justifications of the dealers.
+func (t Transcript) GetJustifications() []Justification {
+	return t.justifications
+}
+
+// GetCommitments returns the commitments of the deal of each dealer.
+func (t Transcript) GetCommitments() [][]kyber.Point {
+	return t.commitments
+}
+
+// GetQualified returns the indices of the qualified dealers.
+func (t Transcript) GetQualified() []int {
+	return t.qual
+}
+
+// GetPublicKey returns the public key of the LTS.
+func (t Transcript) GetPublicKey() kyber.Point {
+	return t.pubkey
+}
+
+// Serialize implements serde.Message.
+func (t Transcript) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, t)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode transcript: %v", err)
+	}
+
+	return data, nil
+}
+
 // DecryptRequest is a message sent to request a decryption.
 //
 // - implements serde.Message
@@ -455,18 +661,26 @@ func (req DecryptRequest) Serialize(ctx serde.Context) ([]byte, error) {
 // using the original randomness K from the write request
 // and the public key PubK from the reader
 type ReencryptRequest struct {
-	K    kyber.Point
-	PubK kyber.Point
+	K     kyber.Point
+	PubK  kyber.Point
+	Proof []byte
 }
 
-// NewReencryptRequest creates a new reencryption request.
-func NewReencryptRequest(k, pubk kyber.Point) *ReencryptRequest {
+// NewReencryptRequest creates a new reencryption request. The proof is
+// optional and is given to the authorizer of the nodes, if any.
+func NewReencryptRequest(k, pubk kyber.Point, proof []byte) *ReencryptRequest {
 	return &ReencryptRequest{
-		K:    k,
-		PubK: pubk,
+		K:     k,
+		PubK:  pubk,
+		Proof: proof,
 	}
 }
 
+// GetProof returns the proof that the reencryption is authorized.
+func (req ReencryptRequest) GetProof() []byte {
+	return req.Proof
+}
+
 // Serialize implements serde.Message.
 func (req ReencryptRequest) Serialize(ctx serde.Context) ([]byte, error) {
 	format := msgFormats.Get(ctx.GetFormat())
@@ -551,19 +765,25 @@ func (req VerifiableDecryptRequest) Serialize(ctx serde.Context) ([]byte, error)
 	return data, nil
 }
 
-// DecryptReply is the response of a decryption request.
+// DecryptReply is the response of a decryption request. It contains the
+// partial decryption of a participant, and a proof (E, F) that it used its
+// share of the distributed key.
 //
 // - implements serde.Message
 type DecryptReply struct {
 	V kyber.Point
 	I int64
+	E kyber.Scalar
+	F kyber.Scalar
 }
 
 // NewDecryptReply returns a new decryption reply.
-func NewDecryptReply(i int64, v kyber.Point) DecryptReply {
+func NewDecryptReply(i int64, v kyber.Point, e, f kyber.Scalar) DecryptReply {
 	return DecryptReply{
 		I: i,
 		V: v,
+		E: e,
+		F: f,
 	}
 }
 
@@ -577,6 +797,16 @@ func (resp DecryptReply) GetI() int64 {
 	return resp.I
 }
 
+// GetE returns the challenge of the proof.
+func (resp DecryptReply) GetE() kyber.Scalar {
+	return resp.E
+}
+
+// GetF returns the response of the proof.
+func (resp DecryptReply) GetF() kyber.Scalar {
+	return resp.F
+}
+
 // Serialize implements serde.Message.
 func (resp DecryptReply) Serialize(ctx serde.Context) ([]byte, error) {
 	format := msgFormats.Get(ctx.GetFormat())
@@ -621,6 +851,270 @@ func (resp VerifiableDecryptReply) Serialize(ctx serde.Context) ([]byte, error)
 	return data, nil
 }
 
+// BeaconRequest is the message sent to request the share of the value of the
+// random beacon for an input.
+//
+// - implements serde.Message
+type BeaconRequest struct {
+	input []byte
+}
+
+// NewBeaconRequest creates a new beacon request.
+func NewBeaconRequest(input []byte) BeaconRequest {
+	return BeaconRequest{
+		input: input,
+	}
+}
+
+// GetInput returns the input of the beacon.
+func (req BeaconRequest) GetInput() []byte {
+	return req.input
+}
+
+// Serialize implements serde.Message.
+func (req BeaconRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, req)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode beacon request: %v", err)
+	}
+
+	return data, nil
+}
+
+// BeaconReply is the response of a beacon request.
+//
+// - implements serde.Message
+type BeaconReply struct {
+	share BeaconShare
+}
+
+// NewBeaconReply creates a new beacon reply.
+func NewBeaconReply(share BeaconShare) BeaconReply {
+	return BeaconReply{
+		share: share,
+	}
+}
+
+// GetShare returns the share of the participant.
+func (resp BeaconReply) GetShare() BeaconShare {
+	return resp.share
+}
+
+// Serialize implements serde.Message.
+func (resp BeaconReply) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, resp)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode beacon reply: %v", err)
+	}
+
+	return data, nil
+}
+
+// PublicKeyRequest is the message sent to a node to request the public key it
+// uses in the DKG, which is needed to add the node to the committee.
+//
+// - implements serde.Message
+type PublicKeyRequest struct{}
+
+// NewPublicKeyRequest creates a new public key request.
+func NewPublicKeyRequest() PublicKeyRequest {
+	return PublicKeyRequest{}
+}
+
+// Serialize implements serde.Message.
+func (req PublicKeyRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, req)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode public key request: %v", err)
+	}
+
+	return data, nil
+}
+
+// PublicKeyReply is the response of a public key request.
+//
+// - implements serde.Message
+type PublicKeyReply struct {
+	publicKey kyber.Point
+}
+
+// NewPublicKeyReply creates a new public key reply.
+func NewPublicKeyReply(pubkey kyber.Point) PublicKeyReply {
+	return PublicKeyReply{
+		publicKey: pubkey,
+	}
+}
+
+// GetPublicKey returns the public key of the node.
+func (resp PublicKeyReply) GetPublicKey() kyber.Point {
+	return resp.publicKey
+}
+
+// Serialize implements serde.Message.
+func (resp PublicKeyReply) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, resp)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode public key reply: %v", err)
+	}
+
+	return data, nil
+}
+
+// SignCommitRequest is the message sent to request the commitment of a
+// participant to its nonces for a signing session.
+//
+// - implements serde.Message
+type SignCommitRequest struct {
+	session []byte
+}
+
+// NewSignCommitRequest creates a new sign commit request.
+func NewSignCommitRequest(session []byte) SignCommitRequest {
+	return SignCommitRequest{
+		session: session,
+	}
+}
+
+// GetSession returns the identifier of the signing session.
+func (req SignCommitRequest) GetSession() []byte {
+	return req.session
+}
+
+// Serialize implements serde.Message.
+func (req SignCommitRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, req)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode sign commit request: %v", err)
+	}
+
+	return data, nil
+}
+
+// SignCommitReply is the response of a sign commit request.
+//
+// - implements serde.Message
+type SignCommitReply struct {
+	commitment SignCommitment
+}
+
+// NewSignCommitReply creates a new sign commit reply.
+func NewSignCommitReply(commitment SignCommitment) SignCommitReply {
+	return SignCommitReply{
+		commitment: commitment,
+	}
+}
+
+// GetCommitment returns the commitment of the participant.
+func (resp SignCommitReply) GetCommitment() SignCommitment {
+	return resp.commitment
+}
+
+// Serialize implements serde.Message.
+func (resp SignCommitReply) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, resp)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode sign commit reply: %v", err)
+	}
+
+	return data, nil
+}
+
+// SignRequest is the message sent to request the share of the signature of a
+// message to the participants of a signing session.
+//
+// - implements serde.Message
+type SignRequest struct {
+	session     []byte
+	message     []byte
+	commitments []SignCommitment
+}
+
+// NewSignRequest creates a new sign request.
+func NewSignRequest(session, message []byte, commitments []SignCommitment) SignRequest {
+	return SignRequest{
+		session:     session,
+		message:     message,
+		commitments: commitments,
+	}
+}
+
+// GetSession returns the identifier of the signing session.
+func (req SignRequest) GetSession() []byte {
+	return req.session
+}
+
+// GetMessage returns the message to sign.
+func (req SignRequest) GetMessage() []byte {
+	return req.message
+}
+
+// GetCommitments returns the commitments of the participants of the session.
+func (req SignRequest) GetCommitments() []SignCommitment {
+	return req.commitments
+}
+
+// Serialize implements serde.Message.
+func (req SignRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, req)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode sign request: %v", err)
+	}
+
+	return data, nil
+}
+
+// SignReply is the response of a sign request.
+//
+// - implements serde.Message
+type SignReply struct {
+	index int64
+	share kyber.Scalar
+}
+
+// NewSignReply creates a new sign reply.
+func NewSignReply(index int64, share kyber.Scalar) SignReply {
+	return SignReply{
+		index: index,
+		share: share,
+	}
+}
+
+// GetIndex returns the index of the participant.
+func (resp SignReply) GetIndex() int64 {
+	return resp.index
+}
+
+// GetShare returns the share of the signature of the participant.
+func (resp SignReply) GetShare() kyber.Scalar {
+	return resp.share
+}
+
+// Serialize implements serde.Message.
+func (resp SignReply) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, resp)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode sign reply: %v", err)
+	}
+
+	return data, nil
+}
+
 // AddrKey is the key for the address factory.
 type AddrKey struct{}
 
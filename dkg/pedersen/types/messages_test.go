@@ -222,6 +222,36 @@ func TestResponse_Serialize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't encode response"))
 }
 
+func TestJustification_Getters(t *testing.T) {
+	priShare := &share.PriShare{I: 2}
+
+	j := NewJustification(1, []byte{1}, 2, priShare, 3, []kyber.Point{fakePoint{}}, []byte{4})
+
+	require.Equal(t, uint32(1), j.GetDealer())
+	require.Equal(t, []byte{1}, j.GetSessionID())
+	require.Equal(t, uint32(2), j.GetIndex())
+	require.Same(t, priShare, j.GetShare())
+	require.Equal(t, uint32(3), j.GetThreshold())
+	require.Equal(t, []kyber.Point{fakePoint{}}, j.GetCommitments())
+	require.Equal(t, []byte{4}, j.GetSignature())
+
+	j = NewJustification(0, nil, 0, nil, 0, nil, nil)
+
+	require.Equal(t, []byte{}, j.GetSessionID())
+	require.Equal(t, []byte{}, j.GetSignature())
+}
+
+func TestJustification_Serialize(t *testing.T) {
+	j := Justification{}
+
+	data, err := j.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = j.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode justification"))
+}
+
 func TestStartDone_GetPublicKey(t *testing.T) {
 	ack := NewStartDone(fakePoint{})
 
@@ -239,6 +269,49 @@ func TestStartDone_Serialize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't encode ack"))
 }
 
+func TestStartDone_GetTranscript(t *testing.T) {
+	ack := NewStartDone(fakePoint{})
+
+	require.Nil(t, ack.GetTranscript())
+
+	transcript := NewTranscript(1, nil, nil, nil, nil, nil, []int{0}, fakePoint{})
+
+	ack = NewStartDoneWithTranscript(fakePoint{}, transcript)
+
+	require.Equal(t, &transcript, ack.GetTranscript())
+}
+
+func TestTranscript_Getters(t *testing.T) {
+	pubkeys := []kyber.Point{fakePoint{}}
+	deals := [][]Deal{{NewDeal(0, nil, EncryptedDeal{})}}
+	resps := []Response{NewResponse(0, DealerResponse{})}
+	justifs := []Justification{NewJustification(0, nil, 0, nil, 0, nil, nil)}
+	commitments := [][]kyber.Point{{fakePoint{}}}
+
+	transcript := NewTranscript(1, pubkeys, deals, resps, justifs, commitments,
+		[]int{0}, fakePoint{})
+
+	require.Equal(t, 1, transcript.GetThreshold())
+	require.Equal(t, pubkeys, transcript.GetPublicKeys())
+	require.Equal(t, deals, transcript.GetDeals())
+	require.Equal(t, resps, transcript.GetResponses())
+	require.Equal(t, justifs, transcript.GetJustifications())
+	require.Equal(t, commitments, transcript.GetCommitments())
+	require.Equal(t, []int{0}, transcript.GetQualified())
+	require.Equal(t, fakePoint{}, transcript.GetPublicKey())
+}
+
+func TestTranscript_Serialize(t *testing.T) {
+	transcript := Transcript{}
+
+	data, err := transcript.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = transcript.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode transcript"))
+}
+
 func TestDecryptRequest_GetK(t *testing.T) {
 	req := NewDecryptRequest(fakePoint{}, nil)
 
@@ -262,9 +335,15 @@ func TestDecryptRequest_Serialize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't encode decrypt request"))
 }
 
+func TestReencryptRequest_GetProof(t *testing.T) {
+	req := NewReencryptRequest(nil, nil, []byte("proof"))
+
+	require.Equal(t, []byte("proof"), req.GetProof())
+}
+
 func TestReencryptRequest_Serialize(t *testing.T) {
 	var fakePoint kyber.Point
-	req := NewReencryptRequest(fakePoint, fakePoint)
+	req := NewReencryptRequest(fakePoint, fakePoint, nil)
 
 	data, err := req.Serialize(fake.NewContext())
 	require.NoError(t, err)
@@ -292,17 +371,24 @@ func TestVerifiableDecryptRequest_Serialize(t *testing.T) {
 }
 
 func TestDecryptReply_GetV(t *testing.T) {
-	resp := NewDecryptReply(0, fakePoint{})
+	resp := NewDecryptReply(0, fakePoint{}, nil, nil)
 
 	require.Equal(t, fakePoint{}, resp.GetV())
 }
 
 func TestDecryptReply_GetI(t *testing.T) {
-	resp := NewDecryptReply(1, nil)
+	resp := NewDecryptReply(1, nil, nil, nil)
 
 	require.Equal(t, int64(1), resp.GetI())
 }
 
+func TestDecryptReply_GetProof(t *testing.T) {
+	resp := NewDecryptReply(1, nil, fakeScalar{}, fakeScalar{})
+
+	require.Equal(t, fakeScalar{}, resp.GetE())
+	require.Equal(t, fakeScalar{}, resp.GetF())
+}
+
 func TestDecryptReply_Serialize(t *testing.T) {
 	resp := DecryptReply{}
 
@@ -345,6 +431,140 @@ func TestVerifiableDecryptReply_Serialize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't encode verifiable decrypt reply"))
 }
 
+func TestBeaconRequest_GetInput(t *testing.T) {
+	req := NewBeaconRequest([]byte{1, 2, 3})
+
+	require.Equal(t, []byte{1, 2, 3}, req.GetInput())
+}
+
+func TestBeaconRequest_Serialize(t *testing.T) {
+	req := BeaconRequest{}
+
+	data, err := req.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = req.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode beacon request"))
+}
+
+func TestBeaconReply_GetShare(t *testing.T) {
+	resp := NewBeaconReply(BeaconShare{I: 2, V: fakePoint{}})
+
+	require.Equal(t, BeaconShare{I: 2, V: fakePoint{}}, resp.GetShare())
+}
+
+func TestBeaconReply_Serialize(t *testing.T) {
+	resp := BeaconReply{}
+
+	data, err := resp.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = resp.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode beacon reply"))
+}
+
+func TestPublicKeyRequest_Serialize(t *testing.T) {
+	req := NewPublicKeyRequest()
+
+	data, err := req.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = req.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode public key request"))
+}
+
+func TestPublicKeyReply_GetPublicKey(t *testing.T) {
+	resp := NewPublicKeyReply(fakePoint{})
+
+	require.Equal(t, fakePoint{}, resp.GetPublicKey())
+}
+
+func TestPublicKeyReply_Serialize(t *testing.T) {
+	resp := PublicKeyReply{}
+
+	data, err := resp.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = resp.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode public key reply"))
+}
+
+func TestSignCommitRequest_GetSession(t *testing.T) {
+	req := NewSignCommitRequest([]byte{1, 2, 3})
+
+	require.Equal(t, []byte{1, 2, 3}, req.GetSession())
+}
+
+func TestSignCommitRequest_Serialize(t *testing.T) {
+	req := SignCommitRequest{}
+
+	data, err := req.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = req.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode sign commit request"))
+}
+
+func TestSignCommitReply_GetCommitment(t *testing.T) {
+	resp := NewSignCommitReply(SignCommitment{I: 2, D: fakePoint{}})
+
+	require.Equal(t, SignCommitment{I: 2, D: fakePoint{}}, resp.GetCommitment())
+}
+
+func TestSignCommitReply_Serialize(t *testing.T) {
+	resp := SignCommitReply{}
+
+	data, err := resp.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = resp.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode sign commit reply"))
+}
+
+func TestSignRequest_Getters(t *testing.T) {
+	commitments := []SignCommitment{{I: 1}, {I: 2}}
+	req := NewSignRequest([]byte{1}, []byte{2}, commitments)
+
+	require.Equal(t, []byte{1}, req.GetSession())
+	require.Equal(t, []byte{2}, req.GetMessage())
+	require.Equal(t, commitments, req.GetCommitments())
+}
+
+func TestSignRequest_Serialize(t *testing.T) {
+	req := SignRequest{}
+
+	data, err := req.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = req.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode sign request"))
+}
+
+func TestSignReply_Getters(t *testing.T) {
+	resp := NewSignReply(2, fakeScalar{})
+
+	require.Equal(t, int64(2), resp.GetIndex())
+	require.Equal(t, fakeScalar{}, resp.GetShare())
+}
+
+func TestSignReply_Serialize(t *testing.T) {
+	resp := SignReply{}
+
+	data, err := resp.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = resp.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode sign reply"))
+}
+
 func TestMessageFactory(t *testing.T) {
 	factory := NewMessageFactory(fake.AddressFactory{})
 
@@ -368,3 +588,7 @@ func TestMessageFactory(t *testing.T) {
 type fakePoint struct {
 	kyber.Point
 }
+
+type fakeScalar struct {
+	kyber.Scalar
+}
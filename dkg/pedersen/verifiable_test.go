@@ -18,6 +18,7 @@ import (
 	"go.dedis.ch/dela/mino/router/tree"
 
 	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
 	"go.dedis.ch/kyber/v3/xof/keccak"
 )
 
@@ -237,18 +238,23 @@ func Test_VerifiableDecrypt_BadRPC(t *testing.T) {
 		rpc:      fake.NewBadRPC(),
 	}
 
-	_, err := a.VerifiableDecrypt(nil)
+	_, err := a.VerifiableDecrypt(make([]types.Ciphertext, 1))
 	require.EqualError(t, err, fake.Err("failed to create stream"))
 }
 
 func Test_VerifiableDecrypt_BadSender(t *testing.T) {
 	a := Actor{
-		startRes: &state{dkgState: certified},
-		rpc:      fake.NewStreamRPC(nil, fake.NewBadSender()),
+		startRes: &state{
+			dkgState:     certified,
+			participants: []mino.Address{fake.NewAddress(0)},
+			threshold:    1,
+		},
+		rpc: fake.NewStreamRPC(fake.NewBadReceiver(), fake.NewBadSender()),
 	}
 
-	_, err := a.VerifiableDecrypt(nil)
-	require.EqualError(t, err, fake.Err("failed to send verifiable decrypt request"))
+	// The actor still waits for the replies of the nodes that are reachable.
+	_, err := a.VerifiableDecrypt(make([]types.Ciphertext, 1))
+	require.EqualError(t, err, fake.Err("stream stopped unexpectedly"))
 }
 
 func Test_VerifiableDecrypt_BadReceiver(t *testing.T) {
@@ -256,28 +262,55 @@ func Test_VerifiableDecrypt_BadReceiver(t *testing.T) {
 		startRes: &state{
 			dkgState:     certified,
 			participants: []mino.Address{fake.NewAddress(0)},
+			threshold:    1,
 		},
 		rpc: fake.NewStreamRPC(fake.NewBadReceiver(), fake.Sender{}),
 	}
 
-	_, err := a.VerifiableDecrypt(nil)
+	_, err := a.VerifiableDecrypt(make([]types.Ciphertext, 1))
 	require.EqualError(t, err, fake.Err("stream stopped unexpectedly"))
 }
 
 func Test_VerifiableDecrypt_BadReply(t *testing.T) {
-	reply := fake.NewRecvMsg(fake.NewAddress(0), fake.Message{})
+	priPoly := share.NewPriPoly(suite, 1, nil, suite.RandomStream())
 
 	a := Actor{
 		startRes: &state{
 			dkgState:     certified,
 			participants: []mino.Address{fake.NewAddress(0)},
+			distrKey:     priPoly.Commit(nil).Commit(),
+			poly:         priPoly.Commit(nil),
+			threshold:    1,
 		},
-		rpc: fake.NewStreamRPC(&fake.Receiver{
-			Msgs: []fake.ReceiverMessage{reply},
-		}, fake.Sender{}),
 	}
 
-	_, err := a.VerifiableDecrypt(nil)
-	require.EqualError(t, err,
-		"got unexpected reply, expected types.VerifiableDecryptReply but got: fake.Message")
+	GBar := suite.Point().Pick(suite.RandomStream())
+
+	ct, _, err := a.VerifiableEncrypt([]byte("abc"), GBar)
+	require.NoError(t, err)
+
+	sp, err := verifiableDecryption(ct, priPoly.Shares(1)[0].V, 0, suite.RandomStream())
+	require.NoError(t, err)
+
+	invalid := *sp
+	invalid.V = suite.Point()
+
+	a.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), fake.Message{}),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewVerifiableDecryptReply(
+			[]types.ShareAndProof{invalid})),
+	), fake.Sender{})
+
+	_, err = a.VerifiableDecrypt([]types.Ciphertext{ct})
+	require.EqualError(t, err, "stream stopped unexpectedly: EOF")
+
+	a.rpc = fake.NewStreamRPC(fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), fake.Message{}),
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewVerifiableDecryptReply(
+			[]types.ShareAndProof{*sp})),
+	), fake.Sender{})
+
+	decrypted, err := a.VerifiableDecrypt([]types.Ciphertext{ct})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("abc")}, decrypted)
 }
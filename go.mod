@@ -3,6 +3,7 @@ module go.dedis.ch/dela
 go 1.21
 
 require (
+	github.com/cloudflare/circl v1.4.0
 	github.com/golang/protobuf v1.5.4
 	github.com/libp2p/go-libp2p v0.36.1
 	github.com/libp2p/go-yamux/v4 v4.0.1
@@ -20,10 +21,12 @@ require (
 	go.etcd.io/bbolt v1.3.9
 	golang.org/x/crypto v0.25.0
 	golang.org/x/net v0.27.0
+	golang.org/x/term v0.22.0
 	golang.org/x/tools v0.23.0
 	golang.org/x/xerrors v0.0.0-20231012003039-104605ab7028
 	google.golang.org/grpc v1.63.0
 	gopkg.in/yaml.v2 v2.4.0
+	lukechampine.com/blake3 v1.3.0
 )
 
 require (
@@ -131,5 +134,4 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240227224415-6ceb2ff114de // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
-	lukechampine.com/blake3 v1.3.0 // indirect
 )
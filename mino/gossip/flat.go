@@ -11,6 +11,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
@@ -28,16 +29,35 @@ type Flat struct {
 	sync.RWMutex
 	mino         mino.Mino
 	rumorFactory serde.Factory
+	clock        clock.Clock
 	ch           chan Rumor
 }
 
+// FlatOption is the type of option to set some fields of a flat gossip.
+type FlatOption func(*Flat)
+
+// WithClock is an option to set the clock used for the timeout of the rumors.
+// It allows a simulation to drive the gossip with a virtual time.
+func WithClock(c clock.Clock) FlatOption {
+	return func(flat *Flat) {
+		flat.clock = c
+	}
+}
+
 // NewFlat creates a new instance of a flat gossip protocol.
-func NewFlat(m mino.Mino, f serde.Factory) *Flat {
-	return &Flat{
+func NewFlat(m mino.Mino, f serde.Factory, opts ...FlatOption) *Flat {
+	flat := &Flat{
 		mino:         m,
 		rumorFactory: f,
+		clock:        clock.NewSystem(),
 		ch:           make(chan Rumor, 100),
 	}
+
+	for _, opt := range opts {
+		opt(flat)
+	}
+
+	return flat
 }
 
 // Listen implements gossip.Gossiper. It creates the RPC and starts to listen
@@ -48,6 +68,7 @@ func (flat *Flat) Listen() (Actor, error) {
 	actor := &flatActor{
 		logger: dela.Logger.With().Str("addr", flat.mino.GetAddress().String()).Logger(),
 		rpc:    mino.MustCreateRPC(flat.mino, "flatgossip", h, flat.rumorFactory),
+		clock:  flat.clock,
 	}
 
 	return actor, nil
@@ -68,6 +89,7 @@ type flatActor struct {
 
 	logger  zerolog.Logger
 	rpc     mino.RPC
+	clock   clock.Clock
 	players mino.Players
 }
 
@@ -91,7 +113,7 @@ func (a *flatActor) Add(rumor Rumor) error {
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), rumorTimeout)
+	ctx, cancel := a.clock.WithTimeout(context.Background(), rumorTimeout)
 	defer cancel()
 
 	resps, err := a.rpc.Call(ctx, rumor, players)
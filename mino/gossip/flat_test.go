@@ -2,10 +2,13 @@ package gossip
 
 import (
 	"bytes"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/clock"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/testing/fake"
@@ -19,6 +22,17 @@ func TestFlat_Listen(t *testing.T) {
 	require.NotNil(t, actor)
 }
 
+func TestFlat_WithClock(t *testing.T) {
+	clk := clock.NewVirtual(time.Unix(0, 0))
+
+	gossiper := NewFlat(fake.Mino{}, nil, WithClock(clk))
+	require.Equal(t, clk, gossiper.clock)
+
+	actor, err := gossiper.Listen()
+	require.NoError(t, err)
+	require.Equal(t, clk, actor.(*flatActor).clock)
+}
+
 func TestFlat_Rumors(t *testing.T) {
 	gossiper := NewFlat(nil, nil)
 	require.NotNil(t, gossiper.Rumors())
@@ -38,6 +52,7 @@ func TestActor_Add(t *testing.T) {
 	rpc := fake.NewRPC()
 	actor := &flatActor{
 		rpc:     rpc,
+		clock:   clock.NewSystem(),
 		players: fake.NewAuthority(3, fake.NewSigner),
 	}
 
@@ -65,6 +80,33 @@ func TestActor_Add(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestActor_Add_Timeout(t *testing.T) {
+	clk := clock.NewVirtual(time.Unix(0, 0))
+	rpc := fake.NewRPC()
+	actor := &flatActor{
+		rpc:     rpc,
+		clock:   clk,
+		players: fake.NewAuthority(3, fake.NewSigner),
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- actor.Add(fakeRumor{})
+	}()
+
+	require.Eventually(t, func() bool { return rpc.Calls.Len() == 1 },
+		time.Second, time.Millisecond)
+
+	ctx := rpc.Calls.Get(0, 0).(context.Context)
+	require.NoError(t, ctx.Err())
+
+	clk.Advance(rumorTimeout)
+	<-ctx.Done()
+
+	rpc.Done()
+	require.NoError(t, <-done)
+}
+
 func TestActor_Close(t *testing.T) {
 	actor := &flatActor{
 		players: fake.NewAuthority(3, fake.NewSigner),
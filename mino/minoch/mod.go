@@ -42,8 +42,19 @@ type Minoch struct {
 	filters    []Filter
 }
 
+// Option is the type of the options to create a Minoch instance.
+type Option func(*Minoch)
+
+// WithContext is an option to set the serde context of the messages, which is
+// the JSON one by default. All the instances must use the same format.
+func WithContext(ctx serde.Context) Option {
+	return func(m *Minoch) {
+		m.context = ctx
+	}
+}
+
 // NewMinoch creates a new instance of a local Mino instance.
-func NewMinoch(manager *Manager, identifier string) (*Minoch, error) {
+func NewMinoch(manager *Manager, identifier string, opts ...Option) (*Minoch, error) {
 	inst := &Minoch{
 		manager:    manager,
 		identifier: identifier,
@@ -52,6 +63,10 @@ func NewMinoch(manager *Manager, identifier string) (*Minoch, error) {
 		context:    json.NewContext(),
 	}
 
+	for _, opt := range opts {
+		opt(inst)
+	}
+
 	err := manager.insert(inst)
 	if err != nil {
 		return nil, xerrors.Errorf("manager refused: %v", err.Error())
@@ -64,8 +79,8 @@ func NewMinoch(manager *Manager, identifier string) (*Minoch, error) {
 
 // MustCreate creates a new minoch instance and panic if the identifier is
 // refused by the manager.
-func MustCreate(manager *Manager, identifier string) *Minoch {
-	m, err := NewMinoch(manager, identifier)
+func MustCreate(manager *Manager, identifier string, opts ...Option) *Minoch {
+	m, err := NewMinoch(manager, identifier, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -102,6 +117,7 @@ func (m *Minoch) WithSegment(path string) mino.Mino {
 		identifier: m.identifier,
 		path:       fmt.Sprintf("%s/%s", m.path, path),
 		rpcs:       m.rpcs,
+		context:    m.context,
 	}
 
 	return newMinoch
@@ -19,6 +19,16 @@ func TestMinoch_New(t *testing.T) {
 	require.NotNil(t, m2)
 }
 
+func TestMinoch_WithContext(t *testing.T) {
+	manager := NewManager()
+
+	m := MustCreate(manager, "A", WithContext(fake.NewContextWithFormat(serde.FormatCBOR)))
+	require.Equal(t, serde.FormatCBOR, m.context.GetFormat())
+
+	m2 := m.WithSegment("abc")
+	require.Equal(t, serde.FormatCBOR, m2.(*Minoch).context.GetFormat())
+}
+
 func TestMinoch_Panic_MustCreate(t *testing.T) {
 	defer func() {
 		r := recover().(error)
@@ -0,0 +1,573 @@
+// Package minofault implements a Mino decorator that injects network faults.
+//
+// Any implementation of Mino can be wrapped so that the messages sent through
+// the RPCs, either by calls or by streams, go through links that can drop,
+// delay, duplicate or reorder them. The instances wrapped by the same network
+// share the configuration of the links, and the network can also schedule
+// partitions that cut the links between groups of participants for a period of
+// time. Its usage is purely to exercise protocols under partial failures in
+// tests.
+//
+// The faults are applied by the sender of a message, which means that every
+// participant of a protocol must be wrapped to simulate faults on all the
+// links.
+//
+// The network also keeps track of its activity: a message is active while it is
+// handed to the decorated network, and every request, reply or message
+// received changes the activity. A simulation can then wait for the network to
+// be quiescent before it moves the time forward.
+//
+// When the network schedules the messages, it holds every message, even
+// without latency, until it is released by Deliver. The messages are released
+// one by one in the order of their delivery time, so that a simulation decides
+// the order in which the participants see them. The fate of a message is then
+// drawn from the seed, the link and the content of the message, instead of the
+// order in which the participants send their messages, which is up to the
+// runtime.
+package minofault
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+// defaultReorderDelay is the amount of time a message is held back when it is
+// reordered and the link has no latency.
+const defaultReorderDelay = 10 * time.Millisecond
+
+// Link describes the faults applied to the messages going through a link
+// between two participants. The probabilities are between 0 and 1.
+type Link struct {
+	// Drop is the probability that a message is lost.
+	Drop float64
+	// Duplicate is the probability that a message is delivered twice.
+	Duplicate float64
+	// Reorder is the probability that a message is held back so that the
+	// following messages overtake it.
+	Reorder float64
+	// Delay is the latency added to every message.
+	Delay time.Duration
+	// Jitter is the upper bound of a random latency added to every message.
+	Jitter time.Duration
+}
+
+// Partition cuts the links between groups of participants. The participants
+// that belong to no group are not affected.
+type Partition struct {
+	// Groups are the sets of participants that can only communicate inside
+	// their own set.
+	Groups [][]mino.Address
+	// After is the amount of time after which the partition starts.
+	After time.Duration
+	// Duration is the amount of time the partition lasts, or forever if it is
+	// zero.
+	Duration time.Duration
+}
+
+type linkRule struct {
+	from mino.Address
+	to   mino.Address
+	link Link
+}
+
+type schedule struct {
+	partition Partition
+	start     time.Time
+	end       time.Time
+}
+
+// active returns true if the partition is in place at the given time.
+func (s schedule) active(now time.Time) bool {
+	if now.Before(s.start) {
+		return false
+	}
+
+	return s.end.IsZero() || now.Before(s.end)
+}
+
+// separates returns true if the partition cuts the link between the two
+// participants.
+func (s schedule) separates(from, to mino.Address) bool {
+	groupFrom := indexOf(s.partition.Groups, from)
+	groupTo := indexOf(s.partition.Groups, to)
+
+	return groupFrom >= 0 && groupTo >= 0 && groupFrom != groupTo
+}
+
+// fate is the outcome of a message sent through a link. A message is dropped
+// when it has no delivery, and delivered multiple times when duplicated.
+type fate struct {
+	delays  []time.Duration
+	ordered bool
+	key     uint64
+}
+
+// delivery is a message held by the network until it is released. The messages
+// with the same delivery time are ordered by the number of messages released
+// before they were sent, and then by their key.
+type delivery struct {
+	deadline time.Time
+	released uint64
+	key      uint64
+	ch       chan time.Time
+}
+
+// before returns true if the message is released before the other one.
+func (d delivery) before(other delivery) bool {
+	if !d.deadline.Equal(other.deadline) {
+		return d.deadline.Before(other.deadline)
+	}
+
+	if d.released != other.released {
+		return d.released < other.released
+	}
+
+	return d.key < other.key
+}
+
+// Network holds the configuration of the links between the instances it wraps.
+type Network struct {
+	sync.Mutex
+
+	seed       int64
+	rand       *rand.Rand
+	context    serde.Context
+	clock      clock.Clock
+	defaults   Link
+	links      []linkRule
+	partitions []schedule
+	queues     map[string]chan struct{}
+
+	// scheduled is true when the messages are held until they are delivered,
+	// in which case held are the messages in the order of their delivery,
+	// released counts the messages delivered so far, and sent counts the
+	// identical messages of a link.
+	scheduled bool
+	held      []delivery
+	released  uint64
+	sent      map[[sha256.Size]byte]uint64
+
+	// active is the number of messages being handed to the decorated network,
+	// and changes is incremented for every event of the network.
+	active  int
+	changes uint64
+}
+
+// NetworkOption is the type of option to set some fields of a network.
+type NetworkOption func(*Network)
+
+// WithSeed is an option to seed the source of randomness of the network so
+// that the faults can be reproduced.
+func WithSeed(seed int64) NetworkOption {
+	return func(n *Network) {
+		n.seed = seed
+		n.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithClock is an option to set the clock used to delay the messages and to
+// schedule the partitions.
+func WithClock(c clock.Clock) NetworkOption {
+	return func(n *Network) {
+		n.clock = c
+	}
+}
+
+// WithDefaultLink is an option to set the faults of the links that are not
+// explicitly configured.
+func WithDefaultLink(link Link) NetworkOption {
+	return func(n *Network) {
+		n.defaults = link
+	}
+}
+
+// WithScheduling is an option to hold the messages until they are released by
+// Deliver, instead of delivering them once their delay has elapsed on the
+// clock.
+func WithScheduling() NetworkOption {
+	return func(n *Network) {
+		n.scheduled = true
+	}
+}
+
+// NewNetwork creates a new network without any fault.
+func NewNetwork(opts ...NetworkOption) *Network {
+	seed := time.Now().UnixNano()
+
+	n := &Network{
+		seed:    seed,
+		rand:    rand.New(rand.NewSource(seed)),
+		context: json.NewContext(),
+		clock:   clock.NewSystem(),
+		queues:  make(map[string]chan struct{}),
+		sent:    make(map[[sha256.Size]byte]uint64),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Wrap returns a Mino that injects the faults of the network in the messages
+// sent by the given instance.
+func (n *Network) Wrap(m mino.Mino) *Mino {
+	return &Mino{
+		Mino:    m,
+		network: n,
+	}
+}
+
+// SetLink sets the faults of the link from one participant to another. It
+// replaces any previous configuration of the same link.
+func (n *Network) SetLink(from, to mino.Address, link Link) {
+	n.Lock()
+	defer n.Unlock()
+
+	for i, rule := range n.links {
+		if same(rule.from, from) && same(rule.to, to) {
+			n.links[i].link = link
+			return
+		}
+	}
+
+	n.links = append(n.links, linkRule{from: from, to: to, link: link})
+}
+
+// SetLinks sets the faults of the links in both directions between the
+// participants.
+func (n *Network) SetLinks(link Link, addrs ...mino.Address) {
+	for _, from := range addrs {
+		for _, to := range addrs {
+			if !same(from, to) {
+				n.SetLink(from, to, link)
+			}
+		}
+	}
+}
+
+// ClearLinks removes the configuration of every link so that the default
+// faults apply.
+func (n *Network) ClearLinks() {
+	n.Lock()
+	n.links = nil
+	n.Unlock()
+}
+
+// Partition schedules the partition relative to the current time.
+func (n *Network) Partition(p Partition) {
+	n.Lock()
+	defer n.Unlock()
+
+	s := schedule{
+		partition: p,
+		start:     n.clock.Now().Add(p.After),
+	}
+
+	if p.Duration > 0 {
+		s.end = s.start.Add(p.Duration)
+	}
+
+	n.partitions = append(n.partitions, s)
+}
+
+// Heal removes every partition, including the scheduled ones.
+func (n *Network) Heal() {
+	n.Lock()
+	n.partitions = nil
+	n.Unlock()
+}
+
+// IsPartitioned returns true if a partition currently cuts the link from one
+// participant to another.
+func (n *Network) IsPartitioned(from, to mino.Address) bool {
+	n.Lock()
+	defer n.Unlock()
+
+	return n.isPartitioned(from, to, n.clock.Now())
+}
+
+func (n *Network) isPartitioned(from, to mino.Address, now time.Time) bool {
+	for _, s := range n.partitions {
+		if s.active(now) && s.separates(from, to) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *Network) getLink(from, to mino.Address) Link {
+	for _, rule := range n.links {
+		if same(rule.from, from) && same(rule.to, to) {
+			return rule.link
+		}
+	}
+
+	return n.defaults
+}
+
+// decide draws the fate of a message sent from one participant to another.
+func (n *Network) decide(from, to mino.Address, msg serde.Message) fate {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.isPartitioned(from, to, n.clock.Now()) {
+		return fate{}
+	}
+
+	link := n.getLink(from, to)
+
+	r := n.rand
+	f := fate{ordered: true}
+
+	if n.scheduled {
+		r, f.key = n.messageRand(from, to, msg)
+	}
+
+	if r.Float64() < link.Drop {
+		return fate{}
+	}
+
+	copies := 1
+	if r.Float64() < link.Duplicate {
+		copies = 2
+	}
+
+	for i := 0; i < copies; i++ {
+		delay := link.Delay
+		if link.Jitter > 0 {
+			delay += time.Duration(r.Int63n(int64(link.Jitter)))
+		}
+
+		f.delays = append(f.delays, delay)
+	}
+
+	if r.Float64() < link.Reorder {
+		f.ordered = false
+
+		hold := link.Delay + link.Jitter
+		if hold == 0 {
+			hold = defaultReorderDelay
+		}
+
+		f.delays[0] += hold
+	}
+
+	return f
+}
+
+// messageRand returns a source of randomness for the message, and the key of
+// the message, that depend on the seed, the link, the content of the message
+// and the number of identical messages sent before on the link, but not on the
+// other messages of the network.
+func (n *Network) messageRand(from, to mino.Address, msg serde.Message) (*rand.Rand, uint64) {
+	h := sha256.New()
+
+	binary.Write(h, binary.LittleEndian, n.seed)
+	h.Write([]byte(from.String() + "\x00" + to.String() + "\x00"))
+
+	if msg != nil {
+		data, err := msg.Serialize(n.context)
+		if err == nil {
+			h.Write(data)
+		}
+	}
+
+	var id [sha256.Size]byte
+	copy(id[:], h.Sum(nil))
+
+	binary.Write(h, binary.LittleEndian, n.sent[id])
+	n.sent[id]++
+
+	key := binary.LittleEndian.Uint64(h.Sum(nil))
+
+	return rand.New(rand.NewSource(int64(key))), key
+}
+
+// NextDelivery returns the delivery time of the next message held by the
+// network, or false if none is held.
+func (n *Network) NextDelivery() (time.Time, bool) {
+	n.Lock()
+	defer n.Unlock()
+
+	if len(n.held) == 0 {
+		return time.Time{}, false
+	}
+
+	return n.held[0].deadline, true
+}
+
+// Deliver releases the next message held by the network if its delivery time
+// has come. It returns false if there is no such message.
+func (n *Network) Deliver() bool {
+	n.Lock()
+	defer n.Unlock()
+
+	now := n.clock.Now()
+
+	if len(n.held) == 0 || n.held[0].deadline.After(now) {
+		return false
+	}
+
+	next := n.held[0]
+	n.held = n.held[1:]
+	n.released++
+	n.changes++
+
+	next.ch <- now
+
+	return true
+}
+
+// after returns a channel that receives the time once a message sent now can
+// be delivered, which is when the delay has elapsed on the clock or, when the
+// network schedules the messages, when it is released.
+func (n *Network) after(delay time.Duration, key uint64) <-chan time.Time {
+	if !n.scheduled {
+		return n.clock.After(delay)
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	d := delivery{
+		deadline: n.clock.Now().Add(delay),
+		released: n.released,
+		key:      key,
+		ch:       make(chan time.Time, 1),
+	}
+
+	// The messages with the same delivery time are released in the order they
+	// were caused, and then in the order of their key, which does not depend on
+	// the order the participants send them.
+	index := sort.Search(len(n.held), func(i int) bool {
+		return d.before(n.held[i])
+	})
+
+	n.held = append(n.held, delivery{})
+	copy(n.held[index+1:], n.held[index:])
+	n.held[index] = d
+
+	n.changes++
+
+	return d.ch
+}
+
+// arrivals returns a channel for each delivery of the message, in the order of
+// the delays of its fate.
+func (n *Network) arrivals(f fate) []<-chan time.Time {
+	chs := make([]<-chan time.Time, len(f.delays))
+	for i, delay := range f.delays {
+		chs[i] = n.after(delay, f.key+uint64(i))
+	}
+
+	return chs
+}
+
+// Activity returns the number of messages that are being handed to the
+// decorated network, and the number of events seen by the network. The network
+// is quiescent when nothing is active and the number of events stays the same
+// over a period of time.
+func (n *Network) Activity() (int, uint64) {
+	n.Lock()
+	defer n.Unlock()
+
+	return n.active, n.changes
+}
+
+func (n *Network) acquire() {
+	n.Lock()
+	n.active++
+	n.changes++
+	n.Unlock()
+}
+
+func (n *Network) release() {
+	n.Lock()
+	n.active--
+	n.changes++
+	n.Unlock()
+}
+
+func (n *Network) touch() {
+	n.Lock()
+	n.changes++
+	n.Unlock()
+}
+
+// enqueue returns a channel to wait on before delivering a message on the
+// link, and the channel to close once it is delivered, so that the messages of
+// a link keep their order.
+func (n *Network) enqueue(from, to mino.Address) (<-chan struct{}, chan struct{}) {
+	key := from.String() + "->" + to.String()
+	done := make(chan struct{})
+
+	n.Lock()
+	prev := n.queues[key]
+	n.queues[key] = done
+	n.Unlock()
+
+	return prev, done
+}
+
+// Mino is a decorator of a Mino that injects the faults of the network in the
+// messages sent by the RPCs.
+//
+// - implements mino.Mino
+type Mino struct {
+	mino.Mino
+
+	network *Network
+}
+
+// Unwrap returns the decorated Mino.
+func (m *Mino) Unwrap() mino.Mino {
+	return m.Mino
+}
+
+// WithSegment implements mino.Mino. It returns a new instance with the segment
+// that injects the faults of the same network.
+func (m *Mino) WithSegment(segment string) mino.Mino {
+	return m.network.Wrap(m.Mino.WithSegment(segment))
+}
+
+// CreateRPC implements mino.Mino. It creates an RPC of the decorated Mino and
+// wraps it to inject the faults.
+func (m *Mino) CreateRPC(name string, h mino.Handler, f serde.Factory) (mino.RPC, error) {
+	me := m.Mino.GetAddress()
+
+	rpc, err := m.Mino.CreateRPC(name, handler{Handler: h, me: me, network: m.network}, f)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create rpc: %v", err)
+	}
+
+	return RPC{rpc: rpc, me: me, network: m.network}, nil
+}
+
+func same(a, b mino.Address) bool {
+	return a.Equal(b) || b.Equal(a)
+}
+
+func indexOf(groups [][]mino.Address, addr mino.Address) int {
+	for i, group := range groups {
+		for _, member := range group {
+			if same(member, addr) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,204 @@
+package minofault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestNetwork_Decide(t *testing.T) {
+	a, b := fake.NewAddress(0), fake.NewAddress(1)
+
+	net := NewNetwork(WithSeed(1))
+
+	f := net.decide(a, b, nil)
+	require.Equal(t, []time.Duration{0}, f.delays)
+	require.True(t, f.ordered)
+
+	net.SetLink(a, b, Link{Drop: 1})
+	require.Empty(t, net.decide(a, b, nil).delays)
+	require.Len(t, net.decide(b, a, nil).delays, 1)
+
+	net.SetLink(a, b, Link{Duplicate: 1, Delay: time.Second})
+	f = net.decide(a, b, nil)
+	require.Equal(t, []time.Duration{time.Second, time.Second}, f.delays)
+
+	net.SetLink(a, b, Link{Reorder: 1})
+	f = net.decide(a, b, nil)
+	require.Equal(t, []time.Duration{defaultReorderDelay}, f.delays)
+	require.False(t, f.ordered)
+
+	net.SetLink(a, b, Link{Jitter: time.Millisecond})
+	f = net.decide(a, b, nil)
+	require.Len(t, f.delays, 1)
+	require.Less(t, f.delays[0], time.Millisecond)
+
+	net.ClearLinks()
+	require.Equal(t, []time.Duration{0}, net.decide(a, b, nil).delays)
+
+	net = NewNetwork(WithDefaultLink(Link{Drop: 1}))
+	require.Empty(t, net.decide(a, b, nil).delays)
+}
+
+func TestNetwork_SetLinks(t *testing.T) {
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)}
+
+	net := NewNetwork()
+	net.SetLinks(Link{Drop: 1}, addrs...)
+	require.Len(t, net.links, 6)
+
+	for _, from := range addrs {
+		for _, to := range addrs {
+			if !from.Equal(to) {
+				require.Equal(t, Link{Drop: 1}, net.getLink(from, to))
+			}
+		}
+	}
+
+	net.SetLinks(Link{}, addrs...)
+	require.Len(t, net.links, 6)
+	require.Equal(t, Link{}, net.getLink(addrs[0], addrs[1]))
+}
+
+func TestNetwork_Partition(t *testing.T) {
+	a, b, c := fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)
+
+	net := NewNetwork()
+	net.Partition(Partition{
+		Groups: [][]mino.Address{{a}, {b}},
+	})
+
+	require.True(t, net.IsPartitioned(a, b))
+	require.True(t, net.IsPartitioned(b, a))
+	require.False(t, net.IsPartitioned(a, c))
+	require.Empty(t, net.decide(a, b, nil).delays)
+
+	net.Heal()
+	require.False(t, net.IsPartitioned(a, b))
+
+	net.Partition(Partition{
+		Groups: [][]mino.Address{{a}, {b}},
+		After:  time.Hour,
+	})
+	require.False(t, net.IsPartitioned(a, b))
+
+	net.Heal()
+	net.Partition(Partition{
+		Groups:   [][]mino.Address{{a}, {b}},
+		Duration: 50 * time.Millisecond,
+	})
+	require.True(t, net.IsPartitioned(a, b))
+
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, net.IsPartitioned(a, b))
+}
+
+func TestNetwork_WithClock(t *testing.T) {
+	a, b := fake.NewAddress(0), fake.NewAddress(1)
+
+	clk := clock.NewVirtual(time.Unix(0, 0))
+
+	net := NewNetwork(WithClock(clk))
+	net.Partition(Partition{
+		Groups:   [][]mino.Address{{a}, {b}},
+		After:    time.Minute,
+		Duration: time.Minute,
+	})
+	require.False(t, net.IsPartitioned(a, b))
+
+	clk.Advance(time.Minute)
+	require.True(t, net.IsPartitioned(a, b))
+
+	clk.Advance(time.Minute)
+	require.False(t, net.IsPartitioned(a, b))
+}
+
+func TestNetwork_Scheduling(t *testing.T) {
+	clk := clock.NewVirtual(time.Unix(0, 0))
+
+	net := NewNetwork(WithClock(clk), WithScheduling())
+
+	_, found := net.NextDelivery()
+	require.False(t, found)
+	require.False(t, net.Deliver())
+
+	late := net.after(time.Second, 0)
+	second := net.after(0, 2)
+	first := net.after(0, 1)
+
+	next, found := net.NextDelivery()
+	require.True(t, found)
+	require.Equal(t, clk.Now(), next)
+
+	// The messages with the same delivery time are ordered by their key.
+	require.True(t, net.Deliver())
+	require.Len(t, first, 1)
+	require.Len(t, second, 0)
+
+	// A message sent after a release comes after the messages sent before,
+	// whatever its key.
+	third := net.after(0, 0)
+
+	require.True(t, net.Deliver())
+	require.Len(t, second, 1)
+	require.Len(t, third, 0)
+
+	require.True(t, net.Deliver())
+	require.Len(t, third, 1)
+
+	// The last message is held until its delivery time.
+	require.False(t, net.Deliver())
+
+	clk.Advance(time.Second)
+	require.True(t, net.Deliver())
+	require.Equal(t, time.Unix(1, 0), <-late)
+}
+
+func TestNetwork_Scheduling_Fate(t *testing.T) {
+	a, b, c := fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)
+
+	link := Link{Reorder: 0.5, Jitter: time.Second}
+
+	first := NewNetwork(WithSeed(1), WithScheduling(), WithDefaultLink(link))
+	second := NewNetwork(WithSeed(1), WithScheduling(), WithDefaultLink(link))
+
+	// The fates do not depend on the order the messages are sent.
+	toB := first.decide(a, b, indexMessage(1))
+	toC := first.decide(a, c, indexMessage(2))
+
+	require.Equal(t, toC, second.decide(a, c, indexMessage(2)))
+	require.Equal(t, toB, second.decide(a, b, indexMessage(1)))
+	require.NotEqual(t, toB.key, toC.key)
+
+	// The identical messages of a link have their own fate.
+	again := first.decide(a, b, indexMessage(1))
+	require.NotEqual(t, toB.key, again.key)
+	require.Equal(t, again, second.decide(a, b, indexMessage(1)))
+}
+
+func TestMino_Unwrap(t *testing.T) {
+	m := minoch.MustCreate(minoch.NewManager(), "A")
+
+	wrapped := NewNetwork().Wrap(m)
+	require.Equal(t, m, wrapped.Unwrap())
+	require.Equal(t, m.GetAddress(), wrapped.GetAddress())
+
+	segment, ok := wrapped.WithSegment("test").(*Mino)
+	require.True(t, ok)
+	require.Equal(t, wrapped.network, segment.network)
+}
+
+func TestMino_CreateRPC(t *testing.T) {
+	wrapped := NewNetwork().Wrap(minoch.MustCreate(minoch.NewManager(), "A"))
+
+	_, err := wrapped.CreateRPC("test", mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.NoError(t, err)
+
+	_, err = wrapped.CreateRPC("test", mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.EqualError(t, err, "failed to create rpc: rpc '/test' already exists")
+}
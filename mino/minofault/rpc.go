@@ -0,0 +1,307 @@
+// This file contains the implementation of the RPC that injects the faults of
+// the network in the calls and the streams.
+
+package minofault
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// RPC is a decorator of an RPC that injects the faults of the network.
+//
+// - implements mino.RPC
+type RPC struct {
+	rpc     mino.RPC
+	me      mino.Address
+	network *Network
+}
+
+// Call implements mino.RPC. It calls each participant separately so that the
+// faults of each link apply to the request and to the reply. A lost message
+// produces an error response, as a connection failure would.
+func (rpc RPC) Call(ctx context.Context, req serde.Message,
+	players mino.Players) (<-chan mino.Response, error) {
+
+	out := make(chan mino.Response, players.Len())
+
+	wg := sync.WaitGroup{}
+	wg.Add(players.Len())
+
+	iter := players.AddressIterator()
+	for iter.HasNext() {
+		addr := iter.GetNext()
+
+		// The fate of the request is drawn before the routine starts so that
+		// the requests are sent in the order of the players.
+		arrivals := rpc.network.arrivals(rpc.network.decide(rpc.me, addr, req))
+
+		go func() {
+			defer wg.Done()
+
+			rpc.call(ctx, req, addr, arrivals, out)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+func (rpc RPC) call(ctx context.Context, req serde.Message, to mino.Address,
+	arrivals []<-chan time.Time, out chan mino.Response) {
+
+	if len(arrivals) == 0 {
+		rpc.lost(to, out)
+		return
+	}
+
+	// A duplicated request is processed by the participant but only the reply
+	// of the first one is returned.
+	for _, arrival := range arrivals[1:] {
+		go func(arrival <-chan time.Time) {
+			if !rpc.network.wait(ctx, arrival) {
+				return
+			}
+
+			resps, err := rpc.rpc.Call(ctx, req, mino.NewAddresses(to))
+			if err == nil {
+				for range resps {
+				}
+			}
+		}(arrival)
+	}
+
+	if !rpc.network.wait(ctx, arrivals[0]) {
+		out <- mino.NewResponseWithError(to, xerrors.Errorf("context done: %v", ctx.Err()))
+		return
+	}
+
+	resp, more, err := rpc.send(ctx, req, to)
+	if err != nil {
+		out <- mino.NewResponseWithError(to, xerrors.Errorf("call failed: %v", err))
+		return
+	}
+
+	if !more {
+		return
+	}
+
+	msg, _ := resp.GetMessageOrError()
+
+	reply := rpc.network.arrivals(rpc.network.decide(to, rpc.me, msg))
+	if len(reply) == 0 {
+		rpc.lost(to, out)
+		return
+	}
+
+	if !rpc.network.wait(ctx, reply[0]) {
+		out <- mino.NewResponseWithError(to, xerrors.Errorf("context done: %v", ctx.Err()))
+		return
+	}
+
+	out <- resp
+}
+
+// send calls the participant and returns its response.
+func (rpc RPC) send(ctx context.Context, req serde.Message,
+	to mino.Address) (mino.Response, bool, error) {
+
+	rpc.network.touch()
+
+	resps, err := rpc.rpc.Call(ctx, req, mino.NewAddresses(to))
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, more := <-resps
+
+	rpc.network.touch()
+
+	return resp, more, nil
+}
+
+func (rpc RPC) lost(to mino.Address, out chan mino.Response) {
+	out <- mino.NewResponseWithError(to, xerrors.New("message lost"))
+}
+
+// Stream implements mino.RPC. It opens a stream with the decorated RPC where
+// the messages sent by the orchestrator go through the links of the network.
+func (rpc RPC) Stream(ctx context.Context,
+	players mino.Players) (mino.Sender, mino.Receiver, error) {
+
+	// The stream is active while it is opened by the decorated network, which
+	// might start the handlers of the participants.
+	rpc.network.acquire()
+	out, in, err := rpc.rpc.Stream(ctx, players)
+	rpc.network.release()
+
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to open stream: %v", err)
+	}
+
+	s := sender{
+		Sender:  out,
+		me:      rpc.me,
+		network: rpc.network,
+	}
+
+	r := receiver{
+		Receiver: in,
+		network:  rpc.network,
+	}
+
+	return s, r, nil
+}
+
+// handler is a decorator of a handler so that the messages sent by the
+// participants of a stream go through the links of the network.
+//
+// - implements mino.Handler
+type handler struct {
+	mino.Handler
+
+	me      mino.Address
+	network *Network
+}
+
+// Process implements mino.Handler. It calls the decorated handler and records
+// the request and the reply as activity of the network.
+func (h handler) Process(req mino.Request) (serde.Message, error) {
+	h.network.touch()
+	defer h.network.touch()
+
+	return h.Handler.Process(req)
+}
+
+// Stream implements mino.Handler. It calls the decorated handler with a sender
+// that injects the faults, and a receiver that records the activity.
+func (h handler) Stream(out mino.Sender, in mino.Receiver) error {
+	s := sender{
+		Sender:  out,
+		me:      h.me,
+		network: h.network,
+	}
+
+	r := receiver{
+		Receiver: in,
+		network:  h.network,
+	}
+
+	return h.Handler.Stream(s, r)
+}
+
+// sender is a decorator of a sender that injects the faults of the links in
+// the messages. The messages of a link are delivered in order unless they are
+// reordered by the network.
+//
+// - implements mino.Sender
+type sender struct {
+	mino.Sender
+
+	me      mino.Address
+	network *Network
+}
+
+// Send implements mino.Sender. It sends the message to each address according
+// to the faults of the link. A lost message does not produce any error.
+func (s sender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := make(chan error, 2*len(addrs)+1)
+
+	wg := sync.WaitGroup{}
+
+	for _, addr := range addrs {
+		f := s.network.decide(s.me, addr, msg)
+
+		for i, arrival := range s.network.arrivals(f) {
+			var prev <-chan struct{}
+			var done chan struct{}
+
+			// Only the first copy of a message can be reordered.
+			if f.ordered || i > 0 {
+				prev, done = s.network.enqueue(s.me, addr)
+			}
+
+			wg.Add(1)
+
+			go func(addr mino.Address, arrival <-chan time.Time) {
+				defer wg.Done()
+
+				if prev != nil {
+					<-prev
+				}
+
+				<-arrival
+
+				// The message is active until it is handed to the decorated
+				// sender.
+				s.network.acquire()
+				res := s.Sender.Send(msg, addr)
+
+				if done != nil {
+					close(done)
+				}
+
+				for err := range res {
+					select {
+					case errs <- err:
+					default:
+						// Errors are discarded when the caller does not read
+						// them.
+					}
+				}
+
+				s.network.release()
+			}(addr, arrival)
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		close(errs)
+	}()
+
+	return errs
+}
+
+// receiver is a decorator of a receiver that records the messages received as
+// activity of the network.
+//
+// - implements mino.Receiver
+type receiver struct {
+	mino.Receiver
+
+	network *Network
+}
+
+// Recv implements mino.Receiver. It returns the next message of the decorated
+// receiver.
+func (r receiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	from, msg, err := r.Receiver.Recv(ctx)
+	if err != nil {
+		return from, msg, err
+	}
+
+	r.network.touch()
+
+	return from, msg, nil
+}
+
+// wait waits for the arrival of a message and returns false if the context is
+// done before.
+func (n *Network) wait(ctx context.Context, arrival <-chan time.Time) bool {
+	select {
+	case <-arrival:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,336 @@
+package minofault
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestRPC_Call(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	net.SetLink(addrs[0], addrs[1], Link{Delay: 20 * time.Millisecond})
+	net.SetLink(addrs[2], addrs[0], Link{Drop: 1})
+
+	start := time.Now()
+
+	resps, err := rpcs[0].Call(ctx, indexMessage(1), mino.NewAddresses(addrs...))
+	require.NoError(t, err)
+
+	replies := map[string]error{}
+	for resp := range resps {
+		msg, err := resp.GetMessageOrError()
+		if err == nil {
+			require.Equal(t, indexMessage(1), msg)
+		}
+
+		replies[resp.GetFrom().String()] = err
+	}
+
+	require.Len(t, replies, 3)
+	require.NoError(t, replies[addrs[0].String()])
+	require.NoError(t, replies[addrs[1].String()])
+	require.EqualError(t, replies[addrs[2].String()],
+		"message lost")
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestRPC_Partition_Call(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 2)
+
+	net.Partition(Partition{Groups: [][]mino.Address{{addrs[0]}, {addrs[1]}}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resps, err := rpcs[0].Call(ctx, indexMessage(1), mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	resp := <-resps
+	_, err = resp.GetMessageOrError()
+	require.EqualError(t, err, "message lost")
+
+	net.Heal()
+
+	resps, err = rpcs[0].Call(context.Background(), indexMessage(1), mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	resp = <-resps
+	_, err = resp.GetMessageOrError()
+	require.NoError(t, err)
+}
+
+func TestRPC_Timeout_Call(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 2)
+
+	net.SetLink(addrs[0], addrs[1], Link{Delay: time.Hour})
+	net.SetLink(addrs[1], addrs[0], Link{Delay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resps, err := rpcs[0].Call(ctx, indexMessage(1), mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	for resp := range resps {
+		_, err = resp.GetMessageOrError()
+		require.EqualError(t, err, "context done: context deadline exceeded")
+	}
+}
+
+func TestRPC_Duplicate_Call(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 2)
+
+	net.SetLink(addrs[0], addrs[1], Link{Duplicate: 1})
+
+	resps, err := rpcs[0].Call(context.Background(), indexMessage(1), mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	count := 0
+	for range resps {
+		count++
+	}
+
+	require.Equal(t, 1, count)
+}
+
+func TestRPC_Stream(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 3)
+
+	// Latency keeps the order of the messages of a link.
+	net.SetLinks(Link{Delay: 5 * time.Millisecond, Jitter: 5 * time.Millisecond}, addrs...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender, receiver, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs[1:]...))
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		errs := sender.Send(indexMessage(i), addrs[1:]...)
+		require.NoError(t, <-errs)
+	}
+
+	next := map[string]int{}
+	for i := 0; i < 40; i++ {
+		from, msg, err := receiver.Recv(ctx)
+		require.NoError(t, err)
+		require.Equal(t, indexMessage(next[from.String()]), msg)
+
+		next[from.String()]++
+	}
+}
+
+func TestRPC_Activity(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 2)
+
+	idle := func() bool {
+		active, _ := net.Activity()
+		return active == 0
+	}
+
+	_, changes := net.Activity()
+
+	resps, err := rpcs[0].Call(context.Background(), indexMessage(0), mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	for range resps {
+	}
+
+	active, next := net.Activity()
+	require.Equal(t, 0, active)
+	require.Greater(t, next, changes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender, receiver, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	require.NoError(t, <-sender.Send(indexMessage(0), addrs[1]))
+
+	_, changes = net.Activity()
+
+	_, _, err = receiver.Recv(ctx)
+	require.NoError(t, err)
+
+	_, next = net.Activity()
+	require.Greater(t, next, changes)
+	require.Eventually(t, idle, time.Second, time.Millisecond)
+}
+
+func TestRPC_Scheduling_Call(t *testing.T) {
+	clk := clock.NewVirtual(time.Unix(0, 0))
+	net, rpcs, addrs := makeNetwork(t, 2, WithClock(clk), WithScheduling())
+
+	resps, err := rpcs[0].Call(context.Background(), indexMessage(1), mino.NewAddresses(addrs...))
+	require.NoError(t, err)
+
+	// The requests, and then the replies, are held until they are delivered.
+	require.Len(t, resps, 0)
+	require.True(t, net.Deliver())
+	require.Len(t, resps, 0)
+
+	for i := 0; i < 3; i++ {
+		require.Eventually(t, net.Deliver, time.Second, time.Millisecond)
+	}
+
+	count := 0
+	for range resps {
+		count++
+	}
+
+	require.Equal(t, 2, count)
+}
+
+func TestRPC_Scheduling_Stream(t *testing.T) {
+	clk := clock.NewVirtual(time.Unix(0, 0))
+	net, rpcs, addrs := makeNetwork(t, 2, WithClock(clk), WithScheduling())
+
+	net.SetLink(addrs[0], addrs[1], Link{Delay: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender, receiver, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	errs := sender.Send(indexMessage(0), addrs[1])
+
+	// The message is held until its delay has elapsed.
+	require.False(t, net.Deliver())
+
+	clk.Advance(time.Second)
+	require.True(t, net.Deliver())
+	require.NoError(t, <-errs)
+
+	// The reply has no latency but it is held as well.
+	require.Eventually(t, net.Deliver, time.Second, time.Millisecond)
+
+	_, msg, err := receiver.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, indexMessage(0), msg)
+}
+
+func TestRPC_Faults_Stream(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 3)
+
+	net.SetLink(addrs[0], addrs[1], Link{Duplicate: 1})
+	net.SetLink(addrs[0], addrs[2], Link{Drop: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender, receiver, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs[1:]...))
+	require.NoError(t, err)
+
+	errs := sender.Send(indexMessage(1), addrs[1:]...)
+	require.NoError(t, <-errs)
+
+	for i := 0; i < 2; i++ {
+		from, _, err := receiver.Recv(ctx)
+		require.NoError(t, err)
+		require.True(t, from.Equal(addrs[1]))
+	}
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelTimeout()
+
+	_, _, err = receiver.Recv(timeout)
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRPC_Reorder_Stream(t *testing.T) {
+	net, rpcs, addrs := makeNetwork(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender, receiver, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs[1]))
+	require.NoError(t, err)
+
+	net.SetLink(addrs[0], addrs[1], Link{Reorder: 1})
+	sender.Send(indexMessage(0), addrs[1])
+
+	net.ClearLinks()
+	sender.Send(indexMessage(1), addrs[1])
+
+	_, msg, err := receiver.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, indexMessage(1), msg)
+
+	_, msg, err = receiver.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, indexMessage(0), msg)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeNetwork(t *testing.T, n int,
+	opts ...NetworkOption) (*Network, []mino.RPC, []mino.Address) {
+
+	manager := minoch.NewManager()
+	net := NewNetwork(append([]NetworkOption{WithSeed(0)}, opts...)...)
+
+	rpcs := make([]mino.RPC, n)
+	addrs := make([]mino.Address, n)
+
+	for i := range rpcs {
+		m := net.Wrap(minoch.MustCreate(manager, strconv.Itoa(i)))
+
+		rpcs[i] = mino.MustCreateRPC(m, "test", echoHandler{}, indexMessageFactory{})
+		addrs[i] = m.GetAddress()
+	}
+
+	return net, rpcs, addrs
+}
+
+// indexMessage is a message that carries an index to verify the order of
+// delivery.
+type indexMessage int
+
+func (m indexMessage) Serialize(serde.Context) ([]byte, error) {
+	return []byte(strconv.Itoa(int(m))), nil
+}
+
+type indexMessageFactory struct{}
+
+func (indexMessageFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	index, err := strconv.Atoi(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return indexMessage(index), nil
+}
+
+// echoHandler replies the messages it receives to the sender.
+type echoHandler struct {
+	mino.UnsupportedHandler
+}
+
+func (echoHandler) Process(req mino.Request) (serde.Message, error) {
+	return req.Message, nil
+}
+
+func (echoHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	for {
+		from, msg, err := in.Recv(context.Background())
+		if err != nil {
+			return nil
+		}
+
+		out.Send(msg, from)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/mino"
@@ -44,12 +45,19 @@ func (a certAction) Execute(req node.Context) error {
 			return false
 		}
 
+		if len(certs) == 0 {
+			fmt.Fprintf(req.Out, "Address: %v (%s) Certificate: no certificate found\n",
+				addr, addrB64)
+			return true
+		}
+
 		certStr := make([]string, len(certs))
 		for i, c := range certs {
 			certStr[i] = hex.EncodeToString(c.Raw[:8]) + "..."
 		}
 
-		fmt.Fprintf(req.Out, "Address: %v (%s) Certificate: %s\n", addr, addrB64, strings.Join(certStr, "<-"))
+		fmt.Fprintf(req.Out, "Address: %v (%s) Certificate: %s Expiration: %s\n",
+			addr, addrB64, strings.Join(certStr, "<-"), certs[0].NotAfter.Format(time.RFC3339))
 		return true
 	})
 
@@ -37,10 +37,22 @@ func TestCertAction_Execute(t *testing.T) {
 	err := action.Execute(req)
 	require.NoError(t, err)
 
-	expected := fmt.Sprintf("Address: fake.Address[0] (AAAAAA==) Certificate: %s...\n",
-		hex.EncodeToString(cert.Certificate[0][:8]))
+	expected := fmt.Sprintf("Address: fake.Address[0] (AAAAAA==) Certificate: %s... Expiration: %s\n",
+		hex.EncodeToString(cert.Certificate[0][:8]), cert.Leaf.NotAfter.Format(time.RFC3339))
 	require.Equal(t, expected, out.String())
 
+	// An empty chain, stored by a join without endorsement, is reported.
+	store = certs.NewInMemoryStore()
+	store.Store(fake.NewAddress(0), certs.CertChain{})
+
+	out.Reset()
+	req.Injector.Inject(fakeJoinable{certs: store})
+
+	err = action.Execute(req)
+	require.NoError(t, err)
+	require.Equal(t, "Address: fake.Address[0] (AAAAAA==) Certificate: no certificate found\n",
+		out.String())
+
 	req.Injector.Inject(fakeJoinable{certs: badCertStore{}})
 
 	err = action.Execute(req)
@@ -287,6 +299,7 @@ type fakeContext struct {
 	path     map[string]string
 	num      int
 	boolean  bool
+	bools    map[string]bool
 }
 
 func (ctx fakeContext) Duration(string) time.Duration {
@@ -305,7 +318,12 @@ func (ctx fakeContext) Int(string) int {
 	return ctx.num
 }
 
-func (ctx fakeContext) Bool(string) bool {
+func (ctx fakeContext) Bool(key string) bool {
+	value, found := ctx.bools[key]
+	if found {
+		return value
+	}
+
 	return ctx.boolean
 }
 
@@ -25,13 +25,19 @@ import (
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/store/kv"
+	dcrypto "go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/nodesigner"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/session"
 	"go.dedis.ch/dela/mino/router"
 	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/cbor"
+	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
 )
 
@@ -84,6 +90,34 @@ func (m miniController) SetCommands(builder node.Builder) {
 			Usage:    "provides the chain certificate file path - requires that --certKey is given, too",
 			Required: false,
 		},
+		cli.DurationFlag{
+			Name: "certRenewal",
+			Usage: "renew the generated certificate when it expires within the " +
+				"given amount of time, or never if zero",
+			Value:    30 * 24 * time.Hour,
+			Required: false,
+		},
+		cli.BoolFlag{
+			Name: "endorse",
+			Usage: "endorse the certificate with the roster signing key and " +
+				"require the members of the roster to do the same",
+			Required: false,
+			Value:    false,
+		},
+		cli.StringFlag{
+			Name: "format",
+			Usage: "sets the format of the messages: 'json' or 'cbor', which must be the " +
+				"same for all the nodes",
+			Value:    "json",
+			Required: false,
+		},
+		cli.IntFlag{
+			Name: "format-version",
+			Usage: "pins the version of the stored messages that are sent to the " +
+				"other nodes, for instance 1 until every node of a chain is upgraded, " +
+				"or the latest one if zero",
+			Required: false,
+		},
 		cli.BoolFlag{
 			Name:     "noTLS",
 			Usage:    "dont't serve TLS on the grpc endpoint",
@@ -175,6 +209,24 @@ func (m miniController) OnStart(ctx cli.Flags, inj node.Injector) error {
 		}
 	}
 
+	var serdeCtx serde.Context
+
+	switch ctx.String("format") {
+	case "", "json":
+		serdeCtx = json.NewContext()
+	case "cbor":
+		serdeCtx = cbor.NewContext()
+	default:
+		return xerrors.Errorf("unknown format: %s", ctx.String("format"))
+	}
+
+	if ctx.Int("format-version") < 0 {
+		return xerrors.Errorf("invalid format version: %d", ctx.Int("format-version"))
+	}
+
+	serdeCtx = registry.WithVersion(serdeCtx, uint32(ctx.Int("format-version")))
+	opts = append(opts, minogrpc.WithContext(serdeCtx))
+
 	var public *url.URL
 
 	if ctx.String("public") != "" {
@@ -251,6 +303,17 @@ func (m miniController) getOptionCert(ctx cli.Flags, inj node.Injector) ([]minog
 		}
 
 		opts = append(opts, minogrpc.WithCert(&cert))
+	} else if ctx.Duration("certRenewal") > 0 {
+		opts = append(opts, minogrpc.WithCertificateRenewal(ctx.Duration("certRenewal")))
+	}
+
+	if ctx.Bool("endorse") {
+		signer, err := m.getSigner(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("endorsement signer: %v", err)
+		}
+
+		opts = append(opts, minogrpc.WithEndorsement(signer))
 	}
 
 	return opts, nil
@@ -284,6 +347,25 @@ func (m miniController) getKey(flags cli.Flags) (crypto.PrivateKey, error) {
 	return nil, xerrors.Errorf("key parsing failed: %v", err)
 }
 
+// getSigner loads the signing key of the node as the ordering service does,
+// with the same flags. Only the BN256 key endorses certificates, which is why
+// the ordering refuses to migrate the roster to BLS12-381 when the endorsement
+// is enabled.
+func (m miniController) getSigner(flags cli.Flags) (dcrypto.Signer, error) {
+	signer, err := nodesigner.Load(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// The endorsement of the certificate has no scope.
+	unscoped, ok := signer.(dcrypto.UnscopedSigner)
+	if ok {
+		return unscoped.Unscoped(), nil
+	}
+
+	return signer, nil
+}
+
 // generator can generate a private key compatible with the x509 certificate.
 //
 // - implements loader.Generator
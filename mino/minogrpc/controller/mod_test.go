@@ -2,6 +2,7 @@ package controller
 
 import (
 	"crypto/elliptic"
+	"crypto/x509"
 	"math/big"
 	"net"
 	"os"
@@ -12,8 +13,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/nodesigner"
+	"go.dedis.ch/dela/crypto/remote"
 	"go.dedis.ch/dela/mino/minogrpc"
+	"go.dedis.ch/dela/serde/json"
 	"go.dedis.ch/dela/testing/fake"
 )
 
@@ -57,6 +64,162 @@ func TestMiniController_OnStart(t *testing.T) {
 	require.NoError(t, m.GracefulStop())
 }
 
+func TestMiniController_Endorse_OnStart(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "minogrpc")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	ctrl := NewController().(miniController)
+
+	injector := node.NewInjector()
+	injector.Inject(db)
+
+	ctx := fakeContext{
+		path:  map[string]string{"config": dir},
+		str:   map[string]string{"routing": "flat"},
+		bools: map[string]bool{"endorse": true},
+	}
+
+	err = ctrl.OnStart(ctx, injector)
+	require.NoError(t, err)
+
+	var m *minogrpc.Minogrpc
+	err = injector.Resolve(&m)
+	require.NoError(t, err)
+
+	defer m.GracefulStop()
+
+	signer, err := ctrl.getSigner(ctx)
+	require.NoError(t, err)
+
+	addr, err := m.GetAddress().MarshalText()
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(m.GetCertificateChain())
+	require.NoError(t, err)
+
+	err = minogrpc.VerifyEndorsement(cert, addr, signer.GetPublicKey(),
+		signer.GetSignatureFactory(), json.NewContext())
+	require.NoError(t, err)
+}
+
+func TestMiniController_EncryptedSigner(t *testing.T) {
+	t.Setenv("DELA_TEST_PASSPHRASE", "pass")
+
+	dir := t.TempDir()
+
+	ctrl := NewController().(miniController)
+
+	ctx := fakeContext{
+		path: map[string]string{"config": dir},
+		str:  map[string]string{"passphrase-env": "DELA_TEST_PASSPHRASE"},
+	}
+
+	signer, err := ctrl.getSigner(ctx)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "private.key"))
+	require.NoError(t, err)
+	require.True(t, loader.IsEncrypted(data))
+
+	other, err := ctrl.getSigner(ctx)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(other.GetPublicKey()))
+
+	ctx.str["passphrase-file"] = "/not/exist"
+
+	_, err = ctrl.getSigner(ctx)
+	require.EqualError(t, err, "passphrase: only one of --passphrase-env, "+
+		"--passphrase-file and --passphrase-prompt is allowed")
+}
+
+func TestMiniController_SignerKeyFlag(t *testing.T) {
+	dir := t.TempDir()
+
+	ctrl := NewController().(miniController)
+
+	ctx := fakeContext{
+		path: map[string]string{
+			"config":     dir,
+			"signer-key": filepath.Join(dir, "node.key"),
+		},
+	}
+
+	signer, err := ctrl.getSigner(ctx)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, "node.key"))
+	require.NoFileExists(t, filepath.Join(dir, "private.key"))
+
+	other, err := nodesigner.Load(ctx)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(other.GetPublicKey()))
+}
+
+func TestMiniController_RemoteSigner(t *testing.T) {
+	dir := t.TempDir()
+
+	key := bls.NewSigner()
+
+	guard, err := remote.NewGuard("")
+	require.NoError(t, err)
+
+	srv := remote.NewServer(key, guard, pbft.NewScopeVerifier(), remote.WithUnscoped())
+	require.NoError(t, srv.Listen(filepath.Join(dir, "signer.sock")))
+
+	defer srv.Close()
+
+	ctrl := NewController().(miniController)
+
+	ctx := fakeContext{
+		path: map[string]string{
+			"config":        dir,
+			"signer-socket": filepath.Join(dir, "signer.sock"),
+		},
+	}
+
+	signer, err := ctrl.getSigner(ctx)
+	require.NoError(t, err)
+	require.True(t, key.GetPublicKey().Equal(signer.GetPublicKey()))
+
+	ctx.path["signer-socket"] = filepath.Join(dir, "unknown.sock")
+
+	_, err = ctrl.getSigner(ctx)
+	require.Regexp(t, "^remote signer: failed to get public key: ", err)
+}
+
+func TestMiniController_BadSigner_OnStart(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "minogrpc")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "private.key"), []byte("bad signer"), os.ModePerm)
+	require.NoError(t, err)
+
+	ctrl := NewController().(miniController)
+
+	injector := node.NewInjector()
+	injector.Inject(db)
+
+	ctx := fakeContext{
+		path:  map[string]string{"config": dir},
+		str:   map[string]string{"routing": "flat"},
+		bools: map[string]bool{"endorse": true},
+	}
+
+	err = ctrl.OnStart(ctx, injector)
+	require.Error(t, err)
+	require.Contains(t, err.Error(),
+		"failed to get cert option: endorsement signer: while unmarshaling: ")
+}
+
 func TestMiniController_OnStart_NoTLS(t *testing.T) {
 	ctrl := NewController()
 
@@ -66,6 +229,42 @@ func TestMiniController_OnStart_NoTLS(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMiniController_Format_OnStart(t *testing.T) {
+	ctrl := NewController()
+
+	str := map[string]string{"routing": "flat", "format": "cbor"}
+	inj := node.NewInjector()
+
+	err := ctrl.OnStart(fakeContext{str: str, boolean: true}, inj)
+	require.NoError(t, err)
+
+	err = ctrl.OnStop(inj)
+	require.NoError(t, err)
+
+	str["format"] = "xml"
+
+	err = ctrl.OnStart(fakeContext{str: str, boolean: true}, node.NewInjector())
+	require.EqualError(t, err, "unknown format: xml")
+
+	str["format"] = "json"
+
+	err = ctrl.OnStart(fakeContext{str: str, boolean: true, num: -1}, node.NewInjector())
+	require.EqualError(t, err, "invalid format version: -1")
+}
+
+func TestMiniController_FormatVersion_OnStart(t *testing.T) {
+	ctrl := NewController()
+
+	str := map[string]string{"routing": "flat"}
+	inj := node.NewInjector()
+
+	err := ctrl.OnStart(fakeContext{str: str, boolean: true, num: 1}, inj)
+	require.NoError(t, err)
+
+	err = ctrl.OnStop(inj)
+	require.NoError(t, err)
+}
+
 func TestMiniController_InvalidAddr_OnStart(t *testing.T) {
 	ctrl := NewController()
 
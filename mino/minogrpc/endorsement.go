@@ -0,0 +1,232 @@
+// This file contains the implementation of the endorsement of the overlay
+// certificate by the signing key of the participant in the roster.
+//
+// Documentation Last Review: 18.10.2026
+//
+
+package minogrpc
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// endorsementOID is the identifier of the x509 extension that contains the
+// signature of the certificate public key by the roster signing key.
+var endorsementOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 59914, 1, 1}
+
+// endorsementPrefix is prepended to the endorsed message so that the signature
+// cannot be mistaken for one of a different protocol.
+var endorsementPrefix = []byte("dela-minogrpc-endorsement")
+
+// WithEndorsement is an option to endorse the generated certificate with the
+// signer, which is expected to be the one of the participant in the roster. It
+// also enables the verification of the peer certificates against the roster
+// provided with SetRoster. Every member of the roster must then use the option.
+func WithEndorsement(signer crypto.Signer) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.endorser = signer
+	}
+}
+
+// HasEndorsement returns true when the certificates are endorsed by the signer
+// of the node.
+func (o *overlay) HasEndorsement() bool {
+	return o.endorser != nil
+}
+
+// SetRoster sets the function that returns the current roster, or nil while
+// the chain has none. When the endorsement is enabled, the certificate of a
+// member of the roster is accepted only if it is endorsed by the public key of
+// the member, and the certificates are rejected when the roster cannot be read.
+// The certificates are accepted without endorsement until it is set.
+func (o *overlay) SetRoster(roster func() (crypto.CollectiveAuthority, error)) {
+	o.rosterLock.Lock()
+	o.roster = roster
+	o.rosterLock.Unlock()
+}
+
+// makeEndorsement returns the x509 extension that contains the signature of the
+// public key for the overlay address.
+func (o *overlay) makeEndorsement() (pkix.Extension, error) {
+	spki, err := x509.MarshalPKIXPublicKey(o.public)
+	if err != nil {
+		return pkix.Extension{}, xerrors.Errorf("failed to marshal public key: %v", err)
+	}
+
+	sig, err := o.endorser.Sign(endorsementMessage([]byte(o.myAddrStr), spki))
+	if err != nil {
+		return pkix.Extension{}, xerrors.Errorf("signer: %v", err)
+	}
+
+	sigBuf, err := sig.Serialize(o.context)
+	if err != nil {
+		return pkix.Extension{}, xerrors.Errorf("failed to serialize signature: %v", err)
+	}
+
+	value, err := asn1.Marshal(sigBuf)
+	if err != nil {
+		return pkix.Extension{}, xerrors.Errorf("failed to marshal extension: %v", err)
+	}
+
+	ext := pkix.Extension{
+		Id:    endorsementOID,
+		Value: value,
+	}
+
+	return ext, nil
+}
+
+// checkEndorsement verifies that the certificate is endorsed by the public key
+// of the address if it is a member of the roster. It does nothing if the
+// endorsement is disabled, or if the roster is not known yet so that the node
+// can bootstrap, and it rejects the certificate if the roster cannot be read.
+func (o *overlay) checkEndorsement(addr mino.Address, cert *x509.Certificate) error {
+	if o.endorser == nil {
+		return nil
+	}
+
+	o.rosterLock.RLock()
+	known := o.roster != nil
+	o.rosterLock.RUnlock()
+
+	if !known {
+		return nil
+	}
+
+	pubkey, err := o.getMemberKey(addr)
+	if err != nil {
+		return xerrors.Errorf("roster unavailable: %v", err)
+	}
+
+	if pubkey == nil {
+		// Only the members of the roster have a signing key to compare with.
+		return nil
+	}
+
+	return o.verifyEndorsement(addr, pubkey, cert)
+}
+
+// checkRosterEndorsement verifies that the certificate is endorsed by the public
+// key of the address in the roster. Contrary to checkEndorsement, it fails when
+// the endorsement cannot be verified, as it is a proof of identity.
+func (o *overlay) checkRosterEndorsement(addr mino.Address, cert *x509.Certificate) error {
+	if o.endorser == nil {
+		return xerrors.New("endorsement is disabled")
+	}
+
+	pubkey, err := o.getMemberKey(addr)
+	if err != nil {
+		return err
+	}
+
+	if pubkey == nil {
+		return xerrors.Errorf("%v is not a member", addr)
+	}
+
+	return o.verifyEndorsement(addr, pubkey, cert)
+}
+
+// getMemberKey returns the public key of the address in the current roster, or
+// nil if it is not a member or if the chain has no roster yet.
+func (o *overlay) getMemberKey(addr mino.Address) (crypto.PublicKey, error) {
+	o.rosterLock.RLock()
+	getRoster := o.roster
+	o.rosterLock.RUnlock()
+
+	if getRoster == nil {
+		return nil, xerrors.New("roster is unknown")
+	}
+
+	roster, err := getRoster()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	if roster == nil {
+		return nil, nil
+	}
+
+	pubkey, index := roster.GetPublicKey(addr)
+	if index < 0 {
+		return nil, nil
+	}
+
+	return pubkey, nil
+}
+
+func (o *overlay) verifyEndorsement(addr mino.Address, pubkey crypto.PublicKey,
+	cert *x509.Certificate) error {
+
+	addrBuf, err := addr.MarshalText()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal address: %v", err)
+	}
+
+	err = VerifyEndorsement(cert, addrBuf, pubkey, o.endorser.GetSignatureFactory(), o.context)
+	if err != nil {
+		return xerrors.Errorf("invalid endorsement for %v: %v", addr, err)
+	}
+
+	return nil
+}
+
+// VerifyEndorsement verifies that the certificate of the address is endorsed by
+// the public key. The address is given in its text form.
+func VerifyEndorsement(cert *x509.Certificate, addr []byte, pubkey crypto.PublicKey,
+	fac crypto.SignatureFactory, ctx serde.Context) error {
+
+	var value []byte
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(endorsementOID) {
+			value = ext.Value
+		}
+	}
+
+	if value == nil {
+		return xerrors.New("certificate is not endorsed")
+	}
+
+	var sigBuf []byte
+	_, err := asn1.Unmarshal(value, &sigBuf)
+	if err != nil {
+		return xerrors.Errorf("malformed extension: %v", err)
+	}
+
+	sig, err := fac.SignatureOf(ctx, sigBuf)
+	if err != nil {
+		return xerrors.Errorf("malformed signature: %v", err)
+	}
+
+	err = pubkey.Verify(endorsementMessage(addr, cert.RawSubjectPublicKeyInfo), sig)
+	if err != nil {
+		return xerrors.Errorf("signature mismatch: %v", err)
+	}
+
+	return nil
+}
+
+func endorsementMessage(addr []byte, spki []byte) []byte {
+	buf := new(bytes.Buffer)
+	buf.Write(endorsementPrefix)
+	buf.Write(addr)
+	buf.Write(spki)
+
+	return buf.Bytes()
+}
+
+// isEndorsed returns true if the certificate contains an endorsement that is
+// valid for the signer of the overlay.
+func (o *overlay) isEndorsed(cert *x509.Certificate) bool {
+	err := VerifyEndorsement(cert, []byte(o.myAddrStr), o.endorser.GetPublicKey(),
+		o.endorser.GetSignatureFactory(), o.context)
+
+	return err == nil
+}
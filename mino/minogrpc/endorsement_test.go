@@ -0,0 +1,325 @@
+package minogrpc
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/certs"
+	"go.dedis.ch/dela/mino/minogrpc/ptypes"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestIntegration_Endorsement(t *testing.T) {
+	signers := []crypto.Signer{bls.Generate(), bls.Generate(), bls.Generate()}
+
+	mm := make([]*Minogrpc, len(signers))
+	rpcs := make([]mino.RPC, len(signers))
+	for i, signer := range signers {
+		m, err := NewMinogrpc(ParseAddress("127.0.0.1", 0), nil,
+			tree.NewRouter(addressFac), WithEndorsement(signer))
+		require.NoError(t, err)
+
+		defer m.GracefulStop()
+
+		mm[i] = m
+		rpcs[i] = mino.MustCreateRPC(m, "test", testHandler{}, fake.MessageFactory{})
+
+		for _, k := range mm[:i] {
+			m.GetCertificateStore().Store(k.GetAddress(), k.GetCertificateChain())
+			k.GetCertificateStore().Store(m.GetAddress(), m.GetCertificateChain())
+		}
+	}
+
+	// The last member claims the signing key of the first one in the roster.
+	roster := fakeRoster{
+		keys: map[string]crypto.PublicKey{
+			mm[0].GetAddress().String(): signers[0].GetPublicKey(),
+			mm[1].GetAddress().String(): signers[1].GetPublicKey(),
+			mm[2].GetAddress().String(): signers[0].GetPublicKey(),
+		},
+	}
+
+	for _, m := range mm {
+		m.SetRoster(func() (crypto.CollectiveAuthority, error) {
+			return roster, nil
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resps, err := rpcs[0].Call(ctx, fake.Message{}, mino.NewAddresses(mm[1].GetAddress()))
+	require.NoError(t, err)
+
+	resp := <-resps
+	_, err = resp.GetMessageOrError()
+	require.NoError(t, err)
+
+	resps, err = rpcs[0].Call(ctx, fake.Message{}, mino.NewAddresses(mm[2].GetAddress()))
+	require.NoError(t, err)
+
+	resp = <-resps
+	_, err = resp.GetMessageOrError()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid endorsement for")
+}
+
+func TestOverlay_Endorsement(t *testing.T) {
+	signer := bls.Generate()
+
+	o, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		endorser:     signer,
+	})
+	require.NoError(t, err)
+
+	cert := mustParseCertificate(t, o.GetCertificateChain())
+	require.True(t, o.isEndorsed(cert))
+
+	err = VerifyEndorsement(cert, []byte(o.myAddrStr), signer.GetPublicKey(),
+		signer.GetSignatureFactory(), json.NewContext())
+	require.NoError(t, err)
+
+	err = VerifyEndorsement(cert, []byte("other"), signer.GetPublicKey(),
+		signer.GetSignatureFactory(), json.NewContext())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature mismatch: ")
+
+	err = VerifyEndorsement(cert, []byte(o.myAddrStr), bls.Generate().GetPublicKey(),
+		signer.GetSignatureFactory(), json.NewContext())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature mismatch: ")
+
+	err = VerifyEndorsement(cert, []byte(o.myAddrStr), signer.GetPublicKey(),
+		fake.NewBadSignatureFactory(), json.NewContext())
+	require.EqualError(t, err, fake.Err("malformed signature"))
+
+	plain := mustParseCertificate(t, fake.MakeCertificate(t))
+
+	err = VerifyEndorsement(plain, []byte(o.myAddrStr), signer.GetPublicKey(),
+		signer.GetSignatureFactory(), json.NewContext())
+	require.EqualError(t, err, "certificate is not endorsed")
+
+	// A stored certificate without endorsement is replaced at creation.
+	store := certs.NewInMemoryStore()
+	store.Store(session.NewAddress("127.0.0.1:0"), fake.MakeCertificate(t))
+
+	o, err = newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        store,
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		endorser:     signer,
+	})
+	require.NoError(t, err)
+	require.True(t, o.isEndorsed(mustParseCertificate(t, o.GetCertificateChain())))
+}
+
+func TestOverlay_HasEndorsement(t *testing.T) {
+	o := &overlay{}
+	require.False(t, o.HasEndorsement())
+
+	o.endorser = bls.Generate()
+	require.True(t, o.HasEndorsement())
+}
+
+func TestOverlay_BadSigner_Endorsement(t *testing.T) {
+	_, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		endorser:     fake.NewBadSigner(),
+	})
+	require.EqualError(t, err,
+		fake.Err("certificate failed: while generating: endorsement failed: signer"))
+}
+
+func TestOverlay_CheckEndorsement(t *testing.T) {
+	signer := bls.Generate()
+
+	o, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		endorser:     signer,
+	})
+	require.NoError(t, err)
+
+	cert := mustParseCertificate(t, o.GetCertificateChain())
+
+	// The certificates are accepted until the roster is known.
+	require.NoError(t, o.checkEndorsement(o.myAddr, cert))
+	require.NoError(t, o.checkEndorsement(o.myAddr, &x509.Certificate{}))
+
+	// The chain has no roster yet.
+	o.SetRoster(func() (crypto.CollectiveAuthority, error) { return nil, nil })
+	require.NoError(t, o.checkEndorsement(o.myAddr, cert))
+
+	roster := fakeRoster{keys: map[string]crypto.PublicKey{
+		o.myAddr.String(): signer.GetPublicKey(),
+	}}
+
+	o.SetRoster(func() (crypto.CollectiveAuthority, error) { return roster, nil })
+	require.NoError(t, o.checkEndorsement(o.myAddr, cert))
+
+	// Not a member of the roster.
+	require.NoError(t, o.checkEndorsement(session.NewAddress("127.0.0.1:1"), cert))
+
+	roster.keys[o.myAddr.String()] = bls.Generate().GetPublicKey()
+	err = o.checkEndorsement(o.myAddr, cert)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid endorsement for grpcs://127.0.0.1:0: ")
+
+	err = o.checkPeerEndorsement(o.myAddr, o.GetCertificateChain())
+	require.Error(t, err)
+
+	err = o.checkPeerEndorsement(o.myAddr, []byte("bad chain"))
+	require.EqualError(t, err, "couldn't parse certificate: x509: malformed certificate")
+
+	o.SetRoster(func() (crypto.CollectiveAuthority, error) { return nil, fake.GetError() })
+	err = o.checkEndorsement(o.myAddr, cert)
+	require.EqualError(t, err, fake.Err("roster unavailable: failed to read roster"))
+
+	o.endorser = nil
+	require.NoError(t, o.checkEndorsement(o.myAddr, &x509.Certificate{}))
+}
+
+func TestOverlay_CheckRosterEndorsement(t *testing.T) {
+	signer := bls.Generate()
+
+	o, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		endorser:     signer,
+	})
+	require.NoError(t, err)
+
+	cert := mustParseCertificate(t, o.GetCertificateChain())
+
+	err = o.checkRosterEndorsement(o.myAddr, cert)
+	require.EqualError(t, err, "roster is unknown")
+
+	o.SetRoster(func() (crypto.CollectiveAuthority, error) { return nil, fake.GetError() })
+	err = o.checkRosterEndorsement(o.myAddr, cert)
+	require.EqualError(t, err, fake.Err("failed to read roster"))
+
+	roster := fakeRoster{keys: map[string]crypto.PublicKey{
+		o.myAddr.String(): signer.GetPublicKey(),
+	}}
+
+	o.SetRoster(func() (crypto.CollectiveAuthority, error) { return nil, nil })
+	err = o.checkRosterEndorsement(o.myAddr, cert)
+	require.EqualError(t, err, "grpcs://127.0.0.1:0 is not a member")
+
+	o.SetRoster(func() (crypto.CollectiveAuthority, error) { return roster, nil })
+	require.NoError(t, o.checkRosterEndorsement(o.myAddr, cert))
+
+	err = o.checkRosterEndorsement(session.NewAddress("127.0.0.1:1"), cert)
+	require.EqualError(t, err, "grpcs://127.0.0.1:1 is not a member")
+
+	err = o.checkRosterEndorsement(o.myAddr, mustParseCertificate(t, fake.MakeCertificate(t)))
+	require.EqualError(t, err,
+		"invalid endorsement for grpcs://127.0.0.1:0: certificate is not endorsed")
+
+	o.endorser = nil
+	err = o.checkRosterEndorsement(o.myAddr, cert)
+	require.EqualError(t, err, "endorsement is disabled")
+}
+
+func TestOverlayServer_Share_Rejoin(t *testing.T) {
+	signer := bls.Generate()
+
+	// The peer has lost the key of its certificate, and creates a new one
+	// endorsed by its signing key.
+	peer, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:8080"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		endorser:     signer,
+	})
+	require.NoError(t, err)
+
+	from := peer.myAddr
+
+	fromBuf, err := from.MarshalText()
+	require.NoError(t, err)
+
+	server := overlayServer{
+		overlay: &overlay{
+			certs:       certs.NewInMemoryStore(),
+			addrFactory: addressFac,
+			endorser:    bls.Generate(),
+			context:     json.NewContext(),
+		},
+	}
+
+	server.certs.Store(from, fake.MakeCertificate(t))
+
+	msg := &ptypes.CertificateChain{Address: fromBuf, Value: peer.GetCertificateChain()}
+
+	_, err = server.Share(context.Background(), msg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), ", and roster is unknown")
+
+	server.SetRoster(func() (crypto.CollectiveAuthority, error) {
+		return fakeRoster{keys: map[string]crypto.PublicKey{
+			from.String(): signer.GetPublicKey(),
+		}}, nil
+	})
+
+	_, err = server.Share(context.Background(), msg)
+	require.NoError(t, err)
+
+	stored, err := server.certs.Load(from)
+	require.NoError(t, err)
+	require.Equal(t, peer.GetCertificateChain(), stored)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeRoster struct {
+	crypto.CollectiveAuthority
+
+	keys map[string]crypto.PublicKey
+}
+
+func (r fakeRoster) GetPublicKey(addr mino.Address) (crypto.PublicKey, int) {
+	pubkey, found := r.keys[addr.String()]
+	if !found {
+		return nil, -1
+	}
+
+	return pubkey, 0
+}
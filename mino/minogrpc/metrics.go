@@ -0,0 +1,59 @@
+// This file contains the Prometheus collectors of the overlay.
+
+package minogrpc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.dedis.ch/dela"
+)
+
+// defines prometheus metrics
+var (
+	promCallMsgsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_call_messages_sent_total",
+		Help: "total number of messages sent by unicast calls",
+	}, []string{"rpc"})
+
+	promCallMsgsRecv = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_call_messages_received_total",
+		Help: "total number of messages received by unicast calls",
+	}, []string{"rpc"})
+
+	promCallBytesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_call_bytes_sent_total",
+		Help: "total number of payload bytes sent by unicast calls",
+	}, []string{"rpc"})
+
+	promCallBytesRecv = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_call_bytes_received_total",
+		Help: "total number of payload bytes received by unicast calls",
+	}, []string{"rpc"})
+
+	promCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dela_minogrpc_call_duration_seconds",
+		Help:    "latency of a unicast call to a participant",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"rpc"})
+
+	promStreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dela_minogrpc_stream_duration_seconds",
+		Help:    "amount of time a stream stays open",
+		Buckets: []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"rpc"})
+
+	promConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dela_minogrpc_connections",
+		Help: "number of open connections to distant peers",
+	})
+
+	promRoutingTables = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dela_minogrpc_routing_tables_total",
+		Help: "total number of routing tables built for streams",
+	})
+)
+
+func init() {
+	dela.PromCollectors = append(dela.PromCollectors, promCallMsgsSent,
+		promCallMsgsRecv, promCallBytesSent, promCallBytesRecv, promCallDuration,
+		promStreamDuration, promConnections, promRoutingTables)
+}
@@ -0,0 +1,57 @@
+package minogrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestMetrics_Call(t *testing.T) {
+	rpc := &RPC{
+		uri:     "metrics",
+		factory: fake.MessageFactory{},
+		overlay: &overlay{
+			connMgr: fakeConnMgr{},
+			context: json.NewContext(),
+		},
+	}
+
+	addrs := mino.NewAddresses(session.NewAddress("A"), session.NewAddress("B"))
+
+	msgs, err := rpc.Call(context.Background(), fake.Message{}, addrs)
+	require.NoError(t, err)
+
+	for range msgs {
+	}
+
+	require.Equal(t, 2.0, testutil.ToFloat64(promCallMsgsSent.WithLabelValues("metrics")))
+	require.Equal(t, 2.0, testutil.ToFloat64(promCallMsgsRecv.WithLabelValues("metrics")))
+	require.Equal(t, 4.0, testutil.ToFloat64(promCallBytesSent.WithLabelValues("metrics")))
+	require.Equal(t, 4.0, testutil.ToFloat64(promCallBytesRecv.WithLabelValues("metrics")))
+	require.NotZero(t, testutil.CollectAndCount(promCallDuration))
+}
+
+func TestMetrics_Connections(t *testing.T) {
+	mgr := newConnManager(fake.NewAddress(0), nil, false)
+	addr := session.NewAddress("grpc://127.0.0.1:2000")
+
+	before := testutil.ToFloat64(promConnections)
+
+	_, err := mgr.Acquire(addr)
+	require.NoError(t, err)
+	require.Equal(t, before+1, testutil.ToFloat64(promConnections))
+
+	_, err = mgr.Acquire(addr)
+	require.NoError(t, err)
+	require.Equal(t, before+1, testutil.ToFloat64(promConnections))
+
+	mgr.Release(addr)
+	mgr.Release(addr)
+	require.Equal(t, before, testutil.ToFloat64(promConnections))
+}
@@ -13,7 +13,6 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io"
-	"math/big"
 	"net"
 	"net/url"
 	"regexp"
@@ -24,6 +23,7 @@ import (
 	otgrpc "github.com/opentracing-contrib/go-grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/internal/traffic"
 	"go.dedis.ch/dela/mino"
@@ -119,6 +119,8 @@ type Minogrpc struct {
 	endpoints map[string]*Endpoint
 	started   chan struct{}
 	closing   chan error
+
+	stopRenewal context.CancelFunc
 }
 
 type minoTemplate struct {
@@ -132,53 +134,11 @@ type minoTemplate struct {
 	random   io.Reader
 	cert     *tls.Certificate
 	serveTLS bool
-}
-
-func (m *minoTemplate) makeCertificate() error {
-	var ips []net.IP
-	var dnsNames []string
-
-	hostname, err := m.myAddr.GetHostname()
-	if err != nil {
-		return xerrors.Errorf("failed to get hostname: %v", err)
-	}
-
-	ip := net.ParseIP(hostname)
-	if ip != nil {
-		ips = []net.IP{ip}
-	} else {
-		dnsNames = []string{hostname}
-	}
-
-	dela.Logger.Info().Str("hostname", hostname).
-		Strs("dnsNames", dnsNames).
-		Msgf("creating certificate: ips: %v", ips)
-
-	tmpl := &x509.Certificate{
-		SerialNumber: big.NewInt(1),
-		IPAddresses:  ips,
-		DNSNames:     dnsNames,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().Add(certificateDuration),
-
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		BasicConstraintsValid: true,
-		MaxPathLen:            1,
-		IsCA:                  true,
-	}
-
-	buf, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, m.public, m.secret)
-	if err != nil {
-		return xerrors.Errorf("while creating: %+v", err)
-	}
-
-	err = m.certs.Store(m.myAddr, buf)
-	if err != nil {
-		return xerrors.Errorf("while storing: %v", err)
-	}
 
-	return nil
+	certDuration time.Duration
+	renewal      time.Duration
+	endorser     crypto.Signer
+	context      serde.Context
 }
 
 // Option is the type to set some fields when instantiating an overlay.
@@ -215,6 +175,32 @@ func WithCert(cert *tls.Certificate) Option {
 	}
 }
 
+// WithCertificateDuration is an option to set the validity period of the
+// certificates generated by the overlay.
+func WithCertificateDuration(d time.Duration) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.certDuration = d
+	}
+}
+
+// WithCertificateRenewal is an option to automatically renew the generated
+// certificate when it expires in less than the given margin. The new
+// certificate is signed with the same key and shared with the known peers. It
+// has no effect when the certificate is provided with WithCert.
+func WithCertificateRenewal(margin time.Duration) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.renewal = margin
+	}
+}
+
+// WithContext is an option to set the serde context of the messages, which is
+// the JSON one by default. All the participants must use the same format.
+func WithContext(ctx serde.Context) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.context = ctx
+	}
+}
+
 // NoTLS sets up the gRPC server to serve plain connections only.
 func NoTLS() Option {
 	return func(tmpl *minoTemplate) {
@@ -258,6 +244,8 @@ func NewMinogrpc(listen net.Addr, public *url.URL, router router.Router, opts ..
 		curve:    elliptic.P521(),
 		random:   rand.Reader,
 		serveTLS: true,
+
+		certDuration: certificateDuration,
 	}
 
 	for _, opt := range opts {
@@ -295,20 +283,16 @@ func NewMinogrpc(listen net.Addr, public *url.URL, router router.Router, opts ..
 			return nil, xerrors.Errorf("failed to parse chain: %v", err)
 		}
 
-		certsBuf := make([][]byte, len(certificates))
-		for i, c := range certificates {
-			certsBuf[i] = c.Raw
+		if len(certificates) == 0 {
+			socket.Close()
+			return nil, xerrors.New("no certificate found")
 		}
 
+		// The certificate is looked up for each handshake so that a renewed
+		// one is served without restarting the server.
 		creds := credentials.NewTLS(&tls.Config{
-			Certificates: []tls.Certificate{
-				{
-					Certificate: certsBuf,
-					Leaf:        certificates[0],
-					PrivateKey:  o.secret,
-				},
-			},
-			MinVersion: tls.VersionTLS12,
+			GetCertificate: o.getCertificate,
+			MinVersion:     tls.VersionTLS12,
 		})
 
 		srvOpts = append(srvOpts, grpc.Creds(creds))
@@ -337,6 +321,14 @@ func NewMinogrpc(listen net.Addr, public *url.URL, router router.Router, opts ..
 
 	m.listen(socket)
 
+	if tmpl.serveTLS && tmpl.cert == nil && tmpl.renewal > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.stopRenewal = cancel
+
+		m.closer.Add(1)
+		go m.watchCertificate(ctx)
+	}
+
 	return m, nil
 }
 
@@ -373,6 +365,10 @@ func (m *Minogrpc) Stop() error {
 }
 
 func (m *Minogrpc) postCheckClose() error {
+	if m.stopRenewal != nil {
+		m.stopRenewal()
+	}
+
 	m.closer.Wait()
 
 	err := <-m.closing
@@ -477,6 +473,26 @@ func (m *Minogrpc) listen(socket net.Listener) {
 	<-m.started
 }
 
+// watchCertificate periodically checks the expiration of the server
+// certificate and renews it when it falls within the renewal margin. It runs
+// until the context is done.
+func (m *Minogrpc) watchCertificate(ctx context.Context) {
+	defer m.closer.Done()
+
+	for {
+		err := m.overlay.renewIfExpiring(time.Now())
+		if err != nil {
+			dela.Logger.Warn().Err(err).Msg("certificate renewal failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(renewalCheckInterval):
+		}
+	}
+}
+
 // decorateServerTrace adds the protocol tag and the streamID tag to a server
 // side trace.
 func decorateServerTrace(
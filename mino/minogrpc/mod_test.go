@@ -1,6 +1,7 @@
 package minogrpc
 
 import (
+	"bytes"
 	"context"
 	"net"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"go.dedis.ch/dela/mino/minogrpc/session"
 	"go.dedis.ch/dela/mino/minogrpc/tokens"
 	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/testing/fake"
 	"google.golang.org/grpc"
 )
@@ -55,6 +57,56 @@ func TestMinogrpc_noTLS(t *testing.T) {
 	require.NoError(t, m.GracefulStop())
 }
 
+func TestMinogrpc_WithContext(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 3333)
+
+	router := tree.NewRouter(addressFac)
+
+	ctx := fake.NewContextWithFormat(serde.FormatCBOR)
+
+	m, err := NewMinogrpc(addr, nil, router, NoTLS(), WithContext(ctx))
+	require.NoError(t, err)
+	require.Equal(t, serde.FormatCBOR, m.context.GetFormat())
+
+	<-m.started
+	require.NoError(t, m.GracefulStop())
+}
+
+func TestMinogrpc_CertificateRenewal(t *testing.T) {
+	defer func(interval time.Duration) {
+		renewalCheckInterval = interval
+	}(renewalCheckInterval)
+
+	renewalCheckInterval = 10 * time.Millisecond
+
+	addr := ParseAddress("127.0.0.1", 0)
+
+	m1, err := NewMinogrpc(addr, nil, tree.NewRouter(addressFac),
+		WithCertificateDuration(time.Hour), WithCertificateRenewal(time.Hour))
+	require.NoError(t, err)
+
+	m2, err := NewMinogrpc(addr, nil, tree.NewRouter(addressFac))
+	require.NoError(t, err)
+
+	defer m2.GracefulStop()
+
+	previous := m1.GetCertificateChain()
+
+	m1.GetCertificateStore().Store(m2.GetAddress(), m2.GetCertificateChain())
+	m2.GetCertificateStore().Store(m1.GetAddress(), previous)
+
+	// The certificate of m1 expires within the renewal margin, so it is
+	// renewed and shared with m2 continuously.
+	require.Eventually(t, func() bool {
+		shared, err := m2.GetCertificateStore().Load(m1.GetAddress())
+		require.NoError(t, err)
+
+		return !bytes.Equal(previous, shared)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, m1.GracefulStop())
+}
+
 func TestMinogrpc_New_FailedParsePublic(t *testing.T) {
 	l := listener
 	defer func() {
@@ -88,7 +140,7 @@ func TestMinogrpc_FailCreateCertNew(t *testing.T) {
 
 	_, err := NewMinogrpc(addr, nil, router, WithCertificateKey(struct{}{}, struct{}{}))
 	require.Error(t, err)
-	require.Contains(t, err.Error(), "overlay: certificate failed: while creating: x509: ")
+	require.Contains(t, err.Error(), "overlay: certificate failed: while generating: while creating: x509: ")
 }
 
 func TestMinogrpc_FailStoreCerTNew(t *testing.T) {
@@ -8,6 +8,7 @@ package minogrpc
 import (
 	context "context"
 	"sync"
+	"time"
 
 	"github.com/rs/xid"
 	"go.dedis.ch/dela"
@@ -79,8 +80,16 @@ func (rpc *RPC) Call(
 			header := metadata.New(map[string]string{headerURIKey: rpc.uri})
 			newCtx := metadata.NewOutgoingContext(ctx, header)
 
+			promCallMsgsSent.WithLabelValues(rpc.uri).Inc()
+			promCallBytesSent.WithLabelValues(rpc.uri).Add(float64(len(data)))
+
+			start := time.Now()
+
 			callResp, err := cl.Call(newCtx, sendMsg,
 				grpc.MaxCallRecvMsgSize(session.MaxMessageSize))
+
+			promCallDuration.WithLabelValues(rpc.uri).Observe(time.Since(start).Seconds())
+
 			if err != nil {
 				resp := mino.NewResponseWithError(
 					addr,
@@ -95,6 +104,9 @@ func (rpc *RPC) Call(
 				return
 			}
 
+			promCallMsgsRecv.WithLabelValues(rpc.uri).Inc()
+			promCallBytesRecv.WithLabelValues(rpc.uri).Add(float64(len(callResp.GetPayload())))
+
 			resp, err := rpc.factory.Deserialize(rpc.overlay.context, callResp.GetPayload())
 			if err != nil {
 				resp := mino.NewResponseWithError(
@@ -169,6 +181,8 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (
 		return nil, nil, xerrors.Errorf("routing table failed: %v", err)
 	}
 
+	promRoutingTables.Inc()
+
 	gw, others := rpc.findGateway(players)
 
 	for _, addr := range others {
@@ -222,8 +236,12 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (
 
 	rpc.overlay.closer.Add(1)
 
+	start := time.Now()
+
 	go func() {
 		defer func() {
+			promStreamDuration.WithLabelValues(rpc.uri).Observe(time.Since(start).Seconds())
+
 			relay.Close()
 			rpc.overlay.connMgr.Release(gw)
 			rpc.overlay.closer.Done()
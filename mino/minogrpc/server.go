@@ -11,11 +11,14 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
-	"sync"
-
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
 	"net/url"
+	"sync"
 	"time"
 
 	otgrpc "github.com/opentracing-contrib/go-grpc"
@@ -23,6 +26,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"go.dedis.ch/dela"
+	dcrypto "go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
@@ -50,6 +54,10 @@ const (
 
 	certificateDuration = time.Hour * 24 * 180
 
+	// shareTimeout is the maximum amount of time allowed to share a renewed
+	// certificate with a peer.
+	shareTimeout = 20 * time.Second
+
 	// defaultMinConnectTimeout is the minimum amount of time we are willing to
 	// wait for a grpc connection to complete
 	defaultMinConnectTimeout = 60 * time.Second
@@ -57,6 +65,10 @@ const (
 
 var getTracerForAddr = tracing.GetTracerForAddr
 
+// renewalCheckInterval is the period between two checks of the expiration of
+// the server certificate. Having it as a variable is convenient for the tests.
+var renewalCheckInterval = time.Hour
+
 type overlayServer struct {
 	*overlay
 
@@ -181,11 +193,58 @@ func (o overlayServer) Share(
 		return nil, xerrors.Errorf("chain cert invalid: %v", err)
 	}
 
-	o.certs.Store(from, msg.GetValue())
+	known, err := o.certs.Load(from)
+	if err != nil {
+		return nil, xerrors.Errorf("while loading known cert: %v", err)
+	}
+
+	if known != nil && !bytes.Equal(known, msg.GetValue()) {
+		err = checkRenewal(known, certs[len(certs)-1])
+		if err != nil {
+			// A member of the roster that has lost its key can join again
+			// with a certificate endorsed by its signing key.
+			errEndorse := o.checkRosterEndorsement(from, certs[0])
+			if errEndorse != nil {
+				return nil, xerrors.Errorf("renewal rejected: %v, and %v", err, errEndorse)
+			}
+		}
+	}
+
+	err = o.checkEndorsement(from, certs[0])
+	if err != nil {
+		return nil, xerrors.Errorf("endorsement rejected: %v", err)
+	}
+
+	err = o.certs.Store(from, msg.GetValue())
+	if err != nil {
+		return nil, xerrors.Errorf("while storing certificate: %v", err)
+	}
 
 	return &ptypes.CertificateAck{}, nil
 }
 
+// checkRenewal verifies that the root certificate replacing a known chain is
+// signed by the key of the known root certificate, even if the known chain has
+// expired. The certificate of a peer that has lost its key must be removed by
+// the operator before the peer can join again, unless it is endorsed.
+func checkRenewal(known certs.CertChain, root *x509.Certificate) error {
+	previous, err := x509.ParseCertificates(known)
+	if err != nil {
+		return xerrors.Errorf("couldn't parse known certificate: %v", err)
+	}
+
+	if len(previous) == 0 {
+		return xerrors.New("no known certificate")
+	}
+
+	err = root.CheckSignatureFrom(previous[len(previous)-1])
+	if err != nil {
+		return xerrors.Errorf("not signed by the known certificate: %v", err)
+	}
+
+	return nil
+}
+
 // Call implements minogrpc.OverlayServer. It processes the request with the
 // targeted handler if it exists, otherwise it returns an error.
 func (o overlayServer) Call(ctx context.Context, msg *ptypes.Message) (*ptypes.Message, error) {
@@ -198,6 +257,9 @@ func (o overlayServer) Call(ctx context.Context, msg *ptypes.Message) (*ptypes.M
 		return nil, xerrors.Errorf("handler '%s' is not registered", uri)
 	}
 
+	promCallMsgsRecv.WithLabelValues(uri).Inc()
+	promCallBytesRecv.WithLabelValues(uri).Add(float64(len(msg.GetPayload())))
+
 	message, err := endpoint.Factory.Deserialize(o.context, msg.GetPayload())
 	if err != nil {
 		return nil, xerrors.Errorf("couldn't deserialize message: %v", err)
@@ -224,6 +286,9 @@ func (o overlayServer) Call(ctx context.Context, msg *ptypes.Message) (*ptypes.M
 		return nil, xerrors.Errorf("couldn't serialize result: %v", err)
 	}
 
+	promCallMsgsSent.WithLabelValues(uri).Inc()
+	promCallBytesSent.WithLabelValues(uri).Add(float64(len(res)))
+
 	return &ptypes.Message{Payload: res}, nil
 }
 
@@ -264,6 +329,11 @@ func (o *overlayServer) Stream(stream ptypes.Overlay_StreamServer) error {
 		return xerrors.Errorf("handler '%s' is not registered", uri)
 	}
 
+	start := time.Now()
+	defer func() {
+		promStreamDuration.WithLabelValues(uri).Observe(time.Since(start).Seconds())
+	}()
+
 	md := metadata.Pairs(
 		headerURIKey, uri,
 		headerStreamIDKey, streamID,
@@ -385,6 +455,8 @@ func (o *overlayServer) tableFromHeaders(h metadata.MD) (router.RoutingTable, bo
 			return nil, false, xerrors.Errorf("invalid handshake: %v", err)
 		}
 
+		promRoutingTables.Inc()
+
 		return table, false, nil
 	}
 
@@ -400,6 +472,8 @@ func (o *overlayServer) tableFromHeaders(h metadata.MD) (router.RoutingTable, bo
 		return nil, true, xerrors.Errorf("failed to create: %v", err)
 	}
 
+	promRoutingTables.Inc()
+
 	return table, true, nil
 }
 
@@ -442,6 +516,17 @@ type overlay struct {
 	addrFactory mino.AddressFactory
 	serveTLS    bool
 
+	// certDuration is the validity period of the generated certificates, and
+	// renewal the margin before the expiration when it must be renewed.
+	certDuration time.Duration
+	renewal      time.Duration
+
+	// endorser signs the certificate of the overlay, and roster returns the
+	// members whose certificates must be endorsed.
+	endorser   dcrypto.Signer
+	rosterLock sync.RWMutex
+	roster     func() (dcrypto.CollectiveAuthority, error)
+
 	// secret and public are the key pair that has generated the server
 	// certificate.
 	secret interface{}
@@ -456,6 +541,31 @@ func newOverlay(tmpl *minoTemplate) (*overlay, error) {
 	// session.Address never returns an error
 	myAddrBuf, _ := tmpl.myAddr.MarshalText()
 
+	ctx := tmpl.context
+	if ctx.ContextEngine == nil {
+		ctx = json.NewContext()
+	}
+
+	o := &overlay{
+		closer:       new(sync.WaitGroup),
+		context:      ctx,
+		myAddr:       tmpl.myAddr,
+		myAddrStr:    string(myAddrBuf),
+		tokens:       tokens.NewInMemoryHolder(),
+		certs:        tmpl.certs,
+		router:       tmpl.router,
+		connMgr:      newConnManager(tmpl.myAddr, tmpl.certs, tmpl.serveTLS),
+		addrFactory:  tmpl.fac,
+		serveTLS:     tmpl.serveTLS,
+		certDuration: tmpl.certDuration,
+		renewal:      tmpl.renewal,
+		endorser:     tmpl.endorser,
+	}
+
+	if mgr, ok := o.connMgr.(*connManager); ok {
+		mgr.verify = o.checkEndorsement
+	}
+
 	if tmpl.serveTLS {
 		if tmpl.cert != nil {
 			tmpl.secret = tmpl.cert.PrivateKey
@@ -482,36 +592,261 @@ func newOverlay(tmpl *minoTemplate) (*overlay, error) {
 			tmpl.public = priv.Public()
 		}
 
+		o.secret = tmpl.secret
+		o.public = tmpl.public
+
 		// Need to make sure that the certificate we loaded actually
 		// matches our address.
 		cert, err := tmpl.certs.Load(tmpl.myAddr)
 		if err != nil {
 			return nil, xerrors.Errorf("while loading cert: %v", err)
 		}
-		if cert == nil {
-			err = tmpl.makeCertificate()
+		if cert == nil || o.mustEndorse(tmpl, cert) {
+			err = o.makeCertificate()
 			if err != nil {
 				return nil, xerrors.Errorf("certificate failed: %v", err)
 			}
 		}
 	}
 
-	o := &overlay{
-		closer:      new(sync.WaitGroup),
-		context:     json.NewContext(),
-		myAddr:      tmpl.myAddr,
-		myAddrStr:   string(myAddrBuf),
-		tokens:      tokens.NewInMemoryHolder(),
-		certs:       tmpl.certs,
-		router:      tmpl.router,
-		connMgr:     newConnManager(tmpl.myAddr, tmpl.certs, tmpl.serveTLS),
-		addrFactory: tmpl.fac,
-		secret:      tmpl.secret,
-		public:      tmpl.public,
-		serveTLS:    tmpl.serveTLS,
+	return o, nil
+}
+
+// mustEndorse returns true if the stored certificate of the overlay is missing
+// the endorsement, in which case a new one is generated with the same key.
+func (o *overlay) mustEndorse(tmpl *minoTemplate, chain certs.CertChain) bool {
+	if o.endorser == nil {
+		return false
 	}
 
-	return o, nil
+	if tmpl.cert != nil {
+		dela.Logger.Warn().Msg("a provided certificate cannot be endorsed")
+		return false
+	}
+
+	certificates, err := x509.ParseCertificates(chain)
+	if err != nil || len(certificates) == 0 {
+		return true
+	}
+
+	return !o.isEndorsed(certificates[0])
+}
+
+// makeCertificate generates a self-signed certificate for the overlay address
+// and stores it.
+func (o *overlay) makeCertificate() error {
+	buf, err := o.createCertificate()
+	if err != nil {
+		return xerrors.Errorf("while generating: %v", err)
+	}
+
+	err = o.certs.Store(o.myAddr, buf)
+	if err != nil {
+		return xerrors.Errorf("while storing: %v", err)
+	}
+
+	return nil
+}
+
+func (o *overlay) createCertificate() ([]byte, error) {
+	var ips []net.IP
+	var dnsNames []string
+
+	hostname, err := o.myAddr.GetHostname()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get hostname: %v", err)
+	}
+
+	ip := net.ParseIP(hostname)
+	if ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		dnsNames = []string{hostname}
+	}
+
+	dela.Logger.Info().Str("hostname", hostname).
+		Strs("dnsNames", dnsNames).
+		Msgf("creating certificate: ips: %v", ips)
+
+	now := time.Now()
+
+	tmpl := &x509.Certificate{
+		// The serial number is based on the time so that a renewed certificate
+		// does not reuse the one of its predecessor.
+		SerialNumber: big.NewInt(now.UnixNano()),
+		IPAddresses:  ips,
+		DNSNames:     dnsNames,
+		NotBefore:    now,
+		NotAfter:     now.Add(o.certDuration),
+
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		MaxPathLen:            1,
+		IsCA:                  true,
+	}
+
+	if o.endorser != nil {
+		ext, err := o.makeEndorsement()
+		if err != nil {
+			return nil, xerrors.Errorf("endorsement failed: %v", err)
+		}
+
+		tmpl.ExtraExtensions = []pkix.Extension{ext}
+	}
+
+	buf, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, o.public, o.secret)
+	if err != nil {
+		return nil, xerrors.Errorf("while creating: %+v", err)
+	}
+
+	return buf, nil
+}
+
+// RenewCertificate generates a new certificate with the same key as the
+// current one, which makes it verifiable by the peers knowing the previous
+// certificate. The new certificate is stored and then shared with the known
+// peers.
+func (o *overlay) RenewCertificate() error {
+	current, err := o.loadOwnCertificates()
+	if err != nil {
+		return xerrors.Errorf("current certificate: %v", err)
+	}
+
+	buf, err := o.createCertificate()
+	if err != nil {
+		return xerrors.Errorf("certificate failed: %v", err)
+	}
+
+	renewed, err := x509.ParseCertificate(buf)
+	if err != nil {
+		return xerrors.Errorf("couldn't parse certificate: %v", err)
+	}
+
+	err = renewed.CheckSignatureFrom(current[len(current)-1])
+	if err != nil {
+		return xerrors.Errorf("certificate is not linked to the current one: %v", err)
+	}
+
+	err = o.certs.Store(o.myAddr, buf)
+	if err != nil {
+		return xerrors.Errorf("while storing: %v", err)
+	}
+
+	dela.Logger.Info().
+		Time("expiration", renewed.NotAfter).
+		Msg("certificate renewed")
+
+	o.shareCertificate(buf)
+
+	return nil
+}
+
+// renewIfExpiring renews the certificate of the overlay if it expires within
+// the renewal margin from the given time.
+func (o *overlay) renewIfExpiring(now time.Time) error {
+	current, err := o.loadOwnCertificates()
+	if err != nil {
+		return xerrors.Errorf("current certificate: %v", err)
+	}
+
+	if current[0].NotAfter.Sub(now) > o.renewal {
+		return nil
+	}
+
+	err = o.RenewCertificate()
+	if err != nil {
+		return xerrors.Errorf("failed to renew: %v", err)
+	}
+
+	return nil
+}
+
+// shareCertificate sends the certificate chain to every known peer. A failure
+// is only logged as the peer will still be able to verify the new certificate
+// with the previous one until it expires.
+func (o *overlay) shareCertificate(chain certs.CertChain) {
+	peers := make([]mino.Address, 0)
+	o.certs.Range(func(addr mino.Address, _ certs.CertChain) bool {
+		if !addr.Equal(o.myAddr) {
+			peers = append(peers, addr)
+		}
+		return true
+	})
+
+	msg := &ptypes.CertificateChain{
+		Address: []byte(o.myAddrStr),
+		Value:   chain,
+	}
+
+	for _, peer := range peers {
+		err := o.sharePeer(peer, msg)
+		if err != nil {
+			dela.Logger.Warn().Err(err).
+				Stringer("peer", peer).
+				Msg("failed to share renewed certificate")
+		}
+	}
+}
+
+func (o *overlay) sharePeer(to mino.Address, msg *ptypes.CertificateChain) error {
+	conn, err := o.connMgr.Acquire(to)
+	if err != nil {
+		return xerrors.Errorf("couldn't open connection: %v", err)
+	}
+
+	defer o.connMgr.Release(to)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shareTimeout)
+	defer cancel()
+
+	client := ptypes.NewOverlayClient(conn)
+
+	_, err = client.Share(ctx, msg, grpc.MaxCallRecvMsgSize(session.MaxMessageSize))
+	if err != nil {
+		return xerrors.Errorf("couldn't call share: %v", err)
+	}
+
+	return nil
+}
+
+// getCertificate returns the TLS certificate of the overlay as currently
+// stored. It is used by the server so that a renewed certificate is served
+// for new connections.
+func (o *overlay) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certificates, err := o.loadOwnCertificates()
+	if err != nil {
+		return nil, xerrors.Errorf("own certificate: %v", err)
+	}
+
+	raw := make([][]byte, len(certificates))
+	for i, c := range certificates {
+		raw[i] = c.Raw
+	}
+
+	return &tls.Certificate{
+		Certificate: raw,
+		Leaf:        certificates[0],
+		PrivateKey:  o.secret,
+	}, nil
+}
+
+func (o *overlay) loadOwnCertificates() ([]*x509.Certificate, error) {
+	chain, err := o.certs.Load(o.myAddr)
+	if err != nil {
+		return nil, xerrors.Errorf("while loading: %v", err)
+	}
+
+	certificates, err := x509.ParseCertificates(chain)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse chain: %v", err)
+	}
+
+	if len(certificates) == 0 {
+		return nil, xerrors.New("no certificate found")
+	}
+
+	return certificates, nil
 }
 
 // ServeTLS returns true if the gRPC server uses TLS
@@ -596,6 +931,12 @@ func (o *overlay) Join(addr *url.URL, token string, certHash []byte) error {
 		// joined. That will allow the node to communicate with the network.
 		for _, raw := range resp.Peers {
 			from := o.addrFactory.FromText(raw.GetAddress())
+
+			err = o.checkPeerEndorsement(from, raw.GetValue())
+			if err != nil {
+				return xerrors.Errorf("peer certificate rejected: %v", err)
+			}
+
 			o.certs.Store(from, raw.GetValue())
 		}
 	}
@@ -603,6 +944,25 @@ func (o *overlay) Join(addr *url.URL, token string, certHash []byte) error {
 	return nil
 }
 
+// checkPeerEndorsement verifies the endorsement of the leaf certificate of the
+// chain if the endorsement is enabled.
+func (o *overlay) checkPeerEndorsement(addr mino.Address, chain certs.CertChain) error {
+	if o.endorser == nil {
+		return nil
+	}
+
+	certificates, err := x509.ParseCertificates(chain)
+	if err != nil {
+		return xerrors.Errorf("couldn't parse certificate: %v", err)
+	}
+
+	if len(certificates) == 0 {
+		return xerrors.New("no certificate found")
+	}
+
+	return o.checkEndorsement(addr, certificates[0])
+}
+
 // ConnManager is a manager to dial and close connections depending on the
 // usage.
 //
@@ -614,6 +974,10 @@ type connManager struct {
 	counters map[mino.Address]int
 	conns    map[mino.Address]*grpc.ClientConn
 	serveTLS bool
+
+	// verify is an optional verification of the certificate presented by the
+	// distant peer during the handshake.
+	verify func(mino.Address, *x509.Certificate) error
 }
 
 func newConnManager(myAddr mino.Address, certs certs.Storage, serveTLS bool) *connManager {
@@ -699,6 +1063,8 @@ func (mgr *connManager) Acquire(to mino.Address) (grpc.ClientConnInterface, erro
 	mgr.conns[to] = conn
 	mgr.counters[to] = 1
 
+	promConnections.Inc()
+
 	return conn, nil
 }
 
@@ -741,7 +1107,7 @@ func (mgr *connManager) getTransportCredential(addr mino.Address) (
 		return nil, xerrors.New("no certificate found")
 	}
 
-	ta := credentials.NewTLS(&tls.Config{
+	cfg := &tls.Config{
 		Certificates: []tls.Certificate{
 			{
 				Certificate: [][]byte{meCerts[0].Raw},
@@ -750,7 +1116,19 @@ func (mgr *connManager) getTransportCredential(addr mino.Address) (
 		},
 		RootCAs:    pool,
 		MinVersion: tls.VersionTLS12,
-	})
+	}
+
+	if mgr.verify != nil {
+		cfg.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			if len(chains) == 0 || len(chains[0]) == 0 {
+				return xerrors.New("no verified chain")
+			}
+
+			return mgr.verify(addr, chains[0][0])
+		}
+	}
+
+	ta := credentials.NewTLS(cfg)
 
 	return ta, nil
 }
@@ -770,6 +1148,8 @@ func (mgr *connManager) Release(to mino.Address) {
 			delete(mgr.conns, to)
 
 			err := conn.Close()
+			promConnections.Dec()
+
 			dela.Logger.Trace().
 				Err(err).
 				Stringer("to", to).
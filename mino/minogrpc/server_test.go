@@ -2,9 +2,12 @@ package minogrpc
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/x509"
 	"fmt"
+	"math/big"
 	"net"
 	"net/url"
 	"strings"
@@ -424,6 +427,99 @@ func TestOverlayServer_Share_Chain_Invalid(t *testing.T) {
 		"chain cert invalid: x509: cannot validate certificate for 127.0.0.1 because it doesn't contain any IP SANs")
 }
 
+func TestOverlayServer_Share_Renewal(t *testing.T) {
+	overlay := overlayServer{
+		overlay: &overlay{
+			certs:       certs.NewInMemoryStore(),
+			addrFactory: addressFac,
+		},
+	}
+
+	from := session.NewAddress("127.0.0.1:8080")
+	fromBuf, err := from.MarshalText()
+	require.NoError(t, err)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	known := makeCertificate(t, priv, time.Now().Add(time.Hour))
+	overlay.certs.Store(from, known)
+
+	renewed := makeCertificate(t, priv, time.Now().Add(2*time.Hour))
+
+	_, err = overlay.Share(context.Background(), &ptypes.CertificateChain{
+		Address: fromBuf,
+		Value:   renewed,
+	})
+	require.NoError(t, err)
+
+	stored, err := overlay.certs.Load(from)
+	require.NoError(t, err)
+	require.Equal(t, certs.CertChain(renewed), stored)
+
+	other, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = overlay.Share(context.Background(), &ptypes.CertificateChain{
+		Address: fromBuf,
+		Value:   makeCertificate(t, other, time.Now().Add(time.Hour)),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "renewal rejected: not signed by the known certificate: ")
+	require.Contains(t, err.Error(), ", and endorsement is disabled")
+
+	overlay.certs = fakeCerts{errLoad: fake.GetError(), errStore: fake.GetError()}
+	_, err = overlay.Share(context.Background(), &ptypes.CertificateChain{
+		Address: fromBuf,
+		Value:   renewed,
+	})
+	require.EqualError(t, err, fake.Err("while loading known cert"))
+
+	overlay.certs = fakeCerts{errStore: fake.GetError()}
+	_, err = overlay.Share(context.Background(), &ptypes.CertificateChain{
+		Address: fromBuf,
+		Value:   renewed,
+	})
+	require.EqualError(t, err, fake.Err("while storing certificate"))
+}
+
+func TestCheckRenewal(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	other, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Now()
+	known := makeCertificate(t, priv, now.Add(time.Hour))
+
+	root, err := x509.ParseCertificate(makeCertificate(t, other, now.Add(time.Hour)))
+	require.NoError(t, err)
+
+	err = checkRenewal(known, root)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not signed by the known certificate: ")
+
+	// An expired certificate is still renewed only with the same key.
+	expired := makeCertificate(t, priv, now.Add(-time.Hour))
+
+	err = checkRenewal(expired, root)
+	require.Error(t, err)
+
+	renewed, err := x509.ParseCertificate(makeCertificate(t, priv, now.Add(time.Hour)))
+	require.NoError(t, err)
+
+	err = checkRenewal(expired, renewed)
+	require.NoError(t, err)
+
+	err = checkRenewal(nil, root)
+	require.EqualError(t, err, "no known certificate")
+
+	err = checkRenewal([]byte("bad chain"), root)
+	require.EqualError(t, err,
+		"couldn't parse known certificate: x509: malformed certificate")
+}
+
 func TestOverlayServer_Call(t *testing.T) {
 	overlay := overlayServer{
 		overlay: &overlay{
@@ -807,6 +903,80 @@ func TestOverlay_New_Wrong_Cert_Store(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to store cert"))
 }
 
+func TestOverlay_RenewCertificate(t *testing.T) {
+	o, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+	})
+	require.NoError(t, err)
+
+	previous := o.GetCertificateChain()
+
+	err = o.RenewCertificate()
+	require.NoError(t, err)
+
+	renewed := o.GetCertificateChain()
+	require.NotEqual(t, previous, renewed)
+
+	err = checkRenewal(previous, mustParseCertificate(t, renewed))
+	require.NoError(t, err)
+
+	tlsCert, err := o.getCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{renewed}, tlsCert.Certificate)
+
+	// A certificate that is not generated by the overlay key cannot be
+	// renewed.
+	o.certs.Store(o.myAddr, fake.MakeCertificate(t))
+	err = o.RenewCertificate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "certificate is not linked to the current one: ")
+
+	o.certs = fakeCerts{errLoad: fake.GetError(), errStore: fake.GetError()}
+	err = o.RenewCertificate()
+	require.EqualError(t, err, fake.Err("current certificate: while loading"))
+
+	o.certs = certs.NewInMemoryStore()
+	err = o.RenewCertificate()
+	require.EqualError(t, err, "current certificate: no certificate found")
+}
+
+func TestOverlay_RenewIfExpiring(t *testing.T) {
+	o, err := newOverlay(&minoTemplate{
+		myAddr:       session.NewAddress("127.0.0.1:0"),
+		certs:        certs.NewInMemoryStore(),
+		curve:        elliptic.P521(),
+		random:       rand.Reader,
+		serveTLS:     true,
+		certDuration: time.Hour,
+		renewal:      time.Minute,
+	})
+	require.NoError(t, err)
+
+	previous := o.GetCertificateChain()
+
+	err = o.renewIfExpiring(time.Now())
+	require.NoError(t, err)
+	require.Equal(t, previous, o.GetCertificateChain())
+
+	err = o.renewIfExpiring(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NotEqual(t, previous, o.GetCertificateChain())
+
+	o.certs.Store(o.myAddr, fake.MakeCertificate(t))
+	err = o.renewIfExpiring(time.Now().Add(time.Hour))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to renew: ")
+
+	o.certs = fakeCerts{errLoad: fake.GetError(), errStore: fake.GetError()}
+	err = o.renewIfExpiring(time.Now())
+	require.EqualError(t, err, fake.Err("current certificate: while loading"))
+}
+
 func TestOverlay_Panic_GetCertificate(t *testing.T) {
 	defer func() {
 		r := recover()
@@ -872,12 +1042,12 @@ func TestOverlay_Join(t *testing.T) {
 }
 
 func TestMakeCertificate_WrongHostname(t *testing.T) {
-	o := minoTemplate{}
+	o := overlay{}
 	o.myAddr = session.NewAddress(":xxx")
 
 	err := o.makeCertificate()
 	require.EqualError(t, err,
-		"failed to get hostname: malformed address: parse \"//:xxx\": invalid port \":xxx\" after host")
+		"while generating: failed to get hostname: malformed address: parse \"//:xxx\": invalid port \":xxx\" after host")
 }
 
 func TestConnManager_Acquire(t *testing.T) {
@@ -1072,6 +1242,31 @@ func makeInstances(t *testing.T, n int, call *fake.Call) ([]mino.Mino, []mino.RP
 	return mm, rpcs
 }
 
+func makeCertificate(t *testing.T, priv *ecdsa.PrivateKey, notAfter time.Time) []byte {
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(notAfter.UnixNano()),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now(),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	buf, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	return buf
+}
+
+func mustParseCertificate(t *testing.T, chain []byte) *x509.Certificate {
+	cert, err := x509.ParseCertificate(chain)
+	require.NoError(t, err)
+
+	return cert
+}
+
 func checkError(t *testing.T, err error, mm ...mino.Mino) {
 	require.Error(t, err)
 
@@ -0,0 +1,61 @@
+// This file contains the Prometheus collectors of the sessions.
+
+package session
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.dedis.ch/dela"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerURIKey is the header set by the overlay with the URI of the RPC.
+const headerURIKey = "apiuri"
+
+// defines prometheus metrics
+var (
+	promMsgsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_stream_messages_sent_total",
+		Help: "total number of messages sent by streams",
+	}, []string{"rpc"})
+
+	promMsgsRecv = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_stream_messages_received_total",
+		Help: "total number of messages received by streams",
+	}, []string{"rpc"})
+
+	promBytesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_stream_bytes_sent_total",
+		Help: "total number of payload bytes sent by streams",
+	}, []string{"rpc"})
+
+	promBytesRecv = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minogrpc_stream_bytes_received_total",
+		Help: "total number of payload bytes received by streams",
+	}, []string{"rpc"})
+
+	promRelayFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dela_minogrpc_relay_failures_total",
+		Help: "total number of packets that failed to be relayed",
+	})
+
+	promTableRebuilds = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dela_minogrpc_routing_table_rebuilds_total",
+		Help: "total number of routing table updates after a link failure",
+	})
+)
+
+func init() {
+	dela.PromCollectors = append(dela.PromCollectors, promMsgsSent, promMsgsRecv,
+		promBytesSent, promBytesRecv, promRelayFailures, promTableRebuilds)
+}
+
+// uriFromHeaders returns the URI of the RPC the stream belongs to, or an empty
+// string if it is not set.
+func uriFromHeaders(md metadata.MD) string {
+	values := md.Get(headerURIKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
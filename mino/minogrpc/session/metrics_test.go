@@ -0,0 +1,53 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/testing/fake"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMetrics_OnFailure(t *testing.T) {
+	sess := &session{
+		queue: newNonBlockingQueue(),
+	}
+
+	p := parent{
+		relay: &streamRelay{stream: &fakeStream{}},
+		table: fakeTable{},
+	}
+
+	failures := testutil.ToFloat64(promRelayFailures)
+	rebuilds := testutil.ToFloat64(promTableRebuilds)
+
+	errs := make(chan error, 1)
+
+	sess.onFailure(p, fake.NewAddress(0), fakePkt{}, errs)
+	require.Equal(t, failures+1, testutil.ToFloat64(promRelayFailures))
+	require.Equal(t, rebuilds+1, testutil.ToFloat64(promTableRebuilds))
+
+	p.table = fakeTable{errFail: fake.GetError()}
+	sess.onFailure(p, fake.NewAddress(0), fakePkt{}, errs)
+	require.Equal(t, failures+2, testutil.ToFloat64(promRelayFailures))
+	require.Equal(t, rebuilds+1, testutil.ToFloat64(promTableRebuilds))
+}
+
+func TestMetrics_Send(t *testing.T) {
+	md := metadata.Pairs(headerURIKey, "metrics")
+
+	sess := NewSession(md, fake.NewAddress(0), fake.MessageFactory{}, nil,
+		fake.NewContext(), nil).(*session)
+
+	require.Equal(t, "metrics", sess.uri)
+
+	errs := sess.Send(fake.Message{})
+	for range errs {
+	}
+
+	require.Equal(t, 1.0, testutil.ToFloat64(promMsgsSent.WithLabelValues("metrics")))
+	require.Equal(t, 0.0, testutil.ToFloat64(promMsgsRecv.WithLabelValues("metrics")))
+
+	require.Empty(t, uriFromHeaders(metadata.MD{}))
+}
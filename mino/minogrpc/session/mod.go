@@ -111,6 +111,7 @@ type session struct {
 
 	log     zerolog.Logger
 	md      metadata.MD
+	uri     string
 	me      mino.Address
 	errs    chan error
 	pktFac  router.PacketFactory
@@ -139,6 +140,7 @@ func NewSession(
 	sess := &session{
 		log:     dela.Logger.With().Str("addr", me.String()).Logger(),
 		md:      md,
+		uri:     uriFromHeaders(md),
 		me:      me,
 		errs:    make(chan error, 1),
 		msgFac:  msgFac,
@@ -286,6 +288,9 @@ func (s *session) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
 			return
 		}
 
+		promMsgsSent.WithLabelValues(s.uri).Inc()
+		promBytesSent.WithLabelValues(s.uri).Add(float64(len(data)))
+
 		s.parentsLock.RLock()
 		parents := s.CopyParents()
 		s.parentsLock.RUnlock()
@@ -321,6 +326,9 @@ func (s *session) Recv(ctx context.Context) (mino.Address, serde.Message, error)
 		return nil, nil, io.EOF
 
 	case packet := <-s.queue.Channel():
+		promMsgsRecv.WithLabelValues(s.uri).Inc()
+		promBytesRecv.WithLabelValues(s.uri).Add(float64(len(packet.GetMessage())))
+
 		msg, err := s.msgFac.Deserialize(s.context, packet.GetMessage())
 		if err != nil {
 			return nil, nil, xerrors.Errorf("message: %v", err)
@@ -508,9 +516,13 @@ func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 					Stringer("to", addr).
 					Msg("relay closed unexpectedly")
 
+				promRelayFailures.Inc()
+
 				// Relay has lost the connection, therefore we announce the
 				// address as unreachable.
-				p.table.OnFailure(addr)
+				if p.table.OnFailure(addr) == nil {
+					promTableRebuilds.Inc()
+				}
 
 				return
 			}
@@ -525,12 +537,16 @@ func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 }
 
 func (s *session) onFailure(p parent, gateway mino.Address, pkt router.Packet, errs chan error) {
+	promRelayFailures.Inc()
+
 	err := p.table.OnFailure(gateway)
 	if err != nil {
 		errs <- xerrors.Errorf("no route to %v: %v", gateway, err)
 		return
 	}
 
+	promTableRebuilds.Inc()
+
 	// Retry to send the packet after the announcement of a link failure. This
 	// recursive call will eventually end by either a success, or a total
 	// failure to send the packet.
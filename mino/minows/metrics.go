@@ -0,0 +1,68 @@
+package minows
+
+import (
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.dedis.ch/dela"
+)
+
+// defines prometheus metrics
+var (
+	promMsgsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minows_messages_sent_total",
+		Help: "total number of messages sent",
+	}, []string{"rpc"})
+
+	promMsgsRecv = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minows_messages_received_total",
+		Help: "total number of messages received",
+	}, []string{"rpc"})
+
+	promBytesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minows_bytes_sent_total",
+		Help: "total number of payload bytes sent",
+	}, []string{"rpc"})
+
+	promBytesRecv = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dela_minows_bytes_received_total",
+		Help: "total number of payload bytes received",
+	}, []string{"rpc"})
+
+	promCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dela_minows_call_duration_seconds",
+		Help:    "latency of a unicast call to a participant",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"rpc"})
+
+	promStreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dela_minows_stream_duration_seconds",
+		Help:    "amount of time a stream stays open",
+		Buckets: []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"rpc"})
+
+	promConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dela_minows_connections",
+		Help: "number of open connections to distant peers",
+	})
+
+	promRelayFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dela_minows_relay_failures_total",
+		Help: "total number of packets the orchestrator failed to relay",
+	})
+)
+
+func init() {
+	dela.PromCollectors = append(dela.PromCollectors, promMsgsSent,
+		promMsgsRecv, promBytesSent, promBytesRecv, promCallDuration,
+		promStreamDuration, promConnections, promRelayFailures)
+}
+
+// connectionNotifiee keeps track of the number of open connections of a host.
+var connectionNotifiee = &network.NotifyBundle{
+	ConnectedF: func(network.Network, network.Conn) {
+		promConnections.Inc()
+	},
+	DisconnectedF: func(network.Network, network.Conn) {
+		promConnections.Dec()
+	},
+}
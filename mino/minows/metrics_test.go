@@ -0,0 +1,39 @@
+package minows
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/testing/fake"
+	"testing"
+)
+
+func Test_metrics_Call(t *testing.T) {
+	handler := &echoHandler{}
+	const addrInitiator = "/ip4/127.0.0.1/tcp/6001/ws"
+	initiator, stop := mustCreateMinows(t, addrInitiator, addrInitiator)
+	defer stop()
+	r := mustCreateRPC(t, initiator, "metrics", handler)
+
+	const addrPlayer = "/ip4/127.0.0.1/tcp/6002/ws"
+	player, stop := mustCreateMinows(t, addrPlayer, addrPlayer)
+	defer stop()
+	mustCreateRPC(t, player, "metrics", handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	responses, err := r.Call(ctx, fake.Message{}, mino.NewAddresses(player.GetAddress()))
+	require.NoError(t, err)
+	for range responses {
+	}
+
+	// Both the request and the reply go through the same RPC.
+	require.Equal(t, 2.0, testutil.ToFloat64(promMsgsSent.WithLabelValues("metrics")))
+	require.Equal(t, 2.0, testutil.ToFloat64(promMsgsRecv.WithLabelValues("metrics")))
+	require.Equal(t, 4.0, testutil.ToFloat64(promBytesSent.WithLabelValues("metrics")))
+	require.Equal(t, 4.0, testutil.ToFloat64(promBytesRecv.WithLabelValues("metrics")))
+	require.NotZero(t, testutil.CollectAndCount(promCallDuration))
+	require.Greater(t, testutil.ToFloat64(promConnections), 0.0)
+}
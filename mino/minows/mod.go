@@ -29,6 +29,18 @@ type minows struct {
 	segments []string
 	rpcs     map[string]any
 	factory  addressFactory
+	context  serde.Context
+}
+
+// Option is the type of the options to create a Minows instance.
+type Option func(*minows)
+
+// WithContext is an option to set the serde context of the messages, which is
+// the JSON one by default. All the participants must use the same format.
+func WithContext(ctx serde.Context) Option {
+	return func(m *minows) {
+		m.context = ctx
+	}
 }
 
 // NewMinows creates a new Minows instance that starts listening.
@@ -39,13 +51,15 @@ type minows struct {
 // `public` can be nil and will be determined
 // by the listening address and the port the host has bound to.
 // key: private key representing this mino instance's identity
-func NewMinows(listen, public ma.Multiaddr, key crypto.PrivKey) (mino.Mino,
+func NewMinows(listen, public ma.Multiaddr, key crypto.PrivKey, opts ...Option) (mino.Mino,
 	error) {
 	h, err := libp2p.New(libp2p.ListenAddrs(listen), libp2p.Identity(key))
 	if err != nil {
 		return nil, xerrors.Errorf("could not start host: %v", err)
 	}
 
+	h.Network().Notify(connectionNotifiee)
+
 	if public == nil {
 		public = h.Addrs()[0]
 	}
@@ -54,14 +68,21 @@ func NewMinows(listen, public ma.Multiaddr, key crypto.PrivKey) (mino.Mino,
 		return nil, xerrors.Errorf("could not create address: %v", err)
 	}
 
-	return &minows{
+	m := &minows{
 		logger:   dela.Logger.With().Str("mino", myAddr.String()).Logger(),
 		myAddr:   myAddr,
 		segments: nil,
 		host:     h,
 		rpcs:     make(map[string]any),
 		factory:  addressFactory{},
-	}, nil
+		context:  json.NewContext(),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
 }
 
 func (m *minows) GetAddressFactory() mino.AddressFactory {
@@ -84,6 +105,7 @@ func (m *minows) WithSegment(segment string) mino.Mino {
 		host:     m.host,
 		rpcs:     make(map[string]any),
 		factory:  addressFactory{},
+		context:  m.context,
 	}
 }
 
@@ -112,7 +134,7 @@ func (m *minows) CreateRPC(name string, h mino.Handler, f serde.Factory) (mino.R
 		handler: h,
 		mino:    m,
 		factory: f,
-		context: json.NewContext(),
+		context: m.context,
 	}
 
 	m.host.SetStreamHandler(protocol.ID(uri+pathCall), r.handleCall)
@@ -6,6 +6,8 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/testing/fake"
 	"testing"
 )
 
@@ -56,6 +58,26 @@ func TestNewMinows_OptionalPublic(t *testing.T) {
 	}
 }
 
+func TestNewMinows_WithContext(t *testing.T) {
+	listen := mustCreateMultiaddress(t, "/ip4/127.0.0.1/tcp/0/ws")
+	key := mustCreateKey(t)
+
+	ctx := fake.NewContextWithFormat(serde.FormatCBOR)
+
+	m, err := NewMinows(listen, nil, key, WithContext(ctx))
+	require.NoError(t, err)
+	require.Equal(t, serde.FormatCBOR, m.(*minows).context.GetFormat())
+
+	seg := m.WithSegment("test")
+	require.Equal(t, serde.FormatCBOR, seg.(*minows).context.GetFormat())
+
+	r, err := m.CreateRPC("test", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, serde.FormatCBOR, r.(*rpc).context.GetFormat())
+
+	require.NoError(t, m.(*minows).stop())
+}
+
 func Test_minows_GetAddressFactory(t *testing.T) {
 	const listen = "/ip4/0.0.0.0/tcp/7452"
 	const ws = "/ip4/127.0.0.1/tcp/7452/ws"
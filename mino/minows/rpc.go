@@ -17,6 +17,7 @@ import (
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
 	"sync"
+	"time"
 )
 
 const pathCall = "/call"
@@ -106,8 +107,12 @@ func (r rpc) Stream(ctx context.Context, players mino.Players) (mino.Sender, min
 		return nil, nil, xerrors.Errorf("could not start host: %v", err)
 	}
 
+	start := time.Now()
+
 	go func() {
 		<-ctx.Done()
+		promStreamDuration.WithLabelValues(r.uri).Observe(time.Since(start).Seconds())
+
 		err := initiator.Close()
 		if err != nil {
 			r.logger.Error().Err(err).Msg("could not close host")
@@ -192,6 +197,11 @@ func (r rpc) handleStream(stream network.Stream) {
 	p := r.createParticipant(stream)
 
 	go func() {
+		start := time.Now()
+		defer func() {
+			promStreamDuration.WithLabelValues(r.uri).Observe(time.Since(start).Seconds())
+		}()
+
 		err := r.handler.Stream(p, p)
 		if err != nil {
 			r.logger.Error().Err(err).Msg("could not handle stream")
@@ -222,6 +232,11 @@ func (r rpc) addPeers(addrs []address) {
 
 func (r rpc) unicast(ctx context.Context, dest address, req serde.Message) (
 	serde.Message, error) {
+	start := time.Now()
+	defer func() {
+		promCallDuration.WithLabelValues(r.uri).Observe(time.Since(start).Seconds())
+	}()
+
 	stream, err := r.openStream(ctx, dest, pathCall)
 	if err != nil {
 		return nil, xerrors.Errorf("could not open stream: %v", err)
@@ -279,6 +294,10 @@ func (r rpc) send(enc *gob.Encoder, msg serde.Message) error {
 	if err != nil {
 		return xerrors.Errorf("could not encode packet: %v", err)
 	}
+
+	promMsgsSent.WithLabelValues(r.uri).Inc()
+	promBytesSent.WithLabelValues(r.uri).Add(float64(len(payload)))
+
 	return nil
 }
 
@@ -292,6 +311,9 @@ func (r rpc) receive(dec *gob.Decoder) (ma.Multiaddr, serde.Message, error) {
 		return nil, nil, xerrors.Errorf("could not decode packet: %v", err)
 	}
 
+	promMsgsRecv.WithLabelValues(r.uri).Inc()
+	promBytesRecv.WithLabelValues(r.uri).Add(float64(len(pkt.Payload)))
+
 	from, err := ma.NewMultiaddrBytes(pkt.Source)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("could not unmarshal address: %v",
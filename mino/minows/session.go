@@ -71,6 +71,9 @@ func (m messageHandler) Recv(ctx context.Context) (mino.Address, serde.Message,
 		if origin == nil {
 			return nil, nil, xerrors.New("could not unmarshal address")
 		}
+		promMsgsRecv.WithLabelValues(m.rpc.uri).Inc()
+		promBytesRecv.WithLabelValues(m.rpc.uri).Add(float64(len(packet.Payload)))
+
 		msg, err := m.rpc.factory.Deserialize(m.rpc.context, packet.Payload)
 		if err != nil {
 			return origin, nil, xerrors.Errorf("could not deserialize message: %v", err)
@@ -170,6 +173,10 @@ func (m messageHandler) send(addr mino.Address, msg serde.Message) error {
 	if err != nil {
 		return xerrors.Errorf("could not encode packet: %v", err)
 	}
+
+	promMsgsSent.WithLabelValues(m.rpc.uri).Inc()
+	promBytesSent.WithLabelValues(m.rpc.uri).Add(float64(len(payload)))
+
 	return nil
 }
 
@@ -218,6 +225,8 @@ func (m messageHandler) listen(decoder *gob.Decoder) (packet, error) {
 		case address:
 			err := m.relay(pkt, to)
 			if err != nil {
+				promRelayFailures.Inc()
+
 				return packet{}, xerrors.Errorf("could not relay: %v", err)
 			}
 		}
@@ -10,6 +10,8 @@ import (
 func init() {
 	types.RegisterPacketFormat(serde.FormatJSON, packetFormat{})
 	types.RegisterHandshakeFormat(serde.FormatJSON, hsFormat{})
+	types.RegisterPacketFormat(serde.FormatCBOR, packetFormat{})
+	types.RegisterHandshakeFormat(serde.FormatCBOR, hsFormat{})
 }
 
 // PacketJSON describes a JSON formatted packet
@@ -0,0 +1,53 @@
+// Package canonical implements the canonical encoding, which is the
+// deterministic encoding of CBOR (RFC 8949) also used by the CBOR format of
+// serde.
+//
+// The fingerprints of the messages, which are the input of their digests and
+// therefore of their signatures, are written in the canonical encoding. A
+// fingerprint is an array of the fields of the message in a fixed order, and a
+// nested message is embedded as the byte string of its own fingerprint. A
+// client in another language can then reproduce the digests with any CBOR
+// library that supports the deterministic encoding.
+package canonical
+
+import (
+	"bytes"
+	"io"
+
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// Version is the version of the formats of the messages that are fingerprinted
+// with the canonical encoding. The messages of the previous versions keep the
+// fingerprints of their time.
+const Version uint32 = 2
+
+// Write writes the canonical encoding of the array of fields into the writer.
+func Write(w io.Writer, fields ...interface{}) error {
+	data, err := Marshal(fields)
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	return nil
+}
+
+// Fingerprint returns the fingerprint of the message, which is embedded as a
+// byte string in the fingerprint of its parent. An empty fingerprint is an
+// empty byte string and not a null.
+func Fingerprint(f serde.Fingerprinter) ([]byte, error) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := f.Fingerprint(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package canonical
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestWrite(t *testing.T) {
+	buffer := new(bytes.Buffer)
+
+	err := Write(buffer, uint64(1), []byte{2}, "A", true)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x84, 0x01, 0x41, 0x02, 0x61, 'A', 0xf5}, buffer.Bytes())
+
+	err = Write(buffer, make(chan int))
+	require.EqualError(t, err, "failed to encode: cbor: unsupported type 'chan int'")
+
+	err = Write(fake.NewBadHash(), uint64(1))
+	require.EqualError(t, err, fake.Err("failed to write"))
+}
+
+func TestFingerprint(t *testing.T) {
+	data, err := Fingerprint(fakeFingerprinter{data: []byte{1, 2}})
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, data)
+
+	data, err = Fingerprint(fakeFingerprinter{})
+	require.NoError(t, err)
+	require.NotNil(t, data)
+	require.Empty(t, data)
+
+	_, err = Fingerprint(fakeFingerprinter{err: fake.GetError()})
+	require.EqualError(t, err, fake.GetError().Error())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeFingerprinter struct {
+	data []byte
+	err  error
+}
+
+func (f fakeFingerprinter) Fingerprint(w io.Writer) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	_, err := w.Write(f.data)
+	return err
+}
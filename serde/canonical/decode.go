@@ -0,0 +1,462 @@
+package canonical
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+
+	"golang.org/x/xerrors"
+)
+
+// maxDepth is the maximum nesting of arrays and maps of a message, which
+// prevents a malicious message from exhausting the stack.
+const maxDepth = 256
+
+// Unmarshal populates the value pointed by v with the CBOR encoding. A null
+// resets the value to its zero value, and the unknown fields of a structure are
+// ignored.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return xerrors.Errorf("cbor: expected a non-nil pointer, got '%T'", v)
+	}
+
+	d := &decoder{data: data}
+
+	err := d.decode(rv.Elem(), 0)
+	if err != nil {
+		return xerrors.Errorf("cbor: %v", err)
+	}
+
+	if d.offset != len(d.data) {
+		return xerrors.Errorf("cbor: %d bytes left after the message", len(d.data)-d.offset)
+	}
+
+	return nil
+}
+
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *decoder) decode(v reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return xerrors.New("maximum depth reached")
+	}
+
+	if d.offset >= len(d.data) {
+		return xerrors.New("unexpected end of data")
+	}
+
+	if d.data[d.offset] == simpleNull {
+		d.offset++
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return d.decode(v.Elem(), depth+1)
+	case reflect.Bool:
+		return d.decodeBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return d.decodeInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return d.decodeUint(v)
+	case reflect.Float32, reflect.Float64:
+		return d.decodeFloat(v)
+	case reflect.String:
+		buf, err := d.readString(majorText)
+		if err != nil {
+			return err
+		}
+
+		v.SetString(string(buf))
+	case reflect.Slice, reflect.Array:
+		return d.decodeList(v, depth)
+	case reflect.Map:
+		return d.decodeMap(v, depth)
+	case reflect.Struct:
+		return d.decodeStruct(v, depth)
+	default:
+		return xerrors.Errorf("unsupported type '%v'", v.Type())
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeBool(v reflect.Value) error {
+	switch d.data[d.offset] {
+	case simpleTrue:
+		v.SetBool(true)
+	case simpleFalse:
+		v.SetBool(false)
+	default:
+		return xerrors.Errorf("expected a boolean for '%v'", v.Type())
+	}
+
+	d.offset++
+
+	return nil
+}
+
+func (d *decoder) decodeInt(v reflect.Value) error {
+	major, arg, err := d.readHeader()
+	if err != nil {
+		return err
+	}
+
+	if arg > math.MaxInt64 {
+		return xerrors.Errorf("integer overflows '%v'", v.Type())
+	}
+
+	var n int64
+
+	switch major {
+	case majorUnsigned:
+		n = int64(arg)
+	case majorNegative:
+		n = -1 - int64(arg)
+	default:
+		return xerrors.Errorf("expected an integer for '%v', got major type %d", v.Type(), major)
+	}
+
+	if v.OverflowInt(n) {
+		return xerrors.Errorf("integer overflows '%v'", v.Type())
+	}
+
+	v.SetInt(n)
+
+	return nil
+}
+
+func (d *decoder) decodeUint(v reflect.Value) error {
+	major, arg, err := d.readHeader()
+	if err != nil {
+		return err
+	}
+
+	if major != majorUnsigned {
+		return xerrors.Errorf("expected an unsigned integer for '%v', got major type %d",
+			v.Type(), major)
+	}
+
+	if v.OverflowUint(arg) {
+		return xerrors.Errorf("integer overflows '%v'", v.Type())
+	}
+
+	v.SetUint(arg)
+
+	return nil
+}
+
+func (d *decoder) decodeFloat(v reflect.Value) error {
+	var f float64
+
+	switch d.data[d.offset] {
+	case simpleFloat64:
+		buf, err := d.read(9)
+		if err != nil {
+			return err
+		}
+
+		f = math.Float64frombits(binary.BigEndian.Uint64(buf[1:]))
+	case simpleFloat32:
+		buf, err := d.read(5)
+		if err != nil {
+			return err
+		}
+
+		f = float64(math.Float32frombits(binary.BigEndian.Uint32(buf[1:])))
+	default:
+		return xerrors.Errorf("expected a float for '%v'", v.Type())
+	}
+
+	v.SetFloat(f)
+
+	return nil
+}
+
+// decodeList decodes a byte string into a list of bytes, and an array into any
+// other list.
+func (d *decoder) decodeList(v reflect.Value, depth int) error {
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		buf, err := d.readString(majorBytes)
+		if err != nil {
+			return err
+		}
+
+		if v.Kind() == reflect.Array {
+			if v.Len() != len(buf) {
+				return xerrors.Errorf("expected %d bytes, got %d", v.Len(), len(buf))
+			}
+
+			reflect.Copy(v, reflect.ValueOf(buf))
+
+			return nil
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(buf), len(buf))
+		reflect.Copy(slice, reflect.ValueOf(buf))
+		v.Set(slice)
+
+		return nil
+	}
+
+	n, err := d.readLength(majorArray)
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Array {
+		if v.Len() != n {
+			return xerrors.Errorf("expected %d elements, got %d", v.Len(), n)
+		}
+	} else {
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	}
+
+	for i := 0; i < n; i++ {
+		err = d.decode(v.Index(i), depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeMap(v reflect.Value, depth int) error {
+	n, err := d.readLength(majorMap)
+	if err != nil {
+		return err
+	}
+
+	m := reflect.MakeMapWithSize(v.Type(), n)
+
+	for i := 0; i < n; i++ {
+		key := reflect.New(v.Type().Key()).Elem()
+
+		err = d.decode(key, depth+1)
+		if err != nil {
+			return err
+		}
+
+		value := reflect.New(v.Type().Elem()).Elem()
+
+		err = d.decode(value, depth+1)
+		if err != nil {
+			return err
+		}
+
+		m.SetMapIndex(key, value)
+	}
+
+	v.Set(m)
+
+	return nil
+}
+
+func (d *decoder) decodeStruct(v reflect.Value, depth int) error {
+	n, err := d.readLength(majorMap)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string][]int)
+	for _, f := range fieldsOf(v.Type()) {
+		fields[f.name] = f.index
+	}
+
+	for i := 0; i < n; i++ {
+		name, err := d.readString(majorText)
+		if err != nil {
+			return err
+		}
+
+		index, found := fields[string(name)]
+		if !found {
+			err = d.skip(depth + 1)
+		} else {
+			err = d.decode(fieldByIndex(v, index), depth+1)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldByIndex returns the field of the structure at the index, and allocates
+// the nil embedded structures on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// skip moves over the next item.
+func (d *decoder) skip(depth int) error {
+	if depth > maxDepth {
+		return xerrors.New("maximum depth reached")
+	}
+
+	if d.offset >= len(d.data) {
+		return xerrors.New("unexpected end of data")
+	}
+
+	switch d.data[d.offset] {
+	case simpleFalse, simpleTrue, simpleNull:
+		d.offset++
+		return nil
+	case simpleFloat32:
+		_, err := d.read(5)
+		return err
+	case simpleFloat64:
+		_, err := d.read(9)
+		return err
+	}
+
+	major, arg, err := d.readHeader()
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case majorUnsigned, majorNegative:
+		return nil
+	case majorBytes, majorText:
+		if arg > uint64(len(d.data)-d.offset) {
+			return xerrors.New("unexpected end of data")
+		}
+
+		d.offset += int(arg)
+
+		return nil
+	case majorArray, majorMap:
+		items := arg
+		if major == majorMap {
+			items *= 2
+		}
+
+		if arg > uint64(len(d.data)-d.offset) {
+			return xerrors.New("unexpected end of data")
+		}
+
+		for i := uint64(0); i < items; i++ {
+			err = d.skip(depth + 1)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return xerrors.Errorf("unsupported major type %d", major)
+	}
+}
+
+// readString reads a byte or a text string of the major type.
+func (d *decoder) readString(major byte) ([]byte, error) {
+	n, err := d.readLength(major)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.read(n)
+}
+
+// readLength reads the header of a string, an array or a map of the major type
+// and returns its length, which cannot exceed the bytes left as every item
+// takes at least one byte.
+func (d *decoder) readLength(major byte) (int, error) {
+	m, arg, err := d.readHeader()
+	if err != nil {
+		return 0, err
+	}
+
+	if m != major {
+		return 0, xerrors.Errorf("expected major type %d, got %d", major, m)
+	}
+
+	if arg > uint64(len(d.data)-d.offset) {
+		return 0, xerrors.New("unexpected end of data")
+	}
+
+	return int(arg), nil
+}
+
+// readHeader reads the major type and the argument of the next item.
+func (d *decoder) readHeader() (byte, uint64, error) {
+	buf, err := d.read(1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	major := buf[0] >> 5
+	info := buf[0] & 0x1f
+
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		buf, err = d.read(1)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return major, uint64(buf[0]), nil
+	case info == 25:
+		buf, err = d.read(2)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return major, uint64(binary.BigEndian.Uint16(buf)), nil
+	case info == 26:
+		buf, err = d.read(4)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return major, uint64(binary.BigEndian.Uint32(buf)), nil
+	case info == 27:
+		buf, err = d.read(8)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return major, binary.BigEndian.Uint64(buf), nil
+	default:
+		return 0, 0, xerrors.Errorf("unsupported additional information %d", info)
+	}
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n > len(d.data)-d.offset {
+		return nil, xerrors.New("unexpected end of data")
+	}
+
+	buf := d.data[d.offset : d.offset+n]
+	d.offset += n
+
+	return buf, nil
+}
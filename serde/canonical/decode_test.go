@@ -0,0 +1,237 @@
+package canonical
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal_RoundTrip(t *testing.T) {
+	type inner struct {
+		Value []byte
+		Flag  bool
+	}
+
+	type message struct {
+		Int     int
+		Int8    int8
+		Uint16  uint16
+		Uint64  uint64
+		Float   float64
+		Text    string
+		Raw     json.RawMessage
+		Bytes   []byte
+		Fixed   [2]byte
+		Points  [][]byte
+		Inner   *inner `json:"in,omitempty"`
+		Inners  []inner
+		Args    map[string][]byte
+		Indices []int
+		Array   [2]int
+	}
+
+	msg := message{
+		Int:     -1000,
+		Int8:    math.MinInt8,
+		Uint16:  math.MaxUint16,
+		Uint64:  math.MaxUint64,
+		Float:   1.5,
+		Text:    "dela",
+		Raw:     json.RawMessage{0xa0},
+		Bytes:   []byte{},
+		Fixed:   [2]byte{1, 2},
+		Points:  [][]byte{{1}, nil, {}},
+		Inner:   &inner{Value: []byte("value"), Flag: true},
+		Inners:  []inner{{}, {Flag: true}},
+		Args:    map[string][]byte{"a": {1}, "b": nil},
+		Indices: []int{0, 1, 2},
+		Array:   [2]int{3, 4},
+	}
+
+	data, err := Marshal(msg)
+	require.NoError(t, err)
+
+	var decoded message
+	err = Unmarshal(data, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	var empty message
+	data, err = Marshal(empty)
+	require.NoError(t, err)
+
+	decoded = message{}
+	err = Unmarshal(data, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, empty, decoded)
+}
+
+func TestUnmarshal_Null(t *testing.T) {
+	value := &struct{ A int }{A: 1}
+
+	err := Unmarshal([]byte{simpleNull}, &value)
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	list := []int{1}
+
+	err = Unmarshal([]byte{simpleNull}, &list)
+	require.NoError(t, err)
+	require.Nil(t, list)
+}
+
+func TestUnmarshal_UnknownFields(t *testing.T) {
+	type v2 struct {
+		A int
+		B []interface{}
+		C map[string]interface{}
+		D float32
+		E bool
+		F *int
+		G []byte
+		H int
+	}
+
+	data, err := Marshal(v2{
+		A: 1,
+		B: []interface{}{1, "a", []int{2}},
+		C: map[string]interface{}{"d": 1.5},
+		D: 1,
+		G: []byte{1},
+		H: -1,
+	})
+	require.NoError(t, err)
+
+	var v1 struct{ A int }
+	err = Unmarshal(data, &v1)
+	require.NoError(t, err)
+	require.Equal(t, 1, v1.A)
+
+	// Half precision floats are not used by the encoder.
+	err = Unmarshal([]byte{0xa2, 0x61, 'A', 0x01, 0x61, 'B', 0xf9, 0x00, 0x00}, &v1)
+	require.EqualError(t, err, "cbor: unsupported major type 7")
+}
+
+func TestUnmarshal_Embedded(t *testing.T) {
+	type Algorithm struct {
+		Name string
+	}
+
+	type key struct {
+		*Algorithm
+		Data []byte
+	}
+
+	data, err := Marshal(key{Algorithm: &Algorithm{Name: "A"}, Data: []byte{1}})
+	require.NoError(t, err)
+
+	var m key
+	err = Unmarshal(data, &m)
+	require.NoError(t, err)
+	require.Equal(t, key{Algorithm: &Algorithm{Name: "A"}, Data: []byte{1}}, m)
+}
+
+func TestUnmarshal_Float32(t *testing.T) {
+	var f float64
+
+	err := Unmarshal([]byte{0xfa, 0x3f, 0xc0, 0x00, 0x00}, &f)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+}
+
+func TestUnmarshal_Failures(t *testing.T) {
+	var i int
+	var i8 int8
+	var u uint
+	var u8 uint8
+	var b bool
+	var f float64
+	var s string
+	var buf []byte
+	var fixed [2]byte
+	var list []int
+	var array [2]int
+	var m map[string]int
+	var st struct{ A int }
+	var iface interface{}
+
+	failures := []struct {
+		data     string
+		value    interface{}
+		expected string
+	}{
+		{"", &i, "unexpected end of data"},
+		{"00", i, "expected a non-nil pointer, got 'int'"},
+		{"0001", &i, "1 bytes left after the message"},
+		{"40", &i, "expected an integer for 'int', got major type 2"},
+		{"1bffffffffffffffff", &i, "integer overflows 'int'"},
+		{"190100", &i8, "integer overflows 'int8'"},
+		{"20", &u, "expected an unsigned integer for 'uint', got major type 1"},
+		{"190100", &u8, "integer overflows 'uint8'"},
+		{"00", &b, "expected a boolean for 'bool'"},
+		{"00", &f, "expected a float for 'float64'"},
+		{"fb00", &f, "unexpected end of data"},
+		{"fa00", &f, "unexpected end of data"},
+		{"40", &s, "expected major type 3, got 2"},
+		{"6461", &s, "unexpected end of data"},
+		{"60", &buf, "expected major type 2, got 3"},
+		{"4101", &fixed, "expected 2 bytes, got 1"},
+		{"40", &list, "expected major type 4, got 2"},
+		{"8140", &list, "expected an integer for 'int', got major type 2"},
+		{"8101", &array, "expected 2 elements, got 1"},
+		{"a1", &m, "unexpected end of data"},
+		{"a10000", &m, "expected major type 3, got 0"},
+		{"a1616140", &m, "expected an integer for 'int', got major type 2"},
+		{"80", &st, "expected major type 5, got 4"},
+		{"a10101", &st, "expected major type 3, got 0"},
+		{"a1614140", &st, "expected an integer for 'int', got major type 2"},
+		{"a161426461", &st, "unexpected end of data"},
+		{"a1614281", &st, "unexpected end of data"},
+		{"a16142", &st, "unexpected end of data"},
+		{"a16142ff", &st, "unsupported additional information 31"},
+		{"a16142fb00", &st, "unexpected end of data"},
+		{"a16142fa00", &st, "unexpected end of data"},
+		{"a16142e0", &st, "unsupported major type 7"},
+		{"18", &i, "unexpected end of data"},
+		{"19", &i, "unexpected end of data"},
+		{"1a", &i, "unexpected end of data"},
+		{"1b", &i, "unexpected end of data"},
+		{"a0", &iface, "unsupported type 'interface {}'"},
+	}
+
+	for _, failure := range failures {
+		data, err := hex.DecodeString(failure.data)
+		require.NoError(t, err)
+
+		err = Unmarshal(data, failure.value)
+		require.EqualError(t, err, "cbor: "+failure.expected, failure.data)
+	}
+}
+
+func TestUnmarshal_MaxDepth(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+
+	data := []byte{}
+	for i := 0; i < maxDepth+1; i++ {
+		data = append(data, 0xa1, 0x64, 'N', 'e', 'x', 't')
+	}
+
+	var n node
+	err := Unmarshal(append(data, 0xa0), &n)
+	require.EqualError(t, err, "cbor: maximum depth reached")
+
+	// The unknown fields are limited as well.
+	data = []byte{0xa1, 0x61, 'B'}
+	for i := 0; i < maxDepth+1; i++ {
+		data = append(data, 0x81)
+	}
+
+	var st struct{ A int }
+	err = Unmarshal(append(data, 0x00), &st)
+	require.EqualError(t, err, "cbor: maximum depth reached")
+}
@@ -0,0 +1,372 @@
+package canonical
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Major types of CBOR.
+const (
+	majorUnsigned byte = 0
+	majorNegative byte = 1
+	majorBytes    byte = 2
+	majorText     byte = 3
+	majorArray    byte = 4
+	majorMap      byte = 5
+	majorSimple   byte = 7
+)
+
+// Simple values and floats of the major type 7.
+const (
+	simpleFalse   byte = 0xf4
+	simpleTrue    byte = 0xf5
+	simpleNull    byte = 0xf6
+	simpleFloat32 byte = 0xfa
+	simpleFloat64 byte = 0xfb
+)
+
+// Marshal returns the deterministic CBOR encoding of the value. The structures
+// are encoded as maps of their exported fields, which are named after their
+// `json` tag when it is set, so that the definitions of the JSON format can be
+// shared. The nil pointers, slices and maps are encoded as null.
+func Marshal(v interface{}) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+
+	err := encode(buffer, reflect.ValueOf(v))
+	if err != nil {
+		return nil, xerrors.Errorf("cbor: %v", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func encode(buffer *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buffer.WriteByte(simpleNull)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			buffer.WriteByte(simpleTrue)
+		} else {
+			buffer.WriteByte(simpleFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if n < 0 {
+			writeHeader(buffer, majorNegative, uint64(-(n + 1)))
+		} else {
+			writeHeader(buffer, majorUnsigned, uint64(n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		writeHeader(buffer, majorUnsigned, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		var buf [9]byte
+		buf[0] = simpleFloat64
+		binary.BigEndian.PutUint64(buf[1:], math.Float64bits(v.Float()))
+		buffer.Write(buf[:])
+	case reflect.String:
+		writeHeader(buffer, majorText, uint64(v.Len()))
+		buffer.WriteString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			buffer.WriteByte(simpleNull)
+			return nil
+		}
+
+		return encodeList(buffer, v)
+	case reflect.Array:
+		return encodeList(buffer, v)
+	case reflect.Map:
+		if v.IsNil() {
+			buffer.WriteByte(simpleNull)
+			return nil
+		}
+
+		return encodeMap(buffer, v)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buffer.WriteByte(simpleNull)
+			return nil
+		}
+
+		return encode(buffer, v.Elem())
+	case reflect.Struct:
+		return encodeStruct(buffer, v)
+	default:
+		return xerrors.Errorf("unsupported type '%v'", v.Type())
+	}
+
+	return nil
+}
+
+// encodeList encodes a list of bytes as a byte string, and any other list as an
+// array.
+func encodeList(buffer *bytes.Buffer, v reflect.Value) error {
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		writeHeader(buffer, majorBytes, uint64(v.Len()))
+
+		for i := 0; i < v.Len(); i++ {
+			buffer.WriteByte(byte(v.Index(i).Uint()))
+		}
+
+		return nil
+	}
+
+	writeHeader(buffer, majorArray, uint64(v.Len()))
+
+	for i := 0; i < v.Len(); i++ {
+		err := encode(buffer, v.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeMap encodes the entries of the map sorted by the encoding of their
+// key.
+func encodeMap(buffer *bytes.Buffer, v reflect.Value) error {
+	entries := make([]entry, 0, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key := new(bytes.Buffer)
+
+		err := encode(key, iter.Key())
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry{key: key.Bytes(), value: iter.Value()})
+	}
+
+	return writeEntries(buffer, entries)
+}
+
+// encodeStruct encodes the structure as a map of its fields sorted by the
+// encoding of their name.
+func encodeStruct(buffer *bytes.Buffer, v reflect.Value) error {
+	fields := fieldsOf(v.Type())
+
+	entries := make([]entry, 0, len(fields))
+
+	for _, f := range fields {
+		value, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			// The field belongs to a nil embedded structure.
+			continue
+		}
+
+		if f.omitEmpty && value.IsZero() {
+			continue
+		}
+
+		entries = append(entries, entry{key: f.key, value: value})
+	}
+
+	return writeEntries(buffer, entries)
+}
+
+type entry struct {
+	key   []byte
+	value reflect.Value
+}
+
+func writeEntries(buffer *bytes.Buffer, entries []entry) error {
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	writeHeader(buffer, majorMap, uint64(len(entries)))
+
+	for _, e := range entries {
+		buffer.Write(e.key)
+
+		err := encode(buffer, e.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHeader writes the major type and the argument in the shortest form.
+func writeHeader(buffer *bytes.Buffer, major byte, arg uint64) {
+	major <<= 5
+
+	switch {
+	case arg < 24:
+		buffer.WriteByte(major | byte(arg))
+	case arg <= math.MaxUint8:
+		buffer.Write([]byte{major | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		var buf [3]byte
+		buf[0] = major | 25
+		binary.BigEndian.PutUint16(buf[1:], uint16(arg))
+		buffer.Write(buf[:])
+	case arg <= math.MaxUint32:
+		var buf [5]byte
+		buf[0] = major | 26
+		binary.BigEndian.PutUint32(buf[1:], uint32(arg))
+		buffer.Write(buf[:])
+	default:
+		var buf [9]byte
+		buf[0] = major | 27
+		binary.BigEndian.PutUint64(buf[1:], arg)
+		buffer.Write(buf[:])
+	}
+}
+
+// field is the definition of a field of a structure, which can be promoted
+// from an embedded structure.
+type field struct {
+	name      string
+	key       []byte
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+var fieldCache sync.Map
+
+// fieldsOf returns the fields of the structure type. As in the JSON format,
+// the fields of the embedded structures are promoted, and a field hides the
+// deeper ones of the same name.
+func fieldsOf(t reflect.Type) []field {
+	cached, found := fieldCache.Load(t)
+	if found {
+		return cached.([]field)
+	}
+
+	byName := make(map[string][]field)
+	names := []string{}
+
+	for _, f := range collectFields(t, nil) {
+		if byName[f.name] == nil {
+			names = append(names, f.name)
+		}
+
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	fields := []field{}
+
+	for _, name := range names {
+		f, found := dominantField(byName[name])
+		if found {
+			fields = append(fields, f)
+		}
+	}
+
+	fieldCache.Store(t, fields)
+
+	return fields
+}
+
+// collectFields returns the fields of the structure type, including the ones
+// of its embedded structures.
+func collectFields(t reflect.Type, parent []int) []field {
+	fields := []field{}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		embedded := sf.Anonymous && ft.Kind() == reflect.Struct
+
+		if !sf.IsExported() && !embedded {
+			continue
+		}
+
+		tag, found := sf.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+
+		index := append(append([]int{}, parent...), i)
+
+		if embedded && parts[0] == "" && len(index) <= maxDepth {
+			fields = append(fields, collectFields(ft, index)...)
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		f := field{name: sf.Name, index: index, tagged: found && parts[0] != ""}
+
+		if f.tagged {
+			f.name = parts[0]
+		}
+
+		for _, opt := range parts[1:] {
+			f.omitEmpty = f.omitEmpty || opt == "omitempty"
+		}
+
+		key := new(bytes.Buffer)
+		writeHeader(key, majorText, uint64(len(f.name)))
+		key.WriteString(f.name)
+
+		f.key = key.Bytes()
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// dominantField returns the field that wins among the ones of the same name:
+// the shallowest one, or the only tagged one at that depth. It returns false
+// when the name is ambiguous, which hides the field.
+func dominantField(fields []field) (field, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+
+	candidates := []field{}
+	for _, f := range fields {
+		if len(f.index) == depth {
+			candidates = append(candidates, f)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	tagged := []field{}
+	for _, f := range candidates {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+
+	return field{}, false
+}
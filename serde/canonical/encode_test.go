@@ -0,0 +1,167 @@
+package canonical
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal_Vectors(t *testing.T) {
+	// Examples of the appendix A of RFC 8949.
+	vectors := []struct {
+		value    interface{}
+		expected string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{1.1, "fb3ff199999999999a"},
+		{"", "60"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]byte{}, "40"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]int{}, "80"},
+		{[]int{1, 2, 3}, "83010203"},
+		{[]interface{}{1, []int{2, 3}, []int{4, 5}}, "8301820203820405"},
+		{map[string]int{}, "a0"},
+		{map[int]int{1: 2, 3: 4}, "a201020304"},
+		{map[string]interface{}{"a": 1, "b": []int{2, 3}}, "a26161016162820203"},
+	}
+
+	for _, vector := range vectors {
+		data, err := Marshal(vector.value)
+		require.NoError(t, err)
+		require.Equal(t, vector.expected, hex.EncodeToString(data), "%v", vector.value)
+	}
+}
+
+func TestMarshal_Deterministic(t *testing.T) {
+	// The keys are sorted by their encoding, so the shorter ones first.
+	data, err := Marshal(map[string]int{"bb": 1, "a": 2, "c": 3})
+	require.NoError(t, err)
+	require.Equal(t, "a3"+"616102"+"616303"+"62626201", hex.EncodeToString(data))
+
+	type message struct {
+		Zeta  int
+		Alpha int `json:"a"`
+	}
+
+	data, err = Marshal(message{Zeta: 1, Alpha: 2})
+	require.NoError(t, err)
+	require.Equal(t, "a2616102645a65746101", hex.EncodeToString(data))
+}
+
+func TestMarshal_Struct(t *testing.T) {
+	type inner struct {
+		Value []byte
+	}
+
+	type message struct {
+		Raw     json.RawMessage
+		Inner   *inner `json:",omitempty"`
+		Skipped string `json:"-"`
+		List    []inner
+		private int
+	}
+
+	data, err := Marshal(message{Skipped: "a", private: 1})
+	require.NoError(t, err)
+	require.Equal(t, "a2"+"63526177f6"+"644c697374f6", hex.EncodeToString(data))
+
+	data, err = Marshal(&message{Inner: &inner{Value: []byte{1}}})
+	require.NoError(t, err)
+	require.Equal(t, "a3"+"63526177f6"+"644c697374f6"+"65496e6e6572a16556616c75654101",
+		hex.EncodeToString(data))
+}
+
+func TestMarshal_Embedded(t *testing.T) {
+	type Algorithm struct {
+		Name string
+	}
+
+	type Named struct {
+		Name string
+	}
+
+	type Tagged struct {
+		Name string `json:"Name"`
+	}
+
+	type key struct {
+		Algorithm
+		Data []byte
+	}
+
+	data, err := Marshal(key{Algorithm: Algorithm{Name: "A"}, Data: []byte{1}})
+	require.NoError(t, err)
+	require.Equal(t, "a2"+"6444617461"+"4101"+"644e616d65"+"6141", hex.EncodeToString(data))
+
+	// The field of the structure hides the promoted one.
+	type shallow struct {
+		Algorithm
+		Name string
+	}
+
+	data, err = Marshal(shallow{Algorithm: Algorithm{Name: "A"}, Name: "B"})
+	require.NoError(t, err)
+	require.Equal(t, "a1"+"644e616d65"+"6142", hex.EncodeToString(data))
+
+	// The promoted fields at the same depth hide each other unless only one
+	// of them is tagged.
+	type ambiguous struct {
+		Algorithm
+		Named
+	}
+
+	data, err = Marshal(ambiguous{})
+	require.NoError(t, err)
+	require.Equal(t, "a0", hex.EncodeToString(data))
+
+	type tagged struct {
+		Algorithm
+		Tagged
+	}
+
+	data, err = Marshal(tagged{Algorithm: Algorithm{Name: "A"}, Tagged: Tagged{Name: "B"}})
+	require.NoError(t, err)
+	require.Equal(t, "a1"+"644e616d65"+"6142", hex.EncodeToString(data))
+
+	// The fields of a nil embedded structure are omitted.
+	type pointer struct {
+		*Algorithm
+		Data []byte
+	}
+
+	data, err = Marshal(pointer{})
+	require.NoError(t, err)
+	require.Equal(t, "a1"+"6444617461"+"f6", hex.EncodeToString(data))
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	_, err := Marshal(func() {})
+	require.EqualError(t, err, "cbor: unsupported type 'func()'")
+
+	_, err = Marshal([]interface{}{make(chan int)})
+	require.EqualError(t, err, "cbor: unsupported type 'chan int'")
+
+	_, err = Marshal(map[string]interface{}{"a": make(chan int)})
+	require.EqualError(t, err, "cbor: unsupported type 'chan int'")
+
+	_, err = Marshal(map[interface{}]int{make(chan int): 1})
+	require.EqualError(t, err, "cbor: unsupported type 'chan int'")
+}
@@ -0,0 +1,57 @@
+// Package cbor implements the context engine for the CBOR format (RFC 8949).
+//
+// The message definitions of the JSON format only rely on the context to
+// marshal their fields, which makes them available in CBOR as well. The bytes
+// are stored as such instead of the base64 strings of JSON, and the messages
+// are encoded deterministically: the integers and the lengths take the
+// shortest form, and the keys of the maps and the fields of the structures are
+// sorted by their encoding.
+package cbor
+
+import (
+	// Static registration of the CBOR formats. By having them here, it ensures
+	// that an import of the CBOR context engine will import the definitions.
+	_ "go.dedis.ch/dela/core/access/darc/json"
+	_ "go.dedis.ch/dela/core/ordering/cosipbft/authority/json"
+	_ "go.dedis.ch/dela/core/ordering/cosipbft/blocksync/json"
+	_ "go.dedis.ch/dela/core/ordering/cosipbft/fastsync/json"
+	_ "go.dedis.ch/dela/core/ordering/cosipbft/json"
+	_ "go.dedis.ch/dela/core/txn/signed/json"
+	_ "go.dedis.ch/dela/core/validation/simple/json"
+	_ "go.dedis.ch/dela/cosi/json"
+	_ "go.dedis.ch/dela/cosi/threshold/json"
+	_ "go.dedis.ch/dela/crypto/bls/json"
+	_ "go.dedis.ch/dela/crypto/bls12381/json"
+	_ "go.dedis.ch/dela/crypto/ed25519/json"
+	_ "go.dedis.ch/dela/dkg/pedersen/json"
+	_ "go.dedis.ch/dela/mino/router/tree/json"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
+)
+
+// cborEngine is a context engine to marshal and unmarshal in CBOR format.
+//
+// - implements serde.ContextEngine
+type cborEngine struct{}
+
+// NewContext returns a CBOR context.
+func NewContext() serde.Context {
+	return serde.NewContext(cborEngine{})
+}
+
+// GetFormat implements serde.ContextEngine. It returns the CBOR format name.
+func (cborEngine) GetFormat() serde.Format {
+	return serde.FormatCBOR
+}
+
+// Marshal implements serde.ContextEngine. It returns the bytes of the message
+// marshaled in CBOR format.
+func (cborEngine) Marshal(m interface{}) ([]byte, error) {
+	return canonical.Marshal(m)
+}
+
+// Unmarshal implements serde.ContextEngine. It populates the message using the
+// CBOR format definition.
+func (cborEngine) Unmarshal(data []byte, m interface{}) error {
+	return canonical.Unmarshal(data, m)
+}
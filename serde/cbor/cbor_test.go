@@ -0,0 +1,36 @@
+package cbor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestCBOREngine_GetFormat(t *testing.T) {
+	ctx := NewContext()
+	require.Equal(t, serde.FormatCBOR, ctx.GetFormat())
+}
+
+func TestCBOREngine_Marshal(t *testing.T) {
+	ctx := NewContext()
+
+	data, err := ctx.Marshal(struct{ A string }{A: "B"})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xa1, 0x61, 'A', 0x61, 'B'}, data)
+
+	_, err = ctx.Marshal(make(chan int))
+	require.EqualError(t, err, "cbor: unsupported type 'chan int'")
+}
+
+func TestCBOREngine_Unmarshal(t *testing.T) {
+	ctx := NewContext()
+
+	var m struct{ A string }
+	err := ctx.Unmarshal([]byte{0xa1, 0x61, 'A', 0x61, 'B'}, &m)
+	require.NoError(t, err)
+	require.Equal(t, "B", m.A)
+
+	err = ctx.Unmarshal(nil, &m)
+	require.EqualError(t, err, "cbor: unexpected end of data")
+}
@@ -0,0 +1,220 @@
+package cbor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access/darc/types"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	blocksync "go.dedis.ch/dela/core/ordering/cosipbft/blocksync/types"
+	fastsync "go.dedis.ch/dela/core/ordering/cosipbft/fastsync/types"
+	otypes "go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/cosi"
+	ttypes "go.dedis.ch/dela/cosi/threshold/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls12381"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/crypto/ed25519"
+	ptypes "go.dedis.ch/dela/dkg/pedersen/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minoch"
+	rtypes "go.dedis.ch/dela/mino/router/tree/types"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/suites"
+)
+
+// TestFormats_RoundTrip makes sure that a message of every family survives a
+// trip through the CBOR format, by comparing its JSON encoding before and
+// after, and the other way around.
+func TestFormats_RoundTrip(t *testing.T) {
+	signer := bls.NewSigner()
+	edSigner := ed25519.NewSigner()
+	suite := suites.MustFind("Ed25519")
+
+	addrFac := minoch.AddressFactory{}
+	addrs := []mino.Address{addrFac.FromText([]byte("A")), addrFac.FromText([]byte("B"))}
+	pubkeys := []crypto.PublicKey{signer.GetPublicKey(), bls.NewSigner().GetPublicKey()}
+
+	sig, err := signer.Sign([]byte("ping"))
+	require.NoError(t, err)
+
+	edSig, err := edSigner.Sign([]byte("ping"))
+	require.NoError(t, err)
+
+	signer12381 := bls12381.NewSigner()
+
+	sig12381, err := signer12381.Sign([]byte("ping"))
+	require.NoError(t, err)
+
+	commonSigner := common.NewSigner("BLS", signer)
+
+	tx, err := signed.NewTransaction(1, signer.GetPublicKey(), signed.WithArg("key", []byte("value")))
+	require.NoError(t, err)
+	require.NoError(t, tx.Sign(signer))
+
+	txFac := signed.NewTransactionFactory()
+	resultFac := simple.NewResultFactory(txFac)
+	result := simple.NewResult([]simple.TransactionResult{
+		simple.NewTransactionResult(tx, true, ""),
+		simple.NewTransactionResult(tx, false, "oops"),
+	})
+
+	roster := authority.New(addrs, pubkeys)
+	rosterFac := authority.NewFactory(addrFac, bls.NewPublicKeyFactory())
+
+	changeset := authority.NewChangeSet()
+	changeset.Remove(1)
+	changeset.Add(addrFac.FromText([]byte("C")), signer.GetPublicKey())
+	csFac := authority.NewChangeSetFactory(addrFac, bls.NewPublicKeyFactory())
+
+	genesis, err := otypes.NewGenesis(roster, otypes.WithGenesisRoot(otypes.Digest{1}))
+	require.NoError(t, err)
+
+	block, err := otypes.NewBlock(result, otypes.WithIndex(1), otypes.WithTreeRoot(otypes.Digest{2}))
+	require.NoError(t, err)
+
+	blockFac := otypes.NewBlockFactory(resultFac)
+	linkFac := otypes.NewLinkFactory(blockFac, bls.NewSignatureFactory(), csFac)
+
+	link, err := otypes.NewBlockLink(otypes.Digest{3}, block,
+		otypes.WithSignatures(sig, sig), otypes.WithChangeSet(changeset))
+	require.NoError(t, err)
+
+	prev, err := otypes.NewForwardLink(otypes.Digest{4}, otypes.Digest{3},
+		otypes.WithSignatures(sig, sig))
+	require.NoError(t, err)
+
+	view := otypes.NewViewMessage(otypes.Digest{5}, 1, sig)
+
+	pbftFac := otypes.NewMessageFactory(otypes.NewGenesisFactory(rosterFac), blockFac,
+		addrFac, bls.NewSignatureFactory(), csFac)
+
+	point := suite.Point().Pick(suite.RandomStream())
+	scalar := suite.Scalar().Pick(suite.RandomStream())
+
+	testCases := []struct {
+		name string
+		msg  serde.Message
+		fac  serde.Factory
+	}{
+		{"bls public key", signer.GetPublicKey(), bls.NewPublicKeyFactory()},
+		{"bls signature", sig, bls.NewSignatureFactory()},
+		{"bls12381 public key", signer12381.GetPublicKey(), bls12381.NewPublicKeyFactory()},
+		{"bls12381 signature", sig12381, bls12381.NewSignatureFactory()},
+		{"ed25519 public key", edSigner.GetPublicKey(), ed25519.NewPublicKeyFactory()},
+		{"ed25519 signature", edSig, ed25519.NewSignatureFactory()},
+		{"common public key", commonSigner.GetPublicKey(), common.NewPublicKeyFactory()},
+		{"transaction", tx, txFac},
+		{"result", result, resultFac},
+		{
+			"permission",
+			types.NewPermission(types.WithRule("rule", signer.GetPublicKey())),
+			types.NewFactory(),
+		},
+		{"roster", roster, rosterFac},
+		{"change set", changeset, csFac},
+		{"genesis", genesis, otypes.NewGenesisFactory(rosterFac)},
+		{"block", block, blockFac},
+		{"block link", link, linkFac},
+		{"chain", otypes.NewChain(link, []otypes.Link{prev}), otypes.NewChainFactory(linkFac)},
+		{"genesis message", otypes.NewGenesisMessage(genesis), pbftFac},
+		{
+			"block message",
+			otypes.NewBlockMessage(block, map[mino.Address]otypes.ViewMessage{addrs[0]: view}),
+			pbftFac,
+		},
+		{"commit message", otypes.NewCommit(otypes.Digest{6}, sig), pbftFac},
+		{"done message", otypes.NewDone(otypes.Digest{7}, sig), pbftFac},
+		{"view message", view, pbftFac},
+		{
+			"sync message",
+			blocksync.NewSyncMessage(otypes.NewChain(link, nil)),
+			blocksync.NewMessageFactory(linkFac, otypes.NewChainFactory(linkFac)),
+		},
+		{
+			"sync reply",
+			blocksync.NewSyncReply(link),
+			blocksync.NewMessageFactory(linkFac, otypes.NewChainFactory(linkFac)),
+		},
+		{
+			"catchup message",
+			fastsync.NewCatchupMessage(true, []otypes.BlockLink{link}),
+			fastsync.NewMessageFactory(linkFac),
+		},
+		{
+			"cosi request",
+			cosi.SignatureRequest{Value: signer.GetPublicKey()},
+			cosi.NewMessageFactory(bls.NewPublicKeyFactory(), bls.NewSignatureFactory()),
+		},
+		{
+			"threshold request",
+			ttypes.NewAggregateRequest(signer.GetPublicKey(), addrs, pubkeys, 3, time.Second),
+			ttypes.NewMessageFactory(nil, bls.NewPublicKeyFactory(), addrFac,
+				bls.NewPublicKeyFactory(), bls.NewSignatureFactory()),
+		},
+		{
+			"threshold signature",
+			ttypes.NewSignature(sig, []byte{0b101}),
+			ttypes.NewSignatureFactory(bls.NewSignatureFactory()),
+		},
+		{
+			"tree packet",
+			rtypes.NewPacket(addrs[0], []byte("ping"), addrs[1]),
+			rtypes.NewPacketFactory(addrFac),
+		},
+		{
+			"tree handshake",
+			rtypes.NewHandshake(3, addrs...),
+			rtypes.NewHandshakeFactory(addrFac),
+		},
+		{
+			"dkg start",
+			ptypes.NewStart(2, addrs, []kyber.Point{point, point}),
+			ptypes.NewMessageFactory(addrFac),
+		},
+		{
+			"dkg deal",
+			ptypes.NewDeal(1, []byte{1}, ptypes.NewEncryptedDeal([]byte{2}, []byte{3},
+				[]byte{4}, []byte{5})),
+			ptypes.NewMessageFactory(addrFac),
+		},
+		{
+			"dkg decrypt reply",
+			ptypes.NewDecryptReply(1, point, scalar, scalar),
+			ptypes.NewMessageFactory(addrFac),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonCtx := json.NewContext()
+			cborCtx := NewContext()
+
+			jsonData, err := tc.msg.Serialize(jsonCtx)
+			require.NoError(t, err)
+
+			cborData, err := tc.msg.Serialize(cborCtx)
+			require.NoError(t, err)
+
+			fromCBOR, err := tc.fac.Deserialize(cborCtx, cborData)
+			require.NoError(t, err)
+
+			data, err := fromCBOR.Serialize(jsonCtx)
+			require.NoError(t, err)
+			require.Equal(t, string(jsonData), string(data))
+
+			fromJSON, err := tc.fac.Deserialize(jsonCtx, jsonData)
+			require.NoError(t, err)
+
+			data, err = fromJSON.Serialize(cborCtx)
+			require.NoError(t, err)
+			require.Equal(t, cborData, data)
+		})
+	}
+}
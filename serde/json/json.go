@@ -18,6 +18,7 @@ import (
 	_ "go.dedis.ch/dela/cosi/json"
 	_ "go.dedis.ch/dela/cosi/threshold/json"
 	_ "go.dedis.ch/dela/crypto/bls/json"
+	_ "go.dedis.ch/dela/crypto/bls12381/json"
 	_ "go.dedis.ch/dela/crypto/ed25519/json"
 	_ "go.dedis.ch/dela/dkg/pedersen/json"
 	_ "go.dedis.ch/dela/mino/router/tree/json"
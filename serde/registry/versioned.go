@@ -0,0 +1,205 @@
+// This file contains the implementation of a versioned format.
+
+package registry
+
+import (
+	"encoding/json"
+	"sort"
+
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// LegacyVersion is the version of the data encoded before the versioned
+// envelope, which is decoded by the engine of this version.
+const LegacyVersion uint32 = 1
+
+// Envelope is the message that wraps the data of a versioned format. It
+// identifies the type of the message and the version of its definition so that
+// a node can decode the data of an older version.
+type Envelope struct {
+	Type    string
+	Version uint32
+	// Data is the message encoded by the engine of the version. It is raw
+	// JSON in the JSON format, and a byte string in the binary ones.
+	Data json.RawMessage
+}
+
+// VersionedMessage is implemented by the messages that are bound to a version
+// of their format, for instance because their digest depends on it.
+type VersionedMessage interface {
+	// GetFormatVersion returns the version of the format of the message, or
+	// zero when it is not bound to a version.
+	GetFormatVersion() uint32
+}
+
+// FingerprintVersioner is implemented by the hash factories that fix the
+// version of the fingerprints of the messages, like the one of a chain, so that
+// the digests follow the version of the chain rather than the version of the
+// encoding.
+type FingerprintVersioner interface {
+	// GetFingerprintVersion returns the version of the fingerprints, or zero
+	// when it is not known.
+	GetFingerprintVersion() uint32
+}
+
+// FingerprintVersionOf returns the version of the fingerprints fixed by the
+// hash factory, or zero if it does not fix one.
+func FingerprintVersionOf(fac interface{}) uint32 {
+	versioner, ok := fac.(FingerprintVersioner)
+	if !ok {
+		return 0
+	}
+
+	return versioner.GetFingerprintVersion()
+}
+
+// VersionedFormat is a format engine that supports several versions of the
+// definition of a message type. A message is encoded with its own version, or
+// the version pinned by the context, or else the latest one, and wrapped in an
+// envelope unless it is the legacy version, so that the nodes of before the
+// envelope can decode it. The data is decoded by the engine of the version in
+// the envelope, or by the one of the legacy version if the data has no
+// envelope.
+//
+// - implements serde.FormatEngine
+type VersionedFormat struct {
+	name    string
+	engines map[uint32]serde.FormatEngine
+}
+
+// NewVersionedFormat returns a new versioned format for the type of message.
+// The name identifies the type in the envelopes and must not change.
+func NewVersionedFormat(name string) *VersionedFormat {
+	return &VersionedFormat{
+		name:    name,
+		engines: make(map[uint32]serde.FormatEngine),
+	}
+}
+
+// Register registers the engine for the version of the message definition. It
+// overrides an already existing engine.
+func (f *VersionedFormat) Register(version uint32, engine serde.FormatEngine) {
+	f.engines[version] = engine
+}
+
+// GetVersions returns the sorted list of the supported versions.
+func (f *VersionedFormat) GetVersions() []uint32 {
+	versions := make([]uint32, 0, len(f.engines))
+	for version := range f.engines {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions
+}
+
+// Encode implements serde.FormatEngine. It encodes the message with the engine
+// of the encoding version and wraps the data in an envelope. The data of the
+// legacy version is returned as is.
+func (f *VersionedFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	version := VersionOf(ctx)
+
+	versioned, ok := msg.(VersionedMessage)
+	if ok && versioned.GetFormatVersion() != 0 {
+		version = versioned.GetFormatVersion()
+	}
+
+	if version == 0 {
+		versions := f.GetVersions()
+		if len(versions) == 0 {
+			return nil, xerrors.Errorf("no version of '%s'", f.name)
+		}
+
+		version = versions[len(versions)-1]
+	}
+
+	engine := f.engines[version]
+	if engine == nil {
+		return nil, xerrors.Errorf("unsupported version %d of '%s'", version, f.name)
+	}
+
+	data, err := engine.Encode(ctx, msg)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode version %d: %v", version, err)
+	}
+
+	if version == LegacyVersion {
+		return data, nil
+	}
+
+	m := Envelope{
+		Type:    f.name,
+		Version: version,
+		Data:    data,
+	}
+
+	data, err = ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal envelope: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It decodes the data of the envelope
+// with the engine of its version. The data without an envelope is decoded with
+// the engine of the legacy version.
+func (f *VersionedFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := Envelope{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil || m.Type == "" {
+		m = Envelope{Type: f.name, Version: LegacyVersion, Data: data}
+	}
+
+	if m.Type != f.name {
+		return nil, xerrors.Errorf("unexpected type '%s' instead of '%s'", m.Type, f.name)
+	}
+
+	engine := f.engines[m.Version]
+	if engine == nil {
+		return nil, xerrors.Errorf("unsupported version %d of '%s'", m.Version, f.name)
+	}
+
+	msg, err := engine.Decode(ctx, m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode version %d: %v", m.Version, err)
+	}
+
+	return msg, nil
+}
+
+// versionKey is the key of the pinned version in a serialization context.
+type versionKey struct{}
+
+// contextVersion wraps the pinned version so that it can be stored in a
+// serialization context.
+//
+// - implements serde.Factory
+type contextVersion uint32
+
+// Deserialize implements serde.Factory. It always returns an error as the
+// factory only provides the version.
+func (contextVersion) Deserialize(serde.Context, []byte) (serde.Message, error) {
+	return nil, xerrors.New("version cannot deserialize")
+}
+
+// WithVersion returns a serialization context that pins the version used by
+// the versioned formats to encode the messages, which is the latest one when
+// it is zero. During a rolling upgrade, the upgraded nodes pin the previous
+// version until all the nodes are able to decode the new one.
+func WithVersion(ctx serde.Context, version uint32) serde.Context {
+	return serde.WithFactory(ctx, versionKey{}, contextVersion(version))
+}
+
+// VersionOf returns the version pinned by the serialization context, or zero
+// if it is not set.
+func VersionOf(ctx serde.Context) uint32 {
+	version, ok := ctx.GetFactory(versionKey{}).(contextVersion)
+	if !ok {
+		return 0
+	}
+
+	return uint32(version)
+}
@@ -0,0 +1,158 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/testing/fake"
+)
+
+func TestVersionedFormat_Register(t *testing.T) {
+	format := NewVersionedFormat("test")
+
+	format.Register(2, fake.Format{})
+	format.Register(1, fake.Format{})
+	format.Register(1, fake.Format{})
+	require.Equal(t, []uint32{1, 2}, format.GetVersions())
+}
+
+func TestVersionedFormat_Encode(t *testing.T) {
+	format := NewVersionedFormat("test")
+	format.Register(1, versionFormat{version: "v1"})
+	format.Register(2, versionFormat{version: "v2"})
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, fake.Message{})
+	require.NoError(t, err)
+	require.Equal(t, `{"Type":"test","Version":2,"Data":"v2"}`, string(data))
+
+	// The legacy version is encoded without envelope.
+	data, err = format.Encode(WithVersion(ctx, LegacyVersion), fake.Message{})
+	require.NoError(t, err)
+	require.Equal(t, `"v1"`, string(data))
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, versionMessage{version: "v1", data: `"v1"`}, msg)
+
+	// The version of a message bound to its format wins over the pinned one.
+	data, err = format.Encode(WithVersion(ctx, 2), versionMessage{version: "v1", bound: 1})
+	require.NoError(t, err)
+	require.Equal(t, `"v1"`, string(data))
+
+	format.Register(3, versionFormat{version: "v3"})
+
+	data, err = format.Encode(ctx, versionMessage{version: "v3", bound: 3})
+	require.NoError(t, err)
+	require.Equal(t, `{"Type":"test","Version":3,"Data":"v3"}`, string(data))
+
+	_, err = format.Encode(WithVersion(ctx, 4), fake.Message{})
+	require.EqualError(t, err, "unsupported version 4 of 'test'")
+
+	_, err = NewVersionedFormat("test").Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "no version of 'test'")
+
+	format = NewVersionedFormat("test")
+	format.Register(1, fake.NewBadFormat())
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, fake.Err("failed to encode version 1"))
+
+	format.Register(2, versionFormat{version: "v2"})
+
+	_, err = format.Encode(fake.NewBadContextWithDelay(1), fake.Message{})
+	require.EqualError(t, err, fake.Err("failed to marshal envelope"))
+}
+
+func TestFingerprintVersionOf(t *testing.T) {
+	require.Equal(t, uint32(0), FingerprintVersionOf(nil))
+	require.Equal(t, uint32(0), FingerprintVersionOf(fake.Message{}))
+	require.Equal(t, uint32(2), FingerprintVersionOf(fakeVersioner{version: 2}))
+}
+
+func TestVersionOf(t *testing.T) {
+	ctx := fake.NewContext()
+	require.Equal(t, uint32(0), VersionOf(ctx))
+
+	pinned := WithVersion(ctx, 2)
+	require.Equal(t, uint32(2), VersionOf(pinned))
+	require.Equal(t, uint32(0), VersionOf(ctx))
+
+	_, err := contextVersion(2).Deserialize(pinned, nil)
+	require.EqualError(t, err, "version cannot deserialize")
+}
+
+func TestVersionedFormat_Decode(t *testing.T) {
+	format := NewVersionedFormat("test")
+	format.Register(1, versionFormat{version: "v1"})
+	format.Register(2, versionFormat{version: "v2"})
+
+	ctx := fake.NewContext()
+
+	msg, err := format.Decode(ctx, []byte(`{"Type":"test","Version":2,"Data":"v2"}`))
+	require.NoError(t, err)
+	require.Equal(t, versionMessage{version: "v2", data: `"v2"`}, msg)
+
+	msg, err = format.Decode(ctx, []byte(`{"Type":"test","Version":1,"Data":"v1"}`))
+	require.NoError(t, err)
+	require.Equal(t, versionMessage{version: "v1", data: `"v1"`}, msg)
+
+	// The data of the legacy version has no envelope.
+	msg, err = format.Decode(ctx, []byte(`{"Index":1}`))
+	require.NoError(t, err)
+	require.Equal(t, versionMessage{version: "v1", data: `{"Index":1}`}, msg)
+
+	msg, err = format.Decode(ctx, []byte(`[]`))
+	require.NoError(t, err)
+	require.Equal(t, versionMessage{version: "v1", data: `[]`}, msg)
+
+	_, err = format.Decode(ctx, []byte(`{"Type":"other","Version":1}`))
+	require.EqualError(t, err, "unexpected type 'other' instead of 'test'")
+
+	_, err = format.Decode(ctx, []byte(`{"Type":"test","Version":3}`))
+	require.EqualError(t, err, "unsupported version 3 of 'test'")
+
+	format.Register(1, fake.NewBadFormat())
+
+	_, err = format.Decode(ctx, []byte(`{"Type":"test","Version":1}`))
+	require.EqualError(t, err, fake.Err("failed to decode version 1"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type versionMessage struct {
+	version string
+	data    string
+	bound   uint32
+}
+
+func (m versionMessage) Serialize(serde.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func (m versionMessage) GetFormatVersion() uint32 {
+	return m.bound
+}
+
+type versionFormat struct {
+	version string
+}
+
+func (f versionFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	return ctx.Marshal(f.version)
+}
+
+func (f versionFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	return versionMessage{version: f.version, data: string(data)}, nil
+}
+
+type fakeVersioner struct {
+	version uint32
+}
+
+func (v fakeVersioner) GetFingerprintVersion() uint32 {
+	return v.version
+}
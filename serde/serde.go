@@ -23,6 +23,9 @@ const (
 
 	// FormatXML is the identifier for XML formats.
 	FormatXML Format = "XML"
+
+	// FormatCBOR is the identifier for CBOR formats.
+	FormatCBOR Format = "CBOR"
 )
 
 // Message is the interface that a message must implement.
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/canonical"
+	sjson "go.dedis.ch/dela/serde/json"
+)
+
+// vector is a test vector of the canonical encoding. The fingerprint and the
+// digest are computed from the message, which is in JSON format.
+type vector struct {
+	Name        string
+	Type        string
+	Message     json.RawMessage
+	Fingerprint string
+	Digest      string
+}
+
+// TestCanonical_Vectors checks the test vectors of the canonical encoding,
+// which the clients in other languages can use to make sure that they compute
+// the same fingerprints and digests.
+func TestCanonical_Vectors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "canonical", "vectors.json"))
+	require.NoError(t, err)
+
+	var vectors []vector
+	err = json.Unmarshal(data, &vectors)
+	require.NoError(t, err)
+	require.NotEmpty(t, vectors)
+
+	addrFac := minoch.AddressFactory{}
+	txFac := signed.NewTransactionFactory()
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(txFac))
+	csFac := authority.NewChangeSetFactory(addrFac, bls.NewPublicKeyFactory())
+
+	factories := map[string]serde.Factory{
+		"signed.Transaction": txFac,
+		"cosipbft.Genesis": types.NewGenesisFactory(
+			authority.NewFactory(addrFac, bls.NewPublicKeyFactory())),
+		"cosipbft.Block": blockFac,
+		"cosipbft.Link":  types.NewLinkFactory(blockFac, bls.NewSignatureFactory(), csFac),
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			fac := factories[v.Type]
+			require.NotNil(t, fac, v.Type)
+
+			msg, err := fac.Deserialize(sjson.NewContext(), v.Message)
+			require.NoError(t, err)
+
+			fingerprint, err := canonical.Fingerprint(msg.(serde.Fingerprinter))
+			require.NoError(t, err)
+			require.Equal(t, v.Fingerprint, hex.EncodeToString(fingerprint))
+
+			var digest []byte
+
+			switch m := msg.(type) {
+			case *signed.Transaction:
+				digest = m.GetID()
+			case types.Genesis:
+				digest = m.GetHash().Bytes()
+			case types.Block:
+				digest = m.GetHash().Bytes()
+			case types.BlockLink:
+				digest = m.GetHash().Bytes()
+			}
+
+			require.Equal(t, v.Digest, hex.EncodeToString(digest))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/cbor"
+	"go.dedis.ch/dela/serde/json"
+)
+
+// TestCompatibility_Fixtures makes sure that the messages stored by a node,
+// which are written by the previous versions of the formats, can still be
+// decoded, and that they are encoded again to the same bytes, as a message is
+// bound to the version of its fingerprint. The fixtures of a version must never
+// be regenerated once it is released.
+func TestCompatibility_Fixtures(t *testing.T) {
+	addrFac := minoch.AddressFactory{}
+	txFac := signed.NewTransactionFactory()
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(txFac))
+	csFac := authority.NewChangeSetFactory(addrFac, bls.NewPublicKeyFactory())
+	genesisFac := types.NewGenesisFactory(authority.NewFactory(addrFac, bls.NewPublicKeyFactory()))
+	linkFac := types.NewLinkFactory(blockFac, bls.NewSignatureFactory(), csFac)
+
+	jsonCtx := json.NewContext()
+	cborCtx := cbor.NewContext()
+
+	// The data of the version 1 is encoded again without envelope, as the nodes
+	// of before the envelopes expect it.
+	versions := []struct {
+		name   string
+		golden string
+	}{
+		{name: "legacy", golden: "legacy"},
+		{name: "v1", golden: "legacy"},
+		{name: "v2", golden: "v2"},
+	}
+
+	genesisHashes := make(map[string]types.Digest)
+
+	for _, version := range versions {
+		msg, err := genesisFac.Deserialize(jsonCtx, readFixture(t, "genesis-"+version.name+".json"))
+		require.NoError(t, err)
+
+		genesisHashes[version.name] = msg.(types.Genesis).GetHash()
+	}
+
+	testCases := []struct {
+		name  string
+		fac   serde.Factory
+		check func(t *testing.T, version string, msg serde.Message)
+	}{
+		{
+			name: "genesis",
+			fac:  genesisFac,
+			check: func(t *testing.T, version string, msg serde.Message) {
+				genesis := msg.(types.Genesis)
+				require.Equal(t, genesisHashes[version], genesis.GetHash())
+				require.Equal(t, types.Digest{1, 2, 3}, genesis.GetRoot())
+				require.Equal(t, 2, genesis.GetRoster().Len())
+			},
+		},
+		{
+			name: "block",
+			fac:  blockFac,
+			check: func(t *testing.T, version string, msg serde.Message) {
+				require.Equal(t, uint64(1), msg.(types.Block).GetIndex())
+				require.Equal(t, types.Digest{4, 5, 6}, msg.(types.Block).GetTreeRoot())
+			},
+		},
+		{
+			name: "link",
+			fac:  linkFac,
+			check: func(t *testing.T, version string, msg serde.Message) {
+				link := msg.(types.BlockLink)
+				require.Equal(t, genesisHashes[version], link.GetFrom())
+				require.Equal(t, uint64(1), link.GetBlock().GetIndex())
+
+				changeset := link.GetChangeSet().(*authority.RosterChangeSet)
+				require.Equal(t, []uint{1}, changeset.GetRemoveIndices())
+			},
+		},
+		{
+			name: "transaction",
+			fac:  txFac,
+			check: func(t *testing.T, version string, msg serde.Message) {
+				tx := msg.(*signed.Transaction)
+				require.Equal(t, uint64(7), tx.GetNonce())
+				require.Equal(t, []byte("abc"), tx.GetArg("value:key"))
+
+				pubkey := tx.GetIdentity().(crypto.PublicKey)
+				require.NoError(t, pubkey.Verify(tx.GetID(), tx.GetSignature()))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, version := range versions {
+			t.Run(tc.name+"-"+version.name, func(t *testing.T) {
+				jsonData := readFixture(t, tc.name+"-"+version.golden+".json")
+				cborData := readFixture(t, tc.name+"-"+version.golden+".cbor")
+
+				fixtures := []fixture{
+					{jsonCtx, readFixture(t, tc.name+"-"+version.name+".json")},
+					{cborCtx, readFixture(t, tc.name+"-"+version.name+".cbor")},
+				}
+
+				for _, fixture := range fixtures {
+					msg, err := tc.fac.Deserialize(fixture.ctx, fixture.data)
+					require.NoError(t, err)
+
+					tc.check(t, version.name, msg)
+
+					data, err := msg.Serialize(jsonCtx)
+					require.NoError(t, err)
+					require.Equal(t, string(jsonData), string(data))
+
+					data, err = msg.Serialize(cborCtx)
+					require.NoError(t, err)
+					require.Equal(t, cborData, data)
+				}
+			})
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fixture struct {
+	ctx  serde.Context
+	data []byte
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "formats", name))
+	require.NoError(t, err)
+
+	return data
+}
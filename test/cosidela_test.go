@@ -43,6 +43,7 @@ import (
 	"go.dedis.ch/dela/crypto/loader"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
+	"go.dedis.ch/dela/mino/minofault"
 	"go.dedis.ch/dela/mino/minogrpc"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/session"
@@ -82,7 +83,30 @@ type cosiDelaNode struct {
 	tree          hashtree.Tree
 }
 
-func newDelaNode(t require.TestingT, path string, port int, kind string) dela {
+// nodeTemplate contains the optional settings of a Dela node.
+type nodeTemplate struct {
+	network *minofault.Network
+}
+
+// nodeOption is the type of option to customize a Dela node.
+type nodeOption func(*nodeTemplate)
+
+// withFaults is an option to inject the faults of the network in the messages
+// sent by the node.
+func withFaults(network *minofault.Network) nodeOption {
+	return func(tmpl *nodeTemplate) {
+		tmpl.network = network
+	}
+}
+
+func newDelaNode(t require.TestingT, path string, port int, kind string,
+	opts ...nodeOption) dela {
+
+	tmpl := nodeTemplate{}
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
 	err := os.MkdirAll(path, 0700)
 	require.NoError(t, err)
 
@@ -126,6 +150,10 @@ func newDelaNode(t require.TestingT, path string, port int, kind string) dela {
 	}
 	onet.GetAddress()
 
+	if tmpl.network != nil {
+		onet = tmpl.network.Wrap(onet)
+	}
+
 	// ordering + validation + execution
 	fload := loader.NewFileLoader(filepath.Join(path, privateKeyFile))
 
@@ -216,7 +244,7 @@ func newDelaNode(t require.TestingT, path string, port int, kind string) dela {
 func (c cosiDelaNode) Setup(kind string, delas ...dela) {
 	// share the certificates
 	if kind == minoGRPC {
-		joinable, ok := c.onet.(minogrpc.Joinable)
+		joinable, ok := unwrapMino(c.onet).(minogrpc.Joinable)
 		require.True(c.t, ok)
 
 		addrURL, err := url.Parse(c.onet.GetAddress().String())
@@ -228,7 +256,7 @@ func (c cosiDelaNode) Setup(kind string, delas ...dela) {
 		require.NoError(c.t, err)
 
 		for _, dela := range delas {
-			otherJoinable, ok := dela.GetMino().(minogrpc.Joinable)
+			otherJoinable, ok := unwrapMino(dela.GetMino()).(minogrpc.Joinable)
 			require.True(c.t, ok)
 
 			err = otherJoinable.Join(addrURL, token, certHash)
@@ -238,7 +266,8 @@ func (c cosiDelaNode) Setup(kind string, delas ...dela) {
 
 	type extendedService interface {
 		GetRoster() (authority.Authority, error)
-		Setup(ctx context.Context, ca crypto.CollectiveAuthority) error
+		Setup(ctx context.Context, ca crypto.CollectiveAuthority,
+			opts ...cosipbft.SetupOption) error
 	}
 
 	// make roster
@@ -270,6 +299,16 @@ func (c cosiDelaNode) Setup(kind string, delas ...dela) {
 	require.NoError(c.t, err)
 }
 
+// unwrapMino returns the Mino decorated by the fault injection, if any.
+func unwrapMino(m mino.Mino) mino.Mino {
+	faulty, ok := m.(*minofault.Mino)
+	if ok {
+		return faulty.Unwrap()
+	}
+
+	return m
+}
+
 // GetMino implements dela
 func (c cosiDelaNode) GetMino() mino.Mino {
 	return c.onet
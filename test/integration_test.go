@@ -20,6 +20,8 @@ import (
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minofault"
 	"golang.org/x/xerrors"
 )
 
@@ -67,65 +69,143 @@ func getTest[T require.TestingT](numNode, numTx int, kind string) func(t T) {
 
 		nodes[0].Setup(kind, nodes[1:]...)
 
-		l := loader.NewFileLoader(filepath.Join(dir, "private.key"))
+		manager := grantValueAccess(t, dir, timeout, nodes)
 
-		signerdata, err := l.LoadOrCreate(newKeyGenerator())
-		require.NoError(t, err)
+		for i := 0; i < numTx; i++ {
+			key := writeValue(t, timeout, manager, nodes[0])
 
-		signer, err := bls.NewSignerFromBytes(signerdata)
-		require.NoError(t, err)
+			prefixedKey := prefixed.NewPrefixedKey([]byte(value.ContractUID), key)
+			proof, err := nodes[0].GetOrdering().GetProof(prefixedKey)
+			require.NoError(t, err)
+			require.Equal(t, []byte("value1"), proof.GetValue())
+		}
+	}
+}
 
-		pubKey := signer.GetPublicKey()
-		cred := accessContract.NewCreds()
+// Start 4 nodes with latency on every link
+// Isolate one of the nodes while the others add values
+// Check that the isolated node catches up once the partition heals
+func TestIntegration_Value_Partition(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping slow test")
+	}
 
-		for _, node := range nodes {
-			node.GetAccessService().Grant(node.(cosiDelaNode).GetAccessStore(), cred, pubKey)
-		}
+	dir, err := os.MkdirTemp(os.TempDir(), "dela-integration-test")
+	require.NoError(t, err)
 
-		manager := signed.NewManager(signer, &txClient{})
+	defer os.RemoveAll(dir)
 
-		pubKeyBuf, err := signer.GetPublicKey().MarshalBinary()
-		require.NoError(t, err)
+	timeout := time.Second * 20
 
-		args := []txn.Arg{
-			{Key: "go.dedis.ch/dela.ContractArg", Value: []byte("go.dedis.ch/dela.Access")},
-			{Key: "access:grant_id", Value: []byte(hex.EncodeToString([]byte(value.ContractUID)))},
-			{Key: "access:grant_contract", Value: []byte("go.dedis.ch/dela.Value")},
-			{Key: "access:grant_command", Value: []byte("all")},
-			{Key: "access:identity", Value: []byte(base64.StdEncoding.EncodeToString(pubKeyBuf))},
-			{Key: "access:command", Value: []byte("GRANT")},
-		}
+	network := minofault.NewNetwork(minofault.WithSeed(0), minofault.WithDefaultLink(
+		minofault.Link{Delay: 5 * time.Millisecond, Jitter: 5 * time.Millisecond}))
 
-		err = addAndWait(t, timeout, manager, nodes[0].(cosiDelaNode), args...)
-		require.NoError(t, err)
+	nodes := make([]dela, 4)
+	addrs := make([]mino.Address, len(nodes))
 
-		for i := 0; i < numTx; i++ {
-			key := make([]byte, 32)
+	for i := range nodes {
+		nodes[i] = newDelaNode(t, filepath.Join(dir, "node"+strconv.Itoa(i)), 0,
+			minoGRPC, withFaults(network))
+		addrs[i] = nodes[i].GetMino().GetAddress()
+	}
 
-			_, err = randGen.Read(key)
-			require.NoError(t, err)
+	nodes[0].Setup(minoGRPC, nodes[1:]...)
 
-			args = []txn.Arg{
-				{Key: "go.dedis.ch/dela.ContractArg", Value: []byte("go.dedis.ch/dela.Value")},
-				{Key: "value:key", Value: key},
-				{Key: "value:value", Value: []byte("value1")},
-				{Key: "value:command", Value: []byte("WRITE")},
-			}
+	manager := grantValueAccess(t, dir, timeout, nodes)
 
-			err = addAndWait(t, timeout, manager, nodes[0].(cosiDelaNode), args...)
-			require.NoError(t, err)
+	network.Partition(minofault.Partition{
+		Groups: [][]mino.Address{addrs[:3], addrs[3:]},
+	})
 
+	keys := make([][]byte, 2)
+	for i := range keys {
+		keys[i] = writeValue(t, timeout, manager, nodes[0])
+	}
+
+	network.Heal()
+
+	keys = append(keys, writeValue(t, timeout, manager, nodes[0]))
+
+	require.Eventually(t, func() bool {
+		for _, key := range keys {
 			prefixedKey := prefixed.NewPrefixedKey([]byte(value.ContractUID), key)
-			proof, err := nodes[0].GetOrdering().GetProof(prefixedKey)
-			require.NoError(t, err)
-			require.Equal(t, []byte("value1"), proof.GetValue())
+
+			proof, err := nodes[3].GetOrdering().GetProof(prefixedKey)
+			if err != nil || !bytes.Equal([]byte("value1"), proof.GetValue()) {
+				return false
+			}
 		}
-	}
+
+		return true
+	}, timeout, 100*time.Millisecond)
 }
 
 // -----------------------------------------------------------------------------
 // Utility functions
 
+// grantValueAccess creates a signer allowed to use the value contract and
+// returns the transaction manager of the signer.
+func grantValueAccess(t require.TestingT, dir string, timeout time.Duration,
+	nodes []dela) txn.Manager {
+
+	l := loader.NewFileLoader(filepath.Join(dir, "private.key"))
+
+	signerdata, err := l.LoadOrCreate(newKeyGenerator())
+	require.NoError(t, err)
+
+	signer, err := bls.NewSignerFromBytes(signerdata)
+	require.NoError(t, err)
+
+	pubKey := signer.GetPublicKey()
+	cred := accessContract.NewCreds()
+
+	for _, node := range nodes {
+		node.GetAccessService().Grant(node.(cosiDelaNode).GetAccessStore(), cred, pubKey)
+	}
+
+	manager := signed.NewManager(signer, &txClient{})
+
+	pubKeyBuf, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	args := []txn.Arg{
+		{Key: "go.dedis.ch/dela.ContractArg", Value: []byte("go.dedis.ch/dela.Access")},
+		{Key: "access:grant_id", Value: []byte(hex.EncodeToString([]byte(value.ContractUID)))},
+		{Key: "access:grant_contract", Value: []byte("go.dedis.ch/dela.Value")},
+		{Key: "access:grant_command", Value: []byte("all")},
+		{Key: "access:identity", Value: []byte(base64.StdEncoding.EncodeToString(pubKeyBuf))},
+		{Key: "access:command", Value: []byte("GRANT")},
+	}
+
+	err = addAndWait(t, timeout, manager, nodes[0].(cosiDelaNode), args...)
+	require.NoError(t, err)
+
+	return manager
+}
+
+// writeValue writes a value to a random key through the node and returns the
+// key once the transaction is accepted.
+func writeValue(t require.TestingT, timeout time.Duration, manager txn.Manager,
+	node dela) []byte {
+
+	key := make([]byte, 32)
+
+	_, err := randGen.Read(key)
+	require.NoError(t, err)
+
+	args := []txn.Arg{
+		{Key: "go.dedis.ch/dela.ContractArg", Value: []byte("go.dedis.ch/dela.Value")},
+		{Key: "value:key", Value: key},
+		{Key: "value:value", Value: []byte("value1")},
+		{Key: "value:command", Value: []byte("WRITE")},
+	}
+
+	err = addAndWait(t, timeout, manager, node.(cosiDelaNode), args...)
+	require.NoError(t, err)
+
+	return key
+}
+
 func addAndWait(
 	t require.TestingT,
 	to time.Duration,
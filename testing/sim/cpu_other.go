@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package sim
+
+import "time"
+
+// cpuTime returns zero as the CPU time of the process is not available on this
+// platform, so that only the activity of the nodes is used to settle.
+func cpuTime() time.Duration {
+	return 0
+}
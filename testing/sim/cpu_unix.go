@@ -0,0 +1,22 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package sim
+
+import (
+	"syscall"
+	"time"
+)
+
+// cpuTime returns the amount of CPU time the process has used so far, or zero
+// if it is not available.
+func cpuTime() time.Duration {
+	var usage syscall.Rusage
+
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
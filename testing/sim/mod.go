@@ -0,0 +1,602 @@
+// Package sim implements a simulation of a whole cluster of nodes running in a
+// single process.
+//
+// The nodes communicate through channels wrapped by a fault-injecting network,
+// and the timeouts of the ordering service, of the transaction pools, of the
+// DKG and of the network are driven by a virtual clock. The network holds every
+// message until the simulation releases it, and the simulation releases one
+// event at a time, either a message or a timer, in the order of their time, so
+// that a scenario spanning minutes of protocol time runs in a fraction of it.
+// The timers go before the messages of the same time.
+//
+// An event is released only once the nodes have settled, which is when neither
+// the network nor the databases of the nodes are in use, and when neither the
+// network, the databases nor the clock has seen any activity for a quiet
+// period, during which the process has barely used the CPU and after which no
+// other goroutine is running or ready to run, so that a node still computing
+// the reaction to an event is waited for. The nodes then react
+// to one event at a time, which makes the order of the messages and of the
+// timers the same from one run to another.
+//
+// The keys of the nodes, the faults of the network and the randomness of the
+// DKG, like its secrets and the nonces of its signatures, are derived from a
+// seed that is logged by the cluster. Setting the seed in the environment
+// variable replays the same run, as long as the scenario draws its own values
+// from the seed too.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.dedis.ch/dela/core/access/darc"
+	"go.dedis.ch/dela/core/clock"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn/pool"
+	poolgossip "go.dedis.ch/dela/core/txn/pool/gossip"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/cosi/threshold"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/ed25519"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/dkg/pedersen"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/gossip"
+	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/mino/minofault"
+	"go.dedis.ch/dela/serde/json"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/util/random"
+	"golang.org/x/xerrors"
+)
+
+// SeedEnv is the name of the environment variable that forces the seed of the
+// simulations.
+const SeedEnv = "DELA_SIM_SEED"
+
+// defaultQuietPeriod is the amount of real time during which the nodes must stay
+// idle before the cluster is considered settled.
+const defaultQuietPeriod = 2 * time.Millisecond
+
+// idleRatio is the maximum share of the quiet period that the process can
+// spend on the CPU for the nodes to be considered idle.
+const idleRatio = 10
+
+// settleLimit is the maximum amount of real time to wait for the nodes to
+// settle, after which the clock moves forward anyway.
+const settleLimit = 10 * time.Second
+
+// noLimit is the time used as a limit by the steps that are not bounded.
+var noLimit = time.Unix(1<<62, 0)
+
+// Node is a participant of the simulated cluster.
+type Node struct {
+	Mino     *minofault.Mino
+	Signer   crypto.Signer
+	Pool     pool.Pool
+	Ordering *cosipbft.Service
+	DKG      dkg.Actor
+
+	db     kv.DB
+	dkgKey kyber.Point
+	height atomic.Uint64
+	cancel context.CancelFunc
+}
+
+// Height returns the number of blocks the node has committed.
+func (n *Node) Height() uint64 {
+	return n.height.Load()
+}
+
+// Cluster is a set of nodes sharing a virtual clock and a network.
+type Cluster struct {
+	Clock   *clock.Virtual
+	Network *minofault.Network
+	Nodes   []*Node
+
+	t         testing.TB
+	seed      int64
+	disk      activity
+	keys      *rand.Rand
+	quiet     time.Duration
+	link      minofault.Link
+	contracts map[string]native.Contract
+}
+
+// ClusterOption is the type of option to set some fields of a cluster.
+type ClusterOption func(*Cluster)
+
+// WithSeed is an option to set the seed of the simulation. It takes precedence
+// over the environment variable.
+func WithSeed(seed int64) ClusterOption {
+	return func(c *Cluster) {
+		c.seed = seed
+	}
+}
+
+// WithDefaultLink is an option to set the faults of the links between the
+// nodes.
+func WithDefaultLink(link minofault.Link) ClusterOption {
+	return func(c *Cluster) {
+		c.link = link
+	}
+}
+
+// WithContract is an option to register a native contract in the execution
+// service of every node.
+func WithContract(name string, contract native.Contract) ClusterOption {
+	return func(c *Cluster) {
+		c.contracts[name] = contract
+	}
+}
+
+// WithQuietPeriod is an option to set the amount of real time the nodes must
+// stay idle before the clock is advanced.
+func WithQuietPeriod(d time.Duration) ClusterOption {
+	return func(c *Cluster) {
+		c.quiet = d
+	}
+}
+
+// NewCluster creates a cluster of n nodes that are listening but not yet
+// following a chain. The nodes are closed when the test ends.
+func NewCluster(t testing.TB, n int, opts ...ClusterOption) *Cluster {
+	t.Helper()
+
+	c := &Cluster{
+		Clock:     clock.NewVirtual(time.Unix(0, 0)),
+		t:         t,
+		seed:      Seed(t),
+		quiet:     defaultQuietPeriod,
+		contracts: make(map[string]native.Contract),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	t.Logf("simulation seed: %d (replay with %s=%d)", c.seed, SeedEnv, c.seed)
+
+	c.keys = rand.New(rand.NewSource(c.seed))
+
+	c.Network = minofault.NewNetwork(
+		minofault.WithSeed(c.seed),
+		minofault.WithClock(c.Clock),
+		minofault.WithDefaultLink(c.link),
+		minofault.WithScheduling(),
+	)
+
+	manager := minoch.NewManager()
+
+	for i := 0; i < n; i++ {
+		node, err := c.makeNode(manager, i)
+		if err != nil {
+			t.Fatalf("failed to create node %d: %v", i, err)
+		}
+
+		c.Nodes = append(c.Nodes, node)
+	}
+
+	t.Cleanup(c.close)
+
+	return c
+}
+
+// Seed returns the seed set in the environment variable, or a new one if it is
+// not set.
+func Seed(t testing.TB) int64 {
+	t.Helper()
+
+	value := os.Getenv(SeedEnv)
+	if value == "" {
+		return time.Now().UnixNano()
+	}
+
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid seed '%s': %v", value, err)
+	}
+
+	return seed
+}
+
+// GetSeed returns the seed of the simulation.
+func (c *Cluster) GetSeed() int64 {
+	return c.seed
+}
+
+// Rand returns a source of randomness derived from the seed of the simulation,
+// for the scenarios that need to draw their own values.
+func (c *Cluster) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(c.seed))
+}
+
+// Authority returns the roster of the ordering service made of every node.
+func (c *Cluster) Authority() authority.Authority {
+	addrs := make([]mino.Address, len(c.Nodes))
+	pubkeys := make([]crypto.PublicKey, len(c.Nodes))
+
+	for i, node := range c.Nodes {
+		addrs[i] = node.Mino.GetAddress()
+		pubkeys[i] = node.Signer.GetPublicKey()
+	}
+
+	return authority.New(addrs, pubkeys)
+}
+
+// Setup creates the genesis block from the first node so that every node starts
+// to follow the chain.
+func (c *Cluster) Setup(limit time.Duration) error {
+	return c.Go(func() error {
+		return c.Nodes[0].Ordering.Setup(context.Background(), c.Authority())
+	}, limit)
+}
+
+// SetupDKG runs the distributed key generation between every node and returns
+// the collective public key.
+func (c *Cluster) SetupDKG(threshold int, limit time.Duration) (kyber.Point, error) {
+	addrs := make([]mino.Address, len(c.Nodes))
+	pubkeys := make([]crypto.PublicKey, len(c.Nodes))
+
+	for i, node := range c.Nodes {
+		addrs[i] = node.Mino.GetAddress()
+		pubkeys[i] = ed25519.NewPublicKeyFromPoint(node.dkgKey)
+	}
+
+	var pubkey kyber.Point
+
+	err := c.Go(func() error {
+		var err error
+		pubkey, err = c.Nodes[0].DKG.Setup(authority.New(addrs, pubkeys), threshold)
+
+		return err
+	}, limit)
+
+	if err != nil {
+		return nil, xerrors.Errorf("dkg setup: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+// Step waits for the nodes to settle and releases the next event, either a
+// message or a timer. It returns false if there is no event left.
+func (c *Cluster) Step() bool {
+	c.settle()
+
+	return c.next(noLimit)
+}
+
+// Run moves the clock forward by the duration, event by event.
+func (c *Cluster) Run(d time.Duration) {
+	c.RunUntil(func() bool { return false }, d)
+}
+
+// RunUntil releases the events one by one until the condition is met, or until
+// the limit of time has passed. It returns true if the condition is met.
+func (c *Cluster) RunUntil(cond func() bool, limit time.Duration) bool {
+	end := c.Clock.Now().Add(limit)
+
+	for {
+		c.settle()
+
+		if cond() {
+			return true
+		}
+
+		if !c.next(end) {
+			c.Clock.Advance(end.Sub(c.Clock.Now()))
+			c.settle()
+
+			return cond()
+		}
+	}
+}
+
+// Go runs the function while the clock moves forward, and returns its error
+// once it is done, or an error if it is still running after the limit of time.
+// As some work is done without waiting on the clock, the function is given the
+// same amount of real time once the clock has reached the limit.
+func (c *Cluster) Go(fn func() error, limit time.Duration) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- fn()
+	}()
+
+	var err error
+	finished := func() bool {
+		select {
+		case err = <-done:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if c.RunUntil(finished, limit) {
+		return err
+	}
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+
+	select {
+	case err = <-done:
+		return err
+	case <-timer.C:
+		return xerrors.Errorf("still running after %v", limit)
+	}
+}
+
+// settle waits until the network and the databases have no activity, and until
+// neither of them nor the clock has seen any activity for the quiet period
+// while the process stayed off the CPU, and until no other goroutine is
+// computing, which means the nodes are all waiting for a timer or for a
+// message. It gives up after the settle limit, for instance when a node keeps
+// working without waiting on the clock.
+func (c *Cluster) settle() {
+	seq := c.Clock.Seq()
+	_, changes := c.Network.Activity()
+	_, writes := c.disk.get()
+	cpu := cpuTime()
+
+	deadline := time.Now().Add(settleLimit)
+
+	for time.Now().Before(deadline) {
+		time.Sleep(c.quiet)
+
+		nextSeq := c.Clock.Seq()
+		active, nextChanges := c.Network.Activity()
+		busy, nextWrites := c.disk.get()
+		nextCPU := cpuTime()
+
+		if active == 0 && busy == 0 && nextSeq == seq && nextChanges == changes &&
+			nextWrites == writes && nextCPU-cpu < c.quiet/idleRatio && !computing() {
+			return
+		}
+
+		seq = nextSeq
+		changes = nextChanges
+		writes = nextWrites
+		cpu = nextCPU
+	}
+
+	c.t.Logf("nodes still busy after %v", settleLimit)
+}
+
+// computing returns true if a goroutine other than the caller is running or is
+// ready to run. It returns false if the runtime does not report the state of
+// the goroutines, which it does from Go 1.26 on.
+func computing() bool {
+	samples := []metrics.Sample{
+		{Name: "/sched/goroutines/running:goroutines"},
+		{Name: "/sched/goroutines/runnable:goroutines"},
+	}
+
+	metrics.Read(samples)
+
+	for _, sample := range samples {
+		if sample.Value.Kind() != metrics.KindUint64 {
+			return false
+		}
+	}
+
+	return samples[0].Value.Uint64() > 1 || samples[1].Value.Uint64() > 0
+}
+
+// next releases the earliest event that is not after the end, which is either
+// a message held by the network or a timer of the clock, and moves the clock to
+// its time. It returns false if there is no such event.
+func (c *Cluster) next(end time.Time) bool {
+	timer, hasTimer := c.Clock.Next()
+	delivery, hasDelivery := c.Network.NextDelivery()
+
+	if hasDelivery && !delivery.After(end) && (!hasTimer || delivery.Before(timer)) {
+		now := c.Clock.Now()
+		if delivery.After(now) {
+			c.Clock.Advance(delivery.Sub(now))
+		}
+
+		return c.Network.Deliver()
+	}
+
+	if hasTimer && !timer.After(end) {
+		return c.Clock.AdvanceNext()
+	}
+
+	return false
+}
+
+func (c *Cluster) makeNode(manager *minoch.Manager, index int) (*Node, error) {
+	m := c.Network.Wrap(minoch.MustCreate(manager, fmt.Sprintf("node%d", index)))
+
+	data, err := c.newScalar(bn256.NewSuiteG2()).MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("key: %v", err)
+	}
+
+	signer, err := bls.NewSignerFromBytes(data)
+	if err != nil {
+		return nil, xerrors.Errorf("signer: %v", err)
+	}
+
+	cosi := threshold.NewThreshold(m, signer)
+	cosi.SetThreshold(threshold.ByzantineThreshold)
+
+	bolt, err := kv.New(filepath.Join(c.t.TempDir(), "dela.db"))
+	if err != nil {
+		return nil, xerrors.Errorf("db: %v", err)
+	}
+
+	db := trackedDB{DB: bolt, activity: &c.disk}
+
+	txFac := signed.NewTransactionFactory()
+
+	p, err := poolgossip.NewPool(gossip.NewFlat(m, txFac, gossip.WithClock(c.Clock)),
+		pool.WithClock(c.Clock))
+	if err != nil {
+		return nil, xerrors.Errorf("pool: %v", err)
+	}
+
+	exec := native.NewExecution()
+	for name, contract := range c.contracts {
+		exec.Set(name, contract)
+	}
+
+	access := darc.NewService(json.NewContext())
+
+	rosterFac := authority.NewFactory(m.GetAddressFactory(), cosi.GetPublicKeyFactory())
+	cosipbft.RegisterRosterContract(exec, rosterFac, access)
+
+	param := cosipbft.ServiceParam{
+		Mino:       m,
+		Cosi:       cosi,
+		Validation: simple.NewService(exec, txFac),
+		Access:     access,
+		Pool:       p,
+		Tree:       binprefix.NewMerkleTree(db, binprefix.Nonce{}),
+		DB:         db,
+	}
+
+	srvc, err := cosipbft.NewService(param, cosipbft.WithClock(c.Clock))
+	if err != nil {
+		return nil, xerrors.Errorf("ordering: %v", err)
+	}
+
+	d, dkgKey := pedersen.NewPedersen(m, pedersen.WithClock(c.Clock),
+		pedersen.WithKey(c.newScalar(edwards25519.NewBlakeSHA256Ed25519())),
+		pedersen.WithRandom(rand.New(rand.NewSource(c.keys.Int63()))))
+
+	actor, err := d.Listen()
+	if err != nil {
+		return nil, xerrors.Errorf("dkg: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	node := &Node{
+		Mino:     m,
+		Signer:   signer,
+		Pool:     p,
+		Ordering: srvc,
+		DKG:      actor,
+		db:       db,
+		dkgKey:   dkgKey,
+		cancel:   cancel,
+	}
+
+	events := srvc.Watch(ctx)
+
+	go func() {
+		for event := range events {
+			node.height.Store(event.Index + 1)
+		}
+	}()
+
+	return node, nil
+}
+
+// newScalar returns a private key of the group derived from the seed. The keys,
+// like the seeds of the randomness of the DKG, are drawn in the order of
+// creation of the nodes.
+func (c *Cluster) newScalar(group kyber.Group) kyber.Scalar {
+	return group.Scalar().Pick(random.New(c.keys))
+}
+
+// activity counts the transactions in progress on the databases of the nodes,
+// and the transactions that have been done.
+type activity struct {
+	sync.Mutex
+
+	active  int
+	changes uint64
+}
+
+func (a *activity) get() (int, uint64) {
+	a.Lock()
+	defer a.Unlock()
+
+	return a.active, a.changes
+}
+
+func (a *activity) start() {
+	a.Lock()
+	a.active++
+	a.changes++
+	a.Unlock()
+}
+
+func (a *activity) stop() {
+	a.Lock()
+	a.active--
+	a.changes++
+	a.Unlock()
+}
+
+// trackedDB is a database that records its transactions as activity of the
+// nodes, as writing to the disk is done without waiting on the clock or on the
+// network.
+//
+// - implements kv.DB
+type trackedDB struct {
+	kv.DB
+
+	activity *activity
+}
+
+// View implements kv.DB. It records the transaction while it is running.
+func (db trackedDB) View(fn func(kv.ReadableTx) error) error {
+	db.activity.start()
+	defer db.activity.stop()
+
+	return db.DB.View(fn)
+}
+
+// Update implements kv.DB. It records the transaction while it is running.
+func (db trackedDB) Update(fn func(kv.WritableTx) error) error {
+	db.activity.start()
+	defer db.activity.stop()
+
+	return db.DB.Update(fn)
+}
+
+func (c *Cluster) close() {
+	for _, node := range c.Nodes {
+		node.cancel()
+
+		// The service might be sleeping on the clock, which then needs to move
+		// forward for the node to notice it is closing.
+		err := c.Go(node.Ordering.Close, time.Hour)
+		if err != nil {
+			c.t.Errorf("failed to close ordering: %v", err)
+		}
+
+		err = node.Pool.Close()
+		if err != nil {
+			c.t.Errorf("failed to close pool: %v", err)
+		}
+
+		err = node.db.Close()
+		if err != nil {
+			c.t.Errorf("failed to close db: %v", err)
+		}
+	}
+}
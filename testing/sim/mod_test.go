@@ -0,0 +1,244 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/participation"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minofault"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+const testContractName = "abc"
+
+func TestCluster_Scenario_Commit(t *testing.T) {
+	link := minofault.Link{Delay: 50 * time.Millisecond, Jitter: 50 * time.Millisecond}
+
+	cluster := NewCluster(t, 4, WithDefaultLink(link),
+		WithContract(testContractName, testExec{}))
+
+	require.NoError(t, cluster.Setup(time.Minute))
+
+	signer := bls.NewSigner()
+
+	for i := 0; i < 3; i++ {
+		addTx(t, cluster, cluster.Nodes[i], makeTx(t, uint64(i), signer))
+
+		height := uint64(i + 1)
+		ok := cluster.RunUntil(func() bool {
+			return cluster.Nodes[0].Height() == height
+		}, time.Minute)
+		require.True(t, ok, "block %d not committed", i)
+	}
+
+	// The whole scenario runs in virtual time which is never shorter than the
+	// latency of the links.
+	require.Greater(t, cluster.Clock.Now().Unix(), int64(0))
+}
+
+func TestCluster_Scenario_Partition(t *testing.T) {
+	cluster := NewCluster(t, 4, WithContract(testContractName, testExec{}))
+
+	require.NoError(t, cluster.Setup(time.Minute))
+
+	addrs := make([]mino.Address, len(cluster.Nodes))
+	for i, node := range cluster.Nodes {
+		addrs[i] = node.Mino.GetAddress()
+	}
+
+	cluster.Network.Partition(minofault.Partition{
+		Groups:   [][]mino.Address{addrs[:3], addrs[3:]},
+		Duration: 10 * time.Second,
+	})
+
+	signer := bls.NewSigner()
+	addTx(t, cluster, cluster.Nodes[0], makeTx(t, 0, signer))
+
+	ok := cluster.RunUntil(func() bool {
+		return cluster.Nodes[0].Height() == 1
+	}, time.Minute)
+	require.True(t, ok)
+	require.Equal(t, uint64(0), cluster.Nodes[3].Height())
+
+	// Once the partition is over, the isolated node catches up with the next
+	// block.
+	cluster.Run(10 * time.Second)
+
+	addTx(t, cluster, cluster.Nodes[0], makeTx(t, 1, signer))
+
+	ok = cluster.RunUntil(func() bool {
+		return cluster.Nodes[3].Height() == 2
+	}, time.Minute)
+	require.True(t, ok)
+}
+
+func TestCluster_SetupDKG(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping slow test")
+	}
+
+	cluster := NewCluster(t, 3)
+
+	pubkey, err := cluster.SetupDKG(2, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, pubkey)
+
+	_, err = cluster.SetupDKG(2, time.Minute)
+	require.Error(t, err)
+}
+
+func TestCluster_Replay(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping slow test")
+	}
+
+	seed := Seed(t)
+
+	first := runReplay(t, seed)
+	second := runReplay(t, seed)
+
+	require.Equal(t, first, second)
+}
+
+func TestCluster_Go_Timeout(t *testing.T) {
+	cluster := NewCluster(t, 1)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	err := cluster.Go(func() error {
+		<-done
+		return nil
+	}, 10*time.Millisecond)
+	require.EqualError(t, err, "still running after 10ms")
+}
+
+func TestSeed(t *testing.T) {
+	t.Setenv(SeedEnv, "42")
+	require.Equal(t, int64(42), Seed(t))
+
+	cluster := NewCluster(t, 1)
+	require.Equal(t, int64(42), cluster.GetSeed())
+	require.Equal(t, cluster.Rand().Int63(), cluster.Rand().Int63())
+}
+
+func TestCluster_Keys(t *testing.T) {
+	first := NewCluster(t, 2, WithSeed(1))
+	second := NewCluster(t, 2, WithSeed(1))
+	other := NewCluster(t, 2, WithSeed(2))
+
+	for i := range first.Nodes {
+		require.True(t, first.Nodes[i].Signer.GetPublicKey().
+			Equal(second.Nodes[i].Signer.GetPublicKey()))
+		require.True(t, first.Nodes[i].dkgKey.Equal(second.Nodes[i].dkgKey))
+
+		require.False(t, first.Nodes[i].Signer.GetPublicKey().
+			Equal(other.Nodes[i].Signer.GetPublicKey()))
+	}
+
+	require.False(t, first.Nodes[0].Signer.GetPublicKey().
+		Equal(first.Nodes[1].Signer.GetPublicKey()))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// replay is the outcome of a scenario that must be the same for every run of
+// the same seed.
+type replay struct {
+	now     time.Time
+	heights []uint64
+	stats   []participation.Stats
+	dkgKey  []byte
+}
+
+// runReplay runs a scenario with a faulty network, from the setup of the chain
+// and of the DKG up to a few blocks, and returns its outcome.
+func runReplay(t *testing.T, seed int64) replay {
+	link := minofault.Link{
+		Reorder: 0.1,
+		Delay:   50 * time.Millisecond,
+		Jitter:  50 * time.Millisecond,
+	}
+
+	cluster := NewCluster(t, 4, WithSeed(seed), WithDefaultLink(link),
+		WithContract(testContractName, testExec{}))
+
+	require.NoError(t, cluster.Setup(time.Minute))
+
+	pubkey, err := cluster.SetupDKG(3, time.Minute)
+	require.NoError(t, err)
+
+	dkgKey, err := pubkey.MarshalBinary()
+	require.NoError(t, err)
+
+	data, err := bn256.NewSuiteG2().Scalar().Pick(random.New(cluster.Rand())).MarshalBinary()
+	require.NoError(t, err)
+
+	signer, err := bls.NewSignerFromBytes(data)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		addTx(t, cluster, cluster.Nodes[i], makeTx(t, uint64(i), signer))
+
+		height := uint64(i + 1)
+		ok := cluster.RunUntil(func() bool {
+			return cluster.Nodes[0].Height() == height
+		}, time.Minute)
+		require.True(t, ok, "block %d not committed", i)
+	}
+
+	heights := make([]uint64, len(cluster.Nodes))
+	for i, node := range cluster.Nodes {
+		heights[i] = node.Height()
+	}
+
+	stats, err := cluster.Nodes[0].Ordering.GetParticipation()
+	require.NoError(t, err)
+
+	return replay{
+		now:     cluster.Clock.Now(),
+		heights: heights,
+		stats:   stats,
+		dkgKey:  dkgKey,
+	}
+}
+
+type testExec struct{}
+
+func (testExec) Execute(store.Snapshot, execution.Step) error {
+	return nil
+}
+
+func (testExec) UID() string {
+	return "TEST"
+}
+
+// addTx adds the transaction to the pool of the node, which gossips it to the
+// other nodes through the simulated network.
+func addTx(t *testing.T, cluster *Cluster, node *Node, tx txn.Transaction) {
+	err := cluster.Go(func() error {
+		return node.Pool.Add(tx)
+	}, time.Minute)
+	require.NoError(t, err)
+}
+
+func makeTx(t *testing.T, nonce uint64, signer crypto.Signer) txn.Transaction {
+	tx, err := signed.NewTransaction(nonce, signer.GetPublicKey(),
+		signed.WithArg(native.ContractArg, []byte(testContractName)))
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Sign(signer))
+
+	return tx
+}